@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
 	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/seed"
+	"ai-zombie-defense/backend-api/internal/services/leaderboard"
+	"ai-zombie-defense/backend-api/internal/services/server"
+	"ai-zombie-defense/backend-api/internal/webhook"
+	"ai-zombie-defense/backend-api/internal/worker"
 	"ai-zombie-defense/backend-api/pkg/config"
 	"ai-zombie-defense/backend-api/pkg/logging"
 	"go.uber.org/zap"
@@ -37,6 +42,9 @@ func main() {
 		case "migrate":
 			handleMigrate(cfg, logger)
 			return
+		case "seed":
+			handleSeed(cfg, logger)
+			return
 		case "help":
 			printUsage()
 			return
@@ -44,14 +52,45 @@ func main() {
 	}
 
 	// Initialize database
-	dbConn, err := db.OpenDB(cfg.Database.Path)
+	dbConn, err := db.OpenDBWithPool(cfg.Database.Path, db.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	})
 	if err != nil {
 		logger.Fatal("Failed to open database", zap.Error(err))
 	}
 	defer dbConn.Close()
 
+	// Background workers are owned by the manager so shutdown can drain them
+	workers := worker.NewManager(logger)
+
+	// Outbound webhooks are only dispatched when an endpoint is configured
+	var gwOpts []gateway.Option
+	if cfg.Webhook.URL != "" {
+		dispatcher := webhook.NewDispatcher(cfg.Webhook, logger)
+		workers.Go("webhook-dispatcher", dispatcher.Run)
+		gwOpts = append(gwOpts, gateway.WithWebhookDispatcher(dispatcher))
+	}
+
+	// Leaderboard reads are served from snapshots rewritten on an interval
+	if cfg.Leaderboard.RefreshInterval > 0 {
+		lbSvc := leaderboard.NewLeaderboardService(*cfg, logger, dbConn)
+		workers.Every("leaderboard-refresh", cfg.Leaderboard.RefreshInterval, lbSvc.RefreshLeaderboards)
+	}
+
+	// Servers that stop sending heartbeats drop out of the server browser
+	if cfg.GameServer.HeartbeatSweepInterval > 0 {
+		serverSvc := server.NewServerService(*cfg, logger, dbConn)
+		workers.Every("server-heartbeat-sweeper", cfg.GameServer.HeartbeatSweepInterval, func(ctx context.Context) error {
+			_, err := serverSvc.MarkStaleServersOffline(ctx)
+			return err
+		})
+	}
+
 	// Initialize API Gateway
-	gw := gateway.NewAPIGateway(*cfg, logger, dbConn)
+	gw := gateway.NewAPIGateway(*cfg, logger, dbConn, gwOpts...)
 
 	// Start server in background
 	go func() {
@@ -66,20 +105,36 @@ func main() {
 	<-quit
 
 	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	// Workers stop alongside the HTTP drain so a slow request can't eat their share of the timeout
+	workersDone := make(chan error, 1)
+	go func() {
+		workersDone <- workers.Shutdown(ctx)
+	}()
+
 	// Attempt graceful shutdown
 	if err := gw.Shutdown(ctx); err != nil {
 		logger.Error("Gateway shutdown failed", zap.Error(err))
 	}
+	if err := <-workersDone; err != nil {
+		logger.Error("Background workers did not stop in time", zap.Error(err))
+	}
 
 	logger.Info("Server stopped")
 }
 
 func handleMigrate(cfg *config.Config, logger *zap.Logger) {
 	if len(os.Args) < 3 {
-		fmt.Println("Usage: server migrate [up|down|status]")
+		fmt.Println("Usage: server migrate [up|down|status] [--to <version>] [--dry-run]")
+		os.Exit(1)
+	}
+
+	command := os.Args[2]
+	opts, err := parseMigrateFlags(command, os.Args[3:])
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
@@ -90,12 +145,11 @@ func handleMigrate(cfg *config.Config, logger *zap.Logger) {
 	defer dbConn.Close()
 
 	var errMig error
-	command := os.Args[2]
 	switch command {
 	case "up":
-		errMig = db.RunMigrations(dbConn)
+		errMig = db.MigrateUp(dbConn, opts)
 	case "down":
-		errMig = db.Rollback(dbConn)
+		errMig = db.MigrateDown(dbConn, opts)
 	case "status":
 		errMig = db.Status(dbConn)
 	default:
@@ -108,11 +162,67 @@ func handleMigrate(cfg *config.Config, logger *zap.Logger) {
 	}
 }
 
+// parseMigrateFlags reads --to and --dry-run, which only apply to up and down.
+func parseMigrateFlags(command string, args []string) (db.MigrateOptions, error) {
+	var opts db.MigrateOptions
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	to := fs.Int64("to", 0, "migrate to this version instead of the default")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "print the SQL that would run without applying it")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "to" {
+			opts.To = to
+		}
+	})
+	if command == "status" && (opts.To != nil || opts.DryRun) {
+		return opts, fmt.Errorf("--to and --dry-run only apply to migrate up and down")
+	}
+	return opts, nil
+}
+
+func handleSeed(cfg *config.Config, logger *zap.Logger) {
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: server seed <fixtures.json|fixtures.yaml>")
+		os.Exit(1)
+	}
+
+	fixtures, err := seed.Load(os.Args[2])
+	if err != nil {
+		logger.Fatal("Failed to load fixtures", zap.Error(err))
+	}
+
+	dbConn, err := db.OpenDB(cfg.Database.Path)
+	if err != nil {
+		logger.Fatal("Failed to open database", zap.Error(err))
+	}
+	defer dbConn.Close()
+
+	result, err := seed.NewSeeder(*cfg, logger, dbConn).Apply(context.Background(), fixtures)
+	if err != nil {
+		logger.Fatal("Seeding failed", zap.Error(err))
+	}
+	fmt.Printf("Seeded cosmetics: %d created, %d updated\n", result.CosmeticsCreated, result.CosmeticsUpdated)
+	fmt.Printf("Seeded loot tables: %d created, %d updated\n", result.LootTablesCreated, result.LootTablesUpdated)
+	fmt.Printf("Seeded loot entries: %d created, %d updated\n", result.LootEntriesCreated, result.LootEntriesUpdated)
+	if fixtures.Admin != nil {
+		fmt.Printf("Admin %q ready (created: %t)\n", fixtures.Admin.Username, result.AdminCreated)
+	}
+}
+
 func printUsage() {
 	fmt.Println("Usage:")
 	fmt.Println("  server              - Start the API server")
 	fmt.Println("  server migrate up   - Run pending migrations")
 	fmt.Println("  server migrate down - Rollback the last migration")
+	fmt.Println("    --to <version>    - Migrate up or down to a specific version")
+	fmt.Println("    --dry-run         - Print the SQL that would run without applying it")
 	fmt.Println("  server migrate status - Show migration status")
+	fmt.Println("  server seed <file>  - Load fixtures (JSON or YAML) idempotently")
 	fmt.Println("  server help         - Show this help message")
 }
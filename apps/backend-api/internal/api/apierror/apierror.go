@@ -0,0 +1,148 @@
+// Package apierror defines the machine-readable codes sent with JSON error
+// responses. Every error body has the form {"error": message, "code": code};
+// clients should branch on code, as messages may be reworded.
+package apierror
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Code identifies an error condition independently of its message.
+type Code string
+
+// Generic codes, used when no more specific code applies.
+const (
+	CodeBadRequest         Code = "BAD_REQUEST"
+	CodeInvalidRequestBody Code = "INVALID_REQUEST_BODY"
+	CodeValidationFailed   Code = "VALIDATION_FAILED"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodeTooManyRequests    Code = "TOO_MANY_REQUESTS"
+	CodeUpgradeRequired    Code = "UPGRADE_REQUIRED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+)
+
+// Authentication and authorization.
+const (
+	CodeMissingToken        Code = "MISSING_TOKEN"
+	CodeInvalidToken        Code = "INVALID_TOKEN"
+	CodeInvalidCredentials  Code = "INVALID_CREDENTIALS"
+	CodeInvalidRefreshToken Code = "INVALID_REFRESH_TOKEN"
+	CodeInvalidPassword     Code = "INVALID_PASSWORD"
+	CodePlayerBanned        Code = "PLAYER_BANNED"
+	CodeAccountLocked       Code = "ACCOUNT_LOCKED"
+	CodeTooManyAuthAttempts Code = "TOO_MANY_AUTH_ATTEMPTS"
+	CodeAdminRequired       Code = "ADMIN_REQUIRED"
+	CodeRegionBlocked       Code = "REGION_BLOCKED"
+	CodeMissingServerToken  Code = "MISSING_SERVER_TOKEN"
+	CodeInvalidServerToken  Code = "INVALID_SERVER_TOKEN"
+	CodeServerMismatch      Code = "SERVER_MISMATCH"
+	CodeOperationInProgress Code = "OPERATION_IN_PROGRESS"
+)
+
+// Accounts and referrals.
+const (
+	CodePlayerNotFound            Code = "PLAYER_NOT_FOUND"
+	CodeDuplicateUsername         Code = "DUPLICATE_USERNAME"
+	CodeDuplicateEmail            Code = "DUPLICATE_EMAIL"
+	CodeInvalidUsername           Code = "INVALID_USERNAME"
+	CodeInvalidEmail              Code = "INVALID_EMAIL"
+	CodeWeakPassword              Code = "WEAK_PASSWORD"
+	CodeEmailUnchanged            Code = "EMAIL_UNCHANGED"
+	CodeEmailChangeTokenNotFound  Code = "EMAIL_CHANGE_TOKEN_NOT_FOUND"
+	CodeEmailChangeTokenExpired   Code = "EMAIL_CHANGE_TOKEN_EXPIRED"
+	CodeEmailChangeTokenUsed      Code = "EMAIL_CHANGE_TOKEN_USED"
+	CodeReferrerNotFound          Code = "REFERRER_NOT_FOUND"
+	CodeSelfReferral              Code = "SELF_REFERRAL"
+	CodeReferralAlreadyClaimed    Code = "REFERRAL_ALREADY_CLAIMED"
+	CodeReferralWindowExpired     Code = "REFERRAL_WINDOW_EXPIRED"
+	CodeInsufficientCurrency      Code = "INSUFFICIENT_CURRENCY"
+	CodePurchaseLimitReached      Code = "PURCHASE_LIMIT_REACHED"
+	CodePurchaseNotFound          Code = "PURCHASE_NOT_FOUND"
+	CodePurchaseAlreadyUndone     Code = "PURCHASE_ALREADY_UNDONE"
+	CodePurchaseUndoWindowExpired Code = "PURCHASE_UNDO_WINDOW_EXPIRED"
+)
+
+// Cosmetics, loadouts and loot.
+const (
+	CodeCosmeticNotFound        Code = "COSMETIC_NOT_FOUND"
+	CodeCosmeticNotOwned        Code = "COSMETIC_NOT_OWNED"
+	CodeCosmeticAlreadyOwned    Code = "COSMETIC_ALREADY_OWNED"
+	CodeCosmeticExpired         Code = "COSMETIC_EXPIRED"
+	CodeEquipRestricted         Code = "EQUIP_RESTRICTED"
+	CodeCosmeticNotForSale      Code = "COSMETIC_NOT_FOR_SALE"
+	CodeCosmeticInUse           Code = "COSMETIC_IN_USE"
+	CodeCosmeticNotPrestigeOnly Code = "COSMETIC_NOT_PRESTIGE_ONLY"
+	CodeBundleNotFound          Code = "BUNDLE_NOT_FOUND"
+	CodeBundleAlreadyOwned      Code = "BUNDLE_ALREADY_OWNED"
+	CodeLoadoutNotFound         Code = "LOADOUT_NOT_FOUND"
+	CodeLoadoutActive           Code = "LOADOUT_ACTIVE"
+	CodeLootTableNotFound       Code = "LOOT_TABLE_NOT_FOUND"
+	CodeLootTableEntryNotFound  Code = "LOOT_TABLE_ENTRY_NOT_FOUND"
+	CodeNoLootAvailable         Code = "NO_LOOT_AVAILABLE"
+)
+
+// Servers, social, moderation and notifications.
+const (
+	CodeServerNotFound             Code = "SERVER_NOT_FOUND"
+	CodeInvalidJoinToken           Code = "INVALID_JOIN_TOKEN"
+	CodeJoinTokenWrongServer       Code = "JOIN_TOKEN_WRONG_SERVER"
+	CodeFavoriteAlreadyExists      Code = "FAVORITE_ALREADY_EXISTS"
+	CodeFavoriteNotFound           Code = "FAVORITE_NOT_FOUND"
+	CodeCannotFriendSelf           Code = "CANNOT_FRIEND_SELF"
+	CodeFriendRequestAlreadyExists Code = "FRIEND_REQUEST_ALREADY_EXISTS"
+	CodeFriendRequestNotAllowed    Code = "FRIEND_REQUEST_NOT_ALLOWED"
+	CodeFriendRequestNotFound      Code = "FRIEND_REQUEST_NOT_FOUND"
+	CodeFriendRequestNotPending    Code = "FRIEND_REQUEST_NOT_PENDING"
+	CodeCannotBlockSelf            Code = "CANNOT_BLOCK_SELF"
+	CodeBlockNotFound              Code = "BLOCK_NOT_FOUND"
+	CodeCannotReportSelf           Code = "CANNOT_REPORT_SELF"
+	CodeAlreadyReported            Code = "ALREADY_REPORTED"
+	CodeReportRateLimited          Code = "REPORT_RATE_LIMITED"
+	CodeNoOpenReports              Code = "NO_OPEN_REPORTS"
+	CodeInvalidBan                 Code = "INVALID_BAN"
+	CodeInvalidMetric              Code = "INVALID_METRIC"
+	CodeNotificationNotFound       Code = "NOTIFICATION_NOT_FOUND"
+)
+
+// Respond writes a JSON error response with the given status, code and message.
+func Respond(c *fiber.Ctx, status int, code Code, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error": message,
+		"code":  code,
+	})
+}
+
+// FromStatus returns the generic code for an HTTP status, e.g. NOT_FOUND for 404.
+// Statuses without a generic constant get their upper-cased status text.
+func FromStatus(status int) Code {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case fiber.StatusUpgradeRequired:
+		return CodeUpgradeRequired
+	case fiber.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	text := http.StatusText(status)
+	if text == "" || status == fiber.StatusInternalServerError {
+		return CodeInternal
+	}
+	return Code(strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text)))
+}
@@ -0,0 +1,52 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRespond(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Respond(c, fiber.StatusPaymentRequired, CodeInsufficientCurrency, "insufficient data currency")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", resp.StatusCode)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body["error"] != "insufficient data currency" || body["code"] != "INSUFFICIENT_CURRENCY" {
+		t.Errorf("Unexpected body: %v", body)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Code
+	}{
+		{fiber.StatusBadRequest, CodeBadRequest},
+		{fiber.StatusNotFound, CodeNotFound},
+		{fiber.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{fiber.StatusRequestEntityTooLarge, "REQUEST_ENTITY_TOO_LARGE"},
+		{fiber.StatusInternalServerError, CodeInternal},
+		{fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{599, CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := FromStatus(tt.status); got != tt.want {
+			t.Errorf("FromStatus(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
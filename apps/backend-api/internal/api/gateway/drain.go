@@ -0,0 +1,56 @@
+package gateway
+
+import "sync"
+
+// drain tracks in-flight requests so Shutdown can turn new requests away and
+// wait for the ones already running.
+type drain struct {
+	mu       sync.Mutex
+	draining bool
+	inflight int
+	// idle is closed once draining has started and nothing is in flight.
+	idle chan struct{}
+}
+
+// enter registers a request, or reports false if draining has started.
+func (d *drain) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight++
+	return true
+}
+
+// leave unregisters a request admitted by enter.
+func (d *drain) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inflight--
+	if d.draining && d.inflight == 0 {
+		close(d.idle)
+	}
+}
+
+// start stops admitting requests and returns a channel closed once the
+// in-flight ones have finished. Calling it again returns the same channel.
+func (d *drain) start() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.draining {
+		d.draining = true
+		d.idle = make(chan struct{})
+		if d.inflight == 0 {
+			close(d.idle)
+		}
+	}
+	return d.idle
+}
+
+// isDraining reports whether start has been called.
+func (d *drain) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
@@ -1,7 +1,9 @@
 package gateway
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/metrics"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/account"
 	accHandlers "ai-zombie-defense/backend-api/internal/services/account/handlers"
@@ -13,13 +15,20 @@ import (
 	lootHandlers "ai-zombie-defense/backend-api/internal/services/loot/handlers"
 	"ai-zombie-defense/backend-api/internal/services/match"
 	matchHandlers "ai-zombie-defense/backend-api/internal/services/match/handlers"
+	"ai-zombie-defense/backend-api/internal/services/moderation"
+	modHandlers "ai-zombie-defense/backend-api/internal/services/moderation/handlers"
+	"ai-zombie-defense/backend-api/internal/services/notification"
+	notifHandlers "ai-zombie-defense/backend-api/internal/services/notification/handlers"
 	"ai-zombie-defense/backend-api/internal/services/progression"
 	progHandlers "ai-zombie-defense/backend-api/internal/services/progression/handlers"
 	"ai-zombie-defense/backend-api/internal/services/server"
 	srvHandlers "ai-zombie-defense/backend-api/internal/services/server/handlers"
 	"ai-zombie-defense/backend-api/internal/services/social"
 	socialHandlers "ai-zombie-defense/backend-api/internal/services/social/handlers"
+	"ai-zombie-defense/backend-api/internal/webhook"
+	"ai-zombie-defense/backend-api/internal/websocket"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
@@ -28,18 +37,69 @@ import (
 	fiberLogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"go.uber.org/zap"
+	"time"
+)
+
+// Names of the heavy admin operations limited by config.AdminConfig.OperationConcurrency.
+const (
+	OperationPrestigeBackfill      = "prestige_backfill"
+	OperationNotificationBroadcast = "notification_broadcast"
+	OperationLootEntriesBulk       = "loot_entries_bulk"
 )
 
 // APIGateway handles the central routing and global middleware for the modular monolith.
 type APIGateway struct {
-	router *fiber.App
-	logger *zap.Logger
-	cfg    config.Config
-	db     db.DBTX
+	router          *fiber.App
+	logger          *zap.Logger
+	cfg             config.Config
+	db              db.DBTX
+	countryResolver middleware.CountryResolver
+	webhooks        *webhook.Dispatcher
+	mailer          account.Mailer
+	// drain turns new requests away with 503 once Shutdown is called.
+	drain drain
+	// metricsApp serves /metrics on Metrics.Addr; nil when metrics share the API port.
+	metricsApp *fiber.App
+}
+
+// Option customizes an APIGateway at construction time.
+type Option func(*APIGateway)
+
+// WithCountryResolver sets the geo-IP resolver used for region blocking on /auth routes.
+// Without a resolver, Server.BlockedCountries has no effect.
+func WithCountryResolver(resolver middleware.CountryResolver) Option {
+	return func(g *APIGateway) {
+		g.countryResolver = resolver
+	}
+}
+
+// WithWebhookDispatcher publishes moderation events to the dispatcher and exposes
+// its queue status on /admin/webhooks/status.
+func WithWebhookDispatcher(dispatcher *webhook.Dispatcher) Option {
+	return func(g *APIGateway) {
+		g.webhooks = dispatcher
+	}
+}
+
+// webhookPublisher returns the configured dispatcher, or nil so services skip
+// publishing instead of calling through a nil *webhook.Dispatcher.
+func (g *APIGateway) webhookPublisher() webhook.Publisher {
+	if g.webhooks == nil {
+		return nil
+	}
+	return g.webhooks
+}
+
+// WithMailer sets the transport for account emails such as email change
+// tokens. Without one, tokens are only logged at debug level.
+func WithMailer(mailer account.Mailer) Option {
+	return func(g *APIGateway) {
+		g.mailer = mailer
+	}
 }
 
 // NewAPIGateway creates a new instance of APIGateway with a configured Fiber router.
-func NewAPIGateway(cfg config.Config, logger *zap.Logger, db db.DBTX) *APIGateway {
+func NewAPIGateway(cfg config.Config, logger *zap.Logger, db db.DBTX, opts ...Option) *APIGateway {
 	app := fiber.New(fiber.Config{
 		AppName: "AI Zombie Defense API Gateway",
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -48,12 +108,9 @@ func NewAPIGateway(cfg config.Config, logger *zap.Logger, db db.DBTX) *APIGatewa
 				code = e.Code
 			}
 			if code >= fiber.StatusInternalServerError {
-				logger.Error("gateway error", zap.Error(err))
+				logging.FromContext(c.Context(), logger).Error("gateway error", zap.Error(err))
 			}
-			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
-			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, code, apierror.FromStatus(code), err.Error())
 		},
 	})
 
@@ -63,21 +120,27 @@ func NewAPIGateway(cfg config.Config, logger *zap.Logger, db db.DBTX) *APIGatewa
 		cfg:    cfg,
 		db:     db,
 	}
+	for _, opt := range opts {
+		opt(gw)
+	}
 
 	gw.applyMiddleware()
 	gw.setupHealthCheck()
+	gw.setupMetrics()
 
 	if db != nil {
 		authSvc := auth.NewAuthService(cfg, logger, db)
-		accSvc := account.NewAccountService(cfg, logger, db)
+		accSvc := account.NewAccountService(cfg, logger, db, gw.mailer)
 		progSvc := progression.NewProgressionService(cfg, logger, db)
-		lootSvc := loot.NewLootService(cfg, logger, db)
+		lootSvc := loot.NewLootService(cfg, logger, db, progSvc, nil)
 		matchSvc := match.NewMatchService(cfg, logger, db, progSvc)
 		serverSvc := server.NewServerService(cfg, logger, db)
-		socialSvc := social.NewSocialService(cfg, logger, db)
+		notifSvc := notification.NewNotificationService(cfg, logger, db)
+		socialSvc := social.NewSocialService(cfg, logger, db, notifSvc)
 		lbSvc := leaderboard.NewLeaderboardService(cfg, logger, db)
+		modSvc := moderation.NewModerationService(cfg, logger, db, authSvc, gw.webhookPublisher())
 
-		gw.registerRoutes(authSvc, accSvc, progSvc, matchSvc, serverSvc, socialSvc, lbSvc, lootSvc)
+		gw.registerRoutes(authSvc, accSvc, progSvc, matchSvc, serverSvc, socialSvc, lbSvc, lootSvc, notifSvc, modSvc)
 	}
 
 	return gw
@@ -92,31 +155,43 @@ func (g *APIGateway) registerRoutes(
 	socialSvc social.Service,
 	lbSvc leaderboard.Service,
 	lootSvc loot.Service,
+	notifSvc notification.Service,
+	modSvc moderation.Service,
 ) {
 	// Auth routes
 	authH := authHandlers.NewAuthHandlers(authSvc, g.cfg, g.logger)
-	authGroup := g.MountGroup("/auth")
-	authGroup.Post("/login", authH.Login)
-	authGroup.Post("/register", authH.Register)
+	authGroup := g.MountGroup("/auth", middleware.GeoBlockMiddleware(g.countryResolver, g.cfg.Server.BlockedCountries, g.logger))
+	authLimiter := middleware.NewAuthRateLimiter(g.cfg.Server.AuthRateLimitMax, g.cfg.Server.AuthRateLimitDuration).Limit(g.logger)
+	authGroup.Post("/login", authLimiter, authH.Login)
+	authGroup.Post("/register", authLimiter, authH.Register)
 	authGroup.Post("/refresh", authH.Refresh)
 	authGroup.Post("/logout", authH.Logout)
 
 	// Protected routes
 	authMiddleware := middleware.AuthMiddleware(authSvc, g.logger)
+	authGroup.Post("/logout-all", authMiddleware, authH.LogoutAll)
+	authGroup.Get("/me", authMiddleware, authH.Me)
 
 	// Account routes
 	accountH := accHandlers.NewAccountHandlers(accSvc, g.logger)
 	accountGroup := g.MountGroup("/account", authMiddleware)
+	accountGroup.Delete("/", accountH.DeleteAccount)
 	accountGroup.Get("/profile", accountH.GetProfile)
 	accountGroup.Put("/profile", accountH.UpdateProfile)
+	accountGroup.Put("/password", accountH.ChangePassword)
+	accountGroup.Post("/email/request", accountH.RequestEmailChange)
+	accountGroup.Post("/email/confirm", accountH.ConfirmEmailChange)
+	accountGroup.Post("/referral/claim", accountH.ClaimReferral)
 	accountGroup.Get("/settings", accountH.GetSettings)
 	accountGroup.Put("/settings", accountH.UpdateSettings)
+	accountGroup.Patch("/settings", accountH.PatchSettings)
 
 	// Progression routes
 	progressionH := progHandlers.NewProgressionHandlers(progSvc, g.logger)
 	progressionGroup := g.MountGroup("/progression", authMiddleware)
 	progressionGroup.Get("/", progressionH.GetProgression)
 	progressionGroup.Get("/currency", progressionH.GetCurrencyBalance)
+	progressionGroup.Get("/currency/history", progressionH.GetCurrencyHistory)
 	progressionGroup.Post("/prestige", progressionH.PrestigePlayer)
 	// Duplicate route for legacy support if needed, but prd says update gateway routing
 	accountGroup.Get("/progression", progressionH.GetProgression)
@@ -125,87 +200,261 @@ func (g *APIGateway) registerRoutes(
 	cosmeticsGroup := g.MountGroup("/cosmetics", authMiddleware)
 	cosmeticsGroup.Get("/catalog", progressionH.GetCosmeticCatalog)
 	cosmeticsGroup.Get("/owned", progressionH.GetPlayerCosmetics)
+	cosmeticsGroup.Get("/bundles", progressionH.ListCosmeticBundles)
+	cosmeticsGroup.Post("/bundles/:id/purchase", progressionH.PurchaseBundle)
+	cosmeticsGroup.Get("/:id/friends-owning", progressionH.GetFriendsOwningCosmetic)
 	cosmeticsGroup.Put("/equip", progressionH.EquipCosmetic)
 	cosmeticsGroup.Post("/purchase", progressionH.PurchaseCosmetic)
+	cosmeticsGroup.Post("/purchase/undo", progressionH.UndoPurchase)
+
+	// Store route
+	storeGroup := g.MountGroup("/store", authMiddleware)
+	storeGroup.Get("/", progressionH.GetStore)
+
+	// Public player routes
+	playersGroup := g.MountGroup("/players", authMiddleware)
+	playersGroup.Get("/:id/loadout", progressionH.GetPublicLoadout)
+	moderationH := modHandlers.NewModerationHandlers(modSvc, g.logger)
+	playersGroup.Post("/:id/report", moderationH.ReportPlayer)
+
+	// Loadout routes
+	loadoutsGroup := g.MountGroup("/loadouts", authMiddleware)
+	loadoutsGroup.Get("/", progressionH.ListLoadouts)
+	loadoutsGroup.Post("/", progressionH.CreateLoadout)
+	loadoutsGroup.Post("/reset", progressionH.ResetLoadout)
+	loadoutsGroup.Post("/:id/activate", progressionH.ActivateLoadout)
+	loadoutsGroup.Delete("/:id", progressionH.DeleteLoadout)
 
 	// Matches routes
 	matchH := matchHandlers.NewMatchHandlers(matchSvc, g.logger)
-	matchesGroup := g.MountGroup("/matches", authMiddleware)
-	matchesGroup.Post("/", matchH.StoreMatch)
-	matchesGroup.Get("/history", matchH.GetMatchHistory)
+	matchesGroup := g.MountGroup("/matches")
+	matchesGroup.Post("/", middleware.ServerTokenMiddleware(serverSvc, g.logger), matchH.StoreMatch)
+	matchesGroup.Get("/history", authMiddleware, matchH.GetMatchHistory)
+	accountGroup.Get("/stats/outcomes", matchH.GetOutcomeDistribution)
 
 	// Server routes
-	serverH := srvHandlers.NewServerHandlers(serverSvc, g.logger)
+	serverH := srvHandlers.NewServerHandlers(serverSvc, g.cfg, g.logger)
 	serversGroup := g.MountGroup("/servers")
 	serversGroup.Post("/register", serverH.RegisterServer)
 	serversGroup.Get("/", serverH.ListServers)
 	serversGroup.Put("/:id/heartbeat", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.UpdateHeartbeat)
+	serversGroup.Put("/:id", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.UpdateServer)
+	serversGroup.Delete("/:id", serverH.RequireServer, middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.DeregisterServer)
 	serversGroup.Post("/:id/join", authMiddleware, serverH.GenerateJoinToken)
-	serversGroup.Post("/join-token/:token/validate", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.ValidateJoinToken)
+	serversGroup.Post("/:id/join-token/:token/validate", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.ValidateJoinToken)
+	serversGroup.Post("/:id/join-token/mark-used-batch", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.MarkTokensUsedBatch)
+	serversGroup.Get("/:id/joins", middleware.ServerAuthMiddleware(serverSvc, g.logger), serverH.ListServerJoins)
+	serversGroup.Get("/players/:id/loadout", middleware.ServerTokenMiddleware(serverSvc, g.logger), progressionH.GetServerPlayerLoadout)
 
 	// Favorites routes
 	favoriteH := socialHandlers.NewFavoriteHandlers(serverSvc, g.logger)
 	favoritesGroup := g.MountGroup("/favorites", authMiddleware)
 	favoritesGroup.Post("/", favoriteH.AddFavorite)
 	favoritesGroup.Get("/", favoriteH.ListFavorites)
+	favoritesGroup.Put("/:id", favoriteH.UpdateFavorite)
 	favoritesGroup.Delete("/:id", favoriteH.RemoveFavorite)
 
 	// Friends routes
-	socialH := socialHandlers.NewFriendHandlers(socialSvc, g.logger)
+	socialH := socialHandlers.NewFriendHandlers(socialSvc, g.cfg, g.logger)
 	friendsGroup := g.MountGroup("/friends", authMiddleware)
 
 	friendsGroup.Post("/request", socialH.SendFriendRequest)
+	friendsGroup.Post("/request/bulk", socialH.SendBulkFriendRequests)
 	friendsGroup.Put("/:id", socialH.UpdateFriendRequest)
+	friendsGroup.Delete("/:id", socialH.RemoveFriend)
+	friendsGroup.Post("/:id/block", socialH.BlockPlayer)
+	friendsGroup.Delete("/:id/block", socialH.UnblockPlayer)
 	friendsGroup.Get("/", socialH.ListFriends)
+	friendsGroup.Get("/events", socialH.FriendEvents)
+	friendsGroup.Get("/requests/incoming", socialH.ListIncomingRequests)
+	friendsGroup.Get("/requests/outgoing", socialH.ListOutgoingRequests)
+
+	// Realtime routes
+	wsGroup := g.MountGroup("/ws", authMiddleware)
+	wsGroup.Get("/presence", websocket.New(socialH.PresenceSocket))
+
+	// Notification routes
+	notificationH := notifHandlers.NewNotificationHandlers(notifSvc, g.logger)
+	notificationsGroup := g.MountGroup("/notifications", authMiddleware)
+	notificationsGroup.Get("/", notificationH.ListNotifications)
+	notificationsGroup.Post("/:id/read", notificationH.MarkRead)
 
 	// Leaderboard routes
 	leaderboardH := lbHandlers.NewLeaderboardHandlers(lbSvc, g.logger)
 	leaderboardsGroup := g.MountGroup("/leaderboards")
-	leaderboardsGroup.Get("/daily", leaderboardH.GetDailyLeaderboard)
-	leaderboardsGroup.Get("/weekly", leaderboardH.GetWeeklyLeaderboard)
-	leaderboardsGroup.Get("/alltime", leaderboardH.GetAllTimeLeaderboard)
+	// Lists are public; ?scope=friends needs the caller, so only then authenticate
+	friendsScopeAuth := func(c *fiber.Ctx) error {
+		if c.Query("scope") == lbHandlers.ScopeFriends {
+			return authMiddleware(c)
+		}
+		return c.Next()
+	}
+	leaderboardsGroup.Get("/daily", friendsScopeAuth, leaderboardH.GetDailyLeaderboard)
+	leaderboardsGroup.Get("/weekly", friendsScopeAuth, leaderboardH.GetWeeklyLeaderboard)
+	leaderboardsGroup.Get("/alltime", friendsScopeAuth, leaderboardH.GetAllTimeLeaderboard)
+	leaderboardsGroup.Get("/:period.csv", leaderboardH.ExportLeaderboardCSV)
+	leaderboardsGroup.Get("/:period/percentile", authMiddleware, leaderboardH.GetPlayerPercentile)
+	leaderboardsGroup.Get("/:period/rank", authMiddleware, leaderboardH.GetPlayerRank)
 
 	// Loot routes
 	lootH := lootHandlers.NewLootHandlers(lootSvc, g.logger)
 	lootGroup := g.MountGroup("/loot", authMiddleware)
 	lootGroup.Post("/drop", lootH.GenerateLootDrop)
+	cosmeticsGroup.Get("/:id/sources", lootH.GetCosmeticSources)
 
 	// Admin routes
 	lootTableH := lootHandlers.NewLootTableHandlers(lootSvc, g.logger)
 	adminGroup := g.MountGroup("/admin", authMiddleware, middleware.AdminMiddleware(authSvc, g.logger))
+	opLimiter := middleware.NewOperationLimiter(g.cfg.Admin.DefaultOperationConcurrency, g.cfg.Admin.OperationConcurrency)
 	adminGroup.Get("/loot-tables", lootTableH.ListLootTables)
 	adminGroup.Post("/loot-tables", lootTableH.CreateLootTable)
 	adminGroup.Get("/loot-tables/:id", lootTableH.GetLootTable)
 	adminGroup.Put("/loot-tables/:id", lootTableH.UpdateLootTable)
 	adminGroup.Delete("/loot-tables/:id", lootTableH.DeleteLootTable)
 	adminGroup.Get("/loot-tables/:id/entries", lootTableH.ListLootTableEntries)
+	adminGroup.Get("/loot-tables/:id/simulate", lootTableH.SimulateLootTable)
 	adminGroup.Post("/loot-tables/:id/entries", lootTableH.CreateLootTableEntry)
+	adminGroup.Post("/loot-tables/:id/entries/bulk", opLimiter.Limit(OperationLootEntriesBulk, g.logger), lootTableH.BulkCreateLootTableEntries)
 	adminGroup.Get("/loot-tables/entries/:entryId", lootTableH.GetLootTableEntry)
 	adminGroup.Put("/loot-tables/entries/:entryId", lootTableH.UpdateLootTableEntry)
 	adminGroup.Delete("/loot-tables/entries/:entryId", lootTableH.DeleteLootTableEntry)
+	adminGroup.Get("/cosmetics", progressionH.ListCosmeticItems)
+	adminGroup.Post("/cosmetics", progressionH.CreateCosmeticItem)
+	adminGroup.Get("/cosmetics/:id", progressionH.GetCosmeticItem)
+	adminGroup.Put("/cosmetics/:id", progressionH.UpdateCosmeticItem)
+	adminGroup.Delete("/cosmetics/:id", progressionH.DeleteCosmeticItem)
+	adminGroup.Post("/cosmetics/:id/backfill-prestige", opLimiter.Limit(OperationPrestigeBackfill, g.logger), progressionH.BackfillPrestigeCosmetic)
+	adminGroup.Post("/notifications/broadcast", opLimiter.Limit(OperationNotificationBroadcast, g.logger), notificationH.Broadcast)
+	adminGroup.Get("/economy/snapshot", progressionH.GetEconomySnapshot)
+	adminGroup.Post("/players/:id/currency", progressionH.AdjustCurrency)
+	adminGroup.Get("/webhooks/status", g.webhookStatus)
+	adminGroup.Get("/reports", moderationH.ListReports)
+	adminGroup.Post("/players/:id/reports/resolve", moderationH.ResolveReports)
+	adminGroup.Get("/players", moderationH.SearchPlayers)
+	adminGroup.Post("/players/:id/ban", moderationH.BanPlayer)
+	adminGroup.Post("/players/:id/unban", moderationH.UnbanPlayer)
 
 }
 
 // applyMiddleware sets up global middleware for the gateway.
 func (g *APIGateway) applyMiddleware() {
+	g.router.Use(middleware.RequestID())
 	g.router.Use(cors.New(cors.Config{
-		AllowOrigins: g.cfg.Server.CORSAllowOrigins,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowOrigins:  g.cfg.Server.CORSAllowOrigins,
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, " + middleware.RequestIDHeader,
+		ExposeHeaders: middleware.RequestIDHeader,
 	}))
-	g.router.Use(fiberLogger.New())
+	g.router.Use(g.rejectWhenDraining)
+	g.router.Use(fiberLogger.New(fiberLogger.Config{
+		Format: "${time} | ${respHeader:" + middleware.RequestIDHeader + "} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+	}))
+	if g.cfg.Metrics.Enabled {
+		g.router.Use(metrics.Middleware())
+	}
 	g.router.Use(recover.New())
 	g.router.Use(limiter.New(limiter.Config{
 		Max:        g.cfg.Server.RateLimitMax,
 		Expiration: g.cfg.Server.RateLimitDuration,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apierror.Respond(c, fiber.StatusTooManyRequests, apierror.CodeTooManyRequests, "too many requests")
+		},
 	}))
 }
 
-// setupHealthCheck adds a basic health check endpoint to the gateway.
+// rejectWhenDraining answers 503 to requests that arrive after Shutdown has
+// begun and counts the rest as in flight. Health probes are exempt so /ready
+// can report the shutdown itself.
+func (g *APIGateway) rejectWhenDraining(c *fiber.Ctx) error {
+	if path := c.Path(); path == "/health" || path == "/ready" {
+		return c.Next()
+	}
+	if !g.drain.enter() {
+		c.Set(fiber.HeaderConnection, "close")
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "server is shutting down")
+	}
+	defer g.drain.leave()
+	return c.Next()
+}
+
+// healthCheckTimeout bounds the database ping made by /health and /ready.
+const healthCheckTimeout = 2 * time.Second
+
+// setupHealthCheck adds the health and readiness endpoints. /health reports
+// whether the process and its database are working; /ready additionally turns
+// 503 once Shutdown has begun, so load balancers stop routing new requests.
 func (g *APIGateway) setupHealthCheck() {
 	g.router.Get("/health", func(c *fiber.Ctx) error {
+		if err := g.pingDatabase(c.Context()); err != nil {
+			logging.FromContext(c.Context(), g.logger).Warn("health check: database ping failed", zap.Error(err))
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":   "degraded",
+				"database": "unavailable",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status":   "ok",
+			"database": "ok",
+		})
+	})
+	g.router.Get("/ready", func(c *fiber.Ctx) error {
+		if g.drain.isDraining() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "shutting_down",
+			})
+		}
+		if err := g.pingDatabase(c.Context()); err != nil {
+			logging.FromContext(c.Context(), g.logger).Warn("readiness check: database ping failed", zap.Error(err))
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "not_ready",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status": "ready",
+		})
+	})
+}
+
+// pingDatabase checks the database connection. A gateway without a database,
+// or with a DBTX that can't be pinged (e.g. a transaction), has nothing to check.
+func (g *APIGateway) pingDatabase(ctx context.Context) error {
+	pinger, ok := g.db.(interface {
+		PingContext(context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return pinger.PingContext(ctx)
+}
+
+// setupMetrics serves the Prometheus /metrics endpoint, on the API router or,
+// when Metrics.Addr is set, on a separate app that Start listens on.
+func (g *APIGateway) setupMetrics() {
+	if !g.cfg.Metrics.Enabled {
+		return
+	}
+	if g.cfg.Metrics.Addr == "" {
+		g.router.Get("/metrics", metrics.Handler())
+		return
+	}
+	g.metricsApp = fiber.New(fiber.Config{
+		AppName:               "AI Zombie Defense Metrics",
+		DisableStartupMessage: true,
+	})
+	g.metricsApp.Get("/metrics", metrics.Handler())
+}
+
+// webhookStatus handles GET /admin/webhooks/status
+func (g *APIGateway) webhookStatus(c *fiber.Ctx) error {
+	if g.webhooks == nil {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"status": "ok",
+			"enabled": false,
 		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"enabled": true,
+		"status":  g.webhooks.Status(),
 	})
 }
 
@@ -222,12 +471,31 @@ func (g *APIGateway) Router() *fiber.App {
 // Start begins listening on the configured host and port.
 func (g *APIGateway) Start() error {
 	addr := fmt.Sprintf("%s:%d", g.cfg.Server.Host, g.cfg.Server.Port)
+	if g.metricsApp != nil {
+		g.logger.Info("Starting metrics listener", zap.String("address", g.cfg.Metrics.Addr))
+		go func() {
+			if err := g.metricsApp.Listen(g.cfg.Metrics.Addr); err != nil {
+				g.logger.Error("metrics listener stopped", zap.Error(err))
+			}
+		}()
+	}
 	g.logger.Info("Starting API Gateway", zap.String("address", addr))
 	return g.router.Listen(addr)
 }
 
-// Shutdown gracefully stops the gateway.
+// Shutdown gracefully stops the gateway: new requests get 503 while in-flight
+// ones finish, then the listeners close. Both steps share ctx's deadline.
 func (g *APIGateway) Shutdown(ctx context.Context) error {
 	g.logger.Info("Shutting down API Gateway...")
+	select {
+	case <-g.drain.start():
+	case <-ctx.Done():
+		g.logger.Warn("shutdown deadline reached with requests still in flight")
+	}
+	if g.metricsApp != nil {
+		if err := g.metricsApp.ShutdownWithContext(ctx); err != nil {
+			g.logger.Error("failed to shut down metrics listener", zap.Error(err))
+		}
+	}
 	return g.router.ShutdownWithContext(ctx)
 }
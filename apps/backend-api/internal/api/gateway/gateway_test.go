@@ -0,0 +1,209 @@
+package gateway_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+// scrapeCounter reads the value of one series from GET /metrics, or 0 if it
+// hasn't been recorded yet.
+func scrapeCounter(t *testing.T, app *fiber.App, series string) float64 {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 from /metrics, got %d", resp.StatusCode)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), series+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("Failed to parse %s value %q: %v", series, value, err)
+			}
+			return v
+		}
+	}
+	return 0
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+	testutils.CreateTestPlayer(t, db, "metricsuser", "metrics@example.com", "password")
+
+	const loginSuccess = `auth_attempts_total{action="login",result="success"}`
+	const loginRequests = `http_requests_total{method="POST",route="/auth/login",status="200"}`
+	successBefore := scrapeCounter(t, app, loginSuccess)
+	requestsBefore := scrapeCounter(t, app, loginRequests)
+
+	body, _ := json.Marshal(map[string]string{"username_or_email": "metricsuser", "password": "password"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if got := scrapeCounter(t, app, loginSuccess) - successBefore; got != 1 {
+		t.Errorf("Expected %s to increase by 1, got %v", loginSuccess, got)
+	}
+	if got := scrapeCounter(t, app, loginRequests) - requestsBefore; got != 1 {
+		t.Errorf("Expected %s to increase by 1, got %v", loginRequests, got)
+	}
+}
+
+func TestMetricsEndpointOnSeparateAddr(t *testing.T) {
+	cfg := testutils.GetTestConfig()
+	cfg.Metrics.Addr = "127.0.0.1:0"
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), nil).Router()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected /metrics to be absent from the API router, got status %d", resp.StatusCode)
+	}
+}
+
+// getStatus requests path and decodes the "status" field of the JSON response.
+func getStatus(t *testing.T, app *fiber.App, path string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode %s response: %v", path, err)
+	}
+	return resp.StatusCode, body.Status
+}
+
+func TestHealthCheck(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+
+	if code, status := getStatus(t, app, "/health"); code != http.StatusOK || status != "ok" {
+		t.Errorf("Expected healthy 200 ok, got %d %q", code, status)
+	}
+	if code, status := getStatus(t, app, "/ready"); code != http.StatusOK || status != "ready" {
+		t.Errorf("Expected ready 200, got %d %q", code, status)
+	}
+
+	// A closed database degrades both checks
+	db.Close()
+	if code, status := getStatus(t, app, "/health"); code != http.StatusServiceUnavailable || status != "degraded" {
+		t.Errorf("Expected 503 degraded with closed DB, got %d %q", code, status)
+	}
+	if code, status := getStatus(t, app, "/ready"); code != http.StatusServiceUnavailable || status != "not_ready" {
+		t.Errorf("Expected 503 not_ready with closed DB, got %d %q", code, status)
+	}
+}
+
+func TestReadyDuringShutdown(t *testing.T) {
+	gw := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), nil)
+	app := gw.Router()
+
+	if code, _ := getStatus(t, app, "/ready"); code != http.StatusOK {
+		t.Fatalf("Expected ready before shutdown, got %d", code)
+	}
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if code, status := getStatus(t, app, "/ready"); code != http.StatusServiceUnavailable || status != "shutting_down" {
+		t.Errorf("Expected 503 shutting_down after shutdown, got %d %q", code, status)
+	}
+	// Liveness is unaffected
+	if code, _ := getStatus(t, app, "/health"); code != http.StatusOK {
+		t.Errorf("Expected /health 200 during shutdown, got %d", code)
+	}
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	gw := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), nil)
+	app := gw.Router()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	inFlight := make(chan int, 1)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), -1)
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		inFlight <- resp.StatusCode
+	}()
+	<-started
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		shutdownDone <- gw.Shutdown(context.Background())
+	}()
+
+	// Wait until draining has begun, which /ready reports
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if code, _ := getStatus(t, app, "/ready"); code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Gateway never started draining")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A new request is turned away while the first is still running
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for a request during shutdown, got %d", resp.StatusCode)
+	}
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("Shutdown returned before the in-flight request finished: %v", err)
+	default:
+	}
+
+	close(release)
+	if status := <-inFlight; status != http.StatusOK {
+		t.Errorf("Expected in-flight request to complete with 200, got %d", status)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
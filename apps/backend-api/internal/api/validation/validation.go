@@ -0,0 +1,208 @@
+// Package validation checks request structs against `validate` struct tags,
+// so handlers share one set of field rules and one 422 response shape.
+//
+// Rules are comma-separated:
+//
+//	required   the field must be set: non-empty string or slice, non-zero
+//	           number, non-nil pointer
+//	min=N      numbers must be >= N; strings and slices must have at least N
+//	           characters or entries
+//	max=N      numbers must be <= N; strings and slices at most N characters
+//	           or entries
+//	oneof=a b  the string must be one of the space-separated values
+//
+// Rules other than required are skipped for empty strings and slices and nil
+// pointers, so optional fields are only checked when present. Numbers are
+// always checked.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrInvalidBody is returned by ParseBody when the body can't be decoded.
+var ErrInvalidBody = errors.New("invalid request body")
+
+// FieldError describes why one request field is invalid. Field is the JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists every invalid field of a request, in struct field order.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + " " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseBody decodes the request body into out, a pointer to a struct, and
+// validates it. It returns ErrInvalidBody or Errors; pass either to Respond.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return ErrInvalidBody
+	}
+	return Struct(out)
+}
+
+// Respond writes the response for an error from ParseBody or Struct: 422 with
+// the field list for Errors, 400 otherwise.
+func Respond(c *fiber.Ctx, err error) error {
+	var fieldErrs Errors
+	if errors.As(err, &fieldErrs) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":  "validation failed",
+			"code":   apierror.CodeValidationFailed,
+			"fields": fieldErrs,
+		})
+	}
+	return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+}
+
+// Struct validates v, a struct or pointer to one, and returns Errors listing
+// every field that breaks its rules, or nil. Malformed tags panic, as they
+// are programming errors.
+func Struct(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %T is not a struct", v))
+	}
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		if msg := checkField(rv.Field(i), tag); msg != "" {
+			errs = append(errs, FieldError{Field: jsonName(field), Message: msg})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkField applies the rules of tag to value and returns the message of the
+// first broken rule, or "" if all pass.
+func checkField(value reflect.Value, tag string) string {
+	rules := strings.Split(tag, ",")
+	present := isPresent(value)
+	for _, rule := range rules {
+		if rule == "required" && !present {
+			return "is required"
+		}
+	}
+	if !present && value.Kind() != reflect.Bool && !isNumber(value.Kind()) {
+		return ""
+	}
+	value = reflect.Indirect(value)
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		var msg string
+		switch name {
+		case "required":
+		case "min":
+			msg = checkBound(value, name, arg, func(n, bound float64) bool { return n >= bound })
+		case "max":
+			msg = checkBound(value, name, arg, func(n, bound float64) bool { return n <= bound })
+		case "oneof":
+			options := strings.Fields(arg)
+			if value.Kind() != reflect.String {
+				panic("validation: oneof on non-string field")
+			}
+			found := false
+			for _, option := range options {
+				if value.String() == option {
+					found = true
+					break
+				}
+			}
+			if !found {
+				msg = "must be one of " + strings.Join(options, ", ")
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q", rule))
+		}
+		if msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// isPresent reports whether value counts as set for the required rule.
+func isPresent(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !value.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len() > 0
+	default:
+		return !value.IsZero()
+	}
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// checkBound applies a min or max rule: to the value of numbers and to the
+// length of strings and slices.
+func checkBound(value reflect.Value, name, arg string, ok func(n, bound float64) bool) string {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid %s bound %q", name, arg))
+	}
+	word := "at least"
+	if name == "max" {
+		word = "at most"
+	}
+	var n float64
+	var unit string
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Map:
+		n, unit = float64(value.Len()), " entries"
+	default:
+		panic(fmt.Sprintf("validation: %s on unsupported kind %s", name, value.Kind()))
+	}
+	if ok(n, bound) {
+		return ""
+	}
+	if unit != "" {
+		return fmt.Sprintf("must have %s %s%s", word, arg, unit)
+	}
+	return fmt.Sprintf("must be %s %s", word, arg)
+}
+
+// jsonName returns the name field has in JSON bodies.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testRequest struct {
+	Name    string   `json:"name" validate:"required,max=5"`
+	Count   int64    `json:"count" validate:"min=0,max=10"`
+	Mode    string   `json:"mode,omitempty" validate:"oneof=fast slow"`
+	Limit   *int64   `json:"limit" validate:"min=1"`
+	Tags    []string `json:"tags" validate:"required,max=2"`
+	Ignored string
+}
+
+func TestStruct(t *testing.T) {
+	zero, two := int64(0), int64(2)
+	tests := []struct {
+		name string
+		req  testRequest
+		want error
+	}{
+		{
+			name: "valid",
+			req:  testRequest{Name: "bob", Count: 3, Mode: "fast", Limit: &two, Tags: []string{"a"}},
+		},
+		{
+			name: "optional fields omitted",
+			req:  testRequest{Name: "bob", Tags: []string{"a"}},
+		},
+		{
+			name: "every field invalid",
+			req:  testRequest{Name: "toolong", Count: -1, Mode: "medium", Limit: &zero},
+			want: Errors{
+				{Field: "name", Message: "must have at most 5 characters"},
+				{Field: "count", Message: "must be at least 0"},
+				{Field: "mode", Message: "must be one of fast, slow"},
+				{Field: "limit", Message: "must be at least 1"},
+				{Field: "tags", Message: "is required"},
+			},
+		},
+		{
+			name: "slice too long",
+			req:  testRequest{Name: "bob", Tags: []string{"a", "b", "c"}},
+			want: Errors{{Field: "tags", Message: "must have at most 2 entries"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Struct(&tt.req)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -15,32 +17,61 @@ const (
 	defaultConnMaxIdleTime = 2 * time.Minute
 )
 
-// OpenDB opens a SQLite database at the given path with connection pooling,
-// foreign keys enabled, and WAL mode for better concurrency.
-func OpenDB(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
-	if err != nil {
-		return nil, err
-	}
+// busyTimeoutMillis is how long a connection waits on another connection's
+// write lock before failing with SQLITE_BUSY.
+const busyTimeoutMillis = 5000
 
-	// Configure connection pool
-	db.SetMaxOpenConns(defaultMaxOpenConns)
-	db.SetMaxIdleConns(defaultMaxIdleConns)
-	db.SetConnMaxLifetime(defaultConnMaxLifetime)
-	db.SetConnMaxIdleTime(defaultConnMaxIdleTime)
+// PoolConfig sizes the connection pool opened by OpenDBWithPool. Zero or
+// negative fields fall back to the package defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
 
-	// Enable foreign keys (required for referential integrity)
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		db.Close()
-		return nil, err
+// withDefaults fills unset fields and keeps the idle pool within the open limit.
+func (p PoolConfig) withDefaults() PoolConfig {
+	if p.MaxOpenConns <= 0 {
+		p.MaxOpenConns = defaultMaxOpenConns
+	}
+	if p.MaxIdleConns <= 0 {
+		p.MaxIdleConns = defaultMaxIdleConns
+	}
+	if p.MaxIdleConns > p.MaxOpenConns {
+		p.MaxIdleConns = p.MaxOpenConns
+	}
+	if p.ConnMaxLifetime <= 0 {
+		p.ConnMaxLifetime = defaultConnMaxLifetime
+	}
+	if p.ConnMaxIdleTime <= 0 {
+		p.ConnMaxIdleTime = defaultConnMaxIdleTime
 	}
+	return p
+}
 
-	// Enable WAL mode for better concurrency and performance
-	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
-		db.Close()
+// OpenDB opens a SQLite database at the given path with the default
+// connection pool, foreign keys enabled, and WAL mode for better concurrency.
+func OpenDB(path string) (*sql.DB, error) {
+	return OpenDBWithPool(path, PoolConfig{})
+}
+
+// OpenDBWithPool is OpenDB with explicit pool settings. Pragmas are passed in
+// the DSN rather than executed once, because SQLite applies them per
+// connection and the pool opens several.
+func OpenDBWithPool(path string, pool PoolConfig) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", withPragmas(path))
+	if err != nil {
 		return nil, err
 	}
 
+	// Configure connection pool
+	pool = pool.withDefaults()
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
 	// Verify connection
 	if err := db.Ping(); err != nil {
 		db.Close()
@@ -50,6 +81,18 @@ func OpenDB(path string) (*sql.DB, error) {
 	return db, nil
 }
 
+// withPragmas appends the per-connection pragmas to a SQLite DSN: foreign keys
+// (required for referential integrity), WAL mode, and a busy timeout so
+// concurrent writers wait for each other instead of failing.
+func withPragmas(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(" +
+		strconv.Itoa(busyTimeoutMillis) + ")"
+}
+
 // OpenInMemory opens an in‑memory SQLite database with the same settings
 // as OpenDB. Useful for testing.
 func OpenInMemory() (*sql.DB, error) {
@@ -1,6 +1,9 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"strings"
 	"testing"
 	"time"
 )
@@ -112,3 +115,46 @@ func TestPoolReuse(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenDBWithPool(t *testing.T) {
+	db, err := OpenDBWithPool(t.TempDir()+"/pool.db", PoolConfig{MaxOpenConns: 3, MaxIdleConns: 10})
+	if err != nil {
+		t.Fatalf("OpenDBWithPool failed: %v", err)
+	}
+	defer db.Close()
+
+	if maxOpen := db.Stats().MaxOpenConnections; maxOpen != 3 {
+		t.Errorf("MaxOpenConnections = %d, want 3", maxOpen)
+	}
+
+	// Pragmas apply to every pooled connection, not just the first
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to get connection %d: %v", i, err)
+		}
+		defer conn.Close()
+		conns[i] = conn
+
+		var fkEnabled, busyTimeout int
+		if err := conn.QueryRowContext(context.Background(), "PRAGMA foreign_keys").Scan(&fkEnabled); err != nil {
+			t.Fatalf("Failed to query foreign_keys on connection %d: %v", i, err)
+		}
+		if err := conn.QueryRowContext(context.Background(), "PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+			t.Fatalf("Failed to query busy_timeout on connection %d: %v", i, err)
+		}
+		if fkEnabled != 1 || busyTimeout != busyTimeoutMillis {
+			t.Errorf("Connection %d: foreign_keys = %d, busy_timeout = %d", i, fkEnabled, busyTimeout)
+		}
+	}
+}
+
+func TestWithPragmas(t *testing.T) {
+	if got := withPragmas("data.db"); !strings.HasPrefix(got, "data.db?_pragma=foreign_keys(1)&") {
+		t.Errorf("Unexpected DSN %q", got)
+	}
+	if got := withPragmas("file:data.db?mode=ro"); !strings.HasPrefix(got, "file:data.db?mode=ro&_pragma=") {
+		t.Errorf("Unexpected DSN %q", got)
+	}
+}
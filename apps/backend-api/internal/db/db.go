@@ -12,62 +12,135 @@ func New() *Queries {
 }
 
 // Aliases for all generated types from the generated package
+type CountCosmeticItemReferencesRow = generated.CountCosmeticItemReferencesRow
+type CreateCosmeticItemParams = generated.CreateCosmeticItemParams
 type GetPrestigeCosmeticsParams = generated.GetPrestigeCosmeticsParams
 type GrantCosmeticToPlayerParams = generated.GrantCosmeticToPlayerParams
+type UpdateCosmeticItemParams = generated.UpdateCosmeticItemParams
+type ListPrestigeBackfillCandidatesParams = generated.ListPrestigeBackfillCandidatesParams
 type CreateCurrencyTransactionParams = generated.CreateCurrencyTransactionParams
 type GetCurrencyTransactionsByPlayerParams = generated.GetCurrencyTransactionsByPlayerParams
+type GetCurrencyTransactionsParams = generated.GetCurrencyTransactionsParams
+type GetCurrencyTransactionsRow = generated.GetCurrencyTransactionsRow
+type GetLatestPurchaseParams = generated.GetLatestPurchaseParams
 type GetCurrencyTransactionsByPlayerAndTypeParams = generated.GetCurrencyTransactionsByPlayerAndTypeParams
+type CountPurchasesTodayParams = generated.CountPurchasesTodayParams
+type CountRefundsAfterParams = generated.CountRefundsAfterParams
+type GetCurrencyCirculationRow = generated.GetCurrencyCirculationRow
+type GetCurrencyTotalsByTypeRow = generated.GetCurrencyTotalsByTypeRow
+type GetTopCosmeticSellersRow = generated.GetTopCosmeticSellersRow
 type AcceptFriendRequestParams = generated.AcceptFriendRequestParams
+type BlockPlayerParams = generated.BlockPlayerParams
 type CreateFriendRequestParams = generated.CreateFriendRequestParams
 type DeclineFriendRequestParams = generated.DeclineFriendRequestParams
+type DeleteFriendRelationshipParams = generated.DeleteFriendRelationshipParams
 type GetFriendRequestParams = generated.GetFriendRequestParams
+type IsBlockedBetweenParams = generated.IsBlockedBetweenParams
+type RemoveFriendParams = generated.RemoveFriendParams
+type UnblockPlayerParams = generated.UnblockPlayerParams
 type ListFriendsRow = generated.ListFriendsRow
 type ListPendingIncomingRow = generated.ListPendingIncomingRow
 type ListPendingOutgoingRow = generated.ListPendingOutgoingRow
+type ConsumeJoinTokenParams = generated.ConsumeJoinTokenParams
 type CreateJoinTokenParams = generated.CreateJoinTokenParams
+type MarkTokensUsedParams = generated.MarkTokensUsedParams
+type GetAllTimeLeaderboardParams = generated.GetAllTimeLeaderboardParams
 type GetAllTimeLeaderboardRow = generated.GetAllTimeLeaderboardRow
+type GetAllTimePlayerRankParams = generated.GetAllTimePlayerRankParams
+type GetAllTimePlayerRankRow = generated.GetAllTimePlayerRankRow
+type GetDailyLeaderboardParams = generated.GetDailyLeaderboardParams
 type GetDailyLeaderboardRow = generated.GetDailyLeaderboardRow
+type GetDailyPlayerRankParams = generated.GetDailyPlayerRankParams
+type GetDailyPlayerRankRow = generated.GetDailyPlayerRankRow
+type GetWeeklyLeaderboardParams = generated.GetWeeklyLeaderboardParams
 type GetWeeklyLeaderboardRow = generated.GetWeeklyLeaderboardRow
+type GetWeeklyPlayerRankParams = generated.GetWeeklyPlayerRankParams
+type GetWeeklyPlayerRankRow = generated.GetWeeklyPlayerRankRow
+type GetFriendsLeaderboardParams = generated.GetFriendsLeaderboardParams
+type GetFriendsLeaderboardRow = generated.GetFriendsLeaderboardRow
+type GetLeaderboardSnapshotGeneratedAtParams = generated.GetLeaderboardSnapshotGeneratedAtParams
+type ListLeaderboardSnapshotParams = generated.ListLeaderboardSnapshotParams
+type ListLeaderboardSnapshotRow = generated.ListLeaderboardSnapshotRow
+type ListFriendsLeaderboardSnapshotParams = generated.ListFriendsLeaderboardSnapshotParams
+type ListFriendsLeaderboardSnapshotRow = generated.ListFriendsLeaderboardSnapshotRow
+type GetLeaderboardSnapshotPlayerRankParams = generated.GetLeaderboardSnapshotPlayerRankParams
+type GetLeaderboardSnapshotPlayerRankRow = generated.GetLeaderboardSnapshotPlayerRankRow
+type DeleteLeaderboardSnapshotParams = generated.DeleteLeaderboardSnapshotParams
+type InsertLeaderboardSnapshotEntryParams = generated.InsertLeaderboardSnapshotEntryParams
 type CreateLoadoutParams = generated.CreateLoadoutParams
+type DeleteInactiveLoadoutParams = generated.DeleteInactiveLoadoutParams
 type DeleteLoadoutCosmeticBySlotParams = generated.DeleteLoadoutCosmeticBySlotParams
+type DeletePlayerLoadoutCosmeticParams = generated.DeletePlayerLoadoutCosmeticParams
+type GetLoadoutParams = generated.GetLoadoutParams
 type GetLoadoutCosmeticBySlotParams = generated.GetLoadoutCosmeticBySlotParams
 type GetLoadoutCosmeticsRow = generated.GetLoadoutCosmeticsRow
 type InsertLoadoutCosmeticParams = generated.InsertLoadoutCosmeticParams
 type UpdateLoadoutActiveParams = generated.UpdateLoadoutActiveParams
+type LockAccountParams = generated.LockAccountParams
 type CreateLootTableEntryParams = generated.CreateLootTableEntryParams
 type GetLootTableEntriesWithCosmeticDetailsRow = generated.GetLootTableEntriesWithCosmeticDetailsRow
+type GetLootTableEntryByCosmeticParams = generated.GetLootTableEntryByCosmeticParams
 type UpdateLootTableEntryParams = generated.UpdateLootTableEntryParams
 type CreateLootTableParams = generated.CreateLootTableParams
 type UpdateLootTableParams = generated.UpdateLootTableParams
+type SetLootPityParams = generated.SetLootPityParams
 type CreateMatchParams = generated.CreateMatchParams
+type GetMatchByIdempotencyKeyParams = generated.GetMatchByIdempotencyKeyParams
 type GetPlayerMatchHistoryParams = generated.GetPlayerMatchHistoryParams
 type GetPlayerMatchHistoryRow = generated.GetPlayerMatchHistoryRow
+type GetPlayerOutcomeCountsRow = generated.GetPlayerOutcomeCountsRow
+type GetPlayerRecentMatchTimesParams = generated.GetPlayerRecentMatchTimesParams
+type GetPlayerRecentMatchTimesRow = generated.GetPlayerRecentMatchTimesRow
 type UpdateMatchOutcomeParams = generated.UpdateMatchOutcomeParams
+type BroadcastNotificationParams = generated.BroadcastNotificationParams
+type CreateNotificationParams = generated.CreateNotificationParams
+type ListNotificationsParams = generated.ListNotificationsParams
+type MarkNotificationReadParams = generated.MarkNotificationReadParams
+type TrimUnreadNotificationsParams = generated.TrimUnreadNotificationsParams
+type CosmeticBundle = generated.CosmeticBundle
+type CosmeticBundleItem = generated.CosmeticBundleItem
 type CosmeticItem = generated.CosmeticItem
 type CurrencyTransaction = generated.CurrencyTransaction
+type EmailChangeToken = generated.EmailChangeToken
 type Friend = generated.Friend
 type JoinToken = generated.JoinToken
 type LeaderboardEntry = generated.LeaderboardEntry
+type LeaderboardSnapshot = generated.LeaderboardSnapshot
 type Loadout = generated.Loadout
 type LoadoutCosmetic = generated.LoadoutCosmetic
+type LoginFailure = generated.LoginFailure
+type LootPity = generated.LootPity
 type LootTable = generated.LootTable
 type LootTableEntry = generated.LootTableEntry
 type Match = generated.Match
+type Notification = generated.Notification
 type Player = generated.Player
 type PlayerCosmetic = generated.PlayerCosmetic
 type PlayerMatchStat = generated.PlayerMatchStat
 type PlayerProgression = generated.PlayerProgression
+type PlayerReport = generated.PlayerReport
 type PlayerSetting = generated.PlayerSetting
+type Referral = generated.Referral
 type Server = generated.Server
 type ServerFavorite = generated.ServerFavorite
+type ServerJoin = generated.ServerJoin
 type Session = generated.Session
+type BanPlayerParams = generated.BanPlayerParams
 type CreatePlayerParams = generated.CreatePlayerParams
+type SearchPlayersParams = generated.SearchPlayersParams
+type SearchPlayersRow = generated.SearchPlayersRow
+type SetPlayerAdminParams = generated.SetPlayerAdminParams
 type UpdatePlayerLastLoginParams = generated.UpdatePlayerLastLoginParams
+type UpdatePlayerEmailParams = generated.UpdatePlayerEmailParams
 type UpdatePlayerPasswordParams = generated.UpdatePlayerPasswordParams
+type CreateEmailChangeTokenParams = generated.CreateEmailChangeTokenParams
 type UpdatePlayerProfileParams = generated.UpdatePlayerProfileParams
 type GetPlayerCosmeticParams = generated.GetPlayerCosmeticParams
+type DeletePurchasedCosmeticParams = generated.DeletePurchasedCosmeticParams
 type GetPlayerCosmeticRow = generated.GetPlayerCosmeticRow
 type GetPlayerCosmeticsRow = generated.GetPlayerCosmeticsRow
+type ListFriendsOwningCosmeticParams = generated.ListFriendsOwningCosmeticParams
+type ListFriendsOwningCosmeticRow = generated.ListFriendsOwningCosmeticRow
 type CreatePlayerMatchStatsParams = generated.CreatePlayerMatchStatsParams
 type GetPlayerMatchStatsParams = generated.GetPlayerMatchStatsParams
 type AddDataCurrencyParams = generated.AddDataCurrencyParams
@@ -76,12 +149,26 @@ type IncrementMatchStatsParams = generated.IncrementMatchStatsParams
 type SetDataCurrencyParams = generated.SetDataCurrencyParams
 type UpdateLevelParams = generated.UpdateLevelParams
 type UpdatePlayerProgressionParams = generated.UpdatePlayerProgressionParams
+type UpdatePlayerSettingsPartialParams = generated.UpdatePlayerSettingsPartialParams
 type UpsertPlayerSettingsParams = generated.UpsertPlayerSettingsParams
+type CountPlayerReportsByReporterSinceParams = generated.CountPlayerReportsByReporterSinceParams
+type CreatePlayerReportParams = generated.CreatePlayerReportParams
+type ListReportedPlayersParams = generated.ListReportedPlayersParams
+type ListReportedPlayersRow = generated.ListReportedPlayersRow
+type ResolvePlayerReportsParams = generated.ResolvePlayerReportsParams
+type CreateReferralParams = generated.CreateReferralParams
 type AddFavoriteParams = generated.AddFavoriteParams
 type GetFavoriteParams = generated.GetFavoriteParams
 type ListPlayerFavoritesRow = generated.ListPlayerFavoritesRow
 type RemoveFavoriteParams = generated.RemoveFavoriteParams
+type UpdateFavoriteNoteParams = generated.UpdateFavoriteNoteParams
+type CreateServerJoinParams = generated.CreateServerJoinParams
+type ListServerJoinsParams = generated.ListServerJoinsParams
+type ListServerJoinsRow = generated.ListServerJoinsRow
+type TrimServerJoinsParams = generated.TrimServerJoinsParams
 type CreateServerParams = generated.CreateServerParams
 type ListActiveServersParams = generated.ListActiveServersParams
 type UpdateServerHeartbeatParams = generated.UpdateServerHeartbeatParams
+type UpdateServerMetadataParams = generated.UpdateServerMetadataParams
 type CreateSessionParams = generated.CreateSessionParams
+type DeleteOtherSessionsByPlayerParams = generated.DeleteOtherSessionsByPlayerParams
@@ -7,10 +7,98 @@ package generated
 
 import (
 	"context"
+
+	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
+const countCosmeticItemReferences = `-- name: CountCosmeticItemReferences :one
+SELECT
+    (SELECT COUNT(*) FROM loot_table_entries lte WHERE lte.cosmetic_id = ?1) AS loot_entries,
+    (SELECT COUNT(*) FROM player_cosmetics pc WHERE pc.cosmetic_id = ?1) AS owners,
+    (SELECT COUNT(*) FROM cosmetic_bundle_items cbi WHERE cbi.cosmetic_id = ?1) AS bundle_items
+`
+
+type CountCosmeticItemReferencesRow struct {
+	LootEntries int64 `json:"loot_entries"`
+	Owners      int64 `json:"owners"`
+	BundleItems int64 `json:"bundle_items"`
+}
+
+// Rows that would be cascade-deleted along with the cosmetic.
+func (q *Queries) CountCosmeticItemReferences(ctx context.Context, db DBTX, cosmeticID int64) (*CountCosmeticItemReferencesRow, error) {
+	row := db.QueryRowContext(ctx, countCosmeticItemReferences, cosmeticID)
+	var i CountCosmeticItemReferencesRow
+	err := row.Scan(&i.LootEntries, &i.Owners, &i.BundleItems)
+	return &i, err
+}
+
+const createCosmeticItem = `-- name: CreateCosmeticItem :one
+INSERT INTO cosmetic_items (name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, max_per_day, available_until, equip_restriction)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at, max_per_day, available_until, equip_restriction
+`
+
+type CreateCosmeticItemParams struct {
+	Name             string              `json:"name"`
+	Description      *string             `json:"description"`
+	Slot             string              `json:"slot"`
+	Category         *string             `json:"category"`
+	Rarity           string              `json:"rarity"`
+	UnlockLevel      int64               `json:"unlock_level"`
+	DataCost         int64               `json:"data_cost"`
+	IsPrestigeOnly   int64               `json:"is_prestige_only"`
+	MaxPerDay        *int64              `json:"max_per_day"`
+	AvailableUntil   types.NullTimestamp `json:"available_until"`
+	EquipRestriction *string             `json:"equip_restriction"`
+}
+
+func (q *Queries) CreateCosmeticItem(ctx context.Context, db DBTX, arg *CreateCosmeticItemParams) (*CosmeticItem, error) {
+	row := db.QueryRowContext(ctx, createCosmeticItem,
+		arg.Name,
+		arg.Description,
+		arg.Slot,
+		arg.Category,
+		arg.Rarity,
+		arg.UnlockLevel,
+		arg.DataCost,
+		arg.IsPrestigeOnly,
+		arg.MaxPerDay,
+		arg.AvailableUntil,
+		arg.EquipRestriction,
+	)
+	var i CosmeticItem
+	err := row.Scan(
+		&i.CosmeticID,
+		&i.Name,
+		&i.Description,
+		&i.Slot,
+		&i.Category,
+		&i.Rarity,
+		&i.UnlockLevel,
+		&i.DataCost,
+		&i.IsPrestigeOnly,
+		&i.CreatedAt,
+		&i.MaxPerDay,
+		&i.AvailableUntil,
+		&i.EquipRestriction,
+	)
+	return &i, err
+}
+
+const deleteCosmeticItem = `-- name: DeleteCosmeticItem :execrows
+DELETE FROM cosmetic_items WHERE cosmetic_id = ?
+`
+
+func (q *Queries) DeleteCosmeticItem(ctx context.Context, db DBTX, cosmeticID int64) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteCosmeticItem, cosmeticID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getCosmeticCatalog = `-- name: GetCosmeticCatalog :many
-SELECT cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at FROM cosmetic_items
+SELECT cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at, max_per_day, available_until, equip_restriction FROM cosmetic_items
 ORDER BY cosmetic_id
 `
 
@@ -34,6 +122,9 @@ func (q *Queries) GetCosmeticCatalog(ctx context.Context, db DBTX) ([]*CosmeticI
 			&i.DataCost,
 			&i.IsPrestigeOnly,
 			&i.CreatedAt,
+			&i.MaxPerDay,
+			&i.AvailableUntil,
+			&i.EquipRestriction,
 		); err != nil {
 			return nil, err
 		}
@@ -48,8 +139,37 @@ func (q *Queries) GetCosmeticCatalog(ctx context.Context, db DBTX) ([]*CosmeticI
 	return items, nil
 }
 
+const getCosmeticItemByName = `-- name: GetCosmeticItemByName :one
+SELECT cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at, max_per_day, available_until, equip_restriction FROM cosmetic_items
+WHERE name = ?
+ORDER BY cosmetic_id
+LIMIT 1
+`
+
+// Names aren't unique; the oldest item wins, which keeps seeding stable.
+func (q *Queries) GetCosmeticItemByName(ctx context.Context, db DBTX, name string) (*CosmeticItem, error) {
+	row := db.QueryRowContext(ctx, getCosmeticItemByName, name)
+	var i CosmeticItem
+	err := row.Scan(
+		&i.CosmeticID,
+		&i.Name,
+		&i.Description,
+		&i.Slot,
+		&i.Category,
+		&i.Rarity,
+		&i.UnlockLevel,
+		&i.DataCost,
+		&i.IsPrestigeOnly,
+		&i.CreatedAt,
+		&i.MaxPerDay,
+		&i.AvailableUntil,
+		&i.EquipRestriction,
+	)
+	return &i, err
+}
+
 const getPrestigeCosmetics = `-- name: GetPrestigeCosmetics :many
-SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at FROM cosmetic_items ci
+SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at, ci.max_per_day, ci.available_until, ci.equip_restriction FROM cosmetic_items ci
 LEFT JOIN player_cosmetics pc ON ci.cosmetic_id = pc.cosmetic_id AND pc.player_id = ?1
 WHERE ci.is_prestige_only = 1
     AND ci.unlock_level <= ?2
@@ -81,6 +201,9 @@ func (q *Queries) GetPrestigeCosmetics(ctx context.Context, db DBTX, arg *GetPre
 			&i.DataCost,
 			&i.IsPrestigeOnly,
 			&i.CreatedAt,
+			&i.MaxPerDay,
+			&i.AvailableUntil,
+			&i.EquipRestriction,
 		); err != nil {
 			return nil, err
 		}
@@ -110,3 +233,105 @@ func (q *Queries) GrantCosmeticToPlayer(ctx context.Context, db DBTX, arg *Grant
 	_, err := db.ExecContext(ctx, grantCosmeticToPlayer, arg.PlayerID, arg.CosmeticID, arg.UnlockedVia)
 	return err
 }
+
+const listPrestigeBackfillCandidates = `-- name: ListPrestigeBackfillCandidates :many
+SELECT pp.player_id FROM player_progression pp
+LEFT JOIN player_cosmetics pc ON pc.player_id = pp.player_id AND pc.cosmetic_id = ?1
+WHERE pp.prestige_level >= ?2
+    AND pc.cosmetic_id IS NULL
+    AND pp.player_id > ?3
+ORDER BY pp.player_id
+LIMIT ?4
+`
+
+type ListPrestigeBackfillCandidatesParams struct {
+	CosmeticID    int64 `json:"cosmetic_id"`
+	PrestigeLevel int64 `json:"prestige_level"`
+	PlayerID      int64 `json:"player_id"`
+	Limit         int64 `json:"limit"`
+}
+
+func (q *Queries) ListPrestigeBackfillCandidates(ctx context.Context, db DBTX, arg *ListPrestigeBackfillCandidatesParams) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, listPrestigeBackfillCandidates,
+		arg.CosmeticID,
+		arg.PrestigeLevel,
+		arg.PlayerID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var player_id int64
+		if err := rows.Scan(&player_id); err != nil {
+			return nil, err
+		}
+		items = append(items, player_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCosmeticItem = `-- name: UpdateCosmeticItem :one
+UPDATE cosmetic_items
+SET name = ?, description = ?, slot = ?, category = ?, rarity = ?, unlock_level = ?,
+    data_cost = ?, is_prestige_only = ?, max_per_day = ?, available_until = ?, equip_restriction = ?
+WHERE cosmetic_id = ?
+RETURNING cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at, max_per_day, available_until, equip_restriction
+`
+
+type UpdateCosmeticItemParams struct {
+	Name             string              `json:"name"`
+	Description      *string             `json:"description"`
+	Slot             string              `json:"slot"`
+	Category         *string             `json:"category"`
+	Rarity           string              `json:"rarity"`
+	UnlockLevel      int64               `json:"unlock_level"`
+	DataCost         int64               `json:"data_cost"`
+	IsPrestigeOnly   int64               `json:"is_prestige_only"`
+	MaxPerDay        *int64              `json:"max_per_day"`
+	AvailableUntil   types.NullTimestamp `json:"available_until"`
+	EquipRestriction *string             `json:"equip_restriction"`
+	CosmeticID       int64               `json:"cosmetic_id"`
+}
+
+func (q *Queries) UpdateCosmeticItem(ctx context.Context, db DBTX, arg *UpdateCosmeticItemParams) (*CosmeticItem, error) {
+	row := db.QueryRowContext(ctx, updateCosmeticItem,
+		arg.Name,
+		arg.Description,
+		arg.Slot,
+		arg.Category,
+		arg.Rarity,
+		arg.UnlockLevel,
+		arg.DataCost,
+		arg.IsPrestigeOnly,
+		arg.MaxPerDay,
+		arg.AvailableUntil,
+		arg.EquipRestriction,
+		arg.CosmeticID,
+	)
+	var i CosmeticItem
+	err := row.Scan(
+		&i.CosmeticID,
+		&i.Name,
+		&i.Description,
+		&i.Slot,
+		&i.Category,
+		&i.Rarity,
+		&i.UnlockLevel,
+		&i.DataCost,
+		&i.IsPrestigeOnly,
+		&i.CreatedAt,
+		&i.MaxPerDay,
+		&i.AvailableUntil,
+		&i.EquipRestriction,
+	)
+	return &i, err
+}
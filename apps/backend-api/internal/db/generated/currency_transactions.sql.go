@@ -7,6 +7,8 @@ package generated
 
 import (
 	"context"
+
+	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
 const countCurrencyTransactionsByPlayer = `-- name: CountCurrencyTransactionsByPlayer :one
@@ -20,6 +22,49 @@ func (q *Queries) CountCurrencyTransactionsByPlayer(ctx context.Context, db DBTX
 	return count, err
 }
 
+const countPurchasesToday = `-- name: CountPurchasesToday :one
+SELECT COUNT(*) FROM currency_transactions
+WHERE player_id = ?
+    AND reference_id = ?
+    AND transaction_type = 'purchase'
+    AND created_at >= strftime('%Y-%m-%dT00:00:00Z', 'now')
+`
+
+type CountPurchasesTodayParams struct {
+	PlayerID    int64  `json:"player_id"`
+	ReferenceID *int64 `json:"reference_id"`
+}
+
+func (q *Queries) CountPurchasesToday(ctx context.Context, db DBTX, arg *CountPurchasesTodayParams) (int64, error) {
+	row := db.QueryRowContext(ctx, countPurchasesToday, arg.PlayerID, arg.ReferenceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRefundsAfter = `-- name: CountRefundsAfter :one
+SELECT COUNT(*) FROM currency_transactions
+WHERE player_id = ?
+    AND reference_id = ?
+    AND transaction_type = 'refund'
+    AND transaction_id > ?
+`
+
+type CountRefundsAfterParams struct {
+	PlayerID      int64  `json:"player_id"`
+	ReferenceID   *int64 `json:"reference_id"`
+	TransactionID int64  `json:"transaction_id"`
+}
+
+// Counts refunds of a reference recorded after the given transaction, so an
+// undone purchase is not refunded again.
+func (q *Queries) CountRefundsAfter(ctx context.Context, db DBTX, arg *CountRefundsAfterParams) (int64, error) {
+	row := db.QueryRowContext(ctx, countRefundsAfter, arg.PlayerID, arg.ReferenceID, arg.TransactionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCurrencyTransaction = `-- name: CreateCurrencyTransaction :exec
 INSERT INTO currency_transactions (player_id, amount, balance_after, transaction_type, reference_id)
 VALUES (?, ?, ?, ?, ?)
@@ -44,6 +89,59 @@ func (q *Queries) CreateCurrencyTransaction(ctx context.Context, db DBTX, arg *C
 	return err
 }
 
+const getCurrencyTransactions = `-- name: GetCurrencyTransactions :many
+SELECT transaction_id, amount, balance_after, transaction_type, reference_id, created_at
+FROM currency_transactions
+WHERE player_id = ?
+ORDER BY created_at DESC, transaction_id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetCurrencyTransactionsParams struct {
+	PlayerID int64 `json:"player_id"`
+	Limit    int64 `json:"limit"`
+	Offset   int64 `json:"offset"`
+}
+
+type GetCurrencyTransactionsRow struct {
+	TransactionID   int64           `json:"transaction_id"`
+	Amount          int64           `json:"amount"`
+	BalanceAfter    int64           `json:"balance_after"`
+	TransactionType string          `json:"transaction_type"`
+	ReferenceID     *int64          `json:"reference_id"`
+	CreatedAt       types.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) GetCurrencyTransactions(ctx context.Context, db DBTX, arg *GetCurrencyTransactionsParams) ([]*GetCurrencyTransactionsRow, error) {
+	rows, err := db.QueryContext(ctx, getCurrencyTransactions, arg.PlayerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetCurrencyTransactionsRow{}
+	for rows.Next() {
+		var i GetCurrencyTransactionsRow
+		if err := rows.Scan(
+			&i.TransactionID,
+			&i.Amount,
+			&i.BalanceAfter,
+			&i.TransactionType,
+			&i.ReferenceID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCurrencyTransactionsByPlayer = `-- name: GetCurrencyTransactionsByPlayer :many
 SELECT transaction_id, player_id, amount, balance_after, transaction_type, reference_id, created_at FROM currency_transactions WHERE player_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
@@ -131,3 +229,30 @@ func (q *Queries) GetCurrencyTransactionsByPlayerAndType(ctx context.Context, db
 	}
 	return items, nil
 }
+
+const getLatestPurchase = `-- name: GetLatestPurchase :one
+SELECT transaction_id, player_id, amount, balance_after, transaction_type, reference_id, created_at FROM currency_transactions
+WHERE player_id = ? AND reference_id = ? AND transaction_type = 'purchase'
+ORDER BY created_at DESC, transaction_id DESC
+LIMIT 1
+`
+
+type GetLatestPurchaseParams struct {
+	PlayerID    int64  `json:"player_id"`
+	ReferenceID *int64 `json:"reference_id"`
+}
+
+func (q *Queries) GetLatestPurchase(ctx context.Context, db DBTX, arg *GetLatestPurchaseParams) (*CurrencyTransaction, error) {
+	row := db.QueryRowContext(ctx, getLatestPurchase, arg.PlayerID, arg.ReferenceID)
+	var i CurrencyTransaction
+	err := row.Scan(
+		&i.TransactionID,
+		&i.PlayerID,
+		&i.Amount,
+		&i.BalanceAfter,
+		&i.TransactionType,
+		&i.ReferenceID,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
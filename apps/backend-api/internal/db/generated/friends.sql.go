@@ -27,8 +27,25 @@ func (q *Queries) AcceptFriendRequest(ctx context.Context, db DBTX, arg *AcceptF
 	return err
 }
 
-const createFriendRequest = `-- name: CreateFriendRequest :exec
+const blockPlayer = `-- name: BlockPlayer :exec
+INSERT INTO friends (player_id, friend_id, status) VALUES (?1, ?2, 'blocked')
+ON CONFLICT (player_id, friend_id) DO UPDATE
+SET status = 'blocked', updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+`
+
+type BlockPlayerParams struct {
+	PlayerID int64 `json:"player_id"`
+	FriendID int64 `json:"friend_id"`
+}
+
+func (q *Queries) BlockPlayer(ctx context.Context, db DBTX, arg *BlockPlayerParams) error {
+	_, err := db.ExecContext(ctx, blockPlayer, arg.PlayerID, arg.FriendID)
+	return err
+}
+
+const createFriendRequest = `-- name: CreateFriendRequest :execrows
 INSERT INTO friends (player_id, friend_id, status) VALUES (?1, ?2, 'pending')
+ON CONFLICT (player_id, friend_id) DO NOTHING
 `
 
 type CreateFriendRequestParams struct {
@@ -36,9 +53,14 @@ type CreateFriendRequestParams struct {
 	FriendID int64 `json:"friend_id"`
 }
 
-func (q *Queries) CreateFriendRequest(ctx context.Context, db DBTX, arg *CreateFriendRequestParams) error {
-	_, err := db.ExecContext(ctx, createFriendRequest, arg.PlayerID, arg.FriendID)
-	return err
+// Inserts nothing when a row for the pair already exists, so callers can tell
+// from the row count without a racy existence check.
+func (q *Queries) CreateFriendRequest(ctx context.Context, db DBTX, arg *CreateFriendRequestParams) (int64, error) {
+	result, err := db.ExecContext(ctx, createFriendRequest, arg.PlayerID, arg.FriendID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const declineFriendRequest = `-- name: DeclineFriendRequest :exec
@@ -56,6 +78,23 @@ func (q *Queries) DeclineFriendRequest(ctx context.Context, db DBTX, arg *Declin
 	return err
 }
 
+const deleteFriendRelationship = `-- name: DeleteFriendRelationship :exec
+DELETE FROM friends
+WHERE ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+  AND status != 'blocked'
+`
+
+type DeleteFriendRelationshipParams struct {
+	PlayerID int64 `json:"player_id"`
+	FriendID int64 `json:"friend_id"`
+}
+
+// Removes pending and accepted rows in either direction, leaving blocks in place.
+func (q *Queries) DeleteFriendRelationship(ctx context.Context, db DBTX, arg *DeleteFriendRelationshipParams) error {
+	_, err := db.ExecContext(ctx, deleteFriendRelationship, arg.PlayerID, arg.FriendID)
+	return err
+}
+
 const getFriendRequest = `-- name: GetFriendRequest :one
 SELECT player_id, friend_id, status, created_at, updated_at FROM friends WHERE player_id = ?1 AND friend_id = ?2
 `
@@ -78,22 +117,46 @@ func (q *Queries) GetFriendRequest(ctx context.Context, db DBTX, arg *GetFriendR
 	return &i, err
 }
 
+const isBlockedBetween = `-- name: IsBlockedBetween :one
+SELECT CAST(EXISTS (
+  SELECT 1 FROM friends
+  WHERE status = 'blocked'
+    AND ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+) AS INTEGER) AS blocked
+`
+
+type IsBlockedBetweenParams struct {
+	PlayerID int64 `json:"player_id"`
+	FriendID int64 `json:"friend_id"`
+}
+
+// Reports whether either player has blocked the other.
+func (q *Queries) IsBlockedBetween(ctx context.Context, db DBTX, arg *IsBlockedBetweenParams) (int64, error) {
+	row := db.QueryRowContext(ctx, isBlockedBetween, arg.PlayerID, arg.FriendID)
+	var blocked int64
+	err := row.Scan(&blocked)
+	return blocked, err
+}
+
 const listFriends = `-- name: ListFriends :many
-SELECT 
-  CAST(CASE 
-    WHEN f.player_id = ?1 THEN f.friend_id 
-    ELSE f.player_id 
-  END AS INTEGER) AS friend_player_id,
+SELECT
+  fr.friend_player_id,
   p.username AS friend_username,
-  f.status,
-  f.created_at,
-  f.updated_at
-FROM friends f
-JOIN players p ON p.player_id = CAST(CASE 
-    WHEN f.player_id = ?1 THEN f.friend_id 
-    ELSE f.player_id 
-  END AS INTEGER)
-WHERE (f.player_id = ?1 OR f.friend_id = ?1) AND f.status = 'accepted'
+  fr.status,
+  MIN(fr.created_at) AS created_at,
+  MAX(fr.updated_at) AS updated_at
+FROM (
+  SELECT f.friend_id AS friend_player_id, f.status, f.created_at, f.updated_at
+  FROM friends f
+  WHERE f.player_id = ?1 AND f.status = 'accepted'
+  UNION
+  SELECT f.player_id AS friend_player_id, f.status, f.created_at, f.updated_at
+  FROM friends f
+  WHERE f.friend_id = ?1 AND f.status = 'accepted'
+) fr
+JOIN players p ON p.player_id = fr.friend_player_id
+GROUP BY fr.friend_player_id
+ORDER BY p.username
 `
 
 type ListFriendsRow struct {
@@ -104,6 +167,8 @@ type ListFriendsRow struct {
 	UpdatedAt      types.Timestamp `json:"updated_at"`
 }
 
+// Accepted friends regardless of which side sent the request. If both players
+// requested each other and both rows were accepted, the friend is listed once.
 func (q *Queries) ListFriends(ctx context.Context, db DBTX, playerID int64) ([]*ListFriendsRow, error) {
 	rows, err := db.QueryContext(ctx, listFriends, playerID)
 	if err != nil {
@@ -204,3 +269,41 @@ func (q *Queries) ListPendingOutgoing(ctx context.Context, db DBTX, playerID int
 	}
 	return items, nil
 }
+
+const removeFriend = `-- name: RemoveFriend :execrows
+DELETE FROM friends
+WHERE ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+  AND status = 'accepted'
+`
+
+type RemoveFriendParams struct {
+	PlayerID int64 `json:"player_id"`
+	FriendID int64 `json:"friend_id"`
+}
+
+// Removes an accepted friendship, whichever direction (or both) it was stored in.
+func (q *Queries) RemoveFriend(ctx context.Context, db DBTX, arg *RemoveFriendParams) (int64, error) {
+	result, err := db.ExecContext(ctx, removeFriend, arg.PlayerID, arg.FriendID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unblockPlayer = `-- name: UnblockPlayer :execrows
+DELETE FROM friends
+WHERE player_id = ?1 AND friend_id = ?2 AND status = 'blocked'
+`
+
+type UnblockPlayerParams struct {
+	PlayerID int64 `json:"player_id"`
+	FriendID int64 `json:"friend_id"`
+}
+
+func (q *Queries) UnblockPlayer(ctx context.Context, db DBTX, arg *UnblockPlayerParams) (int64, error) {
+	result, err := db.ExecContext(ctx, unblockPlayer, arg.PlayerID, arg.FriendID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
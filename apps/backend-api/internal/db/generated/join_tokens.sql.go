@@ -7,10 +7,43 @@ package generated
 
 import (
 	"context"
+	"strings"
 
 	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
+const consumeJoinToken = `-- name: ConsumeJoinToken :one
+UPDATE join_tokens
+SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE token = ?
+  AND server_id = ?
+  AND used_at IS NULL
+  AND expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+RETURNING join_token_id, token, player_id, server_id, expires_at, created_at, used_at
+`
+
+type ConsumeJoinTokenParams struct {
+	Token    string `json:"token"`
+	ServerID int64  `json:"server_id"`
+}
+
+// Validates and consumes a token in one statement so that concurrent
+// validations of the same token cannot both succeed.
+func (q *Queries) ConsumeJoinToken(ctx context.Context, db DBTX, arg *ConsumeJoinTokenParams) (*JoinToken, error) {
+	row := db.QueryRowContext(ctx, consumeJoinToken, arg.Token, arg.ServerID)
+	var i JoinToken
+	err := row.Scan(
+		&i.JoinTokenID,
+		&i.Token,
+		&i.PlayerID,
+		&i.ServerID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UsedAt,
+	)
+	return &i, err
+}
+
 const createJoinToken = `-- name: CreateJoinToken :one
 INSERT INTO join_tokens (
     token,
@@ -59,6 +92,16 @@ func (q *Queries) DeleteExpiredTokens(ctx context.Context, db DBTX) error {
 	return err
 }
 
+const deleteServerJoinTokens = `-- name: DeleteServerJoinTokens :exec
+DELETE FROM join_tokens
+WHERE server_id = ?
+`
+
+func (q *Queries) DeleteServerJoinTokens(ctx context.Context, db DBTX, serverID int64) error {
+	_, err := db.ExecContext(ctx, deleteServerJoinTokens, serverID)
+	return err
+}
+
 const getJoinToken = `-- name: GetJoinToken :one
 SELECT join_token_id, token, player_id, server_id, expires_at, created_at, used_at FROM join_tokens WHERE token = ?
 `
@@ -110,3 +153,36 @@ func (q *Queries) MarkTokenUsed(ctx context.Context, db DBTX, token string) erro
 	_, err := db.ExecContext(ctx, markTokenUsed, token)
 	return err
 }
+
+const markTokensUsed = `-- name: MarkTokensUsed :execrows
+UPDATE join_tokens
+SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE server_id = ?
+  AND token IN (/*SLICE:tokens*/?)
+  AND used_at IS NULL
+  AND expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+`
+
+type MarkTokensUsedParams struct {
+	ServerID int64    `json:"server_id"`
+	Tokens   []string `json:"tokens"`
+}
+
+func (q *Queries) MarkTokensUsed(ctx context.Context, db DBTX, arg *MarkTokensUsedParams) (int64, error) {
+	query := markTokensUsed
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.ServerID)
+	if len(arg.Tokens) > 0 {
+		for _, v := range arg.Tokens {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:tokens*/?", strings.Repeat(",?", len(arg.Tokens))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:tokens*/?", "NULL", 1)
+	}
+	result, err := db.ExecContext(ctx, query, queryParams...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
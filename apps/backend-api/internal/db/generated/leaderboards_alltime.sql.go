@@ -17,13 +17,33 @@ SELECT
   COUNT(pms.match_id) AS matches_played,
   AVG(pms.zombies_killed) AS avg_kills_per_match,
   AVG(pms.waves_survived) AS avg_waves_survived,
-  CAST(ROW_NUMBER() OVER (ORDER BY SUM(pms.score) DESC) AS INTEGER) AS ranking
+  CAST(CASE ?1
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END AS INTEGER) AS metric_value,
+  CAST(ROW_NUMBER() OVER (ORDER BY CASE ?1
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END DESC) AS INTEGER) AS ranking
 FROM player_match_stats pms
 JOIN players p ON pms.player_id = p.player_id
+LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+WHERE COALESCE(ps.show_on_leaderboard, 1) = 1
 GROUP BY pms.player_id
-ORDER BY total_score DESC
+ORDER BY ranking
+LIMIT ?2 OFFSET ?3
 `
 
+type GetAllTimeLeaderboardParams struct {
+	Metric string `json:"metric"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
 type GetAllTimeLeaderboardRow struct {
 	PlayerID         int64    `json:"player_id"`
 	Username         string   `json:"username"`
@@ -31,11 +51,14 @@ type GetAllTimeLeaderboardRow struct {
 	MatchesPlayed    int64    `json:"matches_played"`
 	AvgKillsPerMatch *float64 `json:"avg_kills_per_match"`
 	AvgWavesSurvived *float64 `json:"avg_waves_survived"`
+	MetricValue      int64    `json:"metric_value"`
 	Ranking          int64    `json:"ranking"`
 }
 
-func (q *Queries) GetAllTimeLeaderboard(ctx context.Context, db DBTX) ([]*GetAllTimeLeaderboardRow, error) {
-	rows, err := db.QueryContext(ctx, getAllTimeLeaderboard)
+// metric selects the ranked column (score, kills, waves_survived or data_earned); the
+// service whitelists it and any other value ranks by score.
+func (q *Queries) GetAllTimeLeaderboard(ctx context.Context, db DBTX, arg *GetAllTimeLeaderboardParams) ([]*GetAllTimeLeaderboardRow, error) {
+	rows, err := db.QueryContext(ctx, getAllTimeLeaderboard, arg.Metric, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +73,7 @@ func (q *Queries) GetAllTimeLeaderboard(ctx context.Context, db DBTX) ([]*GetAll
 			&i.MatchesPlayed,
 			&i.AvgKillsPerMatch,
 			&i.AvgWavesSurvived,
+			&i.MetricValue,
 			&i.Ranking,
 		); err != nil {
 			return nil, err
@@ -64,3 +88,39 @@ func (q *Queries) GetAllTimeLeaderboard(ctx context.Context, db DBTX) ([]*GetAll
 	}
 	return items, nil
 }
+
+const getAllTimePlayerRank = `-- name: GetAllTimePlayerRank :one
+WITH ranked AS (
+  SELECT
+    pms.player_id,
+    CAST(ROW_NUMBER() OVER (ORDER BY CASE ?1
+      WHEN 'kills' THEN SUM(pms.zombies_killed)
+      WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+      WHEN 'data_earned' THEN SUM(pms.data_earned)
+      ELSE SUM(pms.score)
+    END DESC) AS INTEGER) AS ranking,
+    CAST(COUNT(*) OVER () AS INTEGER) AS total_players
+  FROM player_match_stats pms
+  LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+  WHERE COALESCE(ps.show_on_leaderboard, 1) = 1
+  GROUP BY pms.player_id
+)
+SELECT ranking, total_players FROM ranked WHERE player_id = ?2
+`
+
+type GetAllTimePlayerRankParams struct {
+	Metric   string `json:"metric"`
+	PlayerID int64  `json:"player_id"`
+}
+
+type GetAllTimePlayerRankRow struct {
+	Ranking      int64 `json:"ranking"`
+	TotalPlayers int64 `json:"total_players"`
+}
+
+func (q *Queries) GetAllTimePlayerRank(ctx context.Context, db DBTX, arg *GetAllTimePlayerRankParams) (*GetAllTimePlayerRankRow, error) {
+	row := db.QueryRowContext(ctx, getAllTimePlayerRank, arg.Metric, arg.PlayerID)
+	var i GetAllTimePlayerRankRow
+	err := row.Scan(&i.Ranking, &i.TotalPlayers)
+	return &i, err
+}
@@ -7,6 +7,8 @@ package generated
 
 import (
 	"context"
+
+	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
 const getDailyLeaderboard = `-- name: GetDailyLeaderboard :many
@@ -17,15 +19,38 @@ SELECT
   COUNT(pms.match_id) AS matches_played,
   AVG(pms.zombies_killed) AS avg_kills_per_match,
   AVG(pms.waves_survived) AS avg_waves_survived,
-  CAST(ROW_NUMBER() OVER (ORDER BY SUM(pms.score) DESC) AS INTEGER) AS ranking
+  CAST(CASE ?1
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END AS INTEGER) AS metric_value,
+  CAST(ROW_NUMBER() OVER (ORDER BY CASE ?1
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END DESC) AS INTEGER) AS ranking
 FROM player_match_stats pms
 JOIN matches m ON pms.match_id = m.match_id
 JOIN players p ON pms.player_id = p.player_id
-WHERE date(m.start_time) = date('now')
+LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+WHERE m.start_time >= ?2
+  AND m.start_time < ?3
+  AND COALESCE(ps.show_on_leaderboard, 1) = 1
 GROUP BY pms.player_id
-ORDER BY total_score DESC
+ORDER BY ranking
+LIMIT ?4 OFFSET ?5
 `
 
+type GetDailyLeaderboardParams struct {
+	Metric string          `json:"metric"`
+	Since  types.Timestamp `json:"since"`
+	Until  types.Timestamp `json:"until"`
+	Limit  int64           `json:"limit"`
+	Offset int64           `json:"offset"`
+}
+
 type GetDailyLeaderboardRow struct {
 	PlayerID         int64    `json:"player_id"`
 	Username         string   `json:"username"`
@@ -33,11 +58,16 @@ type GetDailyLeaderboardRow struct {
 	MatchesPlayed    int64    `json:"matches_played"`
 	AvgKillsPerMatch *float64 `json:"avg_kills_per_match"`
 	AvgWavesSurvived *float64 `json:"avg_waves_survived"`
+	MetricValue      int64    `json:"metric_value"`
 	Ranking          int64    `json:"ranking"`
 }
 
-func (q *Queries) GetDailyLeaderboard(ctx context.Context, db DBTX) ([]*GetDailyLeaderboardRow, error) {
-	rows, err := db.QueryContext(ctx, getDailyLeaderboard)
+// start_time is compared as an RFC 3339 string so idx_matches_start_time can serve the range.
+// The service passes the current UTC day as the half-open window [since, until).
+// metric selects the ranked column (score, kills, waves_survived or data_earned); the
+// service whitelists it and any other value ranks by score.
+func (q *Queries) GetDailyLeaderboard(ctx context.Context, db DBTX, arg *GetDailyLeaderboardParams) ([]*GetDailyLeaderboardRow, error) {
+	rows, err := db.QueryContext(ctx, getDailyLeaderboard, arg.Metric, arg.Since, arg.Until, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +82,7 @@ func (q *Queries) GetDailyLeaderboard(ctx context.Context, db DBTX) ([]*GetDaily
 			&i.MatchesPlayed,
 			&i.AvgKillsPerMatch,
 			&i.AvgWavesSurvived,
+			&i.MetricValue,
 			&i.Ranking,
 		); err != nil {
 			return nil, err
@@ -66,3 +97,44 @@ func (q *Queries) GetDailyLeaderboard(ctx context.Context, db DBTX) ([]*GetDaily
 	}
 	return items, nil
 }
+
+const getDailyPlayerRank = `-- name: GetDailyPlayerRank :one
+WITH ranked AS (
+  SELECT
+    pms.player_id,
+    CAST(ROW_NUMBER() OVER (ORDER BY CASE ?1
+      WHEN 'kills' THEN SUM(pms.zombies_killed)
+      WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+      WHEN 'data_earned' THEN SUM(pms.data_earned)
+      ELSE SUM(pms.score)
+    END DESC) AS INTEGER) AS ranking,
+    CAST(COUNT(*) OVER () AS INTEGER) AS total_players
+  FROM player_match_stats pms
+  JOIN matches m ON pms.match_id = m.match_id
+  LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+  WHERE m.start_time >= ?2
+    AND m.start_time < ?3
+    AND COALESCE(ps.show_on_leaderboard, 1) = 1
+  GROUP BY pms.player_id
+)
+SELECT ranking, total_players FROM ranked WHERE player_id = ?4
+`
+
+type GetDailyPlayerRankParams struct {
+	Metric   string          `json:"metric"`
+	Since    types.Timestamp `json:"since"`
+	Until    types.Timestamp `json:"until"`
+	PlayerID int64           `json:"player_id"`
+}
+
+type GetDailyPlayerRankRow struct {
+	Ranking      int64 `json:"ranking"`
+	TotalPlayers int64 `json:"total_players"`
+}
+
+func (q *Queries) GetDailyPlayerRank(ctx context.Context, db DBTX, arg *GetDailyPlayerRankParams) (*GetDailyPlayerRankRow, error) {
+	row := db.QueryRowContext(ctx, getDailyPlayerRank, arg.Metric, arg.Since, arg.Until, arg.PlayerID)
+	var i GetDailyPlayerRankRow
+	err := row.Scan(&i.Ranking, &i.TotalPlayers)
+	return &i, err
+}
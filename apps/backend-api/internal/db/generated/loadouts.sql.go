@@ -9,8 +9,18 @@ import (
 	"context"
 )
 
-const createLoadout = `-- name: CreateLoadout :exec
+const clearLoadoutCosmetics = `-- name: ClearLoadoutCosmetics :exec
+DELETE FROM loadout_cosmetics WHERE loadout_id = ?
+`
+
+func (q *Queries) ClearLoadoutCosmetics(ctx context.Context, db DBTX, loadoutID int64) error {
+	_, err := db.ExecContext(ctx, clearLoadoutCosmetics, loadoutID)
+	return err
+}
+
+const createLoadout = `-- name: CreateLoadout :one
 INSERT INTO loadouts (player_id, name, is_active) VALUES (?, ?, ?)
+RETURNING loadout_id, player_id, name, is_active, created_at, updated_at
 `
 
 type CreateLoadoutParams struct {
@@ -19,11 +29,48 @@ type CreateLoadoutParams struct {
 	IsActive int64  `json:"is_active"`
 }
 
-func (q *Queries) CreateLoadout(ctx context.Context, db DBTX, arg *CreateLoadoutParams) error {
-	_, err := db.ExecContext(ctx, createLoadout, arg.PlayerID, arg.Name, arg.IsActive)
+func (q *Queries) CreateLoadout(ctx context.Context, db DBTX, arg *CreateLoadoutParams) (*Loadout, error) {
+	row := db.QueryRowContext(ctx, createLoadout, arg.PlayerID, arg.Name, arg.IsActive)
+	var i Loadout
+	err := row.Scan(
+		&i.LoadoutID,
+		&i.PlayerID,
+		&i.Name,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const deactivateLoadouts = `-- name: DeactivateLoadouts :exec
+UPDATE loadouts
+SET is_active = 0, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE player_id = ? AND is_active = 1
+`
+
+func (q *Queries) DeactivateLoadouts(ctx context.Context, db DBTX, playerID int64) error {
+	_, err := db.ExecContext(ctx, deactivateLoadouts, playerID)
 	return err
 }
 
+const deleteInactiveLoadout = `-- name: DeleteInactiveLoadout :execrows
+DELETE FROM loadouts WHERE loadout_id = ? AND player_id = ? AND is_active = 0
+`
+
+type DeleteInactiveLoadoutParams struct {
+	LoadoutID int64 `json:"loadout_id"`
+	PlayerID  int64 `json:"player_id"`
+}
+
+func (q *Queries) DeleteInactiveLoadout(ctx context.Context, db DBTX, arg *DeleteInactiveLoadoutParams) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteInactiveLoadout, arg.LoadoutID, arg.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteLoadoutCosmeticBySlot = `-- name: DeleteLoadoutCosmeticBySlot :exec
 DELETE FROM loadout_cosmetics WHERE loadout_id = ? AND slot = ?
 `
@@ -38,6 +85,34 @@ func (q *Queries) DeleteLoadoutCosmeticBySlot(ctx context.Context, db DBTX, arg
 	return err
 }
 
+const deletePlayerLoadoutCosmetic = `-- name: DeletePlayerLoadoutCosmetic :exec
+DELETE FROM loadout_cosmetics
+WHERE cosmetic_id = ?
+    AND loadout_id IN (SELECT loadout_id FROM loadouts WHERE player_id = ?)
+`
+
+type DeletePlayerLoadoutCosmeticParams struct {
+	CosmeticID int64 `json:"cosmetic_id"`
+	PlayerID   int64 `json:"player_id"`
+}
+
+func (q *Queries) DeletePlayerLoadoutCosmetic(ctx context.Context, db DBTX, arg *DeletePlayerLoadoutCosmeticParams) error {
+	_, err := db.ExecContext(ctx, deletePlayerLoadoutCosmetic, arg.CosmeticID, arg.PlayerID)
+	return err
+}
+
+const ensureActiveLoadout = `-- name: EnsureActiveLoadout :exec
+INSERT INTO loadouts (player_id, name, is_active) VALUES (?, 'Default', 1)
+ON CONFLICT DO NOTHING
+`
+
+// Creates an active "Default" loadout unless the player already has one; the
+// one-active-loadout index turns a concurrent duplicate into a no-op.
+func (q *Queries) EnsureActiveLoadout(ctx context.Context, db DBTX, playerID int64) error {
+	_, err := db.ExecContext(ctx, ensureActiveLoadout, playerID)
+	return err
+}
+
 const getActiveLoadout = `-- name: GetActiveLoadout :one
 SELECT loadout_id, player_id, name, is_active, created_at, updated_at FROM loadouts WHERE player_id = ? AND is_active = 1
 `
@@ -57,7 +132,7 @@ func (q *Queries) GetActiveLoadout(ctx context.Context, db DBTX, playerID int64)
 }
 
 const getCosmeticItem = `-- name: GetCosmeticItem :one
-SELECT cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at FROM cosmetic_items WHERE cosmetic_id = ?
+SELECT cosmetic_id, name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, created_at, max_per_day, available_until, equip_restriction FROM cosmetic_items WHERE cosmetic_id = ?
 `
 
 func (q *Queries) GetCosmeticItem(ctx context.Context, db DBTX, cosmeticID int64) (*CosmeticItem, error) {
@@ -74,6 +149,32 @@ func (q *Queries) GetCosmeticItem(ctx context.Context, db DBTX, cosmeticID int64
 		&i.DataCost,
 		&i.IsPrestigeOnly,
 		&i.CreatedAt,
+		&i.MaxPerDay,
+		&i.AvailableUntil,
+		&i.EquipRestriction,
+	)
+	return &i, err
+}
+
+const getLoadout = `-- name: GetLoadout :one
+SELECT loadout_id, player_id, name, is_active, created_at, updated_at FROM loadouts WHERE loadout_id = ? AND player_id = ?
+`
+
+type GetLoadoutParams struct {
+	LoadoutID int64 `json:"loadout_id"`
+	PlayerID  int64 `json:"player_id"`
+}
+
+func (q *Queries) GetLoadout(ctx context.Context, db DBTX, arg *GetLoadoutParams) (*Loadout, error) {
+	row := db.QueryRowContext(ctx, getLoadout, arg.LoadoutID, arg.PlayerID)
+	var i Loadout
+	err := row.Scan(
+		&i.LoadoutID,
+		&i.PlayerID,
+		&i.Name,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return &i, err
 }
@@ -185,8 +286,10 @@ func (q *Queries) InsertLoadoutCosmetic(ctx context.Context, db DBTX, arg *Inser
 	return err
 }
 
-const updateLoadoutActive = `-- name: UpdateLoadoutActive :exec
-UPDATE loadouts SET is_active = ? WHERE loadout_id = ? AND player_id = ?
+const updateLoadoutActive = `-- name: UpdateLoadoutActive :execrows
+UPDATE loadouts
+SET is_active = ?, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE loadout_id = ? AND player_id = ?
 `
 
 type UpdateLoadoutActiveParams struct {
@@ -195,7 +298,10 @@ type UpdateLoadoutActiveParams struct {
 	PlayerID  int64 `json:"player_id"`
 }
 
-func (q *Queries) UpdateLoadoutActive(ctx context.Context, db DBTX, arg *UpdateLoadoutActiveParams) error {
-	_, err := db.ExecContext(ctx, updateLoadoutActive, arg.IsActive, arg.LoadoutID, arg.PlayerID)
-	return err
+func (q *Queries) UpdateLoadoutActive(ctx context.Context, db DBTX, arg *UpdateLoadoutActiveParams) (int64, error) {
+	result, err := db.ExecContext(ctx, updateLoadoutActive, arg.IsActive, arg.LoadoutID, arg.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
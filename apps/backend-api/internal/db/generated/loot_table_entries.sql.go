@@ -161,6 +161,32 @@ func (q *Queries) GetLootTableEntry(ctx context.Context, db DBTX, lootEntryID in
 	return &i, err
 }
 
+const getLootTableEntryByCosmetic = `-- name: GetLootTableEntryByCosmetic :one
+SELECT loot_entry_id, loot_table_id, cosmetic_id, weight, min_quantity, max_quantity FROM loot_table_entries
+WHERE loot_table_id = ? AND cosmetic_id = ?
+ORDER BY loot_entry_id
+LIMIT 1
+`
+
+type GetLootTableEntryByCosmeticParams struct {
+	LootTableID int64 `json:"loot_table_id"`
+	CosmeticID  int64 `json:"cosmetic_id"`
+}
+
+func (q *Queries) GetLootTableEntryByCosmetic(ctx context.Context, db DBTX, arg *GetLootTableEntryByCosmeticParams) (*LootTableEntry, error) {
+	row := db.QueryRowContext(ctx, getLootTableEntryByCosmetic, arg.LootTableID, arg.CosmeticID)
+	var i LootTableEntry
+	err := row.Scan(
+		&i.LootEntryID,
+		&i.LootTableID,
+		&i.CosmeticID,
+		&i.Weight,
+		&i.MinQuantity,
+		&i.MaxQuantity,
+	)
+	return &i, err
+}
+
 const updateLootTableEntry = `-- name: UpdateLootTableEntry :exec
 UPDATE loot_table_entries
 SET loot_table_id = ?, cosmetic_id = ?, weight = ?, min_quantity = ?, max_quantity = ?
@@ -70,6 +70,27 @@ func (q *Queries) GetLootTable(ctx context.Context, db DBTX, lootTableID int64)
 	return &i, err
 }
 
+const getLootTableByName = `-- name: GetLootTableByName :one
+SELECT loot_table_id, name, description, drop_chance, is_active, created_at FROM loot_tables
+WHERE name = ?
+ORDER BY loot_table_id
+LIMIT 1
+`
+
+func (q *Queries) GetLootTableByName(ctx context.Context, db DBTX, name string) (*LootTable, error) {
+	row := db.QueryRowContext(ctx, getLootTableByName, name)
+	var i LootTable
+	err := row.Scan(
+		&i.LootTableID,
+		&i.Name,
+		&i.Description,
+		&i.DropChance,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
 const listActiveLootTables = `-- name: ListActiveLootTables :many
 SELECT loot_table_id, name, description, drop_chance, is_active, created_at FROM loot_tables
 WHERE is_active = 1
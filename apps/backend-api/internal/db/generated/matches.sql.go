@@ -21,9 +21,10 @@ INSERT INTO matches (
     outcome,
     waves_survived,
     total_zombies_killed,
-    total_players
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING match_id, server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players
+    total_players,
+    idempotency_key
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING match_id, server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players, idempotency_key
 `
 
 type CreateMatchParams struct {
@@ -36,6 +37,7 @@ type CreateMatchParams struct {
 	WavesSurvived      int64               `json:"waves_survived"`
 	TotalZombiesKilled int64               `json:"total_zombies_killed"`
 	TotalPlayers       int64               `json:"total_players"`
+	IdempotencyKey     *string             `json:"idempotency_key"`
 }
 
 func (q *Queries) CreateMatch(ctx context.Context, db DBTX, arg *CreateMatchParams) (*Match, error) {
@@ -49,6 +51,7 @@ func (q *Queries) CreateMatch(ctx context.Context, db DBTX, arg *CreateMatchPara
 		arg.WavesSurvived,
 		arg.TotalZombiesKilled,
 		arg.TotalPlayers,
+		arg.IdempotencyKey,
 	)
 	var i Match
 	err := row.Scan(
@@ -62,12 +65,13 @@ func (q *Queries) CreateMatch(ctx context.Context, db DBTX, arg *CreateMatchPara
 		&i.WavesSurvived,
 		&i.TotalZombiesKilled,
 		&i.TotalPlayers,
+		&i.IdempotencyKey,
 	)
 	return &i, err
 }
 
 const getMatch = `-- name: GetMatch :one
-SELECT match_id, server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players FROM matches WHERE match_id = ?
+SELECT match_id, server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players, idempotency_key FROM matches WHERE match_id = ?
 `
 
 func (q *Queries) GetMatch(ctx context.Context, db DBTX, matchID int64) (*Match, error) {
@@ -84,13 +88,43 @@ func (q *Queries) GetMatch(ctx context.Context, db DBTX, matchID int64) (*Match,
 		&i.WavesSurvived,
 		&i.TotalZombiesKilled,
 		&i.TotalPlayers,
+		&i.IdempotencyKey,
+	)
+	return &i, err
+}
+
+const getMatchByIdempotencyKey = `-- name: GetMatchByIdempotencyKey :one
+SELECT match_id, server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players, idempotency_key FROM matches
+WHERE server_id = ? AND idempotency_key = ?
+`
+
+type GetMatchByIdempotencyKeyParams struct {
+	ServerID       int64   `json:"server_id"`
+	IdempotencyKey *string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetMatchByIdempotencyKey(ctx context.Context, db DBTX, arg *GetMatchByIdempotencyKeyParams) (*Match, error) {
+	row := db.QueryRowContext(ctx, getMatchByIdempotencyKey, arg.ServerID, arg.IdempotencyKey)
+	var i Match
+	err := row.Scan(
+		&i.MatchID,
+		&i.ServerID,
+		&i.MapName,
+		&i.GameMode,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Outcome,
+		&i.WavesSurvived,
+		&i.TotalZombiesKilled,
+		&i.TotalPlayers,
+		&i.IdempotencyKey,
 	)
 	return &i, err
 }
 
 const getPlayerMatchHistory = `-- name: GetPlayerMatchHistory :many
 SELECT 
-    m.match_id, m.server_id, m.map_name, m.game_mode, m.start_time, m.end_time, m.outcome, m.waves_survived, m.total_zombies_killed, m.total_players,
+    m.match_id, m.server_id, m.map_name, m.game_mode, m.start_time, m.end_time, m.outcome, m.waves_survived, m.total_zombies_killed, m.total_players, m.idempotency_key,
     pms.waves_survived as player_waves_survived,
     pms.zombies_killed as player_zombies_killed,
     pms.deaths as player_deaths,
@@ -102,17 +136,23 @@ SELECT
     pms.buildings_destroyed as player_buildings_destroyed,
     pms.healing_given as player_healing_given,
     pms.revives as player_revives,
-    pms.score as player_score
+    pms.score as player_score,
+    s.name as server_name
 FROM matches m
 JOIN player_match_stats pms ON m.match_id = pms.match_id
-WHERE pms.player_id = ?
-ORDER BY m.start_time DESC
-LIMIT ?
+LEFT JOIN servers s ON m.server_id = s.server_id
+WHERE pms.player_id = ?1
+  AND (?2 IS NULL
+    OR (m.start_time, m.match_id) < (SELECT bm.start_time, bm.match_id FROM matches bm WHERE bm.match_id = ?2))
+ORDER BY m.start_time DESC, m.match_id DESC
+LIMIT ?3 OFFSET ?4
 `
 
 type GetPlayerMatchHistoryParams struct {
-	PlayerID int64 `json:"player_id"`
-	Limit    int64 `json:"limit"`
+	PlayerID      int64  `json:"player_id"`
+	BeforeMatchID *int64 `json:"before_match_id"`
+	Limit         int64  `json:"limit"`
+	Offset        int64  `json:"offset"`
 }
 
 type GetPlayerMatchHistoryRow struct {
@@ -126,6 +166,7 @@ type GetPlayerMatchHistoryRow struct {
 	WavesSurvived            int64               `json:"waves_survived"`
 	TotalZombiesKilled       int64               `json:"total_zombies_killed"`
 	TotalPlayers             int64               `json:"total_players"`
+	IdempotencyKey           *string             `json:"idempotency_key"`
 	PlayerWavesSurvived      int64               `json:"player_waves_survived"`
 	PlayerZombiesKilled      int64               `json:"player_zombies_killed"`
 	PlayerDeaths             int64               `json:"player_deaths"`
@@ -138,10 +179,18 @@ type GetPlayerMatchHistoryRow struct {
 	PlayerHealingGiven       int64               `json:"player_healing_given"`
 	PlayerRevives            int64               `json:"player_revives"`
 	PlayerScore              int64               `json:"player_score"`
+	ServerName               *string             `json:"server_name"`
 }
 
+// Servers are LEFT JOINed so a match whose server row is gone still lists, with a null server_name.
+// Ordered newest first with match_id as tiebreak; before_match_id resumes after that match.
 func (q *Queries) GetPlayerMatchHistory(ctx context.Context, db DBTX, arg *GetPlayerMatchHistoryParams) ([]*GetPlayerMatchHistoryRow, error) {
-	rows, err := db.QueryContext(ctx, getPlayerMatchHistory, arg.PlayerID, arg.Limit)
+	rows, err := db.QueryContext(ctx, getPlayerMatchHistory,
+		arg.PlayerID,
+		arg.BeforeMatchID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -160,6 +209,7 @@ func (q *Queries) GetPlayerMatchHistory(ctx context.Context, db DBTX, arg *GetPl
 			&i.WavesSurvived,
 			&i.TotalZombiesKilled,
 			&i.TotalPlayers,
+			&i.IdempotencyKey,
 			&i.PlayerWavesSurvived,
 			&i.PlayerZombiesKilled,
 			&i.PlayerDeaths,
@@ -172,6 +222,7 @@ func (q *Queries) GetPlayerMatchHistory(ctx context.Context, db DBTX, arg *GetPl
 			&i.PlayerHealingGiven,
 			&i.PlayerRevives,
 			&i.PlayerScore,
+			&i.ServerName,
 		); err != nil {
 			return nil, err
 		}
@@ -186,6 +237,97 @@ func (q *Queries) GetPlayerMatchHistory(ctx context.Context, db DBTX, arg *GetPl
 	return items, nil
 }
 
+const getPlayerOutcomeCounts = `-- name: GetPlayerOutcomeCounts :many
+SELECT
+    m.outcome,
+    COUNT(*) AS match_count
+FROM matches m
+JOIN player_match_stats pms ON m.match_id = pms.match_id
+WHERE pms.player_id = ?
+GROUP BY m.outcome
+`
+
+type GetPlayerOutcomeCountsRow struct {
+	Outcome    string `json:"outcome"`
+	MatchCount int64  `json:"match_count"`
+}
+
+func (q *Queries) GetPlayerOutcomeCounts(ctx context.Context, db DBTX, playerID int64) ([]*GetPlayerOutcomeCountsRow, error) {
+	rows, err := db.QueryContext(ctx, getPlayerOutcomeCounts, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetPlayerOutcomeCountsRow{}
+	for rows.Next() {
+		var i GetPlayerOutcomeCountsRow
+		if err := rows.Scan(&i.Outcome, &i.MatchCount); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayerRecentMatchTimes = `-- name: GetPlayerRecentMatchTimes :many
+SELECT m.match_id, m.start_time, m.end_time
+FROM matches m
+JOIN player_match_stats pms ON m.match_id = pms.match_id
+WHERE pms.player_id = ?1
+  AND m.match_id != ?2
+  AND m.end_time >= ?3
+  AND m.end_time <= ?4
+ORDER BY m.end_time DESC
+`
+
+type GetPlayerRecentMatchTimesParams struct {
+	PlayerID       int64               `json:"player_id"`
+	ExcludeMatchID int64               `json:"exclude_match_id"`
+	WindowStart    types.NullTimestamp `json:"window_start"`
+	WindowEnd      types.NullTimestamp `json:"window_end"`
+}
+
+type GetPlayerRecentMatchTimesRow struct {
+	MatchID   int64               `json:"match_id"`
+	StartTime types.Timestamp     `json:"start_time"`
+	EndTime   types.NullTimestamp `json:"end_time"`
+}
+
+// Lists the start and end times of a player's matches that ended within [window_start, window_end], excluding one match.
+func (q *Queries) GetPlayerRecentMatchTimes(ctx context.Context, db DBTX, arg *GetPlayerRecentMatchTimesParams) ([]*GetPlayerRecentMatchTimesRow, error) {
+	rows, err := db.QueryContext(ctx, getPlayerRecentMatchTimes,
+		arg.PlayerID,
+		arg.ExcludeMatchID,
+		arg.WindowStart,
+		arg.WindowEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetPlayerRecentMatchTimesRow{}
+	for rows.Next() {
+		var i GetPlayerRecentMatchTimesRow
+		if err := rows.Scan(&i.MatchID, &i.StartTime, &i.EndTime); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateMatchOutcome = `-- name: UpdateMatchOutcome :exec
 UPDATE matches
 SET outcome = ?, end_time = ?
@@ -8,17 +8,34 @@ import (
 	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
+type CosmeticBundle struct {
+	BundleID    int64           `json:"bundle_id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Price       int64           `json:"price"`
+	IsActive    int64           `json:"is_active"`
+	CreatedAt   types.Timestamp `json:"created_at"`
+}
+
+type CosmeticBundleItem struct {
+	BundleID   int64 `json:"bundle_id"`
+	CosmeticID int64 `json:"cosmetic_id"`
+}
+
 type CosmeticItem struct {
-	CosmeticID     int64           `json:"cosmetic_id"`
-	Name           string          `json:"name"`
-	Description    *string         `json:"description"`
-	Slot           string          `json:"slot"`
-	Category       *string         `json:"category"`
-	Rarity         string          `json:"rarity"`
-	UnlockLevel    int64           `json:"unlock_level"`
-	DataCost       int64           `json:"data_cost"`
-	IsPrestigeOnly int64           `json:"is_prestige_only"`
-	CreatedAt      types.Timestamp `json:"created_at"`
+	CosmeticID       int64               `json:"cosmetic_id"`
+	Name             string              `json:"name"`
+	Description      *string             `json:"description"`
+	Slot             string              `json:"slot"`
+	Category         *string             `json:"category"`
+	Rarity           string              `json:"rarity"`
+	UnlockLevel      int64               `json:"unlock_level"`
+	DataCost         int64               `json:"data_cost"`
+	IsPrestigeOnly   int64               `json:"is_prestige_only"`
+	CreatedAt        types.Timestamp     `json:"created_at"`
+	MaxPerDay        *int64              `json:"max_per_day"`
+	AvailableUntil   types.NullTimestamp `json:"available_until"`
+	EquipRestriction *string             `json:"equip_restriction"`
 }
 
 type CurrencyTransaction struct {
@@ -31,6 +48,16 @@ type CurrencyTransaction struct {
 	CreatedAt       types.Timestamp `json:"created_at"`
 }
 
+type EmailChangeToken struct {
+	EmailChangeTokenID int64               `json:"email_change_token_id"`
+	Token              string              `json:"token"`
+	PlayerID           int64               `json:"player_id"`
+	NewEmail           string              `json:"new_email"`
+	ExpiresAt          types.Timestamp     `json:"expires_at"`
+	CreatedAt          types.Timestamp     `json:"created_at"`
+	UsedAt             types.NullTimestamp `json:"used_at"`
+}
+
 type Friend struct {
 	PlayerID  int64           `json:"player_id"`
 	FriendID  int64           `json:"friend_id"`
@@ -61,6 +88,20 @@ type LeaderboardEntry struct {
 	UpdatedAt        types.Timestamp `json:"updated_at"`
 }
 
+type LeaderboardSnapshot struct {
+	Period           string          `json:"period"`
+	Metric           string          `json:"metric"`
+	Ranking          int64           `json:"ranking"`
+	PlayerID         int64           `json:"player_id"`
+	Username         string          `json:"username"`
+	TotalScore       int64           `json:"total_score"`
+	MatchesPlayed    int64           `json:"matches_played"`
+	AvgKillsPerMatch *float64        `json:"avg_kills_per_match"`
+	AvgWavesSurvived *float64        `json:"avg_waves_survived"`
+	MetricValue      int64           `json:"metric_value"`
+	GeneratedAt      types.Timestamp `json:"generated_at"`
+}
+
 type Loadout struct {
 	LoadoutID int64           `json:"loadout_id"`
 	PlayerID  int64           `json:"player_id"`
@@ -76,6 +117,20 @@ type LoadoutCosmetic struct {
 	Slot       string `json:"slot"`
 }
 
+type LoginFailure struct {
+	PlayerID       int64               `json:"player_id"`
+	FailedAttempts int64               `json:"failed_attempts"`
+	Lockouts       int64               `json:"lockouts"`
+	LockedUntil    types.NullTimestamp `json:"locked_until"`
+	UpdatedAt      types.Timestamp     `json:"updated_at"`
+}
+
+type LootPity struct {
+	PlayerID     int64           `json:"player_id"`
+	UnluckyRolls int64           `json:"unlucky_rolls"`
+	UpdatedAt    types.Timestamp `json:"updated_at"`
+}
+
 type LootTable struct {
 	LootTableID int64           `json:"loot_table_id"`
 	Name        string          `json:"name"`
@@ -105,6 +160,18 @@ type Match struct {
 	WavesSurvived      int64               `json:"waves_survived"`
 	TotalZombiesKilled int64               `json:"total_zombies_killed"`
 	TotalPlayers       int64               `json:"total_players"`
+	IdempotencyKey     *string             `json:"idempotency_key"`
+}
+
+type Notification struct {
+	NotificationID   int64               `json:"notification_id"`
+	PlayerID         int64               `json:"player_id"`
+	NotificationType string              `json:"notification_type"`
+	Message          string              `json:"message"`
+	ReferenceID      *int64              `json:"reference_id"`
+	IsRead           int64               `json:"is_read"`
+	CreatedAt        types.Timestamp     `json:"created_at"`
+	ReadAt           types.NullTimestamp `json:"read_at"`
 }
 
 type Player struct {
@@ -159,15 +226,38 @@ type PlayerProgression struct {
 	UpdatedAt          types.Timestamp `json:"updated_at"`
 }
 
+type PlayerReport struct {
+	ReportID   int64               `json:"report_id"`
+	ReporterID int64               `json:"reporter_id"`
+	TargetID   int64               `json:"target_id"`
+	Category   string              `json:"category"`
+	Reason     *string             `json:"reason"`
+	Status     string              `json:"status"`
+	CreatedAt  types.Timestamp     `json:"created_at"`
+	ResolvedAt types.NullTimestamp `json:"resolved_at"`
+	ResolvedBy *int64              `json:"resolved_by"`
+}
+
 type PlayerSetting struct {
-	PlayerID         int64           `json:"player_id"`
-	KeyBindings      *string         `json:"key_bindings"`
-	MouseSensitivity *float64        `json:"mouse_sensitivity"`
-	UiScale          *float64        `json:"ui_scale"`
-	ColorBlindMode   int64           `json:"color_blind_mode"`
-	SubtitlesEnabled int64           `json:"subtitles_enabled"`
-	CreatedAt        types.Timestamp `json:"created_at"`
-	UpdatedAt        types.Timestamp `json:"updated_at"`
+	PlayerID                  int64           `json:"player_id"`
+	KeyBindings               *string         `json:"key_bindings"`
+	MouseSensitivity          *float64        `json:"mouse_sensitivity"`
+	UiScale                   *float64        `json:"ui_scale"`
+	ColorBlindMode            int64           `json:"color_blind_mode"`
+	SubtitlesEnabled          int64           `json:"subtitles_enabled"`
+	CreatedAt                 types.Timestamp `json:"created_at"`
+	UpdatedAt                 types.Timestamp `json:"updated_at"`
+	AllowFriendRequests       int64           `json:"allow_friend_requests"`
+	ShowOnLeaderboard         int64           `json:"show_on_leaderboard"`
+	MatchHistoryPublic        int64           `json:"match_history_public"`
+	InventoryVisibleToFriends int64           `json:"inventory_visible_to_friends"`
+}
+
+type Referral struct {
+	ReferralID int64           `json:"referral_id"`
+	ReferrerID int64           `json:"referrer_id"`
+	ReferredID int64           `json:"referred_id"`
+	CreatedAt  types.Timestamp `json:"created_at"`
 }
 
 type Server struct {
@@ -193,12 +283,20 @@ type ServerFavorite struct {
 	Note     *string         `json:"note"`
 }
 
+type ServerJoin struct {
+	ServerJoinID int64           `json:"server_join_id"`
+	ServerID     int64           `json:"server_id"`
+	PlayerID     int64           `json:"player_id"`
+	JoinedAt     types.Timestamp `json:"joined_at"`
+}
+
 type Session struct {
-	SessionID int64           `json:"session_id"`
-	PlayerID  int64           `json:"player_id"`
-	Token     string          `json:"token"`
-	ExpiresAt types.Timestamp `json:"expires_at"`
-	CreatedAt types.Timestamp `json:"created_at"`
-	IpAddress *string         `json:"ip_address"`
-	UserAgent *string         `json:"user_agent"`
+	SessionID int64               `json:"session_id"`
+	PlayerID  int64               `json:"player_id"`
+	Token     string              `json:"token"`
+	ExpiresAt types.Timestamp     `json:"expires_at"`
+	CreatedAt types.Timestamp     `json:"created_at"`
+	IpAddress *string             `json:"ip_address"`
+	UserAgent *string             `json:"user_agent"`
+	StartedAt types.NullTimestamp `json:"started_at"`
 }
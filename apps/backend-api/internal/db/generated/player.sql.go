@@ -11,6 +11,40 @@ import (
 	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
+const banPlayer = `-- name: BanPlayer :execrows
+UPDATE players SET is_banned = 1, banned_reason = ?, banned_until = ? WHERE player_id = ?
+`
+
+type BanPlayerParams struct {
+	BannedReason *string             `json:"banned_reason"`
+	BannedUntil  types.NullTimestamp `json:"banned_until"`
+	PlayerID     int64               `json:"player_id"`
+}
+
+// A NULL banned_until bans permanently.
+func (q *Queries) BanPlayer(ctx context.Context, db DBTX, arg *BanPlayerParams) (int64, error) {
+	result, err := db.ExecContext(ctx, banPlayer, arg.BannedReason, arg.BannedUntil, arg.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const clearExpiredBan = `-- name: ClearExpiredBan :execrows
+UPDATE players SET is_banned = 0, banned_reason = NULL, banned_until = NULL
+WHERE player_id = ? AND is_banned = 1 AND banned_until IS NOT NULL
+  AND banned_until <= strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+`
+
+// Only clears a temporary ban that has already ended, so a newer ban is left alone.
+func (q *Queries) ClearExpiredBan(ctx context.Context, db DBTX, playerID int64) (int64, error) {
+	result, err := db.ExecContext(ctx, clearExpiredBan, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createPlayer = `-- name: CreatePlayer :exec
 INSERT INTO players (username, email, password_hash) VALUES (?, ?, ?)
 `
@@ -139,6 +173,90 @@ func (q *Queries) ListPlayers(ctx context.Context, db DBTX) ([]*Player, error) {
 	return items, nil
 }
 
+const searchPlayers = `-- name: SearchPlayers :many
+SELECT player_id, username, email, is_banned, is_admin, created_at, last_login_at
+FROM players
+WHERE ?1 IS NULL
+   OR username LIKE '%' || ?1 || '%' ESCAPE '\'
+   OR email LIKE '%' || ?1 || '%' ESCAPE '\'
+ORDER BY username
+LIMIT ?2 OFFSET ?3
+`
+
+type SearchPlayersParams struct {
+	Query  *string `json:"query"`
+	Limit  int64   `json:"limit"`
+	Offset int64   `json:"offset"`
+}
+
+type SearchPlayersRow struct {
+	PlayerID    int64               `json:"player_id"`
+	Username    string              `json:"username"`
+	Email       string              `json:"email"`
+	IsBanned    int64               `json:"is_banned"`
+	IsAdmin     int64               `json:"is_admin"`
+	CreatedAt   types.Timestamp     `json:"created_at"`
+	LastLoginAt types.NullTimestamp `json:"last_login_at"`
+}
+
+// query must have its LIKE wildcards escaped with '\'; NULL lists every player.
+func (q *Queries) SearchPlayers(ctx context.Context, db DBTX, arg *SearchPlayersParams) ([]*SearchPlayersRow, error) {
+	rows, err := db.QueryContext(ctx, searchPlayers, arg.Query, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*SearchPlayersRow{}
+	for rows.Next() {
+		var i SearchPlayersRow
+		if err := rows.Scan(
+			&i.PlayerID,
+			&i.Username,
+			&i.Email,
+			&i.IsBanned,
+			&i.IsAdmin,
+			&i.CreatedAt,
+			&i.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPlayerAdmin = `-- name: SetPlayerAdmin :exec
+UPDATE players SET is_admin = ? WHERE player_id = ?
+`
+
+type SetPlayerAdminParams struct {
+	IsAdmin  int64 `json:"is_admin"`
+	PlayerID int64 `json:"player_id"`
+}
+
+func (q *Queries) SetPlayerAdmin(ctx context.Context, db DBTX, arg *SetPlayerAdminParams) error {
+	_, err := db.ExecContext(ctx, setPlayerAdmin, arg.IsAdmin, arg.PlayerID)
+	return err
+}
+
+const unbanPlayer = `-- name: UnbanPlayer :execrows
+UPDATE players SET is_banned = 0, banned_reason = NULL, banned_until = NULL WHERE player_id = ?
+`
+
+func (q *Queries) UnbanPlayer(ctx context.Context, db DBTX, playerID int64) (int64, error) {
+	result, err := db.ExecContext(ctx, unbanPlayer, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updatePlayerLastLogin = `-- name: UpdatePlayerLastLogin :exec
 UPDATE players SET last_login_at = ? WHERE player_id = ?
 `
@@ -153,6 +271,20 @@ func (q *Queries) UpdatePlayerLastLogin(ctx context.Context, db DBTX, arg *Updat
 	return err
 }
 
+const updatePlayerEmail = `-- name: UpdatePlayerEmail :exec
+UPDATE players SET email = ? WHERE player_id = ?
+`
+
+type UpdatePlayerEmailParams struct {
+	Email    string `json:"email"`
+	PlayerID int64  `json:"player_id"`
+}
+
+func (q *Queries) UpdatePlayerEmail(ctx context.Context, db DBTX, arg *UpdatePlayerEmailParams) error {
+	_, err := db.ExecContext(ctx, updatePlayerEmail, arg.Email, arg.PlayerID)
+	return err
+}
+
 const updatePlayerPassword = `-- name: UpdatePlayerPassword :exec
 UPDATE players SET password_hash = ? WHERE player_id = ?
 `
@@ -168,16 +300,15 @@ func (q *Queries) UpdatePlayerPassword(ctx context.Context, db DBTX, arg *Update
 }
 
 const updatePlayerProfile = `-- name: UpdatePlayerProfile :exec
-UPDATE players SET username = ?, email = ? WHERE player_id = ?
+UPDATE players SET username = ? WHERE player_id = ?
 `
 
 type UpdatePlayerProfileParams struct {
 	Username string `json:"username"`
-	Email    string `json:"email"`
 	PlayerID int64  `json:"player_id"`
 }
 
 func (q *Queries) UpdatePlayerProfile(ctx context.Context, db DBTX, arg *UpdatePlayerProfileParams) error {
-	_, err := db.ExecContext(ctx, updatePlayerProfile, arg.Username, arg.Email, arg.PlayerID)
+	_, err := db.ExecContext(ctx, updatePlayerProfile, arg.Username, arg.PlayerID)
 	return err
 }
@@ -11,8 +11,26 @@ import (
 	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
+const deletePurchasedCosmetic = `-- name: DeletePurchasedCosmetic :execrows
+DELETE FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ? AND unlocked_via = 'purchase'
+`
+
+type DeletePurchasedCosmeticParams struct {
+	PlayerID   int64 `json:"player_id"`
+	CosmeticID int64 `json:"cosmetic_id"`
+}
+
+// Only removes a cosmetic the player bought, never one granted another way.
+func (q *Queries) DeletePurchasedCosmetic(ctx context.Context, db DBTX, arg *DeletePurchasedCosmeticParams) (int64, error) {
+	result, err := db.ExecContext(ctx, deletePurchasedCosmetic, arg.PlayerID, arg.CosmeticID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getPlayerCosmetic = `-- name: GetPlayerCosmetic :one
-SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at, pc.unlocked_at, pc.unlocked_via
+SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at, ci.max_per_day, ci.available_until, ci.equip_restriction, pc.unlocked_at, pc.unlocked_via
 FROM cosmetic_items ci
 JOIN player_cosmetics pc ON ci.cosmetic_id = pc.cosmetic_id
 WHERE pc.player_id = ? AND pc.cosmetic_id = ?
@@ -24,18 +42,21 @@ type GetPlayerCosmeticParams struct {
 }
 
 type GetPlayerCosmeticRow struct {
-	CosmeticID     int64           `json:"cosmetic_id"`
-	Name           string          `json:"name"`
-	Description    *string         `json:"description"`
-	Slot           string          `json:"slot"`
-	Category       *string         `json:"category"`
-	Rarity         string          `json:"rarity"`
-	UnlockLevel    int64           `json:"unlock_level"`
-	DataCost       int64           `json:"data_cost"`
-	IsPrestigeOnly int64           `json:"is_prestige_only"`
-	CreatedAt      types.Timestamp `json:"created_at"`
-	UnlockedAt     types.Timestamp `json:"unlocked_at"`
-	UnlockedVia    string          `json:"unlocked_via"`
+	CosmeticID       int64               `json:"cosmetic_id"`
+	Name             string              `json:"name"`
+	Description      *string             `json:"description"`
+	Slot             string              `json:"slot"`
+	Category         *string             `json:"category"`
+	Rarity           string              `json:"rarity"`
+	UnlockLevel      int64               `json:"unlock_level"`
+	DataCost         int64               `json:"data_cost"`
+	IsPrestigeOnly   int64               `json:"is_prestige_only"`
+	CreatedAt        types.Timestamp     `json:"created_at"`
+	MaxPerDay        *int64              `json:"max_per_day"`
+	AvailableUntil   types.NullTimestamp `json:"available_until"`
+	EquipRestriction *string             `json:"equip_restriction"`
+	UnlockedAt       types.Timestamp     `json:"unlocked_at"`
+	UnlockedVia      string              `json:"unlocked_via"`
 }
 
 func (q *Queries) GetPlayerCosmetic(ctx context.Context, db DBTX, arg *GetPlayerCosmeticParams) (*GetPlayerCosmeticRow, error) {
@@ -52,6 +73,9 @@ func (q *Queries) GetPlayerCosmetic(ctx context.Context, db DBTX, arg *GetPlayer
 		&i.DataCost,
 		&i.IsPrestigeOnly,
 		&i.CreatedAt,
+		&i.MaxPerDay,
+		&i.AvailableUntil,
+		&i.EquipRestriction,
 		&i.UnlockedAt,
 		&i.UnlockedVia,
 	)
@@ -59,7 +83,7 @@ func (q *Queries) GetPlayerCosmetic(ctx context.Context, db DBTX, arg *GetPlayer
 }
 
 const getPlayerCosmetics = `-- name: GetPlayerCosmetics :many
-SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at, pc.unlocked_at, pc.unlocked_via
+SELECT ci.cosmetic_id, ci.name, ci.description, ci.slot, ci.category, ci.rarity, ci.unlock_level, ci.data_cost, ci.is_prestige_only, ci.created_at, ci.max_per_day, ci.available_until, ci.equip_restriction, pc.unlocked_at, pc.unlocked_via
 FROM cosmetic_items ci
 JOIN player_cosmetics pc ON ci.cosmetic_id = pc.cosmetic_id
 WHERE pc.player_id = ?
@@ -67,18 +91,21 @@ ORDER BY pc.unlocked_at DESC
 `
 
 type GetPlayerCosmeticsRow struct {
-	CosmeticID     int64           `json:"cosmetic_id"`
-	Name           string          `json:"name"`
-	Description    *string         `json:"description"`
-	Slot           string          `json:"slot"`
-	Category       *string         `json:"category"`
-	Rarity         string          `json:"rarity"`
-	UnlockLevel    int64           `json:"unlock_level"`
-	DataCost       int64           `json:"data_cost"`
-	IsPrestigeOnly int64           `json:"is_prestige_only"`
-	CreatedAt      types.Timestamp `json:"created_at"`
-	UnlockedAt     types.Timestamp `json:"unlocked_at"`
-	UnlockedVia    string          `json:"unlocked_via"`
+	CosmeticID       int64               `json:"cosmetic_id"`
+	Name             string              `json:"name"`
+	Description      *string             `json:"description"`
+	Slot             string              `json:"slot"`
+	Category         *string             `json:"category"`
+	Rarity           string              `json:"rarity"`
+	UnlockLevel      int64               `json:"unlock_level"`
+	DataCost         int64               `json:"data_cost"`
+	IsPrestigeOnly   int64               `json:"is_prestige_only"`
+	CreatedAt        types.Timestamp     `json:"created_at"`
+	MaxPerDay        *int64              `json:"max_per_day"`
+	AvailableUntil   types.NullTimestamp `json:"available_until"`
+	EquipRestriction *string             `json:"equip_restriction"`
+	UnlockedAt       types.Timestamp     `json:"unlocked_at"`
+	UnlockedVia      string              `json:"unlocked_via"`
 }
 
 func (q *Queries) GetPlayerCosmetics(ctx context.Context, db DBTX, playerID int64) ([]*GetPlayerCosmeticsRow, error) {
@@ -101,6 +128,9 @@ func (q *Queries) GetPlayerCosmetics(ctx context.Context, db DBTX, playerID int6
 			&i.DataCost,
 			&i.IsPrestigeOnly,
 			&i.CreatedAt,
+			&i.MaxPerDay,
+			&i.AvailableUntil,
+			&i.EquipRestriction,
 			&i.UnlockedAt,
 			&i.UnlockedVia,
 		); err != nil {
@@ -116,3 +146,50 @@ func (q *Queries) GetPlayerCosmetics(ctx context.Context, db DBTX, playerID int6
 	}
 	return items, nil
 }
+
+const listFriendsOwningCosmetic = `-- name: ListFriendsOwningCosmetic :many
+SELECT p.player_id, p.username, pc.unlocked_at
+FROM friends f
+JOIN players p ON p.player_id = CASE WHEN f.player_id = ?1 THEN f.friend_id ELSE f.player_id END
+JOIN player_cosmetics pc ON pc.player_id = p.player_id AND pc.cosmetic_id = ?2
+LEFT JOIN player_settings ps ON ps.player_id = p.player_id
+WHERE (f.player_id = ?1 OR f.friend_id = ?1)
+  AND f.status = 'accepted'
+  AND COALESCE(ps.inventory_visible_to_friends, 1) = 1
+ORDER BY p.username
+`
+
+type ListFriendsOwningCosmeticParams struct {
+	PlayerID   int64 `json:"player_id"`
+	CosmeticID int64 `json:"cosmetic_id"`
+}
+
+type ListFriendsOwningCosmeticRow struct {
+	PlayerID   int64           `json:"player_id"`
+	Username   string          `json:"username"`
+	UnlockedAt types.Timestamp `json:"unlocked_at"`
+}
+
+// Lists a player's accepted friends who own a cosmetic, skipping friends who hide their inventory.
+func (q *Queries) ListFriendsOwningCosmetic(ctx context.Context, db DBTX, arg *ListFriendsOwningCosmeticParams) ([]*ListFriendsOwningCosmeticRow, error) {
+	rows, err := db.QueryContext(ctx, listFriendsOwningCosmetic, arg.PlayerID, arg.CosmeticID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListFriendsOwningCosmeticRow{}
+	for rows.Next() {
+		var i ListFriendsOwningCosmeticRow
+		if err := rows.Scan(&i.PlayerID, &i.Username, &i.UnlockedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
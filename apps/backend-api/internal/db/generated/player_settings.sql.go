@@ -10,7 +10,7 @@ import (
 )
 
 const getPlayerSettings = `-- name: GetPlayerSettings :one
-SELECT player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, created_at, updated_at FROM player_settings WHERE player_id = ?
+SELECT player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, created_at, updated_at, allow_friend_requests, show_on_leaderboard, match_history_public, inventory_visible_to_friends FROM player_settings WHERE player_id = ?
 `
 
 func (q *Queries) GetPlayerSettings(ctx context.Context, db DBTX, playerID int64) (*PlayerSetting, error) {
@@ -25,29 +25,106 @@ func (q *Queries) GetPlayerSettings(ctx context.Context, db DBTX, playerID int64
 		&i.SubtitlesEnabled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AllowFriendRequests,
+		&i.ShowOnLeaderboard,
+		&i.MatchHistoryPublic,
+		&i.InventoryVisibleToFriends,
 	)
 	return &i, err
 }
 
+const updatePlayerSettingsPartial = `-- name: UpdatePlayerSettingsPartial :exec
+INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, allow_friend_requests, show_on_leaderboard, match_history_public, inventory_visible_to_friends)
+VALUES (
+    ?1,
+    CAST(?2 AS TEXT),
+    CAST(?3 AS REAL),
+    CAST(?4 AS REAL),
+    COALESCE(CAST(?5 AS INTEGER), 0),
+    COALESCE(CAST(?6 AS INTEGER), 0),
+    COALESCE(CAST(?7 AS INTEGER), 1),
+    COALESCE(CAST(?8 AS INTEGER), 1),
+    COALESCE(CAST(?9 AS INTEGER), 1),
+    COALESCE(CAST(?10 AS INTEGER), 1)
+)
+ON CONFLICT(player_id) DO UPDATE SET
+    key_bindings = CASE WHEN CAST(?11 AS INTEGER) = 1 THEN excluded.key_bindings ELSE player_settings.key_bindings END,
+    mouse_sensitivity = CASE WHEN CAST(?12 AS INTEGER) = 1 THEN excluded.mouse_sensitivity ELSE player_settings.mouse_sensitivity END,
+    ui_scale = CASE WHEN CAST(?13 AS INTEGER) = 1 THEN excluded.ui_scale ELSE player_settings.ui_scale END,
+    color_blind_mode = COALESCE(CAST(?5 AS INTEGER), player_settings.color_blind_mode),
+    subtitles_enabled = COALESCE(CAST(?6 AS INTEGER), player_settings.subtitles_enabled),
+    allow_friend_requests = COALESCE(CAST(?7 AS INTEGER), player_settings.allow_friend_requests),
+    show_on_leaderboard = COALESCE(CAST(?8 AS INTEGER), player_settings.show_on_leaderboard),
+    match_history_public = COALESCE(CAST(?9 AS INTEGER), player_settings.match_history_public),
+    inventory_visible_to_friends = COALESCE(CAST(?10 AS INTEGER), player_settings.inventory_visible_to_friends),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+`
+
+type UpdatePlayerSettingsPartialParams struct {
+	PlayerID                  int64    `json:"player_id"`
+	KeyBindings               *string  `json:"key_bindings"`
+	MouseSensitivity          *float64 `json:"mouse_sensitivity"`
+	UiScale                   *float64 `json:"ui_scale"`
+	ColorBlindMode            *int64   `json:"color_blind_mode"`
+	SubtitlesEnabled          *int64   `json:"subtitles_enabled"`
+	AllowFriendRequests       *int64   `json:"allow_friend_requests"`
+	ShowOnLeaderboard         *int64   `json:"show_on_leaderboard"`
+	MatchHistoryPublic        *int64   `json:"match_history_public"`
+	InventoryVisibleToFriends *int64   `json:"inventory_visible_to_friends"`
+	SetKeyBindings            int64    `json:"set_key_bindings"`
+	SetMouseSensitivity       int64    `json:"set_mouse_sensitivity"`
+	SetUiScale                int64    `json:"set_ui_scale"`
+}
+
+// Only columns flagged as present are written; the set_* flags let nullable
+// columns be cleared explicitly, while NOT NULL columns keep their value when
+// the argument is NULL.
+func (q *Queries) UpdatePlayerSettingsPartial(ctx context.Context, db DBTX, arg *UpdatePlayerSettingsPartialParams) error {
+	_, err := db.ExecContext(ctx, updatePlayerSettingsPartial,
+		arg.PlayerID,
+		arg.KeyBindings,
+		arg.MouseSensitivity,
+		arg.UiScale,
+		arg.ColorBlindMode,
+		arg.SubtitlesEnabled,
+		arg.AllowFriendRequests,
+		arg.ShowOnLeaderboard,
+		arg.MatchHistoryPublic,
+		arg.InventoryVisibleToFriends,
+		arg.SetKeyBindings,
+		arg.SetMouseSensitivity,
+		arg.SetUiScale,
+	)
+	return err
+}
+
 const upsertPlayerSettings = `-- name: UpsertPlayerSettings :exec
-INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, allow_friend_requests, show_on_leaderboard, match_history_public, inventory_visible_to_friends)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(player_id) DO UPDATE SET
     key_bindings = excluded.key_bindings,
     mouse_sensitivity = excluded.mouse_sensitivity,
     ui_scale = excluded.ui_scale,
     color_blind_mode = excluded.color_blind_mode,
     subtitles_enabled = excluded.subtitles_enabled,
+    allow_friend_requests = excluded.allow_friend_requests,
+    show_on_leaderboard = excluded.show_on_leaderboard,
+    match_history_public = excluded.match_history_public,
+    inventory_visible_to_friends = excluded.inventory_visible_to_friends,
     updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
 `
 
 type UpsertPlayerSettingsParams struct {
-	PlayerID         int64    `json:"player_id"`
-	KeyBindings      *string  `json:"key_bindings"`
-	MouseSensitivity *float64 `json:"mouse_sensitivity"`
-	UiScale          *float64 `json:"ui_scale"`
-	ColorBlindMode   int64    `json:"color_blind_mode"`
-	SubtitlesEnabled int64    `json:"subtitles_enabled"`
+	PlayerID                  int64    `json:"player_id"`
+	KeyBindings               *string  `json:"key_bindings"`
+	MouseSensitivity          *float64 `json:"mouse_sensitivity"`
+	UiScale                   *float64 `json:"ui_scale"`
+	ColorBlindMode            int64    `json:"color_blind_mode"`
+	SubtitlesEnabled          int64    `json:"subtitles_enabled"`
+	AllowFriendRequests       int64    `json:"allow_friend_requests"`
+	ShowOnLeaderboard         int64    `json:"show_on_leaderboard"`
+	MatchHistoryPublic        int64    `json:"match_history_public"`
+	InventoryVisibleToFriends int64    `json:"inventory_visible_to_friends"`
 }
 
 func (q *Queries) UpsertPlayerSettings(ctx context.Context, db DBTX, arg *UpsertPlayerSettingsParams) error {
@@ -58,6 +135,10 @@ func (q *Queries) UpsertPlayerSettings(ctx context.Context, db DBTX, arg *Upsert
 		arg.UiScale,
 		arg.ColorBlindMode,
 		arg.SubtitlesEnabled,
+		arg.AllowFriendRequests,
+		arg.ShowOnLeaderboard,
+		arg.MatchHistoryPublic,
+		arg.InventoryVisibleToFriends,
 	)
 	return err
 }
@@ -11,9 +11,10 @@ import (
 	"ai-zombie-defense/backend-api/internal/db/types"
 )
 
-const addFavorite = `-- name: AddFavorite :exec
+const addFavorite = `-- name: AddFavorite :execrows
 INSERT INTO server_favorites (player_id, server_id, note)
 VALUES (?, ?, ?)
+ON CONFLICT (player_id, server_id) DO NOTHING
 `
 
 type AddFavoriteParams struct {
@@ -22,8 +23,23 @@ type AddFavoriteParams struct {
 	Note     *string `json:"note"`
 }
 
-func (q *Queries) AddFavorite(ctx context.Context, db DBTX, arg *AddFavoriteParams) error {
-	_, err := db.ExecContext(ctx, addFavorite, arg.PlayerID, arg.ServerID, arg.Note)
+// Inserts nothing when the server is already a favorite, so callers can tell
+// from the row count without a racy existence check.
+func (q *Queries) AddFavorite(ctx context.Context, db DBTX, arg *AddFavoriteParams) (int64, error) {
+	result, err := db.ExecContext(ctx, addFavorite, arg.PlayerID, arg.ServerID, arg.Note)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteServerFavorites = `-- name: DeleteServerFavorites :exec
+DELETE FROM server_favorites
+WHERE server_id = ?
+`
+
+func (q *Queries) DeleteServerFavorites(ctx context.Context, db DBTX, serverID int64) error {
+	_, err := db.ExecContext(ctx, deleteServerFavorites, serverID)
 	return err
 }
 
@@ -128,3 +144,23 @@ func (q *Queries) RemoveFavorite(ctx context.Context, db DBTX, arg *RemoveFavori
 	_, err := db.ExecContext(ctx, removeFavorite, arg.PlayerID, arg.ServerID)
 	return err
 }
+
+const updateFavoriteNote = `-- name: UpdateFavoriteNote :execrows
+UPDATE server_favorites
+SET note = ?
+WHERE player_id = ? AND server_id = ?
+`
+
+type UpdateFavoriteNoteParams struct {
+	Note     *string `json:"note"`
+	PlayerID int64   `json:"player_id"`
+	ServerID int64   `json:"server_id"`
+}
+
+func (q *Queries) UpdateFavoriteNote(ctx context.Context, db DBTX, arg *UpdateFavoriteNoteParams) (int64, error) {
+	result, err := db.ExecContext(ctx, updateFavoriteNote, arg.Note, arg.PlayerID, arg.ServerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
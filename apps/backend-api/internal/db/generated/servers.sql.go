@@ -9,6 +9,17 @@ import (
 	"context"
 )
 
+const countServerMatches = `-- name: CountServerMatches :one
+SELECT COUNT(*) FROM matches WHERE server_id = ?
+`
+
+func (q *Queries) CountServerMatches(ctx context.Context, db DBTX, serverID int64) (int64, error) {
+	row := db.QueryRowContext(ctx, countServerMatches, serverID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createServer = `-- name: CreateServer :one
 INSERT INTO servers (
     ip_address,
@@ -131,6 +142,7 @@ WHERE is_online = 1
   AND (version = ?3 OR ?3 IS NULL)
   AND (current_players >= ?4 OR ?4 = -1)
   AND (current_players <= ?5 OR ?5 = -1)
+  AND (name LIKE '%' || ?6 || '%' ESCAPE '\' OR ?6 IS NULL)
 ORDER BY server_id
 `
 
@@ -140,6 +152,7 @@ type ListActiveServersParams struct {
 	Version          *string `json:"version"`
 	CurrentPlayers   int64   `json:"current_players"`
 	CurrentPlayers_2 int64   `json:"current_players_2"`
+	Name             *string `json:"name"`
 }
 
 func (q *Queries) ListActiveServers(ctx context.Context, db DBTX, arg *ListActiveServersParams) ([]*Server, error) {
@@ -149,6 +162,7 @@ func (q *Queries) ListActiveServers(ctx context.Context, db DBTX, arg *ListActiv
 		arg.Version,
 		arg.CurrentPlayers,
 		arg.CurrentPlayers_2,
+		arg.Name,
 	)
 	if err != nil {
 		return nil, err
@@ -237,6 +251,35 @@ func (q *Queries) MarkServerOffline(ctx context.Context, db DBTX, serverID int64
 	return err
 }
 
+const markStaleServersOffline = `-- name: MarkStaleServersOffline :execrows
+UPDATE servers
+SET is_online = 0
+WHERE is_online = 1
+  AND (last_heartbeat IS NULL OR last_heartbeat < ?1)
+`
+
+// last_heartbeat is stored as "2006-01-02T15:04:05Z", so the cutoff compares as text.
+func (q *Queries) MarkStaleServersOffline(ctx context.Context, db DBTX, cutoff *string) (int64, error) {
+	result, err := db.ExecContext(ctx, markStaleServersOffline, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retireServer = `-- name: RetireServer :exec
+UPDATE servers
+SET auth_token = NULL, is_online = 0
+WHERE server_id = ?
+`
+
+// Revokes a deregistered server's auth token and takes it offline while keeping
+// the row for the matches recorded against it.
+func (q *Queries) RetireServer(ctx context.Context, db DBTX, serverID int64) error {
+	_, err := db.ExecContext(ctx, retireServer, serverID)
+	return err
+}
+
 const updateServerHeartbeat = `-- name: UpdateServerHeartbeat :exec
 UPDATE servers
 SET last_heartbeat = ?, current_players = ?, is_online = 1, map_rotation = ?
@@ -259,3 +302,45 @@ func (q *Queries) UpdateServerHeartbeat(ctx context.Context, db DBTX, arg *Updat
 	)
 	return err
 }
+
+const updateServerMetadata = `-- name: UpdateServerMetadata :one
+UPDATE servers
+SET name = ?, map_rotation = ?, region = ?, max_players = ?
+WHERE server_id = ?
+RETURNING server_id, ip_address, port, auth_token, name, map_rotation, max_players, current_players, is_online, last_heartbeat, region, version, created_at
+`
+
+type UpdateServerMetadataParams struct {
+	Name        string  `json:"name"`
+	MapRotation *string `json:"map_rotation"`
+	Region      *string `json:"region"`
+	MaxPlayers  int64   `json:"max_players"`
+	ServerID    int64   `json:"server_id"`
+}
+
+func (q *Queries) UpdateServerMetadata(ctx context.Context, db DBTX, arg *UpdateServerMetadataParams) (*Server, error) {
+	row := db.QueryRowContext(ctx, updateServerMetadata,
+		arg.Name,
+		arg.MapRotation,
+		arg.Region,
+		arg.MaxPlayers,
+		arg.ServerID,
+	)
+	var i Server
+	err := row.Scan(
+		&i.ServerID,
+		&i.IpAddress,
+		&i.Port,
+		&i.AuthToken,
+		&i.Name,
+		&i.MapRotation,
+		&i.MaxPlayers,
+		&i.CurrentPlayers,
+		&i.IsOnline,
+		&i.LastHeartbeat,
+		&i.Region,
+		&i.Version,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
@@ -12,18 +12,20 @@ import (
 )
 
 const createSession = `-- name: CreateSession :exec
-INSERT INTO sessions (player_id, token, expires_at, ip_address, user_agent)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO sessions (player_id, token, expires_at, ip_address, user_agent, started_at)
+VALUES (?, ?, ?, ?, ?, ?)
 `
 
 type CreateSessionParams struct {
-	PlayerID  int64           `json:"player_id"`
-	Token     string          `json:"token"`
-	ExpiresAt types.Timestamp `json:"expires_at"`
-	IpAddress *string         `json:"ip_address"`
-	UserAgent *string         `json:"user_agent"`
+	PlayerID  int64               `json:"player_id"`
+	Token     string              `json:"token"`
+	ExpiresAt types.Timestamp     `json:"expires_at"`
+	IpAddress *string             `json:"ip_address"`
+	UserAgent *string             `json:"user_agent"`
+	StartedAt types.NullTimestamp `json:"started_at"`
 }
 
+// started_at is the login time, carried over to the sessions created by refreshing it.
 func (q *Queries) CreateSession(ctx context.Context, db DBTX, arg *CreateSessionParams) error {
 	_, err := db.ExecContext(ctx, createSession,
 		arg.PlayerID,
@@ -31,10 +33,23 @@ func (q *Queries) CreateSession(ctx context.Context, db DBTX, arg *CreateSession
 		arg.ExpiresAt,
 		arg.IpAddress,
 		arg.UserAgent,
+		arg.StartedAt,
 	)
 	return err
 }
 
+const deleteAllSessionsForPlayer = `-- name: DeleteAllSessionsForPlayer :execrows
+DELETE FROM sessions WHERE player_id = ?
+`
+
+func (q *Queries) DeleteAllSessionsForPlayer(ctx context.Context, db DBTX, playerID int64) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteAllSessionsForPlayer, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
 DELETE FROM sessions WHERE expires_at < ?
 `
@@ -44,26 +59,34 @@ func (q *Queries) DeleteExpiredSessions(ctx context.Context, db DBTX, expiresAt
 	return err
 }
 
-const deleteSession = `-- name: DeleteSession :exec
-DELETE FROM sessions WHERE token = ?
+const deleteOtherSessionsByPlayer = `-- name: DeleteOtherSessionsByPlayer :execrows
+DELETE FROM sessions WHERE player_id = ? AND token != ?
 `
 
-func (q *Queries) DeleteSession(ctx context.Context, db DBTX, token string) error {
-	_, err := db.ExecContext(ctx, deleteSession, token)
-	return err
+type DeleteOtherSessionsByPlayerParams struct {
+	PlayerID int64  `json:"player_id"`
+	Token    string `json:"token"`
 }
 
-const deleteSessionsByPlayer = `-- name: DeleteSessionsByPlayer :exec
-DELETE FROM sessions WHERE player_id = ?
+func (q *Queries) DeleteOtherSessionsByPlayer(ctx context.Context, db DBTX, arg *DeleteOtherSessionsByPlayerParams) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteOtherSessionsByPlayer, arg.PlayerID, arg.Token)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token = ?
 `
 
-func (q *Queries) DeleteSessionsByPlayer(ctx context.Context, db DBTX, playerID int64) error {
-	_, err := db.ExecContext(ctx, deleteSessionsByPlayer, playerID)
+func (q *Queries) DeleteSession(ctx context.Context, db DBTX, token string) error {
+	_, err := db.ExecContext(ctx, deleteSession, token)
 	return err
 }
 
 const getSessionByToken = `-- name: GetSessionByToken :one
-SELECT session_id, player_id, token, expires_at, created_at, ip_address, user_agent FROM sessions WHERE token = ?
+SELECT session_id, player_id, token, expires_at, created_at, ip_address, user_agent, started_at FROM sessions WHERE token = ?
 `
 
 func (q *Queries) GetSessionByToken(ctx context.Context, db DBTX, token string) (*Session, error) {
@@ -77,6 +100,7 @@ func (q *Queries) GetSessionByToken(ctx context.Context, db DBTX, token string)
 		&i.CreatedAt,
 		&i.IpAddress,
 		&i.UserAgent,
+		&i.StartedAt,
 	)
 	return &i, err
 }
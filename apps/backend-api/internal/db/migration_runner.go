@@ -1,11 +1,14 @@
 package db
 
 import (
+	"bufio"
 	"database/sql"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
@@ -23,14 +26,48 @@ func RunMigrations(db *sql.DB) error {
 
 // RunMigrationsWithDir runs all pending migrations from the specified directory.
 func RunMigrationsWithDir(db *sql.DB, migrationDir string) error {
-	goose.SetBaseFS(nil)
-	goose.SetLogger(log.New(os.Stdout, "[migrations] ", log.LstdFlags))
+	return MigrateUpWithDir(db, migrationDir, MigrateOptions{})
+}
 
-	if err := goose.SetDialect("sqlite"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
+// MigrateUp applies pending migrations according to opts using migrations
+// directory located in "./migrations" relative to the current working directory.
+func MigrateUp(db *sql.DB, opts MigrateOptions) error {
+	migrationDir, err := getMigrationDir()
+	if err != nil {
+		return fmt.Errorf("failed to get migration directory: %w", err)
+	}
+	return MigrateUpWithDir(db, migrationDir, opts)
+}
+
+// MigrateOptions controls how MigrateUpWithDir and MigrateDownWithDir run.
+type MigrateOptions struct {
+	// To is the version to migrate to. When nil, up applies every pending
+	// migration and down rolls back only the latest one.
+	To *int64
+	// DryRun prints the SQL that would run instead of applying it.
+	DryRun bool
+	// Out receives the dry-run output; defaults to os.Stdout.
+	Out io.Writer
+}
+
+// MigrateUpWithDir applies pending migrations from the specified directory,
+// stopping at opts.To when it is set.
+func MigrateUpWithDir(db *sql.DB, migrationDir string, opts MigrateOptions) error {
+	if opts.DryRun {
+		return dryRun(db, migrationDir, true, opts)
+	}
+
+	if err := setupGoose(); err != nil {
+		return err
 	}
 
-	if err := goose.Up(db, migrationDir); err != nil {
+	var err error
+	if opts.To != nil {
+		err = goose.UpTo(db, migrationDir, *opts.To)
+	} else {
+		err = goose.Up(db, migrationDir)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
@@ -49,14 +86,38 @@ func Rollback(db *sql.DB) error {
 
 // RollbackWithDir rolls back the latest migration from the specified directory.
 func RollbackWithDir(db *sql.DB, migrationDir string) error {
-	goose.SetBaseFS(nil)
-	goose.SetLogger(log.New(os.Stdout, "[migrations] ", log.LstdFlags))
+	return MigrateDownWithDir(db, migrationDir, MigrateOptions{})
+}
 
-	if err := goose.SetDialect("sqlite"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
+// MigrateDown rolls back migrations according to opts using migrations
+// directory located in "./migrations" relative to the current working directory.
+func MigrateDown(db *sql.DB, opts MigrateOptions) error {
+	migrationDir, err := getMigrationDir()
+	if err != nil {
+		return fmt.Errorf("failed to get migration directory: %w", err)
+	}
+	return MigrateDownWithDir(db, migrationDir, opts)
+}
+
+// MigrateDownWithDir rolls back migrations from the specified directory. With
+// opts.To set, every applied migration newer than it is rolled back, newest
+// first; otherwise only the latest one is.
+func MigrateDownWithDir(db *sql.DB, migrationDir string, opts MigrateOptions) error {
+	if opts.DryRun {
+		return dryRun(db, migrationDir, false, opts)
+	}
+
+	if err := setupGoose(); err != nil {
+		return err
 	}
 
-	if err := goose.Down(db, migrationDir); err != nil {
+	var err error
+	if opts.To != nil {
+		err = goose.DownTo(db, migrationDir, *opts.To)
+	} else {
+		err = goose.Down(db, migrationDir)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to rollback migration: %w", err)
 	}
 
@@ -75,14 +136,165 @@ func Status(db *sql.DB) error {
 
 // StatusWithDir prints the migration status from the specified directory.
 func StatusWithDir(db *sql.DB, migrationDir string) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+
+	return goose.Status(db, migrationDir)
+}
+
+// plannedMigration is a migration a dry run would apply or roll back.
+type plannedMigration struct {
+	Version int64
+	Source  string
+	SQL     string
+}
+
+// plan returns the migrations MigrateUpWithDir or MigrateDownWithDir would run
+// for to, in the order they would run, without modifying the database.
+func plan(db *sql.DB, migrationDir string, up bool, to *int64) ([]plannedMigration, error) {
+	if err := setupGoose(); err != nil {
+		return nil, err
+	}
+
+	migrations, err := goose.CollectMigrations(migrationDir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected goose.Migrations
+	if up {
+		for _, m := range migrations {
+			if !applied[m.Version] && (to == nil || m.Version <= *to) {
+				selected = append(selected, m)
+			}
+		}
+	} else {
+		// Newest first, so later migrations are undone before the ones they build on
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if !applied[m.Version] || (to != nil && m.Version <= *to) {
+				continue
+			}
+			selected = append(selected, m)
+			if to == nil {
+				break
+			}
+		}
+	}
+
+	planned := make([]plannedMigration, 0, len(selected))
+	for _, m := range selected {
+		data, err := os.ReadFile(m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", m.Source, err)
+		}
+		planned = append(planned, plannedMigration{
+			Version: m.Version,
+			Source:  filepath.Base(m.Source),
+			SQL:     migrationSection(string(data), up),
+		})
+	}
+	return planned, nil
+}
+
+// appliedVersions reads the goose version table without creating it, so a dry
+// run against a fresh database leaves it untouched.
+func appliedVersions(db *sql.DB) (map[int64]bool, error) {
+	applied := make(map[int64]bool)
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, goose.TableName()).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check migration table: %w", err)
+	}
+	if count == 0 {
+		return applied, nil
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`SELECT version_id, is_applied FROM %s ORDER BY id`, goose.TableName()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration table: %w", err)
+	}
+	defer rows.Close()
+
+	// Later rows supersede earlier ones for the same version
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return nil, fmt.Errorf("failed to read migration table: %w", err)
+		}
+		applied[version] = isApplied
+	}
+	delete(applied, 0)
+	return applied, rows.Err()
+}
+
+// migrationSection returns the statements under the "-- +goose Up" or
+// "-- +goose Down" annotation of a SQL migration, without goose annotations.
+func migrationSection(source string, up bool) string {
+	want := "-- +goose down"
+	if up {
+		want = "-- +goose up"
+	}
+
+	var lines []string
+	inSection := false
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(trimmed, "-- +goose up") || strings.HasPrefix(trimmed, "-- +goose down") {
+			inSection = strings.HasPrefix(trimmed, want)
+			continue
+		}
+		if !inSection || strings.HasPrefix(trimmed, "-- +goose") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func dryRun(db *sql.DB, migrationDir string, up bool, opts MigrateOptions) error {
+	planned, err := plan(db, migrationDir, up, opts.To)
+	if err != nil {
+		return err
+	}
+
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if len(planned) == 0 {
+		_, err := fmt.Fprintln(out, "-- no migrations to run")
+		return err
+	}
+
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	for _, m := range planned {
+		if _, err := fmt.Fprintf(out, "-- %s (%s)\n%s\n\n", m.Source, direction, m.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setupGoose() error {
 	goose.SetBaseFS(nil)
 	goose.SetLogger(log.New(os.Stdout, "[migrations] ", log.LstdFlags))
 
 	if err := goose.SetDialect("sqlite"); err != nil {
 		return fmt.Errorf("failed to set dialect: %w", err)
 	}
-
-	return goose.Status(db, migrationDir)
+	return nil
 }
 
 func getMigrationDir() (string, error) {
@@ -1,9 +1,12 @@
 package db
 
 import (
+	"bytes"
 	"database/sql"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	_ "modernc.org/sqlite"
@@ -60,6 +63,8 @@ func TestRunMigrations(t *testing.T) {
 		"loot_table_entries",
 		"currency_transactions",
 		"join_tokens",
+		"server_joins",
+		"notifications",
 	}
 
 	for _, table := range tables {
@@ -81,6 +86,210 @@ func TestRunMigrations(t *testing.T) {
 	}
 }
 
+// writeTestMigrations writes three migrations whose Down sections record their
+// version in rollback_log, so tests can check the order of rollbacks.
+func writeTestMigrations(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"00001_create_a.sql": `-- +goose Up
+CREATE TABLE a (id INTEGER PRIMARY KEY);
+CREATE TABLE rollback_log (id INTEGER PRIMARY KEY AUTOINCREMENT, version INTEGER NOT NULL);
+
+-- +goose Down
+DROP TABLE rollback_log;
+DROP TABLE a;
+`,
+		"00002_create_b.sql": `-- +goose Up
+CREATE TABLE b (id INTEGER PRIMARY KEY);
+
+-- +goose Down
+INSERT INTO rollback_log (version) VALUES (2);
+DROP TABLE b;
+`,
+		"00003_create_c.sql": `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE c (id INTEGER PRIMARY KEY);
+-- +goose StatementEnd
+
+-- +goose Down
+INSERT INTO rollback_log (version) VALUES (3);
+DROP TABLE c;
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write migration %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func openMigrationTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, name).Scan(&count); err != nil {
+		t.Fatalf("Failed to query for table %s: %v", name, err)
+	}
+	return count == 1
+}
+
+func currentVersion(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+	var version int64
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied = 1`).Scan(&version); err != nil {
+		t.Fatalf("Failed to read migration version: %v", err)
+	}
+	return version
+}
+
+func versionPtr(v int64) *int64 {
+	return &v
+}
+
+func TestMigrateUpToMiddleVersion(t *testing.T) {
+	dir := writeTestMigrations(t)
+	db := openMigrationTestDB(t)
+
+	if err := MigrateUpWithDir(db, dir, MigrateOptions{To: versionPtr(2)}); err != nil {
+		t.Fatalf("MigrateUpWithDir failed: %v", err)
+	}
+
+	if got := currentVersion(t, db); got != 2 {
+		t.Errorf("Expected version 2, got %d", got)
+	}
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Error("Expected tables a and b to exist")
+	}
+	if tableExists(t, db, "c") {
+		t.Error("Expected table c not to exist")
+	}
+}
+
+func TestMigrateDownToMiddleVersion(t *testing.T) {
+	dir := writeTestMigrations(t)
+	db := openMigrationTestDB(t)
+
+	if err := RunMigrationsWithDir(db, dir); err != nil {
+		t.Fatalf("RunMigrationsWithDir failed: %v", err)
+	}
+	if err := MigrateDownWithDir(db, dir, MigrateOptions{To: versionPtr(2)}); err != nil {
+		t.Fatalf("MigrateDownWithDir failed: %v", err)
+	}
+
+	if got := currentVersion(t, db); got != 2 {
+		t.Errorf("Expected version 2, got %d", got)
+	}
+	if !tableExists(t, db, "b") {
+		t.Error("Expected table b to exist")
+	}
+	if tableExists(t, db, "c") {
+		t.Error("Expected table c to be dropped")
+	}
+}
+
+func TestMigrateDownPastTargetRollsBackInOrder(t *testing.T) {
+	dir := writeTestMigrations(t)
+	db := openMigrationTestDB(t)
+
+	if err := RunMigrationsWithDir(db, dir); err != nil {
+		t.Fatalf("RunMigrationsWithDir failed: %v", err)
+	}
+	if err := MigrateDownWithDir(db, dir, MigrateOptions{To: versionPtr(1)}); err != nil {
+		t.Fatalf("MigrateDownWithDir failed: %v", err)
+	}
+
+	if got := currentVersion(t, db); got != 1 {
+		t.Errorf("Expected version 1, got %d", got)
+	}
+	if tableExists(t, db, "b") || tableExists(t, db, "c") {
+		t.Error("Expected tables b and c to be dropped")
+	}
+
+	rows, err := db.Query(`SELECT version FROM rollback_log ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to read rollback log: %v", err)
+	}
+	defer rows.Close()
+	var order []int64
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("Failed to scan rollback log: %v", err)
+		}
+		order = append(order, v)
+	}
+	if want := []int64{3, 2}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected rollback order %v, got %v", want, order)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	dir := writeTestMigrations(t)
+	db := openMigrationTestDB(t)
+
+	var out bytes.Buffer
+	if err := MigrateUpWithDir(db, dir, MigrateOptions{To: versionPtr(2), DryRun: true, Out: &out}); err != nil {
+		t.Fatalf("MigrateUpWithDir dry run failed: %v", err)
+	}
+	if tableExists(t, db, "goose_db_version") || tableExists(t, db, "a") {
+		t.Error("Expected dry run to leave a fresh database untouched")
+	}
+	text := out.String()
+	if !strings.Contains(text, "CREATE TABLE a") || !strings.Contains(text, "CREATE TABLE b") {
+		t.Errorf("Expected dry run to print migrations 1 and 2, got:\n%s", text)
+	}
+	if strings.Contains(text, "CREATE TABLE c") || strings.Contains(text, "DROP TABLE") {
+		t.Errorf("Expected dry run to print only up sections of migrations 1 and 2, got:\n%s", text)
+	}
+
+	if err := RunMigrationsWithDir(db, dir); err != nil {
+		t.Fatalf("RunMigrationsWithDir failed: %v", err)
+	}
+	out.Reset()
+	if err := MigrateDownWithDir(db, dir, MigrateOptions{To: versionPtr(1), DryRun: true, Out: &out}); err != nil {
+		t.Fatalf("MigrateDownWithDir dry run failed: %v", err)
+	}
+	if got := currentVersion(t, db); got != 3 {
+		t.Errorf("Expected dry run to keep version 3, got %d", got)
+	}
+	text = out.String()
+	c, b := strings.Index(text, "DROP TABLE c"), strings.Index(text, "DROP TABLE b")
+	if c < 0 || b < 0 || c > b {
+		t.Errorf("Expected dry run to print rollbacks of 3 then 2, got:\n%s", text)
+	}
+	if strings.Contains(text, "DROP TABLE a") {
+		t.Errorf("Expected dry run not to roll back migration 1, got:\n%s", text)
+	}
+}
+
+func TestMigrationSection(t *testing.T) {
+	source := `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE x (id INTEGER);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE x;
+`
+	if got := migrationSection(source, true); got != "CREATE TABLE x (id INTEGER);" {
+		t.Errorf("Unexpected up section: %q", got)
+	}
+	if got := migrationSection(source, false); got != "DROP TABLE x;" {
+		t.Errorf("Unexpected down section: %q", got)
+	}
+}
+
 func findMigrationsDir() (string, error) {
 	// Try from current working directory (module root)
 	dir := "migrations"
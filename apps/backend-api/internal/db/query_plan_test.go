@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+var placeholderPattern = regexp.MustCompile(`sqlc\.n?arg\(\w+\)|\?\d+`)
+
+// loadNamedQuery returns the SQL of the "-- name: <name>" block in a queries
+// file, with sqlc.arg and numbered placeholders replaced by plain "?".
+func loadNamedQuery(t *testing.T, file, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("sql", "queries", file))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", file, err)
+	}
+	var block []string
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "-- name: ") {
+			if inBlock {
+				break
+			}
+			inBlock = strings.HasPrefix(line, "-- name: "+name+" ")
+			continue
+		}
+		if inBlock && !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			block = append(block, line)
+		}
+	}
+	if len(block) == 0 {
+		t.Fatalf("Query %s not found in %s", name, file)
+	}
+	return placeholderPattern.ReplaceAllString(strings.Join(block, "\n"), "?")
+}
+
+// queryPlan returns the detail column of every EXPLAIN QUERY PLAN row. All
+// parameters are bound to NULL since the plan does not depend on them.
+func queryPlan(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+	args := make([]any, strings.Count(query, "?"))
+	rows, err := db.Query("EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		t.Fatalf("EXPLAIN QUERY PLAN failed: %v", err)
+	}
+	defer rows.Close()
+	var details []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("Failed to scan plan row: %v", err)
+		}
+		details = append(details, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Failed to read plan: %v", err)
+	}
+	return details
+}
+
+func TestHotQueriesUseIndexes(t *testing.T) {
+	migrationsSrc, err := findMigrationsDir()
+	if err != nil {
+		t.Skipf("Could not find migration files: %v", err)
+	}
+	migrationsDst := filepath.Join(t.TempDir(), "migrations")
+	if err := copyMigrationFiles(migrationsSrc, migrationsDst); err != nil {
+		t.Skipf("Could not copy migration files: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	if err := RunMigrationsWithDir(db, migrationsDst); err != nil {
+		t.Fatalf("RunMigrations failed: %v", err)
+	}
+
+	tests := []struct {
+		file  string
+		query string
+		// want lists plan fragments that must all appear: the index searched and
+		// the constraint it serves (SQLite may report it as a covering index)
+		want []string
+	}{
+		{"sessions.sql", "GetSessionByToken", []string{"sqlite_autoindex_sessions_1 (token=?)"}},
+		{"player.sql", "GetPlayerByEmail", []string{"sqlite_autoindex_players_2 (email=?)"}},
+		{"servers.sql", "ListActiveServers", []string{"idx_servers_is_online (is_online=?)"}},
+		{"leaderboards_daily.sql", "GetDailyLeaderboard", []string{
+			"idx_matches_start_time (start_time>? AND start_time<?)",
+			"idx_player_match_stats_match_id (match_id=?)",
+		}},
+		{"leaderboards_weekly.sql", "GetWeeklyLeaderboard", []string{
+			"idx_matches_start_time (start_time>? AND start_time<?)",
+			"idx_player_match_stats_match_id (match_id=?)",
+		}},
+		{"leaderboards_weekly.sql", "GetWeeklyPlayerRank", []string{
+			"idx_matches_start_time (start_time>? AND start_time<?)",
+			"idx_player_match_stats_match_id (match_id=?)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			plan := queryPlan(t, db, loadNamedQuery(t, tt.file, tt.query))
+			joined := strings.Join(plan, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(joined, want) {
+					t.Errorf("Expected plan to contain %q, got:\n%s", want, joined)
+				}
+			}
+		})
+	}
+}
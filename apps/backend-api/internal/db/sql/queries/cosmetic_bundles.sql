@@ -0,0 +1,13 @@
+-- name: GetCosmeticBundle :one
+SELECT * FROM cosmetic_bundles WHERE bundle_id = ?;
+
+-- name: ListActiveCosmeticBundles :many
+SELECT * FROM cosmetic_bundles
+WHERE is_active = 1
+ORDER BY bundle_id;
+
+-- name: ListCosmeticBundleItems :many
+SELECT ci.* FROM cosmetic_bundle_items cbi
+JOIN cosmetic_items ci ON ci.cosmetic_id = cbi.cosmetic_id
+WHERE cbi.bundle_id = ?
+ORDER BY ci.cosmetic_id;
@@ -11,4 +11,42 @@ WHERE ci.is_prestige_only = 1
 
 -- name: GrantCosmeticToPlayer :exec
 INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via)
-VALUES (?, ?, ?);
\ No newline at end of file
+VALUES (?, ?, ?);
+
+-- name: ListPrestigeBackfillCandidates :many
+SELECT pp.player_id FROM player_progression pp
+LEFT JOIN player_cosmetics pc ON pc.player_id = pp.player_id AND pc.cosmetic_id = ?1
+WHERE pp.prestige_level >= ?2
+    AND pc.cosmetic_id IS NULL
+    AND pp.player_id > ?3
+ORDER BY pp.player_id
+LIMIT ?4;
+
+-- name: CreateCosmeticItem :one
+INSERT INTO cosmetic_items (name, description, slot, category, rarity, unlock_level, data_cost, is_prestige_only, max_per_day, available_until, equip_restriction)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateCosmeticItem :one
+UPDATE cosmetic_items
+SET name = ?, description = ?, slot = ?, category = ?, rarity = ?, unlock_level = ?,
+    data_cost = ?, is_prestige_only = ?, max_per_day = ?, available_until = ?, equip_restriction = ?
+WHERE cosmetic_id = ?
+RETURNING *;
+
+-- name: CountCosmeticItemReferences :one
+-- Rows that would be cascade-deleted along with the cosmetic.
+SELECT
+    (SELECT COUNT(*) FROM loot_table_entries lte WHERE lte.cosmetic_id = ?1) AS loot_entries,
+    (SELECT COUNT(*) FROM player_cosmetics pc WHERE pc.cosmetic_id = ?1) AS owners,
+    (SELECT COUNT(*) FROM cosmetic_bundle_items cbi WHERE cbi.cosmetic_id = ?1) AS bundle_items;
+
+-- name: DeleteCosmeticItem :execrows
+DELETE FROM cosmetic_items WHERE cosmetic_id = ?;
+
+-- name: GetCosmeticItemByName :one
+-- Names aren't unique; the oldest item wins, which keeps seeding stable.
+SELECT * FROM cosmetic_items
+WHERE name = ?
+ORDER BY cosmetic_id
+LIMIT 1;
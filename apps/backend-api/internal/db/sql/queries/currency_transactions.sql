@@ -9,4 +9,33 @@ SELECT * FROM currency_transactions WHERE player_id = ? ORDER BY created_at DESC
 SELECT * FROM currency_transactions WHERE player_id = ? AND transaction_type = ? ORDER BY created_at DESC LIMIT ? OFFSET ?;
 
 -- name: CountCurrencyTransactionsByPlayer :one
-SELECT COUNT(*) FROM currency_transactions WHERE player_id = ?;
\ No newline at end of file
+SELECT COUNT(*) FROM currency_transactions WHERE player_id = ?;
+
+-- name: CountPurchasesToday :one
+SELECT COUNT(*) FROM currency_transactions
+WHERE player_id = ?
+    AND reference_id = ?
+    AND transaction_type = 'purchase'
+    AND created_at >= strftime('%Y-%m-%dT00:00:00Z', 'now');
+
+-- name: CountRefundsAfter :one
+-- Counts refunds of a reference recorded after the given transaction, so an
+-- undone purchase is not refunded again.
+SELECT COUNT(*) FROM currency_transactions
+WHERE player_id = ?
+    AND reference_id = ?
+    AND transaction_type = 'refund'
+    AND transaction_id > ?;
+
+-- name: GetLatestPurchase :one
+SELECT * FROM currency_transactions
+WHERE player_id = ? AND reference_id = ? AND transaction_type = 'purchase'
+ORDER BY created_at DESC, transaction_id DESC
+LIMIT 1;
+
+-- name: GetCurrencyTransactions :many
+SELECT transaction_id, amount, balance_after, transaction_type, reference_id, created_at
+FROM currency_transactions
+WHERE player_id = ?
+ORDER BY created_at DESC, transaction_id DESC
+LIMIT ? OFFSET ?;
@@ -0,0 +1,28 @@
+-- name: GetCurrencyCirculation :one
+SELECT
+    CAST(COALESCE(SUM(data_currency), 0) AS INTEGER) AS total_circulation,
+    CAST(COALESCE(AVG(data_currency), 0) AS REAL) AS average_balance,
+    COUNT(*) AS player_count
+FROM player_progression;
+
+-- name: GetCurrencyTotalsByType :many
+SELECT
+    transaction_type,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS INTEGER) AS earned,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS INTEGER) AS spent
+FROM currency_transactions
+GROUP BY transaction_type
+ORDER BY transaction_type;
+
+-- name: GetTopCosmeticSellers :many
+SELECT
+    ci.cosmetic_id,
+    ci.name,
+    COUNT(*) AS units_sold,
+    CAST(COALESCE(SUM(-ct.amount), 0) AS INTEGER) AS revenue
+FROM currency_transactions ct
+JOIN cosmetic_items ci ON ci.cosmetic_id = ct.reference_id
+WHERE ct.transaction_type = 'purchase'
+GROUP BY ci.cosmetic_id, ci.name
+ORDER BY units_sold DESC, revenue DESC, ci.cosmetic_id
+LIMIT ?;
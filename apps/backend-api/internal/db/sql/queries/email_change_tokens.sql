@@ -0,0 +1,20 @@
+-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+    token,
+    player_id,
+    new_email,
+    expires_at
+) VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetEmailChangeToken :one
+SELECT * FROM email_change_tokens WHERE token = ?;
+
+-- name: MarkEmailChangeTokenUsed :execrows
+UPDATE email_change_tokens
+SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE token = ? AND used_at IS NULL;
+
+-- name: DeletePendingEmailChangeTokens :exec
+-- Drops a player's unused tokens so only the latest email change request can be confirmed.
+DELETE FROM email_change_tokens WHERE player_id = ? AND used_at IS NULL;
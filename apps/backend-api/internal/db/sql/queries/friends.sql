@@ -1,5 +1,8 @@
--- name: CreateFriendRequest :exec
-INSERT INTO friends (player_id, friend_id, status) VALUES (?1, ?2, 'pending');
+-- name: CreateFriendRequest :execrows
+-- Inserts nothing when a row for the pair already exists, so callers can tell
+-- from the row count without a racy existence check.
+INSERT INTO friends (player_id, friend_id, status) VALUES (?1, ?2, 'pending')
+ON CONFLICT (player_id, friend_id) DO NOTHING;
 
 -- name: AcceptFriendRequest :exec
 UPDATE friends 
@@ -10,25 +13,59 @@ WHERE player_id = ?1 AND friend_id = ?2 AND status = 'pending';
 DELETE FROM friends 
 WHERE player_id = ?1 AND friend_id = ?2 AND status = 'pending';
 
+-- name: DeleteFriendRelationship :exec
+-- Removes pending and accepted rows in either direction, leaving blocks in place.
+DELETE FROM friends
+WHERE ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+  AND status != 'blocked';
+
+-- name: RemoveFriend :execrows
+-- Removes an accepted friendship, whichever direction (or both) it was stored in.
+DELETE FROM friends
+WHERE ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+  AND status = 'accepted';
+
+-- name: BlockPlayer :exec
+INSERT INTO friends (player_id, friend_id, status) VALUES (?1, ?2, 'blocked')
+ON CONFLICT (player_id, friend_id) DO UPDATE
+SET status = 'blocked', updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
+
+-- name: UnblockPlayer :execrows
+DELETE FROM friends
+WHERE player_id = ?1 AND friend_id = ?2 AND status = 'blocked';
+
+-- name: IsBlockedBetween :one
+-- Reports whether either player has blocked the other.
+SELECT CAST(EXISTS (
+  SELECT 1 FROM friends
+  WHERE status = 'blocked'
+    AND ((player_id = ?1 AND friend_id = ?2) OR (player_id = ?2 AND friend_id = ?1))
+) AS INTEGER) AS blocked;
+
 -- name: GetFriendRequest :one
 SELECT * FROM friends WHERE player_id = ?1 AND friend_id = ?2;
 
 -- name: ListFriends :many
-SELECT 
-  CAST(CASE 
-    WHEN f.player_id = ?1 THEN f.friend_id 
-    ELSE f.player_id 
-  END AS INTEGER) AS friend_player_id,
+-- Accepted friends regardless of which side sent the request. If both players
+-- requested each other and both rows were accepted, the friend is listed once.
+SELECT
+  fr.friend_player_id,
   p.username AS friend_username,
-  f.status,
-  f.created_at,
-  f.updated_at
-FROM friends f
-JOIN players p ON p.player_id = CAST(CASE 
-    WHEN f.player_id = ?1 THEN f.friend_id 
-    ELSE f.player_id 
-  END AS INTEGER)
-WHERE (f.player_id = ?1 OR f.friend_id = ?1) AND f.status = 'accepted';
+  fr.status,
+  MIN(fr.created_at) AS created_at,
+  MAX(fr.updated_at) AS updated_at
+FROM (
+  SELECT f.friend_id AS friend_player_id, f.status, f.created_at, f.updated_at
+  FROM friends f
+  WHERE f.player_id = ?1 AND f.status = 'accepted'
+  UNION
+  SELECT f.player_id AS friend_player_id, f.status, f.created_at, f.updated_at
+  FROM friends f
+  WHERE f.friend_id = ?1 AND f.status = 'accepted'
+) fr
+JOIN players p ON p.player_id = fr.friend_player_id
+GROUP BY fr.friend_player_id
+ORDER BY p.username;
 
 -- name: ListPendingIncoming :many
 SELECT f.player_id AS requester_player_id, p.username AS requester_username, f.created_at
@@ -16,6 +16,17 @@ WHERE token = ?
   AND expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
   AND used_at IS NULL;
 
+-- name: ConsumeJoinToken :one
+-- Validates and consumes a token in one statement so that concurrent
+-- validations of the same token cannot both succeed.
+UPDATE join_tokens
+SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE token = ?
+  AND server_id = ?
+  AND used_at IS NULL
+  AND expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+RETURNING *;
+
 -- name: MarkTokenUsed :exec
 UPDATE join_tokens
 SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
@@ -24,4 +35,16 @@ WHERE token = ?;
 -- name: DeleteExpiredTokens :exec
 DELETE FROM join_tokens
 WHERE expires_at <= strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
-   OR used_at IS NOT NULL;
\ No newline at end of file
+   OR used_at IS NOT NULL;
+
+-- name: DeleteServerJoinTokens :exec
+DELETE FROM join_tokens
+WHERE server_id = ?;
+
+-- name: MarkTokensUsed :execrows
+UPDATE join_tokens
+SET used_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE server_id = ?
+  AND token IN (sqlc.slice(tokens))
+  AND used_at IS NULL
+  AND expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
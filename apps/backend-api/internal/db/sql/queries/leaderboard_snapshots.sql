@@ -0,0 +1,39 @@
+-- name: GetLeaderboardSnapshotGeneratedAt :one
+-- Every row of a period and metric is written by the same refresh, so any row's
+-- generated_at dates the whole snapshot.
+SELECT generated_at FROM leaderboard_snapshots
+WHERE period = ? AND metric = ?
+LIMIT 1;
+
+-- name: ListLeaderboardSnapshot :many
+SELECT
+  player_id,
+  username,
+  total_score,
+  matches_played,
+  avg_kills_per_match,
+  avg_waves_survived,
+  metric_value,
+  ranking,
+  generated_at
+FROM leaderboard_snapshots
+WHERE period = ? AND metric = ?
+ORDER BY ranking
+LIMIT ? OFFSET ?;
+
+-- name: GetLeaderboardSnapshotPlayerRank :one
+SELECT
+  s.ranking,
+  (SELECT COUNT(*) FROM leaderboard_snapshots t
+   WHERE t.period = s.period AND t.metric = s.metric) AS total_players
+FROM leaderboard_snapshots s
+WHERE s.period = ? AND s.metric = ? AND s.player_id = ?;
+
+-- name: DeleteLeaderboardSnapshot :exec
+DELETE FROM leaderboard_snapshots WHERE period = ? AND metric = ?;
+
+-- name: InsertLeaderboardSnapshotEntry :exec
+INSERT INTO leaderboard_snapshots (
+  period, metric, ranking, player_id, username, total_score, matches_played,
+  avg_kills_per_match, avg_waves_survived, metric_value, generated_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
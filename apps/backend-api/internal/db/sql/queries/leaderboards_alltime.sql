@@ -1,4 +1,6 @@
 -- name: GetAllTimeLeaderboard :many
+-- metric selects the ranked column (score, kills, waves_survived or data_earned); the
+-- service whitelists it and any other value ranks by score.
 SELECT
   p.player_id,
   p.username,
@@ -6,8 +8,40 @@ SELECT
   COUNT(pms.match_id) AS matches_played,
   AVG(pms.zombies_killed) AS avg_kills_per_match,
   AVG(pms.waves_survived) AS avg_waves_survived,
-  CAST(ROW_NUMBER() OVER (ORDER BY SUM(pms.score) DESC) AS INTEGER) AS ranking
+  CAST(CASE sqlc.arg(metric)
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END AS INTEGER) AS metric_value,
+  CAST(ROW_NUMBER() OVER (ORDER BY CASE sqlc.arg(metric)
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END DESC) AS INTEGER) AS ranking
 FROM player_match_stats pms
 JOIN players p ON pms.player_id = p.player_id
+LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+WHERE COALESCE(ps.show_on_leaderboard, 1) = 1
 GROUP BY pms.player_id
-ORDER BY total_score DESC
\ No newline at end of file
+ORDER BY ranking
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: GetAllTimePlayerRank :one
+WITH ranked AS (
+  SELECT
+    pms.player_id,
+    CAST(ROW_NUMBER() OVER (ORDER BY CASE sqlc.arg(metric)
+      WHEN 'kills' THEN SUM(pms.zombies_killed)
+      WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+      WHEN 'data_earned' THEN SUM(pms.data_earned)
+      ELSE SUM(pms.score)
+    END DESC) AS INTEGER) AS ranking,
+    CAST(COUNT(*) OVER () AS INTEGER) AS total_players
+  FROM player_match_stats pms
+  LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+  WHERE COALESCE(ps.show_on_leaderboard, 1) = 1
+  GROUP BY pms.player_id
+)
+SELECT ranking, total_players FROM ranked WHERE player_id = sqlc.arg(player_id);
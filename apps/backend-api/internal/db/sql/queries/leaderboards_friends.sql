@@ -0,0 +1,66 @@
+-- name: GetFriendsLeaderboard :many
+-- Ranks only the player and their accepted friends (from either side of the
+-- friendship), so the cost follows the friend count rather than the whole board.
+-- since and until bound matches.start_time like the daily and weekly queries;
+-- both are NULL for all-time.
+WITH members AS (
+  SELECT sqlc.arg(player_id) AS player_id
+  UNION
+  SELECT f.friend_id FROM friends f WHERE f.player_id = sqlc.arg(player_id) AND f.status = 'accepted'
+  UNION
+  SELECT f.player_id FROM friends f WHERE f.friend_id = sqlc.arg(player_id) AND f.status = 'accepted'
+)
+SELECT
+  p.player_id,
+  p.username,
+  CAST(SUM(pms.score) AS INTEGER) AS total_score,
+  COUNT(pms.match_id) AS matches_played,
+  AVG(pms.zombies_killed) AS avg_kills_per_match,
+  AVG(pms.waves_survived) AS avg_waves_survived,
+  CAST(CASE sqlc.arg(metric)
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END AS INTEGER) AS metric_value,
+  CAST(ROW_NUMBER() OVER (ORDER BY CASE sqlc.arg(metric)
+    WHEN 'kills' THEN SUM(pms.zombies_killed)
+    WHEN 'waves_survived' THEN SUM(pms.waves_survived)
+    WHEN 'data_earned' THEN SUM(pms.data_earned)
+    ELSE SUM(pms.score)
+  END DESC) AS INTEGER) AS ranking
+FROM members mb
+JOIN player_match_stats pms ON pms.player_id = mb.player_id
+JOIN matches m ON pms.match_id = m.match_id
+JOIN players p ON pms.player_id = p.player_id
+LEFT JOIN player_settings ps ON pms.player_id = ps.player_id
+WHERE (sqlc.narg(since) IS NULL OR m.start_time >= sqlc.narg(since))
+  AND (sqlc.narg(until) IS NULL OR m.start_time < sqlc.narg(until))
+  AND COALESCE(ps.show_on_leaderboard, 1) = 1
+GROUP BY pms.player_id
+ORDER BY ranking;
+
+-- name: ListFriendsLeaderboardSnapshot :many
+-- The snapshot rows of the player and their accepted friends, re-ranked from 1
+-- in snapshot order.
+WITH members AS (
+  SELECT sqlc.arg(player_id) AS player_id
+  UNION
+  SELECT f.friend_id FROM friends f WHERE f.player_id = sqlc.arg(player_id) AND f.status = 'accepted'
+  UNION
+  SELECT f.player_id FROM friends f WHERE f.friend_id = sqlc.arg(player_id) AND f.status = 'accepted'
+)
+SELECT
+  s.player_id,
+  s.username,
+  s.total_score,
+  s.matches_played,
+  s.avg_kills_per_match,
+  s.avg_waves_survived,
+  s.metric_value,
+  CAST(ROW_NUMBER() OVER (ORDER BY s.ranking) AS INTEGER) AS ranking,
+  s.generated_at
+FROM members mb
+JOIN leaderboard_snapshots s ON s.player_id = mb.player_id
+WHERE s.period = sqlc.arg(period) AND s.metric = sqlc.arg(metric)
+ORDER BY ranking;
@@ -4,11 +4,31 @@ SELECT * FROM loadouts WHERE player_id = ? ORDER BY loadout_id;
 -- name: GetActiveLoadout :one
 SELECT * FROM loadouts WHERE player_id = ? AND is_active = 1;
 
--- name: CreateLoadout :exec
-INSERT INTO loadouts (player_id, name, is_active) VALUES (?, ?, ?);
+-- name: GetLoadout :one
+SELECT * FROM loadouts WHERE loadout_id = ? AND player_id = ?;
 
--- name: UpdateLoadoutActive :exec
-UPDATE loadouts SET is_active = ? WHERE loadout_id = ? AND player_id = ?;
+-- name: CreateLoadout :one
+INSERT INTO loadouts (player_id, name, is_active) VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: EnsureActiveLoadout :exec
+-- Creates an active "Default" loadout unless the player already has one; the
+-- one-active-loadout index turns a concurrent duplicate into a no-op.
+INSERT INTO loadouts (player_id, name, is_active) VALUES (?, 'Default', 1)
+ON CONFLICT DO NOTHING;
+
+-- name: UpdateLoadoutActive :execrows
+UPDATE loadouts
+SET is_active = ?, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE loadout_id = ? AND player_id = ?;
+
+-- name: DeactivateLoadouts :exec
+UPDATE loadouts
+SET is_active = 0, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE player_id = ? AND is_active = 1;
+
+-- name: DeleteInactiveLoadout :execrows
+DELETE FROM loadouts WHERE loadout_id = ? AND player_id = ? AND is_active = 0;
 
 -- name: GetLoadoutCosmetics :many
 SELECT lc.*, ci.slot AS cosmetic_slot FROM loadout_cosmetics lc
@@ -26,4 +46,12 @@ DELETE FROM loadout_cosmetics WHERE loadout_id = ? AND slot = ?;
 INSERT INTO loadout_cosmetics (loadout_id, cosmetic_id, slot) VALUES (?, ?, ?);
 
 -- name: GetCosmeticItem :one
-SELECT * FROM cosmetic_items WHERE cosmetic_id = ?;
\ No newline at end of file
+SELECT * FROM cosmetic_items WHERE cosmetic_id = ?;
+
+-- name: DeletePlayerLoadoutCosmetic :exec
+DELETE FROM loadout_cosmetics
+WHERE cosmetic_id = ?
+    AND loadout_id IN (SELECT loadout_id FROM loadouts WHERE player_id = ?);
+
+-- name: ClearLoadoutCosmetics :exec
+DELETE FROM loadout_cosmetics WHERE loadout_id = ?;
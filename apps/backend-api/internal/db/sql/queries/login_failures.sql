@@ -0,0 +1,22 @@
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures WHERE player_id = ?;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (player_id, failed_attempts)
+VALUES (?, 1)
+ON CONFLICT (player_id) DO UPDATE SET
+    failed_attempts = failed_attempts + 1,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+RETURNING *;
+
+-- name: LockAccount :exec
+-- Starts a lockout; failures after it are counted from zero again.
+UPDATE login_failures SET
+    failed_attempts = 0,
+    lockouts = lockouts + 1,
+    locked_until = ?,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE player_id = ?;
+
+-- name: ClearLoginFailures :exec
+DELETE FROM login_failures WHERE player_id = ?;
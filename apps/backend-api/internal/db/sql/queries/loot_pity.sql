@@ -0,0 +1,9 @@
+-- name: GetLootPity :one
+SELECT unlucky_rolls FROM loot_pity WHERE player_id = ?;
+
+-- name: SetLootPity :exec
+INSERT INTO loot_pity (player_id, unlucky_rolls)
+VALUES (?, ?)
+ON CONFLICT (player_id) DO UPDATE SET
+    unlucky_rolls = excluded.unlucky_rolls,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
@@ -26,4 +26,10 @@ WHERE loot_entry_id = ?;
 
 -- name: DeleteLootTableEntry :exec
 DELETE FROM loot_table_entries
-WHERE loot_entry_id = ?;
\ No newline at end of file
+WHERE loot_entry_id = ?;
+
+-- name: GetLootTableEntryByCosmetic :one
+SELECT * FROM loot_table_entries
+WHERE loot_table_id = ? AND cosmetic_id = ?
+ORDER BY loot_entry_id
+LIMIT 1;
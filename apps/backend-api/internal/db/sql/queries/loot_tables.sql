@@ -23,4 +23,10 @@ WHERE loot_table_id = ?;
 
 -- name: DeleteLootTable :exec
 DELETE FROM loot_tables
-WHERE loot_table_id = ?;
\ No newline at end of file
+WHERE loot_table_id = ?;
+
+-- name: GetLootTableByName :one
+SELECT * FROM loot_tables
+WHERE name = ?
+ORDER BY loot_table_id
+LIMIT 1;
@@ -8,14 +8,21 @@ INSERT INTO matches (
     outcome,
     waves_survived,
     total_zombies_killed,
-    total_players
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    total_players,
+    idempotency_key
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetMatch :one
 SELECT * FROM matches WHERE match_id = ?;
 
+-- name: GetMatchByIdempotencyKey :one
+SELECT * FROM matches
+WHERE server_id = ? AND idempotency_key = ?;
+
 -- name: GetPlayerMatchHistory :many
+-- Servers are LEFT JOINed so a match whose server row is gone still lists, with a null server_name.
+-- Ordered newest first with match_id as tiebreak; before_match_id resumes after that match.
 SELECT 
     m.*,
     pms.waves_survived as player_waves_survived,
@@ -29,14 +36,37 @@ SELECT
     pms.buildings_destroyed as player_buildings_destroyed,
     pms.healing_given as player_healing_given,
     pms.revives as player_revives,
-    pms.score as player_score
+    pms.score as player_score,
+    s.name as server_name
 FROM matches m
 JOIN player_match_stats pms ON m.match_id = pms.match_id
-WHERE pms.player_id = ?
-ORDER BY m.start_time DESC
-LIMIT ?;
+LEFT JOIN servers s ON m.server_id = s.server_id
+WHERE pms.player_id = sqlc.arg(player_id)
+  AND (sqlc.narg(before_match_id) IS NULL
+    OR (m.start_time, m.match_id) < (SELECT bm.start_time, bm.match_id FROM matches bm WHERE bm.match_id = sqlc.narg(before_match_id)))
+ORDER BY m.start_time DESC, m.match_id DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
 
 -- name: UpdateMatchOutcome :exec
 UPDATE matches
 SET outcome = ?, end_time = ?
-WHERE match_id = ?;
\ No newline at end of file
+WHERE match_id = ?;
+-- name: GetPlayerOutcomeCounts :many
+SELECT
+    m.outcome,
+    COUNT(*) AS match_count
+FROM matches m
+JOIN player_match_stats pms ON m.match_id = pms.match_id
+WHERE pms.player_id = ?
+GROUP BY m.outcome;
+
+-- name: GetPlayerRecentMatchTimes :many
+-- Lists the start and end times of a player's matches that ended within [window_start, window_end], excluding one match.
+SELECT m.match_id, m.start_time, m.end_time
+FROM matches m
+JOIN player_match_stats pms ON m.match_id = pms.match_id
+WHERE pms.player_id = sqlc.arg(player_id)
+  AND m.match_id != sqlc.arg(exclude_match_id)
+  AND m.end_time >= sqlc.arg(window_start)
+  AND m.end_time <= sqlc.arg(window_end)
+ORDER BY m.end_time DESC;
@@ -0,0 +1,48 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (player_id, notification_type, message, reference_id)
+VALUES (?, ?, ?, ?);
+
+-- name: BroadcastNotification :execrows
+INSERT INTO notifications (player_id, notification_type, message)
+SELECT player_id, CAST(sqlc.arg(notification_type) AS TEXT), CAST(sqlc.arg(message) AS TEXT)
+FROM players
+WHERE is_banned = 0;
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE player_id = ?
+ORDER BY is_read ASC, created_at DESC, notification_id DESC
+LIMIT ?;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE player_id = ? AND is_read = 0;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET is_read = 1,
+    read_at = COALESCE(read_at, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+WHERE notification_id = ? AND player_id = ?;
+
+-- name: TrimUnreadNotifications :exec
+DELETE FROM notifications
+WHERE player_id = ?
+  AND is_read = 0
+  AND notification_id NOT IN (
+    SELECT notification_id FROM notifications
+    WHERE player_id = ? AND is_read = 0
+    ORDER BY created_at DESC, notification_id DESC
+    LIMIT ?
+  );
+
+-- name: TrimAllUnreadNotifications :exec
+DELETE FROM notifications
+WHERE notification_id IN (
+    SELECT notification_id FROM (
+        SELECT notification_id,
+               ROW_NUMBER() OVER (PARTITION BY player_id ORDER BY created_at DESC, notification_id DESC) AS unread_rank
+        FROM notifications
+        WHERE is_read = 0
+    )
+    WHERE unread_rank > CAST(sqlc.arg(max_unread) AS INTEGER)
+);
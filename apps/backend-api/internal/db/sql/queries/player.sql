@@ -20,7 +20,36 @@ SELECT * FROM players WHERE email = ?;
 DELETE FROM players WHERE player_id = ?;
 
 -- name: UpdatePlayerProfile :exec
-UPDATE players SET username = ?, email = ? WHERE player_id = ?;
+UPDATE players SET username = ? WHERE player_id = ?;
+
+-- name: UpdatePlayerEmail :exec
+UPDATE players SET email = ? WHERE player_id = ?;
 
 -- name: UpdatePlayerPassword :exec
-UPDATE players SET password_hash = ? WHERE player_id = ?;
\ No newline at end of file
+UPDATE players SET password_hash = ? WHERE player_id = ?;
+
+-- name: BanPlayer :execrows
+-- A NULL banned_until bans permanently.
+UPDATE players SET is_banned = 1, banned_reason = ?, banned_until = ? WHERE player_id = ?;
+
+-- name: UnbanPlayer :execrows
+UPDATE players SET is_banned = 0, banned_reason = NULL, banned_until = NULL WHERE player_id = ?;
+
+-- name: ClearExpiredBan :execrows
+-- Only clears a temporary ban that has already ended, so a newer ban is left alone.
+UPDATE players SET is_banned = 0, banned_reason = NULL, banned_until = NULL
+WHERE player_id = ? AND is_banned = 1 AND banned_until IS NOT NULL
+  AND banned_until <= strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
+
+-- name: SearchPlayers :many
+-- query must have its LIKE wildcards escaped with '\'; NULL lists every player.
+SELECT player_id, username, email, is_banned, is_admin, created_at, last_login_at
+FROM players
+WHERE ?1 IS NULL
+   OR username LIKE '%' || ?1 || '%' ESCAPE '\'
+   OR email LIKE '%' || ?1 || '%' ESCAPE '\'
+ORDER BY username
+LIMIT ?2 OFFSET ?3;
+
+-- name: SetPlayerAdmin :exec
+UPDATE players SET is_admin = ? WHERE player_id = ?;
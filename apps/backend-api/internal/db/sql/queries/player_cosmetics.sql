@@ -9,4 +9,20 @@ ORDER BY pc.unlocked_at DESC;
 SELECT ci.*, pc.unlocked_at, pc.unlocked_via
 FROM cosmetic_items ci
 JOIN player_cosmetics pc ON ci.cosmetic_id = pc.cosmetic_id
-WHERE pc.player_id = ? AND pc.cosmetic_id = ?;
\ No newline at end of file
+WHERE pc.player_id = ? AND pc.cosmetic_id = ?;
+
+-- name: DeletePurchasedCosmetic :execrows
+-- Only removes a cosmetic the player bought, never one granted another way.
+DELETE FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ? AND unlocked_via = 'purchase';
+
+-- name: ListFriendsOwningCosmetic :many
+-- Lists a player's accepted friends who own a cosmetic, skipping friends who hide their inventory.
+SELECT p.player_id, p.username, pc.unlocked_at
+FROM friends f
+JOIN players p ON p.player_id = CASE WHEN f.player_id = sqlc.arg(player_id) THEN f.friend_id ELSE f.player_id END
+JOIN player_cosmetics pc ON pc.player_id = p.player_id AND pc.cosmetic_id = sqlc.arg(cosmetic_id)
+LEFT JOIN player_settings ps ON ps.player_id = p.player_id
+WHERE (f.player_id = sqlc.arg(player_id) OR f.friend_id = sqlc.arg(player_id))
+  AND f.status = 'accepted'
+  AND COALESCE(ps.inventory_visible_to_friends, 1) = 1
+ORDER BY p.username;
@@ -0,0 +1,30 @@
+-- name: CreatePlayerReport :one
+INSERT INTO player_reports (reporter_id, target_id, category, reason)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: CountPlayerReportsByReporterSince :one
+SELECT COUNT(*) FROM player_reports
+WHERE reporter_id = ? AND created_at >= ?;
+
+-- name: ListReportedPlayers :many
+-- Reports are aggregated per reported player, most reported first.
+SELECT
+    pr.target_id,
+    p.username,
+    COUNT(*) AS report_count,
+    COUNT(DISTINCT pr.reporter_id) AS reporter_count,
+    CAST(GROUP_CONCAT(DISTINCT pr.category) AS TEXT) AS categories,
+    CAST(MIN(pr.created_at) AS TEXT) AS first_reported_at,
+    CAST(MAX(pr.created_at) AS TEXT) AS last_reported_at
+FROM player_reports pr
+JOIN players p ON p.player_id = pr.target_id
+WHERE pr.status = ?1
+GROUP BY pr.target_id, p.username
+ORDER BY report_count DESC, last_reported_at DESC
+LIMIT ?2 OFFSET ?3;
+
+-- name: ResolvePlayerReports :execrows
+UPDATE player_reports
+SET status = ?1, resolved_by = ?2, resolved_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE target_id = ?3 AND status = 'open';
@@ -2,12 +2,45 @@
 SELECT * FROM player_settings WHERE player_id = ?;
 
 -- name: UpsertPlayerSettings :exec
-INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, allow_friend_requests, show_on_leaderboard, match_history_public, inventory_visible_to_friends)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(player_id) DO UPDATE SET
     key_bindings = excluded.key_bindings,
     mouse_sensitivity = excluded.mouse_sensitivity,
     ui_scale = excluded.ui_scale,
     color_blind_mode = excluded.color_blind_mode,
     subtitles_enabled = excluded.subtitles_enabled,
-    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
\ No newline at end of file
+    allow_friend_requests = excluded.allow_friend_requests,
+    show_on_leaderboard = excluded.show_on_leaderboard,
+    match_history_public = excluded.match_history_public,
+    inventory_visible_to_friends = excluded.inventory_visible_to_friends,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
+
+-- name: UpdatePlayerSettingsPartial :exec
+-- Only columns flagged as present are written; the set_* flags let nullable
+-- columns be cleared explicitly, while NOT NULL columns keep their value when
+-- the argument is NULL.
+INSERT INTO player_settings (player_id, key_bindings, mouse_sensitivity, ui_scale, color_blind_mode, subtitles_enabled, allow_friend_requests, show_on_leaderboard, match_history_public, inventory_visible_to_friends)
+VALUES (
+    sqlc.arg(player_id),
+    CAST(sqlc.narg(key_bindings) AS TEXT),
+    CAST(sqlc.narg(mouse_sensitivity) AS REAL),
+    CAST(sqlc.narg(ui_scale) AS REAL),
+    COALESCE(CAST(sqlc.narg(color_blind_mode) AS INTEGER), 0),
+    COALESCE(CAST(sqlc.narg(subtitles_enabled) AS INTEGER), 0),
+    COALESCE(CAST(sqlc.narg(allow_friend_requests) AS INTEGER), 1),
+    COALESCE(CAST(sqlc.narg(show_on_leaderboard) AS INTEGER), 1),
+    COALESCE(CAST(sqlc.narg(match_history_public) AS INTEGER), 1),
+    COALESCE(CAST(sqlc.narg(inventory_visible_to_friends) AS INTEGER), 1)
+)
+ON CONFLICT(player_id) DO UPDATE SET
+    key_bindings = CASE WHEN CAST(sqlc.arg(set_key_bindings) AS INTEGER) = 1 THEN excluded.key_bindings ELSE player_settings.key_bindings END,
+    mouse_sensitivity = CASE WHEN CAST(sqlc.arg(set_mouse_sensitivity) AS INTEGER) = 1 THEN excluded.mouse_sensitivity ELSE player_settings.mouse_sensitivity END,
+    ui_scale = CASE WHEN CAST(sqlc.arg(set_ui_scale) AS INTEGER) = 1 THEN excluded.ui_scale ELSE player_settings.ui_scale END,
+    color_blind_mode = COALESCE(CAST(sqlc.narg(color_blind_mode) AS INTEGER), player_settings.color_blind_mode),
+    subtitles_enabled = COALESCE(CAST(sqlc.narg(subtitles_enabled) AS INTEGER), player_settings.subtitles_enabled),
+    allow_friend_requests = COALESCE(CAST(sqlc.narg(allow_friend_requests) AS INTEGER), player_settings.allow_friend_requests),
+    show_on_leaderboard = COALESCE(CAST(sqlc.narg(show_on_leaderboard) AS INTEGER), player_settings.show_on_leaderboard),
+    match_history_public = COALESCE(CAST(sqlc.narg(match_history_public) AS INTEGER), player_settings.match_history_public),
+    inventory_visible_to_friends = COALESCE(CAST(sqlc.narg(inventory_visible_to_friends) AS INTEGER), player_settings.inventory_visible_to_friends),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
@@ -0,0 +1,7 @@
+-- name: CreateReferral :one
+INSERT INTO referrals (referrer_id, referred_id)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: GetReferralByReferred :one
+SELECT * FROM referrals WHERE referred_id = ?;
@@ -1,15 +1,27 @@
--- name: AddFavorite :exec
+-- name: AddFavorite :execrows
+-- Inserts nothing when the server is already a favorite, so callers can tell
+-- from the row count without a racy existence check.
 INSERT INTO server_favorites (player_id, server_id, note)
-VALUES (?, ?, ?);
+VALUES (?, ?, ?)
+ON CONFLICT (player_id, server_id) DO NOTHING;
 
 -- name: RemoveFavorite :exec
 DELETE FROM server_favorites
 WHERE player_id = ? AND server_id = ?;
 
+-- name: DeleteServerFavorites :exec
+DELETE FROM server_favorites
+WHERE server_id = ?;
+
 -- name: GetFavorite :one
 SELECT * FROM server_favorites
 WHERE player_id = ? AND server_id = ?;
 
+-- name: UpdateFavoriteNote :execrows
+UPDATE server_favorites
+SET note = ?
+WHERE player_id = ? AND server_id = ?;
+
 -- name: ListPlayerFavorites :many
 SELECT s.*, sf.added_at, sf.note
 FROM servers s
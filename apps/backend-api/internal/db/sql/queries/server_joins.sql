@@ -0,0 +1,25 @@
+-- name: CreateServerJoin :exec
+INSERT INTO server_joins (server_id, player_id)
+VALUES (?, ?);
+
+-- name: ListServerJoins :many
+SELECT sj.server_join_id, sj.server_id, sj.player_id, p.username, sj.joined_at
+FROM server_joins sj
+JOIN players p ON sj.player_id = p.player_id
+WHERE sj.server_id = ?
+ORDER BY sj.joined_at DESC, sj.server_join_id DESC
+LIMIT ?;
+
+-- name: DeleteServerJoinsBefore :exec
+DELETE FROM server_joins
+WHERE joined_at < ?;
+
+-- name: TrimServerJoins :exec
+DELETE FROM server_joins
+WHERE server_id = ?
+  AND server_join_id NOT IN (
+    SELECT server_join_id FROM server_joins
+    WHERE server_id = ?
+    ORDER BY joined_at DESC, server_join_id DESC
+    LIMIT ?
+  );
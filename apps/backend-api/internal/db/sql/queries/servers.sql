@@ -25,11 +25,34 @@ UPDATE servers
 SET last_heartbeat = ?, current_players = ?, is_online = 1, map_rotation = ?
 WHERE server_id = ?;
 
+-- name: UpdateServerMetadata :one
+UPDATE servers
+SET name = ?, map_rotation = ?, region = ?, max_players = ?
+WHERE server_id = ?
+RETURNING *;
+
 -- name: MarkServerOffline :exec
 UPDATE servers
 SET is_online = 0
 WHERE server_id = ?;
 
+-- name: MarkStaleServersOffline :execrows
+-- last_heartbeat is stored as "2006-01-02T15:04:05Z", so the cutoff compares as text.
+UPDATE servers
+SET is_online = 0
+WHERE is_online = 1
+  AND (last_heartbeat IS NULL OR last_heartbeat < sqlc.arg(cutoff));
+
+-- name: RetireServer :exec
+-- Revokes a deregistered server's auth token and takes it offline while keeping
+-- the row for the matches recorded against it.
+UPDATE servers
+SET auth_token = NULL, is_online = 0
+WHERE server_id = ?;
+
+-- name: CountServerMatches :one
+SELECT COUNT(*) FROM matches WHERE server_id = ?;
+
 -- name: DeleteServer :exec
 DELETE FROM servers WHERE server_id = ?;
 
@@ -41,4 +64,5 @@ WHERE is_online = 1
   AND (version = ?3 OR ?3 IS NULL)
   AND (current_players >= ?4 OR ?4 = -1)
   AND (current_players <= ?5 OR ?5 = -1)
+  AND (name LIKE '%' || ?6 || '%' ESCAPE '\' OR ?6 IS NULL)
 ORDER BY server_id;
\ No newline at end of file
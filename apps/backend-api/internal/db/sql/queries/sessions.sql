@@ -1,6 +1,7 @@
 -- name: CreateSession :exec
-INSERT INTO sessions (player_id, token, expires_at, ip_address, user_agent)
-VALUES (?, ?, ?, ?, ?);
+-- started_at is the login time, carried over to the sessions created by refreshing it.
+INSERT INTO sessions (player_id, token, expires_at, ip_address, user_agent, started_at)
+VALUES (?, ?, ?, ?, ?, ?);
 
 -- name: GetSessionByToken :one
 SELECT * FROM sessions WHERE token = ?;
@@ -11,5 +12,8 @@ DELETE FROM sessions WHERE token = ?;
 -- name: DeleteExpiredSessions :exec
 DELETE FROM sessions WHERE expires_at < ?;
 
--- name: DeleteSessionsByPlayer :exec
-DELETE FROM sessions WHERE player_id = ?;
\ No newline at end of file
+-- name: DeleteAllSessionsForPlayer :execrows
+DELETE FROM sessions WHERE player_id = ?;
+
+-- name: DeleteOtherSessionsByPlayer :execrows
+DELETE FROM sessions WHERE player_id = ? AND token != ?;
\ No newline at end of file
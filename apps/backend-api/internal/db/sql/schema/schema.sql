@@ -22,18 +22,28 @@ CREATE TABLE sessions (
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
     ip_address TEXT,
     user_agent TEXT,
+    started_at TEXT,
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
 );
 
 CREATE INDEX idx_sessions_player_id ON sessions (player_id);
 
+CREATE TABLE login_failures (
+    player_id INTEGER PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until TEXT,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
 
 CREATE TABLE currency_transactions (
     transaction_id INTEGER PRIMARY KEY AUTOINCREMENT,
     player_id INTEGER NOT NULL,
     amount INTEGER NOT NULL,
     balance_after INTEGER NOT NULL,
-    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'bundle_purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
     reference_id INTEGER,
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
@@ -67,6 +77,10 @@ CREATE TABLE player_settings (
     subtitles_enabled INTEGER NOT NULL DEFAULT 0,
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
     updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    allow_friend_requests INTEGER NOT NULL DEFAULT 1,
+    show_on_leaderboard INTEGER NOT NULL DEFAULT 1,
+    match_history_public INTEGER NOT NULL DEFAULT 1,
+    inventory_visible_to_friends INTEGER NOT NULL DEFAULT 1,
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
 );
 
@@ -80,19 +94,41 @@ CREATE TABLE cosmetic_items (
     unlock_level INTEGER NOT NULL DEFAULT 1,
     data_cost INTEGER NOT NULL DEFAULT 0,
     is_prestige_only INTEGER NOT NULL DEFAULT 0,
-    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    max_per_day INTEGER,
+    available_until TEXT,
+    equip_restriction TEXT CHECK (equip_restriction IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral'))
 );
 
 CREATE TABLE player_cosmetics (
     player_id INTEGER NOT NULL,
     cosmetic_id INTEGER NOT NULL,
     unlocked_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
-    unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige')),
+    unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral')),
     PRIMARY KEY (player_id, cosmetic_id),
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
     FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
 );
 
+CREATE TABLE cosmetic_bundles (
+    bundle_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE cosmetic_bundle_items (
+    bundle_id INTEGER NOT NULL,
+    cosmetic_id INTEGER NOT NULL,
+    PRIMARY KEY (bundle_id, cosmetic_id),
+    FOREIGN KEY (bundle_id) REFERENCES cosmetic_bundles (bundle_id) ON DELETE CASCADE,
+    FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_cosmetic_bundle_items_cosmetic_id ON cosmetic_bundle_items (cosmetic_id);
+
 CREATE TABLE loadouts (
     loadout_id INTEGER PRIMARY KEY AUTOINCREMENT,
     player_id INTEGER NOT NULL,
@@ -103,6 +139,9 @@ CREATE TABLE loadouts (
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
 );
 
+-- A player has at most one active loadout.
+CREATE UNIQUE INDEX idx_loadouts_one_active ON loadouts(player_id) WHERE is_active = 1;
+
 CREATE TABLE loadout_cosmetics (
     loadout_id INTEGER NOT NULL,
     cosmetic_id INTEGER NOT NULL,
@@ -128,6 +167,7 @@ CREATE TABLE servers (
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
 );
 CREATE UNIQUE INDEX idx_servers_auth_token ON servers(auth_token);
+CREATE INDEX idx_servers_is_online ON servers(is_online);
 
 CREATE TABLE matches (
     match_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -140,9 +180,13 @@ CREATE TABLE matches (
     waves_survived INTEGER NOT NULL DEFAULT 0,
     total_zombies_killed INTEGER NOT NULL DEFAULT 0,
     total_players INTEGER NOT NULL DEFAULT 0,
+    idempotency_key TEXT,
     FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE
 );
 
+CREATE UNIQUE INDEX idx_matches_server_idempotency_key ON matches(server_id, idempotency_key);
+CREATE INDEX idx_matches_start_time ON matches(start_time);
+
 CREATE TABLE player_match_stats (
     player_id INTEGER NOT NULL,
     match_id INTEGER NOT NULL,
@@ -163,6 +207,8 @@ CREATE TABLE player_match_stats (
     FOREIGN KEY (match_id) REFERENCES matches (match_id) ON DELETE CASCADE
 );
 
+CREATE INDEX idx_player_match_stats_match_id ON player_match_stats(match_id);
+
 CREATE TABLE leaderboard_entries (
     leaderboard_id INTEGER PRIMARY KEY AUTOINCREMENT,
     player_id INTEGER NOT NULL,
@@ -176,6 +222,24 @@ CREATE TABLE leaderboard_entries (
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
 );
 
+CREATE TABLE leaderboard_snapshots (
+    period TEXT NOT NULL CHECK (period IN ('daily', 'weekly', 'alltime')),
+    metric TEXT NOT NULL,
+    ranking INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    total_score INTEGER NOT NULL,
+    matches_played INTEGER NOT NULL,
+    avg_kills_per_match REAL,
+    avg_waves_survived REAL,
+    metric_value INTEGER NOT NULL,
+    generated_at TEXT NOT NULL,
+    PRIMARY KEY (period, metric, ranking),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_leaderboard_snapshots_player_id ON leaderboard_snapshots(period, metric, player_id);
+
 CREATE TABLE friends (
     player_id INTEGER NOT NULL,
     friend_id INTEGER NOT NULL,
@@ -217,6 +281,13 @@ CREATE TABLE loot_table_entries (
     FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
 );
 
+CREATE TABLE loot_pity (
+    player_id INTEGER PRIMARY KEY,
+    unlucky_rolls INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
 CREATE TABLE join_tokens (
     join_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
     token TEXT NOT NULL UNIQUE,
@@ -234,3 +305,75 @@ CREATE INDEX idx_join_tokens_player_id ON join_tokens(player_id);
 CREATE INDEX idx_join_tokens_server_id ON join_tokens(server_id);
 CREATE INDEX idx_join_tokens_expires_at ON join_tokens(expires_at);
 
+CREATE TABLE email_change_tokens (
+    email_change_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token TEXT NOT NULL UNIQUE,
+    player_id INTEGER NOT NULL,
+    new_email TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    used_at TEXT,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_change_tokens_player_id ON email_change_tokens(player_id);
+
+CREATE TABLE referrals (
+    referral_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    referrer_id INTEGER NOT NULL,
+    referred_id INTEGER NOT NULL UNIQUE,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    CHECK (referrer_id != referred_id),
+    FOREIGN KEY (referrer_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (referred_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_referrals_referrer_id ON referrals(referrer_id);
+
+CREATE TABLE player_reports (
+    report_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    reporter_id INTEGER NOT NULL,
+    target_id INTEGER NOT NULL,
+    category TEXT NOT NULL CHECK (category IN ('cheating', 'abuse', 'griefing', 'other')),
+    reason TEXT,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    resolved_at TEXT,
+    resolved_by INTEGER,
+    CHECK (reporter_id != target_id),
+    FOREIGN KEY (reporter_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (target_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES players (player_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_player_reports_target_id_status ON player_reports(target_id, status);
+CREATE INDEX idx_player_reports_reporter_id_created_at ON player_reports(reporter_id, created_at);
+-- A reporter has at most one open report against the same player.
+CREATE UNIQUE INDEX idx_player_reports_open_pair ON player_reports(reporter_id, target_id) WHERE status = 'open';
+
+CREATE TABLE server_joins (
+    server_join_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    joined_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_server_joins_server_id_joined_at ON server_joins(server_id, joined_at);
+CREATE INDEX idx_server_joins_player_id ON server_joins(player_id);
+
+CREATE TABLE notifications (
+    notification_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    notification_type TEXT NOT NULL,
+    message TEXT NOT NULL,
+    reference_id INTEGER,
+    is_read INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    read_at TEXT,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_player_id_is_read ON notifications(player_id, is_read, created_at);
+
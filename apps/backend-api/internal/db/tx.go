@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithTx runs fn in a transaction when dbConn is a *sql.DB, committing if fn
+// returns nil and rolling back otherwise. Any other DBTX, such as a *sql.Tx the
+// caller already opened, is handed to fn as is so the work joins the caller's
+// transaction.
+func WithTx(ctx context.Context, dbConn DBTX, fn func(tx DBTX) error) error {
+	conn, ok := dbConn.(*sql.DB)
+	if !ok {
+		return fn(dbConn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
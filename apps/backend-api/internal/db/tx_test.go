@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func openTxTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenDB(t.TempDir() + "/tx.db")
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TABLE items (name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	return db
+}
+
+func countItems(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&n); err != nil {
+		t.Fatalf("Failed to count items: %v", err)
+	}
+	return n
+}
+
+func TestWithTxCommits(t *testing.T) {
+	db := openTxTestDB(t)
+	ctx := context.Background()
+
+	err := WithTx(ctx, db, func(tx DBTX) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('a')`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+	if n := countItems(t, db); n != 1 {
+		t.Errorf("Expected 1 item after commit, got %d", n)
+	}
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	db := openTxTestDB(t)
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	err := WithTx(ctx, db, func(tx DBTX) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('a')`); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Expected fn's error to be returned, got %v", err)
+	}
+	if n := countItems(t, db); n != 0 {
+		t.Errorf("Expected insert to be rolled back, got %d items", n)
+	}
+}
+
+func TestWithTxJoinsExistingTransaction(t *testing.T) {
+	db := openTxTestDB(t)
+	ctx := context.Background()
+
+	outer, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer outer.Rollback()
+
+	err = WithTx(ctx, outer, func(tx DBTX) error {
+		if tx != DBTX(outer) {
+			t.Error("Expected fn to receive the caller's transaction")
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('a')`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+
+	// Nothing is committed until the caller commits
+	if err := outer.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if n := countItems(t, db); n != 0 {
+		t.Errorf("Expected the caller's rollback to discard the insert, got %d items", n)
+	}
+}
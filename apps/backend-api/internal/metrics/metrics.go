@@ -0,0 +1,98 @@
+// Package metrics collects the API's Prometheus metrics and serves them in
+// the text exposition format on /metrics. Metrics live in package variables
+// on the Default registry so services can record them without extra wiring.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Default is the registry served by Handler.
+var Default = NewRegistry()
+
+var (
+	// HTTPRequests counts requests by method, route pattern and status.
+	HTTPRequests = Default.NewCounterVec("http_requests_total",
+		"HTTP requests by method, route and status.", "method", "route", "status")
+	// HTTPRequestDuration observes request latency by method and route pattern.
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds by method and route.", DefaultBuckets, "method", "route")
+	// AuthAttempts counts logins and registrations by outcome (success or failure).
+	AuthAttempts = Default.NewCounterVec("auth_attempts_total",
+		"Login and registration attempts by action and result.", "action", "result")
+	// LootDrops counts loot drops by cosmetic rarity.
+	LootDrops = Default.NewCounterVec("loot_drops_total",
+		"Loot drops by cosmetic rarity.", "rarity")
+	// DataCurrencyMinted sums data currency credited to players, by transaction type.
+	DataCurrencyMinted = Default.NewCounterVec("data_currency_minted_total",
+		"Data currency credited to players by transaction type.", "type")
+	// DataCurrencySpent sums data currency debited from players, by transaction type.
+	DataCurrencySpent = Default.NewCounterVec("data_currency_spent_total",
+		"Data currency debited from players by transaction type.", "type")
+)
+
+// Outcome labels of AuthAttempts.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// RecordAuthAttempt counts a login or registration attempt.
+func RecordAuthAttempt(action string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+	AuthAttempts.Inc(action, result)
+}
+
+// RecordCurrency counts a data currency change: positive amounts as minted,
+// negative ones as spent.
+func RecordCurrency(transactionType string, amount int64) {
+	switch {
+	case amount > 0:
+		DataCurrencyMinted.Add(float64(amount), transactionType)
+	case amount < 0:
+		DataCurrencySpent.Add(float64(-amount), transactionType)
+	}
+}
+
+// unmatchedRoute labels requests that matched no route, so unknown paths
+// can't grow the number of series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records HTTPRequests and HTTPRequestDuration for every request,
+// labelled with the matched route pattern rather than the raw path.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+		route := c.Route().Path
+		if status == fiber.StatusNotFound && (route == "/" || route == "") && c.Path() != "/" {
+			route = unmatchedRoute
+		}
+		method := c.Method()
+		HTTPRequests.Inc(method, route, strconv.Itoa(status))
+		HTTPRequestDuration.Observe(time.Since(start).Seconds(), method, route)
+		return err
+	}
+}
+
+// Handler serves the Default registry in the Prometheus text format.
+func Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return Default.WriteText(c)
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests.", "route")
+	latency := r.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+
+	requests.Inc("/a")
+	requests.Add(2, "/a")
+	requests.Inc(`/b"`)
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(5, "/a")
+
+	if got := requests.Value("/a"); got != 3 {
+		t.Errorf("Expected /a count 3, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE requests_total counter\n",
+		`requests_total{route="/a"} 3` + "\n",
+		`requests_total{route="/b\""} 1` + "\n",
+		"# TYPE latency_seconds histogram\n",
+		`latency_seconds_bucket{route="/a",le="0.1"} 1` + "\n",
+		`latency_seconds_bucket{route="/a",le="1"} 2` + "\n",
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3` + "\n",
+		`latency_seconds_sum{route="/a"} 5.55` + "\n",
+		`latency_seconds_count{route="/a"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecordCurrency(t *testing.T) {
+	mintedBefore := DataCurrencyMinted.Value("test")
+	spentBefore := DataCurrencySpent.Value("test")
+
+	RecordCurrency("test", 50)
+	RecordCurrency("test", -20)
+	RecordCurrency("test", 0)
+
+	if got := DataCurrencyMinted.Value("test") - mintedBefore; got != 50 {
+		t.Errorf("Expected 50 minted, got %v", got)
+	}
+	if got := DataCurrencySpent.Value("test") - spentBefore; got != 20 {
+		t.Errorf("Expected 20 spent, got %v", got)
+	}
+}
@@ -3,7 +3,9 @@ package middleware
 import (
 	"errors"
 
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/services/auth"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -16,33 +18,35 @@ var (
 
 // AdminMiddleware creates a middleware that requires the player to be an administrator.
 // This middleware expects that AuthMiddleware has already run and stored player_id in locals.
+// Admin status comes from the token's is_admin claim, so a promoted or demoted player
+// must log in again; tokens issued before the claim existed fall back to authService.IsAdmin.
 func AdminMiddleware(authService auth.Service, logger *zap.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Retrieve player ID from locals (set by AuthMiddleware)
 		playerID, ok := GetPlayerID(c)
 		if !ok {
-			logger.Debug("missing player ID in admin middleware")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "authentication required",
-			})
+			logging.FromContext(c.Context(), logger).Debug("missing player ID in admin middleware")
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "authentication required")
 		}
 
 		// Check if player is admin
-		isAdmin, err := authService.IsAdmin(c.Context(), playerID)
-		if err != nil {
-			logger.Error("failed to check admin status", zap.Int64("player_id", playerID), zap.Error(err))
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "internal server error",
-			})
+		var isAdmin bool
+		if claims, ok := GetClaims(c); ok && claims.IsAdmin != nil {
+			isAdmin = *claims.IsAdmin
+		} else {
+			var err error
+			isAdmin, err = authService.IsAdmin(c.Context(), playerID)
+			if err != nil {
+				logging.FromContext(c.Context(), logger).Error("failed to check admin status", zap.Int64("player_id", playerID), zap.Error(err))
+				return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+			}
 		}
 		if !isAdmin {
-			logger.Debug("player is not admin", zap.Int64("player_id", playerID))
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": ErrNotAdmin.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("player is not admin", zap.Int64("player_id", playerID))
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeAdminRequired, ErrNotAdmin.Error())
 		}
 
-		logger.Debug("admin access granted", zap.Int64("player_id", playerID))
+		logging.FromContext(c.Context(), logger).Debug("admin access granted", zap.Int64("player_id", playerID))
 		return c.Next()
 	}
 }
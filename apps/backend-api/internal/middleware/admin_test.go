@@ -0,0 +1,108 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/internal/services/auth"
+	"ai-zombie-defense/backend-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cfg := config.Config{
+		JWT: config.JWTConfig{
+			Secret:            "test-secret",
+			AccessExpiration:  15 * time.Minute,
+			RefreshExpiration: 7 * 24 * time.Hour,
+		},
+	}
+	authService := auth.NewAuthService(cfg, logger, db)
+	ctx := context.Background()
+	register := func(username string, isAdmin bool) int64 {
+		t.Helper()
+		player, err := authService.RegisterPlayer(ctx, username, username+"@example.com", "securepassword123")
+		if err != nil {
+			t.Fatalf("Failed to register player: %v", err)
+		}
+		if isAdmin {
+			if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, player.PlayerID); err != nil {
+				t.Fatalf("Failed to promote player: %v", err)
+			}
+		}
+		return player.PlayerID
+	}
+	issue := func(playerID int64) string {
+		t.Helper()
+		token, err := authService.GenerateAccessToken(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to generate access token: %v", err)
+		}
+		return token
+	}
+	adminID := register("admin", true)
+	playerID := register("player", false)
+
+	app := fiber.New()
+	app.Use(middleware.AuthMiddleware(authService, logger), middleware.AdminMiddleware(authService, logger))
+	app.Get("/admin", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	status := func(token string) int {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	adminToken := issue(adminID)
+	playerToken := issue(playerID)
+	if got := status(adminToken); got != fiber.StatusOK {
+		t.Errorf("Expected status 200 for admin token, got %d", got)
+	}
+	if got := status(playerToken); got != fiber.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin token, got %d", got)
+	}
+
+	// The claim is trusted until the player logs in again
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 - is_admin`); err != nil {
+		t.Fatalf("Failed to swap roles: %v", err)
+	}
+	if got := status(adminToken); got != fiber.StatusOK {
+		t.Errorf("Expected demoted admin's old token to keep access, got %d", got)
+	}
+	if got := status(playerToken); got != fiber.StatusForbidden {
+		t.Errorf("Expected promoted player's old token to stay forbidden, got %d", got)
+	}
+	if got := status(issue(playerID)); got != fiber.StatusOK {
+		t.Errorf("Expected promoted player's new token to grant access, got %d", got)
+	}
+
+	// Tokens without the claim fall back to the database
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(playerID, 10),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	legacyToken, err := legacy.SignedString([]byte(cfg.JWT.Secret))
+	if err != nil {
+		t.Fatalf("Failed to sign legacy token: %v", err)
+	}
+	if got := status(legacyToken); got != fiber.StatusOK {
+		t.Errorf("Expected legacy token of an admin to grant access, got %d", got)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"strings"
 
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/services/auth"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
@@ -32,52 +33,46 @@ func AuthMiddleware(authService auth.Service, logger *zap.Logger) fiber.Handler
 		// Extract token from Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			logger.Debug("missing Authorization header")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrMissingToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("missing Authorization header")
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeMissingToken, ErrMissingToken.Error())
 		}
 
 		// Check Bearer prefix
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			logger.Debug("malformed Authorization header", zap.String("header", authHeader))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrMissingToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("malformed Authorization header", zap.String("header", authHeader))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeMissingToken, ErrMissingToken.Error())
 		}
 
 		tokenString := parts[1]
 		if tokenString == "" {
-			logger.Debug("empty token")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrMissingToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("empty token")
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeMissingToken, ErrMissingToken.Error())
 		}
 
 		// Validate token
 		claims, err := authService.ValidateToken(tokenString)
 		if err != nil {
-			logger.Debug("token validation failed", zap.Error(err))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrInvalidToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("token validation failed", zap.Error(err))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, ErrInvalidToken.Error())
+		}
+		if authService.IsTokenRevoked(claims) {
+			logging.FromContext(c.Context(), logger).Debug("token revoked", zap.String("jti", claims.ID))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, ErrInvalidToken.Error())
 		}
 
 		// Extract player ID from subject claim
 		playerID, err := parsePlayerID(claims.Subject)
 		if err != nil {
-			logger.Debug("invalid player ID in token", zap.String("subject", claims.Subject), zap.Error(err))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrInvalidToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("invalid player ID in token", zap.String("subject", claims.Subject), zap.Error(err))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, ErrInvalidToken.Error())
 		}
 
 		// Store player ID and claims in locals for downstream handlers
 		c.Locals(PlayerIDKey, playerID)
 		c.Locals(ClaimsKey, claims)
 
-		logger.Debug("token validated", zap.Int64("player_id", playerID))
+		logging.FromContext(c.Context(), logger).Debug("token validated", zap.Int64("player_id", playerID))
 		return c.Next()
 	}
 }
@@ -102,7 +97,7 @@ func GetPlayerID(c *fiber.Ctx) (int64, bool) {
 }
 
 // GetClaims retrieves JWT claims from Fiber's locals.
-func GetClaims(c *fiber.Ctx) (*jwt.RegisteredClaims, bool) {
-	claims, ok := c.Locals(ClaimsKey).(*jwt.RegisteredClaims)
+func GetClaims(c *fiber.Ctx) (*auth.Claims, bool) {
+	claims, ok := c.Locals(ClaimsKey).(*auth.Claims)
 	return claims, ok
 }
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ErrTooManyAuthAttempts indicates a client or account exceeded the auth rate limit.
+var ErrTooManyAuthAttempts = errors.New("too many authentication attempts")
+
+// AuthRateLimiter counts auth attempts per client IP and per account name in
+// fixed windows, separately from the global limiter. Both keys share the limit,
+// so spreading attempts over many IPs doesn't help against one account.
+type AuthRateLimiter struct {
+	max    int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*attemptWindow
+	nextSweep time.Time
+}
+
+type attemptWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewAuthRateLimiter creates a limiter allowing max attempts per key in each
+// window. A max of 0 disables it.
+func NewAuthRateLimiter(max int, window time.Duration) *AuthRateLimiter {
+	return &AuthRateLimiter{
+		max:     max,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[string]*attemptWindow),
+	}
+}
+
+// allow records an attempt for every key and reports whether all of them are
+// still within the limit; otherwise it returns how long until the longest
+// exceeded window resets.
+func (l *AuthRateLimiter) allow(keys ...string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !now.Before(l.nextSweep) {
+		for key, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, key)
+			}
+		}
+		l.nextSweep = now.Add(l.window)
+	}
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		w, ok := l.windows[key]
+		if !ok || !now.Before(w.resetAt) {
+			w = &attemptWindow{resetAt: now.Add(l.window)}
+			l.windows[key] = w
+		}
+		w.count++
+		if w.count > l.max {
+			if wait := w.resetAt.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	return retryAfter == 0, retryAfter
+}
+
+// Limit creates a middleware rejecting requests over the limit with 429 and a
+// Retry-After header. The account key is the username_or_email or username
+// field of the request body, case-insensitively; requests without one are only
+// limited by IP.
+func (l *AuthRateLimiter) Limit(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if l.max <= 0 {
+			return c.Next()
+		}
+
+		ip := c.IP()
+		keys := []string{"ip:" + ip}
+		var body struct {
+			UsernameOrEmail string `json:"username_or_email" form:"username_or_email"`
+			Username        string `json:"username" form:"username"`
+		}
+		// Malformed bodies are left for the handler to reject
+		_ = c.BodyParser(&body)
+		account := body.UsernameOrEmail
+		if account == "" {
+			account = body.Username
+		}
+		if account = strings.ToLower(strings.TrimSpace(account)); account != "" {
+			keys = append(keys, "account:"+account)
+		}
+
+		if ok, retryAfter := l.allow(keys...); !ok {
+			logging.FromContext(c.Context(), logger).Warn("auth rate limit exceeded", zap.String("ip", ip), zap.String("account", account), zap.String("path", c.Path()))
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return apierror.Respond(c, fiber.StatusTooManyRequests, apierror.CodeTooManyAuthAttempts, ErrTooManyAuthAttempts.Error())
+		}
+		return c.Next()
+	}
+}
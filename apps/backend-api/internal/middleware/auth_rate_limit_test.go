@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAuthRateLimit_Login(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	testutils.CreateTestPlayer(t, db, "victim", "victim@example.com", "password")
+	cfg := testutils.GetTestConfig()
+	cfg.Server.AuthRateLimitMax = 3
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+	login := func(path string) *http.Response {
+		body, _ := json.Marshal(map[string]string{"username_or_email": "victim", "password": "wrong-password"})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	for i := 0; i < 3; i++ {
+		if resp := login("/auth/login"); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Attempt %d: expected status 401, got %d", i+1, resp.StatusCode)
+		}
+	}
+	resp := login("/auth/login")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 after the threshold, got %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "60" {
+		t.Errorf("Expected Retry-After 60, got %q", retryAfter)
+	}
+
+	// Refreshing is not limited by the auth limiter
+	body, _ := json.Marshal(map[string]string{"refresh_token": "invalid"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t.Error("Did not expect /auth/refresh to be rate limited")
+	}
+}
+
+func TestAuthRateLimiter_PerAccount(t *testing.T) {
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	limiter := middleware.NewAuthRateLimiter(2, time.Minute)
+	app.Post("/login", limiter.Limit(zaptest.NewLogger(t)), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	})
+
+	attempt := func(ip, account string) int {
+		body, _ := json.Marshal(map[string]string{"username_or_email": account, "password": "wrong"})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Rotating IPs doesn't reset the budget of one account, whatever its casing
+	if status := attempt("10.0.0.1", "victim"); status != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401, got %d", status)
+	}
+	if status := attempt("10.0.0.2", "Victim"); status != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401, got %d", status)
+	}
+	if status := attempt("10.0.0.3", "VICTIM"); status != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for the third attempt on one account, got %d", status)
+	}
+	// Other accounts from fresh IPs are unaffected
+	if status := attempt("10.0.0.4", "someone-else"); status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for another account, got %d", status)
+	}
+}
+
+func TestAuthRateLimiter_Disabled(t *testing.T) {
+	app := fiber.New()
+	limiter := middleware.NewAuthRateLimiter(0, time.Minute)
+	app.Post("/login", limiter.Limit(zaptest.NewLogger(t)), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	for i := 0; i < 10; i++ {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/login", nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected a zero max to disable the limiter, got %d", resp.StatusCode)
+		}
+	}
+}
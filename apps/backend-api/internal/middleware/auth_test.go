@@ -99,7 +99,7 @@ func TestAuthMiddleware(t *testing.T) {
 	playerID := player.PlayerID
 
 	// Generate a valid access token
-	token, err := authService.GenerateAccessToken(playerID)
+	token, err := authService.GenerateAccessToken(ctx, playerID)
 	if err != nil {
 		t.Fatalf("Failed to generate access token: %v", err)
 	}
@@ -188,6 +188,23 @@ func TestAuthMiddleware(t *testing.T) {
 			t.Errorf("Expected status 401, got %d", resp.StatusCode)
 		}
 	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		if revoked := authService.RevokeAccessTokens(playerID); revoked != 1 {
+			t.Fatalf("Expected 1 revoked token, got %d", revoked)
+		}
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("Expected status 401 for a revoked token, got %d", resp.StatusCode)
+		}
+	})
 }
 
 func TestAuthMiddleware_PlayerIDInLocals(t *testing.T) {
@@ -212,7 +229,7 @@ func TestAuthMiddleware_PlayerIDInLocals(t *testing.T) {
 	}
 	playerID := player.PlayerID
 
-	token, err := authService.GenerateAccessToken(playerID)
+	token, err := authService.GenerateAccessToken(ctx, playerID)
 	if err != nil {
 		t.Fatalf("Failed to generate access token: %v", err)
 	}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrRegionBlocked indicates the client's country is blocked by configuration.
+	ErrRegionBlocked = errors.New("service unavailable in your region")
+)
+
+// CountryResolver looks up the ISO 3166-1 alpha-2 country code for an IP address.
+// Implementations may wrap a local geo-IP database or an external service.
+type CountryResolver interface {
+	CountryForIP(ip string) (string, error)
+}
+
+// CountryResolverFunc adapts a plain function to the CountryResolver interface.
+type CountryResolverFunc func(ip string) (string, error)
+
+// CountryForIP calls f(ip).
+func (f CountryResolverFunc) CountryForIP(ip string) (string, error) {
+	return f(ip)
+}
+
+// GeoBlockMiddleware rejects requests from blocked countries with 451.
+// It fails open: if the resolver is nil or the lookup errors, the request is allowed.
+func GeoBlockMiddleware(resolver CountryResolver, blockedCountries []string, logger *zap.Logger) fiber.Handler {
+	blocked := make(map[string]struct{}, len(blockedCountries))
+	for _, country := range blockedCountries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if country != "" {
+			blocked[country] = struct{}{}
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if resolver == nil || len(blocked) == 0 {
+			return c.Next()
+		}
+
+		ip := c.IP()
+		country, err := resolver.CountryForIP(ip)
+		if err != nil {
+			logging.FromContext(c.Context(), logger).Warn("geo-IP lookup failed, allowing request", zap.String("ip", ip), zap.Error(err))
+			return c.Next()
+		}
+
+		if _, ok := blocked[strings.ToUpper(country)]; ok {
+			logging.FromContext(c.Context(), logger).Debug("request blocked by region", zap.String("ip", ip), zap.String("country", country))
+			return apierror.Respond(c, fiber.StatusUnavailableForLegalReasons, apierror.CodeRegionBlocked, ErrRegionBlocked.Error())
+		}
+
+		return c.Next()
+	}
+}
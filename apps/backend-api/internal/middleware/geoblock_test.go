@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGeoBlockMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolver   middleware.CountryResolver
+		wantStatus int
+	}{
+		{
+			name: "blocked country",
+			resolver: middleware.CountryResolverFunc(func(ip string) (string, error) {
+				return "kp", nil
+			}),
+			wantStatus: http.StatusUnavailableForLegalReasons,
+		},
+		{
+			name: "allowed country",
+			resolver: middleware.CountryResolverFunc(func(ip string) (string, error) {
+				return "DE", nil
+			}),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "lookup failure fails open",
+			resolver: middleware.CountryResolverFunc(func(ip string) (string, error) {
+				return "", errors.New("geo database unavailable")
+			}),
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutils.SetupTestDB(t)
+			defer db.Close()
+			cfg := testutils.GetTestConfig()
+			cfg.Server.BlockedCountries = []string{"KP", "IR"}
+			app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db, gateway.WithCountryResolver(tt.resolver)).Router()
+
+			body, _ := json.Marshal(map[string]string{
+				"username": "geouser",
+				"email":    "geo@example.com",
+				"password": "password123",
+			})
+			req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGeoBlockMiddleware_DisabledByDefault(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	resolver := middleware.CountryResolverFunc(func(ip string) (string, error) {
+		t.Error("resolver should not be called when no countries are blocked")
+		return "KP", nil
+	})
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db, gateway.WithCountryResolver(resolver)).Router()
+
+	body, _ := json.Marshal(map[string]string{"username": "nobody", "password": "wrong"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode == http.StatusUnavailableForLegalReasons {
+		t.Errorf("Did not expect geoblocking with empty BlockedCountries")
+	}
+}
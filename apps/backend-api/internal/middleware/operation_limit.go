@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"sync"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ErrOperationInProgress indicates an operation is already running at its concurrency limit.
+var ErrOperationInProgress = errors.New("operation already in progress")
+
+// OperationLimiter caps how many runs of each named operation may be in
+// flight at once. It is shared by every route guarding the same operation.
+type OperationLimiter struct {
+	mu           sync.Mutex
+	limits       map[string]int
+	defaultLimit int
+	running      map[string]int
+}
+
+// NewOperationLimiter creates a limiter using limits per operation name and
+// defaultLimit for operations not listed.
+func NewOperationLimiter(defaultLimit int, limits map[string]int) *OperationLimiter {
+	return &OperationLimiter{
+		limits:       limits,
+		defaultLimit: defaultLimit,
+		running:      make(map[string]int),
+	}
+}
+
+// Acquire reserves a slot for operation and returns the function releasing
+// it, or ErrOperationInProgress when every slot is taken.
+func (l *OperationLimiter) Acquire(operation string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.limits[operation]
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if l.running[operation] >= limit {
+		return nil, ErrOperationInProgress
+	}
+	l.running[operation]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.running[operation]--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// Limit creates a middleware that holds a slot of operation for the rest of
+// the request and rejects it with 409 when none is free.
+func (l *OperationLimiter) Limit(operation string, logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		release, err := l.Acquire(operation)
+		if err != nil {
+			logging.FromContext(c.Context(), logger).Warn("admin operation rejected: already in progress", zap.String("operation", operation))
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeOperationInProgress, ErrOperationInProgress.Error())
+		}
+		defer release()
+		return c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+// newBlockingApp mounts a handler for operation that signals on started and
+// waits for release before responding.
+func newBlockingApp(t *testing.T, limiter *middleware.OperationLimiter, operation string, started chan<- struct{}, release <-chan struct{}) *fiber.App {
+	app := fiber.New()
+	app.Post("/run", limiter.Limit(operation, zaptest.NewLogger(t)), func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestOperationLimiter_RejectsConcurrentRun(t *testing.T) {
+	limiter := middleware.NewOperationLimiter(1, map[string]int{})
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	app := newBlockingApp(t, limiter, "season_reset", started, release)
+
+	firstStatus := make(chan int, 1)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/run", nil), -1)
+		if err != nil {
+			firstStatus <- 0
+			return
+		}
+		firstStatus <- resp.StatusCode
+	}()
+	<-started
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/run", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 for concurrent run, got %d", resp.StatusCode)
+	}
+
+	close(release)
+	if status := <-firstStatus; status != http.StatusOK {
+		t.Errorf("Expected first run to finish with 200, got %d", status)
+	}
+
+	// The slot is free again once the first run finished
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/run", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after the first run finished, got %d", resp.StatusCode)
+	}
+}
+
+func TestOperationLimiter_PerOperationLimits(t *testing.T) {
+	limiter := middleware.NewOperationLimiter(1, map[string]int{"backfill": 2})
+
+	first, err := limiter.Acquire("backfill")
+	if err != nil {
+		t.Fatalf("Expected first backfill slot, got %v", err)
+	}
+	second, err := limiter.Acquire("backfill")
+	if err != nil {
+		t.Fatalf("Expected second backfill slot with limit 2, got %v", err)
+	}
+	if _, err := limiter.Acquire("backfill"); err != middleware.ErrOperationInProgress {
+		t.Errorf("Expected ErrOperationInProgress for third backfill, got %v", err)
+	}
+
+	// Other operations use the default limit independently
+	reset, err := limiter.Acquire("season_reset")
+	if err != nil {
+		t.Fatalf("Expected season_reset slot, got %v", err)
+	}
+	if _, err := limiter.Acquire("season_reset"); err != middleware.ErrOperationInProgress {
+		t.Errorf("Expected ErrOperationInProgress for second season_reset, got %v", err)
+	}
+
+	first()
+	first() // releasing twice must not free an extra slot
+	if _, err := limiter.Acquire("backfill"); err != nil {
+		t.Errorf("Expected backfill slot after release, got %v", err)
+	}
+	if _, err := limiter.Acquire("backfill"); err != middleware.ErrOperationInProgress {
+		t.Errorf("Expected double release to free only one slot, got %v", err)
+	}
+	second()
+	reset()
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestIDHeader carries the request's correlation ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they can't bloat every log line.
+const maxRequestIDLength = 128
+
+// RequestID creates a middleware that tags each request with a correlation ID:
+// a valid X-Request-ID from the client is kept, otherwise a random one is
+// generated. The ID is echoed in the response header and stored under
+// logging.RequestIDKey, so logging.FromContext(c.Context(), logger) adds it to
+// log lines in handlers and in the services they call.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Locals(logging.RequestIDKey, id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		c.Set(RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// GetRequestID retrieves the request ID set by RequestID.
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(logging.RequestIDKey).(string)
+	return id
+}
+
+// validRequestID accepts non-empty, bounded, printable ASCII IDs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
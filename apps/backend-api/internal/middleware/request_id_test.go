@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// failingService stands in for a service that logs through the request context.
+func failingService(ctx context.Context, logger *zap.Logger) error {
+	err := errors.New("database is on fire")
+	logging.FromContext(ctx, logger).Error("service failed", zap.Error(err))
+	return err
+}
+
+func newRequestIDApp(logger *zap.Logger) *fiber.App {
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		if err := failingService(c.Context(), logger); err != nil {
+			logging.FromContext(c.Context(), logger).Error("request failed", zap.Error(err))
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestRequestID_EchoesAndLogsClientID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	app := newRequestIDApp(zap.New(core))
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(middleware.RequestIDHeader, "client-req-42")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get(middleware.RequestIDHeader); got != "client-req-42" {
+		t.Errorf("Expected response to echo request ID, got %q", got)
+	}
+
+	entries := logs.FilterField(zap.String("request_id", "client-req-42")).All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected handler and service logs to carry the request ID, got %d of %d entries", len(entries), logs.Len())
+	}
+	if entries[0].Message != "service failed" || entries[1].Message != "request failed" {
+		t.Errorf("Unexpected log messages: %q, %q", entries[0].Message, entries[1].Message)
+	}
+}
+
+func TestRequestID_GeneratesWhenMissingOrInvalid(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	app := newRequestIDApp(zap.New(core))
+
+	for _, header := range []string{"", "has spaces", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		if header != "" {
+			req.Header.Set(middleware.RequestIDHeader, header)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		id := resp.Header.Get(middleware.RequestIDHeader)
+		if len(id) != 32 || id == header {
+			t.Errorf("Expected a generated 32-character ID for header %q, got %q", header, id)
+		}
+		if n := logs.FilterField(zap.String("request_id", id)).Len(); n != 2 {
+			t.Errorf("Expected 2 log entries with generated ID %q, got %d", id, n)
+		}
+	}
+}
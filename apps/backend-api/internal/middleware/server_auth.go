@@ -4,7 +4,9 @@ import (
 	"errors"
 	"strconv"
 
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/services/server"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -30,52 +32,63 @@ func ServerAuthMiddleware(serverService server.Service, logger *zap.Logger) fibe
 		// Extract server ID from path parameter
 		serverIDStr := c.Params("id")
 		if serverIDStr == "" {
-			logger.Debug("missing server ID in path")
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "server ID is required",
-			})
+			logging.FromContext(c.Context(), logger).Debug("missing server ID in path")
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "server ID is required")
 		}
 
 		serverID, err := strconv.ParseInt(serverIDStr, 10, 64)
 		if err != nil {
-			logger.Debug("invalid server ID format", zap.String("server_id", serverIDStr), zap.Error(err))
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "invalid server ID format",
-			})
+			logging.FromContext(c.Context(), logger).Debug("invalid server ID format", zap.String("server_id", serverIDStr), zap.Error(err))
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid server ID format")
 		}
 
 		// Extract token from X-Server-Token header
 		token := c.Get("X-Server-Token")
 		if token == "" {
-			logger.Debug("missing X-Server-Token header")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrMissingServerToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("missing X-Server-Token header")
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeMissingServerToken, ErrMissingServerToken.Error())
 		}
 
 		// Look up server by auth token
 		server, err := serverService.GetServerByAuthToken(c.Context(), token)
 		if err != nil {
-			logger.Debug("server lookup failed", zap.Error(err))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": ErrInvalidServerToken.Error(),
-			})
+			logging.FromContext(c.Context(), logger).Debug("server lookup failed", zap.Error(err))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidServerToken, ErrInvalidServerToken.Error())
 		}
 
 		// Verify server ID matches
 		if server.ServerID != serverID {
-			logger.Debug("server ID mismatch",
+			logging.FromContext(c.Context(), logger).Debug("server ID mismatch",
 				zap.Int64("token_server_id", server.ServerID),
 				zap.Int64("path_server_id", serverID))
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": ErrServerMismatch.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeServerMismatch, ErrServerMismatch.Error())
 		}
 
 		// Store server ID in locals for downstream handlers
 		c.Locals(ServerIDKey, serverID)
 
-		logger.Debug("server authentication successful", zap.Int64("server_id", serverID))
+		logging.FromContext(c.Context(), logger).Debug("server authentication successful", zap.Int64("server_id", serverID))
+		return c.Next()
+	}
+}
+
+// ServerTokenMiddleware validates the X-Server-Token header without tying it
+// to a server ID in the path, for server endpoints addressing other resources.
+func ServerTokenMiddleware(serverService server.Service, logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Get("X-Server-Token")
+		if token == "" {
+			logging.FromContext(c.Context(), logger).Debug("missing X-Server-Token header")
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeMissingServerToken, ErrMissingServerToken.Error())
+		}
+
+		server, err := serverService.GetServerByAuthToken(c.Context(), token)
+		if err != nil {
+			logging.FromContext(c.Context(), logger).Debug("server lookup failed", zap.Error(err))
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidServerToken, ErrInvalidServerToken.Error())
+		}
+
+		c.Locals(ServerIDKey, server.ServerID)
 		return c.Next()
 	}
 }
@@ -0,0 +1,346 @@
+// Package seed loads development fixtures (cosmetics, loot tables and an admin
+// account) into the database. Seeding is idempotent: rows are matched by name
+// (and loot entries by table and cosmetic), so re-running a fixtures file
+// updates what it created instead of duplicating it.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/services/auth"
+	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+// Fixtures is the content of a fixtures file.
+type Fixtures struct {
+	Cosmetics  []Cosmetic  `json:"cosmetics" yaml:"cosmetics"`
+	LootTables []LootTable `json:"loot_tables" yaml:"loot_tables"`
+	Admin      *Admin      `json:"admin" yaml:"admin"`
+}
+
+// Cosmetic is a cosmetic item, matched to existing rows by name.
+type Cosmetic struct {
+	Name           string  `json:"name" yaml:"name"`
+	Description    *string `json:"description" yaml:"description"`
+	Slot           string  `json:"slot" yaml:"slot"`
+	Category       *string `json:"category" yaml:"category"`
+	Rarity         string  `json:"rarity" yaml:"rarity"`
+	UnlockLevel    int64   `json:"unlock_level" yaml:"unlock_level"`
+	DataCost       int64   `json:"data_cost" yaml:"data_cost"`
+	IsPrestigeOnly bool    `json:"is_prestige_only" yaml:"is_prestige_only"`
+}
+
+// LootTable is a loot table and its entries, matched to existing rows by name.
+// IsActive defaults to true.
+type LootTable struct {
+	Name        string      `json:"name" yaml:"name"`
+	Description *string     `json:"description" yaml:"description"`
+	DropChance  float64     `json:"drop_chance" yaml:"drop_chance"`
+	IsActive    *bool       `json:"is_active" yaml:"is_active"`
+	Entries     []LootEntry `json:"entries" yaml:"entries"`
+}
+
+// LootEntry references its cosmetic by name, either from the same fixtures
+// file or already in the database. Quantities default to 1.
+type LootEntry struct {
+	Cosmetic    string `json:"cosmetic" yaml:"cosmetic"`
+	Weight      int64  `json:"weight" yaml:"weight"`
+	MinQuantity int64  `json:"min_quantity" yaml:"min_quantity"`
+	MaxQuantity int64  `json:"max_quantity" yaml:"max_quantity"`
+}
+
+// Admin is the initial admin account. An existing player with the username is
+// promoted without touching its email or password.
+type Admin struct {
+	Username string `json:"username" yaml:"username"`
+	Email    string `json:"email" yaml:"email"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// Result counts what a seeding run created and what it found already present.
+type Result struct {
+	CosmeticsCreated   int  `json:"cosmetics_created"`
+	CosmeticsUpdated   int  `json:"cosmetics_updated"`
+	LootTablesCreated  int  `json:"loot_tables_created"`
+	LootTablesUpdated  int  `json:"loot_tables_updated"`
+	LootEntriesCreated int  `json:"loot_entries_created"`
+	LootEntriesUpdated int  `json:"loot_entries_updated"`
+	AdminCreated       bool `json:"admin_created"`
+}
+
+// Load reads a fixtures file, parsed as YAML for .yaml/.yml and JSON otherwise.
+func Load(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixtures)
+	default:
+		err = json.Unmarshal(data, &fixtures)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// Seeder writes fixtures to the database.
+type Seeder struct {
+	logger  *zap.Logger
+	dbConn  *sql.DB
+	queries *db.Queries
+	authSvc auth.Service
+}
+
+func NewSeeder(cfg config.Config, logger *zap.Logger, dbConn *sql.DB) *Seeder {
+	return &Seeder{
+		logger:  logger,
+		dbConn:  dbConn,
+		queries: db.New(),
+		authSvc: auth.NewAuthService(cfg, logger, dbConn),
+	}
+}
+
+// Apply upserts the cosmetics and loot tables in one transaction, then
+// creates or promotes the admin account through the auth service so it gets
+// the same validation and progression row as a registered player.
+func (s *Seeder) Apply(ctx context.Context, fixtures *Fixtures) (*Result, error) {
+	result := &Result{}
+
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		for _, cosmetic := range fixtures.Cosmetics {
+			if err := s.upsertCosmetic(ctx, tx, cosmetic, result); err != nil {
+				return err
+			}
+		}
+		for _, table := range fixtures.LootTables {
+			if err := s.upsertLootTable(ctx, tx, table, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if fixtures.Admin != nil {
+		created, err := s.ensureAdmin(ctx, *fixtures.Admin)
+		if err != nil {
+			return nil, err
+		}
+		result.AdminCreated = created
+	}
+
+	logging.FromContext(ctx, s.logger).Info("fixtures seeded", zap.Any("result", result))
+	return result, nil
+}
+
+func (s *Seeder) upsertCosmetic(ctx context.Context, tx db.DBTX, c Cosmetic, result *Result) error {
+	if c.Name == "" {
+		return errors.New("cosmetic without a name")
+	}
+	unlockLevel := c.UnlockLevel
+	if unlockLevel < 1 {
+		unlockLevel = 1
+	}
+	var prestigeOnly int64
+	if c.IsPrestigeOnly {
+		prestigeOnly = 1
+	}
+
+	existing, err := s.queries.GetCosmeticItemByName(ctx, tx, c.Name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up cosmetic %q: %w", c.Name, err)
+	}
+	if err == nil {
+		// Keep the columns fixtures don't describe
+		_, err = s.queries.UpdateCosmeticItem(ctx, tx, &db.UpdateCosmeticItemParams{
+			Name:             c.Name,
+			Description:      c.Description,
+			Slot:             c.Slot,
+			Category:         c.Category,
+			Rarity:           c.Rarity,
+			UnlockLevel:      unlockLevel,
+			DataCost:         c.DataCost,
+			IsPrestigeOnly:   prestigeOnly,
+			MaxPerDay:        existing.MaxPerDay,
+			AvailableUntil:   existing.AvailableUntil,
+			EquipRestriction: existing.EquipRestriction,
+			CosmeticID:       existing.CosmeticID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update cosmetic %q: %w", c.Name, err)
+		}
+		result.CosmeticsUpdated++
+		return nil
+	}
+
+	_, err = s.queries.CreateCosmeticItem(ctx, tx, &db.CreateCosmeticItemParams{
+		Name:           c.Name,
+		Description:    c.Description,
+		Slot:           c.Slot,
+		Category:       c.Category,
+		Rarity:         c.Rarity,
+		UnlockLevel:    unlockLevel,
+		DataCost:       c.DataCost,
+		IsPrestigeOnly: prestigeOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create cosmetic %q: %w", c.Name, err)
+	}
+	result.CosmeticsCreated++
+	return nil
+}
+
+func (s *Seeder) upsertLootTable(ctx context.Context, tx db.DBTX, t LootTable, result *Result) error {
+	if t.Name == "" {
+		return errors.New("loot table without a name")
+	}
+	if t.DropChance < 0 || t.DropChance > 1 {
+		return fmt.Errorf("loot table %q: drop_chance must be between 0 and 1", t.Name)
+	}
+	isActive := int64(1)
+	if t.IsActive != nil && !*t.IsActive {
+		isActive = 0
+	}
+
+	var tableID int64
+	existing, err := s.queries.GetLootTableByName(ctx, tx, t.Name)
+	switch {
+	case err == nil:
+		tableID = existing.LootTableID
+		err = s.queries.UpdateLootTable(ctx, tx, &db.UpdateLootTableParams{
+			Name:        t.Name,
+			Description: t.Description,
+			DropChance:  t.DropChance,
+			IsActive:    isActive,
+			LootTableID: tableID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update loot table %q: %w", t.Name, err)
+		}
+		result.LootTablesUpdated++
+	case errors.Is(err, sql.ErrNoRows):
+		created, err := s.queries.CreateLootTable(ctx, tx, &db.CreateLootTableParams{
+			Name:        t.Name,
+			Description: t.Description,
+			DropChance:  t.DropChance,
+			IsActive:    isActive,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create loot table %q: %w", t.Name, err)
+		}
+		tableID = created.LootTableID
+		result.LootTablesCreated++
+	default:
+		return fmt.Errorf("failed to look up loot table %q: %w", t.Name, err)
+	}
+
+	for _, entry := range t.Entries {
+		if err := s.upsertLootEntry(ctx, tx, t.Name, tableID, entry, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Seeder) upsertLootEntry(ctx context.Context, tx db.DBTX, tableName string, tableID int64, e LootEntry, result *Result) error {
+	minQuantity, maxQuantity := e.MinQuantity, e.MaxQuantity
+	if minQuantity == 0 {
+		minQuantity = 1
+	}
+	if maxQuantity == 0 {
+		maxQuantity = minQuantity
+	}
+	if e.Weight <= 0 || minQuantity < 1 || maxQuantity < minQuantity {
+		return fmt.Errorf("loot table %q: entry for %q needs a positive weight and 1 <= min_quantity <= max_quantity", tableName, e.Cosmetic)
+	}
+
+	cosmetic, err := s.queries.GetCosmeticItemByName(ctx, tx, e.Cosmetic)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("loot table %q: unknown cosmetic %q", tableName, e.Cosmetic)
+		}
+		return fmt.Errorf("failed to look up cosmetic %q: %w", e.Cosmetic, err)
+	}
+
+	existing, err := s.queries.GetLootTableEntryByCosmetic(ctx, tx, &db.GetLootTableEntryByCosmeticParams{
+		LootTableID: tableID,
+		CosmeticID:  cosmetic.CosmeticID,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up loot entry for %q: %w", e.Cosmetic, err)
+	}
+	if err == nil {
+		err = s.queries.UpdateLootTableEntry(ctx, tx, &db.UpdateLootTableEntryParams{
+			LootTableID: tableID,
+			CosmeticID:  cosmetic.CosmeticID,
+			Weight:      e.Weight,
+			MinQuantity: minQuantity,
+			MaxQuantity: maxQuantity,
+			LootEntryID: existing.LootEntryID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update loot entry for %q: %w", e.Cosmetic, err)
+		}
+		result.LootEntriesUpdated++
+		return nil
+	}
+
+	_, err = s.queries.CreateLootTableEntry(ctx, tx, &db.CreateLootTableEntryParams{
+		LootTableID: tableID,
+		CosmeticID:  cosmetic.CosmeticID,
+		Weight:      e.Weight,
+		MinQuantity: minQuantity,
+		MaxQuantity: maxQuantity,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create loot entry for %q: %w", e.Cosmetic, err)
+	}
+	result.LootEntriesCreated++
+	return nil
+}
+
+// ensureAdmin registers the admin if the username is free and marks the player
+// as an admin either way. It reports whether the account was created.
+func (s *Seeder) ensureAdmin(ctx context.Context, admin Admin) (bool, error) {
+	created := false
+	player, err := s.queries.GetPlayerByUsername(ctx, s.dbConn, admin.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		player, err = s.authSvc.RegisterPlayer(ctx, admin.Username, admin.Email, admin.Password)
+		if err != nil {
+			return false, fmt.Errorf("failed to register admin %q: %w", admin.Username, err)
+		}
+		created = true
+	} else if err != nil {
+		return false, fmt.Errorf("failed to look up admin %q: %w", admin.Username, err)
+	}
+
+	if player.IsAdmin != 1 {
+		err := s.queries.SetPlayerAdmin(ctx, s.dbConn, &db.SetPlayerAdminParams{
+			IsAdmin:  1,
+			PlayerID: player.PlayerID,
+		})
+		if err != nil {
+			return created, fmt.Errorf("failed to grant admin to %q: %w", admin.Username, err)
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,134 @@
+package seed_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/seed"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+func TestLoad(t *testing.T) {
+	// The shipped development fixtures stay loadable
+	fixtures, err := seed.Load("../../fixtures/dev.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load dev fixtures: %v", err)
+	}
+	if len(fixtures.Cosmetics) == 0 || len(fixtures.LootTables) == 0 || fixtures.Admin == nil {
+		t.Errorf("Expected cosmetics, loot tables and an admin, got %+v", fixtures)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	content := `{"cosmetics":[{"name":"Cap","slot":"other","rarity":"common"}],"loot_tables":[{"name":"T","drop_chance":0.5,"is_active":false,"entries":[{"cosmetic":"Cap","weight":3}]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	fixtures, err = seed.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load JSON fixtures: %v", err)
+	}
+	table := fixtures.LootTables[0]
+	if fixtures.Cosmetics[0].Name != "Cap" || table.IsActive == nil || *table.IsActive || table.Entries[0].Weight != 3 {
+		t.Errorf("Unexpected JSON fixtures: %+v", fixtures)
+	}
+
+	if _, err := seed.Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func countRows(t *testing.T, dbConn *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := dbConn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestSeederApplyIsIdempotent(t *testing.T) {
+	dbConn := testutils.SetupTestDB(t)
+	defer dbConn.Close()
+	seeder := seed.NewSeeder(testutils.GetTestConfig(), zaptest.NewLogger(t), dbConn)
+	fixtures, err := seed.Load("../../fixtures/dev.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load dev fixtures: %v", err)
+	}
+
+	first, err := seeder.Apply(context.Background(), fixtures)
+	if err != nil {
+		t.Fatalf("First seed failed: %v", err)
+	}
+	if first.CosmeticsCreated != 4 || first.LootTablesCreated != 1 || first.LootEntriesCreated != 3 || !first.AdminCreated {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+
+	// Columns the fixtures don't describe survive a re-seed
+	if _, err := dbConn.Exec(`UPDATE cosmetic_items SET equip_restriction = 'prestige'`); err != nil {
+		t.Fatalf("Failed to restrict cosmetics: %v", err)
+	}
+
+	second, err := seeder.Apply(context.Background(), fixtures)
+	if err != nil {
+		t.Fatalf("Second seed failed: %v", err)
+	}
+	if second.CosmeticsCreated != 0 || second.LootTablesCreated != 0 || second.LootEntriesCreated != 0 || second.AdminCreated {
+		t.Errorf("Expected nothing created on the second run, got %+v", second)
+	}
+	if second.CosmeticsUpdated != 4 || second.LootTablesUpdated != 1 || second.LootEntriesUpdated != 3 {
+		t.Errorf("Expected every row matched on the second run, got %+v", second)
+	}
+
+	for table, want := range map[string]int{
+		"cosmetic_items":     4,
+		"loot_tables":        1,
+		"loot_table_entries": 3,
+		"players":            1,
+	} {
+		if got := countRows(t, dbConn, table); got != want {
+			t.Errorf("Expected %d rows in %s after seeding twice, got %d", want, table, got)
+		}
+	}
+
+	var isAdmin int
+	if err := dbConn.QueryRow(`SELECT is_admin FROM players WHERE username = ?`, fixtures.Admin.Username).Scan(&isAdmin); err != nil {
+		t.Fatalf("Failed to read admin: %v", err)
+	}
+	if isAdmin != 1 {
+		t.Error("Expected the seeded admin to have is_admin = 1")
+	}
+	var unrestricted int
+	if err := dbConn.QueryRow(`SELECT COUNT(*) FROM cosmetic_items WHERE equip_restriction IS NULL`).Scan(&unrestricted); err != nil {
+		t.Fatalf("Failed to count unrestricted cosmetics: %v", err)
+	}
+	if unrestricted != 0 {
+		t.Errorf("Expected the re-seed to keep equip_restriction, got %d unrestricted cosmetics", unrestricted)
+	}
+}
+
+func TestSeederApplyRollsBackOnUnknownCosmetic(t *testing.T) {
+	dbConn := testutils.SetupTestDB(t)
+	defer dbConn.Close()
+	seeder := seed.NewSeeder(testutils.GetTestConfig(), zaptest.NewLogger(t), dbConn)
+
+	fixtures := &seed.Fixtures{
+		Cosmetics: []seed.Cosmetic{{Name: "Cap", Slot: "other", Rarity: "common"}},
+		LootTables: []seed.LootTable{{
+			Name:       "Broken",
+			DropChance: 0.5,
+			Entries:    []seed.LootEntry{{Cosmetic: "Missing", Weight: 1}},
+		}},
+	}
+	if _, err := seeder.Apply(context.Background(), fixtures); err == nil {
+		t.Fatal("Expected error for an entry with an unknown cosmetic")
+	}
+	if n := countRows(t, dbConn, "cosmetic_items"); n != 0 {
+		t.Errorf("Expected the failed seed to roll back, found %d cosmetics", n)
+	}
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/account"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -30,20 +36,49 @@ type ProfileResponse struct {
 	IsBanned    bool    `json:"is_banned"`
 }
 
+// UpdateProfileRequest only carries the username; email changes are verified
+// through POST /account/email/request.
 type UpdateProfileRequest struct {
 	Username string `json:"username"`
-	Email    string `json:"email"`
+}
+
+type EmailChangeRequest struct {
+	Email string `json:"email"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+	// RefreshToken optionally identifies the caller's session, which is kept
+	RefreshToken string `json:"refresh_token"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type ClaimReferralRequest struct {
+	// ReferralCode is the referrer's username
+	ReferralCode string `json:"referral_code"`
 }
 
 type SettingsResponse struct {
-	PlayerID         int64    `json:"player_id"`
-	KeyBindings      *string  `json:"key_bindings,omitempty"`
-	MouseSensitivity *float64 `json:"mouse_sensitivity,omitempty"`
-	UiScale          *float64 `json:"ui_scale,omitempty"`
-	ColorBlindMode   int64    `json:"color_blind_mode"`
-	SubtitlesEnabled int64    `json:"subtitles_enabled"`
-	CreatedAt        string   `json:"created_at"`
-	UpdatedAt        string   `json:"updated_at"`
+	PlayerID                  int64    `json:"player_id"`
+	KeyBindings               *string  `json:"key_bindings,omitempty"`
+	MouseSensitivity          *float64 `json:"mouse_sensitivity,omitempty"`
+	UiScale                   *float64 `json:"ui_scale,omitempty"`
+	ColorBlindMode            int64    `json:"color_blind_mode"`
+	SubtitlesEnabled          int64    `json:"subtitles_enabled"`
+	AllowFriendRequests       int64    `json:"allow_friend_requests"`
+	ShowOnLeaderboard         int64    `json:"show_on_leaderboard"`
+	MatchHistoryPublic        int64    `json:"match_history_public"`
+	InventoryVisibleToFriends int64    `json:"inventory_visible_to_friends"`
+	CreatedAt                 string   `json:"created_at"`
+	UpdatedAt                 string   `json:"updated_at"`
 }
 
 type UpdateSettingsRequest struct {
@@ -52,25 +87,158 @@ type UpdateSettingsRequest struct {
 	UiScale          *float64 `json:"ui_scale"`
 	ColorBlindMode   int64    `json:"color_blind_mode"`
 	SubtitlesEnabled int64    `json:"subtitles_enabled"`
+	// Privacy preferences are optional; omitted fields keep their stored value
+	AllowFriendRequests       *int64 `json:"allow_friend_requests"`
+	ShowOnLeaderboard         *int64 `json:"show_on_leaderboard"`
+	MatchHistoryPublic        *int64 `json:"match_history_public"`
+	InventoryVisibleToFriends *int64 `json:"inventory_visible_to_friends"`
+}
+
+// settingsPatchFields lists the fields accepted by PATCH /account/settings.
+// Nullable fields may be set to null to clear them; the rest must be numbers.
+var settingsPatchFields = map[string]bool{
+	"key_bindings":                 true,
+	"mouse_sensitivity":            true,
+	"ui_scale":                     true,
+	"color_blind_mode":             false,
+	"subtitles_enabled":            false,
+	"allow_friend_requests":        false,
+	"show_on_leaderboard":          false,
+	"match_history_public":         false,
+	"inventory_visible_to_friends": false,
+}
+
+// parseSettingsPatch decodes a PATCH body into partial update params. A
+// presence map separates omitted fields (left untouched) from fields
+// explicitly set to null (cleared).
+func parseSettingsPatch(playerID int64, body []byte) (*db.UpdatePlayerSettingsPartialParams, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, errors.New("invalid request body")
+	}
+
+	params := &db.UpdatePlayerSettingsPartialParams{PlayerID: playerID}
+	for name, raw := range fields {
+		nullable, ok := settingsPatchFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if string(raw) == "null" && !nullable {
+			return nil, fmt.Errorf("%s cannot be null", name)
+		}
+
+		var err error
+		switch name {
+		case "key_bindings":
+			params.SetKeyBindings = 1
+			err = json.Unmarshal(raw, &params.KeyBindings)
+		case "mouse_sensitivity":
+			params.SetMouseSensitivity = 1
+			err = json.Unmarshal(raw, &params.MouseSensitivity)
+		case "ui_scale":
+			params.SetUiScale = 1
+			err = json.Unmarshal(raw, &params.UiScale)
+		case "color_blind_mode":
+			err = json.Unmarshal(raw, &params.ColorBlindMode)
+		case "subtitles_enabled":
+			err = json.Unmarshal(raw, &params.SubtitlesEnabled)
+		case "allow_friend_requests":
+			err = json.Unmarshal(raw, &params.AllowFriendRequests)
+		case "show_on_leaderboard":
+			err = json.Unmarshal(raw, &params.ShowOnLeaderboard)
+		case "match_history_public":
+			err = json.Unmarshal(raw, &params.MatchHistoryPublic)
+		case "inventory_visible_to_friends":
+			err = json.Unmarshal(raw, &params.InventoryVisibleToFriends)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s", name)
+		}
+	}
+	return params, nil
+}
+
+// RequestEmailChange handles POST /account/email/request
+func (h *AccountHandlers) RequestEmailChange(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	var req EmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.Email == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "email is required")
+	}
+
+	token, err := h.accSvc.RequestEmailChange(c.Context(), playerID, req.Email)
+	if err != nil {
+		if err == account.ErrInvalidEmail {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidEmail, "invalid email")
+		}
+		if err == account.ErrDuplicateEmail {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeDuplicateEmail, "email already exists")
+		}
+		if err == account.ErrEmailUnchanged {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeEmailUnchanged, "new email matches current email")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to request email change", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	// The token is only mailed to the new address, never echoed back:
+	// possessing it must prove control of that address
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":    "email change pending verification",
+		"expires_at": token.ExpiresAt.Time.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+// ConfirmEmailChange handles POST /account/email/confirm
+func (h *AccountHandlers) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req ConfirmEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.Token == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "token is required")
+	}
+
+	if err := h.accSvc.ConfirmEmailChange(c.Context(), req.Token); err != nil {
+		if err == account.ErrEmailChangeTokenNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeEmailChangeTokenNotFound, "email change token not found")
+		}
+		if err == account.ErrEmailChangeTokenExpired {
+			return apierror.Respond(c, fiber.StatusGone, apierror.CodeEmailChangeTokenExpired, "email change token expired")
+		}
+		if err == account.ErrEmailChangeTokenUsed {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeEmailChangeTokenUsed, "email change token already used")
+		}
+		if err == account.ErrDuplicateEmail {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeDuplicateEmail, "email already exists")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to confirm email change", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "email updated successfully",
+	})
 }
 
 // GetProfile handles GET /account/profile
 func (h *AccountHandlers) GetProfile(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 
 	ctx := c.Context()
 	player, err := h.accSvc.GetPlayer(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	// Convert timestamps to ISO 8601 strings
@@ -96,42 +264,30 @@ func (h *AccountHandlers) GetProfile(c *fiber.Ctx) error {
 func (h *AccountHandlers) UpdateProfile(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 
 	var req UpdateProfileRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 
-	if req.Username == "" || req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "username and email are required",
-		})
+	if req.Username == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "username is required")
 	}
 
 	ctx := c.Context()
-	err := h.accSvc.UpdatePlayerProfile(ctx, playerID, req.Username, req.Email)
+	err := h.accSvc.UpdatePlayerProfile(ctx, playerID, req.Username)
 	if err != nil {
-		if err == account.ErrDuplicateUsername {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "username already exists",
-			})
+		if err == account.ErrInvalidUsername {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidUsername, "username must be 2-32 letters, digits, underscores or hyphens")
 		}
-		if err == account.ErrDuplicateEmail {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "email already exists",
-			})
+		if err == account.ErrDuplicateUsername {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeDuplicateUsername, "username already exists")
 		}
-		h.logger.Error("failed to update player profile", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to update player profile", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -139,69 +295,218 @@ func (h *AccountHandlers) UpdateProfile(c *fiber.Ctx) error {
 	})
 }
 
+// ChangePassword handles PUT /account/password
+func (h *AccountHandlers) ChangePassword(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.OldPassword == "" || req.NewPassword == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "old_password and new_password are required")
+	}
+
+	ctx := c.Context()
+	err := h.accSvc.VerifyPassword(ctx, playerID, req.OldPassword)
+	if err == nil {
+		err = h.accSvc.UpdatePlayerPassword(ctx, playerID, req.NewPassword)
+	}
+	if err != nil {
+		if err == account.ErrInvalidPassword || err == account.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidPassword, "invalid password")
+		}
+		if errors.Is(err, account.ErrWeakPassword) {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeWeakPassword, "new "+err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to change password", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	// The password is already changed; a failed revocation is logged, not returned
+	revoked, err := h.accSvc.RevokeOtherSessions(ctx, playerID, req.RefreshToken)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to revoke sessions after password change", zap.Error(err), zap.Int64("player_id", playerID))
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":          "password updated successfully",
+		"revoked_sessions": revoked,
+	})
+}
+
+// DeleteAccount handles DELETE /account
+func (h *AccountHandlers) DeleteAccount(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	var req DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.Password == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "password is required")
+	}
+
+	ctx := c.Context()
+	err := h.accSvc.VerifyPassword(ctx, playerID, req.Password)
+	if err == nil {
+		err = h.accSvc.DeletePlayer(ctx, playerID)
+	}
+	if err != nil {
+		if err == account.ErrInvalidPassword || err == account.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidPassword, "invalid password")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to delete account", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ClaimReferral handles POST /account/referral/claim
+func (h *AccountHandlers) ClaimReferral(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	var req ClaimReferralRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.ReferralCode == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "referral_code is required")
+	}
+
+	referral, err := h.accSvc.ClaimReferral(c.Context(), playerID, req.ReferralCode)
+	if err != nil {
+		if err == account.ErrReferrerNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeReferrerNotFound, "referrer not found")
+		}
+		if err == account.ErrSelfReferral {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeSelfReferral, "cannot refer yourself")
+		}
+		if err == account.ErrReferralAlreadyClaimed {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeReferralAlreadyClaimed, "referral already claimed")
+		}
+		if err == account.ErrReferralWindowExpired {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeReferralWindowExpired, "referral claim window expired")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to claim referral", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"referral_id": referral.ReferralID,
+		"referrer_id": referral.ReferrerID,
+		"created_at":  referral.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
 // GetSettings handles GET /account/settings
 func (h *AccountHandlers) GetSettings(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	settings, err := h.accSvc.GetPlayerSettings(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player settings", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player settings", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	// Convert timestamps to ISO 8601 strings
 	createdAt := settings.CreatedAt.Time.Format("2006-01-02T15:04:05Z")
 	updatedAt := settings.UpdatedAt.Time.Format("2006-01-02T15:04:05Z")
 	resp := SettingsResponse{
-		PlayerID:         settings.PlayerID,
-		KeyBindings:      settings.KeyBindings,
-		MouseSensitivity: settings.MouseSensitivity,
-		UiScale:          settings.UiScale,
-		ColorBlindMode:   settings.ColorBlindMode,
-		SubtitlesEnabled: settings.SubtitlesEnabled,
-		CreatedAt:        createdAt,
-		UpdatedAt:        updatedAt,
+		PlayerID:                  settings.PlayerID,
+		KeyBindings:               settings.KeyBindings,
+		MouseSensitivity:          settings.MouseSensitivity,
+		UiScale:                   settings.UiScale,
+		ColorBlindMode:            settings.ColorBlindMode,
+		SubtitlesEnabled:          settings.SubtitlesEnabled,
+		AllowFriendRequests:       settings.AllowFriendRequests,
+		ShowOnLeaderboard:         settings.ShowOnLeaderboard,
+		MatchHistoryPublic:        settings.MatchHistoryPublic,
+		InventoryVisibleToFriends: settings.InventoryVisibleToFriends,
+		CreatedAt:                 createdAt,
+		UpdatedAt:                 updatedAt,
 	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
+// PatchSettings handles PATCH /account/settings
+func (h *AccountHandlers) PatchSettings(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	params, err := parseSettingsPatch(playerID, c.Body())
+	if err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+	if err := h.accSvc.UpdatePlayerSettingsPartial(c.Context(), params); err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to patch player settings", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "settings updated successfully",
+	})
+}
+
 // UpdateSettings handles PUT /account/settings
 func (h *AccountHandlers) UpdateSettings(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	var req UpdateSettingsRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	ctx := c.Context()
+	current, err := h.accSvc.GetPlayerSettings(ctx, playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player settings", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	params := &db.UpsertPlayerSettingsParams{
-		PlayerID:         playerID,
-		KeyBindings:      req.KeyBindings,
-		MouseSensitivity: req.MouseSensitivity,
-		UiScale:          req.UiScale,
-		ColorBlindMode:   req.ColorBlindMode,
-		SubtitlesEnabled: req.SubtitlesEnabled,
+		PlayerID:                  playerID,
+		KeyBindings:               req.KeyBindings,
+		MouseSensitivity:          req.MouseSensitivity,
+		UiScale:                   req.UiScale,
+		ColorBlindMode:            req.ColorBlindMode,
+		SubtitlesEnabled:          req.SubtitlesEnabled,
+		AllowFriendRequests:       current.AllowFriendRequests,
+		ShowOnLeaderboard:         current.ShowOnLeaderboard,
+		MatchHistoryPublic:        current.MatchHistoryPublic,
+		InventoryVisibleToFriends: current.InventoryVisibleToFriends,
 	}
-	ctx := c.Context()
-	err := h.accSvc.UpsertPlayerSettings(ctx, params)
+	if req.AllowFriendRequests != nil {
+		params.AllowFriendRequests = *req.AllowFriendRequests
+	}
+	if req.ShowOnLeaderboard != nil {
+		params.ShowOnLeaderboard = *req.ShowOnLeaderboard
+	}
+	if req.MatchHistoryPublic != nil {
+		params.MatchHistoryPublic = *req.MatchHistoryPublic
+	}
+	if req.InventoryVisibleToFriends != nil {
+		params.InventoryVisibleToFriends = *req.InventoryVisibleToFriends
+	}
+	err = h.accSvc.UpsertPlayerSettings(ctx, params)
 	if err != nil {
-		h.logger.Error("failed to upsert player settings", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to upsert player settings", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "settings updated successfully",
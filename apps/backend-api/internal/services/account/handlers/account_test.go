@@ -2,11 +2,15 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
 	"ai-zombie-defense/backend-api/internal/testutils"
@@ -66,7 +70,7 @@ func TestAccountHandlers_UpdateProfile(t *testing.T) {
 	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
 	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
 
-	// Test successful profile update
+	// Test successful profile update; an email in the body is ignored
 	updateBody := map[string]string{
 		"username": "newusername",
 		"email":    "newemail@example.com",
@@ -92,6 +96,9 @@ func TestAccountHandlers_UpdateProfile(t *testing.T) {
 	if username != "newusername" {
 		t.Errorf("Expected username newusername, got %s", username)
 	}
+	if email != "test@example.com" {
+		t.Errorf("Expected email to change only through verification, got %s", email)
+	}
 }
 
 func TestAccountHandlers_GetSettings(t *testing.T) {
@@ -143,3 +150,489 @@ func TestAccountHandlers_UpdateSettings(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestAccountHandlers_PatchSettings(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	patchSettings := func(body string) int {
+		req := httptest.NewRequest(http.MethodPatch, "/account/settings", bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	getSettings := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/account/settings", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var settings map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return settings
+	}
+
+	// PATCH creates the row when none exists yet
+	if status := patchSettings(`{"key_bindings": "WASD", "mouse_sensitivity": 1.5, "ui_scale": 1.25, "color_blind_mode": 1}`); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+
+	// Omitted fields are preserved
+	if status := patchSettings(`{"mouse_sensitivity": 2.5}`); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	settings := getSettings()
+	if settings["mouse_sensitivity"] != 2.5 {
+		t.Errorf("Expected mouse_sensitivity 2.5, got %v", settings["mouse_sensitivity"])
+	}
+	if settings["key_bindings"] != "WASD" || settings["ui_scale"] != 1.25 || settings["color_blind_mode"] != float64(1) {
+		t.Errorf("Expected omitted fields to be preserved, got %v", settings)
+	}
+
+	// An explicit null clears a nullable field without touching the others
+	if status := patchSettings(`{"key_bindings": null}`); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	settings = getSettings()
+	if _, ok := settings["key_bindings"]; ok {
+		t.Errorf("Expected key_bindings to be cleared, got %v", settings["key_bindings"])
+	}
+	if settings["mouse_sensitivity"] != 2.5 || settings["ui_scale"] != 1.25 {
+		t.Errorf("Expected other fields to be preserved, got %v", settings)
+	}
+
+	// NOT NULL fields reject null, and unknown fields are refused
+	if status := patchSettings(`{"color_blind_mode": null}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for null color_blind_mode, got %d", status)
+	}
+	if status := patchSettings(`{"volume": 3}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown field, got %d", status)
+	}
+	if status := patchSettings(`{"ui_scale": "big"}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for wrong type, got %d", status)
+	}
+}
+
+func TestAccountHandlers_UpdatePrivacySettings(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	putSettings := func(payload map[string]interface{}) {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPut, "/account/settings", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+	}
+	getSettings := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/account/settings", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var settings map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return settings
+	}
+
+	// Privacy preferences default to enabled
+	settings := getSettings()
+	if settings["allow_friend_requests"].(float64) != 1 || settings["show_on_leaderboard"].(float64) != 1 || settings["match_history_public"].(float64) != 1 {
+		t.Errorf("Expected privacy preferences to default to 1, got %v", settings)
+	}
+
+	putSettings(map[string]interface{}{
+		"allow_friend_requests": 0,
+		"show_on_leaderboard":   0,
+	})
+	settings = getSettings()
+	if settings["allow_friend_requests"].(float64) != 0 || settings["show_on_leaderboard"].(float64) != 0 {
+		t.Errorf("Expected privacy preferences to be disabled, got %v", settings)
+	}
+	if settings["match_history_public"].(float64) != 1 {
+		t.Errorf("Expected match_history_public to stay enabled, got %v", settings["match_history_public"])
+	}
+
+	// Omitting the privacy fields keeps the stored values
+	putSettings(map[string]interface{}{"subtitles_enabled": 1})
+	settings = getSettings()
+	if settings["allow_friend_requests"].(float64) != 0 || settings["show_on_leaderboard"].(float64) != 0 {
+		t.Errorf("Expected privacy preferences to be preserved, got %v", settings)
+	}
+}
+
+func TestAccountHandlers_DeleteAccount(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	friendID := testutils.CreateTestPlayer(t, db, "frienduser", "friend@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	testutils.CreateTestSession(t, db, playerID)
+	serverID := testutils.CreateTestServerRow(t, db)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level) VALUES ('Test Skin', 'character_skin', 'common', 1)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	seed := []string{
+		`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ` + strconv.FormatInt(cosmeticID, 10) + `, 'purchase')`,
+		`INSERT INTO loadouts (player_id, name, is_active) VALUES (?, 'Default', 1)`,
+		`INSERT INTO player_settings (player_id) VALUES (?)`,
+		`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ` + strconv.FormatInt(friendID, 10) + `, 'accepted')`,
+		`INSERT INTO server_favorites (player_id, server_id) VALUES (?, ` + strconv.FormatInt(serverID, 10) + `)`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.Exec(stmt, playerID); err != nil {
+			t.Fatalf("Failed to seed %q: %v", stmt, err)
+		}
+	}
+
+	deleteAccount := func(password string) int {
+		body, _ := json.Marshal(map[string]string{"password": password})
+		req := httptest.NewRequest(http.MethodDelete, "/account", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := deleteAccount("wrong-password"); status != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401 for wrong password, got %d", status)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM players WHERE player_id = ?`, playerID).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("Expected player to survive a failed deletion, count=%d err=%v", count, err)
+	}
+
+	if status := deleteAccount("password"); status != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", status)
+	}
+
+	tables := map[string]string{
+		"players":            `SELECT COUNT(*) FROM players WHERE player_id = ?`,
+		"sessions":           `SELECT COUNT(*) FROM sessions WHERE player_id = ?`,
+		"player_progression": `SELECT COUNT(*) FROM player_progression WHERE player_id = ?`,
+		"player_cosmetics":   `SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ?`,
+		"loadouts":           `SELECT COUNT(*) FROM loadouts WHERE player_id = ?`,
+		"player_settings":    `SELECT COUNT(*) FROM player_settings WHERE player_id = ?`,
+		"friends":            `SELECT COUNT(*) FROM friends WHERE player_id = ?1 OR friend_id = ?1`,
+		"server_favorites":   `SELECT COUNT(*) FROM server_favorites WHERE player_id = ?`,
+	}
+	for table, query := range tables {
+		if err := db.QueryRow(query, playerID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("Expected no %s rows after deletion, got %d", table, count)
+		}
+	}
+
+	// The other player is untouched
+	if err := db.QueryRow(`SELECT COUNT(*) FROM players WHERE player_id = ?`, friendID).Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected friend account to remain, count=%d err=%v", count, err)
+	}
+}
+
+// recordingMailer keeps the last email change it was asked to send, or fails with err.
+type recordingMailer struct {
+	to, token string
+	err       error
+}
+
+func (m *recordingMailer) SendEmailChange(ctx context.Context, to, token string, expiresAt time.Time) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.to, m.token = to, token
+	return nil
+}
+
+func TestAccountHandlers_EmailChange(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	mailer := &recordingMailer{}
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db, gateway.WithMailer(mailer)).Router()
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	testutils.CreateTestPlayer(t, db, "otheruser", "taken@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	post := func(path string, payload map[string]string) int {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	latestToken := func() string {
+		var token string
+		if err := db.QueryRow(`SELECT token FROM email_change_tokens WHERE player_id = ? ORDER BY email_change_token_id DESC LIMIT 1`, playerID).Scan(&token); err != nil {
+			t.Fatalf("Failed to read email change token: %v", err)
+		}
+		return token
+	}
+	currentEmail := func() string {
+		var email string
+		if err := db.QueryRow(`SELECT email FROM players WHERE player_id = ?`, playerID).Scan(&email); err != nil {
+			t.Fatalf("Failed to read email: %v", err)
+		}
+		return email
+	}
+
+	// Taken email is rejected up front
+	if status := post("/account/email/request", map[string]string{"email": "taken@example.com"}); status != http.StatusConflict {
+		t.Errorf("Expected status 409 for taken email, got %d", status)
+	}
+
+	// Happy path: the email only changes once the token is confirmed
+	if status := post("/account/email/request", map[string]string{"email": "new@example.com"}); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+	if email := currentEmail(); email != "test@example.com" {
+		t.Errorf("Expected email unchanged before confirmation, got %s", email)
+	}
+	token := latestToken()
+	if mailer.to != "new@example.com" || mailer.token != token {
+		t.Errorf("Expected the token mailed to new@example.com, got %q to %q", mailer.token, mailer.to)
+	}
+	if status := post("/account/email/confirm", map[string]string{"token": token}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if email := currentEmail(); email != "new@example.com" {
+		t.Errorf("Expected email new@example.com, got %s", email)
+	}
+	if status := post("/account/email/confirm", map[string]string{"token": token}); status != http.StatusConflict {
+		t.Errorf("Expected status 409 for reused token, got %d", status)
+	}
+
+	// Expired token
+	if status := post("/account/email/request", map[string]string{"email": "expired@example.com"}); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+	token = latestToken()
+	if _, err := db.Exec(`UPDATE email_change_tokens SET expires_at = '2020-01-01T00:00:00Z' WHERE token = ?`, token); err != nil {
+		t.Fatalf("Failed to expire token: %v", err)
+	}
+	if status := post("/account/email/confirm", map[string]string{"token": token}); status != http.StatusGone {
+		t.Errorf("Expected status 410 for expired token, got %d", status)
+	}
+	if email := currentEmail(); email != "new@example.com" {
+		t.Errorf("Expected email to stay new@example.com after expired confirm, got %s", email)
+	}
+
+	if status := post("/account/email/confirm", map[string]string{"token": "does-not-exist"}); status != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown token, got %d", status)
+	}
+
+	// A token that couldn't be mailed isn't kept
+	mailer.err = errors.New("smtp unavailable")
+	if status := post("/account/email/request", map[string]string{"email": "unsent@example.com"}); status != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500 when mailing fails, got %d", status)
+	}
+	var unsent int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM email_change_tokens WHERE new_email = 'unsent@example.com'`).Scan(&unsent); err != nil {
+		t.Fatalf("Failed to count email change tokens: %v", err)
+	}
+	if unsent != 0 {
+		t.Errorf("Expected no stored token after a failed send, got %d", unsent)
+	}
+}
+
+func TestAccountHandlers_ChangePassword(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	currentSession := testutils.CreateTestSession(t, db, playerID)
+	testutils.CreateTestSession(t, db, playerID)
+	testutils.CreateTestSession(t, db, playerID)
+
+	changePassword := func(payload map[string]string) int {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPut, "/account/password", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	login := func(password string) int {
+		body, _ := json.Marshal(map[string]string{"username_or_email": "testuser", "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	sessionCount := func() int {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE player_id = ?`, playerID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count sessions: %v", err)
+		}
+		return count
+	}
+
+	// Wrong old password
+	if status := changePassword(map[string]string{"old_password": "wrong-password", "new_password": "new-password"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for wrong old password, got %d", status)
+	}
+	// New password below the configured minimum
+	if status := changePassword(map[string]string{"old_password": "password", "new_password": "short"}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for short new password, got %d", status)
+	}
+	if count := sessionCount(); count != 3 {
+		t.Fatalf("Expected failed changes to keep all 3 sessions, got %d", count)
+	}
+
+	// Success keeps only the caller's session
+	if status := changePassword(map[string]string{
+		"old_password":  "password",
+		"new_password":  "new-password",
+		"refresh_token": currentSession,
+	}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	var remaining string
+	if err := db.QueryRow(`SELECT token FROM sessions WHERE player_id = ?`, playerID).Scan(&remaining); err != nil {
+		t.Fatalf("Failed to read remaining session: %v", err)
+	}
+	if count := sessionCount(); count != 1 || remaining != currentSession {
+		t.Errorf("Expected only the current session to remain, got %d sessions", count)
+	}
+	if status := login("password"); status != http.StatusUnauthorized {
+		t.Errorf("Expected old password to be rejected, got %d", status)
+	}
+	if status := login("new-password"); status != http.StatusOK {
+		t.Errorf("Expected new password to log in, got %d", status)
+	}
+}
+
+func TestAccountHandlers_ClaimReferral(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Recruiter Badge', 'badge', 'rare', 1, 0)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	cfg := testutils.GetTestConfig()
+	cfg.Account.ReferralCosmeticID = cosmeticID
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+	referrerID := testutils.CreateTestPlayer(t, db, "referrer", "referrer@example.com", "password")
+	newbieID := testutils.CreateTestPlayer(t, db, "newbie", "newbie@example.com", "password")
+	newbieToken := testutils.CreateTestAccessToken(t, db, newbieID)
+
+	claim := func(token, code string) int {
+		body, _ := json.Marshal(map[string]string{"referral_code": code})
+		req := httptest.NewRequest(http.MethodPost, "/account/referral/claim", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	balance := func(playerID int64) int64 {
+		var amount int64
+		if err := db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&amount); err != nil {
+			t.Fatalf("Failed to get balance: %v", err)
+		}
+		return amount
+	}
+	ownsCosmetic := func(playerID int64) bool {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ? AND unlocked_via = 'referral'`, playerID, cosmeticID).Scan(&count); err != nil {
+			t.Fatalf("Failed to check cosmetic: %v", err)
+		}
+		return count == 1
+	}
+
+	t.Run("Self referral", func(t *testing.T) {
+		if status := claim(newbieToken, "newbie"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("Unknown referrer", func(t *testing.T) {
+		if status := claim(newbieToken, "nobody"); status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+	})
+
+	t.Run("Valid claim", func(t *testing.T) {
+		if status := claim(newbieToken, "referrer"); status != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", status)
+		}
+		for _, id := range []int64{referrerID, newbieID} {
+			if got := balance(id); got != cfg.Account.ReferralCurrencyReward {
+				t.Errorf("Expected player %d balance %d, got %d", id, cfg.Account.ReferralCurrencyReward, got)
+			}
+			if !ownsCosmetic(id) {
+				t.Errorf("Expected player %d to own the referral cosmetic", id)
+			}
+		}
+	})
+
+	t.Run("Double claim", func(t *testing.T) {
+		if status := claim(newbieToken, "referrer"); status != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", status)
+		}
+		if got := balance(referrerID); got != cfg.Account.ReferralCurrencyReward {
+			t.Errorf("Expected referrer to be rewarded once, balance %d", got)
+		}
+	})
+
+	t.Run("Claim window expired", func(t *testing.T) {
+		veteranID := testutils.CreateTestPlayer(t, db, "veteran", "veteran@example.com", "password")
+		if _, err := db.Exec(`UPDATE players SET created_at = '2020-01-01T00:00:00Z' WHERE player_id = ?`, veteranID); err != nil {
+			t.Fatalf("Failed to backdate player: %v", err)
+		}
+		veteranToken := testutils.CreateTestAccessToken(t, db, veteranID)
+		if status := claim(veteranToken, "referrer"); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package account
+
+import (
+	"net/mail"
+	"strings"
+)
+
+const (
+	UsernameMinLength = 2
+	UsernameMaxLength = 32
+)
+
+// ValidateUsername checks that username is UsernameMinLength to
+// UsernameMaxLength ASCII letters, digits, underscores or hyphens.
+func ValidateUsername(username string) error {
+	if len(username) < UsernameMinLength || len(username) > UsernameMaxLength {
+		return ErrInvalidUsername
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return ErrInvalidUsername
+		}
+	}
+	return nil
+}
+
+// NormalizeEmail trims and lowercases an email, the form it is stored and
+// looked up in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that email is a bare address (no display name) whose
+// domain has at least one dot.
+func ValidateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrInvalidEmail
+	}
+	at := strings.LastIndexByte(email, '@')
+	if domain := email[at+1:]; !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return ErrInvalidEmail
+	}
+	return nil
+}
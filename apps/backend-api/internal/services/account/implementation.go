@@ -3,12 +3,17 @@ package account
 import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/db/types"
+	"ai-zombie-defense/backend-api/internal/metrics"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
+	cryptorand "crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
@@ -19,14 +24,21 @@ type accountService struct {
 	logger  *zap.Logger
 	dbConn  db.DBTX
 	queries *db.Queries
+	mailer  Mailer
 }
 
-func NewAccountService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX) Service {
+// NewAccountService creates the account service. A nil mailer falls back to
+// NewLogMailer.
+func NewAccountService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX, mailer Mailer) Service {
+	if mailer == nil {
+		mailer = NewLogMailer(logger)
+	}
 	return &accountService{
 		config:  cfg,
 		logger:  logger,
 		dbConn:  dbConn,
 		queries: db.New(),
+		mailer:  mailer,
 	}
 }
 
@@ -38,26 +50,133 @@ func (s *accountService) GetPlayer(ctx context.Context, playerID int64) (*db.Pla
 	return player, nil
 }
 
-func (s *accountService) UpdatePlayerProfile(ctx context.Context, playerID int64, username, email string) error {
+func (s *accountService) UpdatePlayerProfile(ctx context.Context, playerID int64, username string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
 	params := &db.UpdatePlayerProfileParams{
 		PlayerID: playerID,
 		Username: username,
-		Email:    email,
 	}
 	err := s.queries.UpdatePlayerProfile(ctx, s.dbConn, params)
 	if err != nil {
 		if s.isDuplicateError(err, "username") {
 			return ErrDuplicateUsername
 		}
-		if s.isDuplicateError(err, "email") {
-			return ErrDuplicateEmail
-		}
 		return fmt.Errorf("failed to update player profile: %w", err)
 	}
 	return nil
 }
 
+// RequestEmailChange stores a pending email change behind a random token that
+// expires after Account.EmailChangeTokenExpiry and mails the token to the new
+// address. Earlier unused tokens for the player are discarded. The email is
+// only changed by ConfirmEmailChange.
+func (s *accountService) RequestEmailChange(ctx context.Context, playerID int64, newEmail string) (*db.EmailChangeToken, error) {
+	newEmail = NormalizeEmail(newEmail)
+	if err := ValidateEmail(newEmail); err != nil {
+		return nil, err
+	}
+	player, err := s.queries.GetPlayer(ctx, s.dbConn, playerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+	if strings.EqualFold(player.Email, newEmail) {
+		return nil, ErrEmailUnchanged
+	}
+	if _, err := s.queries.GetPlayerByEmail(ctx, s.dbConn, newEmail); err == nil {
+		return nil, ErrDuplicateEmail
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := cryptorand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate random token: %w", err)
+	}
+
+	var token *db.EmailChangeToken
+	err = db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if err := s.queries.DeletePendingEmailChangeTokens(ctx, tx, playerID); err != nil {
+			return fmt.Errorf("failed to delete pending email change tokens: %w", err)
+		}
+		var err error
+		token, err = s.queries.CreateEmailChangeToken(ctx, tx, &db.CreateEmailChangeTokenParams{
+			Token:     hex.EncodeToString(tokenBytes),
+			PlayerID:  playerID,
+			NewEmail:  newEmail,
+			ExpiresAt: types.Timestamp{Time: time.Now().UTC().Add(s.config.Account.EmailChangeTokenExpiry)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create email change token: %w", err)
+		}
+
+		// Mail before committing so a token nobody received is rolled back
+		if err := s.mailer.SendEmailChange(ctx, token.NewEmail, token.Token, token.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("failed to send email change token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx, s.logger).Info("email change requested", zap.Int64("player_id", playerID))
+	return token, nil
+}
+
+// ConfirmEmailChange applies the email change behind token. Each token can be
+// used once and only before it expires.
+func (s *accountService) ConfirmEmailChange(ctx context.Context, token string) error {
+	var playerID int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		change, err := s.queries.GetEmailChangeToken(ctx, tx, token)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEmailChangeTokenNotFound
+			}
+			return fmt.Errorf("failed to get email change token: %w", err)
+		}
+		if change.UsedAt.Valid {
+			return ErrEmailChangeTokenUsed
+		}
+		if !time.Now().UTC().Before(change.ExpiresAt.Time) {
+			return ErrEmailChangeTokenExpired
+		}
+
+		marked, err := s.queries.MarkEmailChangeTokenUsed(ctx, tx, token)
+		if err != nil {
+			return fmt.Errorf("failed to mark email change token used: %w", err)
+		}
+		if marked == 0 {
+			return ErrEmailChangeTokenUsed
+		}
+		err = s.queries.UpdatePlayerEmail(ctx, tx, &db.UpdatePlayerEmailParams{
+			Email:    change.NewEmail,
+			PlayerID: change.PlayerID,
+		})
+		if err != nil {
+			if s.isDuplicateError(err, "email") {
+				return ErrDuplicateEmail
+			}
+			return fmt.Errorf("failed to update player email: %w", err)
+		}
+		playerID = change.PlayerID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx, s.logger).Info("email change confirmed", zap.Int64("player_id", playerID))
+	return nil
+}
+
 func (s *accountService) UpdatePlayerPassword(ctx context.Context, playerID int64, newPassword string) error {
+	if err := ValidatePassword(s.config.Account.PasswordPolicy, newPassword); err != nil {
+		return err
+	}
 	hash, err := s.hashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
@@ -73,20 +192,129 @@ func (s *accountService) UpdatePlayerPassword(ctx context.Context, playerID int6
 	return nil
 }
 
+func (s *accountService) RevokeOtherSessions(ctx context.Context, playerID int64, keepToken string) (int64, error) {
+	revoked, err := s.queries.DeleteOtherSessionsByPlayer(ctx, s.dbConn, &db.DeleteOtherSessionsByPlayerParams{
+		PlayerID: playerID,
+		Token:    keepToken,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return revoked, nil
+}
+
+// VerifyPassword checks password against the player's stored hash and
+// returns ErrInvalidPassword on mismatch.
+func (s *accountService) VerifyPassword(ctx context.Context, playerID int64, password string) error {
+	player, err := s.queries.GetPlayer(ctx, s.dbConn, playerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPlayerNotFound
+		}
+		return fmt.Errorf("failed to get player: %w", err)
+	}
+	if !s.verifyPassword(player.PasswordHash, password) {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// DeletePlayer permanently removes a player. Sessions, progression, cosmetics,
+// loadouts, match stats, friends, favorites and other player-owned rows are
+// removed by the ON DELETE CASCADE foreign keys.
+func (s *accountService) DeletePlayer(ctx context.Context, playerID int64) error {
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if _, err := s.queries.GetPlayer(ctx, tx, playerID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("failed to get player: %w", err)
+		}
+		if err := s.queries.DeletePlayer(ctx, tx, playerID); err != nil {
+			return fmt.Errorf("failed to delete player: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx, s.logger).Info("player account deleted", zap.Int64("player_id", playerID))
+	return nil
+}
+
+func (s *accountService) ClaimReferral(ctx context.Context, playerID int64, referrerUsername string) (*db.Referral, error) {
+	var referral *db.Referral
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		player, err := s.queries.GetPlayer(ctx, tx, playerID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("failed to get player: %w", err)
+		}
+		referrer, err := s.queries.GetPlayerByUsername(ctx, tx, referrerUsername)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrReferrerNotFound
+			}
+			return fmt.Errorf("failed to get referrer: %w", err)
+		}
+		if referrer.PlayerID == playerID {
+			return ErrSelfReferral
+		}
+		if _, err := s.queries.GetReferralByReferred(ctx, tx, playerID); err == nil {
+			return ErrReferralAlreadyClaimed
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check referral: %w", err)
+		}
+		if time.Since(player.CreatedAt.Time) > s.config.Account.ReferralClaimWindow {
+			return ErrReferralWindowExpired
+		}
+
+		referral, err = s.queries.CreateReferral(ctx, tx, &db.CreateReferralParams{
+			ReferrerID: referrer.PlayerID,
+			ReferredID: playerID,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed: referrals.referred_id") {
+				return ErrReferralAlreadyClaimed
+			}
+			return fmt.Errorf("failed to create referral: %w", err)
+		}
+		for _, id := range []int64{referrer.PlayerID, playerID} {
+			if err := s.grantReferralReward(ctx, tx, id, referral.ReferralID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx, s.logger).Info("referral claimed",
+		zap.Int64("player_id", playerID),
+		zap.Int64("referrer_id", referral.ReferrerID))
+	return referral, nil
+}
+
 func (s *accountService) GetPlayerSettings(ctx context.Context, playerID int64) (*db.PlayerSetting, error) {
 	settings, err := s.queries.GetPlayerSettings(ctx, s.dbConn, playerID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Return default settings
 			return &db.PlayerSetting{
-				PlayerID:         playerID,
-				KeyBindings:      nil,
-				MouseSensitivity: nil,
-				UiScale:          nil,
-				ColorBlindMode:   0,
-				SubtitlesEnabled: 0,
-				CreatedAt:        types.Timestamp{},
-				UpdatedAt:        types.Timestamp{},
+				PlayerID:                  playerID,
+				KeyBindings:               nil,
+				MouseSensitivity:          nil,
+				UiScale:                   nil,
+				ColorBlindMode:            0,
+				SubtitlesEnabled:          0,
+				CreatedAt:                 types.Timestamp{},
+				UpdatedAt:                 types.Timestamp{},
+				AllowFriendRequests:       1,
+				ShowOnLeaderboard:         1,
+				MatchHistoryPublic:        1,
+				InventoryVisibleToFriends: 1,
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to get player settings: %w", err)
@@ -102,6 +330,16 @@ func (s *accountService) UpsertPlayerSettings(ctx context.Context, params *db.Up
 	return nil
 }
 
+// UpdatePlayerSettingsPartial writes only the fields present in params,
+// creating the settings row with defaults for everything else if needed.
+func (s *accountService) UpdatePlayerSettingsPartial(ctx context.Context, params *db.UpdatePlayerSettingsPartialParams) error {
+	err := s.queries.UpdatePlayerSettingsPartial(ctx, s.dbConn, params)
+	if err != nil {
+		return fmt.Errorf("failed to update player settings: %w", err)
+	}
+	return nil
+}
+
 // Internal helpers
 
 func (s *accountService) hashPassword(password string) (string, error) {
@@ -112,6 +350,62 @@ func (s *accountService) hashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
+func (s *accountService) verifyPassword(hash, password string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
+
+// grantReferralReward credits the configured referral currency and cosmetic
+// to playerID. A cosmetic the player already owns is skipped.
+func (s *accountService) grantReferralReward(ctx context.Context, dbTx db.DBTX, playerID, referralID int64) error {
+	if amount := s.config.Account.ReferralCurrencyReward; amount > 0 {
+		err := s.queries.AddDataCurrency(ctx, dbTx, &db.AddDataCurrencyParams{
+			DataCurrency: amount,
+			PlayerID:     playerID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add referral currency: %w", err)
+		}
+		balance, err := s.queries.GetDataCurrency(ctx, dbTx, playerID)
+		if err != nil {
+			return fmt.Errorf("failed to get data currency: %w", err)
+		}
+		err = s.queries.CreateCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          amount,
+			BalanceAfter:    balance,
+			TransactionType: ReferralTransactionType,
+			ReferenceID:     &referralID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record referral transaction: %w", err)
+		}
+		metrics.RecordCurrency(ReferralTransactionType, amount)
+	}
+
+	if cosmeticID := s.config.Account.ReferralCosmeticID; cosmeticID > 0 {
+		_, err := s.queries.GetPlayerCosmetic(ctx, dbTx, &db.GetPlayerCosmeticParams{
+			PlayerID:   playerID,
+			CosmeticID: cosmeticID,
+		})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check referral cosmetic: %w", err)
+		}
+		err = s.queries.GrantCosmeticToPlayer(ctx, dbTx, &db.GrantCosmeticToPlayerParams{
+			PlayerID:    playerID,
+			CosmeticID:  cosmeticID,
+			UnlockedVia: ReferralUnlockedVia,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to grant referral cosmetic: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *accountService) isDuplicateError(err error, column string) bool {
 	if err == nil {
 		return false
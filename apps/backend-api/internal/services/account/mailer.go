@@ -0,0 +1,35 @@
+package account
+
+import (
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Mailer delivers account emails.
+type Mailer interface {
+	// SendEmailChange sends the verification token of a pending email change
+	// to the new address.
+	SendEmailChange(ctx context.Context, to, token string, expiresAt time.Time) error
+}
+
+// logMailer stands in for a mail transport: it logs email change tokens at
+// debug level so the verification flow can be exercised in development.
+type logMailer struct {
+	logger *zap.Logger
+}
+
+// NewLogMailer returns a Mailer that only logs.
+func NewLogMailer(logger *zap.Logger) Mailer {
+	return &logMailer{logger: logger}
+}
+
+func (m *logMailer) SendEmailChange(ctx context.Context, to, token string, expiresAt time.Time) error {
+	logging.FromContext(ctx, m.logger).Debug("email change token (no mail transport configured)",
+		zap.String("to", to),
+		zap.String("token", token),
+		zap.Time("expires_at", expiresAt))
+	return nil
+}
@@ -0,0 +1,37 @@
+package account
+
+import (
+	"ai-zombie-defense/backend-api/pkg/config"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidatePassword checks password against policy. The returned error wraps
+// ErrWeakPassword and names the first rule the password fails.
+func ValidatePassword(policy config.PasswordPolicy, password string) error {
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrWeakPassword, policy.MinLength)
+	}
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireLetter && !hasLetter {
+		return fmt.Errorf("%w: must contain a letter", ErrWeakPassword)
+	}
+	if policy.RequireDigit && !hasDigit {
+		return fmt.Errorf("%w: must contain a digit", ErrWeakPassword)
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("%w: must contain a symbol", ErrWeakPassword)
+	}
+	return nil
+}
@@ -9,12 +9,49 @@ import (
 var (
 	ErrDuplicateUsername = errors.New("username already exists")
 	ErrDuplicateEmail    = errors.New("email already exists")
+	ErrInvalidPassword   = errors.New("invalid password")
+	ErrPlayerNotFound    = errors.New("player not found")
+	ErrEmailUnchanged    = errors.New("new email matches current email")
+	ErrWeakPassword      = errors.New("password is too weak")
+	ErrInvalidUsername   = errors.New("invalid username")
+	ErrInvalidEmail      = errors.New("invalid email")
+
+	ErrEmailChangeTokenNotFound = errors.New("email change token not found")
+	ErrEmailChangeTokenExpired  = errors.New("email change token expired")
+	ErrEmailChangeTokenUsed     = errors.New("email change token already used")
+
+	ErrReferrerNotFound       = errors.New("referrer not found")
+	ErrSelfReferral           = errors.New("cannot refer yourself")
+	ErrReferralAlreadyClaimed = errors.New("referral already claimed")
+	ErrReferralWindowExpired  = errors.New("referral claim window expired")
 )
 
+// ReferralTransactionType tags the currency transactions of referral rewards.
+const ReferralTransactionType = "other"
+
+// ReferralUnlockedVia marks cosmetics granted as referral rewards.
+const ReferralUnlockedVia = "referral"
+
 type Service interface {
 	GetPlayer(ctx context.Context, playerID int64) (*db.Player, error)
-	UpdatePlayerProfile(ctx context.Context, playerID int64, username, email string) error
+	// UpdatePlayerProfile changes the username; email changes go through
+	// RequestEmailChange and ConfirmEmailChange.
+	UpdatePlayerProfile(ctx context.Context, playerID int64, username string) error
 	UpdatePlayerPassword(ctx context.Context, playerID int64, newPassword string) error
+	// RequestEmailChange stores a pending email change and sends its token to
+	// the new address through the Mailer.
+	RequestEmailChange(ctx context.Context, playerID int64, newEmail string) (*db.EmailChangeToken, error)
+	ConfirmEmailChange(ctx context.Context, token string) error
+	VerifyPassword(ctx context.Context, playerID int64, password string) error
+	// RevokeOtherSessions deletes every session of the player except the one
+	// identified by keepToken (all of them when keepToken is empty).
+	RevokeOtherSessions(ctx context.Context, playerID int64, keepToken string) (int64, error)
+	DeletePlayer(ctx context.Context, playerID int64) error
+	// ClaimReferral records referrerUsername as the player's referrer and
+	// grants the configured reward to both players. Each player can claim
+	// once, within Account.ReferralClaimWindow of registering.
+	ClaimReferral(ctx context.Context, playerID int64, referrerUsername string) (*db.Referral, error)
 	GetPlayerSettings(ctx context.Context, playerID int64) (*db.PlayerSetting, error)
 	UpsertPlayerSettings(ctx context.Context, params *db.UpsertPlayerSettingsParams) error
+	UpdatePlayerSettingsPartial(ctx context.Context, params *db.UpdatePlayerSettingsPartialParams) error
 }
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// adminStatusCache remembers IsAdmin results per player for a fixed TTL, so
+// admin routes don't query players on every request. A ttl of 0 disables it.
+type adminStatusCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[int64]adminStatusEntry
+}
+
+type adminStatusEntry struct {
+	isAdmin   bool
+	expiresAt time.Time
+}
+
+func newAdminStatusCache(ttl time.Duration) *adminStatusCache {
+	return &adminStatusCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[int64]adminStatusEntry),
+	}
+}
+
+func (c *adminStatusCache) get(playerID int64) (isAdmin bool, ok bool) {
+	if c.ttl <= 0 {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[playerID]
+	if !ok {
+		return false, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, playerID)
+		return false, false
+	}
+	return entry.isAdmin, true
+}
+
+func (c *adminStatusCache) set(playerID int64, isAdmin bool) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[playerID] = adminStatusEntry{isAdmin: isAdmin, expiresAt: c.now().Add(c.ttl)}
+}
+
+func (c *adminStatusCache) invalidate(playerID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, playerID)
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdminStatusCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newAdminStatusCache(30 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.get(1); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+	cache.set(1, true)
+	cache.set(2, false)
+	if isAdmin, ok := cache.get(1); !ok || !isAdmin {
+		t.Errorf("Expected cached admin for player 1, got %v %v", isAdmin, ok)
+	}
+	if isAdmin, ok := cache.get(2); !ok || isAdmin {
+		t.Errorf("Expected cached non-admin for player 2, got %v %v", isAdmin, ok)
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(29 * time.Second)
+	if _, ok := cache.get(1); !ok {
+		t.Error("Expected entry to survive until the TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.get(1); ok {
+		t.Error("Expected entry to expire at the TTL")
+	}
+
+	cache.invalidate(2)
+	if _, ok := cache.get(2); ok {
+		t.Error("Expected invalidated entry to miss")
+	}
+}
+
+func TestAdminStatusCacheDisabled(t *testing.T) {
+	cache := newAdminStatusCache(0)
+	cache.set(1, true)
+	if _, ok := cache.get(1); ok {
+		t.Error("Expected a zero TTL to disable caching")
+	}
+}
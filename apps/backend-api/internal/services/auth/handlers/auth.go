@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/api/validation"
+	"ai-zombie-defense/backend-api/internal/metrics"
+	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/account"
 	"ai-zombie-defense/backend-api/internal/services/auth"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -25,8 +31,8 @@ func NewAuthHandlers(service auth.Service, cfg config.Config, logger *zap.Logger
 }
 
 type LoginRequest struct {
-	UsernameOrEmail string `json:"username_or_email"`
-	Password        string `json:"password"`
+	UsernameOrEmail string `json:"username_or_email" validate:"required"`
+	Password        string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -36,10 +42,19 @@ type LoginResponse struct {
 	PlayerID     int64     `json:"player_id"`
 }
 
+// RefreshResponse adds the refresh token's expiry and the session's absolute
+// deadline to LoginResponse. SessionExpiresAt is omitted when refreshing can
+// keep the session alive indefinitely.
+type RefreshResponse struct {
+	LoginResponse
+	RefreshExpiresAt time.Time  `json:"refresh_expires_at"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
+}
+
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type RegisterResponse struct {
@@ -51,56 +66,50 @@ type RegisterResponse struct {
 	Email        string    `json:"email"`
 }
 
+// MeResponse is the identity carried by the caller's access token.
+type MeResponse struct {
+	PlayerID  int64     `json:"player_id"`
+	Username  string    `json:"username"`
+	IsAdmin   bool      `json:"is_admin"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Login handles POST /auth/login
 func (h *AuthHandlers) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-
-	if req.UsernameOrEmail == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "username_or_email and password are required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	ctx := c.Context()
 	player, err := h.service.Authenticate(ctx, req.UsernameOrEmail, req.Password)
+	metrics.RecordAuthAttempt("login", err)
 	if err != nil {
 		if err == auth.ErrInvalidCredentials {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid credentials",
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidCredentials, "invalid credentials")
 		}
 		if err == auth.ErrPlayerBanned {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "player is banned",
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodePlayerBanned, "player is banned")
+		}
+		if err == auth.ErrAccountLocked {
+			return apierror.Respond(c, fiber.StatusLocked, apierror.CodeAccountLocked, "account is temporarily locked")
 		}
-		h.logger.Error("authentication failed", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("authentication failed", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
-	accessToken, err := h.service.GenerateAccessToken(player.PlayerID)
+	accessToken, err := h.service.GenerateAccessToken(ctx, player.PlayerID)
 	if err != nil {
-		h.logger.Error("failed to generate access token", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to generate access token", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	ip := c.IP()
 	userAgent := c.Get("User-Agent")
 	refreshToken, err := h.service.CreateSession(ctx, player.PlayerID, ip, userAgent)
 	if err != nil {
-		h.logger.Error("failed to create session", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to create session", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	exp := time.Now().Add(h.config.JWT.AccessExpiration)
@@ -116,53 +125,45 @@ func (h *AuthHandlers) Login(c *fiber.Ctx) error {
 // Register handles POST /auth/register
 func (h *AuthHandlers) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-
-	if req.Username == "" || req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "username, email, and password are required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	ctx := c.Context()
 	player, err := h.service.RegisterPlayer(ctx, req.Username, req.Email, req.Password)
+	metrics.RecordAuthAttempt("register", err)
 	if err != nil {
+		if err == account.ErrInvalidUsername {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidUsername, "username must be 2-32 letters, digits, underscores or hyphens")
+		}
+		if err == account.ErrInvalidEmail {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidEmail, "invalid email")
+		}
 		if err == account.ErrDuplicateUsername {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "username already exists",
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeDuplicateUsername, "username already exists")
 		}
 		if err == account.ErrDuplicateEmail {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "email already exists",
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeDuplicateEmail, "email already exists")
 		}
-		h.logger.Error("registration failed", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		if errors.Is(err, account.ErrWeakPassword) {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeWeakPassword, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("registration failed", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
-	accessToken, err := h.service.GenerateAccessToken(player.PlayerID)
+	accessToken, err := h.service.GenerateAccessToken(ctx, player.PlayerID)
 	if err != nil {
-		h.logger.Error("failed to generate access token", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to generate access token", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	ip := c.IP()
 	userAgent := c.Get("User-Agent")
 	refreshToken, err := h.service.CreateSession(ctx, player.PlayerID, ip, userAgent)
 	if err != nil {
-		h.logger.Error("failed to create session", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to create session", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	exp := time.Now().Add(h.config.JWT.AccessExpiration)
@@ -180,49 +181,42 @@ func (h *AuthHandlers) Register(c *fiber.Ctx) error {
 // Refresh handles POST /auth/refresh
 func (h *AuthHandlers) Refresh(c *fiber.Ctx) error {
 	var req struct {
-		RefreshToken string `json:"refresh_token"`
-	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
-	if req.RefreshToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "refresh_token is required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	ctx := c.Context()
 	ip := c.IP()
 	userAgent := c.Get("User-Agent")
-	playerID, newRefreshToken, err := h.service.RefreshSession(ctx, req.RefreshToken, ip, userAgent)
+	session, err := h.service.RefreshSession(ctx, req.RefreshToken, ip, userAgent)
 	if err != nil {
 		if err == auth.ErrInvalidRefreshToken || err == auth.ErrSessionNotFound {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid refresh token",
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeInvalidRefreshToken, "invalid refresh token")
 		}
-		h.logger.Error("refresh failed", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("refresh failed", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
-	accessToken, err := h.service.GenerateAccessToken(playerID)
+	accessToken, err := h.service.GenerateAccessToken(ctx, session.PlayerID)
 	if err != nil {
-		h.logger.Error("failed to generate access token", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to generate access token", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	exp := time.Now().Add(h.config.JWT.AccessExpiration)
-	resp := LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresAt:    exp,
-		PlayerID:     playerID,
+	resp := RefreshResponse{
+		LoginResponse: LoginResponse{
+			AccessToken:  accessToken,
+			RefreshToken: session.RefreshToken,
+			ExpiresAt:    exp,
+			PlayerID:     session.PlayerID,
+		},
+		RefreshExpiresAt: session.ExpiresAt,
+	}
+	if !session.Deadline.IsZero() {
+		resp.SessionExpiresAt = &session.Deadline
 	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
@@ -230,29 +224,61 @@ func (h *AuthHandlers) Refresh(c *fiber.Ctx) error {
 // Logout handles POST /auth/logout
 func (h *AuthHandlers) Logout(c *fiber.Ctx) error {
 	var req struct {
-		RefreshToken string `json:"refresh_token"`
+		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-	if req.RefreshToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "refresh_token is required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	ctx := c.Context()
 	err := h.service.DeleteSession(ctx, req.RefreshToken)
 	if err != nil {
-		h.logger.Error("logout failed", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("logout failed", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "logged out successfully",
 	})
 }
+
+// LogoutAll handles POST /auth/logout-all
+func (h *AuthHandlers) LogoutAll(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	deleted, err := h.service.DeleteAllSessionsForPlayer(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("logout-all failed", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":          "all sessions logged out",
+		"deleted_sessions": deleted,
+	})
+}
+
+// Me handles GET /auth/me. It answers from the validated token alone, without
+// loading the player, so the username and admin flag are as of token issue.
+func (h *AuthHandlers) Me(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	claims, claimsOK := middleware.GetClaims(c)
+	if !ok || !claimsOK {
+		logging.FromContext(c.Context(), h.logger).Error("player ID or claims missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	resp := MeResponse{
+		PlayerID: playerID,
+		Username: claims.Username,
+		IsAdmin:  claims.IsAdmin != nil && *claims.IsAdmin,
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
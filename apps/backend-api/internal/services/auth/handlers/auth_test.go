@@ -2,12 +2,17 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"ai-zombie-defense/backend-api/internal/api/validation"
+	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/auth"
 	"ai-zombie-defense/backend-api/internal/services/auth/handlers"
 	"ai-zombie-defense/backend-api/internal/testutils"
@@ -28,6 +33,8 @@ func createTestServer(t *testing.T, db *sql.DB) *fiber.App {
 	authGroup.Post("/register", authHandlers.Register)
 	authGroup.Post("/refresh", authHandlers.Refresh)
 	authGroup.Post("/logout", authHandlers.Logout)
+	authGroup.Post("/logout-all", middleware.AuthMiddleware(authService, logger), authHandlers.LogoutAll)
+	authGroup.Get("/me", middleware.AuthMiddleware(authService, logger), authHandlers.Me)
 	return app
 }
 
@@ -62,6 +69,12 @@ func TestAuthHandlers_Refresh(t *testing.T) {
 	if _, ok := result["refresh_token"]; !ok {
 		t.Error("Response missing refresh_token")
 	}
+	if _, ok := result["refresh_expires_at"]; !ok {
+		t.Error("Response missing refresh_expires_at")
+	}
+	if _, ok := result["session_expires_at"]; !ok {
+		t.Error("Response missing session_expires_at")
+	}
 	newRefreshToken, _ := result["refresh_token"].(string)
 	if newRefreshToken == "" {
 		t.Error("New refresh token is empty")
@@ -121,6 +134,184 @@ func TestAuthHandlers_Logout(t *testing.T) {
 	}
 }
 
+func TestAuthHandlers_LogoutAll(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	otherID := testutils.CreateTestPlayer(t, db, "otheruser", "other@example.com", "password")
+	otherToken := testutils.CreateTestSession(t, db, otherID)
+	refreshTokens := []string{
+		testutils.CreateTestSession(t, db, playerID),
+		testutils.CreateTestSession(t, db, playerID),
+		testutils.CreateTestSession(t, db, playerID),
+	}
+
+	// Requires authentication
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without token, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+testutils.CreateTestAccessToken(t, db, playerID))
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result struct {
+		DeletedSessions int64 `json:"deleted_sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.DeletedSessions != 3 {
+		t.Errorf("Expected 3 deleted sessions, got %d", result.DeletedSessions)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE player_id = ?`, playerID).Scan(&count); err != nil {
+		t.Fatalf("Failed to query session count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected 0 sessions after logout-all, got %d", count)
+	}
+
+	refresh := func(token string) int {
+		body, _ := json.Marshal(map[string]string{"refresh_token": token})
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	for i, token := range refreshTokens {
+		if status := refresh(token); status != http.StatusUnauthorized {
+			t.Errorf("Expected refresh token %d to be rejected with 401, got %d", i, status)
+		}
+	}
+	// Other players' sessions are untouched
+	if status := refresh(otherToken); status != http.StatusOK {
+		t.Errorf("Expected other player's refresh to succeed, got %d", status)
+	}
+}
+
+func TestAuthHandlers_Me(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	me := func(token string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	// A valid token returns its identity
+	resp := me(testutils.CreateTestAccessToken(t, db, playerID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result handlers.MeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.PlayerID != playerID || result.Username != "testuser" || result.IsAdmin {
+		t.Errorf("Unexpected identity: %+v", result)
+	}
+	if !result.ExpiresAt.After(time.Now()) {
+		t.Errorf("Expected expires_at in the future, got %v", result.ExpiresAt)
+	}
+
+	// An expired token is rejected
+	cfg := testutils.GetTestConfig()
+	cfg.JWT.AccessExpiration = -time.Minute
+	expired, err := auth.NewAuthService(cfg, zaptest.NewLogger(t), db).GenerateAccessToken(context.Background(), playerID)
+	if err != nil {
+		t.Fatalf("Failed to generate expired token: %v", err)
+	}
+	if resp := me(expired); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for expired token, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthHandlers_RegisterValidation(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db)
+
+	body, _ := json.Marshal(map[string]string{"email": "someone@example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", resp.StatusCode)
+	}
+	var result struct {
+		Code   string                  `json:"code"`
+		Fields []validation.FieldError `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := []validation.FieldError{
+		{Field: "username", Message: "is required"},
+		{Field: "password", Message: "is required"},
+	}
+	if result.Code != "VALIDATION_FAILED" || !reflect.DeepEqual(result.Fields, want) {
+		t.Errorf("Expected VALIDATION_FAILED with %+v, got %s %+v", want, result.Code, result.Fields)
+	}
+}
+
+func TestAuthHandlers_RegisterWeakPassword(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db)
+
+	body, _ := json.Marshal(map[string]string{
+		"username": "weakuser",
+		"email":    "weak@example.com",
+		"password": "short",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+	}
+	var result map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if want := "password is too weak: must be at least 8 characters long"; result["error"] != want {
+		t.Errorf("Expected error %q, got %q", want, result["error"])
+	}
+	if result["code"] != "WEAK_PASSWORD" {
+		t.Errorf("Expected code WEAK_PASSWORD, got %q", result["code"])
+	}
+}
+
 func TestAuthHandlers_RegisterAndLogin(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -154,6 +345,29 @@ func TestAuthHandlers_RegisterAndLogin(t *testing.T) {
 		t.Error("Registration response missing refresh_token")
 	}
 
+	// Registering the same username again reports a duplicate
+	dupBody, _ := json.Marshal(map[string]string{
+		"username": "newuser",
+		"email":    "other@example.com",
+		"password": "securepass123",
+	})
+	req = httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(dupBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 for duplicate username, got %d", resp.StatusCode)
+	}
+	var dupResult map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&dupResult); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if dupResult["error"] != "username already exists" || dupResult["code"] != "DUPLICATE_USERNAME" {
+		t.Errorf("Expected username already exists with code DUPLICATE_USERNAME, got %v", dupResult)
+	}
+
 	// Test login with registered credentials
 	loginBody := map[string]string{
 		"username_or_email": "newuser",
@@ -5,8 +5,10 @@ import (
 	"ai-zombie-defense/backend-api/internal/db/types"
 	"ai-zombie-defense/backend-api/internal/services/account"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	cryptorand "crypto/rand"
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -20,18 +22,22 @@ import (
 )
 
 type authService struct {
-	config  config.Config
-	logger  *zap.Logger
-	dbConn  db.DBTX
-	queries *db.Queries
+	config     config.Config
+	logger     *zap.Logger
+	dbConn     db.DBTX
+	queries    *db.Queries
+	adminCache *adminStatusCache
+	revocation *tokenRevocationList
 }
 
 func NewAuthService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX) Service {
 	return &authService{
-		config:  cfg,
-		logger:  logger,
-		dbConn:  dbConn,
-		queries: db.New(),
+		config:     cfg,
+		logger:     logger,
+		dbConn:     dbConn,
+		queries:    db.New(),
+		adminCache: newAdminStatusCache(cfg.Admin.StatusCacheTTL),
+		revocation: newTokenRevocationList(),
 	}
 }
 
@@ -42,29 +48,130 @@ func (s *authService) Authenticate(ctx context.Context, usernameOrEmail, passwor
 	// Try username first
 	player, err = s.queries.GetPlayerByUsername(ctx, s.dbConn, usernameOrEmail)
 	if err != nil {
-		s.logger.Debug("GetPlayerByUsername failed", zap.String("usernameOrEmail", usernameOrEmail), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Debug("GetPlayerByUsername failed", zap.String("usernameOrEmail", usernameOrEmail), zap.Error(err))
 		// Try email
-		player, err = s.queries.GetPlayerByEmail(ctx, s.dbConn, usernameOrEmail)
+		player, err = s.queries.GetPlayerByEmail(ctx, s.dbConn, account.NormalizeEmail(usernameOrEmail))
 		if err != nil {
-			s.logger.Debug("GetPlayerByEmail failed", zap.String("usernameOrEmail", usernameOrEmail), zap.Error(err))
+			logging.FromContext(ctx, s.logger).Debug("GetPlayerByEmail failed", zap.String("usernameOrEmail", usernameOrEmail), zap.Error(err))
 			return nil, ErrInvalidCredentials
 		}
 	}
 
 	// Check if player is banned
-	if player.IsBanned != 0 {
+	if isBanned(player) {
 		return nil, ErrPlayerBanned
 	}
 
+	// The lockout is only revealed to a caller who got the password right: a wrong
+	// password on a locked account fails like an unknown username, so 423 can't be
+	// used to find out which accounts exist, and it doesn't extend the lockout
+	hasFailures, locked := false, false
+	if s.config.Account.LoginLockoutThreshold > 0 {
+		failure, err := s.queries.GetLoginFailure(ctx, s.dbConn, player.PlayerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get login failures: %w", err)
+		}
+		hasFailures = err == nil
+		locked = hasFailures && failure.LockedUntil.Valid && failure.LockedUntil.Time.After(time.Now())
+	}
+
 	// Verify password
 	if !s.verifyPassword(player.PasswordHash, password) {
-		return nil, ErrInvalidCredentials
+		if locked {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, s.recordLoginFailure(ctx, player.PlayerID)
+	}
+	if locked {
+		return nil, ErrAccountLocked
+	}
+
+	if hasFailures {
+		if err := s.queries.ClearLoginFailures(ctx, s.dbConn, player.PlayerID); err != nil {
+			logging.FromContext(ctx, s.logger).Warn("failed to clear login failures", zap.Int64("player_id", player.PlayerID), zap.Error(err))
+		}
+	}
+	if player.IsBanned != 0 {
+		s.clearExpiredBan(ctx, player)
 	}
 
 	return player, nil
 }
 
+// recordLoginFailure counts a wrong password and locks the account once
+// Account.LoginLockoutThreshold consecutive failures are reached. It returns
+// the error for the failed attempt, which is ErrInvalidCredentials even when
+// the attempt locks the account.
+func (s *authService) recordLoginFailure(ctx context.Context, playerID int64) error {
+	threshold := s.config.Account.LoginLockoutThreshold
+	if threshold <= 0 {
+		return ErrInvalidCredentials
+	}
+	failure, err := s.queries.RecordLoginFailure(ctx, s.dbConn, playerID)
+	if err != nil {
+		return fmt.Errorf("failed to record login failure: %w", err)
+	}
+	if failure.FailedAttempts < int64(threshold) {
+		return ErrInvalidCredentials
+	}
+
+	duration := s.lockoutDuration(failure.Lockouts)
+	err = s.queries.LockAccount(ctx, s.dbConn, &db.LockAccountParams{
+		LockedUntil: types.NullTimestamp{Timestamp: types.Timestamp{Time: time.Now().Add(duration).UTC()}, Valid: true},
+		PlayerID:    playerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	logging.FromContext(ctx, s.logger).Warn("account locked after failed logins",
+		zap.Int64("player_id", playerID),
+		zap.Int64("previous_lockouts", failure.Lockouts),
+		zap.Duration("duration", duration))
+	return ErrInvalidCredentials
+}
+
+// lockoutDuration doubles Account.LoginLockoutDuration for every earlier
+// lockout since the last successful login, up to Account.LoginLockoutMaxDuration.
+func (s *authService) lockoutDuration(previousLockouts int64) time.Duration {
+	duration := s.config.Account.LoginLockoutDuration
+	maxDuration := s.config.Account.LoginLockoutMaxDuration
+	for i := int64(0); i < previousLockouts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	if maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+	return duration
+}
+
+// clearExpiredBan resets the ban columns of a player whose temporary ban has
+// ended. Failing to do so doesn't block the login, since the expiry is checked anyway.
+func (s *authService) clearExpiredBan(ctx context.Context, player *db.Player) {
+	cleared, err := s.queries.ClearExpiredBan(ctx, s.dbConn, player.PlayerID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warn("failed to clear expired ban", zap.Int64("player_id", player.PlayerID), zap.Error(err))
+		return
+	}
+	if cleared > 0 {
+		player.IsBanned = 0
+		player.BannedReason = nil
+		player.BannedUntil = types.NullTimestamp{}
+		logging.FromContext(ctx, s.logger).Info("expired ban cleared", zap.Int64("player_id", player.PlayerID))
+	}
+}
+
 func (s *authService) RegisterPlayer(ctx context.Context, username, email, password string) (*db.Player, error) {
+	if err := account.ValidateUsername(username); err != nil {
+		return nil, err
+	}
+	email = account.NormalizeEmail(email)
+	if err := account.ValidateEmail(email); err != nil {
+		return nil, err
+	}
+	if err := account.ValidatePassword(s.config.Account.PasswordPolicy, password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hash, err := s.hashPassword(password)
 	if err != nil {
@@ -98,7 +205,7 @@ func (s *authService) RegisterPlayer(ctx context.Context, username, email, passw
 	err = s.queries.CreatePlayerProgression(ctx, s.dbConn, player.PlayerID)
 	if err != nil {
 		// Log but continue - progression row may already exist or other issue
-		s.logger.Warn("Failed to create player progression row",
+		logging.FromContext(ctx, s.logger).Warn("Failed to create player progression row",
 			zap.Int64("player_id", player.PlayerID),
 			zap.Error(err))
 	}
@@ -106,31 +213,56 @@ func (s *authService) RegisterPlayer(ctx context.Context, username, email, passw
 	return player, nil
 }
 
-func (s *authService) GenerateAccessToken(playerID int64) (string, error) {
+func (s *authService) GenerateAccessToken(ctx context.Context, playerID int64) (string, error) {
+	player, err := s.queries.GetPlayer(ctx, s.dbConn, playerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get player: %w", err)
+	}
+	isAdmin := player.IsAdmin == 1 && !isBanned(player)
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 	exp := time.Now().Add(s.config.JWT.AccessExpiration)
-	claims := jwt.RegisteredClaims{
-		Subject:   fmt.Sprintf("%d", playerID),
-		ExpiresAt: jwt.NewNumericDate(exp),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", playerID),
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+		},
+		IsAdmin:  &isAdmin,
+		Username: player.Username,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWT.Secret))
+	signed, err := token.SignedString([]byte(s.config.JWT.Secret))
+	if err != nil {
+		return "", err
+	}
+	s.revocation.issue(playerID, jti, exp)
+	return signed, nil
 }
 
 func (s *authService) CreateSession(ctx context.Context, playerID int64, ipAddress, userAgent string) (string, error) {
+	now := time.Now()
+	return s.createSession(ctx, playerID, ipAddress, userAgent, now.Add(s.config.JWT.RefreshExpiration), now)
+}
+
+// createSession stores a new refresh token expiring at expiresAt for a login made at startedAt.
+func (s *authService) createSession(ctx context.Context, playerID int64, ipAddress, userAgent string, expiresAt, startedAt time.Time) (string, error) {
 	refreshToken, err := s.generateRefreshToken(playerID)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
-	s.logger.Debug("CreateSession generating token", zap.String("token", refreshToken), zap.Int64("playerID", playerID))
+	logging.FromContext(ctx, s.logger).Debug("CreateSession generating token", zap.String("token", refreshToken), zap.Int64("playerID", playerID))
 
-	expiresAt := time.Now().Add(s.config.JWT.RefreshExpiration)
 	params := &db.CreateSessionParams{
 		PlayerID:  playerID,
 		Token:     refreshToken,
 		ExpiresAt: types.Timestamp{Time: expiresAt},
 		IpAddress: &ipAddress,
 		UserAgent: &userAgent,
+		StartedAt: types.NullTimestamp{Timestamp: types.Timestamp{Time: startedAt}, Valid: true},
 	}
 	if ipAddress == "" {
 		params.IpAddress = nil
@@ -141,44 +273,96 @@ func (s *authService) CreateSession(ctx context.Context, playerID int64, ipAddre
 
 	err = s.queries.CreateSession(ctx, s.dbConn, params)
 	if err != nil {
-		s.logger.Error("CreateSession query failed", zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("CreateSession query failed", zap.Error(err))
 		return "", fmt.Errorf("failed to create session: %w", err)
 	}
-	s.logger.Debug("CreateSession inserted", zap.String("token", refreshToken))
+	logging.FromContext(ctx, s.logger).Debug("CreateSession inserted", zap.String("token", refreshToken))
 	return refreshToken, nil
 }
 
-func (s *authService) RefreshSession(ctx context.Context, oldToken, ipAddress, userAgent string) (int64, string, error) {
-	playerID, err := s.validateRefreshToken(ctx, oldToken)
+func (s *authService) RefreshSession(ctx context.Context, oldToken, ipAddress, userAgent string) (*RefreshedSession, error) {
+	session, err := s.validateRefreshToken(ctx, oldToken)
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
 
 	// Delete old session
 	err = s.DeleteSession(ctx, oldToken)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to delete old session: %w", err)
+		return nil, fmt.Errorf("failed to delete old session: %w", err)
 	}
 
-	// Create new session
-	newToken, err := s.CreateSession(ctx, playerID, ipAddress, userAgent)
+	// Create new session, unless the session's lifetime is used up
+	startedAt := session.CreatedAt.Time
+	if session.StartedAt.Valid {
+		startedAt = session.StartedAt.Time
+	}
+	expiresAt, deadline := s.refreshedExpiry(session, startedAt)
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	newToken, err := s.createSession(ctx, session.PlayerID, ipAddress, userAgent, expiresAt, startedAt)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to create new session: %w", err)
+		return nil, fmt.Errorf("failed to create new session: %w", err)
 	}
-	return playerID, newToken, nil
+	return &RefreshedSession{
+		PlayerID:     session.PlayerID,
+		RefreshToken: newToken,
+		ExpiresAt:    expiresAt,
+		Deadline:     deadline,
+	}, nil
+}
+
+// refreshedExpiry returns the expiry for the session replacing session on refresh
+// and the session's absolute deadline (zero for none). By default the login's
+// expiry is kept, so it is also the deadline; with sliding expiration it restarts
+// from now, capped at RefreshMaxLifetime after the original login.
+func (s *authService) refreshedExpiry(session *db.Session, startedAt time.Time) (expiresAt, deadline time.Time) {
+	if !s.config.JWT.RefreshSliding {
+		return session.ExpiresAt.Time, session.ExpiresAt.Time
+	}
+	expiresAt = time.Now().Add(s.config.JWT.RefreshExpiration)
+	if maxLifetime := s.config.JWT.RefreshMaxLifetime; maxLifetime > 0 {
+		deadline = startedAt.Add(maxLifetime)
+		if expiresAt.After(deadline) {
+			expiresAt = deadline
+		}
+	}
+	return expiresAt, deadline
 }
 
 func (s *authService) DeleteSession(ctx context.Context, token string) error {
-	s.logger.Debug("deleting session", zap.String("token", token))
+	logging.FromContext(ctx, s.logger).Debug("deleting session", zap.String("token", token))
 	err := s.queries.DeleteSession(ctx, s.dbConn, token)
 	if err != nil {
-		s.logger.Error("DeleteSession query failed", zap.Error(err), zap.String("token", token))
+		logging.FromContext(ctx, s.logger).Error("DeleteSession query failed", zap.Error(err), zap.String("token", token))
 	}
 	return err
 }
 
-func (s *authService) ValidateToken(tokenString string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+func (s *authService) DeleteAllSessionsForPlayer(ctx context.Context, playerID int64) (int64, error) {
+	deleted, err := s.queries.DeleteAllSessionsForPlayer(ctx, s.dbConn, playerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	revoked := s.RevokeAccessTokens(playerID)
+	logging.FromContext(ctx, s.logger).Info("all sessions revoked",
+		zap.Int64("player_id", playerID),
+		zap.Int64("count", deleted),
+		zap.Int("revoked_access_tokens", revoked))
+	return deleted, nil
+}
+
+func (s *authService) RevokeAccessTokens(playerID int64) int {
+	return s.revocation.revokePlayer(playerID)
+}
+
+func (s *authService) IsTokenRevoked(claims *Claims) bool {
+	return claims.ID != "" && s.revocation.isRevoked(claims.ID)
+}
+
+func (s *authService) ValidateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
@@ -187,7 +371,7 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.RegisteredClaims,
 	if err != nil {
 		return nil, err
 	}
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		return claims, nil
 	}
 	return nil, errors.New("invalid token")
@@ -195,6 +379,12 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.RegisteredClaims,
 
 // Internal helpers
 
+// isBanned reports whether a ban is in force: a NULL banned_until is permanent,
+// otherwise the ban lifts once banned_until has passed.
+func isBanned(player *db.Player) bool {
+	return player.IsBanned != 0 && (!player.BannedUntil.Valid || player.BannedUntil.Time.After(time.Now()))
+}
+
 func (s *authService) hashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -218,11 +408,10 @@ func (s *authService) isDuplicateError(err error, column string) bool {
 
 func (s *authService) generateRefreshToken(playerID int64) (string, error) {
 	exp := time.Now().Add(s.config.JWT.RefreshExpiration)
-	randBytes := make([]byte, 16)
-	if _, err := cryptorand.Read(randBytes); err != nil {
-		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
-	jti := hex.EncodeToString(randBytes)
 	claims := jwt.RegisteredClaims{
 		Subject:   fmt.Sprintf("%d", playerID),
 		ExpiresAt: jwt.NewNumericDate(exp),
@@ -233,34 +422,53 @@ func (s *authService) generateRefreshToken(playerID int64) (string, error) {
 	return token.SignedString([]byte(s.config.JWT.Secret))
 }
 
-func (s *authService) validateRefreshToken(ctx context.Context, token string) (int64, error) {
+// newTokenID returns a random JWT ID (jti).
+func newTokenID() (string, error) {
+	randBytes := make([]byte, 16)
+	if _, err := cryptorand.Read(randBytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(randBytes), nil
+}
+
+func (s *authService) validateRefreshToken(ctx context.Context, token string) (*db.Session, error) {
 	claims, err := s.ValidateToken(token)
 	if err != nil {
-		return 0, ErrInvalidRefreshToken
+		return nil, ErrInvalidRefreshToken
 	}
 	playerID, err := strconv.ParseInt(claims.Subject, 10, 64)
 	if err != nil {
-		return 0, ErrInvalidRefreshToken
+		return nil, ErrInvalidRefreshToken
 	}
 
 	session, err := s.queries.GetSessionByToken(ctx, s.dbConn, token)
 	if err != nil {
-		return 0, ErrSessionNotFound
+		return nil, ErrSessionNotFound
 	}
 	if session.ExpiresAt.Time.Before(time.Now()) {
 		_ = s.queries.DeleteSession(ctx, s.dbConn, token)
-		return 0, ErrInvalidRefreshToken
+		return nil, ErrInvalidRefreshToken
 	}
 	if session.PlayerID != playerID {
-		return 0, ErrInvalidRefreshToken
+		return nil, ErrInvalidRefreshToken
 	}
-	return playerID, nil
+	return session, nil
 }
 
 func (s *authService) IsAdmin(ctx context.Context, playerID int64) (bool, error) {
+	if isAdmin, ok := s.adminCache.get(playerID); ok {
+		return isAdmin, nil
+	}
 	player, err := s.queries.GetPlayer(ctx, s.dbConn, playerID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check admin status: %w", err)
 	}
-	return player.IsAdmin == 1, nil
+	// A banned admin keeps is_admin but loses admin access while the ban lasts
+	isAdmin := player.IsAdmin == 1 && !isBanned(player)
+	s.adminCache.set(playerID, isAdmin)
+	return isAdmin, nil
+}
+
+func (s *authService) InvalidateAdminStatus(playerID int64) {
+	s.adminCache.invalidate(playerID)
 }
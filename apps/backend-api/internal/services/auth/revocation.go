@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// revocationSweepInterval is how often issue drops expired entries of every
+// player, so players who never come back don't leave their tokens behind.
+const revocationSweepInterval = time.Minute
+
+// tokenRevocationList tracks the access tokens this process issued, by jti, so
+// they can be revoked before they expire. Entries are kept only until the token
+// would have expired anyway.
+type tokenRevocationList struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	issued    map[int64]map[string]time.Time
+	revoked   map[string]time.Time
+	nextSweep time.Time
+}
+
+func newTokenRevocationList() *tokenRevocationList {
+	return &tokenRevocationList{
+		now:     time.Now,
+		issued:  make(map[int64]map[string]time.Time),
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// issue records an access token of the player expiring at expiresAt.
+func (l *tokenRevocationList) issue(playerID int64, jti string, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tokens, ok := l.issued[playerID]
+	if !ok {
+		tokens = make(map[string]time.Time)
+		l.issued[playerID] = tokens
+	}
+	now := l.now()
+	for id, exp := range tokens {
+		if !now.Before(exp) {
+			delete(tokens, id)
+		}
+	}
+	tokens[jti] = expiresAt
+
+	if !now.Before(l.nextSweep) {
+		l.sweep(now)
+		l.nextSweep = now.Add(revocationSweepInterval)
+	}
+}
+
+// sweep drops every expired issued token and revocation. The caller holds mu.
+func (l *tokenRevocationList) sweep(now time.Time) {
+	for playerID, tokens := range l.issued {
+		for id, exp := range tokens {
+			if !now.Before(exp) {
+				delete(tokens, id)
+			}
+		}
+		if len(tokens) == 0 {
+			delete(l.issued, playerID)
+		}
+	}
+	for id, exp := range l.revoked {
+		if !now.Before(exp) {
+			delete(l.revoked, id)
+		}
+	}
+}
+
+// revokePlayer revokes every unexpired access token issued to the player and
+// returns how many were revoked.
+func (l *tokenRevocationList) revokePlayer(playerID int64) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for id, exp := range l.revoked {
+		if !now.Before(exp) {
+			delete(l.revoked, id)
+		}
+	}
+	revoked := 0
+	for id, exp := range l.issued[playerID] {
+		if now.Before(exp) {
+			l.revoked[id] = exp
+			revoked++
+		}
+	}
+	delete(l.issued, playerID)
+	return revoked
+}
+
+func (l *tokenRevocationList) isRevoked(jti string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	exp, ok := l.revoked[jti]
+	if !ok {
+		return false
+	}
+	if !l.now().Before(exp) {
+		delete(l.revoked, jti)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenRevocationList(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	list := newTokenRevocationList()
+	list.now = func() time.Time { return now }
+
+	list.issue(1, "a", now.Add(15*time.Minute))
+	list.issue(1, "b", now.Add(5*time.Minute))
+	list.issue(2, "c", now.Add(15*time.Minute))
+	if list.isRevoked("a") {
+		t.Fatal("Expected an issued token not to be revoked")
+	}
+
+	if revoked := list.revokePlayer(1); revoked != 2 {
+		t.Errorf("Expected 2 revoked tokens, got %d", revoked)
+	}
+	if !list.isRevoked("a") || !list.isRevoked("b") {
+		t.Error("Expected both tokens of player 1 to be revoked")
+	}
+	if list.isRevoked("c") {
+		t.Error("Expected the token of player 2 to stay valid")
+	}
+
+	// Tokens issued after the revocation are not affected
+	list.issue(1, "d", now.Add(15*time.Minute))
+	if list.isRevoked("d") {
+		t.Error("Expected a token issued after the revocation to stay valid")
+	}
+
+	// Revocations are dropped once the token has expired
+	now = now.Add(5 * time.Minute)
+	if list.isRevoked("b") {
+		t.Error("Expected the revocation to be dropped at the token's expiry")
+	}
+	if !list.isRevoked("a") {
+		t.Error("Expected the unexpired token to stay revoked")
+	}
+
+	// Expired tokens are not counted
+	if revoked := list.revokePlayer(2); revoked != 1 {
+		t.Errorf("Expected 1 revoked token, got %d", revoked)
+	}
+	now = now.Add(10 * time.Minute)
+	if revoked := list.revokePlayer(1); revoked != 0 {
+		t.Errorf("Expected expired tokens not to be revoked, got %d", revoked)
+	}
+}
+
+func TestTokenRevocationListSweepsOtherPlayers(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	list := newTokenRevocationList()
+	list.now = func() time.Time { return now }
+
+	list.issue(1, "a", now.Add(5*time.Minute))
+	list.issue(2, "b", now.Add(30*time.Minute))
+	list.issue(3, "c", now.Add(5*time.Minute))
+	list.revokePlayer(3)
+
+	// Player 1 never comes back; another player's login sweeps their expired token
+	now = now.Add(10 * time.Minute)
+	list.issue(2, "d", now.Add(15*time.Minute))
+	if _, ok := list.issued[1]; ok {
+		t.Error("Expected the expired token of player 1 to be dropped")
+	}
+	if _, ok := list.revoked["c"]; ok {
+		t.Error("Expected the expired revocation to be dropped")
+	}
+	if tokens := list.issued[2]; len(tokens) != 2 {
+		t.Errorf("Expected both unexpired tokens of player 2 to be kept, got %v", tokens)
+	}
+
+	// Sweeps are spaced out; an expiry inside the interval waits for the next one
+	list.issue(4, "e", now.Add(time.Second))
+	now = now.Add(2 * time.Second)
+	list.issue(2, "f", now.Add(15*time.Minute))
+	if _, ok := list.issued[4]; !ok {
+		t.Error("Expected no sweep before the interval has passed")
+	}
+	now = now.Add(revocationSweepInterval)
+	list.issue(2, "g", now.Add(15*time.Minute))
+	if _, ok := list.issued[4]; ok {
+		t.Error("Expected the next sweep to drop the expired token of player 4")
+	}
+}
@@ -4,23 +4,72 @@ import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"context"
 	"errors"
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
 	ErrInvalidCredentials  = errors.New("invalid credentials")
 	ErrPlayerBanned        = errors.New("player is banned")
+	ErrAccountLocked       = errors.New("account is temporarily locked")
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
 	ErrSessionNotFound     = errors.New("session not found")
 )
 
+// Claims are the claims of access and refresh tokens. IsAdmin is only set on
+// access tokens, captured when the token is issued; it is nil in tokens issued
+// before the claim was added.
+type Claims struct {
+	jwt.RegisteredClaims
+	IsAdmin *bool `json:"is_admin,omitempty"`
+	// Username is the player's username when the token was issued.
+	Username string `json:"username,omitempty"`
+}
+
+// RefreshedSession is the session that replaces a refreshed one.
+type RefreshedSession struct {
+	PlayerID     int64
+	RefreshToken string
+	// ExpiresAt is when the new refresh token expires.
+	ExpiresAt time.Time
+	// Deadline is when the session ends however often it is refreshed, after
+	// which the player has to log in again; zero when there is no limit.
+	Deadline time.Time
+}
+
 type Service interface {
+	// Authenticate checks the credentials. After Account.LoginLockoutThreshold
+	// consecutive wrong passwords the account is locked until the lockout ends:
+	// the right password then fails with ErrAccountLocked, a wrong one with
+	// ErrInvalidCredentials as for an unknown account. A successful login resets the count.
 	Authenticate(ctx context.Context, usernameOrEmail, password string) (*db.Player, error)
+	// RegisterPlayer validates the username, email and password and stores the
+	// email lowercased, so emails differing only in case are duplicates.
 	RegisterPlayer(ctx context.Context, username, email, password string) (*db.Player, error)
-	GenerateAccessToken(playerID int64) (string, error)
+	// GenerateAccessToken issues an access token carrying the player's current
+	// admin status, which stays fixed until the token expires.
+	GenerateAccessToken(ctx context.Context, playerID int64) (string, error)
 	CreateSession(ctx context.Context, playerID int64, ipAddress, userAgent string) (string, error)
-	RefreshSession(ctx context.Context, oldToken, ipAddress, userAgent string) (int64, string, error)
+	// RefreshSession rotates the refresh token. With JWT.RefreshSliding the new
+	// token's expiry restarts from now, up to JWT.RefreshMaxLifetime after login.
+	RefreshSession(ctx context.Context, oldToken, ipAddress, userAgent string) (*RefreshedSession, error)
 	DeleteSession(ctx context.Context, token string) error
-	ValidateToken(tokenString string) (*jwt.RegisteredClaims, error)
+	// DeleteAllSessionsForPlayer revokes every refresh and access token of the
+	// player and returns how many sessions were removed.
+	DeleteAllSessionsForPlayer(ctx context.Context, playerID int64) (int64, error)
+	ValidateToken(tokenString string) (*Claims, error)
+	// RevokeAccessTokens revokes the player's unexpired access tokens before
+	// their expiry and returns how many were revoked. Only tokens issued by this
+	// process since it started are known.
+	RevokeAccessTokens(playerID int64) int
+	// IsTokenRevoked reports whether the access token was revoked. Tokens without
+	// a jti claim can't be revoked.
+	IsTokenRevoked(claims *Claims) bool
+	// IsAdmin reports whether the player has admin access: is_admin set and not
+	// currently banned. Results are cached for Admin.StatusCacheTTL.
 	IsAdmin(ctx context.Context, playerID int64) (bool, error)
+	// InvalidateAdminStatus drops the cached IsAdmin result, so a role change or
+	// ban applies on the player's next request.
+	InvalidateAdminStatus(playerID int64)
 }
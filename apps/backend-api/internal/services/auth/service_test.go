@@ -3,7 +3,11 @@ package auth_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"ai-zombie-defense/backend-api/internal/services/account"
 	"ai-zombie-defense/backend-api/internal/services/auth"
@@ -57,11 +61,23 @@ func setupTestDB(t *testing.T) *sql.DB {
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
     ip_address TEXT,
     user_agent TEXT,
+    started_at TEXT,
     FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
 );`
 	if _, err := db.Exec(createSessionsSQL); err != nil {
 		t.Fatalf("Failed to create sessions table: %v", err)
 	}
+	createLoginFailuresSQL := `CREATE TABLE login_failures (
+    player_id INTEGER PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until TEXT,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);`
+	if _, err := db.Exec(createLoginFailuresSQL); err != nil {
+		t.Fatalf("Failed to create login_failures table: %v", err)
+	}
 	createProgressionSQL := `CREATE TABLE player_progression (
     player_id INTEGER PRIMARY KEY,
     level INTEGER NOT NULL DEFAULT 1,
@@ -139,6 +155,213 @@ func TestAuthService_Authenticate(t *testing.T) {
 	}
 }
 
+func TestAuthService_AuthenticateBanned(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	service := auth.NewAuthService(newTestConfig(), logger, dbConn)
+	ctx := context.Background()
+	player, err := service.RegisterPlayer(ctx, "banned", "banned@example.com", "securepassword123")
+	if err != nil {
+		t.Fatalf("Failed to register player: %v", err)
+	}
+	ban := func(until interface{}) {
+		t.Helper()
+		if _, err := dbConn.Exec(`UPDATE players SET is_banned = 1, banned_reason = 'cheating', banned_until = ? WHERE player_id = ?`, until, player.PlayerID); err != nil {
+			t.Fatalf("Failed to ban player: %v", err)
+		}
+	}
+
+	// A NULL banned_until is permanent
+	ban(nil)
+	if _, err := service.Authenticate(ctx, "banned", "securepassword123"); err != auth.ErrPlayerBanned {
+		t.Errorf("Expected ErrPlayerBanned for permanent ban, got %v", err)
+	}
+
+	// A temporary ban blocks login until it ends
+	ban(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	if _, err := service.Authenticate(ctx, "banned", "securepassword123"); err != auth.ErrPlayerBanned {
+		t.Errorf("Expected ErrPlayerBanned for active temp ban, got %v", err)
+	}
+
+	// An expired temporary ban lets the player in and is cleared from the row
+	ban(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	authed, err := service.Authenticate(ctx, "banned", "securepassword123")
+	if err != nil {
+		t.Fatalf("Expected login after temp ban expired, got %v", err)
+	}
+	if authed.IsBanned != 0 || authed.BannedUntil.Valid {
+		t.Errorf("Expected returned player unbanned, got is_banned %d until %v", authed.IsBanned, authed.BannedUntil)
+	}
+	var isBanned int64
+	var reason, bannedUntil sql.NullString
+	if err := dbConn.QueryRow(`SELECT is_banned, banned_reason, banned_until FROM players WHERE player_id = ?`, player.PlayerID).Scan(&isBanned, &reason, &bannedUntil); err != nil {
+		t.Fatalf("Failed to read ban: %v", err)
+	}
+	if isBanned != 0 || reason.Valid || bannedUntil.Valid {
+		t.Errorf("Expected expired ban cleared, got is_banned %d reason %v until %v", isBanned, reason, bannedUntil)
+	}
+
+	// A wrong password doesn't clear an expired ban
+	ban(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	if _, err := service.Authenticate(ctx, "banned", "wrongpassword"); err != auth.ErrInvalidCredentials {
+		t.Errorf("Expected ErrInvalidCredentials for wrong password, got %v", err)
+	}
+	if err := dbConn.QueryRow(`SELECT is_banned FROM players WHERE player_id = ?`, player.PlayerID).Scan(&isBanned); err != nil {
+		t.Fatalf("Failed to read ban: %v", err)
+	}
+	if isBanned != 1 {
+		t.Errorf("Expected ban flag kept after failed login, got %d", isBanned)
+	}
+}
+
+func TestAuthService_AuthenticateLockout(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	cfg := newTestConfig()
+	cfg.Account.LoginLockoutThreshold = 3
+	cfg.Account.LoginLockoutDuration = 5 * time.Minute
+	cfg.Account.LoginLockoutMaxDuration = 8 * time.Minute
+	service := auth.NewAuthService(cfg, logger, dbConn)
+	ctx := context.Background()
+	player, err := service.RegisterPlayer(ctx, "locked", "locked@example.com", "securepassword123")
+	if err != nil {
+		t.Fatalf("Failed to register player: %v", err)
+	}
+	fail := func(times int) error {
+		t.Helper()
+		var err error
+		for i := 0; i < times; i++ {
+			_, err = service.Authenticate(ctx, "locked", "wrongpassword")
+		}
+		return err
+	}
+	lockedFor := func() time.Duration {
+		t.Helper()
+		var lockedUntil string
+		if err := dbConn.QueryRow(`SELECT locked_until FROM login_failures WHERE player_id = ?`, player.PlayerID).Scan(&lockedUntil); err != nil {
+			t.Fatalf("Failed to read lockout: %v", err)
+		}
+		until, err := time.Parse(time.RFC3339, lockedUntil)
+		if err != nil {
+			t.Fatalf("Failed to parse locked_until %q: %v", lockedUntil, err)
+		}
+		return time.Until(until)
+	}
+	expire := func() {
+		t.Helper()
+		if _, err := dbConn.Exec(`UPDATE login_failures SET locked_until = ? WHERE player_id = ?`, time.Now().Add(-time.Second).UTC().Format(time.RFC3339), player.PlayerID); err != nil {
+			t.Fatalf("Failed to expire lockout: %v", err)
+		}
+	}
+
+	// Failures below the threshold are plain invalid credentials
+	if err := fail(2); err != auth.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials below threshold, got %v", err)
+	}
+
+	// The failure that reaches the threshold locks the account without saying so
+	if err := fail(1); err != auth.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials at threshold, got %v", err)
+	}
+	if d := lockedFor(); d <= 4*time.Minute || d > 5*time.Minute {
+		t.Errorf("Expected first lockout of about 5m, got %v", d)
+	}
+
+	// While locked a wrong password fails like an unknown account, so the
+	// lockout can't be used to enumerate accounts, and doesn't extend it
+	before := lockedFor()
+	if err := fail(1); err != auth.ErrInvalidCredentials {
+		t.Errorf("Expected ErrInvalidCredentials with wrong password while locked, got %v", err)
+	}
+	if _, err := service.Authenticate(ctx, "nobody", "wrongpassword"); err != auth.ErrInvalidCredentials {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown account, got %v", err)
+	}
+	if d := lockedFor(); d > before {
+		t.Errorf("Expected a failure while locked not to extend the lockout, got %v after %v", d, before)
+	}
+
+	// Only the right password learns about the lockout, and is still rejected
+	if _, err := service.Authenticate(ctx, "locked", "securepassword123"); err != auth.ErrAccountLocked {
+		t.Errorf("Expected ErrAccountLocked with correct password while locked, got %v", err)
+	}
+
+	// Repeated lockouts double the duration but never beyond the cap
+	expire()
+	if err := fail(3); err != auth.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials on second lockout, got %v", err)
+	}
+	if d := lockedFor(); d <= 7*time.Minute || d > 8*time.Minute {
+		t.Errorf("Expected second lockout capped at 8m, got %v", d)
+	}
+
+	// Once the cooldown expires the right password works and resets the count
+	expire()
+	if _, err := service.Authenticate(ctx, "locked", "securepassword123"); err != nil {
+		t.Fatalf("Expected login after lockout expired, got %v", err)
+	}
+	var count int
+	if err := dbConn.QueryRow(`SELECT COUNT(*) FROM login_failures WHERE player_id = ?`, player.PlayerID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count login failures: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected login failures cleared after successful login, got %d rows", count)
+	}
+	if err := fail(2); err != auth.ErrInvalidCredentials {
+		t.Errorf("Expected count to restart after successful login, got %v", err)
+	}
+}
+
+func TestAuthService_IsAdminCache(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	cfg := newTestConfig()
+	cfg.Admin.StatusCacheTTL = time.Minute
+	service := auth.NewAuthService(cfg, logger, dbConn)
+	ctx := context.Background()
+	player, err := service.RegisterPlayer(ctx, "moderator", "moderator@example.com", "securepassword123")
+	if err != nil {
+		t.Fatalf("Failed to register player: %v", err)
+	}
+	isAdmin := func() bool {
+		t.Helper()
+		ok, err := service.IsAdmin(ctx, player.PlayerID)
+		if err != nil {
+			t.Fatalf("IsAdmin failed: %v", err)
+		}
+		return ok
+	}
+
+	if isAdmin() {
+		t.Fatal("Expected a new player not to be admin")
+	}
+	if _, err := dbConn.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, player.PlayerID); err != nil {
+		t.Fatalf("Failed to promote player: %v", err)
+	}
+	// The cached answer is served until it is invalidated
+	if isAdmin() {
+		t.Error("Expected the cached non-admin status within the TTL")
+	}
+	service.InvalidateAdminStatus(player.PlayerID)
+	if !isAdmin() {
+		t.Error("Expected admin status after invalidation")
+	}
+
+	// A banned admin loses admin access
+	if _, err := dbConn.Exec(`UPDATE players SET is_banned = 1 WHERE player_id = ?`, player.PlayerID); err != nil {
+		t.Fatalf("Failed to ban player: %v", err)
+	}
+	service.InvalidateAdminStatus(player.PlayerID)
+	if isAdmin() {
+		t.Error("Expected a banned admin to lose admin access")
+	}
+}
+
 func TestAuthService_GenerateToken(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	dbConn := setupTestDB(t)
@@ -147,8 +370,12 @@ func TestAuthService_GenerateToken(t *testing.T) {
 	cfg := newTestConfig()
 	service := auth.NewAuthService(cfg, logger, dbConn)
 
-	playerID := int64(42)
-	token, err := service.GenerateAccessToken(playerID)
+	ctx := context.Background()
+	player, err := service.RegisterPlayer(ctx, "tokenuser", "token@example.com", "securepassword123")
+	if err != nil {
+		t.Fatalf("Failed to register player: %v", err)
+	}
+	token, err := service.GenerateAccessToken(ctx, player.PlayerID)
 	if err != nil {
 		t.Fatalf("Failed to generate access token: %v", err)
 	}
@@ -159,10 +386,86 @@ func TestAuthService_GenerateToken(t *testing.T) {
 	// Validate token
 	claims, err := service.ValidateToken(token)
 	if err != nil {
-		t.Errorf("Failed to validate token: %v", err)
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.Subject != strconv.FormatInt(player.PlayerID, 10) {
+		t.Errorf("Expected subject %d, got %s", player.PlayerID, claims.Subject)
 	}
-	if claims.Subject != "42" {
-		t.Errorf("Expected subject '42', got %s", claims.Subject)
+	if claims.IsAdmin == nil || *claims.IsAdmin {
+		t.Errorf("Expected is_admin false for a regular player, got %v", claims.IsAdmin)
+	}
+
+	// Admin status is captured when the token is issued
+	if _, err := dbConn.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, player.PlayerID); err != nil {
+		t.Fatalf("Failed to promote player: %v", err)
+	}
+	token, err = service.GenerateAccessToken(ctx, player.PlayerID)
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	claims, err = service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.IsAdmin == nil || !*claims.IsAdmin {
+		t.Errorf("Expected is_admin true for an admin, got %v", claims.IsAdmin)
+	}
+
+	if _, err := service.GenerateAccessToken(ctx, 9999); err == nil {
+		t.Error("Expected an error issuing a token for an unknown player")
+	}
+}
+
+func TestAuthService_RevokeAccessTokens(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	cfg := newTestConfig()
+	service := auth.NewAuthService(cfg, logger, dbConn)
+
+	ctx := context.Background()
+	player, err := service.RegisterPlayer(ctx, "revokeuser", "revoke@example.com", "securepassword123")
+	if err != nil {
+		t.Fatalf("Failed to register player: %v", err)
+	}
+	token, err := service.GenerateAccessToken(ctx, player.PlayerID)
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.ID == "" {
+		t.Fatal("Expected the access token to carry a jti")
+	}
+	if service.IsTokenRevoked(claims) {
+		t.Fatal("Expected a fresh token not to be revoked")
+	}
+
+	// Logging out everywhere revokes the access token before it expires
+	if _, err := service.DeleteAllSessionsForPlayer(ctx, player.PlayerID); err != nil {
+		t.Fatalf("DeleteAllSessionsForPlayer failed: %v", err)
+	}
+	if _, err := service.ValidateToken(token); err != nil {
+		t.Fatalf("Expected the token to still be unexpired: %v", err)
+	}
+	if !service.IsTokenRevoked(claims) {
+		t.Error("Expected the access token to be revoked after logout-all")
+	}
+
+	// A token issued afterwards is valid
+	token, err = service.GenerateAccessToken(ctx, player.PlayerID)
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	claims, err = service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if service.IsTokenRevoked(claims) {
+		t.Error("Expected a token issued after the revocation to be valid")
 	}
 }
 
@@ -211,6 +514,82 @@ func TestAuthService_RegisterPlayer(t *testing.T) {
 			t.Errorf("Expected account.ErrDuplicateEmail, got %v", err)
 		}
 	})
+
+	t.Run("email differing in case", func(t *testing.T) {
+		player, err := service.RegisterPlayer(ctx, "user4", " Mixed.Case@Example.com ", "pass")
+		if err != nil {
+			t.Fatalf("Registration failed: %v", err)
+		}
+		if player.Email != "mixed.case@example.com" {
+			t.Errorf("Expected the email to be stored lowercased, got %s", player.Email)
+		}
+		_, err = service.RegisterPlayer(ctx, "user5", "mixed.case@example.com", "pass")
+		if err != account.ErrDuplicateEmail {
+			t.Errorf("Expected account.ErrDuplicateEmail, got %v", err)
+		}
+		if _, err := service.Authenticate(ctx, "MIXED.CASE@example.com", "pass"); err != nil {
+			t.Errorf("Expected login by email to ignore case, got %v", err)
+		}
+	})
+
+	t.Run("invalid username", func(t *testing.T) {
+		for _, username := range []string{"a", "has space", "semi;colon", "ünicode", strings.Repeat("x", 33)} {
+			if _, err := service.RegisterPlayer(ctx, username, "invalid@example.com", "pass"); err != account.ErrInvalidUsername {
+				t.Errorf("Expected account.ErrInvalidUsername for %q, got %v", username, err)
+			}
+		}
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		for _, email := range []string{"no-at-sign", "user@localhost", "Name <user@example.com>", "user@example.com."} {
+			if _, err := service.RegisterPlayer(ctx, "user6", email, "pass"); err != account.ErrInvalidEmail {
+				t.Errorf("Expected account.ErrInvalidEmail for %q, got %v", email, err)
+			}
+		}
+	})
+}
+
+func TestAuthService_RegisterPlayerPasswordPolicy(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	cfg := newTestConfig()
+	cfg.Account.PasswordPolicy = config.PasswordPolicy{MinLength: 10, RequireLetter: true, RequireDigit: true}
+	service := auth.NewAuthService(cfg, logger, dbConn)
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{"too short", "abc123", "password is too weak: must be at least 10 characters long"},
+		{"missing digit", "onlyletters", "password is too weak: must contain a digit"},
+		{"missing letter", "1234567890", "password is too weak: must contain a letter"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RegisterPlayer(ctx, "weak"+strconv.Itoa(i), "weak"+strconv.Itoa(i)+"@example.com", tt.password)
+			if !errors.Is(err, account.ErrWeakPassword) {
+				t.Fatalf("Expected account.ErrWeakPassword, got %v", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+
+	if _, err := service.RegisterPlayer(ctx, "strong", "strong@example.com", "letters4digits"); err != nil {
+		t.Errorf("Expected a password meeting the policy to be accepted, got %v", err)
+	}
+	var count int
+	if err := dbConn.QueryRow(`SELECT COUNT(*) FROM players`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count players: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected only the strong password to create a player, got %d players", count)
+	}
 }
 
 func TestAuthService_Sessions(t *testing.T) {
@@ -243,16 +622,20 @@ func TestAuthService_Sessions(t *testing.T) {
 	}
 
 	// Refresh session
-	newPlayerID, newToken, err := service.RefreshSession(ctx, token, ip, ua)
+	refreshed, err := service.RefreshSession(ctx, token, ip, ua)
 	if err != nil {
 		t.Fatalf("RefreshSession failed: %v", err)
 	}
-	if newPlayerID != playerID {
-		t.Errorf("Expected player ID %d, got %d", playerID, newPlayerID)
+	if refreshed.PlayerID != playerID {
+		t.Errorf("Expected player ID %d, got %d", playerID, refreshed.PlayerID)
 	}
+	newToken := refreshed.RefreshToken
 	if newToken == "" {
 		t.Fatal("New token is empty")
 	}
+	if newToken == token {
+		t.Error("Expected refresh to issue a different token")
+	}
 
 	// Delete session
 	err = service.DeleteSession(ctx, newToken)
@@ -270,3 +653,111 @@ func TestAuthService_Sessions(t *testing.T) {
 		t.Errorf("Expected 0 sessions, got %d", count)
 	}
 }
+
+func TestAuthService_RefreshSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	const day = 24 * time.Hour
+
+	// refreshAfter refreshes a session whose login was loginAgo and returns the
+	// refreshed session after checking its expiry was stored; the old session
+	// is set to expire in an hour
+	refreshAfter := func(t *testing.T, cfg config.Config, loginAgo time.Duration) *auth.RefreshedSession {
+		t.Helper()
+		dbConn := setupTestDB(t)
+		defer dbConn.Close()
+		service := auth.NewAuthService(cfg, zaptest.NewLogger(t), dbConn)
+		if _, err := dbConn.Exec(`INSERT INTO players (player_id, username, email, password_hash) VALUES (1, 'testuser', 'test@example.com', 'hash')`); err != nil {
+			t.Fatalf("Failed to insert player: %v", err)
+		}
+		token, err := service.CreateSession(ctx, 1, "127.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		now := time.Now().UTC()
+		if _, err := dbConn.Exec(`UPDATE sessions SET started_at = ?, expires_at = ? WHERE token = ?`,
+			now.Add(-loginAgo).Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339), token); err != nil {
+			t.Fatalf("Failed to backdate session: %v", err)
+		}
+
+		refreshed, err := service.RefreshSession(ctx, token, "127.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatalf("RefreshSession failed: %v", err)
+		}
+		var expiresAt string
+		if err := dbConn.QueryRow(`SELECT expires_at FROM sessions WHERE token = ?`, refreshed.RefreshToken).Scan(&expiresAt); err != nil {
+			t.Fatalf("Failed to query new session: %v", err)
+		}
+		parsed, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			t.Fatalf("Failed to parse expiry %q: %v", expiresAt, err)
+		}
+		if !parsed.Equal(refreshed.ExpiresAt.Truncate(time.Second)) {
+			t.Errorf("Expected stored expiry %v to match returned %v", parsed, refreshed.ExpiresAt)
+		}
+		return refreshed
+	}
+	assertNear := func(t *testing.T, got, want time.Time) {
+		t.Helper()
+		if diff := got.Sub(want); diff > 5*time.Second || diff < -5*time.Second {
+			t.Errorf("Expected expiry near %v, got %v", want, got)
+		}
+	}
+
+	sliding := newTestConfig()
+	sliding.JWT.RefreshSliding = true
+	sliding.JWT.RefreshMaxLifetime = 10 * day
+
+	t.Run("fixed by default", func(t *testing.T) {
+		got := refreshAfter(t, newTestConfig(), 6*day)
+		assertNear(t, got.ExpiresAt, time.Now().Add(time.Hour))
+		assertNear(t, got.Deadline, got.ExpiresAt)
+	})
+
+	t.Run("sliding extends from now", func(t *testing.T) {
+		got := refreshAfter(t, sliding, day)
+		assertNear(t, got.ExpiresAt, time.Now().Add(7*day))
+		assertNear(t, got.Deadline, time.Now().Add(9*day))
+	})
+
+	t.Run("sliding capped at max lifetime", func(t *testing.T) {
+		got := refreshAfter(t, sliding, 8*day)
+		assertNear(t, got.ExpiresAt, time.Now().Add(2*day))
+		assertNear(t, got.Deadline, got.ExpiresAt)
+	})
+
+	t.Run("sliding just before max lifetime", func(t *testing.T) {
+		got := refreshAfter(t, sliding, 10*day-time.Minute)
+		assertNear(t, got.ExpiresAt, time.Now().Add(time.Minute))
+	})
+
+	t.Run("sliding without max lifetime has no deadline", func(t *testing.T) {
+		unlimited := sliding
+		unlimited.JWT.RefreshMaxLifetime = 0
+		got := refreshAfter(t, unlimited, 30*day)
+		assertNear(t, got.ExpiresAt, time.Now().Add(7*day))
+		if !got.Deadline.IsZero() {
+			t.Errorf("Expected no deadline, got %v", got.Deadline)
+		}
+	})
+
+	t.Run("sliding past max lifetime requires login", func(t *testing.T) {
+		dbConn := setupTestDB(t)
+		defer dbConn.Close()
+		service := auth.NewAuthService(sliding, zaptest.NewLogger(t), dbConn)
+		if _, err := dbConn.Exec(`INSERT INTO players (player_id, username, email, password_hash) VALUES (1, 'testuser', 'test@example.com', 'hash')`); err != nil {
+			t.Fatalf("Failed to insert player: %v", err)
+		}
+		token, err := service.CreateSession(ctx, 1, "127.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		now := time.Now().UTC()
+		if _, err := dbConn.Exec(`UPDATE sessions SET started_at = ? WHERE token = ?`,
+			now.Add(-10*day-time.Minute).Format(time.RFC3339), token); err != nil {
+			t.Fatalf("Failed to backdate session: %v", err)
+		}
+		if _, err := service.RefreshSession(ctx, token, "127.0.0.1", "test-agent"); err != auth.ErrInvalidRefreshToken {
+			t.Errorf("Expected ErrInvalidRefreshToken past max lifetime, got %v", err)
+		}
+	})
+}
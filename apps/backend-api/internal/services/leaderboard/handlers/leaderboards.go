@@ -1,7 +1,16 @@
 package handlers
 
 import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/leaderboard"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -20,36 +29,76 @@ func NewLeaderboardHandlers(service leaderboard.Service, logger *zap.Logger) *Le
 }
 
 type LeaderboardEntryResponse struct {
-	PlayerID         int64    `json:"player_id"`
-	Username         string   `json:"username"`
-	TotalScore       int64    `json:"total_score"`
-	MatchesPlayed    int64    `json:"matches_played"`
-	AvgKillsPerMatch *float64 `json:"avg_kills_per_match,omitempty"`
-	AvgWavesSurvived *float64 `json:"avg_waves_survived,omitempty"`
-	Ranking          int64    `json:"ranking"`
+	PlayerID         int64     `json:"player_id"`
+	Username         string    `json:"username"`
+	TotalScore       int64     `json:"total_score"`
+	MatchesPlayed    int64     `json:"matches_played"`
+	AvgKillsPerMatch *float64  `json:"avg_kills_per_match,omitempty"`
+	AvgWavesSurvived *float64  `json:"avg_waves_survived,omitempty"`
+	MetricValue      int64     `json:"metric_value"`
+	Ranking          int64     `json:"ranking"`
+	GeneratedAt      time.Time `json:"generated_at"`
+}
+
+// Leaderboard scopes accepted by the list endpoints' scope query parameter
+const (
+	ScopeGlobal  = "global"
+	ScopeFriends = "friends"
+)
+
+const invalidMetricMessage = "metric must be one of score, kills, waves_survived, data_earned"
+
+// rankRadius is how many entries GetPlayerRank shows on each side of the player.
+const rankRadius = 5
+
+// pageParams parses the limit (default 100, max 500) and offset query parameters.
+func pageParams(c *fiber.Ctx) (int64, int64) {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+	return int64(limit), int64(offset)
+}
+
+func entryToResponse(e *leaderboard.Entry) LeaderboardEntryResponse {
+	return LeaderboardEntryResponse{
+		PlayerID:         e.PlayerID,
+		Username:         e.Username,
+		TotalScore:       e.TotalScore,
+		MatchesPlayed:    e.MatchesPlayed,
+		AvgKillsPerMatch: e.AvgKillsPerMatch,
+		AvgWavesSurvived: e.AvgWavesSurvived,
+		MetricValue:      e.MetricValue,
+		Ranking:          e.Ranking,
+		GeneratedAt:      e.GeneratedAt,
+	}
 }
 
 // GetDailyLeaderboard handles GET /leaderboards/daily
 func (h *LeaderboardHandlers) GetDailyLeaderboard(c *fiber.Ctx) error {
-	entries, err := h.service.GetDailyLeaderboard(c.Context())
+	if scope := c.Query("scope", ScopeGlobal); scope != ScopeGlobal {
+		return h.getScopedLeaderboard(c, leaderboard.PeriodDaily, scope)
+	}
+	limit, offset := pageParams(c)
+	entries, err := h.service.GetLeaderboard(c.Context(), leaderboard.PeriodDaily, c.Query("metric", leaderboard.MetricScore), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to get daily leaderboard", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve daily leaderboard",
-		})
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get daily leaderboard", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve daily leaderboard")
 	}
 
 	response := make([]LeaderboardEntryResponse, 0, len(entries))
 	for _, e := range entries {
-		response = append(response, LeaderboardEntryResponse{
-			PlayerID:         e.PlayerID,
-			Username:         e.Username,
-			TotalScore:       e.TotalScore,
-			MatchesPlayed:    e.MatchesPlayed,
-			AvgKillsPerMatch: e.AvgKillsPerMatch,
-			AvgWavesSurvived: e.AvgWavesSurvived,
-			Ranking:          e.Ranking,
-		})
+		response = append(response, entryToResponse(e))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -57,25 +106,22 @@ func (h *LeaderboardHandlers) GetDailyLeaderboard(c *fiber.Ctx) error {
 
 // GetWeeklyLeaderboard handles GET /leaderboards/weekly
 func (h *LeaderboardHandlers) GetWeeklyLeaderboard(c *fiber.Ctx) error {
-	entries, err := h.service.GetWeeklyLeaderboard(c.Context())
+	if scope := c.Query("scope", ScopeGlobal); scope != ScopeGlobal {
+		return h.getScopedLeaderboard(c, leaderboard.PeriodWeekly, scope)
+	}
+	limit, offset := pageParams(c)
+	entries, err := h.service.GetLeaderboard(c.Context(), leaderboard.PeriodWeekly, c.Query("metric", leaderboard.MetricScore), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to get weekly leaderboard", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve weekly leaderboard",
-		})
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get weekly leaderboard", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve weekly leaderboard")
 	}
 
 	response := make([]LeaderboardEntryResponse, 0, len(entries))
 	for _, e := range entries {
-		response = append(response, LeaderboardEntryResponse{
-			PlayerID:         e.PlayerID,
-			Username:         e.Username,
-			TotalScore:       e.TotalScore,
-			MatchesPlayed:    e.MatchesPlayed,
-			AvgKillsPerMatch: e.AvgKillsPerMatch,
-			AvgWavesSurvived: e.AvgWavesSurvived,
-			Ranking:          e.Ranking,
-		})
+		response = append(response, entryToResponse(e))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -83,26 +129,166 @@ func (h *LeaderboardHandlers) GetWeeklyLeaderboard(c *fiber.Ctx) error {
 
 // GetAllTimeLeaderboard handles GET /leaderboards/alltime
 func (h *LeaderboardHandlers) GetAllTimeLeaderboard(c *fiber.Ctx) error {
-	entries, err := h.service.GetAllTimeLeaderboard(c.Context())
+	if scope := c.Query("scope", ScopeGlobal); scope != ScopeGlobal {
+		return h.getScopedLeaderboard(c, leaderboard.PeriodAllTime, scope)
+	}
+	limit, offset := pageParams(c)
+	entries, err := h.service.GetLeaderboard(c.Context(), leaderboard.PeriodAllTime, c.Query("metric", leaderboard.MetricScore), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to get all-time leaderboard", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve all-time leaderboard",
-		})
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get all-time leaderboard", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve all-time leaderboard")
 	}
 
 	response := make([]LeaderboardEntryResponse, 0, len(entries))
 	for _, e := range entries {
-		response = append(response, LeaderboardEntryResponse{
-			PlayerID:         e.PlayerID,
-			Username:         e.Username,
-			TotalScore:       e.TotalScore,
-			MatchesPlayed:    e.MatchesPlayed,
-			AvgKillsPerMatch: e.AvgKillsPerMatch,
-			AvgWavesSurvived: e.AvgWavesSurvived,
-			Ranking:          e.Ranking,
-		})
+		response = append(response, entryToResponse(e))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// getScopedLeaderboard serves a list endpoint for a non-global scope. Only
+// ScopeFriends exists; it needs the caller, so the route authenticates when it is requested.
+func (h *LeaderboardHandlers) getScopedLeaderboard(c *fiber.Ctx, period, scope string) error {
+	if scope != ScopeFriends {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "scope must be one of global, friends")
+	}
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	entries, err := h.service.GetFriendsLeaderboard(c.Context(), period, c.Query("metric", leaderboard.MetricScore), playerID)
+	if err != nil {
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get friends leaderboard", zap.Error(err), zap.String("period", period), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve friends leaderboard")
 	}
 
+	response := make([]LeaderboardEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		response = append(response, entryToResponse(e))
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// ExportLeaderboardCSV handles GET /leaderboards/:period.csv
+func (h *LeaderboardHandlers) ExportLeaderboardCSV(c *fiber.Ctx) error {
+	period := c.Params("period")
+	entries, err := h.service.GetLeaderboard(c.Context(), period, c.Query("metric", leaderboard.MetricScore), leaderboard.NoLimit, 0)
+	if err != nil {
+		if err == leaderboard.ErrInvalidPeriod {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "period must be one of daily, weekly, alltime")
+		}
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to export leaderboard", zap.Error(err), zap.String("period", period))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to export leaderboard")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="leaderboard-%s.csv"`, period))
+	// The stream outlives the handler, so resolve the request logger up front
+	logger := logging.FromContext(c.Context(), h.logger)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"rank", "username", "total_score"})
+		for _, e := range entries {
+			_ = cw.Write([]string{
+				strconv.FormatInt(e.Ranking, 10),
+				e.Username,
+				strconv.FormatInt(e.TotalScore, 10),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logger.Warn("Failed to stream leaderboard CSV", zap.Error(err), zap.String("period", period))
+		}
+	})
+	return nil
+}
+
+type PercentileResponse struct {
+	Period       string   `json:"period"`
+	Rank         *int64   `json:"rank"`
+	TotalPlayers *int64   `json:"total_players"`
+	Percentile   *float64 `json:"percentile"`
+}
+
+// GetPlayerPercentile handles GET /leaderboards/:period/percentile
+func (h *LeaderboardHandlers) GetPlayerPercentile(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	period := c.Params("period")
+	result, err := h.service.GetPlayerPercentile(c.Context(), period, c.Query("metric", leaderboard.MetricScore), playerID)
+	if err != nil {
+		if err == leaderboard.ErrInvalidPeriod {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "period must be one of daily, weekly, alltime")
+		}
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get player percentile", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve player percentile")
+	}
+
+	// Players without activity in the period have no rank; report nulls
+	response := PercentileResponse{Period: period}
+	if result != nil {
+		response.Rank = &result.Rank
+		response.TotalPlayers = &result.TotalPlayers
+		response.Percentile = &result.Percentile
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+type PlayerRankResponse struct {
+	Period       string                     `json:"period"`
+	Rank         *int64                     `json:"rank"`
+	TotalPlayers *int64                     `json:"total_players"`
+	Entries      []LeaderboardEntryResponse `json:"entries"`
+}
+
+// GetPlayerRank handles GET /leaderboards/:period/rank
+func (h *LeaderboardHandlers) GetPlayerRank(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	period := c.Params("period")
+	result, err := h.service.GetPlayerRank(c.Context(), period, c.Query("metric", leaderboard.MetricScore), playerID, rankRadius)
+	if err != nil {
+		if err == leaderboard.ErrInvalidPeriod {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "period must be one of daily, weekly, alltime")
+		}
+		if err == leaderboard.ErrInvalidMetric {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidMetric, invalidMetricMessage)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get player rank", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve player rank")
+	}
+
+	// Players without activity in the period have no rank; report nulls
+	response := PlayerRankResponse{Period: period, Entries: []LeaderboardEntryResponse{}}
+	if result != nil {
+		response.Rank = &result.Rank
+		response.TotalPlayers = &result.TotalPlayers
+		for _, e := range result.Neighbors {
+			response.Entries = append(response.Entries, entryToResponse(e))
+		}
+	}
 	return c.Status(fiber.StatusOK).JSON(response)
 }
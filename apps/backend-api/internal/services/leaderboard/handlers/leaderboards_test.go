@@ -1,14 +1,19 @@
 package handlers_test
 
 import (
+	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/services/leaderboard"
 	"ai-zombie-defense/backend-api/internal/testutils"
 
 	"github.com/gofiber/fiber/v2"
@@ -83,3 +88,544 @@ func TestLeaderboardHandlers_GetDailyLeaderboard(t *testing.T) {
 		t.Errorf("Expected 2 entries, got %d", len(entries))
 	}
 }
+
+func TestLeaderboardHandlers_GetPlayerPercentile(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+
+	// Four active players today; the caller places second
+	scores := []int{9000, 7000, 5000, 1000}
+	var callerID int64
+	for i, score := range scores {
+		name := fmt.Sprintf("player%d", i+1)
+		id := testutils.CreateTestPlayer(t, db, name, name+"@example.com", "password")
+		createTestPlayerMatchStats(t, db, id, matchID, score, 10, 5)
+		if i == 1 {
+			callerID = id
+		}
+	}
+	idleID := testutils.CreateTestPlayer(t, db, "idle", "idle@example.com", "password")
+
+	getPercentile := func(playerID int64, period string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/leaderboards/"+period+"/percentile", nil)
+		req.Header.Set("Authorization", "Bearer "+testutils.CreateTestAccessToken(t, db, playerID))
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := getPercentile(callerID, "daily")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if body["rank"].(float64) != 2 || body["total_players"].(float64) != 4 {
+		t.Errorf("Expected rank 2 of 4, got %v of %v", body["rank"], body["total_players"])
+	}
+	if body["percentile"].(float64) != 0.5 {
+		t.Errorf("Expected percentile 0.5, got %v", body["percentile"])
+	}
+
+	status, body = getPercentile(idleID, "weekly")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if body["percentile"] != nil {
+		t.Errorf("Expected null percentile for player without activity, got %v", body["percentile"])
+	}
+
+	if status, _ := getPercentile(callerID, "monthly"); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown period, got %d", status)
+	}
+}
+
+func TestLeaderboardHandlers_ExcludesOptedOutPlayers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	visibleID := testutils.CreateTestPlayer(t, db, "visible", "visible@example.com", "password")
+	hiddenID := testutils.CreateTestPlayer(t, db, "hidden", "hidden@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+	createTestPlayerMatchStats(t, db, visibleID, matchID, 1000, 10, 5)
+	createTestPlayerMatchStats(t, db, hiddenID, matchID, 9000, 90, 9)
+
+	if _, err := db.Exec(`INSERT INTO player_settings (player_id, show_on_leaderboard) VALUES (?, 0)`, hiddenID); err != nil {
+		t.Fatalf("Failed to opt out of leaderboards: %v", err)
+	}
+
+	for _, period := range []string{"daily", "weekly", "alltime"} {
+		req := httptest.NewRequest(http.MethodGet, "/leaderboards/"+period, nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var entries []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("%s: expected 1 entry, got %d", period, len(entries))
+		}
+		if int64(entries[0]["player_id"].(float64)) != visibleID || entries[0]["ranking"].(float64) != 1 {
+			t.Errorf("%s: expected visible player ranked first, got %v", period, entries[0])
+		}
+	}
+}
+
+func TestLeaderboardHandlers_GetWeeklyLeaderboardModes(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	thisWeek := testutils.CreateTestPlayer(t, db, "thisweek", "thisweek@example.com", "password")
+	lastWeek := testutils.CreateTestPlayer(t, db, "lastweek", "lastweek@example.com", "password")
+	stale := testutils.CreateTestPlayer(t, db, "stale", "stale@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+
+	now := time.Now().UTC()
+	weekStart := leaderboard.WeeklyWindow(leaderboard.WeeklyModeCalendar, now).Start
+	// Just before Monday 00:00 UTC but still inside the last 7 days
+	beforeBoundary := weekStart.Add(-weekStart.Add(7*24*time.Hour).Sub(now) / 2)
+
+	matchID := createTestMatch(t, db, serverID, now)
+	createTestPlayerMatchStats(t, db, thisWeek, matchID, 1000, 10, 5)
+	matchID = createTestMatch(t, db, serverID, beforeBoundary)
+	createTestPlayerMatchStats(t, db, lastWeek, matchID, 2000, 20, 6)
+	matchID = createTestMatch(t, db, serverID, now.AddDate(0, 0, -8))
+	createTestPlayerMatchStats(t, db, stale, matchID, 3000, 30, 7)
+
+	weekly := func(mode string) []string {
+		t.Helper()
+		cfg := testutils.GetTestConfig()
+		cfg.Leaderboard.WeeklyMode = mode
+		app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/leaderboards/weekly", nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var entries []struct {
+			Username string `json:"username"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		usernames := make([]string, 0, len(entries))
+		for _, e := range entries {
+			usernames = append(usernames, e.Username)
+		}
+		return usernames
+	}
+
+	if got := weekly(leaderboard.WeeklyModeCalendar); fmt.Sprint(got) != "[thisweek]" {
+		t.Errorf("Expected calendar week to include only thisweek, got %v", got)
+	}
+	if got := weekly(leaderboard.WeeklyModeRolling); fmt.Sprint(got) != "[lastweek thisweek]" {
+		t.Errorf("Expected rolling window to include lastweek and thisweek, got %v", got)
+	}
+}
+
+func TestLeaderboardHandlers_ExportLeaderboardCSV(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password")
+	hiddenID := testutils.CreateTestPlayer(t, db, "hidden", "hidden@example.com", "password")
+	if _, err := db.Exec(`INSERT INTO player_settings (player_id, show_on_leaderboard) VALUES (?, 0)`, hiddenID); err != nil {
+		t.Fatalf("Failed to insert settings: %v", err)
+	}
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+	createTestPlayerMatchStats(t, db, player1ID, matchID, 5000, 50, 10)
+	createTestPlayerMatchStats(t, db, player2ID, matchID, 3000, 30, 8)
+	createTestPlayerMatchStats(t, db, hiddenID, matchID, 9000, 90, 12)
+
+	req := httptest.NewRequest(http.MethodGet, "/leaderboards/alltime.csv", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("Expected text/csv content type, got %q", ct)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename="leaderboard-alltime.csv"` {
+		t.Errorf("Unexpected Content-Disposition %q", cd)
+	}
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	if fmt.Sprint(records[0]) != "[rank username total_score]" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	if fmt.Sprint(records[1]) != "[1 player1 5000]" || fmt.Sprint(records[2]) != "[2 player2 3000]" {
+		t.Errorf("Unexpected rows %v", records[1:])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/leaderboards/monthly.csv", nil)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown period, got %d", resp.StatusCode)
+	}
+}
+
+func TestLeaderboardHandlers_RankAndPagination(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+
+	// 50 players scoring 100..5000; player25 (2500) ranks 26th
+	ids := make([]int64, 50)
+	for i := range ids {
+		name := fmt.Sprintf("player%d", i+1)
+		ids[i] = testutils.CreateTestPlayer(t, db, name, name+"@example.com", "password")
+		createTestPlayerMatchStats(t, db, ids[i], matchID, (i+1)*100, 10, 5)
+	}
+
+	get := func(path string, playerID int64, body interface{}) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if playerID != 0 {
+			req.Header.Set("Authorization", "Bearer "+testutils.CreateTestAccessToken(t, db, playerID))
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.StatusCode
+	}
+	type entry struct {
+		Username string `json:"username"`
+		Ranking  int64  `json:"ranking"`
+	}
+
+	t.Run("around me", func(t *testing.T) {
+		var body struct {
+			Rank         int64   `json:"rank"`
+			TotalPlayers int64   `json:"total_players"`
+			Entries      []entry `json:"entries"`
+		}
+		if status := get("/leaderboards/alltime/rank", ids[24], &body); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if body.Rank != 26 || body.TotalPlayers != 50 {
+			t.Errorf("Expected rank 26 of 50, got %d of %d", body.Rank, body.TotalPlayers)
+		}
+		if len(body.Entries) != 11 {
+			t.Fatalf("Expected 11 entries around the player, got %d", len(body.Entries))
+		}
+		for i, e := range body.Entries {
+			// Ranks 21..31 hold players 30 down to 20
+			wantRank := int64(21 + i)
+			if want := fmt.Sprintf("player%d", 51-wantRank); e.Ranking != wantRank || e.Username != want {
+				t.Errorf("Expected %s at rank %d, got %s at %d", want, wantRank, e.Username, e.Ranking)
+			}
+		}
+	})
+
+	t.Run("around me near the top", func(t *testing.T) {
+		var body struct {
+			Rank    int64   `json:"rank"`
+			Entries []entry `json:"entries"`
+		}
+		get("/leaderboards/alltime/rank", ids[48], &body)
+		if body.Rank != 2 || len(body.Entries) != 7 || body.Entries[0].Ranking != 1 {
+			t.Errorf("Expected rank 2 with ranks 1..7, got rank %d and %+v", body.Rank, body.Entries)
+		}
+	})
+
+	t.Run("limit and offset", func(t *testing.T) {
+		var entries []entry
+		if status := get("/leaderboards/alltime?limit=5&offset=10", 0, &entries); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if len(entries) != 5 || entries[0].Ranking != 11 || entries[4].Ranking != 15 {
+			t.Errorf("Expected ranks 11..15, got %+v", entries)
+		}
+	})
+}
+
+func TestLeaderboardHandlers_Metric(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+
+	// The top scorer kills the fewest zombies, so the two metrics rank in reverse
+	stats := []struct {
+		name         string
+		score, kills int
+	}{{"builder", 9000, 10}, {"medic", 6000, 40}, {"slayer", 3000, 90}}
+	for _, s := range stats {
+		id := testutils.CreateTestPlayer(t, db, s.name, s.name+"@example.com", "password")
+		createTestPlayerMatchStats(t, db, id, matchID, s.score, s.kills, 5)
+	}
+
+	ranking := func(path string) (int, []string, []int64) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, nil, nil
+		}
+		var entries []struct {
+			Username    string `json:"username"`
+			MetricValue int64  `json:"metric_value"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		var names []string
+		var values []int64
+		for _, e := range entries {
+			names = append(names, e.Username)
+			values = append(values, e.MetricValue)
+		}
+		return resp.StatusCode, names, values
+	}
+
+	for _, period := range []string{"daily", "weekly", "alltime"} {
+		_, byScore, scores := ranking("/leaderboards/" + period + "?metric=score")
+		if fmt.Sprint(byScore) != "[builder medic slayer]" || fmt.Sprint(scores) != "[9000 6000 3000]" {
+			t.Errorf("%s: unexpected score ranking %v %v", period, byScore, scores)
+		}
+		_, byKills, kills := ranking("/leaderboards/" + period + "?metric=kills")
+		if fmt.Sprint(byKills) != "[slayer medic builder]" || fmt.Sprint(kills) != "[90 40 10]" {
+			t.Errorf("%s: unexpected kills ranking %v %v", period, byKills, kills)
+		}
+	}
+
+	// Only whitelisted metrics reach the query
+	for _, metric := range []string{"deaths", "score;DROP TABLE players", "pms.score"} {
+		if status, _, _ := ranking("/leaderboards/alltime?metric=" + url.QueryEscape(metric)); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for metric %q, got %d", metric, status)
+		}
+	}
+}
+
+func TestLeaderboardHandlers_FriendsScope(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now().UTC())
+
+	ids := map[string]int64{}
+	for name, score := range map[string]int{"me": 4000, "mine": 5000, "theirs": 2000, "asked": 9000, "asking": 8000, "stranger": 7000} {
+		ids[name] = testutils.CreateTestPlayer(t, db, name, name+"@example.com", "password")
+		createTestPlayerMatchStats(t, db, ids[name], matchID, score, 10, 5)
+	}
+	// Friendships are stored once, by whoever sent the request
+	for _, f := range []struct {
+		from, to, status string
+	}{
+		{"me", "mine", "accepted"},
+		{"theirs", "me", "accepted"},
+		{"me", "asked", "pending"},
+		{"asking", "me", "pending"},
+	} {
+		if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, ?)`, ids[f.from], ids[f.to], f.status); err != nil {
+			t.Fatalf("Failed to insert friendship: %v", err)
+		}
+	}
+
+	get := func(path string, withAuth bool) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if withAuth {
+			req.Header.Set("Authorization", "Bearer "+testutils.CreateTestAccessToken(t, db, ids["me"]))
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	checkFriends := func(source string) {
+		t.Helper()
+		for _, period := range []string{"daily", "weekly", "alltime"} {
+			resp := get("/leaderboards/"+period+"?scope=friends", true)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("%s %s: expected status 200, got %d", source, period, resp.StatusCode)
+			}
+			var entries []struct {
+				Username string `json:"username"`
+				Ranking  int64  `json:"ranking"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			got := fmt.Sprint(entries)
+			if want := "[{mine 1} {me 2} {theirs 3}]"; got != want {
+				t.Errorf("%s %s: expected friends ranking %s, got %s", source, period, want, got)
+			}
+		}
+	}
+	checkFriends("live")
+
+	// Fresh snapshots are scoped to the same friend group
+	lbSvc := leaderboard.NewLeaderboardService(testutils.GetTestConfig(), zaptest.NewLogger(t), db)
+	if err := lbSvc.RefreshLeaderboards(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh leaderboards: %v", err)
+	}
+	checkFriends("snapshot")
+
+	if resp := get("/leaderboards/daily?scope=friends", false); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without auth, got %d", resp.StatusCode)
+	}
+	if resp := get("/leaderboards/daily?scope=guild", true); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown scope, got %d", resp.StatusCode)
+	}
+	if resp := get("/leaderboards/daily", false); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the global board to stay public, got %d", resp.StatusCode)
+	}
+}
+
+func TestLeaderboardHandlers_DailyBoundary(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+	serverID := testutils.CreateTestServerRow(t, db)
+
+	// Matches on either side of today's UTC midnight, and exactly on it
+	midnight := leaderboard.DailyWindow(time.Now()).Start
+	for name, start := range map[string]time.Time{
+		"yesterday": midnight.Add(-time.Second),
+		"midnight":  midnight,
+		"today":     midnight.Add(time.Second),
+	} {
+		id := testutils.CreateTestPlayer(t, db, name, name+"@example.com", "password")
+		createTestPlayerMatchStats(t, db, id, createTestMatch(t, db, serverID, start), 1000, 10, 5)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/leaderboards/daily", nil), -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var entries []struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	got := map[string]bool{}
+	for _, e := range entries {
+		got[e.Username] = true
+	}
+	if len(got) != 2 || !got["midnight"] || !got["today"] {
+		t.Errorf("Expected only the midnight and today matches in the daily board, got %v", got)
+	}
+}
+
+func TestLeaderboardHandlers_Snapshot(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+	lbSvc := leaderboard.NewLeaderboardService(testutils.GetTestConfig(), zaptest.NewLogger(t), db)
+
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	matchID := createTestMatch(t, db, serverID, time.Now())
+	createTestPlayerMatchStats(t, db, player1ID, matchID, 5000, 50, 10)
+	createTestPlayerMatchStats(t, db, player2ID, matchID, 3000, 30, 8)
+
+	type entry struct {
+		Username    string    `json:"username"`
+		TotalScore  int64     `json:"total_score"`
+		Ranking     int64     `json:"ranking"`
+		GeneratedAt time.Time `json:"generated_at"`
+	}
+	getBoard := func(path string) []entry {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d", path, resp.StatusCode)
+		}
+		var entries []entry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return entries
+	}
+
+	before := time.Now().UTC().Truncate(time.Second)
+	if err := lbSvc.RefreshLeaderboards(context.Background()); err != nil {
+		t.Fatalf("RefreshLeaderboards failed: %v", err)
+	}
+	// Refreshing again replaces the rows rather than adding to them
+	if err := lbSvc.RefreshLeaderboards(context.Background()); err != nil {
+		t.Fatalf("RefreshLeaderboards failed: %v", err)
+	}
+	var rows int
+	db.QueryRow(`SELECT COUNT(*) FROM leaderboard_snapshots WHERE period = 'daily' AND metric = 'score'`).Scan(&rows)
+	if rows != 2 {
+		t.Fatalf("Expected 2 snapshot rows for the daily score board, got %d", rows)
+	}
+
+	// A match played after the refresh is not visible until the next one
+	player3ID := testutils.CreateTestPlayer(t, db, "player3", "player3@example.com", "password")
+	createTestPlayerMatchStats(t, db, player3ID, createTestMatch(t, db, serverID, time.Now()), 9000, 90, 12)
+
+	for _, period := range []string{"daily", "weekly", "alltime"} {
+		entries := getBoard("/leaderboards/" + period)
+		if len(entries) != 2 || entries[0].Username != "player1" || entries[1].Username != "player2" {
+			t.Fatalf("Expected the %s snapshot of player1 and player2, got %+v", period, entries)
+		}
+		if entries[0].GeneratedAt.Before(before) || entries[0].GeneratedAt.After(time.Now()) {
+			t.Errorf("Expected %s generated_at from the refresh, got %s", period, entries[0].GeneratedAt)
+		}
+	}
+
+	// Snapshots older than SnapshotMaxAge fall back to the live ranking
+	if _, err := db.Exec(`UPDATE leaderboard_snapshots SET generated_at = ?`, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatalf("Failed to age snapshots: %v", err)
+	}
+	entries := getBoard("/leaderboards/alltime")
+	if len(entries) != 3 || entries[0].Username != "player3" || entries[0].TotalScore != 9000 {
+		t.Fatalf("Expected the live ranking led by player3, got %+v", entries)
+	}
+	if time.Since(entries[0].GeneratedAt) > time.Minute {
+		t.Errorf("Expected a live generated_at, got %s", entries[0].GeneratedAt)
+	}
+}
@@ -2,9 +2,13 @@ package leaderboard
 
 import (
 	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/db/types"
 	"ai-zombie-defense/backend-api/pkg/config"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -25,26 +29,414 @@ func NewLeaderboardService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX
 	}
 }
 
-func (s *leaderboardService) GetDailyLeaderboard(ctx context.Context) ([]*db.GetDailyLeaderboardRow, error) {
-	entries, err := s.queries.GetDailyLeaderboard(ctx, s.dbConn)
+func (s *leaderboardService) GetDailyLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetDailyLeaderboardRow, error) {
+	if !validMetric(metric) {
+		return nil, ErrInvalidMetric
+	}
+	window := DailyWindow(time.Now())
+	entries, err := s.queries.GetDailyLeaderboard(ctx, s.dbConn, &db.GetDailyLeaderboardParams{
+		Metric: metric,
+		Since:  window.since(),
+		Until:  window.until(),
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily leaderboard: %w", err)
 	}
 	return entries, nil
 }
 
-func (s *leaderboardService) GetWeeklyLeaderboard(ctx context.Context) ([]*db.GetWeeklyLeaderboardRow, error) {
-	entries, err := s.queries.GetWeeklyLeaderboard(ctx, s.dbConn)
+func (s *leaderboardService) GetWeeklyLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetWeeklyLeaderboardRow, error) {
+	if !validMetric(metric) {
+		return nil, ErrInvalidMetric
+	}
+	window := WeeklyWindow(s.config.Leaderboard.WeeklyMode, time.Now())
+	entries, err := s.queries.GetWeeklyLeaderboard(ctx, s.dbConn, &db.GetWeeklyLeaderboardParams{
+		Metric: metric,
+		Since:  window.since(),
+		Until:  window.until(),
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get weekly leaderboard: %w", err)
 	}
 	return entries, nil
 }
 
-func (s *leaderboardService) GetAllTimeLeaderboard(ctx context.Context) ([]*db.GetAllTimeLeaderboardRow, error) {
-	entries, err := s.queries.GetAllTimeLeaderboard(ctx, s.dbConn)
+func (s *leaderboardService) GetAllTimeLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetAllTimeLeaderboardRow, error) {
+	if !validMetric(metric) {
+		return nil, ErrInvalidMetric
+	}
+	entries, err := s.queries.GetAllTimeLeaderboard(ctx, s.dbConn, &db.GetAllTimeLeaderboardParams{
+		Metric: metric,
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all-time leaderboard: %w", err)
 	}
 	return entries, nil
 }
+
+func (s *leaderboardService) GetLeaderboard(ctx context.Context, period, metric string, limit, offset int64) ([]*Entry, error) {
+	// Invalid periods and metrics never have a snapshot, so they fall through
+	// to the live path and its validation
+	fresh, err := s.snapshotFresh(ctx, period, metric)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return s.liveLeaderboard(ctx, period, metric, limit, offset)
+	}
+
+	rows, err := s.queries.ListLeaderboardSnapshot(ctx, s.dbConn, &db.ListLeaderboardSnapshotParams{
+		Period: period,
+		Metric: metric,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s leaderboard snapshot: %w", period, err)
+	}
+	entries := make([]*Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, &Entry{
+			PlayerID:         r.PlayerID,
+			Username:         r.Username,
+			TotalScore:       r.TotalScore,
+			MatchesPlayed:    r.MatchesPlayed,
+			AvgKillsPerMatch: r.AvgKillsPerMatch,
+			AvgWavesSurvived: r.AvgWavesSurvived,
+			MetricValue:      r.MetricValue,
+			Ranking:          r.Ranking,
+			GeneratedAt:      r.GeneratedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+// liveLeaderboard computes a page of the leaderboard straight from player_match_stats.
+func (s *leaderboardService) liveLeaderboard(ctx context.Context, period, metric string, limit, offset int64) ([]*Entry, error) {
+	// The period row types share their fields, so daily and weekly rows convert
+	// to the all-time row type
+	generatedAt := time.Now().UTC()
+	var entries []*Entry
+	switch period {
+	case PeriodDaily:
+		rows, err := s.GetDailyLeaderboard(ctx, metric, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			entries = append(entries, newEntry(db.GetAllTimeLeaderboardRow(*r), generatedAt))
+		}
+	case PeriodWeekly:
+		rows, err := s.GetWeeklyLeaderboard(ctx, metric, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			entries = append(entries, newEntry(db.GetAllTimeLeaderboardRow(*r), generatedAt))
+		}
+	case PeriodAllTime:
+		rows, err := s.GetAllTimeLeaderboard(ctx, metric, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			entries = append(entries, newEntry(*r, generatedAt))
+		}
+	default:
+		return nil, ErrInvalidPeriod
+	}
+	return entries, nil
+}
+
+func newEntry(r db.GetAllTimeLeaderboardRow, generatedAt time.Time) *Entry {
+	return &Entry{
+		PlayerID:         r.PlayerID,
+		Username:         r.Username,
+		TotalScore:       r.TotalScore,
+		MatchesPlayed:    r.MatchesPlayed,
+		AvgKillsPerMatch: r.AvgKillsPerMatch,
+		AvgWavesSurvived: r.AvgWavesSurvived,
+		MetricValue:      r.MetricValue,
+		Ranking:          r.Ranking,
+		GeneratedAt:      generatedAt,
+	}
+}
+
+// snapshotFresh reports whether the stored snapshot for period and metric can
+// be served. A missing snapshot, one older than Leaderboard.SnapshotMaxAge or
+// one taken before the current daily or weekly window began is not fresh.
+func (s *leaderboardService) snapshotFresh(ctx context.Context, period, metric string) (bool, error) {
+	generatedAt, err := s.queries.GetLeaderboardSnapshotGeneratedAt(ctx, s.dbConn, &db.GetLeaderboardSnapshotGeneratedAtParams{
+		Period: period,
+		Metric: metric,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s leaderboard snapshot: %w", period, err)
+	}
+
+	now := time.Now()
+	if now.Sub(generatedAt.Time) > s.config.Leaderboard.SnapshotMaxAge {
+		return false, nil
+	}
+	switch period {
+	case PeriodDaily:
+		return !generatedAt.Before(DailyWindow(now).Start), nil
+	case PeriodWeekly:
+		return !generatedAt.Before(WeeklyWindow(s.config.Leaderboard.WeeklyMode, now).Start), nil
+	}
+	return true, nil
+}
+
+func (s *leaderboardService) RefreshLeaderboards(ctx context.Context) error {
+	// Stored timestamps have second precision; truncating keeps a refresh right
+	// after midnight inside the new day's window
+	generatedAt := time.Now().UTC().Truncate(time.Second)
+
+	// Compute every board before opening the transaction so the write lock is
+	// only held while the rows are replaced
+	type board struct {
+		period, metric string
+		entries        []*Entry
+	}
+	var boards []board
+	for _, period := range []string{PeriodDaily, PeriodWeekly, PeriodAllTime} {
+		for _, metric := range []string{MetricScore, MetricKills, MetricWavesSurvived, MetricDataEarned} {
+			entries, err := s.liveLeaderboard(ctx, period, metric, NoLimit, 0)
+			if err != nil {
+				return err
+			}
+			boards = append(boards, board{period: period, metric: metric, entries: entries})
+		}
+	}
+
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		for _, b := range boards {
+			if err := s.queries.DeleteLeaderboardSnapshot(ctx, tx, &db.DeleteLeaderboardSnapshotParams{
+				Period: b.period,
+				Metric: b.metric,
+			}); err != nil {
+				return fmt.Errorf("failed to clear %s leaderboard snapshot: %w", b.period, err)
+			}
+			for _, e := range b.entries {
+				if err := s.queries.InsertLeaderboardSnapshotEntry(ctx, tx, &db.InsertLeaderboardSnapshotEntryParams{
+					Period:           b.period,
+					Metric:           b.metric,
+					Ranking:          e.Ranking,
+					PlayerID:         e.PlayerID,
+					Username:         e.Username,
+					TotalScore:       e.TotalScore,
+					MatchesPlayed:    e.MatchesPlayed,
+					AvgKillsPerMatch: e.AvgKillsPerMatch,
+					AvgWavesSurvived: e.AvgWavesSurvived,
+					MetricValue:      e.MetricValue,
+					GeneratedAt:      types.Timestamp{Time: generatedAt},
+				}); err != nil {
+					return fmt.Errorf("failed to write %s leaderboard snapshot: %w", b.period, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *leaderboardService) GetPlayerRank(ctx context.Context, period, metric string, playerID int64, radius int64) (*PlayerRank, error) {
+	percentile, err := s.GetPlayerPercentile(ctx, period, metric, playerID)
+	if err != nil || percentile == nil {
+		return nil, err
+	}
+
+	// Rankings are 1-based and contiguous, so the window is a plain page of the board
+	offset := max(percentile.Rank-radius-1, 0)
+	neighbors, err := s.GetLeaderboard(ctx, period, metric, percentile.Rank+radius-offset, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerRank{
+		Rank:         percentile.Rank,
+		TotalPlayers: percentile.TotalPlayers,
+		Neighbors:    neighbors,
+	}, nil
+}
+
+func (s *leaderboardService) GetFriendsLeaderboard(ctx context.Context, period, metric string, playerID int64) ([]*Entry, error) {
+	// Both queries rank only the player's friend group, never the whole board
+	fresh, err := s.snapshotFresh(ctx, period, metric)
+	if err != nil {
+		return nil, err
+	}
+	if fresh {
+		rows, err := s.queries.ListFriendsLeaderboardSnapshot(ctx, s.dbConn, &db.ListFriendsLeaderboardSnapshotParams{
+			PlayerID: playerID,
+			Period:   period,
+			Metric:   metric,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s friends leaderboard snapshot: %w", period, err)
+		}
+		entries := make([]*Entry, 0, len(rows))
+		for _, r := range rows {
+			entries = append(entries, &Entry{
+				PlayerID:         r.PlayerID,
+				Username:         r.Username,
+				TotalScore:       r.TotalScore,
+				MatchesPlayed:    r.MatchesPlayed,
+				AvgKillsPerMatch: r.AvgKillsPerMatch,
+				AvgWavesSurvived: r.AvgWavesSurvived,
+				MetricValue:      r.MetricValue,
+				Ranking:          r.Ranking,
+				GeneratedAt:      r.GeneratedAt.Time,
+			})
+		}
+		return entries, nil
+	}
+
+	if !validMetric(metric) {
+		return nil, ErrInvalidMetric
+	}
+	params := &db.GetFriendsLeaderboardParams{
+		PlayerID: playerID,
+		Metric:   metric,
+	}
+	switch period {
+	case PeriodDaily:
+		params.Since, params.Until = DailyWindow(time.Now()).bounds()
+	case PeriodWeekly:
+		params.Since, params.Until = WeeklyWindow(s.config.Leaderboard.WeeklyMode, time.Now()).bounds()
+	case PeriodAllTime:
+	default:
+		return nil, ErrInvalidPeriod
+	}
+	rows, err := s.queries.GetFriendsLeaderboard(ctx, s.dbConn, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s friends leaderboard: %w", period, err)
+	}
+	generatedAt := time.Now().UTC()
+	entries := make([]*Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, newEntry(db.GetAllTimeLeaderboardRow(*r), generatedAt))
+	}
+	return entries, nil
+}
+
+func (s *leaderboardService) GetPlayerPercentile(ctx context.Context, period, metric string, playerID int64) (*PlayerPercentile, error) {
+	if !validMetric(metric) {
+		return nil, ErrInvalidMetric
+	}
+	fresh, err := s.snapshotFresh(ctx, period, metric)
+	if err != nil {
+		return nil, err
+	}
+	var rank, total int64
+	switch {
+	case fresh:
+		var row *db.GetLeaderboardSnapshotPlayerRankRow
+		row, err = s.queries.GetLeaderboardSnapshotPlayerRank(ctx, s.dbConn, &db.GetLeaderboardSnapshotPlayerRankParams{
+			Period:   period,
+			Metric:   metric,
+			PlayerID: playerID,
+		})
+		if err == nil {
+			rank, total = row.Ranking, row.TotalPlayers
+		}
+	case period == PeriodDaily:
+		var row *db.GetDailyPlayerRankRow
+		window := DailyWindow(time.Now())
+		row, err = s.queries.GetDailyPlayerRank(ctx, s.dbConn, &db.GetDailyPlayerRankParams{
+			Metric:   metric,
+			Since:    window.since(),
+			Until:    window.until(),
+			PlayerID: playerID,
+		})
+		if err == nil {
+			rank, total = row.Ranking, row.TotalPlayers
+		}
+	case period == PeriodWeekly:
+		var row *db.GetWeeklyPlayerRankRow
+		window := WeeklyWindow(s.config.Leaderboard.WeeklyMode, time.Now())
+		row, err = s.queries.GetWeeklyPlayerRank(ctx, s.dbConn, &db.GetWeeklyPlayerRankParams{
+			Metric:   metric,
+			Since:    window.since(),
+			Until:    window.until(),
+			PlayerID: playerID,
+		})
+		if err == nil {
+			rank, total = row.Ranking, row.TotalPlayers
+		}
+	case period == PeriodAllTime:
+		var row *db.GetAllTimePlayerRankRow
+		row, err = s.queries.GetAllTimePlayerRank(ctx, s.dbConn, &db.GetAllTimePlayerRankParams{
+			Metric:   metric,
+			PlayerID: playerID,
+		})
+		if err == nil {
+			rank, total = row.Ranking, row.TotalPlayers
+		}
+	default:
+		return nil, ErrInvalidPeriod
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s player rank: %w", period, err)
+	}
+
+	return &PlayerPercentile{
+		Rank:         rank,
+		TotalPlayers: total,
+		Percentile:   1 - float64(rank)/float64(total),
+	}, nil
+}
+
+// validMetric reports whether metric is one of the Metric constants. The
+// queries only compare it against literals, but an unknown metric would
+// silently rank by score, so it is rejected instead.
+func validMetric(metric string) bool {
+	switch metric {
+	case MetricScore, MetricKills, MetricWavesSurvived, MetricDataEarned:
+		return true
+	}
+	return false
+}
+
+func (w Window) since() types.Timestamp { return types.Timestamp{Time: w.Start} }
+func (w Window) until() types.Timestamp { return types.Timestamp{Time: w.End} }
+
+// bounds returns the window as the nullable since and until of queries that
+// also serve all-time.
+func (w Window) bounds() (types.NullTimestamp, types.NullTimestamp) {
+	return types.NullTimestamp{Timestamp: w.since(), Valid: true}, types.NullTimestamp{Timestamp: w.until(), Valid: true}
+}
+
+// DailyWindow returns the UTC calendar day containing now: midnight UTC up to
+// the next midnight, so a match at exactly 00:00:00Z starts the new day.
+func DailyWindow(now time.Time) Window {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return Window{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// WeeklyWindow returns the weekly leaderboard window at now for mode. The
+// calendar mode is the ISO week (Monday 00:00 UTC up to the next Monday);
+// rolling is the last 7 days up to and including the current second, since
+// start times are stored at second precision. Unknown modes fall back to calendar.
+func WeeklyWindow(mode string, now time.Time) Window {
+	now = now.UTC()
+	if mode == WeeklyModeRolling {
+		end := now.Truncate(time.Second).Add(time.Second)
+		return Window{Start: end.Add(-7 * 24 * time.Hour), End: end}
+	}
+	// time.Weekday counts from Sunday; shift so Monday is day 0
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	return Window{Start: start, End: start.AddDate(0, 0, 7)}
+}
@@ -3,10 +3,97 @@ package leaderboard
 import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"context"
+	"errors"
+	"time"
 )
 
+var (
+	ErrInvalidPeriod = errors.New("invalid leaderboard period")
+	ErrInvalidMetric = errors.New("invalid leaderboard metric")
+)
+
+// Leaderboard periods accepted by period-parameterised endpoints
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodAllTime = "alltime"
+)
+
+// Leaderboard metrics players can be ranked by, each summed over the period's
+// player_match_stats. These are the only values passed to the queries' metric argument.
+const (
+	MetricScore         = "score"
+	MetricKills         = "kills"
+	MetricWavesSurvived = "waves_survived"
+	MetricDataEarned    = "data_earned"
+)
+
+// Weekly leaderboard windows selected by config.LeaderboardConfig.WeeklyMode
+const (
+	WeeklyModeCalendar = "calendar"
+	WeeklyModeRolling  = "rolling"
+)
+
+// Window is the half-open range [Start, End) of match start times, in UTC,
+// that a periodic leaderboard covers.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NoLimit can be passed as a leaderboard limit to return every entry; SQLite
+// treats a negative LIMIT as unbounded.
+const NoLimit = -1
+
+// Entry is one row of a leaderboard, independent of its period.
+type Entry struct {
+	PlayerID         int64
+	Username         string
+	TotalScore       int64
+	MatchesPlayed    int64
+	AvgKillsPerMatch *float64
+	AvgWavesSurvived *float64
+	// MetricValue is the player's total for the metric the board is ranked by.
+	MetricValue int64
+	Ranking     int64
+	// GeneratedAt is when the ranking was computed: the snapshot's refresh
+	// time, or the read itself when the board was computed live.
+	GeneratedAt time.Time
+}
+
+// PlayerRank is a player's position in a leaderboard period together with the
+// entries ranked immediately around them, the player included.
+type PlayerRank struct {
+	Rank         int64
+	TotalPlayers int64
+	Neighbors    []*Entry
+}
+
+// PlayerPercentile describes where a player sits within a leaderboard period.
+// Percentile is computed as 1 - rank/total.
+type PlayerPercentile struct {
+	Rank         int64
+	TotalPlayers int64
+	Percentile   float64
+}
+
 type Service interface {
-	GetDailyLeaderboard(ctx context.Context) ([]*db.GetDailyLeaderboardRow, error)
-	GetWeeklyLeaderboard(ctx context.Context) ([]*db.GetWeeklyLeaderboardRow, error)
-	GetAllTimeLeaderboard(ctx context.Context) ([]*db.GetAllTimeLeaderboardRow, error)
+	// The leaderboard methods rank by metric, one of the Metric constants, and
+	// return ErrInvalidMetric for anything else.
+	GetDailyLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetDailyLeaderboardRow, error)
+	GetWeeklyLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetWeeklyLeaderboardRow, error)
+	GetAllTimeLeaderboard(ctx context.Context, metric string, limit, offset int64) ([]*db.GetAllTimeLeaderboardRow, error)
+	// GetLeaderboard returns a page of the leaderboard for period as period-independent entries.
+	// It reads the latest snapshot, computing the board live when the snapshot is missing or stale.
+	GetLeaderboard(ctx context.Context, period, metric string, limit, offset int64) ([]*Entry, error)
+	// GetPlayerRank returns nil when the player has no activity in the period;
+	// Neighbors holds up to radius entries on each side of the player.
+	GetPlayerRank(ctx context.Context, period, metric string, playerID int64, radius int64) (*PlayerRank, error)
+	// GetFriendsLeaderboard returns the period's leaderboard restricted to the player and
+	// their accepted friends (in either direction), re-ranked from 1 within that group.
+	GetFriendsLeaderboard(ctx context.Context, period, metric string, playerID int64) ([]*Entry, error)
+	// GetPlayerPercentile returns nil when the player has no activity in the period.
+	GetPlayerPercentile(ctx context.Context, period, metric string, playerID int64) (*PlayerPercentile, error)
+	// RefreshLeaderboards recomputes every period and metric and replaces the stored snapshots.
+	RefreshLeaderboards(ctx context.Context) error
 }
@@ -0,0 +1,69 @@
+package leaderboard_test
+
+import (
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/services/leaderboard"
+)
+
+func TestDailyWindow(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"last second of the day", day.Add(-time.Second), day.AddDate(0, 0, -1)},
+		{"exactly midnight", day, day},
+		{"first second of the day", day.Add(time.Second), day},
+		// 01:00 in UTC+2 is still 23:00 the previous UTC day
+		{"non-UTC input", time.Date(2026, 3, 10, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), day.AddDate(0, 0, -1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := leaderboard.DailyWindow(tt.now)
+			if !w.Start.Equal(tt.want) || !w.End.Equal(tt.want.AddDate(0, 0, 1)) {
+				t.Errorf("Expected [%s, %s), got [%s, %s)", tt.want, tt.want.AddDate(0, 0, 1), w.Start, w.End)
+			}
+			if w.Start.Location() != time.UTC {
+				t.Errorf("Expected a UTC window, got %s", w.Start.Location())
+			}
+		})
+	}
+}
+
+func TestWeeklyWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"sunday night", time.Date(2026, 3, 15, 23, 59, 59, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"monday midnight", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		// ISO week 1 of 2026 starts on Monday 2025-12-29
+		{"across new year", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := leaderboard.WeeklyWindow(leaderboard.WeeklyModeCalendar, tt.now)
+			if !w.Start.Equal(tt.want) || !w.End.Equal(tt.want.AddDate(0, 0, 7)) {
+				t.Errorf("Expected [%s, %s), got [%s, %s)", tt.want, tt.want.AddDate(0, 0, 7), w.Start, w.End)
+			}
+			year, week := tt.now.ISOWeek()
+			if y, wk := w.Start.ISOWeek(); y != year || wk != week {
+				t.Errorf("Expected ISO week %d-%d, got %d-%d", year, week, y, wk)
+			}
+		})
+	}
+
+	t.Run("rolling", func(t *testing.T) {
+		now := time.Date(2026, 3, 10, 12, 30, 15, 500, time.UTC)
+		w := leaderboard.WeeklyWindow(leaderboard.WeeklyModeRolling, now)
+		// The current second is included since start times have second precision
+		wantEnd := time.Date(2026, 3, 10, 12, 30, 16, 0, time.UTC)
+		if !w.End.Equal(wantEnd) || !w.Start.Equal(wantEnd.AddDate(0, 0, -7)) {
+			t.Errorf("Expected the 7 days up to %s, got [%s, %s)", wantEnd, w.Start, w.End)
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/loot"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -33,49 +35,105 @@ type CosmeticDropResponse struct {
 	CreatedAt      string  `json:"created_at"`
 }
 
+// LootDropResponse is the cosmetic that dropped plus the roll details.
+type LootDropResponse struct {
+	CosmeticDropResponse
+	Quantity     int64 `json:"quantity"`
+	LootTableID  int64 `json:"loot_table_id"`
+	WasDuplicate bool  `json:"was_duplicate"`
+	RefundedData int64 `json:"refunded_data"`
+}
+
 // GenerateLootDrop handles POST /loot/drop
 func (h *LootHandlers) GenerateLootDrop(c *fiber.Ctx) error {
 	ctx := c.Context()
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("failed to get player ID from context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player ID from context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
-	cosmetic, err := h.service.GenerateLootDrop(ctx, playerID)
+	drop, err := h.service.GenerateLootDrop(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to generate loot drop", zap.Error(err))
+		logging.FromContext(c.Context(), h.logger).Error("failed to generate loot drop", zap.Error(err))
 		// Determine appropriate status code
 		if err.Error() == "no active loot tables" ||
 			err.Error() == "no drop from any loot table" ||
 			err.Error() == "loot table has no entries" ||
 			err.Error() == "total weight must be positive" ||
 			err.Error() == "cosmetic not found" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeNoLootAvailable, err.Error())
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to generate loot drop",
-		})
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to generate loot drop")
 	}
 
 	// Convert to response
+	cosmetic := drop.Cosmetic
 	isPrestigeOnly := cosmetic.IsPrestigeOnly == 1
-	response := CosmeticDropResponse{
-		CosmeticID:     cosmetic.CosmeticID,
-		Name:           cosmetic.Name,
-		Description:    cosmetic.Description,
-		Slot:           cosmetic.Slot,
-		Category:       cosmetic.Category,
-		Rarity:         cosmetic.Rarity,
-		UnlockLevel:    cosmetic.UnlockLevel,
-		DataCost:       cosmetic.DataCost,
-		IsPrestigeOnly: isPrestigeOnly,
-		CreatedAt:      cosmetic.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+	response := LootDropResponse{
+		CosmeticDropResponse: CosmeticDropResponse{
+			CosmeticID:     cosmetic.CosmeticID,
+			Name:           cosmetic.Name,
+			Description:    cosmetic.Description,
+			Slot:           cosmetic.Slot,
+			Category:       cosmetic.Category,
+			Rarity:         cosmetic.Rarity,
+			UnlockLevel:    cosmetic.UnlockLevel,
+			DataCost:       cosmetic.DataCost,
+			IsPrestigeOnly: isPrestigeOnly,
+			CreatedAt:      cosmetic.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		},
+		Quantity:     drop.Quantity,
+		LootTableID:  drop.LootTableID,
+		WasDuplicate: drop.WasDuplicate,
+		RefundedData: drop.RefundedData,
 	}
 
 	return c.JSON(response)
 }
+
+type CosmeticSourceResponse struct {
+	Type          string   `json:"type"`
+	DataCost      *int64   `json:"data_cost,omitempty"`
+	LootTableID   *int64   `json:"loot_table_id,omitempty"`
+	LootTableName *string  `json:"loot_table_name,omitempty"`
+	DropChance    *float64 `json:"drop_chance,omitempty"`
+	Level         *int64   `json:"level,omitempty"`
+}
+
+// GetCosmeticSources handles GET /cosmetics/:id/sources
+func (h *LootHandlers) GetCosmeticSources(c *fiber.Ctx) error {
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+
+	sources, err := h.service.GetCosmeticSources(c.Context(), int64(cosmeticID))
+	if err != nil {
+		if err == loot.ErrCosmeticNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to get cosmetic sources", zap.Error(err), zap.Int("cosmetic_id", cosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	response := make([]CosmeticSourceResponse, 0, len(sources))
+	for _, source := range sources {
+		item := CosmeticSourceResponse{Type: source.Type}
+		switch source.Type {
+		case loot.SourcePurchase:
+			item.DataCost = &source.DataCost
+		case loot.SourceLootDrop:
+			item.LootTableID = &source.LootTableID
+			item.LootTableName = &source.LootTableName
+			item.DropChance = &source.DropChance
+		case loot.SourceLevelUp, loot.SourcePrestige:
+			item.Level = &source.Level
+		}
+		response = append(response, item)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"cosmetic_id": cosmeticID,
+		"sources":     response,
+	})
+}
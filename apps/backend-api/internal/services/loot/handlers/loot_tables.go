@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/services/loot"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"errors"
 	"strconv"
 
@@ -71,6 +73,31 @@ type UpdateLootTableEntryRequest struct {
 	MaxQuantity int64 `json:"max_quantity"`
 }
 
+type SimulatedDropResponse struct {
+	CosmeticID int64   `json:"cosmetic_id"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+type LootTableSimulationResponse struct {
+	LootTableID int64                   `json:"loot_table_id"`
+	Rolls       int                     `json:"rolls"`
+	Drops       []SimulatedDropResponse `json:"drops"`
+}
+
+// entryValidationError maps the loot service's entry validation errors to a status, code and message.
+func entryValidationError(err error) (int, apierror.Code, string, bool) {
+	switch {
+	case errors.Is(err, loot.ErrCosmeticNotFound):
+		return fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found", true
+	case errors.Is(err, loot.ErrInvalidWeight):
+		return fiber.StatusBadRequest, apierror.CodeBadRequest, "weight must be positive", true
+	case errors.Is(err, loot.ErrInvalidQuantityRange):
+		return fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid quantity range", true
+	}
+	return 0, "", "", false
+}
+
 // Helper function to convert db.LootTable to LootTableResponse
 func lootTableToResponse(lt *db.LootTable) LootTableResponse {
 	isActive := lt.IsActive == 1
@@ -101,10 +128,8 @@ func (h *LootTableHandlers) ListLootTables(c *fiber.Ctx) error {
 	ctx := c.Context()
 	tables, err := h.service.ListLootTables(ctx)
 	if err != nil {
-		h.logger.Error("failed to list loot tables", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to retrieve loot tables",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to list loot tables", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to retrieve loot tables")
 	}
 	responses := make([]LootTableResponse, len(tables))
 	for i, table := range tables {
@@ -121,21 +146,15 @@ func (h *LootTableHandlers) GetLootTable(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
 	}
 	table, err := h.service.GetLootTable(ctx, lootTableID)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableNotFound, "loot table not found")
 		}
-		h.logger.Error("failed to get loot table", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to retrieve loot table",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get loot table", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to retrieve loot table")
 	}
 	return c.JSON(lootTableToResponse(table))
 }
@@ -145,22 +164,16 @@ func (h *LootTableHandlers) CreateLootTable(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var req CreateLootTableRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	// Validate drop chance
 	if req.DropChance < 0 || req.DropChance > 1 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "drop_chance must be between 0 and 1",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "drop_chance must be between 0 and 1")
 	}
 	table, err := h.service.CreateLootTable(ctx, req.Name, req.Description, req.DropChance, req.IsActive)
 	if err != nil {
-		h.logger.Error("failed to create loot table", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create loot table",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to create loot table", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to create loot table")
 	}
 	return c.Status(fiber.StatusCreated).JSON(lootTableToResponse(table))
 }
@@ -171,57 +184,46 @@ func (h *LootTableHandlers) UpdateLootTable(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
 	}
 	var req UpdateLootTableRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	if req.DropChance < 0 || req.DropChance > 1 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "drop_chance must be between 0 and 1",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "drop_chance must be between 0 and 1")
 	}
 	err = h.service.UpdateLootTable(ctx, lootTableID, req.Name, req.Description, req.DropChance, req.IsActive)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableNotFound, "loot table not found")
 		}
-		h.logger.Error("failed to update loot table", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update loot table",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to update loot table", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to update loot table")
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // DeleteLootTable handles DELETE /admin/loot-tables/:id
+// With ?soft=true the table is only deactivated, keeping it and its entries.
 func (h *LootTableHandlers) DeleteLootTable(c *fiber.Ctx) error {
 	ctx := c.Context()
 	idStr := c.Params("id")
 	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
+	}
+	if c.QueryBool("soft") {
+		err = h.service.DeactivateLootTable(ctx, lootTableID)
+	} else {
+		err = h.service.DeleteLootTable(ctx, lootTableID)
 	}
-	err = h.service.DeleteLootTable(ctx, lootTableID)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableNotFound, "loot table not found")
 		}
-		h.logger.Error("failed to delete loot table", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete loot table",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to delete loot table", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to delete loot table")
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -232,16 +234,12 @@ func (h *LootTableHandlers) ListLootTableEntries(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
 	}
 	entries, err := h.service.GetLootTableEntriesByLootTableID(ctx, lootTableID)
 	if err != nil {
-		h.logger.Error("failed to list loot table entries", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to retrieve loot table entries",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to list loot table entries", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to retrieve loot table entries")
 	}
 	responses := make([]LootTableEntryResponse, len(entries))
 	for i, entry := range entries {
@@ -258,53 +256,126 @@ func (h *LootTableHandlers) CreateLootTableEntry(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
 	}
 	var req CreateLootTableEntryRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-	// Validate quantity range
-	if req.MinQuantity < 1 || req.MaxQuantity < req.MinQuantity {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid quantity range",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	entry, err := h.service.CreateLootTableEntry(ctx, lootTableID, req.CosmeticID, req.Weight, req.MinQuantity, req.MaxQuantity)
 	if err != nil {
-		h.logger.Error("failed to create loot table entry", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create loot table entry",
-		})
+		if status, code, message, ok := entryValidationError(err); ok {
+			return apierror.Respond(c, status, code, message)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to create loot table entry", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to create loot table entry")
 	}
 	return c.Status(fiber.StatusCreated).JSON(lootTableEntryToResponse(entry))
 }
 
+// BulkCreateLootTableEntries handles POST /admin/loot-tables/:id/entries/bulk
+func (h *LootTableHandlers) BulkCreateLootTableEntries(c *fiber.Ctx) error {
+	ctx := c.Context()
+	idStr := c.Params("id")
+	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
+	}
+	var req []CreateLootTableEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if len(req) == 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "no entries provided")
+	}
+	inputs := make([]loot.LootTableEntryInput, len(req))
+	for i, entry := range req {
+		inputs[i] = loot.LootTableEntryInput{
+			CosmeticID:  entry.CosmeticID,
+			Weight:      entry.Weight,
+			MinQuantity: entry.MinQuantity,
+			MaxQuantity: entry.MaxQuantity,
+		}
+	}
+	entries, err := h.service.CreateLootTableEntries(ctx, lootTableID, inputs)
+	if err != nil {
+		if errors.Is(err, loot.ErrLootTableNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableNotFound, "loot table not found")
+		}
+		var entryErr *loot.EntryError
+		if errors.As(err, &entryErr) {
+			if status, code, message, ok := entryValidationError(entryErr); ok {
+				return c.Status(status).JSON(fiber.Map{
+					"error": message,
+					"code":  code,
+					"index": entryErr.Index,
+				})
+			}
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to create loot table entries", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to create loot table entries")
+	}
+	responses := make([]LootTableEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = lootTableEntryToResponse(entry)
+	}
+	return c.Status(fiber.StatusCreated).JSON(responses)
+}
+
+// SimulateLootTable handles GET /admin/loot-tables/:id/simulate
+func (h *LootTableHandlers) SimulateLootTable(c *fiber.Ctx) error {
+	ctx := c.Context()
+	idStr := c.Params("id")
+	lootTableID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table ID")
+	}
+	// Parse rolls query parameter (default 10000, max 1000000)
+	rolls := c.QueryInt("rolls", 10000)
+	if rolls <= 0 {
+		rolls = 10000
+	}
+	if rolls > 1000000 {
+		rolls = 1000000
+	}
+	drops, err := h.service.SimulateLootTable(ctx, lootTableID, rolls)
+	if err != nil {
+		if errors.Is(err, loot.ErrLootTableNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableNotFound, "loot table not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to simulate loot table", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to simulate loot table")
+	}
+	resp := LootTableSimulationResponse{
+		LootTableID: lootTableID,
+		Rolls:       rolls,
+		Drops:       make([]SimulatedDropResponse, len(drops)),
+	}
+	for i, drop := range drops {
+		resp.Drops[i] = SimulatedDropResponse{
+			CosmeticID: drop.CosmeticID,
+			Count:      drop.Count,
+			Percentage: drop.Percentage,
+		}
+	}
+	return c.JSON(resp)
+}
+
 // GetLootTableEntry handles GET /admin/loot-tables/entries/:entryId
 func (h *LootTableHandlers) GetLootTableEntry(c *fiber.Ctx) error {
 	ctx := c.Context()
 	entryIDStr := c.Params("entryId")
 	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table entry ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table entry ID")
 	}
 	entry, err := h.service.GetLootTableEntry(ctx, entryID)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableEntryNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table entry not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableEntryNotFound, "loot table entry not found")
 		}
-		h.logger.Error("failed to get loot table entry", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to retrieve loot table entry",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get loot table entry", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to retrieve loot table entry")
 	}
 	return c.JSON(lootTableEntryToResponse(entry))
 }
@@ -315,32 +386,22 @@ func (h *LootTableHandlers) UpdateLootTableEntry(c *fiber.Ctx) error {
 	entryIDStr := c.Params("entryId")
 	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table entry ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table entry ID")
 	}
 	var req UpdateLootTableEntryRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-	if req.MinQuantity < 1 || req.MaxQuantity < req.MinQuantity {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid quantity range",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	err = h.service.UpdateLootTableEntry(ctx, entryID, req.LootTableID, req.CosmeticID, req.Weight, req.MinQuantity, req.MaxQuantity)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableEntryNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table entry not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableEntryNotFound, "loot table entry not found")
+		}
+		if status, code, message, ok := entryValidationError(err); ok {
+			return apierror.Respond(c, status, code, message)
 		}
-		h.logger.Error("failed to update loot table entry", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update loot table entry",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to update loot table entry", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to update loot table entry")
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -351,21 +412,15 @@ func (h *LootTableHandlers) DeleteLootTableEntry(c *fiber.Ctx) error {
 	entryIDStr := c.Params("entryId")
 	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid loot table entry ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loot table entry ID")
 	}
 	err = h.service.DeleteLootTableEntry(ctx, entryID)
 	if err != nil {
 		if errors.Is(err, loot.ErrLootTableEntryNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "loot table entry not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLootTableEntryNotFound, "loot table entry not found")
 		}
-		h.logger.Error("failed to delete loot table entry", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete loot table entry",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to delete loot table entry", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "failed to delete loot table entry")
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
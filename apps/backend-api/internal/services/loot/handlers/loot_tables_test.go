@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/services/loot"
+	"ai-zombie-defense/backend-api/internal/services/progression"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+func doJSON(t *testing.T, app *fiber.App, method, path, token string, payload interface{}) *http.Response {
+	t.Helper()
+	b, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request %s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+// setupAdmin creates an admin player, a loot table and count cosmetics.
+func setupAdmin(t *testing.T, db *sql.DB, count int) (string, int64, []int64) {
+	t.Helper()
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password123")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	res, err := db.Exec(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Bulk Crate', 1.0, 1)`)
+	if err != nil {
+		t.Fatalf("Failed to insert loot table: %v", err)
+	}
+	tableID, _ := res.LastInsertId()
+	var cosmeticIDs []int64
+	for i := 0; i < count; i++ {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, 'badge', 'common', 1, 0)`, fmt.Sprintf("Bulk Badge %d", i))
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		cosmeticIDs = append(cosmeticIDs, id)
+	}
+	return testutils.CreateTestAccessToken(t, db, adminID), tableID, cosmeticIDs
+}
+
+func TestLootTableHandlers_BulkCreateLootTableEntries(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+	token, tableID, cosmeticIDs := setupAdmin(t, db, 5)
+	path := fmt.Sprintf("/admin/loot-tables/%d/entries/bulk", tableID)
+
+	batch := func() []map[string]int64 {
+		entries := make([]map[string]int64, len(cosmeticIDs))
+		for i, id := range cosmeticIDs {
+			entries[i] = map[string]int64{"cosmetic_id": id, "weight": int64(10 * (i + 1)), "min_quantity": 1, "max_quantity": 3}
+		}
+		return entries
+	}
+	countEntries := func() int {
+		t.Helper()
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM loot_table_entries WHERE loot_table_id = ?`, tableID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count entries: %v", err)
+		}
+		return count
+	}
+
+	t.Run("invalid entry rolls back the batch", func(t *testing.T) {
+		entries := batch()
+		entries[3]["min_quantity"] = 4
+		resp := doJSON(t, app, http.MethodPost, path, token, entries)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+		}
+		var body struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+			Index int    `json:"index"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.Error != "invalid quantity range" || body.Code != "BAD_REQUEST" || body.Index != 3 {
+			t.Errorf("Expected invalid quantity range at index 3, got %+v", body)
+		}
+		if count := countEntries(); count != 0 {
+			t.Errorf("Expected no entries to be inserted, got %d", count)
+		}
+	})
+
+	t.Run("missing cosmetic", func(t *testing.T) {
+		entries := batch()
+		entries[4]["cosmetic_id"] = 9999
+		resp := doJSON(t, app, http.MethodPost, path, token, entries)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		if count := countEntries(); count != 0 {
+			t.Errorf("Expected no entries to be inserted, got %d", count)
+		}
+	})
+
+	t.Run("valid batch", func(t *testing.T) {
+		resp := doJSON(t, app, http.MethodPost, path, token, batch())
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+		var created []struct {
+			LootEntryID int64 `json:"loot_entry_id"`
+			LootTableID int64 `json:"loot_table_id"`
+			CosmeticID  int64 `json:"cosmetic_id"`
+			Weight      int64 `json:"weight"`
+		}
+		json.NewDecoder(resp.Body).Decode(&created)
+		if len(created) != len(cosmeticIDs) {
+			t.Fatalf("Expected %d created entries, got %d", len(cosmeticIDs), len(created))
+		}
+		for i, entry := range created {
+			if entry.LootEntryID == 0 || entry.LootTableID != tableID || entry.CosmeticID != cosmeticIDs[i] || entry.Weight != int64(10*(i+1)) {
+				t.Errorf("Unexpected created entry %d: %+v", i, entry)
+			}
+		}
+		if count := countEntries(); count != len(cosmeticIDs) {
+			t.Errorf("Expected %d entries, got %d", len(cosmeticIDs), count)
+		}
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		resp := doJSON(t, app, http.MethodPost, "/admin/loot-tables/9999/entries/bulk", token, batch())
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestLootTableHandlers_SimulateLootTable(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+	token, tableID, cosmeticIDs := setupAdmin(t, db, 2)
+	for i, weight := range []int64{25, 75} {
+		if _, err := db.Exec(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, ?)`, tableID, cosmeticIDs[i], weight); err != nil {
+			t.Fatalf("Failed to insert entry: %v", err)
+		}
+	}
+
+	resp := doJSON(t, app, http.MethodGet, fmt.Sprintf("/admin/loot-tables/%d/simulate?rolls=8000", tableID), token, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Rolls int `json:"rolls"`
+		Drops []struct {
+			CosmeticID int64   `json:"cosmetic_id"`
+			Count      int64   `json:"count"`
+			Percentage float64 `json:"percentage"`
+		} `json:"drops"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.Rolls != 8000 || len(body.Drops) != 2 {
+		t.Fatalf("Unexpected simulation: %+v", body)
+	}
+	for i, want := range []float64{25, 75} {
+		if got := body.Drops[i].Percentage; got < want-2 || got > want+2 {
+			t.Errorf("Expected cosmetic %d near %.0f%%, got %.2f%%", body.Drops[i].CosmeticID, want, got)
+		}
+	}
+
+	var granted int
+	db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics`).Scan(&granted)
+	if granted != 0 {
+		t.Errorf("Expected simulation not to grant anything, got %d cosmetics", granted)
+	}
+
+	resp = doJSON(t, app, http.MethodGet, "/admin/loot-tables/9999/simulate", token, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown table, got %d", resp.StatusCode)
+	}
+}
+
+func TestLootTableHandlers_SoftDeleteLootTable(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+	token, tableID, cosmeticIDs := setupAdmin(t, db, 1)
+	if _, err := db.Exec(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, tableID, cosmeticIDs[0]); err != nil {
+		t.Fatalf("Failed to insert entry: %v", err)
+	}
+
+	resp := doJSON(t, app, http.MethodDelete, fmt.Sprintf("/admin/loot-tables/%d?soft=true", tableID), token, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", resp.StatusCode)
+	}
+
+	// Still fetchable by ID, but inactive and with its entries intact
+	resp = doJSON(t, app, http.MethodGet, fmt.Sprintf("/admin/loot-tables/%d", tableID), token, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected soft-deleted table to be fetchable, got %d", resp.StatusCode)
+	}
+	var table struct {
+		IsActive bool `json:"is_active"`
+	}
+	json.NewDecoder(resp.Body).Decode(&table)
+	if table.IsActive {
+		t.Errorf("Expected soft-deleted table to be inactive")
+	}
+	var entries int
+	db.QueryRow(`SELECT COUNT(*) FROM loot_table_entries WHERE loot_table_id = ?`, tableID).Scan(&entries)
+	if entries != 1 {
+		t.Errorf("Expected entries to be kept, got %d", entries)
+	}
+
+	// Hidden from the active tables, so drops never use it
+	cfg := testutils.GetTestConfig()
+	lootSvc := loot.NewLootService(cfg, zaptest.NewLogger(t), db, progression.NewProgressionService(cfg, zaptest.NewLogger(t), db), loot.NewRoller(1))
+	active, err := lootSvc.ListActiveLootTables(context.Background())
+	if err != nil {
+		t.Fatalf("ListActiveLootTables failed: %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("Expected no active loot tables, got %d", len(active))
+	}
+	resp = doJSON(t, app, http.MethodPost, "/loot/drop", token, nil)
+	if resp.StatusCode == http.StatusOK {
+		t.Errorf("Expected no drop from a soft-deleted table")
+	}
+
+	resp = doJSON(t, app, http.MethodDelete, "/admin/loot-tables/9999?soft=true", token, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown table, got %d", resp.StatusCode)
+	}
+}
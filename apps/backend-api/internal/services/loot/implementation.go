@@ -2,30 +2,42 @@ package loot
 
 import (
 	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/metrics"
+	"ai-zombie-defense/backend-api/internal/services/progression"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	randmath "math/rand"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type lootService struct {
-	config  config.Config
-	logger  *zap.Logger
-	dbConn  db.DBTX
-	queries *db.Queries
+	config         config.Config
+	logger         *zap.Logger
+	dbConn         db.DBTX
+	queries        *db.Queries
+	progressionSvc progression.Service
+	roller         Roller
 }
 
-func NewLootService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX) Service {
+// NewLootService creates a loot service. A nil roller defaults to one seeded
+// from the current time; tests pass NewRoller with a fixed seed.
+func NewLootService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX, progressionSvc progression.Service, roller Roller) Service {
+	if roller == nil {
+		roller = NewRoller(time.Now().UnixNano())
+	}
 	return &lootService{
-		config:  cfg,
-		logger:  logger,
-		dbConn:  dbConn,
-		queries: db.New(),
+		config:         cfg,
+		logger:         logger,
+		dbConn:         dbConn,
+		queries:        db.New(),
+		progressionSvc: progressionSvc,
+		roller:         roller,
 	}
 }
 
@@ -99,7 +111,37 @@ func (s *lootService) DeleteLootTable(ctx context.Context, lootTableID int64) er
 	return nil
 }
 
+// validateEntry rejects entries that could never drop: empty quantity ranges,
+// non-positive weights and cosmetics that don't exist, which would otherwise
+// surface as a FK error.
+func (s *lootService) validateEntry(ctx context.Context, dbTx db.DBTX, cosmeticID int64, weight int64, minQuantity int64, maxQuantity int64) error {
+	if minQuantity < 1 || maxQuantity < minQuantity {
+		return ErrInvalidQuantityRange
+	}
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+	if _, err := s.queries.GetCosmeticItem(ctx, dbTx, cosmeticID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCosmeticNotFound
+		}
+		return fmt.Errorf("failed to get cosmetic item: %w", err)
+	}
+	return nil
+}
+
+func (s *lootService) DeactivateLootTable(ctx context.Context, lootTableID int64) error {
+	table, err := s.GetLootTable(ctx, lootTableID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateLootTable(ctx, lootTableID, table.Name, table.Description, table.DropChance, false)
+}
+
 func (s *lootService) CreateLootTableEntry(ctx context.Context, lootTableID int64, cosmeticID int64, weight int64, minQuantity int64, maxQuantity int64) (*db.LootTableEntry, error) {
+	if err := s.validateEntry(ctx, s.dbConn, cosmeticID, weight, minQuantity, maxQuantity); err != nil {
+		return nil, err
+	}
 	params := &db.CreateLootTableEntryParams{
 		LootTableID: lootTableID,
 		CosmeticID:  cosmeticID,
@@ -110,6 +152,41 @@ func (s *lootService) CreateLootTableEntry(ctx context.Context, lootTableID int6
 	return s.queries.CreateLootTableEntry(ctx, s.dbConn, params)
 }
 
+func (s *lootService) CreateLootTableEntries(ctx context.Context, lootTableID int64, entries []LootTableEntryInput) ([]*db.LootTableEntry, error) {
+	var created []*db.LootTableEntry
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if _, err := s.queries.GetLootTable(ctx, tx, lootTableID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrLootTableNotFound
+			}
+			return fmt.Errorf("failed to get loot table: %w", err)
+		}
+
+		created = make([]*db.LootTableEntry, 0, len(entries))
+		for i, entry := range entries {
+			if err := s.validateEntry(ctx, tx, entry.CosmeticID, entry.Weight, entry.MinQuantity, entry.MaxQuantity); err != nil {
+				return &EntryError{Index: i, Err: err}
+			}
+			row, err := s.queries.CreateLootTableEntry(ctx, tx, &db.CreateLootTableEntryParams{
+				LootTableID: lootTableID,
+				CosmeticID:  entry.CosmeticID,
+				Weight:      entry.Weight,
+				MinQuantity: entry.MinQuantity,
+				MaxQuantity: entry.MaxQuantity,
+			})
+			if err != nil {
+				return &EntryError{Index: i, Err: fmt.Errorf("failed to create loot table entry: %w", err)}
+			}
+			created = append(created, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
 func (s *lootService) GetLootTableEntry(ctx context.Context, lootEntryID int64) (*db.LootTableEntry, error) {
 	entry, err := s.queries.GetLootTableEntry(ctx, s.dbConn, lootEntryID)
 	if err != nil {
@@ -130,6 +207,9 @@ func (s *lootService) GetLootTableEntriesWithCosmeticDetails(ctx context.Context
 }
 
 func (s *lootService) UpdateLootTableEntry(ctx context.Context, lootEntryID int64, lootTableID int64, cosmeticID int64, weight int64, minQuantity int64, maxQuantity int64) error {
+	if err := s.validateEntry(ctx, s.dbConn, cosmeticID, weight, minQuantity, maxQuantity); err != nil {
+		return err
+	}
 	params := &db.UpdateLootTableEntryParams{
 		LootEntryID: lootEntryID,
 		LootTableID: lootTableID,
@@ -159,28 +239,151 @@ func (s *lootService) DeleteLootTableEntry(ctx context.Context, lootEntryID int6
 	return nil
 }
 
-func (s *lootService) GenerateLootDrop(ctx context.Context, playerID int64) (*db.CosmeticItem, error) {
-	tables, err := s.ListActiveLootTables(ctx)
+func (s *lootService) GenerateLootDrop(ctx context.Context, playerID int64) (*LootDropResult, error) {
+	var result *LootDropResult
+	var noDrop bool
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		tables, err := s.queries.ListActiveLootTables(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get active loot tables: %w", err)
+		}
+		if len(tables) == 0 {
+			return errors.New("no active loot tables")
+		}
+
+		unluckyRolls, err := s.queries.GetLootPity(ctx, tx, playerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get loot pity: %w", err)
+		}
+
+		pityThreshold := int64(s.config.Loot.PityThreshold)
+		minRank := progression.RarityRank[s.config.Loot.PityMinRarity]
+
+		var entry *db.LootTableEntry
+		if pityThreshold > 0 && unluckyRolls >= pityThreshold {
+			entry, err = s.rollPityDrop(ctx, tx, tables, minRank)
+			if err != nil {
+				return err
+			}
+		}
+		if entry == nil {
+			entry, err = s.rollDrop(ctx, tx, tables)
+			if err != nil {
+				return err
+			}
+		}
+		if entry == nil {
+			// The unlucky roll still counts towards pity, so it is committed
+			if err := s.setLootPity(ctx, tx, playerID, unluckyRolls+1); err != nil {
+				return err
+			}
+			noDrop = true
+			return nil
+		}
+
+		result = &LootDropResult{
+			Quantity:    s.rollQuantity(entry),
+			LootTableID: entry.LootTableID,
+		}
+
+		err = s.queries.GrantCosmeticToPlayer(ctx, tx, &db.GrantCosmeticToPlayerParams{
+			PlayerID:    playerID,
+			CosmeticID:  entry.CosmeticID,
+			UnlockedVia: "loot_drop",
+		})
+		if err != nil {
+			if !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return fmt.Errorf("failed to grant cosmetic: %w", err)
+			}
+			logging.FromContext(ctx, s.logger).Debug("player already owns cosmetic", zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", entry.CosmeticID))
+			result.WasDuplicate = true
+		}
+
+		cosmetic, err := s.queries.GetCosmeticItem(ctx, tx, entry.CosmeticID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New("cosmetic not found")
+			}
+			return fmt.Errorf("failed to get cosmetic item: %w", err)
+		}
+		result.Cosmetic = cosmetic
+
+		// Duplicates are converted to data currency in the same transaction as the drop
+		if result.WasDuplicate {
+			result.RefundedData = s.config.Loot.DuplicateLootRefund[cosmetic.Rarity] * result.Quantity
+			err := s.progressionSvc.AddDataCurrencyWithTransaction(ctx, tx, playerID, result.RefundedData, "refund", &cosmetic.CosmeticID)
+			if err != nil {
+				return fmt.Errorf("failed to refund duplicate: %w", err)
+			}
+		}
+
+		// A drop at or above the pity rarity resets the counter; anything else counts as unlucky.
+		nextRolls := unluckyRolls + 1
+		if progression.RarityRank[cosmetic.Rarity] >= minRank {
+			nextRolls = 0
+		}
+		if err := s.setLootPity(ctx, tx, playerID, nextRolls); err != nil {
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active loot tables: %w", err)
+		return nil, err
 	}
-	if len(tables) == 0 {
-		return nil, errors.New("no active loot tables")
+	if noDrop {
+		return nil, errors.New("no drop from any loot table")
 	}
 
-	var selectedTable *db.LootTable
+	metrics.LootDrops.Inc(result.Cosmetic.Rarity)
+	return result, nil
+}
+
+// rollQuantity picks a quantity uniformly within the entry's inclusive range.
+func (s *lootService) rollQuantity(entry *db.LootTableEntry) int64 {
+	if entry.MaxQuantity <= entry.MinQuantity {
+		return entry.MinQuantity
+	}
+	return entry.MinQuantity + s.roller.Int63n(entry.MaxQuantity-entry.MinQuantity+1)
+}
+
+// tableChances turns the tables' drop chances into one probability
+// distribution, indexed like tables, so table order never matters. While the
+// chances add up to at most 1 each table drops with exactly its drop_chance and
+// the remainder is the "no drop" outcome. Beyond 1, or with Loot.GuaranteedDrop,
+// the chances are scaled to sum to 1 and every roll drops.
+func (s *lootService) tableChances(tables []*db.LootTable) []float64 {
+	var total float64
 	for _, table := range tables {
-		roll := randmath.Float64()
-		if roll < table.DropChance {
-			selectedTable = table
+		total += max(table.DropChance, 0)
+	}
+	chances := make([]float64, len(tables))
+	for i, table := range tables {
+		chances[i] = max(table.DropChance, 0)
+		if total > 0 && (total > 1 || s.config.Loot.GuaranteedDrop) {
+			chances[i] /= total
+		}
+	}
+	return chances
+}
+
+// rollDrop picks an active table with a single roll against tableChances and
+// then a weighted entry from it. It returns nil when the roll lands on "no drop".
+func (s *lootService) rollDrop(ctx context.Context, dbTx db.DBTX, tables []*db.LootTable) (*db.LootTableEntry, error) {
+	var selectedTable *db.LootTable
+	roll := s.roller.Float64()
+	var cumulative float64
+	for i, chance := range s.tableChances(tables) {
+		cumulative += chance
+		if roll < cumulative {
+			selectedTable = tables[i]
 			break
 		}
 	}
 	if selectedTable == nil {
-		return nil, errors.New("no drop from any loot table")
+		return nil, nil
 	}
 
-	entries, err := s.GetLootTableEntriesByLootTableID(ctx, selectedTable.LootTableID)
+	entries, err := s.queries.GetLootTableEntriesByLootTableID(ctx, dbTx, selectedTable.LootTableID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get loot table entries: %w", err)
 	}
@@ -188,48 +391,176 @@ func (s *lootService) GenerateLootDrop(ctx context.Context, playerID int64) (*db
 		return nil, errors.New("loot table has no entries")
 	}
 
+	entry := pickWeighted(entries, s.roller)
+	if entry == nil {
+		return nil, errors.New("total weight must be positive")
+	}
+	return entry, nil
+}
+
+// pickWeighted picks an entry with probability proportional to its weight. It
+// returns nil when the weights don't add up to a positive total.
+func pickWeighted(entries []*db.LootTableEntry, roller Roller) *db.LootTableEntry {
 	var totalWeight int64
 	for _, entry := range entries {
 		totalWeight += entry.Weight
 	}
 	if totalWeight <= 0 {
-		return nil, errors.New("total weight must be positive")
+		return nil
 	}
 
-	randomWeight := randmath.Int63n(totalWeight)
-	var selectedEntry *db.LootTableEntry
+	randomWeight := roller.Int63n(totalWeight)
 	var cumulativeWeight int64
 	for _, entry := range entries {
 		cumulativeWeight += entry.Weight
 		if randomWeight < cumulativeWeight {
-			selectedEntry = entry
-			break
+			return entry
+		}
+	}
+	return entries[len(entries)-1]
+}
+
+// simulationSeed keeps SimulateLootTable reproducible for the same table and rolls.
+const simulationSeed = 1
+
+func (s *lootService) SimulateLootTable(ctx context.Context, lootTableID int64, rolls int) ([]*SimulatedDrop, error) {
+	if _, err := s.GetLootTable(ctx, lootTableID); err != nil {
+		return nil, err
+	}
+	entries, err := s.queries.GetLootTableEntriesByLootTableID(ctx, s.dbConn, lootTableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loot table entries: %w", err)
+	}
+	return SimulateEntries(entries, rolls, NewRoller(simulationSeed)), nil
+}
+
+// SimulateEntries picks from entries rolls times using the same weighted
+// selection as GenerateLootDrop and tallies the results per cosmetic, in the
+// order each cosmetic first appears in entries.
+func SimulateEntries(entries []*db.LootTableEntry, rolls int, roller Roller) []*SimulatedDrop {
+	drops := make([]*SimulatedDrop, 0, len(entries))
+	byCosmetic := make(map[int64]*SimulatedDrop, len(entries))
+	for _, entry := range entries {
+		if _, ok := byCosmetic[entry.CosmeticID]; !ok {
+			drop := &SimulatedDrop{CosmeticID: entry.CosmeticID}
+			byCosmetic[entry.CosmeticID] = drop
+			drops = append(drops, drop)
+		}
+	}
+	for i := 0; i < rolls; i++ {
+		if entry := pickWeighted(entries, roller); entry != nil {
+			byCosmetic[entry.CosmeticID].Count++
+		}
+	}
+	if rolls > 0 {
+		for _, drop := range drops {
+			drop.Percentage = float64(drop.Count) / float64(rolls) * 100
+		}
+	}
+	return drops
+}
+
+// rollPityDrop picks a weighted entry of at least minRank rarity across all
+// active tables, ignoring drop chances. It returns nil when no such entry exists.
+func (s *lootService) rollPityDrop(ctx context.Context, dbTx db.DBTX, tables []*db.LootTable, minRank int) (*db.LootTableEntry, error) {
+	var candidates []*db.GetLootTableEntriesWithCosmeticDetailsRow
+	var totalWeight int64
+	for _, table := range tables {
+		entries, err := s.queries.GetLootTableEntriesWithCosmeticDetails(ctx, dbTx, table.LootTableID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loot table entries: %w", err)
 		}
+		for _, entry := range entries {
+			if entry.Weight > 0 && progression.RarityRank[entry.CosmeticRarity] >= minRank {
+				candidates = append(candidates, entry)
+				totalWeight += entry.Weight
+			}
+		}
+	}
+	if totalWeight == 0 {
+		return nil, nil
 	}
-	if selectedEntry == nil {
-		selectedEntry = entries[len(entries)-1]
+
+	selected := candidates[len(candidates)-1]
+	randomWeight := s.roller.Int63n(totalWeight)
+	var cumulativeWeight int64
+	for _, entry := range candidates {
+		cumulativeWeight += entry.Weight
+		if randomWeight < cumulativeWeight {
+			selected = entry
+			break
+		}
 	}
+	return &db.LootTableEntry{
+		LootEntryID: selected.LootEntryID,
+		LootTableID: selected.LootTableID,
+		CosmeticID:  selected.CosmeticID,
+		Weight:      selected.Weight,
+		MinQuantity: selected.MinQuantity,
+		MaxQuantity: selected.MaxQuantity,
+	}, nil
+}
 
-	err = s.queries.GrantCosmeticToPlayer(ctx, s.dbConn, &db.GrantCosmeticToPlayerParams{
-		PlayerID:    playerID,
-		CosmeticID:  selectedEntry.CosmeticID,
-		UnlockedVia: "loot_drop",
+func (s *lootService) setLootPity(ctx context.Context, dbTx db.DBTX, playerID, unluckyRolls int64) error {
+	err := s.queries.SetLootPity(ctx, dbTx, &db.SetLootPityParams{
+		PlayerID:     playerID,
+		UnluckyRolls: unluckyRolls,
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			s.logger.Debug("player already owns cosmetic", zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", selectedEntry.CosmeticID))
-		} else {
-			return nil, fmt.Errorf("failed to grant cosmetic: %w", err)
-		}
+		return fmt.Errorf("failed to update loot pity: %w", err)
 	}
+	return nil
+}
 
-	cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, selectedEntry.CosmeticID)
+// GetCosmeticSources lists every way the cosmetic can be obtained right now.
+// The result is empty, not nil, for cosmetics that are currently unobtainable.
+func (s *lootService) GetCosmeticSources(ctx context.Context, cosmeticID int64) ([]*CosmeticSource, error) {
+	cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, cosmeticID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("cosmetic not found")
+			return nil, ErrCosmeticNotFound
 		}
 		return nil, fmt.Errorf("failed to get cosmetic item: %w", err)
 	}
 
-	return cosmetic, nil
+	sources := []*CosmeticSource{}
+	if cosmetic.IsPrestigeOnly == 1 {
+		// Prestige cosmetics store the required prestige level in unlock_level
+		sources = append(sources, &CosmeticSource{Type: SourcePrestige, Level: cosmetic.UnlockLevel})
+	} else {
+		if cosmetic.DataCost > 0 {
+			sources = append(sources, &CosmeticSource{Type: SourcePurchase, DataCost: cosmetic.DataCost})
+		}
+		if cosmetic.UnlockLevel > 1 {
+			sources = append(sources, &CosmeticSource{Type: SourceLevelUp, Level: cosmetic.UnlockLevel})
+		}
+	}
+
+	tables, err := s.ListActiveLootTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active loot tables: %w", err)
+	}
+	chances := s.tableChances(tables)
+	for i, table := range tables {
+		entries, err := s.GetLootTableEntriesByLootTableID(ctx, table.LootTableID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loot table entries: %w", err)
+		}
+		var totalWeight, cosmeticWeight int64
+		for _, entry := range entries {
+			totalWeight += entry.Weight
+			if entry.CosmeticID == cosmeticID {
+				cosmeticWeight += entry.Weight
+			}
+		}
+		if cosmeticWeight > 0 && totalWeight > 0 {
+			sources = append(sources, &CosmeticSource{
+				Type:          SourceLootDrop,
+				LootTableID:   table.LootTableID,
+				LootTableName: table.Name,
+				DropChance:    chances[i] * float64(cosmeticWeight) / float64(totalWeight),
+			})
+		}
+	}
+	return sources, nil
 }
@@ -4,13 +4,120 @@ import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
 )
 
 var (
 	ErrLootTableNotFound      = errors.New("loot table not found")
 	ErrLootTableEntryNotFound = errors.New("loot table entry not found")
+	ErrCosmeticNotFound       = errors.New("cosmetic not found")
+	ErrInvalidWeight          = errors.New("weight must be positive")
+	ErrInvalidQuantityRange   = errors.New("invalid quantity range")
 )
 
+// Cosmetic source types, named after the matching player_cosmetics.unlocked_via values.
+const (
+	SourcePurchase = "purchase"
+	SourceLootDrop = "loot_drop"
+	SourceLevelUp  = "level_up"
+	SourcePrestige = "prestige"
+)
+
+// Roller is the source of randomness for loot drops. *rand.Rand satisfies it;
+// implementations must be safe for concurrent use when shared across requests.
+type Roller interface {
+	// Float64 returns a number in [0.0, 1.0).
+	Float64() float64
+	// Int63n returns a number in [0, n). It panics if n <= 0.
+	Int63n(n int64) int64
+}
+
+// lockedRoller guards a *rand.Rand, which is not safe for concurrent use.
+type lockedRoller struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRoller returns a concurrency-safe Roller seeded with seed, so the same
+// seed always produces the same sequence of rolls.
+func NewRoller(seed int64) Roller {
+	return &lockedRoller{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (r *lockedRoller) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+func (r *lockedRoller) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Int63n(n)
+}
+
+// CosmeticSource describes one way a player can currently obtain a cosmetic.
+// Only the fields relevant to Type are set.
+type CosmeticSource struct {
+	Type string
+	// DataCost is the purchase price for SourcePurchase.
+	DataCost int64
+	// LootTableID, LootTableName and DropChance describe a SourceLootDrop.
+	// DropChance is the probability that a single loot drop yields the
+	// cosmetic from this table.
+	LootTableID   int64
+	LootTableName string
+	DropChance    float64
+	// Level is the player level for SourceLevelUp and the prestige level for SourcePrestige.
+	Level int64
+}
+
+// LootTableEntryInput is one entry of a bulk loot table entry creation.
+type LootTableEntryInput struct {
+	CosmeticID  int64
+	Weight      int64
+	MinQuantity int64
+	MaxQuantity int64
+}
+
+// EntryError reports which entry of a bulk creation was rejected; Err is one
+// of the entry validation errors or a database error.
+type EntryError struct {
+	Index int
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("entry %d: %v", e.Index, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// SimulatedDrop is one cosmetic's share of a loot table simulation.
+type SimulatedDrop struct {
+	CosmeticID int64
+	Count      int64
+	// Percentage is Count as a percentage of all rolls.
+	Percentage float64
+}
+
+// LootDropResult is the outcome of a single loot drop.
+type LootDropResult struct {
+	Cosmetic *db.CosmeticItem
+	// Quantity is rolled uniformly within the entry's [min_quantity, max_quantity].
+	Quantity    int64
+	LootTableID int64
+	// WasDuplicate is set when the player already owned the cosmetic, in which
+	// case RefundedData is what they were credited instead (Loot.DuplicateLootRefund
+	// for the cosmetic's rarity, per unit).
+	WasDuplicate bool
+	RefundedData int64
+}
+
 type Service interface {
 	CreateLootTable(ctx context.Context, name string, description *string, dropChance float64, isActive bool) (*db.LootTable, error)
 	GetLootTable(ctx context.Context, lootTableID int64) (*db.LootTable, error)
@@ -18,11 +125,20 @@ type Service interface {
 	ListActiveLootTables(ctx context.Context) ([]*db.LootTable, error)
 	UpdateLootTable(ctx context.Context, lootTableID int64, name string, description *string, dropChance float64, isActive bool) error
 	DeleteLootTable(ctx context.Context, lootTableID int64) error
+	// DeactivateLootTable retires a table by clearing is_active, keeping the row and its entries.
+	DeactivateLootTable(ctx context.Context, lootTableID int64) error
 	CreateLootTableEntry(ctx context.Context, lootTableID int64, cosmeticID int64, weight int64, minQuantity int64, maxQuantity int64) (*db.LootTableEntry, error)
+	// CreateLootTableEntries inserts all entries in one transaction; if any entry is
+	// invalid nothing is inserted and the error is an *EntryError naming it.
+	CreateLootTableEntries(ctx context.Context, lootTableID int64, entries []LootTableEntryInput) ([]*db.LootTableEntry, error)
 	GetLootTableEntry(ctx context.Context, lootEntryID int64) (*db.LootTableEntry, error)
 	GetLootTableEntriesByLootTableID(ctx context.Context, lootTableID int64) ([]*db.LootTableEntry, error)
 	GetLootTableEntriesWithCosmeticDetails(ctx context.Context, lootTableID int64) ([]*db.GetLootTableEntriesWithCosmeticDetailsRow, error)
 	UpdateLootTableEntry(ctx context.Context, lootEntryID int64, lootTableID int64, cosmeticID int64, weight int64, minQuantity int64, maxQuantity int64) error
 	DeleteLootTableEntry(ctx context.Context, lootEntryID int64) error
-	GenerateLootDrop(ctx context.Context, playerID int64) (*db.CosmeticItem, error)
+	GenerateLootDrop(ctx context.Context, playerID int64) (*LootDropResult, error)
+	// SimulateLootTable runs the table's weighted entry selection rolls times with a
+	// fixed-seed roller, assuming the table itself was picked. Nothing is granted.
+	SimulateLootTable(ctx context.Context, lootTableID int64, rolls int) ([]*SimulatedDrop, error)
+	GetCosmeticSources(ctx context.Context, cosmeticID int64) ([]*CosmeticSource, error)
 }
@@ -3,9 +3,14 @@ package loot_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
+	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/services/loot"
+	"ai-zombie-defense/backend-api/internal/services/progression"
 	"ai-zombie-defense/backend-api/pkg/config"
 
 	"go.uber.org/zap/zaptest"
@@ -45,7 +50,10 @@ func setupTestDB(t *testing.T) *sql.DB {
 		unlock_level INTEGER NOT NULL DEFAULT 1,
 		data_cost INTEGER NOT NULL DEFAULT 0,
 		is_prestige_only INTEGER NOT NULL DEFAULT 0,
-		created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+		created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+		max_per_day INTEGER,
+		available_until TEXT,
+		equip_restriction TEXT
 	);`
 	if _, err := db.Exec(createCosmeticItemsSQL); err != nil {
 		t.Fatalf("Failed to create cosmetic_items table: %v", err)
@@ -86,6 +94,46 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if _, err := db.Exec(createPlayerCosmeticsSQL); err != nil {
 		t.Fatalf("Failed to create player_cosmetics table: %v", err)
 	}
+	createLootPitySQL := `CREATE TABLE loot_pity (
+		player_id INTEGER PRIMARY KEY,
+		unlucky_rolls INTEGER NOT NULL DEFAULT 0,
+		updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+		FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+	);`
+	if _, err := db.Exec(createLootPitySQL); err != nil {
+		t.Fatalf("Failed to create loot_pity table: %v", err)
+	}
+	createPlayerProgressionSQL := `CREATE TABLE player_progression (
+		player_id INTEGER PRIMARY KEY,
+		level INTEGER NOT NULL DEFAULT 1,
+		experience INTEGER NOT NULL DEFAULT 0,
+		prestige_level INTEGER NOT NULL DEFAULT 0,
+		data_currency INTEGER NOT NULL DEFAULT 0,
+		total_matches_played INTEGER NOT NULL DEFAULT 0,
+		total_waves_survived INTEGER NOT NULL DEFAULT 0,
+		total_kills INTEGER NOT NULL DEFAULT 0,
+		total_deaths INTEGER NOT NULL DEFAULT 0,
+		total_scrap_earned INTEGER NOT NULL DEFAULT 0,
+		total_data_earned INTEGER NOT NULL DEFAULT 0,
+		updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+		FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+	);`
+	if _, err := db.Exec(createPlayerProgressionSQL); err != nil {
+		t.Fatalf("Failed to create player_progression table: %v", err)
+	}
+	createTransactionsSQL := `CREATE TABLE currency_transactions (
+		transaction_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		player_id INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		balance_after INTEGER NOT NULL,
+		transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'bundle_purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+		reference_id INTEGER,
+		created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+		FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+	);`
+	if _, err := db.Exec(createTransactionsSQL); err != nil {
+		t.Fatalf("Failed to create currency_transactions table: %v", err)
+	}
 	return db
 }
 
@@ -95,7 +143,7 @@ func TestLootService_GenerateLootDrop(t *testing.T) {
 	defer dbConn.Close()
 
 	cfg := config.Config{}
-	service := loot.NewLootService(cfg, logger, dbConn)
+	service := loot.NewLootService(cfg, logger, dbConn, progression.NewProgressionService(cfg, logger, dbConn), loot.NewRoller(1))
 
 	ctx := context.Background()
 
@@ -143,12 +191,441 @@ func TestLootService_GenerateLootDrop(t *testing.T) {
 	}
 
 	// Generate loot drop
-	cosmetic, err := service.GenerateLootDrop(ctx, playerID)
+	drop, err := service.GenerateLootDrop(ctx, playerID)
 	if err != nil {
 		t.Fatalf("GenerateLootDrop failed: %v", err)
 	}
 
-	if cosmetic.CosmeticID != cosmeticID {
-		t.Errorf("Expected cosmetic ID %d, got %d", cosmeticID, cosmetic.CosmeticID)
+	if drop.Cosmetic.CosmeticID != cosmeticID {
+		t.Errorf("Expected cosmetic ID %d, got %d", cosmeticID, drop.Cosmetic.CosmeticID)
+	}
+}
+
+func TestLootService_GenerateLootDropQuantityAndDuplicates(t *testing.T) {
+	setup := func(t *testing.T, cfg config.Config) (*sql.DB, loot.Service, int64, int64, int64) {
+		dbConn := setupTestDB(t)
+		service := loot.NewLootService(cfg, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(cfg, zaptest.NewLogger(t), dbConn), loot.NewRoller(1))
+		insert := func(query string, args ...any) int64 {
+			t.Helper()
+			res, err := dbConn.Exec(query, args...)
+			if err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+			id, _ := res.LastInsertId()
+			return id
+		}
+		playerID := insert(`INSERT INTO players (username, email, password_hash) VALUES ('looter', 'looter@example.com', 'hash')`)
+		cosmeticID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Scrap Pile', 'other', 'common', 1, 0)`)
+		tableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Scrap Crate', 1.0, 1)`)
+		insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight, min_quantity, max_quantity) VALUES (?, ?, 100, 2, 5)`, tableID, cosmeticID)
+		return dbConn, service, playerID, cosmeticID, tableID
+	}
+	ctx := context.Background()
+
+	t.Run("quantity within range", func(t *testing.T) {
+		dbConn, service, playerID, cosmeticID, tableID := setup(t, config.Config{})
+		defer dbConn.Close()
+		seen := map[int64]bool{}
+		for i := 0; i < 200; i++ {
+			drop, err := service.GenerateLootDrop(ctx, playerID)
+			if err != nil {
+				t.Fatalf("GenerateLootDrop failed: %v", err)
+			}
+			if drop.Cosmetic.CosmeticID != cosmeticID || drop.LootTableID != tableID {
+				t.Fatalf("Expected cosmetic %d from table %d, got %+v", cosmeticID, tableID, drop)
+			}
+			if drop.Quantity < 2 || drop.Quantity > 5 {
+				t.Fatalf("Expected quantity in [2, 5], got %d", drop.Quantity)
+			}
+			if drop.WasDuplicate != (i > 0) {
+				t.Fatalf("Expected only drops after the first to be duplicates, drop %d was_duplicate=%v", i, drop.WasDuplicate)
+			}
+			seen[drop.Quantity] = true
+		}
+		if !seen[2] || !seen[5] {
+			t.Errorf("Expected both range bounds to be rolled, got %v", seen)
+		}
+	})
+
+	t.Run("duplicate converted to data", func(t *testing.T) {
+		dbConn, service, playerID, cosmeticID, _ := setup(t, config.Config{Loot: config.LootConfig{DuplicateLootRefund: map[string]int64{"common": 10}}})
+		defer dbConn.Close()
+		if _, err := dbConn.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, playerID, cosmeticID); err != nil {
+			t.Fatalf("Failed to grant cosmetic: %v", err)
+		}
+
+		drop, err := service.GenerateLootDrop(ctx, playerID)
+		if err != nil {
+			t.Fatalf("GenerateLootDrop failed: %v", err)
+		}
+		if !drop.WasDuplicate {
+			t.Fatalf("Expected drop to be a duplicate")
+		}
+		if drop.RefundedData != 10*drop.Quantity {
+			t.Errorf("Expected refund of %d, got %d", 10*drop.Quantity, drop.RefundedData)
+		}
+		var balance, refunded int64
+		if err := dbConn.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&balance); err != nil {
+			t.Fatalf("Failed to read balance: %v", err)
+		}
+		if err := dbConn.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM currency_transactions WHERE player_id = ? AND transaction_type = 'refund' AND reference_id = ?`, playerID, cosmeticID).Scan(&refunded); err != nil {
+			t.Fatalf("Failed to read transactions: %v", err)
+		}
+		if balance != drop.RefundedData || refunded != drop.RefundedData {
+			t.Errorf("Expected balance and refund transaction of %d, got %d and %d", drop.RefundedData, balance, refunded)
+		}
+	})
+
+	t.Run("duplicate without refund for rarity", func(t *testing.T) {
+		dbConn, service, playerID, cosmeticID, _ := setup(t, config.Config{Loot: config.LootConfig{DuplicateLootRefund: map[string]int64{"rare": 25}}})
+		defer dbConn.Close()
+		if _, err := dbConn.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, playerID, cosmeticID); err != nil {
+			t.Fatalf("Failed to grant cosmetic: %v", err)
+		}
+
+		drop, err := service.GenerateLootDrop(ctx, playerID)
+		if err != nil {
+			t.Fatalf("GenerateLootDrop failed: %v", err)
+		}
+		if !drop.WasDuplicate || drop.RefundedData != 0 {
+			t.Errorf("Expected an unrefunded duplicate, got %+v", drop)
+		}
+		var transactions int
+		dbConn.QueryRow(`SELECT COUNT(*) FROM currency_transactions WHERE player_id = ?`, playerID).Scan(&transactions)
+		if transactions != 0 {
+			t.Errorf("Expected no currency transactions, got %d", transactions)
+		}
+	})
+}
+
+func TestLootService_GenerateLootDropPity(t *testing.T) {
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+	cfg := config.Config{Loot: config.LootConfig{PityThreshold: 3, PityMinRarity: "rare"}}
+	service := loot.NewLootService(cfg, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(cfg, zaptest.NewLogger(t), dbConn), loot.NewRoller(1))
+	ctx := context.Background()
+
+	insert := func(query string, args ...any) int64 {
+		t.Helper()
+		res, err := dbConn.Exec(query, args...)
+		if err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return id
+	}
+	playerID := insert(`INSERT INTO players (username, email, password_hash) VALUES ('unlucky', 'unlucky@example.com', 'hash')`)
+	commonID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Plain Badge', 'badge', 'common', 1, 0)`)
+	rareID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Shiny Skin', 'character_skin', 'rare', 1, 0)`)
+
+	// The rare table never drops on its own, so it is only reachable through pity
+	commonTableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Common Crate', 1.0, 1)`)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, commonTableID, commonID)
+	rareTableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Rare Crate', 0.0, 1)`)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, rareTableID, rareID)
+
+	unluckyRolls := func() int64 {
+		t.Helper()
+		var rolls int64
+		if err := dbConn.QueryRow(`SELECT unlucky_rolls FROM loot_pity WHERE player_id = ?`, playerID).Scan(&rolls); err != nil {
+			t.Fatalf("Failed to read loot pity: %v", err)
+		}
+		return rolls
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		drop, err := service.GenerateLootDrop(ctx, playerID)
+		if err != nil {
+			t.Fatalf("GenerateLootDrop %d failed: %v", i, err)
+		}
+		if drop.Cosmetic.CosmeticID != commonID {
+			t.Fatalf("Expected drop %d to be the common cosmetic, got %d", i, drop.Cosmetic.CosmeticID)
+		}
+		if rolls := unluckyRolls(); rolls != i {
+			t.Fatalf("Expected %d unlucky rolls after drop %d, got %d", i, i, rolls)
+		}
+	}
+
+	drop, err := service.GenerateLootDrop(ctx, playerID)
+	if err != nil {
+		t.Fatalf("GenerateLootDrop failed: %v", err)
+	}
+	if drop.Cosmetic.CosmeticID != rareID {
+		t.Fatalf("Expected pity drop to be the rare cosmetic, got %d", drop.Cosmetic.CosmeticID)
+	}
+	if rolls := unluckyRolls(); rolls != 0 {
+		t.Errorf("Expected pity counter to reset, got %d", rolls)
+	}
+
+	var owned int
+	if err := dbConn.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, playerID, rareID).Scan(&owned); err != nil {
+		t.Fatalf("Failed to check ownership: %v", err)
+	}
+	if owned != 1 {
+		t.Errorf("Expected rare cosmetic to be granted")
+	}
+}
+
+func TestLootService_GenerateLootDropDistribution(t *testing.T) {
+	const iterations = 10000
+	const tolerance = 0.02
+
+	run := func(t *testing.T, cfg config.Config, want map[string]float64) {
+		dbConn := setupTestDB(t)
+		defer dbConn.Close()
+		service := loot.NewLootService(cfg, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(cfg, zaptest.NewLogger(t), dbConn), loot.NewRoller(1))
+		ctx := context.Background()
+
+		insert := func(query string, args ...any) int64 {
+			t.Helper()
+			res, err := dbConn.Exec(query, args...)
+			if err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+			id, _ := res.LastInsertId()
+			return id
+		}
+		playerID := insert(`INSERT INTO players (username, email, password_hash) VALUES ('roller', 'roller@example.com', 'hash')`)
+		// The earlier table has the lower chance, which the old first-hit loop
+		// skewed toward: 0.2 and 0.8 * 0.3 = 0.24 instead of 0.2 and 0.3
+		names := map[int64]string{}
+		for _, table := range []struct {
+			name   string
+			chance float64
+		}{{"first", 0.2}, {"second", 0.3}} {
+			cosmeticID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, 'badge', 'common', 1, 0)`, table.name)
+			tableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES (?, ?, 1)`, table.name, table.chance)
+			insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, tableID, cosmeticID)
+			names[cosmeticID] = table.name
+		}
+
+		counts := map[string]int{}
+		for i := 0; i < iterations; i++ {
+			drop, err := service.GenerateLootDrop(ctx, playerID)
+			if err != nil {
+				if err.Error() != "no drop from any loot table" {
+					t.Fatalf("GenerateLootDrop failed: %v", err)
+				}
+				counts["none"]++
+				continue
+			}
+			counts[names[drop.Cosmetic.CosmeticID]]++
+		}
+
+		for outcome, p := range want {
+			got := float64(counts[outcome]) / iterations
+			if got < p-tolerance || got > p+tolerance {
+				t.Errorf("Expected %s rate %.2f, got %.4f", outcome, p, got)
+			}
+		}
+	}
+
+	t.Run("chances below one", func(t *testing.T) {
+		run(t, config.Config{}, map[string]float64{"first": 0.2, "second": 0.3, "none": 0.5})
+	})
+
+	t.Run("guaranteed drop", func(t *testing.T) {
+		cfg := config.Config{Loot: config.LootConfig{GuaranteedDrop: true}}
+		run(t, cfg, map[string]float64{"first": 0.4, "second": 0.6, "none": 0})
+	})
+}
+
+func TestLootService_GenerateLootDropSeeded(t *testing.T) {
+	// drops rolls 30 drops against a fresh database with the given seed and
+	// records the cosmetic and quantity of each, or 0 for no drop
+	drops := func(t *testing.T, seed int64) [][2]int64 {
+		dbConn := setupTestDB(t)
+		defer dbConn.Close()
+		service := loot.NewLootService(config.Config{}, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(config.Config{}, zaptest.NewLogger(t), dbConn), loot.NewRoller(seed))
+		ctx := context.Background()
+
+		insert := func(query string, args ...any) int64 {
+			t.Helper()
+			res, err := dbConn.Exec(query, args...)
+			if err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+			id, _ := res.LastInsertId()
+			return id
+		}
+		playerID := insert(`INSERT INTO players (username, email, password_hash) VALUES ('seeded', 'seeded@example.com', 'hash')`)
+		tableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Mixed Crate', 0.6, 1)`)
+		for i, weight := range []int64{10, 30, 60} {
+			cosmeticID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, 'badge', 'common', 1, 0)`, fmt.Sprintf("Badge %d", i))
+			insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight, min_quantity, max_quantity) VALUES (?, ?, ?, 1, 5)`, tableID, cosmeticID, weight)
+		}
+
+		var sequence [][2]int64
+		for i := 0; i < 30; i++ {
+			drop, err := service.GenerateLootDrop(ctx, playerID)
+			if err != nil {
+				if err.Error() != "no drop from any loot table" {
+					t.Fatalf("GenerateLootDrop failed: %v", err)
+				}
+				sequence = append(sequence, [2]int64{})
+				continue
+			}
+			sequence = append(sequence, [2]int64{drop.Cosmetic.CosmeticID, drop.Quantity})
+		}
+		return sequence
+	}
+
+	first, second := drops(t, 42), drops(t, 42)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same seed to yield the same drops:\n%v\n%v", first, second)
+	}
+	if other := drops(t, 7); reflect.DeepEqual(first, other) {
+		t.Errorf("Expected a different seed to yield different drops, got %v", other)
+	}
+}
+
+func TestLootService_GetCosmeticSources(t *testing.T) {
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+	service := loot.NewLootService(config.Config{}, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(config.Config{}, zaptest.NewLogger(t), dbConn), loot.NewRoller(1))
+	ctx := context.Background()
+
+	insert := func(query string, args ...any) int64 {
+		t.Helper()
+		res, err := dbConn.Exec(query, args...)
+		if err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return id
+	}
+	skinID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Bloody Skin', 'character_skin', 'rare', 1, 250)`)
+	fillerID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Filler Badge', 'badge', 'common', 1, 0)`)
+
+	// The first table never yields the skin; the drop chances add up to 1.5, so
+	// they are normalized and the second table is picked 1.0/1.5 of the time
+	firstTableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Common Crate', 0.5, 1)`)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, firstTableID, fillerID)
+	secondTableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Rare Crate', 1.0, 1)`)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 25)`, secondTableID, skinID)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 75)`, secondTableID, fillerID)
+	inactiveTableID := insert(`INSERT INTO loot_tables (name, drop_chance, is_active) VALUES ('Retired Crate', 1.0, 0)`)
+	insert(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (?, ?, 100)`, inactiveTableID, skinID)
+
+	t.Run("purchase and loot", func(t *testing.T) {
+		sources, err := service.GetCosmeticSources(ctx, skinID)
+		if err != nil {
+			t.Fatalf("GetCosmeticSources failed: %v", err)
+		}
+		if len(sources) != 2 {
+			t.Fatalf("Expected 2 sources, got %d", len(sources))
+		}
+		if sources[0].Type != loot.SourcePurchase || sources[0].DataCost != 250 {
+			t.Errorf("Expected purchase source costing 250, got %+v", sources[0])
+		}
+		if sources[1].Type != loot.SourceLootDrop || sources[1].LootTableID != secondTableID {
+			t.Fatalf("Expected loot source from table %d, got %+v", secondTableID, sources[1])
+		}
+		if diff := sources[1].DropChance - 1.0/6; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Expected drop chance 1/6, got %v", sources[1].DropChance)
+		}
+	})
+
+	t.Run("unobtainable", func(t *testing.T) {
+		lonelyID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Lost Hat', 'hat', 'epic', 1, 0)`)
+		sources, err := service.GetCosmeticSources(ctx, lonelyID)
+		if err != nil {
+			t.Fatalf("GetCosmeticSources failed: %v", err)
+		}
+		if sources == nil || len(sources) != 0 {
+			t.Errorf("Expected empty sources, got %+v", sources)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := service.GetCosmeticSources(ctx, 9999); err != loot.ErrCosmeticNotFound {
+			t.Errorf("Expected ErrCosmeticNotFound, got %v", err)
+		}
+	})
+}
+
+func TestLootService_LootTableEntryValidation(t *testing.T) {
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+	service := loot.NewLootService(config.Config{}, zaptest.NewLogger(t), dbConn, progression.NewProgressionService(config.Config{}, zaptest.NewLogger(t), dbConn), loot.NewRoller(1))
+	ctx := context.Background()
+
+	table, err := service.CreateLootTable(ctx, "Validation Crate", nil, 1.0, true)
+	if err != nil {
+		t.Fatalf("CreateLootTable failed: %v", err)
+	}
+	res, err := dbConn.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Valid Badge', 'badge', 'common', 1, 0)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	entry, err := service.CreateLootTableEntry(ctx, table.LootTableID, cosmeticID, 10, 1, 1)
+	if err != nil {
+		t.Fatalf("CreateLootTableEntry failed: %v", err)
+	}
+
+	t.Run("missing cosmetic", func(t *testing.T) {
+		if _, err := service.CreateLootTableEntry(ctx, table.LootTableID, 9999, 10, 1, 1); !errors.Is(err, loot.ErrCosmeticNotFound) {
+			t.Errorf("Expected ErrCosmeticNotFound on create, got %v", err)
+		}
+		if err := service.UpdateLootTableEntry(ctx, entry.LootEntryID, table.LootTableID, 9999, 10, 1, 1); !errors.Is(err, loot.ErrCosmeticNotFound) {
+			t.Errorf("Expected ErrCosmeticNotFound on update, got %v", err)
+		}
+	})
+
+	t.Run("zero weight", func(t *testing.T) {
+		if _, err := service.CreateLootTableEntry(ctx, table.LootTableID, cosmeticID, 0, 1, 1); !errors.Is(err, loot.ErrInvalidWeight) {
+			t.Errorf("Expected ErrInvalidWeight on create, got %v", err)
+		}
+		if err := service.UpdateLootTableEntry(ctx, entry.LootEntryID, table.LootTableID, cosmeticID, 0, 1, 1); !errors.Is(err, loot.ErrInvalidWeight) {
+			t.Errorf("Expected ErrInvalidWeight on update, got %v", err)
+		}
+	})
+
+	entries, err := service.GetLootTableEntriesByLootTableID(ctx, table.LootTableID)
+	if err != nil {
+		t.Fatalf("GetLootTableEntriesByLootTableID failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Weight != 10 || entries[0].CosmeticID != cosmeticID {
+		t.Errorf("Expected the original entry to be untouched, got %+v", entries)
+	}
+}
+
+func TestSimulateEntries(t *testing.T) {
+	const rolls = 20000
+	const tolerance = 1.5 // percentage points
+
+	entries := []*db.LootTableEntry{
+		{LootEntryID: 1, CosmeticID: 10, Weight: 10},
+		{LootEntryID: 2, CosmeticID: 20, Weight: 30},
+		{LootEntryID: 3, CosmeticID: 30, Weight: 60},
+		// A second entry for the same cosmetic adds to its share
+		{LootEntryID: 4, CosmeticID: 10, Weight: 100},
+	}
+	want := map[int64]float64{10: 55, 20: 15, 30: 30}
+
+	drops := loot.SimulateEntries(entries, rolls, loot.NewRoller(1))
+	if len(drops) != 3 {
+		t.Fatalf("Expected 3 cosmetics, got %d", len(drops))
+	}
+	var total int64
+	for i, drop := range drops {
+		if wantID := []int64{10, 20, 30}[i]; drop.CosmeticID != wantID {
+			t.Errorf("Expected cosmetic %d at position %d, got %d", wantID, i, drop.CosmeticID)
+		}
+		if got := drop.Percentage; got < want[drop.CosmeticID]-tolerance || got > want[drop.CosmeticID]+tolerance {
+			t.Errorf("Expected cosmetic %d near %.0f%%, got %.2f%%", drop.CosmeticID, want[drop.CosmeticID], got)
+		}
+		total += drop.Count
+	}
+	if total != rolls {
+		t.Errorf("Expected counts to add up to %d, got %d", rolls, total)
+	}
+
+	again := loot.SimulateEntries(entries, rolls, loot.NewRoller(1))
+	for i := range drops {
+		if drops[i].Count != again[i].Count {
+			t.Errorf("Expected the same seed to give the same counts, got %d and %d", drops[i].Count, again[i].Count)
+		}
 	}
 }
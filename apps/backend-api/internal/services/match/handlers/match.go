@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/db/types"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/match"
 	"ai-zombie-defense/backend-api/internal/services/server"
+	"ai-zombie-defense/backend-api/pkg/logging"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -50,55 +52,52 @@ type StoreMatchRequest struct {
 	TotalZombiesKilled int64                     `json:"total_zombies_killed"`
 	TotalPlayers       int64                     `json:"total_players"`
 	PlayerStats        []PlayerMatchStatsRequest `json:"player_stats"`
+	// IdempotencyKey lets a server safely retry a submission; it is unique per server.
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
 }
 
 // StoreMatch handles POST /matches
+// Only game servers may submit results: the match is recorded against the
+// server authenticated by X-Server-Token, never a server_id chosen by the client.
 func (h *MatchHandlers) StoreMatch(c *fiber.Ctx) error {
-	playerID, ok := middleware.GetPlayerID(c)
+	serverID, ok := middleware.GetServerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("server ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 
 	var req StoreMatchRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 
-	// Validate required fields
-	if req.ServerID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "server_id must be positive",
-		})
+	// server_id in the body is optional but must match the authenticated server
+	if req.ServerID != 0 && req.ServerID != serverID {
+		return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeServerMismatch, middleware.ErrServerMismatch.Error())
 	}
+	req.ServerID = serverID
+
+	// Validate required fields
 	if req.MapName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "map_name is required",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "map_name is required")
 	}
 	if req.GameMode == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "game_mode is required",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "game_mode is required")
 	}
 	if req.Outcome == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "outcome is required",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "outcome is required")
+	}
+	if req.Outcome != match.OutcomeCompleted && req.Outcome != match.OutcomeFailed && req.Outcome != match.OutcomeAbandoned {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "outcome must be one of completed, failed, abandoned")
+	}
+	if req.EndTime != nil && req.EndTime.Valid && req.EndTime.Time.Before(req.StartTime.Time) {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "end_time cannot be before start_time")
 	}
 	if req.TotalPlayers <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "total_players must be positive",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "total_players must be positive")
 	}
 	if len(req.PlayerStats) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "player_stats cannot be empty",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "player_stats cannot be empty")
 	}
 
 	// Build match params
@@ -116,14 +115,18 @@ func (h *MatchHandlers) StoreMatch(c *fiber.Ctx) error {
 	if req.EndTime != nil {
 		matchParams.EndTime = *req.EndTime
 	}
+	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
+		matchParams.IdempotencyKey = req.IdempotencyKey
+	}
 
 	// Convert player stats
 	playerStats := make([]*db.CreatePlayerMatchStatsParams, 0, len(req.PlayerStats))
 	for _, ps := range req.PlayerStats {
 		if ps.PlayerID <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "player_stats.player_id must be positive",
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "player_stats.player_id must be positive")
+		}
+		if ps.WavesSurvived > req.WavesSurvived {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "player_stats.waves_survived cannot exceed match waves_survived")
 		}
 		playerStats = append(playerStats, &db.CreatePlayerMatchStatsParams{
 			PlayerID:           ps.PlayerID,
@@ -144,21 +147,27 @@ func (h *MatchHandlers) StoreMatch(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	err := h.matchSvc.StoreMatchWithStats(ctx, req.ServerID, matchParams, playerStats)
+	stored, created, err := h.matchSvc.StoreMatchWithStats(ctx, serverID, matchParams, playerStats)
 	if err != nil {
 		if err == server.ErrServerNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "server not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeServerNotFound, "server not found")
 		}
-		h.logger.Error("failed to store match", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("server_id", req.ServerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		if err == match.ErrImplausibleStats {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "player stats are negative or exceed plausible limits")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to store match", zap.Error(err), zap.Int64("server_id", serverID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
+	if !created {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message":  "match already stored",
+			"match_id": stored.MatchID,
+		})
+	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "match stored successfully",
+		"message":  "match stored successfully",
+		"match_id": stored.MatchID,
 	})
 }
 
@@ -166,10 +175,8 @@ func (h *MatchHandlers) StoreMatch(c *fiber.Ctx) error {
 func (h *MatchHandlers) GetMatchHistory(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 
 	// Parse limit query parameter (default 10, max 100)
@@ -180,15 +187,62 @@ func (h *MatchHandlers) GetMatchHistory(c *fiber.Ctx) error {
 	if limit > 100 {
 		limit = 100
 	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+	// before is a match_id cursor: only matches older than it are returned
+	var beforeMatchID *int64
+	if before := c.QueryInt("before", 0); before > 0 {
+		id := int64(before)
+		beforeMatchID = &id
+	}
 
 	ctx := c.Context()
-	matches, err := h.matchSvc.GetPlayerMatchHistory(ctx, playerID, int32(limit))
+	matches, err := h.matchSvc.GetPlayerMatchHistory(ctx, playerID, int32(limit), int32(offset), beforeMatchID)
 	if err != nil {
-		h.logger.Error("failed to get match history", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get match history", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(matches)
 }
+
+type OutcomeCountResponse struct {
+	Outcome    string  `json:"outcome"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+type OutcomeDistributionResponse struct {
+	TotalMatches int64                   `json:"total_matches"`
+	Outcomes     []*OutcomeCountResponse `json:"outcomes"`
+}
+
+// GetOutcomeDistribution handles GET /account/stats/outcomes
+func (h *MatchHandlers) GetOutcomeDistribution(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	dist, err := h.matchSvc.GetOutcomeDistribution(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to get outcome distribution", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := OutcomeDistributionResponse{
+		TotalMatches: dist.TotalMatches,
+		Outcomes:     make([]*OutcomeCountResponse, 0, len(dist.Outcomes)),
+	}
+	for _, o := range dist.Outcomes {
+		resp.Outcomes = append(resp.Outcomes, &OutcomeCountResponse{
+			Outcome:    o.Outcome,
+			Count:      o.Count,
+			Percentage: o.Percentage,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
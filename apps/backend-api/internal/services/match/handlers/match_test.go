@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
 	"ai-zombie-defense/backend-api/internal/testutils"
@@ -30,8 +32,8 @@ func TestAccountHandlers_StoreMatch(t *testing.T) {
 
 	// Create a player and server
 	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
-	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
 	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
 
 	// Prepare match request
 	reqBody := map[string]interface{}{
@@ -64,7 +66,7 @@ func TestAccountHandlers_StoreMatch(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/matches", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("X-Server-Token", serverToken)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := app.Test(req, -1)
 	if err != nil {
@@ -75,6 +77,262 @@ func TestAccountHandlers_StoreMatch(t *testing.T) {
 	}
 }
 
+func TestAccountHandlers_StoreMatchValidation(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
+
+	newRequest := func() map[string]interface{} {
+		return map[string]interface{}{
+			"server_id":      serverID,
+			"map_name":       "Test Map",
+			"game_mode":      "survival",
+			"start_time":     "2026-01-22T15:30:00Z",
+			"end_time":       "2026-01-22T16:00:00Z",
+			"outcome":        "completed",
+			"waves_survived": 5,
+			"total_players":  1,
+			"player_stats": []map[string]interface{}{
+				{"player_id": playerID, "waves_survived": 5, "score": 100},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(req map[string]interface{})
+		wantCode  int
+		wantError string
+	}{
+		{
+			name:     "Valid match",
+			modify:   func(req map[string]interface{}) {},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Missing end time",
+			modify:   func(req map[string]interface{}) { delete(req, "end_time") },
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "Unknown outcome",
+			modify:    func(req map[string]interface{}) { req["outcome"] = "victory" },
+			wantCode:  http.StatusBadRequest,
+			wantError: "outcome must be one of completed, failed, abandoned",
+		},
+		{
+			name:      "End before start",
+			modify:    func(req map[string]interface{}) { req["end_time"] = "2026-01-22T15:00:00Z" },
+			wantCode:  http.StatusBadRequest,
+			wantError: "end_time cannot be before start_time",
+		},
+		{
+			name: "Player waves exceed match waves",
+			modify: func(req map[string]interface{}) {
+				req["player_stats"] = []map[string]interface{}{
+					{"player_id": playerID, "waves_survived": 6},
+				}
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: "player_stats.waves_survived cannot exceed match waves_survived",
+		},
+		{
+			name: "Implausible kills for one wave",
+			modify: func(req map[string]interface{}) {
+				req["waves_survived"] = 1
+				req["player_stats"] = []map[string]interface{}{
+					{"player_id": playerID, "waves_survived": 1, "zombies_killed": 10000},
+				}
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: "player stats are negative or exceed plausible limits",
+		},
+		{
+			name: "Implausible scrap",
+			modify: func(req map[string]interface{}) {
+				req["player_stats"] = []map[string]interface{}{
+					{"player_id": playerID, "waves_survived": 5, "scrap_earned": 5*5000 + 1},
+				}
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: "player stats are negative or exceed plausible limits",
+		},
+		{
+			name: "Negative stat",
+			modify: func(req map[string]interface{}) {
+				req["player_stats"] = []map[string]interface{}{
+					{"player_id": playerID, "waves_survived": 5, "deaths": -1},
+				}
+			},
+			wantCode:  http.StatusBadRequest,
+			wantError: "player stats are negative or exceed plausible limits",
+		},
+		{
+			name: "Stats at the cap",
+			modify: func(req map[string]interface{}) {
+				req["player_stats"] = []map[string]interface{}{
+					{"player_id": playerID, "waves_survived": 5, "zombies_killed": 5 * 500, "scrap_earned": 5 * 5000},
+				}
+			},
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody := newRequest()
+			tt.modify(reqBody)
+			body, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/matches", bytes.NewReader(body))
+			req.Header.Set("X-Server-Token", serverToken)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+			if tt.wantError != "" {
+				var result map[string]string
+				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if result["error"] != tt.wantError {
+					t.Errorf("Expected error %q, got %q", tt.wantError, result["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestMatchHandlers_StoreMatchRequiresServerAuth(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
+	otherServerID := testutils.CreateTestServerRow(t, db)
+
+	storeMatch := func(header, value string, bodyServerID int64) int {
+		t.Helper()
+		reqBody := map[string]interface{}{
+			"map_name":      "Test Map",
+			"game_mode":     "survival",
+			"start_time":    "2026-01-22T15:30:00Z",
+			"outcome":       "completed",
+			"total_players": 1,
+			"player_stats": []map[string]interface{}{
+				{"player_id": playerID, "score": 100},
+			},
+		}
+		if bodyServerID != 0 {
+			reqBody["server_id"] = bodyServerID
+		}
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/matches", bytes.NewReader(body))
+		req.Header.Set(header, value)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := storeMatch("Authorization", "Bearer "+accessToken, serverID); status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for player token, got %d", status)
+	}
+	if status := storeMatch("X-Server-Token", serverToken, otherServerID); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for mismatched server_id, got %d", status)
+	}
+	if status := storeMatch("X-Server-Token", serverToken, 0); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 for server token, got %d", status)
+	}
+
+	var storedServerID int64
+	if err := db.QueryRow(`SELECT server_id FROM matches`).Scan(&storedServerID); err != nil {
+		t.Fatalf("Failed to query stored match: %v", err)
+	}
+	if storedServerID != serverID {
+		t.Errorf("Expected match stored for server %d, got %d", serverID, storedServerID)
+	}
+}
+
+func TestMatchHandlers_StoreMatchIdempotencyKey(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
+
+	storeMatch := func() (int, float64) {
+		t.Helper()
+		reqBody := map[string]interface{}{
+			"map_name":        "Test Map",
+			"game_mode":       "survival",
+			"start_time":      "2026-01-22T15:30:00Z",
+			"end_time":        "2026-01-22T16:00:00Z",
+			"outcome":         "completed",
+			"waves_survived":  5,
+			"total_players":   1,
+			"idempotency_key": "match-retry-1",
+			"player_stats": []map[string]interface{}{
+				{"player_id": playerID, "waves_survived": 5, "data_earned": 50, "score": 100},
+			},
+		}
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/matches", bytes.NewReader(body))
+		req.Header.Set("X-Server-Token", serverToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		matchID, _ := result["match_id"].(float64)
+		return resp.StatusCode, matchID
+	}
+
+	status, firstID := storeMatch()
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201 for first submission, got %d", status)
+	}
+	status, retryID := storeMatch()
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200 for retried submission, got %d", status)
+	}
+	if retryID != firstID {
+		t.Errorf("Expected retry to return match %v, got %v", firstID, retryID)
+	}
+
+	var matches, rewards, matchesPlayed int64
+	if err := db.QueryRow(`SELECT COUNT(*) FROM matches`).Scan(&matches); err != nil {
+		t.Fatalf("Failed to count matches: %v", err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM currency_transactions WHERE player_id = ? AND transaction_type = 'match_reward'`, playerID).Scan(&rewards); err != nil {
+		t.Fatalf("Failed to count rewards: %v", err)
+	}
+	if err := db.QueryRow(`SELECT total_matches_played FROM player_progression WHERE player_id = ?`, playerID).Scan(&matchesPlayed); err != nil {
+		t.Fatalf("Failed to get progression: %v", err)
+	}
+	if matches != 1 || rewards != 1 || matchesPlayed != 1 {
+		t.Errorf("Expected exactly one match and one reward, got %d matches, %d rewards, %d matches played", matches, rewards, matchesPlayed)
+	}
+}
+
 func TestAccountHandlers_GetMatchHistory(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -109,3 +367,317 @@ func TestAccountHandlers_GetMatchHistory(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestAccountHandlers_GetMatchHistoryMissingServer(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	serverID := testutils.CreateTestServerRow(t, db)
+	goneServerID := testutils.CreateTestServerRow(t, db)
+
+	for i, sid := range []int64{serverID, goneServerID} {
+		res, err := db.Exec(`INSERT INTO matches (server_id, map_name, game_mode, start_time, end_time, outcome, waves_survived, total_zombies_killed, total_players) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sid, "Test Map", "survival", time.Date(2026, 1, 22, 15+i, 0, 0, 0, time.UTC).Format(time.RFC3339), nil, "completed", 5, 100, 1)
+		if err != nil {
+			t.Fatalf("Failed to insert match: %v", err)
+		}
+		matchID, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_match_stats (player_id, match_id) VALUES (?, ?)`, playerID, matchID); err != nil {
+			t.Fatalf("Failed to insert player match stats: %v", err)
+		}
+	}
+
+	// Remove the server row without cascading, leaving the match orphaned
+	if _, err := db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("Failed to disable foreign keys: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM servers WHERE server_id = ?`, goneServerID); err != nil {
+		t.Fatalf("Failed to delete server: %v", err)
+	}
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		t.Fatalf("Failed to enable foreign keys: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/matches/history", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var history []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(history))
+	}
+	// Newest first: the orphaned match has no server name
+	if name, ok := history[0]["server_name"]; !ok || name != nil {
+		t.Errorf("Expected null server_name for deleted server, got %v", history[0]["server_name"])
+	}
+	if name, _ := history[1]["server_name"].(string); name == "" {
+		t.Errorf("Expected server_name for existing server, got %v", history[1]["server_name"])
+	}
+}
+
+func TestAccountHandlers_GetMatchHistoryPagination(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	serverID := testutils.CreateTestServerRow(t, db)
+
+	// 25 matches where pairs share a start time, so ordering relies on the match_id tiebreak
+	base := time.Date(2026, 1, 22, 12, 0, 0, 0, time.UTC)
+	var expected []int64
+	for i := 0; i < 25; i++ {
+		start := base.Add(time.Duration(i/2) * time.Minute).Format(time.RFC3339)
+		res, err := db.Exec(`INSERT INTO matches (server_id, map_name, game_mode, start_time, outcome) VALUES (?, ?, ?, ?, ?)`,
+			serverID, "Test Map", "survival", start, "completed")
+		if err != nil {
+			t.Fatalf("Failed to insert match: %v", err)
+		}
+		matchID, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_match_stats (player_id, match_id) VALUES (?, ?)`, playerID, matchID); err != nil {
+			t.Fatalf("Failed to insert player match stats: %v", err)
+		}
+		expected = append([]int64{matchID}, expected...)
+	}
+
+	getPage := func(query string) []int64 {
+		req := httptest.NewRequest(http.MethodGet, "/matches/history?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var rows []struct {
+			MatchID int64 `json:"match_id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		ids := make([]int64, 0, len(rows))
+		for _, r := range rows {
+			ids = append(ids, r.MatchID)
+		}
+		return ids
+	}
+	assertPages := func(t *testing.T, got []int64) {
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %d matches across pages, got %d", len(expected), len(got))
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("Position %d: expected match %d, got %d (all: %v)", i, expected[i], got[i], got)
+			}
+		}
+	}
+
+	t.Run("Offset", func(t *testing.T) {
+		var all []int64
+		for offset := 0; ; offset += 10 {
+			page := getPage(fmt.Sprintf("limit=10&offset=%d", offset))
+			all = append(all, page...)
+			if len(page) < 10 {
+				if len(page) != 5 {
+					t.Errorf("Expected last page of 5, got %d", len(page))
+				}
+				break
+			}
+		}
+		assertPages(t, all)
+	})
+
+	t.Run("Before cursor", func(t *testing.T) {
+		var all []int64
+		query := "limit=10"
+		for {
+			page := getPage(query)
+			all = append(all, page...)
+			if len(page) < 10 {
+				break
+			}
+			query = fmt.Sprintf("limit=10&before=%d", page[len(page)-1])
+		}
+		assertPages(t, all)
+	})
+
+	t.Run("Default limit", func(t *testing.T) {
+		if page := getPage(""); len(page) != 10 {
+			t.Errorf("Expected default page of 10, got %d", len(page))
+		}
+	})
+}
+
+func TestAccountHandlers_GetOutcomeDistribution(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	otherID := testutils.CreateTestPlayer(t, db, "otheruser", "other@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	serverID := testutils.CreateTestServerRow(t, db)
+
+	getDistribution := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/account/stats/outcomes", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return result
+	}
+
+	// No matches yet: all zeros, no division by zero
+	result := getDistribution()
+	if result["total_matches"] != float64(0) {
+		t.Errorf("Expected 0 total matches, got %v", result["total_matches"])
+	}
+	for _, o := range result["outcomes"].([]interface{}) {
+		outcome := o.(map[string]interface{})
+		if outcome["count"] != float64(0) || outcome["percentage"] != float64(0) {
+			t.Errorf("Expected zero count and percentage for %v, got %v", outcome["outcome"], outcome)
+		}
+	}
+
+	insertMatch := func(player int64, outcome string) {
+		res, err := db.Exec(`INSERT INTO matches (server_id, map_name, game_mode, start_time, outcome) VALUES (?, 'Test Map', 'survival', '2026-01-22T15:30:00Z', ?)`, serverID, outcome)
+		if err != nil {
+			t.Fatalf("Failed to insert match: %v", err)
+		}
+		matchID, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_match_stats (player_id, match_id) VALUES (?, ?)`, player, matchID); err != nil {
+			t.Fatalf("Failed to insert player match stats: %v", err)
+		}
+	}
+	insertMatch(playerID, "completed")
+	insertMatch(playerID, "completed")
+	insertMatch(playerID, "failed")
+	// Another player's match must not be counted
+	insertMatch(otherID, "abandoned")
+
+	result = getDistribution()
+	if result["total_matches"] != float64(3) {
+		t.Errorf("Expected 3 total matches, got %v", result["total_matches"])
+	}
+	expected := []struct {
+		outcome    string
+		count      float64
+		percentage float64
+	}{
+		{"completed", 2, 66.67},
+		{"failed", 1, 33.33},
+		{"abandoned", 0, 0},
+	}
+	outcomes := result["outcomes"].([]interface{})
+	if len(outcomes) != len(expected) {
+		t.Fatalf("Expected %d outcomes, got %d", len(expected), len(outcomes))
+	}
+	for i, want := range expected {
+		got := outcomes[i].(map[string]interface{})
+		if got["outcome"] != want.outcome || got["count"] != want.count || got["percentage"] != want.percentage {
+			t.Errorf("Expected %s count=%v percentage=%v, got %v", want.outcome, want.count, want.percentage, got)
+		}
+	}
+}
+
+func TestMatchHandlers_StoreMatchRewardDecay(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
+
+	storeMatch := func(start, end time.Time) {
+		t.Helper()
+		reqBody := map[string]interface{}{
+			"server_id":     serverID,
+			"map_name":      "Test Map",
+			"game_mode":     "survival",
+			"start_time":    start.Format(time.RFC3339),
+			"end_time":      end.Format(time.RFC3339),
+			"outcome":       "completed",
+			"total_players": 1,
+			"player_stats": []map[string]interface{}{
+				{"player_id": playerID, "data_earned": 100},
+			},
+		}
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/matches", bytes.NewReader(body))
+		req.Header.Set("X-Server-Token", serverToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+	}
+
+	// Six rapid 2-minute matches: the default config gives 3 free short
+	// matches, then halves rewards per extra match
+	base := time.Date(2026, 1, 22, 15, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		start := base.Add(time.Duration(i) * 3 * time.Minute)
+		storeMatch(start, start.Add(2*time.Minute))
+	}
+	// A normal-length match right after is unaffected
+	longStart := base.Add(20 * time.Minute)
+	storeMatch(longStart, longStart.Add(30*time.Minute))
+
+	rows, err := db.Query(`SELECT amount FROM currency_transactions WHERE player_id = ? AND transaction_type = 'match_reward' ORDER BY transaction_id`, playerID)
+	if err != nil {
+		t.Fatalf("Failed to query currency transactions: %v", err)
+	}
+	defer rows.Close()
+	var amounts []int64
+	for rows.Next() {
+		var amount int64
+		if err := rows.Scan(&amount); err != nil {
+			t.Fatalf("Failed to scan amount: %v", err)
+		}
+		amounts = append(amounts, amount)
+	}
+	expected := []int64{100, 100, 100, 50, 25, 12, 100}
+	if len(amounts) != len(expected) {
+		t.Fatalf("Expected %d match rewards, got %v", len(expected), amounts)
+	}
+	for i := range expected {
+		if amounts[i] != expected[i] {
+			t.Errorf("Expected data rewards %v, got %v", expected, amounts)
+			break
+		}
+	}
+
+	// Base XP of 100 per match decays the same way
+	var experience int64
+	if err := db.QueryRow(`SELECT experience FROM player_progression WHERE player_id = ?`, playerID).Scan(&experience); err != nil {
+		t.Fatalf("Failed to query progression: %v", err)
+	}
+	if experience != 487 {
+		t.Errorf("Expected 487 total XP, got %d", experience)
+	}
+}
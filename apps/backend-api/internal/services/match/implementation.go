@@ -3,12 +3,17 @@ package match
 import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/db/types"
+	"ai-zombie-defense/backend-api/internal/metrics"
 	"ai-zombie-defense/backend-api/internal/services/progression"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+
 	"go.uber.org/zap"
 )
 
@@ -30,76 +35,148 @@ func NewMatchService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX, prog
 	}
 }
 
-func (s *matchService) StoreMatchWithStats(ctx context.Context, serverID int64, matchParams *db.CreateMatchParams, playerStats []*db.CreatePlayerMatchStatsParams) error {
+func (s *matchService) StoreMatchWithStats(ctx context.Context, serverID int64, matchParams *db.CreateMatchParams, playerStats []*db.CreatePlayerMatchStatsParams) (*db.Match, bool, error) {
 	// Ensure matchParams.ServerID matches the provided serverID
 	if matchParams.ServerID != serverID {
-		return fmt.Errorf("server ID mismatch: expected %d, got %d", serverID, matchParams.ServerID)
+		return nil, false, fmt.Errorf("server ID mismatch: expected %d, got %d", serverID, matchParams.ServerID)
 	}
-
-	// Start transaction
-	var dbTx db.DBTX
-	var tx *sql.Tx
-	var err error
-	if db, ok := s.dbConn.(*sql.DB); ok {
-		tx, err = db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+	for _, stats := range playerStats {
+		if err := s.checkStatCaps(stats); err != nil {
+			return nil, false, err
 		}
-		defer tx.Rollback()
-		dbTx = tx
-	} else {
-		s.logger.Warn("dbConn is not *sql.DB, proceeding without transaction")
-		dbTx = s.dbConn
 	}
 
-	// Create match
-	match, err := s.queries.CreateMatch(ctx, dbTx, matchParams)
-	if err != nil {
-		return fmt.Errorf("failed to create match: %w", err)
-	}
+	var match, existing *db.Match
+	var conflictErr error
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		var err error
+		// A retried submission returns the original match without re-awarding rewards
+		if matchParams.IdempotencyKey != nil {
+			existing, err = s.findByIdempotencyKey(ctx, tx, serverID, matchParams.IdempotencyKey)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return nil
+			}
+		}
 
-	// Insert player stats
-	for _, stats := range playerStats {
-		// Ensure stats.MatchID matches the created match
-		stats.MatchID = match.MatchID
-		_, err := s.queries.CreatePlayerMatchStats(ctx, dbTx, stats)
+		// Create match
+		match, err = s.queries.CreateMatch(ctx, tx, matchParams)
 		if err != nil {
-			return fmt.Errorf("failed to create player match stats: %w", err)
+			// A concurrent retry may have inserted the same key after our lookup;
+			// it is looked up again once this transaction has rolled back
+			if matchParams.IdempotencyKey != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				conflictErr = err
+				return err
+			}
+			return fmt.Errorf("failed to create match: %w", err)
 		}
-	}
 
-	// Award rewards based on player performance
-	for _, stats := range playerStats {
-		err := s.addMatchRewardsWithTx(ctx, dbTx, stats.PlayerID, stats.ZombiesKilled, stats.Deaths, stats.WavesSurvived, stats.ScrapEarned, stats.DataEarned)
-		if err != nil {
-			return fmt.Errorf("failed to award match rewards: %w", err)
+		// Insert player stats
+		for _, stats := range playerStats {
+			// Ensure stats.MatchID matches the created match
+			stats.MatchID = match.MatchID
+			if _, err := s.queries.CreatePlayerMatchStats(ctx, tx, stats); err != nil {
+				return fmt.Errorf("failed to create player match stats: %w", err)
+			}
 		}
-	}
 
-	// Commit transaction if we started one
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
+		// Award rewards based on player performance
+		for _, stats := range playerStats {
+			multiplier, err := s.rewardMultiplier(ctx, tx, match, stats.PlayerID)
+			if err != nil {
+				return fmt.Errorf("failed to compute reward multiplier: %w", err)
+			}
+			err = s.addMatchRewardsWithTx(ctx, tx, match.MatchID, stats.PlayerID, stats.ZombiesKilled, stats.Deaths, stats.WavesSurvived, stats.ScrapEarned, stats.DataEarned, stats.Revives, stats.HealingGiven, multiplier)
+			if err != nil {
+				return fmt.Errorf("failed to award match rewards: %w", err)
+			}
+		}
+		return nil
+	})
+	if conflictErr != nil {
+		existing, lookupErr := s.findByIdempotencyKey(ctx, s.dbConn, serverID, matchParams.IdempotencyKey)
+		if lookupErr != nil {
+			return nil, false, lookupErr
+		}
+		if existing != nil {
+			return existing, false, nil
 		}
+		return nil, false, fmt.Errorf("failed to create match: %w", conflictErr)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
 	}
 
-	s.logger.Info("Match stored successfully",
+	logging.FromContext(ctx, s.logger).Info("Match stored successfully",
 		zap.Int64("match_id", match.MatchID),
 		zap.Int64("server_id", serverID),
 		zap.Int("player_count", len(playerStats)))
-	return nil
+	return match, true, nil
 }
 
-func (s *matchService) addMatchRewardsWithTx(ctx context.Context, dbTx db.DBTX, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned int64) error {
-	if kills < 0 || deaths < 0 || wavesSurvived < 0 || scrapEarned < 0 || dataEarned < 0 {
-		return fmt.Errorf("match stats cannot be negative")
+// findByIdempotencyKey returns the server's match stored under key, or nil if there is none.
+func (s *matchService) findByIdempotencyKey(ctx context.Context, dbTx db.DBTX, serverID int64, key *string) (*db.Match, error) {
+	match, err := s.queries.GetMatchByIdempotencyKey(ctx, dbTx, &db.GetMatchByIdempotencyKeyParams{
+		ServerID:       serverID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get match by idempotency key: %w", err)
 	}
-	baseXP := int64(100)
-	xpPerKill := int64(10)
-	xpPerWave := int64(50)
-	xpPerScrap := int64(1)
+	return match, nil
+}
+
+// rewardMultiplier returns the diminishing-returns multiplier for a player's
+// rewards from match. Only short matches decay, based on how many other short
+// matches the player finished within the decay window before this one ended.
+func (s *matchService) rewardMultiplier(ctx context.Context, dbTx db.DBTX, match *db.Match, playerID int64) (float64, error) {
+	decay := s.config.Progression.RewardDecay
+	if !match.EndTime.Valid || !progression.IsShortMatch(decay, match.EndTime.Time.Sub(match.StartTime.Time)) {
+		return 1, nil
+	}
+
+	end := match.EndTime.Time
+	recent, err := s.queries.GetPlayerRecentMatchTimes(ctx, dbTx, &db.GetPlayerRecentMatchTimesParams{
+		PlayerID:       playerID,
+		ExcludeMatchID: match.MatchID,
+		WindowStart:    types.NullTimestamp{Timestamp: types.Timestamp{Time: end.Add(-decay.Window)}, Valid: true},
+		WindowEnd:      match.EndTime,
+	})
+	if err != nil {
+		return 0, err
+	}
+	shortMatches := 0
+	for _, m := range recent {
+		if m.EndTime.Valid && progression.IsShortMatch(decay, m.EndTime.Time.Sub(m.StartTime.Time)) {
+			shortMatches++
+		}
+	}
+
+	multiplier := progression.RewardDecayMultiplier(decay, shortMatches)
+	if multiplier < 1 {
+		logging.FromContext(ctx, s.logger).Info("Applying reward decay for rapid short matches",
+			zap.Int64("player_id", playerID),
+			zap.Int64("match_id", match.MatchID),
+			zap.Int("recent_short_matches", shortMatches),
+			zap.Float64("multiplier", multiplier))
+	}
+	return multiplier, nil
+}
 
-	totalXP := baseXP + (kills * xpPerKill) + (wavesSurvived * xpPerWave) + (scrapEarned * xpPerScrap)
+func (s *matchService) addMatchRewardsWithTx(ctx context.Context, dbTx db.DBTX, matchID int64, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned, revives, healingGiven int64, multiplier float64) error {
+	if kills < 0 || deaths < 0 || wavesSurvived < 0 || scrapEarned < 0 || dataEarned < 0 || revives < 0 || healingGiven < 0 {
+		return fmt.Errorf("match stats cannot be negative")
+	}
+	totalXP := progression.ApplyRewardMultiplier(progression.MatchRewardXP(s.config.Progression.XPRewards, kills, wavesSurvived, scrapEarned, revives, healingGiven), multiplier)
+	dataReward := progression.ApplyRewardMultiplier(dataEarned, multiplier)
 
 	err := s.queries.IncrementMatchStats(ctx, dbTx, &db.IncrementMatchStatsParams{
 		TotalMatchesPlayed: 1,
@@ -117,17 +194,32 @@ func (s *matchService) addMatchRewardsWithTx(ctx context.Context, dbTx db.DBTX,
 	if err != nil {
 		return fmt.Errorf("failed to add experience: %w", err)
 	}
-	if dataEarned > 0 {
+	if dataReward > 0 {
 		err = s.queries.AddDataCurrency(ctx, dbTx, &db.AddDataCurrencyParams{
-			DataCurrency: dataEarned,
+			DataCurrency: dataReward,
 			PlayerID:     playerID,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to add data currency",
+			logging.FromContext(ctx, s.logger).Warn("Failed to add data currency",
 				zap.Int64("player_id", playerID),
-				zap.Int64("data_earned", dataEarned),
+				zap.Int64("data_earned", dataReward),
 				zap.Error(err))
+			return nil
+		}
+		balance, err := s.queries.GetDataCurrency(ctx, dbTx, playerID)
+		if err != nil {
+			return fmt.Errorf("failed to get data currency: %w", err)
 		}
+		if err := s.queries.CreateCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          dataReward,
+			BalanceAfter:    balance,
+			TransactionType: "match_reward",
+			ReferenceID:     &matchID,
+		}); err != nil {
+			return fmt.Errorf("failed to create currency transaction: %w", err)
+		}
+		metrics.RecordCurrency("match_reward", dataReward)
 	}
 	return nil
 }
@@ -176,7 +268,7 @@ func (s *matchService) addExperienceWithTx(ctx context.Context, dbTx db.DBTX, pl
 			PlayerID: playerID,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to update level after XP gain",
+			logging.FromContext(ctx, s.logger).Warn("Failed to update level after XP gain",
 				zap.Int64("player_id", playerID),
 				zap.Int64("new_level", newLevel),
 				zap.Error(err))
@@ -186,27 +278,75 @@ func (s *matchService) addExperienceWithTx(ctx context.Context, dbTx db.DBTX, pl
 }
 
 func (s *matchService) calculateLevelFromXP(xp int64) int64 {
-	if xp <= 0 {
-		return 1
+	return progression.LevelForXP(s.config.Progression, xp)
+}
+
+// checkStatCaps rejects stats that are negative or exceed
+// Progression.StatCaps for the waves the player survived, logging the
+// offending player.
+func (s *matchService) checkStatCaps(stats *db.CreatePlayerMatchStatsParams) error {
+	if stats.WavesSurvived < 0 || stats.ZombiesKilled < 0 || stats.Deaths < 0 ||
+		stats.ScrapEarned < 0 || stats.DataEarned < 0 || stats.DamageDealt < 0 ||
+		stats.DamageTaken < 0 || stats.BuildingsBuilt < 0 || stats.BuildingsDestroyed < 0 ||
+		stats.HealingGiven < 0 || stats.Revives < 0 || stats.Score < 0 {
+		s.logger.Warn("rejected match stats: negative value", zap.Int64("player_id", stats.PlayerID))
+		return ErrImplausibleStats
+	}
+
+	caps := s.config.Progression.StatCaps
+	waves := stats.WavesSurvived
+	if waves < 1 {
+		waves = 1
 	}
-	base := int64(s.config.Progression.BaseXPPerLevel)
-	if base <= 0 {
-		base = 1000
+	exceeds := func(value, perWave int64) bool {
+		return perWave > 0 && value > perWave*waves
 	}
-	level := xp/base + 1
-	if level < 1 {
-		return 1
+	if exceeds(stats.ZombiesKilled, caps.MaxKillsPerWave) ||
+		exceeds(stats.ScrapEarned, caps.MaxScrapPerWave) ||
+		exceeds(stats.DataEarned, caps.MaxDataPerWave) {
+		s.logger.Warn("rejected match stats: exceeds stat caps",
+			zap.Int64("player_id", stats.PlayerID),
+			zap.Int64("waves_survived", stats.WavesSurvived),
+			zap.Int64("zombies_killed", stats.ZombiesKilled),
+			zap.Int64("scrap_earned", stats.ScrapEarned),
+			zap.Int64("data_earned", stats.DataEarned))
+		return ErrImplausibleStats
 	}
-	return level
+	return nil
 }
 
-func (s *matchService) GetPlayerMatchHistory(ctx context.Context, playerID int64, limit int32) ([]*db.GetPlayerMatchHistoryRow, error) {
+func (s *matchService) GetPlayerMatchHistory(ctx context.Context, playerID int64, limit, offset int32, beforeMatchID *int64) ([]*db.GetPlayerMatchHistoryRow, error) {
 	matches, err := s.queries.GetPlayerMatchHistory(ctx, s.dbConn, &db.GetPlayerMatchHistoryParams{
-		PlayerID: playerID,
-		Limit:    int64(limit),
+		PlayerID:      playerID,
+		BeforeMatchID: beforeMatchID,
+		Limit:         int64(limit),
+		Offset:        int64(offset),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get player match history: %w", err)
 	}
 	return matches, nil
 }
+
+func (s *matchService) GetOutcomeDistribution(ctx context.Context, playerID int64) (*OutcomeDistribution, error) {
+	rows, err := s.queries.GetPlayerOutcomeCounts(ctx, s.dbConn, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outcome counts: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	dist := &OutcomeDistribution{}
+	for _, row := range rows {
+		counts[row.Outcome] = row.MatchCount
+		dist.TotalMatches += row.MatchCount
+	}
+
+	for _, outcome := range []string{OutcomeCompleted, OutcomeFailed, OutcomeAbandoned} {
+		oc := &OutcomeCount{Outcome: outcome, Count: counts[outcome]}
+		if dist.TotalMatches > 0 {
+			oc.Percentage = math.Round(float64(oc.Count)*10000/float64(dist.TotalMatches)) / 100
+		}
+		dist.Outcomes = append(dist.Outcomes, oc)
+	}
+	return dist, nil
+}
@@ -7,10 +7,39 @@ import (
 )
 
 var (
-	ErrMatchNotFound = errors.New("match not found")
+	ErrMatchNotFound    = errors.New("match not found")
+	ErrImplausibleStats = errors.New("player stats are negative or exceed plausible limits")
 )
 
+// Match outcomes accepted by the matches.outcome CHECK constraint
+const (
+	OutcomeCompleted = "completed"
+	OutcomeFailed    = "failed"
+	OutcomeAbandoned = "abandoned"
+)
+
+// OutcomeCount is the number of a player's matches that ended with Outcome.
+type OutcomeCount struct {
+	Outcome string
+	Count   int64
+	// Percentage of the player's matches, 0-100 rounded to two decimals
+	Percentage float64
+}
+
+// OutcomeDistribution is a player's match outcome breakdown. Outcomes always
+// lists completed, failed and abandoned in that order, even when a count is 0.
+type OutcomeDistribution struct {
+	TotalMatches int64
+	Outcomes     []*OutcomeCount
+}
+
 type Service interface {
-	StoreMatchWithStats(ctx context.Context, serverID int64, matchParams *db.CreateMatchParams, playerStats []*db.CreatePlayerMatchStatsParams) error
-	GetPlayerMatchHistory(ctx context.Context, playerID int64, limit int32) ([]*db.GetPlayerMatchHistoryRow, error)
+	// StoreMatchWithStats records a match and awards its rewards, returning the
+	// match and whether it was created. A repeated matchParams.IdempotencyKey
+	// from the same server returns the original match with created false.
+	StoreMatchWithStats(ctx context.Context, serverID int64, matchParams *db.CreateMatchParams, playerStats []*db.CreatePlayerMatchStatsParams) (match *db.Match, created bool, err error)
+	// GetPlayerMatchHistory pages a player's matches newest first. When
+	// beforeMatchID is set only matches older than it are returned.
+	GetPlayerMatchHistory(ctx context.Context, playerID int64, limit, offset int32, beforeMatchID *int64) ([]*db.GetPlayerMatchHistoryRow, error)
+	GetOutcomeDistribution(ctx context.Context, playerID int64) (*OutcomeDistribution, error)
 }
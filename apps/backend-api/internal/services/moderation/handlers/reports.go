@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/db/types"
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/internal/services/moderation"
+	"ai-zombie-defense/backend-api/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// maxReasonLength bounds the free-text reason attached to a report.
+const maxReasonLength = 500
+
+type ModerationHandlers struct {
+	service moderation.Service
+	logger  *zap.Logger
+}
+
+func NewModerationHandlers(service moderation.Service, logger *zap.Logger) *ModerationHandlers {
+	return &ModerationHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type ReportPlayerRequest struct {
+	Category string  `json:"category"`
+	Reason   *string `json:"reason,omitempty"`
+}
+
+// BanPlayerRequest bans a player permanently unless Until is set.
+type BanPlayerRequest struct {
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+type AdminPlayerResponse struct {
+	PlayerID    int64               `json:"player_id"`
+	Username    string              `json:"username"`
+	Email       string              `json:"email"`
+	IsBanned    bool                `json:"is_banned"`
+	IsAdmin     bool                `json:"is_admin"`
+	CreatedAt   types.Timestamp     `json:"created_at"`
+	LastLoginAt types.NullTimestamp `json:"last_login_at"`
+}
+
+type ReportedPlayerResponse struct {
+	PlayerID        int64    `json:"player_id"`
+	Username        string   `json:"username"`
+	ReportCount     int64    `json:"report_count"`
+	ReporterCount   int64    `json:"reporter_count"`
+	Categories      []string `json:"categories"`
+	FirstReportedAt string   `json:"first_reported_at"`
+	LastReportedAt  string   `json:"last_reported_at"`
+}
+
+// ReportPlayer handles POST /players/:id/report
+func (h *ModerationHandlers) ReportPlayer(c *fiber.Ctx) error {
+	reporterID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	targetID, err := c.ParamsInt("id")
+	if err != nil || targetID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+	var req ReportPlayerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.Reason != nil && len(*req.Reason) > maxReasonLength {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "reason must be at most 500 characters")
+	}
+
+	report, err := h.service.ReportPlayer(c.Context(), reporterID, int64(targetID), req.Category, req.Reason)
+	if err != nil {
+		switch err {
+		case moderation.ErrInvalidCategory:
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "category must be one of cheating, abuse, griefing, other")
+		case moderation.ErrCannotReportSelf:
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeCannotReportSelf, err.Error())
+		case moderation.ErrPlayerNotFound:
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, err.Error())
+		case moderation.ErrAlreadyReported:
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeAlreadyReported, err.Error())
+		case moderation.ErrReportRateLimited:
+			return apierror.Respond(c, fiber.StatusTooManyRequests, apierror.CodeReportRateLimited, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to report player", zap.Error(err), zap.Int64("reporter_id", reporterID), zap.Int("target_id", targetID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"report_id": report.ReportID,
+		"status":    report.Status,
+	})
+}
+
+// ListReports handles GET /admin/reports
+func (h *ModerationHandlers) ListReports(c *fiber.Ctx) error {
+	status := c.Query("status", moderation.StatusOpen)
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	players, err := h.service.ListReportedPlayers(c.Context(), status, int64(limit), int64(offset))
+	if err != nil {
+		if err == moderation.ErrInvalidStatus {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "status must be one of open, resolved, dismissed")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to list reported players", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := make([]ReportedPlayerResponse, 0, len(players))
+	for _, p := range players {
+		resp = append(resp, ReportedPlayerResponse{
+			PlayerID:        p.PlayerID,
+			Username:        p.Username,
+			ReportCount:     p.ReportCount,
+			ReporterCount:   p.ReporterCount,
+			Categories:      p.Categories,
+			FirstReportedAt: p.FirstReportedAt,
+			LastReportedAt:  p.LastReportedAt,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  status,
+		"players": resp,
+	})
+}
+
+// ResolveReports handles POST /admin/players/:id/reports/resolve
+func (h *ModerationHandlers) ResolveReports(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	targetID, err := c.ParamsInt("id")
+	if err != nil || targetID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+
+	closed, err := h.service.ResolveReports(c.Context(), adminID, int64(targetID), req.Status)
+	if err != nil {
+		switch err {
+		case moderation.ErrInvalidStatus:
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "status must be resolved or dismissed")
+		case moderation.ErrNoOpenReports:
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeNoOpenReports, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to resolve reports", zap.Error(err), zap.Int64("admin_id", adminID), zap.Int("target_id", targetID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"player_id":      targetID,
+		"status":         req.Status,
+		"closed_reports": closed,
+	})
+}
+
+// BanPlayer handles POST /admin/players/:id/ban
+func (h *ModerationHandlers) BanPlayer(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	targetID, err := c.ParamsInt("id")
+	if err != nil || targetID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+	var req BanPlayerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > maxReasonLength {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "reason is required and must be at most 500 characters")
+	}
+
+	err = h.service.BanPlayer(c.Context(), adminID, int64(targetID), req.Reason, req.Until)
+	if err != nil {
+		switch err {
+		case moderation.ErrCannotBanSelf, moderation.ErrBanInPast:
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidBan, err.Error())
+		case moderation.ErrPlayerNotFound:
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to ban player", zap.Error(err), zap.Int64("admin_id", adminID), zap.Int("target_id", targetID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"player_id":    targetID,
+		"status":       "banned",
+		"reason":       req.Reason,
+		"banned_until": req.Until,
+	})
+}
+
+// UnbanPlayer handles POST /admin/players/:id/unban
+func (h *ModerationHandlers) UnbanPlayer(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	targetID, err := c.ParamsInt("id")
+	if err != nil || targetID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+
+	if err := h.service.UnbanPlayer(c.Context(), adminID, int64(targetID)); err != nil {
+		if err == moderation.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to unban player", zap.Error(err), zap.Int64("admin_id", adminID), zap.Int("target_id", targetID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"player_id": targetID,
+		"status":    "unbanned",
+	})
+}
+
+// SearchPlayers handles GET /admin/players
+func (h *ModerationHandlers) SearchPlayers(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	players, err := h.service.SearchPlayers(c.Context(), query, int64(limit), int64(offset))
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to search players", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := make([]AdminPlayerResponse, 0, len(players))
+	for _, p := range players {
+		resp = append(resp, AdminPlayerResponse{
+			PlayerID:    p.PlayerID,
+			Username:    p.Username,
+			Email:       p.Email,
+			IsBanned:    p.IsBanned != 0,
+			IsAdmin:     p.IsAdmin != 0,
+			CreatedAt:   p.CreatedAt,
+			LastLoginAt: p.LastLoginAt,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"players": resp,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
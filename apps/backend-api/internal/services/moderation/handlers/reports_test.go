@@ -0,0 +1,497 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/services/moderation"
+	"ai-zombie-defense/backend-api/internal/testutils"
+	"ai-zombie-defense/backend-api/internal/webhook"
+	"ai-zombie-defense/backend-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+func createTestServer(t *testing.T, db *sql.DB, cfg config.Config) *fiber.App {
+	logger := zaptest.NewLogger(t)
+	gw := gateway.NewAPIGateway(cfg, logger, db)
+	return gw.Router()
+}
+
+func doJSON(t *testing.T, app *fiber.App, method, path, token string, payload interface{}) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	return resp
+}
+
+func reportPath(playerID int64) string {
+	return fmt.Sprintf("/players/%d/report", playerID)
+}
+
+func TestModerationHandlers_ReportPlayer(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db, testutils.GetTestConfig())
+
+	reporterID := testutils.CreateTestPlayer(t, db, "reporter", "reporter@example.com", "password123")
+	reporterToken := testutils.CreateTestAccessToken(t, db, reporterID)
+	cheaterID := testutils.CreateTestPlayer(t, db, "cheater", "cheater@example.com", "password123")
+
+	tests := []struct {
+		name     string
+		targetID int64
+		payload  map[string]interface{}
+		wantCode int
+	}{
+		{"valid report", cheaterID, map[string]interface{}{"category": "cheating", "reason": "aimbot"}, http.StatusCreated},
+		{"duplicate open report", cheaterID, map[string]interface{}{"category": "abuse"}, http.StatusConflict},
+		{"unknown category", cheaterID, map[string]interface{}{"category": "rude"}, http.StatusBadRequest},
+		{"self report", reporterID, map[string]interface{}{"category": "other"}, http.StatusBadRequest},
+		{"unknown player", 9999, map[string]interface{}{"category": "other"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doJSON(t, app, http.MethodPost, reportPath(tt.targetID), reporterToken, tt.payload)
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("Expected status %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+		})
+	}
+
+	var stored int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM player_reports WHERE reporter_id = ? AND target_id = ? AND status = 'open'`, reporterID, cheaterID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to count reports: %v", err)
+	}
+	if stored != 1 {
+		t.Errorf("Expected 1 stored report, got %d", stored)
+	}
+}
+
+func TestModerationHandlers_ReportPlayerRateLimit(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	cfg := testutils.GetTestConfig()
+	cfg.Moderation.ReportsPerDay = 2
+	app := createTestServer(t, db, cfg)
+
+	reporterID := testutils.CreateTestPlayer(t, db, "reporter", "reporter@example.com", "password123")
+	reporterToken := testutils.CreateTestAccessToken(t, db, reporterID)
+
+	for i := 0; i < 3; i++ {
+		targetID := testutils.CreateTestPlayer(t, db, fmt.Sprintf("target%d", i), fmt.Sprintf("target%d@example.com", i), "password123")
+		resp := doJSON(t, app, http.MethodPost, reportPath(targetID), reporterToken, map[string]interface{}{"category": "griefing"})
+		want := http.StatusCreated
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("Report %d: expected status %d, got %d", i, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestModerationHandlers_AdminListAndResolve(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db, testutils.GetTestConfig())
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password123")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+	cheaterID := testutils.CreateTestPlayer(t, db, "cheater", "cheater@example.com", "password123")
+	grieferID := testutils.CreateTestPlayer(t, db, "griefer", "griefer@example.com", "password123")
+
+	// Three players report the cheater, one reports the griefer
+	for i, category := range []string{"cheating", "cheating", "abuse"} {
+		reporterID := testutils.CreateTestPlayer(t, db, fmt.Sprintf("reporter%d", i), fmt.Sprintf("reporter%d@example.com", i), "password123")
+		token := testutils.CreateTestAccessToken(t, db, reporterID)
+		if resp := doJSON(t, app, http.MethodPost, reportPath(cheaterID), token, map[string]interface{}{"category": category}); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 for report, got %d", resp.StatusCode)
+		}
+		if i == 0 {
+			if resp := doJSON(t, app, http.MethodPost, reportPath(grieferID), token, map[string]interface{}{"category": "griefing"}); resp.StatusCode != http.StatusCreated {
+				t.Fatalf("Expected status 201 for report, got %d", resp.StatusCode)
+			}
+		}
+	}
+
+	type reportList struct {
+		Players []struct {
+			PlayerID      int64    `json:"player_id"`
+			Username      string   `json:"username"`
+			ReportCount   int64    `json:"report_count"`
+			ReporterCount int64    `json:"reporter_count"`
+			Categories    []string `json:"categories"`
+		} `json:"players"`
+	}
+	listReports := func(query string) reportList {
+		t.Helper()
+		resp := doJSON(t, app, http.MethodGet, "/admin/reports"+query, adminToken, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for list, got %d", resp.StatusCode)
+		}
+		var list reportList
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatalf("Failed to decode list: %v", err)
+		}
+		return list
+	}
+
+	// Non-admins cannot review reports
+	playerToken := testutils.CreateTestAccessToken(t, db, cheaterID)
+	if resp := doJSON(t, app, http.MethodGet, "/admin/reports", playerToken, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin list, got %d", resp.StatusCode)
+	}
+
+	list := listReports("")
+	if len(list.Players) != 2 {
+		t.Fatalf("Expected 2 reported players, got %d", len(list.Players))
+	}
+	top := list.Players[0]
+	if top.PlayerID != cheaterID || top.ReportCount != 3 || top.ReporterCount != 3 || len(top.Categories) != 2 {
+		t.Errorf("Expected cheater aggregated with 3 reports over 2 categories first, got %+v", top)
+	}
+
+	resp := doJSON(t, app, http.MethodPost, fmt.Sprintf("/admin/players/%d/reports/resolve", cheaterID), adminToken, map[string]interface{}{"status": "resolved"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for resolve, got %d", resp.StatusCode)
+	}
+	if list := listReports("?status=open"); len(list.Players) != 1 || list.Players[0].PlayerID != grieferID {
+		t.Errorf("Expected only the griefer to remain open, got %+v", list.Players)
+	}
+	if list := listReports("?status=resolved"); len(list.Players) != 1 || list.Players[0].ReportCount != 3 {
+		t.Errorf("Expected the cheater's 3 reports resolved, got %+v", list.Players)
+	}
+
+	// Nothing left to resolve, and statuses are validated
+	resp = doJSON(t, app, http.MethodPost, fmt.Sprintf("/admin/players/%d/reports/resolve", cheaterID), adminToken, map[string]interface{}{"status": "dismissed"})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 with no open reports, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, app, http.MethodGet, "/admin/reports?status=pending", adminToken, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown status, got %d", resp.StatusCode)
+	}
+}
+
+func banPath(playerID int64, action string) string {
+	return fmt.Sprintf("/admin/players/%d/%s", playerID, action)
+}
+
+// setupBanTest creates an admin and a target player, returning the app, the admin's token and the target's ID.
+func setupBanTest(t *testing.T, db *sql.DB) (*fiber.App, string, int64) {
+	t.Helper()
+	app := createTestServer(t, db, testutils.GetTestConfig())
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password123")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	targetID := testutils.CreateTestPlayer(t, db, "cheater", "cheater@example.com", "password123")
+	return app, testutils.CreateTestAccessToken(t, db, adminID), targetID
+}
+
+func login(t *testing.T, app *fiber.App) int {
+	t.Helper()
+	resp := doJSON(t, app, http.MethodPost, "/auth/login", "", map[string]interface{}{
+		"username_or_email": "cheater",
+		"password":          "password123",
+	})
+	return resp.StatusCode
+}
+
+func TestModerationHandlers_BanPlayerPermanent(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app, adminToken, targetID := setupBanTest(t, db)
+	refreshToken := testutils.CreateTestSession(t, db, targetID)
+
+	// Non-admins cannot ban, and a reason is required
+	playerToken := testutils.CreateTestAccessToken(t, db, targetID)
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), playerToken, map[string]interface{}{"reason": "aimbot"}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin ban, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{"reason": " "}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a reason, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(9999, "ban"), adminToken, map[string]interface{}{"reason": "aimbot"}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+
+	resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{"reason": "aimbot"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for ban, got %d", resp.StatusCode)
+	}
+	var reason string
+	var bannedUntil sql.NullString
+	if err := db.QueryRow(`SELECT banned_reason, banned_until FROM players WHERE player_id = ?`, targetID).Scan(&reason, &bannedUntil); err != nil {
+		t.Fatalf("Failed to read ban: %v", err)
+	}
+	if reason != "aimbot" || bannedUntil.Valid {
+		t.Errorf("Expected permanent ban for aimbot, got reason %q until %v", reason, bannedUntil)
+	}
+
+	// Existing sessions are revoked and the player can no longer log in
+	if resp := doJSON(t, app, http.MethodPost, "/auth/refresh", "", map[string]interface{}{"refresh_token": refreshToken}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 refreshing a revoked session, got %d", resp.StatusCode)
+	}
+	if status := login(t, app); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 logging in while banned, got %d", status)
+	}
+}
+
+func TestModerationHandlers_TempBanExpiry(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app, adminToken, targetID := setupBanTest(t, db)
+
+	resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{
+		"reason": "griefing",
+		"until":  time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a ban ending in the past, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{
+		"reason": "griefing",
+		"until":  time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for temp ban, got %d", resp.StatusCode)
+	}
+	if status := login(t, app); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 during temp ban, got %d", status)
+	}
+
+	// Once banned_until has passed the ban lifts by itself
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`UPDATE players SET banned_until = ? WHERE player_id = ?`, past, targetID); err != nil {
+		t.Fatalf("Failed to expire ban: %v", err)
+	}
+	if status := login(t, app); status != http.StatusOK {
+		t.Errorf("Expected status 200 after temp ban expired, got %d", status)
+	}
+}
+
+func TestModerationHandlers_UnbanPlayer(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app, adminToken, targetID := setupBanTest(t, db)
+
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{"reason": "abuse"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for ban, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "unban"), adminToken, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for unban, got %d", resp.StatusCode)
+	}
+	var isBanned int64
+	var reason sql.NullString
+	if err := db.QueryRow(`SELECT is_banned, banned_reason FROM players WHERE player_id = ?`, targetID).Scan(&isBanned, &reason); err != nil {
+		t.Fatalf("Failed to read ban: %v", err)
+	}
+	if isBanned != 0 || reason.Valid {
+		t.Errorf("Expected ban cleared, got is_banned %d reason %v", isBanned, reason)
+	}
+	if status := login(t, app); status != http.StatusOK {
+		t.Errorf("Expected status 200 after unban, got %d", status)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(9999, "unban"), adminToken, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+}
+
+func TestModerationHandlers_SearchPlayers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app, adminToken, cheaterID := setupBanTest(t, db)
+	testutils.CreateTestPlayer(t, db, "zombie_hunter", "hunter@example.com", "password123")
+	testutils.CreateTestPlayer(t, db, "zombieXhunter", "x@zombies.test", "password123")
+	if resp := doJSON(t, app, http.MethodPost, banPath(cheaterID, "ban"), adminToken, map[string]interface{}{"reason": "aimbot"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for ban, got %d", resp.StatusCode)
+	}
+
+	type playerList struct {
+		Players []struct {
+			PlayerID  int64   `json:"player_id"`
+			Username  string  `json:"username"`
+			Email     string  `json:"email"`
+			IsBanned  bool    `json:"is_banned"`
+			IsAdmin   bool    `json:"is_admin"`
+			CreatedAt string  `json:"created_at"`
+			LastLogin *string `json:"last_login_at"`
+		} `json:"players"`
+	}
+	search := func(query string) playerList {
+		t.Helper()
+		resp := doJSON(t, app, http.MethodGet, "/admin/players"+query, adminToken, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for search, got %d", resp.StatusCode)
+		}
+		var list playerList
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatalf("Failed to decode players: %v", err)
+		}
+		return list
+	}
+
+	// Partial usernames match, and '_' is matched literally rather than as a wildcard
+	if list := search("?q=zombie"); len(list.Players) != 2 {
+		t.Errorf("Expected 2 players matching zombie, got %+v", list.Players)
+	}
+	if list := search("?q=e_h"); len(list.Players) != 1 || list.Players[0].Username != "zombie_hunter" {
+		t.Errorf("Expected only zombie_hunter for e_h, got %+v", list.Players)
+	}
+	if list := search("?q=zombies.test"); len(list.Players) != 1 || list.Players[0].Username != "zombieXhunter" {
+		t.Errorf("Expected email match for zombieXhunter, got %+v", list.Players)
+	}
+
+	// Ban and admin flags surface
+	list := search("?q=cheat")
+	if len(list.Players) != 1 || !list.Players[0].IsBanned || list.Players[0].IsAdmin || list.Players[0].CreatedAt == "" {
+		t.Errorf("Expected banned non-admin cheater, got %+v", list.Players)
+	}
+	if list := search("?q=admin"); len(list.Players) != 1 || !list.Players[0].IsAdmin || list.Players[0].IsBanned {
+		t.Errorf("Expected unbanned admin, got %+v", list.Players)
+	}
+
+	// No query lists everyone by username, paginated
+	all := search("")
+	if len(all.Players) != 4 || all.Players[0].Username != "admin" {
+		t.Fatalf("Expected 4 players starting with admin, got %+v", all.Players)
+	}
+	if page := search("?limit=2&offset=2"); len(page.Players) != 2 || page.Players[0].PlayerID != all.Players[2].PlayerID {
+		t.Errorf("Expected second page to start at %s, got %+v", all.Players[2].Username, page.Players)
+	}
+
+	playerToken := testutils.CreateTestAccessToken(t, db, cheaterID)
+	if resp := doJSON(t, app, http.MethodGet, "/admin/players", playerToken, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin search, got %d", resp.StatusCode)
+	}
+}
+
+func TestModerationHandlers_BanRevokesAdminAccess(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app, adminToken, rogueID := setupBanTest(t, db)
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, rogueID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	// A token issued before the is_admin claim existed, so AdminMiddleware
+	// falls back to the cached IsAdmin lookup
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(rogueID, 10),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	rogueToken, err := legacy.SignedString([]byte(testutils.GetTestConfig().JWT.Secret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	// The rogue admin's status is cached by the first admin request
+	if resp := doJSON(t, app, http.MethodGet, "/admin/reports", rogueToken, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for admin, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(rogueID, "ban"), adminToken, map[string]interface{}{"reason": "abuse of power"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for ban, got %d", resp.StatusCode)
+	}
+	// Banning drops the cached status, so admin access ends immediately
+	if resp := doJSON(t, app, http.MethodGet, "/admin/reports", rogueToken, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for banned admin, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(rogueID, "unban"), adminToken, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for unban, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodGet, "/admin/reports", rogueToken, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after unban, got %d", resp.StatusCode)
+	}
+}
+
+func TestModerationHandlers_PublishesWebhookEvents(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	var mu sync.Mutex
+	var received []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evt webhook.Event
+		if err := json.NewDecoder(r.Body).Decode(&evt); err == nil {
+			mu.Lock()
+			received = append(received, evt.Type)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer endpoint.Close()
+
+	cfg := testutils.GetTestConfig()
+	cfg.Webhook = config.WebhookConfig{URL: endpoint.URL, QueueSize: 10, DropPolicy: webhook.DropOldest, Timeout: time.Second}
+	dispatcher := webhook.NewDispatcher(cfg.Webhook, zaptest.NewLogger(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db, gateway.WithWebhookDispatcher(dispatcher)).Router()
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password123")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+	targetID := testutils.CreateTestPlayer(t, db, "cheater", "cheater@example.com", "password123")
+
+	if resp := doJSON(t, app, http.MethodPost, reportPath(targetID), adminToken, map[string]interface{}{"category": "cheating"}); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 for report, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "ban"), adminToken, map[string]interface{}{"reason": "aimbot"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for ban, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, app, http.MethodPost, banPath(targetID, "unban"), adminToken, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for unban, got %d", resp.StatusCode)
+	}
+
+	want := []string{moderation.EventPlayerReported, moderation.EventPlayerBanned, moderation.EventPlayerUnbanned}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		got := append([]string(nil), received...)
+		mu.Unlock()
+		if len(got) >= len(want) {
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("Expected events %v, got %v", want, got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for webhook events, got %v", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
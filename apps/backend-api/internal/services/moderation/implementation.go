@@ -0,0 +1,244 @@
+package moderation
+
+import (
+	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/db/types"
+	"ai-zombie-defense/backend-api/internal/services/auth"
+	"ai-zombie-defense/backend-api/internal/webhook"
+	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reportWindow is the rolling window Moderation.ReportsPerDay applies to.
+const reportWindow = 24 * time.Hour
+
+type moderationService struct {
+	config  config.Config
+	logger  *zap.Logger
+	dbConn  db.DBTX
+	queries *db.Queries
+	authSvc auth.Service
+	// events receives a webhook event per moderation action; nil when no
+	// webhook endpoint is configured.
+	events webhook.Publisher
+}
+
+func NewModerationService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX, authSvc auth.Service, events webhook.Publisher) Service {
+	return &moderationService{
+		config:  cfg,
+		logger:  logger,
+		dbConn:  dbConn,
+		queries: db.New(),
+		authSvc: authSvc,
+		events:  events,
+	}
+}
+
+// publish queues a webhook event, logging when the queue drops it.
+func (s *moderationService) publish(ctx context.Context, eventType string, payload interface{}) {
+	if s.events == nil {
+		return
+	}
+	if !s.events.Enqueue(webhook.Event{Type: eventType, Payload: payload}) {
+		logging.FromContext(ctx, s.logger).Warn("webhook event dropped", zap.String("event_type", eventType))
+	}
+}
+
+func (s *moderationService) ReportPlayer(ctx context.Context, reporterID, targetID int64, category string, reason *string) (*db.PlayerReport, error) {
+	if reporterID == targetID {
+		return nil, ErrCannotReportSelf
+	}
+	switch category {
+	case CategoryCheating, CategoryAbuse, CategoryGriefing, CategoryOther:
+	default:
+		return nil, ErrInvalidCategory
+	}
+	if _, err := s.queries.GetPlayer(ctx, s.dbConn, targetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	recent, err := s.queries.CountPlayerReportsByReporterSince(ctx, s.dbConn, &db.CountPlayerReportsByReporterSinceParams{
+		ReporterID: reporterID,
+		CreatedAt:  types.Timestamp{Time: time.Now().Add(-reportWindow)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recent reports: %w", err)
+	}
+	if recent >= int64(s.config.Moderation.ReportsPerDay) {
+		logging.FromContext(ctx, s.logger).Info("player report rate limited", zap.Int64("reporter_id", reporterID), zap.Int64("recent_reports", recent))
+		return nil, ErrReportRateLimited
+	}
+
+	report, err := s.queries.CreatePlayerReport(ctx, s.dbConn, &db.CreatePlayerReportParams{
+		ReporterID: reporterID,
+		TargetID:   targetID,
+		Category:   category,
+		Reason:     reason,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, ErrAlreadyReported
+		}
+		return nil, fmt.Errorf("failed to create player report: %w", err)
+	}
+	s.publish(ctx, EventPlayerReported, report)
+	return report, nil
+}
+
+func (s *moderationService) ListReportedPlayers(ctx context.Context, status string, limit, offset int64) ([]*ReportedPlayer, error) {
+	if !isValidStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	rows, err := s.queries.ListReportedPlayers(ctx, s.dbConn, &db.ListReportedPlayersParams{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reported players: %w", err)
+	}
+	players := make([]*ReportedPlayer, 0, len(rows))
+	for _, row := range rows {
+		players = append(players, &ReportedPlayer{
+			PlayerID:        row.TargetID,
+			Username:        row.Username,
+			ReportCount:     row.ReportCount,
+			ReporterCount:   row.ReporterCount,
+			Categories:      strings.Split(row.Categories, ","),
+			FirstReportedAt: row.FirstReportedAt,
+			LastReportedAt:  row.LastReportedAt,
+		})
+	}
+	return players, nil
+}
+
+func (s *moderationService) ResolveReports(ctx context.Context, adminID, targetID int64, status string) (int64, error) {
+	if status != StatusResolved && status != StatusDismissed {
+		return 0, ErrInvalidStatus
+	}
+	closed, err := s.queries.ResolvePlayerReports(ctx, s.dbConn, &db.ResolvePlayerReportsParams{
+		Status:     status,
+		ResolvedBy: &adminID,
+		TargetID:   targetID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve player reports: %w", err)
+	}
+	if closed == 0 {
+		return 0, ErrNoOpenReports
+	}
+	logging.FromContext(ctx, s.logger).Info("player reports closed",
+		zap.Int64("admin_id", adminID),
+		zap.Int64("target_id", targetID),
+		zap.String("status", status),
+		zap.Int64("count", closed))
+	s.publish(ctx, EventReportsResolved, map[string]interface{}{
+		"admin_id":  adminID,
+		"target_id": targetID,
+		"status":    status,
+		"count":     closed,
+	})
+	return closed, nil
+}
+
+func (s *moderationService) BanPlayer(ctx context.Context, adminID, targetID int64, reason string, until *time.Time) error {
+	if adminID == targetID {
+		return ErrCannotBanSelf
+	}
+	bannedUntil := types.NullTimestamp{}
+	if until != nil {
+		if !until.After(time.Now()) {
+			return ErrBanInPast
+		}
+		bannedUntil = types.NullTimestamp{Timestamp: types.Timestamp{Time: until.UTC()}, Valid: true}
+	}
+
+	var revoked int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		banned, err := s.queries.BanPlayer(ctx, tx, &db.BanPlayerParams{
+			BannedReason: &reason,
+			BannedUntil:  bannedUntil,
+			PlayerID:     targetID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to ban player: %w", err)
+		}
+		if banned == 0 {
+			return ErrPlayerNotFound
+		}
+		revoked, err = s.queries.DeleteAllSessionsForPlayer(ctx, tx, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to delete sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.authSvc.InvalidateAdminStatus(targetID)
+	revokedTokens := s.authSvc.RevokeAccessTokens(targetID)
+	logging.FromContext(ctx, s.logger).Info("player banned",
+		zap.Int64("admin_id", adminID),
+		zap.Int64("target_id", targetID),
+		zap.Bool("permanent", until == nil),
+		zap.Int64("revoked_sessions", revoked),
+		zap.Int("revoked_access_tokens", revokedTokens))
+	s.publish(ctx, EventPlayerBanned, map[string]interface{}{
+		"admin_id":     adminID,
+		"target_id":    targetID,
+		"reason":       reason,
+		"banned_until": until,
+	})
+	return nil
+}
+
+func (s *moderationService) UnbanPlayer(ctx context.Context, adminID, targetID int64) error {
+	unbanned, err := s.queries.UnbanPlayer(ctx, s.dbConn, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to unban player: %w", err)
+	}
+	if unbanned == 0 {
+		return ErrPlayerNotFound
+	}
+	s.authSvc.InvalidateAdminStatus(targetID)
+	logging.FromContext(ctx, s.logger).Info("player unbanned", zap.Int64("admin_id", adminID), zap.Int64("target_id", targetID))
+	s.publish(ctx, EventPlayerUnbanned, map[string]interface{}{
+		"admin_id":  adminID,
+		"target_id": targetID,
+	})
+	return nil
+}
+
+// likeEscaper escapes LIKE wildcards so a search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *moderationService) SearchPlayers(ctx context.Context, query string, limit, offset int64) ([]*db.SearchPlayersRow, error) {
+	params := &db.SearchPlayersParams{
+		Limit:  limit,
+		Offset: offset,
+	}
+	if query != "" {
+		escaped := likeEscaper.Replace(query)
+		params.Query = &escaped
+	}
+	players, err := s.queries.SearchPlayers(ctx, s.dbConn, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search players: %w", err)
+	}
+	return players, nil
+}
+
+func isValidStatus(status string) bool {
+	return status == StatusOpen || status == StatusResolved || status == StatusDismissed
+}
@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"ai-zombie-defense/backend-api/internal/db"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPlayerNotFound    = errors.New("player not found")
+	ErrCannotReportSelf  = errors.New("cannot report yourself")
+	ErrInvalidCategory   = errors.New("invalid report category")
+	ErrInvalidStatus     = errors.New("invalid report status")
+	ErrAlreadyReported   = errors.New("player already has an open report from you")
+	ErrReportRateLimited = errors.New("daily report limit reached")
+	ErrNoOpenReports     = errors.New("player has no open reports")
+	ErrCannotBanSelf     = errors.New("cannot ban yourself")
+	ErrBanInPast         = errors.New("ban end must be in the future")
+)
+
+// Report categories accepted by the player_reports.category CHECK constraint
+const (
+	CategoryCheating = "cheating"
+	CategoryAbuse    = "abuse"
+	CategoryGriefing = "griefing"
+	CategoryOther    = "other"
+)
+
+// Report statuses; open reports are resolved (action taken) or dismissed by an admin.
+const (
+	StatusOpen      = "open"
+	StatusResolved  = "resolved"
+	StatusDismissed = "dismissed"
+)
+
+// Webhook event types published for moderation actions
+const (
+	EventPlayerReported  = "player.reported"
+	EventReportsResolved = "player.reports_resolved"
+	EventPlayerBanned    = "player.banned"
+	EventPlayerUnbanned  = "player.unbanned"
+)
+
+// ReportedPlayer aggregates every report with the same status against one player.
+type ReportedPlayer struct {
+	PlayerID        int64
+	Username        string
+	ReportCount     int64
+	ReporterCount   int64
+	Categories      []string
+	FirstReportedAt string
+	LastReportedAt  string
+}
+
+type Service interface {
+	// ReportPlayer files a report against targetID, limited to one open report
+	// per reporter and target and to Moderation.ReportsPerDay per reporter.
+	ReportPlayer(ctx context.Context, reporterID, targetID int64, category string, reason *string) (*db.PlayerReport, error)
+	ListReportedPlayers(ctx context.Context, status string, limit, offset int64) ([]*ReportedPlayer, error)
+	// ResolveReports closes every open report against targetID with the given
+	// status and returns how many were closed.
+	ResolveReports(ctx context.Context, adminID, targetID int64, status string) (int64, error)
+	// BanPlayer bans targetID until the given time, or permanently when until is
+	// nil, and revokes all of the player's sessions.
+	BanPlayer(ctx context.Context, adminID, targetID int64, reason string, until *time.Time) error
+	UnbanPlayer(ctx context.Context, adminID, targetID int64) error
+	// SearchPlayers returns players whose username or email contains query,
+	// ordered by username; an empty query lists every player.
+	SearchPlayers(ctx context.Context, query string, limit, offset int64) ([]*db.SearchPlayersRow, error)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/middleware"
+	"ai-zombie-defense/backend-api/internal/services/notification"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type NotificationHandlers struct {
+	service notification.Service
+	logger  *zap.Logger
+}
+
+func NewNotificationHandlers(service notification.Service, logger *zap.Logger) *NotificationHandlers {
+	return &NotificationHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type NotificationResponse struct {
+	NotificationID   int64   `json:"notification_id"`
+	NotificationType string  `json:"notification_type"`
+	Message          string  `json:"message"`
+	ReferenceID      *int64  `json:"reference_id,omitempty"`
+	IsRead           bool    `json:"is_read"`
+	CreatedAt        string  `json:"created_at"`
+	ReadAt           *string `json:"read_at,omitempty"`
+}
+
+type ListNotificationsResponse struct {
+	UnreadCount   int64                  `json:"unread_count"`
+	Notifications []NotificationResponse `json:"notifications"`
+}
+
+type BroadcastNotificationRequest struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ListNotifications handles GET /notifications
+func (h *NotificationHandlers) ListNotifications(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	// Parse limit query parameter (default 50, max 100)
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	notifications, err := h.service.ListNotifications(c.Context(), playerID, int64(limit))
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list notifications", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve notifications")
+	}
+	unread, err := h.service.CountUnread(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to count unread notifications", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve notifications")
+	}
+
+	resp := ListNotificationsResponse{
+		UnreadCount:   unread,
+		Notifications: make([]NotificationResponse, 0, len(notifications)),
+	}
+	for _, n := range notifications {
+		item := NotificationResponse{
+			NotificationID:   n.NotificationID,
+			NotificationType: n.NotificationType,
+			Message:          n.Message,
+			ReferenceID:      n.ReferenceID,
+			IsRead:           n.IsRead == 1,
+			CreatedAt:        n.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		}
+		if n.ReadAt.Valid {
+			readAt := n.ReadAt.Time.Format("2006-01-02T15:04:05Z")
+			item.ReadAt = &readAt
+		}
+		resp.Notifications = append(resp.Notifications, item)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// MarkRead handles POST /notifications/:id/read
+func (h *NotificationHandlers) MarkRead(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	notificationID, err := c.ParamsInt("id")
+	if err != nil || notificationID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid notification ID")
+	}
+
+	err = h.service.MarkRead(c.Context(), playerID, int64(notificationID))
+	if err != nil {
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeNotificationNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to mark notification read", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to mark notification read")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "read",
+	})
+}
+
+// Broadcast handles POST /admin/notifications/broadcast
+func (h *NotificationHandlers) Broadcast(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	var req BroadcastNotificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
+	}
+	if req.Type == "" {
+		req.Type = notification.TypeAnnouncement
+	}
+	if req.Type != notification.TypeAnnouncement && req.Type != notification.TypeMaintenance {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid type, must be 'announcement' or 'maintenance'")
+	}
+
+	count, err := h.service.Broadcast(c.Context(), req.Type, req.Message)
+	if err != nil {
+		if errors.Is(err, notification.ErrInvalidNotification) {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "message is required")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to broadcast notification", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to broadcast notification")
+	}
+
+	logging.FromContext(c.Context(), h.logger).Info("audit: notification broadcast",
+		zap.Int64("admin_id", adminID),
+		zap.String("notification_type", req.Type),
+		zap.Int64("recipients", count))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"recipients": count,
+	})
+}
@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/testutils"
+	"ai-zombie-defense/backend-api/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+func createTestServer(t *testing.T, db *sql.DB, cfg config.Config) *fiber.App {
+	logger := zaptest.NewLogger(t)
+	gw := gateway.NewAPIGateway(cfg, logger, db)
+	return gw.Router()
+}
+
+type notificationList struct {
+	UnreadCount   int64 `json:"unread_count"`
+	Notifications []struct {
+		NotificationID   int64  `json:"notification_id"`
+		NotificationType string `json:"notification_type"`
+		Message          string `json:"message"`
+		ReferenceID      *int64 `json:"reference_id"`
+		IsRead           bool   `json:"is_read"`
+	} `json:"notifications"`
+}
+
+func doJSON(t *testing.T, app *fiber.App, method, path, token string, payload interface{}) *http.Response {
+	t.Helper()
+	var body *bytes.Reader
+	if payload != nil {
+		b, _ := json.Marshal(payload)
+		body = bytes.NewReader(b)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request %s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+func listNotifications(t *testing.T, app *fiber.App, token string) notificationList {
+	t.Helper()
+	resp := doJSON(t, app, http.MethodGet, "/notifications", token, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 listing notifications, got %d", resp.StatusCode)
+	}
+	var list notificationList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode notifications: %v", err)
+	}
+	return list
+}
+
+func TestNotificationHandlers_FriendAcceptedNotification(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServer(t, db, testutils.GetTestConfig())
+
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+
+	resp := doJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 for friend request, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, app, http.MethodPut, "/friends/"+strconv.FormatInt(player1ID, 10), token2, map[string]interface{}{"action": "accept"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for accept, got %d", resp.StatusCode)
+	}
+
+	list := listNotifications(t, app, token1)
+	if list.UnreadCount != 1 || len(list.Notifications) != 1 {
+		t.Fatalf("Expected 1 unread notification, got unread=%d len=%d", list.UnreadCount, len(list.Notifications))
+	}
+	n := list.Notifications[0]
+	if n.NotificationType != "friend_accepted" {
+		t.Errorf("Expected type friend_accepted, got %s", n.NotificationType)
+	}
+	if n.Message != "player2 accepted your friend request" {
+		t.Errorf("Unexpected message: %s", n.Message)
+	}
+	if n.ReferenceID == nil || *n.ReferenceID != player2ID {
+		t.Errorf("Expected reference_id %d, got %v", player2ID, n.ReferenceID)
+	}
+
+	// Another player cannot acknowledge it
+	resp = doJSON(t, app, http.MethodPost, "/notifications/"+strconv.FormatInt(n.NotificationID, 10)+"/read", token2, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for foreign notification, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, app, http.MethodPost, "/notifications/"+strconv.FormatInt(n.NotificationID, 10)+"/read", token1, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for mark read, got %d", resp.StatusCode)
+	}
+
+	list = listNotifications(t, app, token1)
+	if list.UnreadCount != 0 {
+		t.Errorf("Expected 0 unread after acknowledging, got %d", list.UnreadCount)
+	}
+	if len(list.Notifications) != 1 || !list.Notifications[0].IsRead {
+		t.Errorf("Expected the notification to be listed as read")
+	}
+}
+
+func TestNotificationHandlers_BroadcastUnreadFirstAndCapped(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	cfg := testutils.GetTestConfig()
+	cfg.Notification.MaxUnreadPerPlayer = 2
+	app := createTestServer(t, db, cfg)
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password123")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+	playerID := testutils.CreateTestPlayer(t, db, "player", "player@example.com", "password123")
+	playerToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	// Non-admins cannot broadcast
+	resp := doJSON(t, app, http.MethodPost, "/admin/notifications/broadcast", playerToken, map[string]interface{}{"message": "hi"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin broadcast, got %d", resp.StatusCode)
+	}
+
+	messages := []string{"first", "second", "third"}
+	for i, msg := range messages {
+		resp = doJSON(t, app, http.MethodPost, "/admin/notifications/broadcast", adminToken, map[string]interface{}{"type": "maintenance", "message": msg})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 for broadcast, got %d", resp.StatusCode)
+		}
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode broadcast response: %v", err)
+		}
+		if result["recipients"].(float64) != 2 {
+			t.Errorf("Broadcast %d: expected 2 recipients, got %v", i, result["recipients"])
+		}
+		if i == 0 {
+			// Read the first one so it sorts after the unread ones and isn't capped
+			list := listNotifications(t, app, playerToken)
+			resp = doJSON(t, app, http.MethodPost, "/notifications/"+strconv.FormatInt(list.Notifications[0].NotificationID, 10)+"/read", playerToken, nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200 for mark read, got %d", resp.StatusCode)
+			}
+		}
+	}
+
+	resp = doJSON(t, app, http.MethodPost, "/admin/notifications/broadcast", adminToken, map[string]interface{}{"type": "maintenance", "message": "fourth"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 for broadcast, got %d", resp.StatusCode)
+	}
+
+	// Cap of 2 unread drops "second"; the read "first" is kept and listed last
+	list := listNotifications(t, app, playerToken)
+	if list.UnreadCount != 2 {
+		t.Errorf("Expected unread count capped at 2, got %d", list.UnreadCount)
+	}
+	got := make([]string, 0, len(list.Notifications))
+	for _, n := range list.Notifications {
+		got = append(got, n.Message)
+	}
+	want := []string{"fourth", "third", "first"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected notifications %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected notifications %v, got %v", want, got)
+		}
+	}
+	if !list.Notifications[2].IsRead {
+		t.Errorf("Expected last notification to be read")
+	}
+}
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type notificationService struct {
+	config  config.Config
+	logger  *zap.Logger
+	dbConn  db.DBTX
+	queries *db.Queries
+}
+
+func NewNotificationService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX) Service {
+	return &notificationService{
+		config:  cfg,
+		logger:  logger,
+		dbConn:  dbConn,
+		queries: db.New(),
+	}
+}
+
+func (s *notificationService) Notify(ctx context.Context, playerID int64, notificationType string, message string, referenceID *int64) error {
+	if notificationType == "" || strings.TrimSpace(message) == "" {
+		return ErrInvalidNotification
+	}
+	err := s.queries.CreateNotification(ctx, s.dbConn, &db.CreateNotificationParams{
+		PlayerID:         playerID,
+		NotificationType: notificationType,
+		Message:          message,
+		ReferenceID:      referenceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	if maxUnread := s.config.Notification.MaxUnreadPerPlayer; maxUnread > 0 {
+		err = s.queries.TrimUnreadNotifications(ctx, s.dbConn, &db.TrimUnreadNotificationsParams{
+			PlayerID:   playerID,
+			PlayerID_2: playerID,
+			Limit:      int64(maxUnread),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to trim unread notifications: %w", err)
+		}
+	}
+
+	logging.FromContext(ctx, s.logger).Debug("Notification created",
+		zap.Int64("player_id", playerID),
+		zap.String("notification_type", notificationType))
+	return nil
+}
+
+// Broadcast sends a notification to every non-banned player and returns how many were created.
+func (s *notificationService) Broadcast(ctx context.Context, notificationType string, message string) (int64, error) {
+	if notificationType == "" || strings.TrimSpace(message) == "" {
+		return 0, ErrInvalidNotification
+	}
+	count, err := s.queries.BroadcastNotification(ctx, s.dbConn, &db.BroadcastNotificationParams{
+		NotificationType: notificationType,
+		Message:          message,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to broadcast notification: %w", err)
+	}
+
+	if maxUnread := s.config.Notification.MaxUnreadPerPlayer; maxUnread > 0 {
+		if err := s.queries.TrimAllUnreadNotifications(ctx, s.dbConn, int64(maxUnread)); err != nil {
+			return count, fmt.Errorf("failed to trim unread notifications: %w", err)
+		}
+	}
+
+	logging.FromContext(ctx, s.logger).Info("Notification broadcast",
+		zap.String("notification_type", notificationType),
+		zap.Int64("recipients", count))
+	return count, nil
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, playerID int64, limit int64) ([]*db.Notification, error) {
+	notifications, err := s.queries.ListNotifications(ctx, s.dbConn, &db.ListNotificationsParams{
+		PlayerID: playerID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (s *notificationService) CountUnread(ctx context.Context, playerID int64) (int64, error) {
+	count, err := s.queries.CountUnreadNotifications(ctx, s.dbConn, playerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, playerID int64, notificationID int64) error {
+	rows, err := s.queries.MarkNotificationRead(ctx, s.dbConn, &db.MarkNotificationReadParams{
+		NotificationID: notificationID,
+		PlayerID:       playerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"ai-zombie-defense/backend-api/internal/db"
+	"context"
+	"errors"
+)
+
+// Notification types produced by the backend.
+const (
+	TypeFriendAccepted = "friend_accepted"
+	TypeGiftReceived   = "gift_received"
+	TypeMaintenance    = "maintenance"
+	TypeAnnouncement   = "announcement"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidNotification  = errors.New("invalid notification")
+)
+
+type Service interface {
+	Notify(ctx context.Context, playerID int64, notificationType string, message string, referenceID *int64) error
+	Broadcast(ctx context.Context, notificationType string, message string) (int64, error)
+	ListNotifications(ctx context.Context, playerID int64, limit int64) ([]*db.Notification, error)
+	CountUnread(ctx context.Context, playerID int64) (int64, error)
+	MarkRead(ctx context.Context, playerID int64, notificationID int64) error
+}
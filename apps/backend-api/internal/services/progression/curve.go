@@ -0,0 +1,110 @@
+package progression
+
+import (
+	"math"
+
+	"ai-zombie-defense/backend-api/pkg/config"
+)
+
+// XP curve types accepted by config.ProgressionConfig.CurveType
+const (
+	CurveLinear      = "linear"
+	CurveExponential = "exponential"
+	CurveTable       = "table"
+)
+
+const (
+	defaultBaseXPPerLevel = 1000
+	// maxCurveLevel bounds level searches so a misconfigured curve can't spin forever
+	maxCurveLevel = 10000
+)
+
+// XPForNextLevel returns the XP a player at level needs to reach level+1.
+// It returns 0 when level is the highest level a table curve defines.
+func XPForNextLevel(cfg config.ProgressionConfig, level int64) int64 {
+	if level < 1 {
+		level = 1
+	}
+	switch cfg.CurveType {
+	case CurveExponential:
+		cost := float64(baseXP(cfg)) * math.Pow(growthFactor(cfg), float64(level-1))
+		if cost >= math.MaxInt64 {
+			return math.MaxInt64
+		}
+		return int64(math.Round(cost))
+	case CurveTable:
+		if level > int64(len(cfg.XPTable)) {
+			return 0
+		}
+		return XPThreshold(cfg, level+1) - XPThreshold(cfg, level)
+	default:
+		return baseXP(cfg)
+	}
+}
+
+// XPThreshold returns the cumulative XP required to reach level. Level 1 is free.
+func XPThreshold(cfg config.ProgressionConfig, level int64) int64 {
+	if level <= 1 {
+		return 0
+	}
+	switch cfg.CurveType {
+	case CurveExponential:
+		var total int64
+		for l := int64(1); l < level; l++ {
+			cost := XPForNextLevel(cfg, l)
+			if total > math.MaxInt64-cost {
+				return math.MaxInt64
+			}
+			total += cost
+		}
+		return total
+	case CurveTable:
+		// XPTable[i] is the cumulative XP needed for level i+2
+		if level-2 >= int64(len(cfg.XPTable)) {
+			return math.MaxInt64
+		}
+		return cfg.XPTable[level-2]
+	default:
+		return (level - 1) * baseXP(cfg)
+	}
+}
+
+// LevelForXP returns the level reached with xp total experience. Zero or
+// negative XP is always level 1.
+func LevelForXP(cfg config.ProgressionConfig, xp int64) int64 {
+	if xp <= 0 {
+		return 1
+	}
+	switch cfg.CurveType {
+	case CurveExponential, CurveTable:
+		level := int64(1)
+		threshold := int64(0)
+		for level < maxCurveLevel {
+			cost := XPForNextLevel(cfg, level)
+			if cost <= 0 || threshold > math.MaxInt64-cost || xp < threshold+cost {
+				break
+			}
+			threshold += cost
+			level++
+		}
+		return level
+	default:
+		return xp/baseXP(cfg) + 1
+	}
+}
+
+func baseXP(cfg config.ProgressionConfig) int64 {
+	if cfg.BaseXPPerLevel <= 0 {
+		return defaultBaseXPPerLevel
+	}
+	return int64(cfg.BaseXPPerLevel)
+}
+
+// growthFactor falls back to 1 (flat costs) for factors that would make each
+// level cheaper than the last.
+func growthFactor(cfg config.ProgressionConfig) float64 {
+	if cfg.CurveGrowthFactor < 1 {
+		return 1
+	}
+	return cfg.CurveGrowthFactor
+}
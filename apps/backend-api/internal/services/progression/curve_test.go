@@ -0,0 +1,59 @@
+package progression_test
+
+import (
+	"testing"
+
+	"ai-zombie-defense/backend-api/internal/services/progression"
+	"ai-zombie-defense/backend-api/pkg/config"
+)
+
+func TestLevelForXP(t *testing.T) {
+	linear := config.ProgressionConfig{BaseXPPerLevel: 1000}
+	exponential := config.ProgressionConfig{BaseXPPerLevel: 100, CurveType: progression.CurveExponential, CurveGrowthFactor: 2}
+	table := config.ProgressionConfig{CurveType: progression.CurveTable, XPTable: []int64{50, 200, 500}}
+
+	tests := []struct {
+		name  string
+		cfg   config.ProgressionConfig
+		xp    int64
+		level int64
+	}{
+		{"linear floor for negative XP", linear, -50, 1},
+		{"linear floor for zero XP", linear, 0, 1},
+		{"linear boundary", linear, 1000, 2},
+		{"linear multi-level", linear, 3500, 4},
+		{"empty curve type is linear", config.ProgressionConfig{}, 2999, 3},
+		{"exponential floor", exponential, 0, 1},
+		{"exponential just below level 2", exponential, 99, 1},
+		{"exponential level 4 boundary", exponential, 700, 4},
+		{"exponential just below level 5", exponential, 1499, 4},
+		{"table below first threshold", table, 49, 1},
+		{"table middle", table, 200, 3},
+		{"table caps at last level", table, 1000000, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progression.LevelForXP(tt.cfg, tt.xp); got != tt.level {
+				t.Errorf("LevelForXP(%d) = %d, want %d", tt.xp, got, tt.level)
+			}
+		})
+	}
+}
+
+func TestXPForNextLevel(t *testing.T) {
+	exponential := config.ProgressionConfig{BaseXPPerLevel: 100, CurveType: progression.CurveExponential, CurveGrowthFactor: 2}
+	if got := progression.XPForNextLevel(exponential, 3); got != 400 {
+		t.Errorf("Expected level 3 -> 4 to cost 400 XP, got %d", got)
+	}
+	if got := progression.XPThreshold(exponential, 4); got != 700 {
+		t.Errorf("Expected level 4 threshold 700, got %d", got)
+	}
+
+	table := config.ProgressionConfig{CurveType: progression.CurveTable, XPTable: []int64{50, 200, 500}}
+	if got := progression.XPForNextLevel(table, 2); got != 150 {
+		t.Errorf("Expected level 2 -> 3 to cost 150 XP, got %d", got)
+	}
+	if got := progression.XPForNextLevel(table, 4); got != 0 {
+		t.Errorf("Expected 0 XP at the table's max level, got %d", got)
+	}
+}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/progression"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -32,6 +38,8 @@ type ProgressionResponse struct {
 	TotalDeaths        int64  `json:"total_deaths"`
 	TotalScrapEarned   int64  `json:"total_scrap_earned"`
 	TotalDataEarned    int64  `json:"total_data_earned"`
+	XPIntoCurrentLevel int64  `json:"xp_into_current_level"`
+	XPForNextLevel     int64  `json:"xp_for_next_level"`
 	UpdatedAt          string `json:"updated_at"`
 }
 
@@ -45,21 +53,18 @@ type PrestigeResponse struct {
 func (h *ProgressionHandlers) GetProgression(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	progression, err := h.progressionSvc.GetPlayerProgression(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player progression", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player progression", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	// Convert timestamp to ISO 8601 string
 	updatedAt := progression.UpdatedAt.Time.Format("2006-01-02T15:04:05Z")
+	xpIntoLevel, xpForNextLevel := h.progressionSvc.XPProgress(progression.Experience)
 	resp := ProgressionResponse{
 		PlayerID:           progression.PlayerID,
 		Level:              progression.Level,
@@ -72,6 +77,8 @@ func (h *ProgressionHandlers) GetProgression(c *fiber.Ctx) error {
 		TotalDeaths:        progression.TotalDeaths,
 		TotalScrapEarned:   progression.TotalScrapEarned,
 		TotalDataEarned:    progression.TotalDataEarned,
+		XPIntoCurrentLevel: xpIntoLevel,
+		XPForNextLevel:     xpForNextLevel,
 		UpdatedAt:          updatedAt,
 	}
 	return c.Status(fiber.StatusOK).JSON(resp)
@@ -81,23 +88,19 @@ func (h *ProgressionHandlers) GetProgression(c *fiber.Ctx) error {
 func (h *ProgressionHandlers) PrestigePlayer(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	err := h.progressionSvc.PrestigePlayer(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to prestige player", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to prestige player", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	// Get updated progression to include in response
 	progression, err := h.progressionSvc.GetPlayerProgression(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player progression after prestige", zap.Error(err), zap.Int64("player_id", playerID))
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player progression after prestige", zap.Error(err), zap.Int64("player_id", playerID))
 		// Still return success because prestige succeeded
 		return c.Status(fiber.StatusOK).JSON(PrestigeResponse{
 			Message:          "prestige successful",
@@ -117,40 +120,61 @@ func (h *ProgressionHandlers) PrestigePlayer(c *fiber.Ctx) error {
 func (h *ProgressionHandlers) GetCurrencyBalance(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	progression, err := h.progressionSvc.GetPlayerProgression(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player progression", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player progression", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"data_currency": progression.DataCurrency,
 	})
 }
 
+// GetCurrencyHistory handles GET /progression/currency/history
+func (h *ProgressionHandlers) GetCurrencyHistory(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	// Parse limit query parameter (default 20, max 100)
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	transactions, err := h.progressionSvc.GetCurrencyTransactions(c.Context(), playerID, int64(limit), int64(offset))
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to get currency history", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(transactions)
+}
+
 // GetCosmeticCatalog handles GET /cosmetics/catalog
 func (h *ProgressionHandlers) GetCosmeticCatalog(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	items, err := h.progressionSvc.GetCosmeticCatalog(ctx)
 	if err != nil {
-		h.logger.Error("failed to get cosmetic catalog", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get cosmetic catalog", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	return c.Status(fiber.StatusOK).JSON(items)
 }
@@ -159,66 +183,85 @@ func (h *ProgressionHandlers) GetCosmeticCatalog(c *fiber.Ctx) error {
 func (h *ProgressionHandlers) GetPlayerCosmetics(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	ctx := c.Context()
 	items, err := h.progressionSvc.GetPlayerCosmetics(ctx, playerID)
 	if err != nil {
-		h.logger.Error("failed to get player cosmetics", zap.Error(err), zap.Int64("player_id", playerID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to get player cosmetics", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	return c.Status(fiber.StatusOK).JSON(items)
 }
 
+// GetFriendsOwningCosmetic handles GET /cosmetics/:id/friends-owning
+func (h *ProgressionHandlers) GetFriendsOwningCosmetic(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+
+	friends, err := h.progressionSvc.GetFriendsOwningCosmetic(c.Context(), playerID, int64(cosmeticID))
+	if err != nil {
+		if err == progression.ErrCosmeticNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to get friends owning cosmetic", zap.Error(err), zap.Int64("player_id", playerID), zap.Int("cosmetic_id", cosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"cosmetic_id": cosmeticID,
+		"count":       len(friends),
+		"friends":     friends,
+	})
+}
+
 // EquipCosmetic handles PUT /cosmetics/equip
 func (h *ProgressionHandlers) EquipCosmetic(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 	var req struct {
-		CosmeticID int64 `json:"cosmetic_id"`
+		CosmeticID int64  `json:"cosmetic_id"`
+		LoadoutID  *int64 `json:"loadout_id"`
 	}
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	if req.CosmeticID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "cosmetic_id must be positive",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "cosmetic_id must be positive")
+	}
+	if req.LoadoutID != nil && *req.LoadoutID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "loadout_id must be positive")
 	}
 	ctx := c.Context()
-	err := h.progressionSvc.EquipCosmetic(ctx, playerID, req.CosmeticID)
+	err := h.progressionSvc.EquipCosmetic(ctx, playerID, req.CosmeticID, req.LoadoutID)
 	if err != nil {
 		if err == progression.ErrCosmeticNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "cosmetic not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found")
 		}
 		if err == progression.ErrCosmeticNotOwned {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "cosmetic not owned",
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeCosmeticNotOwned, "cosmetic not owned")
+		}
+		if err == progression.ErrCosmeticExpired {
+			return apierror.Respond(c, fiber.StatusGone, apierror.CodeCosmeticExpired, "cosmetic is no longer equippable")
+		}
+		if err == progression.ErrEquipRestricted {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeEquipRestricted, "cosmetic can only be equipped when unlocked another way")
 		}
 		if err == progression.ErrLoadoutNotFound {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "loadout not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLoadoutNotFound, "loadout not found")
 		}
-		h.logger.Error("failed to equip cosmetic", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", req.CosmeticID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("failed to equip cosmetic", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", req.CosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "cosmetic equipped successfully",
@@ -229,51 +272,731 @@ func (h *ProgressionHandlers) EquipCosmetic(c *fiber.Ctx) error {
 func (h *ProgressionHandlers) PurchaseCosmetic(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID missing from context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
 	}
 
 	var req struct {
 		CosmeticID int64 `json:"cosmetic_id"`
 	}
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
 	}
 	if req.CosmeticID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "cosmetic_id must be positive",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "cosmetic_id must be positive")
 	}
 
 	ctx := c.Context()
 	err := h.progressionSvc.PurchaseCosmetic(ctx, playerID, req.CosmeticID)
 	if err != nil {
 		if err == progression.ErrCosmeticNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "cosmetic not found",
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found")
 		}
 		if err == progression.ErrInsufficientCurrency {
-			return c.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{
-				"error": "insufficient data currency",
-			})
+			return apierror.Respond(c, fiber.StatusPaymentRequired, apierror.CodeInsufficientCurrency, "insufficient data currency")
 		}
 		if err == progression.ErrCosmeticAlreadyOwned {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "cosmetic already owned",
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeCosmeticAlreadyOwned, "cosmetic already owned")
 		}
-		h.logger.Error("failed to purchase cosmetic", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", req.CosmeticID))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		if err == progression.ErrPurchaseLimitReached {
+			return apierror.Respond(c, fiber.StatusTooManyRequests, apierror.CodePurchaseLimitReached, "daily purchase limit reached")
+		}
+		if err == progression.ErrCosmeticNotForSale {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeCosmeticNotForSale, "cosmetic cannot be purchased")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to purchase cosmetic", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", req.CosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "cosmetic purchased successfully",
 	})
 }
+
+// UndoPurchase handles POST /cosmetics/purchase/undo
+func (h *ProgressionHandlers) UndoPurchase(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	var req struct {
+		CosmeticID int64 `json:"cosmetic_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	if req.CosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "cosmetic_id must be positive")
+	}
+
+	err := h.progressionSvc.UndoPurchase(c.Context(), playerID, req.CosmeticID)
+	if err != nil {
+		if err == progression.ErrPurchaseNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePurchaseNotFound, "purchase not found")
+		}
+		if err == progression.ErrUndoWindowExpired {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodePurchaseUndoWindowExpired, "purchase undo window has expired")
+		}
+		if err == progression.ErrPurchaseAlreadyUndone {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodePurchaseAlreadyUndone, "purchase already undone")
+		}
+		if err == progression.ErrCosmeticNotOwned {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeCosmeticNotOwned, "cosmetic not owned")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to undo purchase", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("cosmetic_id", req.CosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "purchase undone and refunded",
+	})
+}
+
+// CosmeticBundleResponse is one store bundle as returned by GET /cosmetics/bundles.
+type CosmeticBundleResponse struct {
+	BundleID    int64              `json:"bundle_id"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	Price       int64              `json:"price"`
+	Cosmetics   []*db.CosmeticItem `json:"cosmetics"`
+}
+
+// ListCosmeticBundles handles GET /cosmetics/bundles
+func (h *ProgressionHandlers) ListCosmeticBundles(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	bundles, err := h.progressionSvc.ListCosmeticBundles(c.Context())
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to list cosmetic bundles", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	resp := make([]CosmeticBundleResponse, 0, len(bundles))
+	for _, b := range bundles {
+		resp = append(resp, CosmeticBundleResponse{
+			BundleID:    b.Bundle.BundleID,
+			Name:        b.Bundle.Name,
+			Description: b.Bundle.Description,
+			Price:       b.Bundle.Price,
+			Cosmetics:   b.Cosmetics,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// PurchaseBundle handles POST /cosmetics/bundles/:id/purchase
+func (h *ProgressionHandlers) PurchaseBundle(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	bundleID, err := c.ParamsInt("id")
+	if err != nil || bundleID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid bundle id")
+	}
+
+	purchase, err := h.progressionSvc.PurchaseBundle(c.Context(), playerID, int64(bundleID))
+	if err != nil {
+		switch err {
+		case progression.ErrBundleNotFound:
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeBundleNotFound, "bundle not found")
+		case progression.ErrBundleAlreadyOwned:
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeBundleAlreadyOwned, "all bundle cosmetics already owned")
+		case progression.ErrInsufficientCurrency:
+			return apierror.Respond(c, fiber.StatusPaymentRequired, apierror.CodeInsufficientCurrency, "insufficient data currency")
+		case progression.ErrCosmeticNotForSale:
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeCosmeticNotForSale, "bundle contains a cosmetic that cannot be purchased")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to purchase bundle", zap.Error(err), zap.Int64("player_id", playerID), zap.Int("bundle_id", bundleID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":              "bundle purchased successfully",
+		"bundle_id":            purchase.BundleID,
+		"charged":              purchase.Charged,
+		"granted_cosmetic_ids": purchase.GrantedCosmeticIDs,
+	})
+}
+
+// StoreItemResponse is a catalog cosmetic with the player's ownership flag.
+type StoreItemResponse struct {
+	*db.CosmeticItem
+	Owned bool `json:"owned"`
+}
+
+// StoreBundleResponse is a bundle as shown in GET /store. PlayerPrice is what
+// the player would be charged now and is 0 when they already own everything.
+type StoreBundleResponse struct {
+	BundleID    int64                `json:"bundle_id"`
+	Name        string               `json:"name"`
+	Description *string              `json:"description"`
+	Price       int64                `json:"price"`
+	PlayerPrice int64                `json:"player_price"`
+	FullyOwned  bool                 `json:"fully_owned"`
+	Cosmetics   []*StoreItemResponse `json:"cosmetics"`
+}
+
+// StoreResponse is the combined store view returned by GET /store.
+type StoreResponse struct {
+	DataCurrency int64                  `json:"data_currency"`
+	Featured     []*StoreItemResponse   `json:"featured"`
+	Bundles      []*StoreBundleResponse `json:"bundles"`
+	Catalog      []*StoreItemResponse   `json:"catalog"`
+}
+
+func toStoreItems(items []*progression.StoreItem) []*StoreItemResponse {
+	resp := make([]*StoreItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, &StoreItemResponse{CosmeticItem: item.Cosmetic, Owned: item.Owned})
+	}
+	return resp
+}
+
+// GetStore handles GET /store
+func (h *ProgressionHandlers) GetStore(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	store, err := h.progressionSvc.GetStore(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to get store", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := StoreResponse{
+		DataCurrency: store.DataCurrency,
+		Featured:     toStoreItems(store.Featured),
+		Bundles:      make([]*StoreBundleResponse, 0, len(store.Bundles)),
+		Catalog:      toStoreItems(store.Catalog),
+	}
+	for _, b := range store.Bundles {
+		resp.Bundles = append(resp.Bundles, &StoreBundleResponse{
+			BundleID:    b.Bundle.BundleID,
+			Name:        b.Bundle.Name,
+			Description: b.Bundle.Description,
+			Price:       b.Bundle.Price,
+			PlayerPrice: b.Price,
+			FullyOwned:  b.FullyOwned,
+			Cosmetics:   toStoreItems(b.Items),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// BackfillPrestigeCosmetic handles POST /admin/cosmetics/:id/backfill-prestige
+func (h *ProgressionHandlers) BackfillPrestigeCosmetic(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+
+	ctx := c.Context()
+	granted, err := h.progressionSvc.BackfillPrestigeCosmetic(ctx, int64(cosmeticID))
+	if err != nil {
+		if err == progression.ErrCosmeticNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found")
+		}
+		if err == progression.ErrCosmeticNotPrestige {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeCosmeticNotPrestigeOnly, "cosmetic is not prestige-only")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to backfill prestige cosmetic", zap.Error(err), zap.Int64("cosmetic_id", int64(cosmeticID)), zap.Int64("granted", granted))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	logging.FromContext(c.Context(), h.logger).Info("audit: prestige cosmetic backfill",
+		zap.Int64("admin_id", adminID),
+		zap.Int64("cosmetic_id", int64(cosmeticID)),
+		zap.Int64("granted", granted))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"cosmetic_id": cosmeticID,
+		"granted":     granted,
+	})
+}
+
+// AdjustCurrencyRequest grants (positive Amount) or deducts (negative Amount) data currency.
+type AdjustCurrencyRequest struct {
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// AdjustCurrency handles POST /admin/players/:id/currency
+func (h *ProgressionHandlers) AdjustCurrency(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	playerID, err := c.ParamsInt("id")
+	if err != nil || playerID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+
+	var req AdjustCurrencyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Amount == 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "amount must be non-zero")
+	}
+	if req.Reason == "" {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "reason is required")
+	}
+
+	balance, err := h.progressionSvc.AdjustDataCurrency(c.Context(), int64(playerID), req.Amount)
+	if err != nil {
+		if err == progression.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, "player not found")
+		}
+		if err == progression.ErrInsufficientCurrency {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInsufficientCurrency, "deduction exceeds the player's balance")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to adjust data currency", zap.Error(err), zap.Int("player_id", playerID), zap.Int64("amount", req.Amount))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	logging.FromContext(c.Context(), h.logger).Info("audit: data currency adjusted",
+		zap.Int64("admin_id", adminID),
+		zap.Int("player_id", playerID),
+		zap.Int64("amount", req.Amount),
+		zap.Int64("balance", balance),
+		zap.String("reason", req.Reason))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"player_id":     playerID,
+		"amount":        req.Amount,
+		"data_currency": balance,
+	})
+}
+
+type EconomySnapshotResponse struct {
+	TotalCirculation int64                            `json:"total_circulation"`
+	AverageBalance   float64                          `json:"average_balance"`
+	PlayerCount      int64                            `json:"player_count"`
+	TotalEarned      int64                            `json:"total_earned"`
+	TotalSpent       int64                            `json:"total_spent"`
+	ByType           []*db.GetCurrencyTotalsByTypeRow `json:"by_type"`
+	TopSellers       []*db.GetTopCosmeticSellersRow   `json:"top_sellers"`
+}
+
+// GetEconomySnapshot handles GET /admin/economy/snapshot
+func (h *ProgressionHandlers) GetEconomySnapshot(c *fiber.Ctx) error {
+	// Parse top query parameter (default 10, max 50)
+	top := c.QueryInt("top", 10)
+	if top <= 0 {
+		top = 10
+	}
+	if top > 50 {
+		top = 50
+	}
+
+	snapshot, err := h.progressionSvc.GetEconomySnapshot(c.Context(), int64(top))
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to get economy snapshot", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := EconomySnapshotResponse{
+		TotalCirculation: snapshot.Circulation.TotalCirculation,
+		AverageBalance:   snapshot.Circulation.AverageBalance,
+		PlayerCount:      snapshot.Circulation.PlayerCount,
+		ByType:           snapshot.TotalsByType,
+		TopSellers:       snapshot.TopSellers,
+	}
+	for _, t := range snapshot.TotalsByType {
+		resp.TotalEarned += t.Earned
+		resp.TotalSpent += t.Spent
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+type PublicLoadoutSlot struct {
+	Slot       string `json:"slot"`
+	CosmeticID int64  `json:"cosmetic_id"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type PublicLoadoutResponse struct {
+	PlayerID int64                `json:"player_id"`
+	Slots    []*PublicLoadoutSlot `json:"slots"`
+}
+
+// GetPublicLoadout handles GET /players/:id/loadout
+func (h *ProgressionHandlers) GetPublicLoadout(c *fiber.Ctx) error {
+	playerID, err := c.ParamsInt("id")
+	if err != nil || playerID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+
+	slots, err := h.progressionSvc.GetPublicLoadout(c.Context(), int64(playerID))
+	if err != nil {
+		if err == progression.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, "player not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to get public loadout", zap.Error(err), zap.Int("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := PublicLoadoutResponse{
+		PlayerID: int64(playerID),
+		Slots:    make([]*PublicLoadoutSlot, 0, len(slots)),
+	}
+	for _, s := range slots {
+		resp.Slots = append(resp.Slots, &PublicLoadoutSlot{
+			Slot:       s.Slot,
+			CosmeticID: s.CosmeticID,
+			IsDefault:  s.IsDefault,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+type ServerLoadoutCosmetic struct {
+	CosmeticID int64   `json:"cosmetic_id"`
+	Name       string  `json:"name"`
+	Category   *string `json:"category"`
+	Rarity     string  `json:"rarity"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+type ServerLoadoutResponse struct {
+	PlayerID int64                             `json:"player_id"`
+	Slots    map[string]*ServerLoadoutCosmetic `json:"slots"`
+}
+
+// GetServerPlayerLoadout handles GET /servers/players/:id/loadout
+func (h *ProgressionHandlers) GetServerPlayerLoadout(c *fiber.Ctx) error {
+	playerID, err := c.ParamsInt("id")
+	if err != nil || playerID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid player id")
+	}
+
+	equipped, err := h.progressionSvc.GetEquippedCosmetics(c.Context(), int64(playerID))
+	if err != nil {
+		if err == progression.ErrPlayerNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, "player not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to get equipped cosmetics", zap.Error(err), zap.Int("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := ServerLoadoutResponse{
+		PlayerID: int64(playerID),
+		Slots:    make(map[string]*ServerLoadoutCosmetic, len(equipped)),
+	}
+	for _, e := range equipped {
+		resp.Slots[e.Slot] = &ServerLoadoutCosmetic{
+			CosmeticID: e.Cosmetic.CosmeticID,
+			Name:       e.Cosmetic.Name,
+			Category:   e.Cosmetic.Category,
+			Rarity:     e.Cosmetic.Rarity,
+			IsDefault:  e.IsDefault,
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// ResetLoadout handles POST /loadouts/reset
+func (h *ProgressionHandlers) ResetLoadout(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	slots, err := h.progressionSvc.ResetLoadout(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to reset loadout", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := PublicLoadoutResponse{
+		PlayerID: playerID,
+		Slots:    make([]*PublicLoadoutSlot, 0, len(slots)),
+	}
+	for _, s := range slots {
+		resp.Slots = append(resp.Slots, &PublicLoadoutSlot{
+			Slot:       s.Slot,
+			CosmeticID: s.CosmeticID,
+			IsDefault:  s.IsDefault,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+type LoadoutResponse struct {
+	LoadoutID int64                `json:"loadout_id"`
+	Name      string               `json:"name"`
+	IsActive  bool                 `json:"is_active"`
+	CreatedAt string               `json:"created_at"`
+	Slots     []*PublicLoadoutSlot `json:"slots"`
+}
+
+func toLoadoutResponse(loadout *db.Loadout, slots []*progression.LoadoutSlot) *LoadoutResponse {
+	resp := &LoadoutResponse{
+		LoadoutID: loadout.LoadoutID,
+		Name:      loadout.Name,
+		IsActive:  loadout.IsActive == 1,
+		CreatedAt: loadout.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		Slots:     make([]*PublicLoadoutSlot, 0, len(slots)),
+	}
+	for _, s := range slots {
+		resp.Slots = append(resp.Slots, &PublicLoadoutSlot{
+			Slot:       s.Slot,
+			CosmeticID: s.CosmeticID,
+		})
+	}
+	return resp
+}
+
+// ListLoadouts handles GET /loadouts
+func (h *ProgressionHandlers) ListLoadouts(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	loadouts, err := h.progressionSvc.ListLoadouts(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to list loadouts", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	resp := make([]*LoadoutResponse, 0, len(loadouts))
+	for _, l := range loadouts {
+		resp = append(resp, toLoadoutResponse(l.Loadout, l.Slots))
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CreateLoadout handles POST /loadouts
+func (h *ProgressionHandlers) CreateLoadout(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+
+	loadout, err := h.progressionSvc.CreateLoadout(c.Context(), playerID, req.Name)
+	if err != nil {
+		if err == progression.ErrInvalidLoadoutName {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, fmt.Sprintf("name must be 1 to %d characters", progression.MaxLoadoutNameLength))
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to create loadout", zap.Error(err), zap.Int64("player_id", playerID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusCreated).JSON(toLoadoutResponse(loadout, nil))
+}
+
+// ActivateLoadout handles POST /loadouts/:id/activate
+func (h *ProgressionHandlers) ActivateLoadout(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	loadoutID, err := c.ParamsInt("id")
+	if err != nil || loadoutID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loadout id")
+	}
+
+	if err := h.progressionSvc.SetActiveLoadout(c.Context(), playerID, int64(loadoutID)); err != nil {
+		if err == progression.ErrLoadoutNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLoadoutNotFound, "loadout not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to activate loadout", zap.Error(err), zap.Int64("player_id", playerID), zap.Int("loadout_id", loadoutID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "loadout activated successfully",
+	})
+}
+
+// DeleteLoadout handles DELETE /loadouts/:id
+func (h *ProgressionHandlers) DeleteLoadout(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID missing from context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+	}
+	loadoutID, err := c.ParamsInt("id")
+	if err != nil || loadoutID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid loadout id")
+	}
+
+	if err := h.progressionSvc.DeleteLoadout(c.Context(), playerID, int64(loadoutID)); err != nil {
+		if err == progression.ErrLoadoutNotFound {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeLoadoutNotFound, "loadout not found")
+		}
+		if err == progression.ErrLoadoutActive {
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeLoadoutActive, "cannot delete the active loadout")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to delete loadout", zap.Error(err), zap.Int64("player_id", playerID), zap.Int("loadout_id", loadoutID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CosmeticItemRequest is the body of admin cosmetic creates and updates; updates
+// replace every field.
+type CosmeticItemRequest struct {
+	Name             string     `json:"name"`
+	Description      *string    `json:"description,omitempty"`
+	Slot             string     `json:"slot"`
+	Category         *string    `json:"category,omitempty"`
+	Rarity           string     `json:"rarity"`
+	UnlockLevel      *int64     `json:"unlock_level,omitempty"`
+	DataCost         int64      `json:"data_cost"`
+	IsPrestigeOnly   bool       `json:"is_prestige_only"`
+	MaxPerDay        *int64     `json:"max_per_day,omitempty"`
+	AvailableUntil   *time.Time `json:"available_until,omitempty"`
+	EquipRestriction *string    `json:"equip_restriction,omitempty"`
+}
+
+func (r *CosmeticItemRequest) toInput() progression.CosmeticItemInput {
+	unlockLevel := int64(1)
+	if r.UnlockLevel != nil {
+		unlockLevel = *r.UnlockLevel
+	}
+	return progression.CosmeticItemInput{
+		Name:             r.Name,
+		Description:      r.Description,
+		Slot:             r.Slot,
+		Category:         r.Category,
+		Rarity:           r.Rarity,
+		UnlockLevel:      unlockLevel,
+		DataCost:         r.DataCost,
+		IsPrestigeOnly:   r.IsPrestigeOnly,
+		MaxPerDay:        r.MaxPerDay,
+		AvailableUntil:   r.AvailableUntil,
+		EquipRestriction: r.EquipRestriction,
+	}
+}
+
+// cosmeticItemError maps the progression service's cosmetic validation errors to a status, code and message.
+func cosmeticItemError(err error) (int, apierror.Code, string, bool) {
+	switch err {
+	case progression.ErrCosmeticNotFound:
+		return fiber.StatusNotFound, apierror.CodeCosmeticNotFound, "cosmetic not found", true
+	case progression.ErrInvalidCosmeticSlot:
+		return fiber.StatusBadRequest, apierror.CodeBadRequest, "slot must be one of character_skin, weapon_skin, emote, taunt, badge, title, particle_effect, other", true
+	case progression.ErrInvalidRarity:
+		return fiber.StatusBadRequest, apierror.CodeBadRequest, "rarity must be one of common, uncommon, rare, epic, legendary", true
+	case progression.ErrInvalidCosmeticItem:
+		return fiber.StatusBadRequest, apierror.CodeBadRequest, "name is required, unlock_level and max_per_day must be at least 1 and data_cost non-negative", true
+	case progression.ErrCosmeticInUse:
+		return fiber.StatusConflict, apierror.CodeCosmeticInUse, err.Error(), true
+	}
+	return 0, "", "", false
+}
+
+// ListCosmeticItems handles GET /admin/cosmetics
+func (h *ProgressionHandlers) ListCosmeticItems(c *fiber.Ctx) error {
+	items, err := h.progressionSvc.GetCosmeticCatalog(c.Context())
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("failed to list cosmetic items", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"cosmetics": items,
+	})
+}
+
+// CreateCosmeticItem handles POST /admin/cosmetics
+func (h *ProgressionHandlers) CreateCosmeticItem(c *fiber.Ctx) error {
+	var req CosmeticItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+
+	item, err := h.progressionSvc.CreateCosmeticItem(c.Context(), req.toInput())
+	if err != nil {
+		if status, code, msg, ok := cosmeticItemError(err); ok {
+			return apierror.Respond(c, status, code, msg)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to create cosmetic item", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+// GetCosmeticItem handles GET /admin/cosmetics/:id
+func (h *ProgressionHandlers) GetCosmeticItem(c *fiber.Ctx) error {
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+
+	item, err := h.progressionSvc.GetCosmeticItem(c.Context(), int64(cosmeticID))
+	if err != nil {
+		if status, code, msg, ok := cosmeticItemError(err); ok {
+			return apierror.Respond(c, status, code, msg)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to get cosmetic item", zap.Error(err), zap.Int("cosmetic_id", cosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+// UpdateCosmeticItem handles PUT /admin/cosmetics/:id
+func (h *ProgressionHandlers) UpdateCosmeticItem(c *fiber.Ctx) error {
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+	var req CosmeticItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "invalid request body")
+	}
+
+	item, err := h.progressionSvc.UpdateCosmeticItem(c.Context(), int64(cosmeticID), req.toInput())
+	if err != nil {
+		if status, code, msg, ok := cosmeticItemError(err); ok {
+			return apierror.Respond(c, status, code, msg)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to update cosmetic item", zap.Error(err), zap.Int("cosmetic_id", cosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+// DeleteCosmeticItem handles DELETE /admin/cosmetics/:id
+func (h *ProgressionHandlers) DeleteCosmeticItem(c *fiber.Ctx) error {
+	cosmeticID, err := c.ParamsInt("id")
+	if err != nil || cosmeticID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid cosmetic id")
+	}
+
+	if err := h.progressionSvc.DeleteCosmeticItem(c.Context(), int64(cosmeticID)); err != nil {
+		if status, code, msg, ok := cosmeticItemError(err); ok {
+			return apierror.Respond(c, status, code, msg)
+		}
+		logging.FromContext(c.Context(), h.logger).Error("failed to delete cosmetic item", zap.Error(err), zap.Int("cosmetic_id", cosmeticID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
 	"ai-zombie-defense/backend-api/internal/testutils"
@@ -47,6 +50,61 @@ func TestAccountHandlers_GetProgression(t *testing.T) {
 	}
 }
 
+func TestAccountHandlers_GetProgressionXPBar(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	getProgression := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/progression", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return result
+	}
+	// Creates the default progression row
+	getProgression()
+
+	// Default config is 1000 XP per level
+	tests := []struct {
+		experience   int64
+		level        float64
+		intoLevel    float64
+		forNextLevel float64
+	}{
+		{3500, 4, 500, 1000},
+		{3000, 4, 0, 1000},
+		{0, 1, 0, 1000},
+	}
+	for _, tt := range tests {
+		if _, err := db.Exec(`UPDATE player_progression SET experience = ?, level = ? WHERE player_id = ?`, tt.experience, int64(tt.level), playerID); err != nil {
+			t.Fatalf("Failed to set experience: %v", err)
+		}
+		result := getProgression()
+		if result["level"] != tt.level {
+			t.Errorf("XP %d: expected level %v, got %v", tt.experience, tt.level, result["level"])
+		}
+		if result["xp_into_current_level"] != tt.intoLevel {
+			t.Errorf("XP %d: expected xp_into_current_level %v, got %v", tt.experience, tt.intoLevel, result["xp_into_current_level"])
+		}
+		if result["xp_for_next_level"] != tt.forNextLevel {
+			t.Errorf("XP %d: expected xp_for_next_level %v, got %v", tt.experience, tt.forNextLevel, result["xp_for_next_level"])
+		}
+	}
+}
+
 func TestAccountHandlers_GetCosmeticCatalog(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -112,3 +170,1554 @@ func TestAccountHandlers_PurchaseCosmetic(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestAccountHandlers_GetCurrencyHistory(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	otherID := testutils.CreateTestPlayer(t, db, "otheruser", "other@example.com", "password")
+	serverID := testutils.CreateTestServerRow(t, db)
+	serverToken := testutils.CreateTestServerAuthToken(t, db, serverID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Test Skin', 'character_skin', 'common', 1, 150)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	if _, err := db.Exec(`UPDATE player_progression SET data_currency = 200 WHERE player_id = ?`, playerID); err != nil {
+		t.Fatalf("Failed to set data currency: %v", err)
+	}
+	// Another player's ledger must never leak into the response
+	if _, err := db.Exec(`INSERT INTO currency_transactions (player_id, amount, balance_after, transaction_type) VALUES (?, 500, 500, 'admin_grant')`, otherID); err != nil {
+		t.Fatalf("Failed to insert other player's transaction: %v", err)
+	}
+
+	doRequest := func(method, path string, payload interface{}) *http.Response {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		// Match submission authenticates as the game server instead
+		req.Header.Set("X-Server-Token", serverToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if resp := doRequest(http.MethodPost, "/cosmetics/purchase", map[string]interface{}{"cosmetic_id": cosmeticID}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for purchase, got %d", resp.StatusCode)
+	}
+	match := map[string]interface{}{
+		"server_id":      serverID,
+		"map_name":       "Test Map",
+		"game_mode":      "survival",
+		"start_time":     "2026-01-22T15:30:00Z",
+		"outcome":        "completed",
+		"waves_survived": 3,
+		"total_players":  1,
+		"player_stats": []map[string]interface{}{
+			{"player_id": playerID, "waves_survived": 3, "data_earned": 75},
+		},
+	}
+	if resp := doRequest(http.MethodPost, "/matches", match); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 for match, got %d", resp.StatusCode)
+	}
+
+	resp := doRequest(http.MethodGet, "/progression/currency/history", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var history []struct {
+		Amount          int64  `json:"amount"`
+		BalanceAfter    int64  `json:"balance_after"`
+		TransactionType string `json:"transaction_type"`
+		ReferenceID     *int64 `json:"reference_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 ledger entries, got %d", len(history))
+	}
+	// Newest first
+	if history[0].TransactionType != "match_reward" || history[0].Amount != 75 || history[0].BalanceAfter != 125 {
+		t.Errorf("Unexpected first entry: %+v", history[0])
+	}
+	if history[1].TransactionType != "purchase" || history[1].Amount != -150 || history[1].BalanceAfter != 50 {
+		t.Errorf("Unexpected second entry: %+v", history[1])
+	}
+	if history[1].ReferenceID == nil || *history[1].ReferenceID != cosmeticID {
+		t.Errorf("Expected purchase to reference cosmetic %d, got %v", cosmeticID, history[1].ReferenceID)
+	}
+
+	// Pagination
+	resp = doRequest(http.MethodGet, "/progression/currency/history?limit=1&offset=1", nil)
+	history = nil
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(history) != 1 || history[0].TransactionType != "purchase" {
+		t.Errorf("Expected only the purchase entry on the second page, got %+v", history)
+	}
+}
+
+func TestAccountHandlers_EquipExpiredCosmetic(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	windows := map[string]time.Time{
+		"Expired Event Skin": time.Now().UTC().Add(-time.Hour),
+		"Active Event Skin":  time.Now().UTC().Add(time.Hour),
+	}
+	cosmeticIDs := make(map[string]int64)
+	for name, until := range windows {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, available_until) VALUES (?, 'character_skin', 'epic', 1, ?)`,
+			name, until.Format("2006-01-02T15:04:05Z"))
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic item: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, id); err != nil {
+			t.Fatalf("Failed to grant cosmetic: %v", err)
+		}
+		cosmeticIDs[name] = id
+	}
+
+	equip := func(cosmeticID int64) int {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(http.MethodPut, "/cosmetics/equip", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := equip(cosmeticIDs["Expired Event Skin"]); status != http.StatusGone {
+		t.Errorf("Expected status 410 for expired cosmetic, got %d", status)
+	}
+	if status := equip(cosmeticIDs["Active Event Skin"]); status != http.StatusOK {
+		t.Errorf("Expected status 200 for cosmetic still in its window, got %d", status)
+	}
+
+	// The expired item stays in the inventory
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, playerID, cosmeticIDs["Expired Event Skin"]).Scan(&count); err != nil {
+		t.Fatalf("Failed to count cosmetics: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected expired cosmetic to remain owned, got count %d", count)
+	}
+}
+
+func TestAccountHandlers_EquipRestrictedCosmetic(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	if _, err := db.Exec(`UPDATE player_progression SET data_currency = 1000 WHERE player_id = ?`, playerID); err != nil {
+		t.Fatalf("Failed to fund player: %v", err)
+	}
+
+	// Two prestige-restricted items, one unlocked through prestige and one
+	// through a loot drop
+	grants := map[string]string{
+		"Prestige Skin": "prestige",
+		"Prestige Gun":  "loot_drop",
+	}
+	slots := map[string]string{
+		"Prestige Skin": "character_skin",
+		"Prestige Gun":  "weapon_skin",
+	}
+	cosmeticIDs := make(map[string]int64)
+	for name, via := range grants {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost, equip_restriction) VALUES (?, ?, 'legendary', 1, 100, 'prestige')`, name, slots[name])
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic item: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, ?)`, playerID, id, via); err != nil {
+			t.Fatalf("Failed to grant cosmetic: %v", err)
+		}
+		cosmeticIDs[name] = id
+	}
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost, equip_restriction) VALUES ('Prestige Badge', 'badge', 'epic', 1, 100, 'prestige')`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	unownedID, _ := res.LastInsertId()
+
+	send := func(method, path string, cosmeticID int64) int {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := send(http.MethodPut, "/cosmetics/equip", cosmeticIDs["Prestige Gun"]); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for cosmetic unlocked the wrong way, got %d", status)
+	}
+	if status := send(http.MethodPut, "/cosmetics/equip", cosmeticIDs["Prestige Skin"]); status != http.StatusOK {
+		t.Errorf("Expected status 200 for cosmetic unlocked through prestige, got %d", status)
+	}
+
+	// Restricted items can't be bought
+	if status := send(http.MethodPost, "/cosmetics/purchase", unownedID); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 purchasing a prestige-restricted cosmetic, got %d", status)
+	}
+	var balance int64
+	if err := db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&balance); err != nil {
+		t.Fatalf("Failed to read balance: %v", err)
+	}
+	if balance != 1000 {
+		t.Errorf("Expected balance to stay 1000, got %d", balance)
+	}
+}
+
+func TestAccountHandlers_EquipConcurrentCreatesOneLoadout(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	// Two cosmetics in different slots, so both equips should land
+	var cosmeticIDs []int64
+	for _, slot := range []string{"character_skin", "weapon_skin"} {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level) VALUES (?, ?, 'common', 1)`, "Item "+slot, slot)
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic item: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, id); err != nil {
+			t.Fatalf("Failed to grant cosmetic: %v", err)
+		}
+		cosmeticIDs = append(cosmeticIDs, id)
+	}
+
+	statuses := make([]int, len(cosmeticIDs))
+	errs := make([]error, len(cosmeticIDs))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, cosmeticID := range cosmeticIDs {
+		wg.Add(1)
+		go func(i int, cosmeticID int64) {
+			defer wg.Done()
+			body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+			req := httptest.NewRequest(http.MethodPut, "/cosmetics/equip", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Header.Set("Content-Type", "application/json")
+			<-start
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			statuses[i] = resp.StatusCode
+		}(i, cosmeticID)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := range cosmeticIDs {
+		if errs[i] != nil {
+			t.Fatalf("Failed to make request: %v", errs[i])
+		}
+		if statuses[i] != http.StatusOK {
+			t.Errorf("Expected status 200 for equip %d, got %d", i, statuses[i])
+		}
+	}
+
+	var active int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM loadouts WHERE player_id = ? AND is_active = 1`, playerID).Scan(&active); err != nil {
+		t.Fatalf("Failed to count loadouts: %v", err)
+	}
+	if active != 1 {
+		t.Fatalf("Expected a single active loadout, got %d", active)
+	}
+	var equipped int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM loadout_cosmetics lc JOIN loadouts l ON l.loadout_id = lc.loadout_id
+		WHERE l.player_id = ? AND l.is_active = 1`, playerID).Scan(&equipped); err != nil {
+		t.Fatalf("Failed to count equipped cosmetics: %v", err)
+	}
+	if equipped != len(cosmeticIDs) {
+		t.Errorf("Expected both cosmetics in the active loadout, got %d", equipped)
+	}
+}
+
+func TestLoadoutHandlers_MultipleLoadouts(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level) VALUES ('Skin', 'character_skin', 'common', 1)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, cosmeticID); err != nil {
+		t.Fatalf("Failed to grant cosmetic: %v", err)
+	}
+
+	send := func(method, path string, payload interface{}) *http.Response {
+		body := bytes.NewReader(nil)
+		if payload != nil {
+			b, _ := json.Marshal(payload)
+			body = bytes.NewReader(b)
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request %s %s: %v", method, path, err)
+		}
+		return resp
+	}
+	create := func(name string) int64 {
+		resp := send(http.MethodPost, "/loadouts", map[string]interface{}{"name": name})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating %q, got %d", name, resp.StatusCode)
+		}
+		var created struct {
+			LoadoutID int64 `json:"loadout_id"`
+			IsActive  bool  `json:"is_active"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if created.IsActive {
+			t.Errorf("Expected new loadout %q to be inactive", name)
+		}
+		return created.LoadoutID
+	}
+	type loadoutView struct {
+		LoadoutID int64  `json:"loadout_id"`
+		Name      string `json:"name"`
+		IsActive  bool   `json:"is_active"`
+		Slots     []struct {
+			Slot       string `json:"slot"`
+			CosmeticID int64  `json:"cosmetic_id"`
+		} `json:"slots"`
+	}
+	list := func() map[int64]loadoutView {
+		resp := send(http.MethodGet, "/loadouts", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 listing loadouts, got %d", resp.StatusCode)
+		}
+		var loadouts []loadoutView
+		if err := json.NewDecoder(resp.Body).Decode(&loadouts); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		byID := make(map[int64]loadoutView, len(loadouts))
+		for _, l := range loadouts {
+			byID[l.LoadoutID] = l
+		}
+		return byID
+	}
+
+	if resp := send(http.MethodPost, "/loadouts", map[string]interface{}{"name": "   "}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a blank name, got %d", resp.StatusCode)
+	}
+
+	attack := create("Attack")
+	defense := create("Defense")
+
+	// Switching the active loadout leaves exactly one active
+	for _, id := range []int64{attack, defense} {
+		if resp := send(http.MethodPost, "/loadouts/"+strconv.FormatInt(id, 10)+"/activate", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 activating %d, got %d", id, resp.StatusCode)
+		}
+		loadouts := list()
+		if len(loadouts) != 2 {
+			t.Fatalf("Expected 2 loadouts, got %d", len(loadouts))
+		}
+		for lid, l := range loadouts {
+			if l.IsActive != (lid == id) {
+				t.Errorf("After activating %d, loadout %d (%s) has is_active=%v", id, lid, l.Name, l.IsActive)
+			}
+		}
+	}
+	if resp := send(http.MethodPost, "/loadouts/999999/activate", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 activating an unknown loadout, got %d", resp.StatusCode)
+	}
+	if !list()[defense].IsActive {
+		t.Error("Expected a failed activation to keep the current loadout active")
+	}
+
+	// Equip into the inactive loadout without touching the active one
+	if resp := send(http.MethodPut, "/cosmetics/equip", map[string]interface{}{"cosmetic_id": cosmeticID, "loadout_id": attack}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 equipping into loadout %d, got %d", attack, resp.StatusCode)
+	}
+	loadouts := list()
+	if slots := loadouts[attack].Slots; len(slots) != 1 || slots[0].CosmeticID != cosmeticID || slots[0].Slot != "character_skin" {
+		t.Errorf("Expected the cosmetic in the inactive loadout, got %+v", slots)
+	}
+	if slots := loadouts[defense].Slots; len(slots) != 0 {
+		t.Errorf("Expected the active loadout to stay empty, got %+v", slots)
+	}
+	if resp := send(http.MethodPut, "/cosmetics/equip", map[string]interface{}{"cosmetic_id": cosmeticID, "loadout_id": 999999}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 equipping into an unknown loadout, got %d", resp.StatusCode)
+	}
+
+	// The active loadout can't be deleted; an inactive one can
+	if resp := send(http.MethodDelete, "/loadouts/"+strconv.FormatInt(defense, 10), nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 deleting the active loadout, got %d", resp.StatusCode)
+	}
+	if resp := send(http.MethodDelete, "/loadouts/"+strconv.FormatInt(attack, 10), nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204 deleting an inactive loadout, got %d", resp.StatusCode)
+	}
+	if resp := send(http.MethodDelete, "/loadouts/"+strconv.FormatInt(attack, 10), nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 deleting a deleted loadout, got %d", resp.StatusCode)
+	}
+	if loadouts := list(); len(loadouts) != 1 || !loadouts[defense].IsActive {
+		t.Errorf("Expected only the active loadout to remain, got %+v", loadouts)
+	}
+}
+
+func TestPlayerHandlers_GetPublicLoadoutDefaults(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	cosmeticIDs := make(map[string]int64)
+	for _, slot := range []string{"character_skin", "badge", "title"} {
+		for _, kind := range []string{"default", "owned"} {
+			res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level) VALUES (?, ?, 'common', 1)`, kind+" "+slot, slot)
+			if err != nil {
+				t.Fatalf("Failed to insert cosmetic item: %v", err)
+			}
+			id, _ := res.LastInsertId()
+			cosmeticIDs[kind+" "+slot] = id
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, playerID, cosmeticIDs["owned character_skin"]); err != nil {
+		t.Fatalf("Failed to grant cosmetic: %v", err)
+	}
+
+	cfg := testutils.GetTestConfig()
+	cfg.Cosmetics.DefaultSlotCosmetics = map[string]int64{
+		"character_skin": cosmeticIDs["default character_skin"],
+		"badge":          cosmeticIDs["default badge"],
+		"title":          cosmeticIDs["default title"],
+	}
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+	body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticIDs["owned character_skin"]})
+	req := httptest.NewRequest(http.MethodPut, "/cosmetics/equip", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 equipping cosmetic, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/players/"+strconv.FormatInt(playerID, 10)+"/loadout", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Slots []struct {
+			Slot       string `json:"slot"`
+			CosmeticID int64  `json:"cosmetic_id"`
+			IsDefault  bool   `json:"is_default"`
+		} `json:"slots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result.Slots) != 3 {
+		t.Fatalf("Expected 3 slots, got %d", len(result.Slots))
+	}
+	expected := map[string]struct {
+		cosmeticID int64
+		isDefault  bool
+	}{
+		"character_skin": {cosmeticIDs["owned character_skin"], false},
+		"badge":          {cosmeticIDs["default badge"], true},
+		"title":          {cosmeticIDs["default title"], true},
+	}
+	for _, s := range result.Slots {
+		want, ok := expected[s.Slot]
+		if !ok {
+			t.Errorf("Unexpected slot %q", s.Slot)
+			continue
+		}
+		if s.CosmeticID != want.cosmeticID || s.IsDefault != want.isDefault {
+			t.Errorf("Slot %s: expected cosmetic %d (default=%v), got %d (default=%v)", s.Slot, want.cosmeticID, want.isDefault, s.CosmeticID, s.IsDefault)
+		}
+	}
+
+	// Unknown players return 404
+	req = httptest.NewRequest(http.MethodGet, "/players/999999/loadout", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoadoutHandlers_ResetLoadout(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	expired := time.Now().UTC().Add(-time.Hour).Format("2006-01-02T15:04:05Z")
+	items := []struct {
+		name           string
+		slot           string
+		rarity         string
+		availableUntil interface{}
+		owned          bool
+	}{
+		{"Common Skin", "character_skin", "common", nil, true},
+		{"Legendary Skin", "character_skin", "legendary", nil, true},
+		{"Rare Badge", "badge", "rare", nil, true},
+		{"Expired Epic Badge", "badge", "epic", expired, true},
+		{"Unowned Title", "title", "epic", nil, false},
+	}
+	ids := make(map[string]int64)
+	for _, item := range items {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, available_until) VALUES (?, ?, ?, 1, ?)`,
+			item.name, item.slot, item.rarity, item.availableUntil)
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic item: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		ids[item.name] = id
+		if item.owned {
+			if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, id); err != nil {
+				t.Fatalf("Failed to grant cosmetic: %v", err)
+			}
+		}
+	}
+
+	// Start from a non-default state
+	body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": ids["Common Skin"]})
+	req := httptest.NewRequest(http.MethodPut, "/cosmetics/equip", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 equipping cosmetic, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/loadouts/reset", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result struct {
+		Slots []struct {
+			Slot       string `json:"slot"`
+			CosmeticID int64  `json:"cosmetic_id"`
+		} `json:"slots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	expected := map[string]int64{
+		"badge":          ids["Rare Badge"],
+		"character_skin": ids["Legendary Skin"],
+	}
+	if len(result.Slots) != len(expected) {
+		t.Fatalf("Expected %d equipped slots, got %+v", len(expected), result.Slots)
+	}
+	for _, slot := range result.Slots {
+		if expected[slot.Slot] != slot.CosmeticID {
+			t.Errorf("Slot %s: expected cosmetic %d, got %d", slot.Slot, expected[slot.Slot], slot.CosmeticID)
+		}
+	}
+
+	// The stored loadout matches the response
+	rows, err := db.Query(`SELECT lc.slot, lc.cosmetic_id FROM loadout_cosmetics lc JOIN loadouts l ON l.loadout_id = lc.loadout_id WHERE l.player_id = ? AND l.is_active = 1`, playerID)
+	if err != nil {
+		t.Fatalf("Failed to query loadout: %v", err)
+	}
+	defer rows.Close()
+	stored := make(map[string]int64)
+	for rows.Next() {
+		var slot string
+		var cosmeticID int64
+		if err := rows.Scan(&slot, &cosmeticID); err != nil {
+			t.Fatalf("Failed to scan loadout row: %v", err)
+		}
+		stored[slot] = cosmeticID
+	}
+	if len(stored) != len(expected) || stored["badge"] != expected["badge"] || stored["character_skin"] != expected["character_skin"] {
+		t.Errorf("Expected stored loadout %v, got %v", expected, stored)
+	}
+}
+
+func TestAccountHandlers_PurchaseCosmeticDailyLimit(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost, max_per_day) VALUES (?, ?, ?, ?, ?, ?)`,
+		"Limited Emote", "emote", "rare", 1, 50, 1)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	if _, err := db.Exec(`UPDATE player_progression SET data_currency = 200 WHERE player_id = ?`, playerID); err != nil {
+		t.Fatalf("Failed to set data currency: %v", err)
+	}
+
+	purchase := func() int {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(http.MethodPost, "/cosmetics/purchase", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := purchase(); status != http.StatusOK {
+		t.Fatalf("Expected first purchase to succeed, got %d", status)
+	}
+
+	// Drop ownership so only the daily cap can block the second purchase
+	if _, err := db.Exec(`DELETE FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, playerID, cosmeticID); err != nil {
+		t.Fatalf("Failed to remove ownership: %v", err)
+	}
+	if status := purchase(); status != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 once the daily cap is hit, got %d", status)
+	}
+
+	var balance int64
+	if err := db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&balance); err != nil {
+		t.Fatalf("Failed to read balance: %v", err)
+	}
+	if balance != 150 {
+		t.Errorf("Expected balance 150 after a single charge, got %d", balance)
+	}
+}
+
+func TestAccountHandlers_UndoPurchase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, ?, ?, ?, ?)`,
+		"Regret Skin", "character_skin", "rare", 1, 120)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	if _, err := db.Exec(`UPDATE player_progression SET data_currency = 200 WHERE player_id = ?`, playerID); err != nil {
+		t.Fatalf("Failed to set data currency: %v", err)
+	}
+
+	post := func(path string) int {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	equip := func() {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(http.MethodPut, "/cosmetics/equip", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to equip cosmetic: %v", err)
+		}
+	}
+	balance := func() int64 {
+		var b int64
+		if err := db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&b); err != nil {
+			t.Fatalf("Failed to read balance: %v", err)
+		}
+		return b
+	}
+
+	t.Run("within window", func(t *testing.T) {
+		if status := post("/cosmetics/purchase"); status != http.StatusOK {
+			t.Fatalf("Expected purchase to succeed, got %d", status)
+		}
+		equip()
+		if status := post("/cosmetics/purchase/undo"); status != http.StatusOK {
+			t.Fatalf("Expected undo to succeed, got %d", status)
+		}
+		if b := balance(); b != 200 {
+			t.Errorf("Expected full refund to 200, got %d", b)
+		}
+		var owned, equipped, refunds int
+		db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, playerID, cosmeticID).Scan(&owned)
+		db.QueryRow(`SELECT COUNT(*) FROM loadout_cosmetics WHERE cosmetic_id = ?`, cosmeticID).Scan(&equipped)
+		db.QueryRow(`SELECT COUNT(*) FROM currency_transactions WHERE player_id = ? AND transaction_type = 'refund' AND amount = 120`, playerID).Scan(&refunds)
+		if owned != 0 || equipped != 0 || refunds != 1 {
+			t.Errorf("Expected ownership and loadout cleared with one refund, got owned=%d equipped=%d refunds=%d", owned, equipped, refunds)
+		}
+		if status := post("/cosmetics/purchase/undo"); status != http.StatusConflict {
+			t.Errorf("Expected repeated undo to conflict, got %d", status)
+		}
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		if status := post("/cosmetics/purchase"); status != http.StatusOK {
+			t.Fatalf("Expected purchase to succeed, got %d", status)
+		}
+		if _, err := db.Exec(`UPDATE currency_transactions SET created_at = ? WHERE player_id = ? AND transaction_type = 'purchase'`,
+			time.Now().UTC().Add(-time.Hour).Format("2006-01-02T15:04:05Z"), playerID); err != nil {
+			t.Fatalf("Failed to age purchase: %v", err)
+		}
+		if status := post("/cosmetics/purchase/undo"); status != http.StatusForbidden {
+			t.Errorf("Expected status 403 outside the undo window, got %d", status)
+		}
+		if b := balance(); b != 80 {
+			t.Errorf("Expected balance to stay at 80, got %d", b)
+		}
+	})
+}
+
+func TestAccountHandlers_UndoPurchaseAfterReacquire(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, ?, ?, ?, ?)`,
+		"Boomerang Skin", "character_skin", "rare", 1, 120)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	if _, err := db.Exec(`UPDATE player_progression SET data_currency = 200 WHERE player_id = ?`, playerID); err != nil {
+		t.Fatalf("Failed to set data currency: %v", err)
+	}
+
+	post := func(path string) int {
+		body, _ := json.Marshal(map[string]interface{}{"cosmetic_id": cosmeticID})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := post("/cosmetics/purchase"); status != http.StatusOK {
+		t.Fatalf("Expected purchase to succeed, got %d", status)
+	}
+	if status := post("/cosmetics/purchase/undo"); status != http.StatusOK {
+		t.Fatalf("Expected undo to succeed, got %d", status)
+	}
+	// Re-acquire the same cosmetic for free, still inside the undo window
+	if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, cosmeticID); err != nil {
+		t.Fatalf("Failed to grant cosmetic: %v", err)
+	}
+	if status := post("/cosmetics/purchase/undo"); status != http.StatusConflict {
+		t.Errorf("Expected second undo to conflict, got %d", status)
+	}
+
+	var balance int64
+	var owned, refunds int
+	db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&balance)
+	db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, playerID, cosmeticID).Scan(&owned)
+	db.QueryRow(`SELECT COUNT(*) FROM currency_transactions WHERE player_id = ? AND transaction_type = 'refund'`, playerID).Scan(&refunds)
+	if balance != 200 || owned != 1 || refunds != 1 {
+		t.Errorf("Expected one refund and the looted cosmetic kept, got balance=%d owned=%d refunds=%d", balance, owned, refunds)
+	}
+}
+
+func TestCosmeticHandlers_PurchaseBundle(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	insert := func(query string, args ...interface{}) int64 {
+		t.Helper()
+		res, err := db.Exec(query, args...)
+		if err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return id
+	}
+	var cosmeticIDs []int64
+	for _, name := range []string{"Crate Skin", "Crate Emote", "Crate Badge", "Crate Title"} {
+		cosmeticIDs = append(cosmeticIDs, insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, 'other', 'rare', 1, 100)`, name))
+	}
+	bundleID := insert(`INSERT INTO cosmetic_bundles (name, price) VALUES ('Starter Pack', 300)`)
+	for _, id := range cosmeticIDs {
+		insert(`INSERT INTO cosmetic_bundle_items (bundle_id, cosmetic_id) VALUES (?, ?)`, bundleID, id)
+	}
+
+	type purchaseResponse struct {
+		Charged            int64   `json:"charged"`
+		GrantedCosmeticIDs []int64 `json:"granted_cosmetic_ids"`
+	}
+	purchase := func(accessToken string) (int, purchaseResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/cosmetics/bundles/"+strconv.FormatInt(bundleID, 10)+"/purchase", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var body purchaseResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+	setup := func(username string) (int64, string) {
+		playerID := testutils.CreateTestPlayer(t, db, username, username+"@example.com", "password")
+		if _, err := db.Exec(`UPDATE player_progression SET data_currency = 500 WHERE player_id = ?`, playerID); err != nil {
+			t.Fatalf("Failed to set data currency: %v", err)
+		}
+		return playerID, testutils.CreateTestAccessToken(t, db, playerID)
+	}
+	balanceAndOwned := func(playerID int64) (int64, int) {
+		var balance int64
+		var owned int
+		db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&balance)
+		db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ?`, playerID).Scan(&owned)
+		return balance, owned
+	}
+
+	t.Run("lists bundle", func(t *testing.T) {
+		_, accessToken := setup("browser")
+		req := httptest.NewRequest(http.MethodGet, "/cosmetics/bundles", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var bundles []struct {
+			BundleID  int64             `json:"bundle_id"`
+			Price     int64             `json:"price"`
+			Cosmetics []json.RawMessage `json:"cosmetics"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&bundles); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(bundles) != 1 || bundles[0].BundleID != bundleID || bundles[0].Price != 300 || len(bundles[0].Cosmetics) != 4 {
+			t.Errorf("Expected the starter pack with 4 cosmetics at 300, got %+v", bundles)
+		}
+	})
+
+	t.Run("full purchase", func(t *testing.T) {
+		playerID, accessToken := setup("fullbuyer")
+		status, body := purchase(accessToken)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if body.Charged != 300 || len(body.GrantedCosmeticIDs) != 4 {
+			t.Errorf("Expected 4 cosmetics for 300, got %+v", body)
+		}
+		if balance, owned := balanceAndOwned(playerID); balance != 200 || owned != 4 {
+			t.Errorf("Expected balance 200 and 4 owned, got %d and %d", balance, owned)
+		}
+		var txType string
+		var referenceID int64
+		db.QueryRow(`SELECT transaction_type, reference_id FROM currency_transactions WHERE player_id = ? AND amount = -300`, playerID).Scan(&txType, &referenceID)
+		if txType != "bundle_purchase" || referenceID != bundleID {
+			t.Errorf("Expected a bundle_purchase transaction for bundle %d, got %q for %d", bundleID, txType, referenceID)
+		}
+
+		if status, _ := purchase(accessToken); status != http.StatusConflict {
+			t.Errorf("Expected status 409 once everything is owned, got %d", status)
+		}
+		if balance, _ := balanceAndOwned(playerID); balance != 200 {
+			t.Errorf("Expected balance to stay at 200, got %d", balance)
+		}
+	})
+
+	t.Run("partial purchase", func(t *testing.T) {
+		playerID, accessToken := setup("partialbuyer")
+		insert(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'loot_drop')`, playerID, cosmeticIDs[0])
+
+		status, body := purchase(accessToken)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		// 3 of 4 items missing: 300 * 3 / 4
+		if body.Charged != 225 || len(body.GrantedCosmeticIDs) != 3 {
+			t.Errorf("Expected 3 cosmetics for 225, got %+v", body)
+		}
+		for _, id := range body.GrantedCosmeticIDs {
+			if id == cosmeticIDs[0] {
+				t.Errorf("Expected already owned cosmetic %d not to be granted again", id)
+			}
+		}
+		if balance, owned := balanceAndOwned(playerID); balance != 275 || owned != 4 {
+			t.Errorf("Expected balance 275 and 4 owned, got %d and %d", balance, owned)
+		}
+	})
+}
+
+func TestStoreHandlers_GetStore(t *testing.T) {
+	type storeItem struct {
+		CosmeticID int64 `json:"cosmetic_id"`
+		Owned      bool  `json:"owned"`
+	}
+	type storeResponse struct {
+		DataCurrency int64        `json:"data_currency"`
+		Featured     []*storeItem `json:"featured"`
+		Bundles      []struct {
+			BundleID    int64        `json:"bundle_id"`
+			PlayerPrice int64        `json:"player_price"`
+			FullyOwned  bool         `json:"fully_owned"`
+			Cosmetics   []*storeItem `json:"cosmetics"`
+		} `json:"bundles"`
+		Catalog []*storeItem `json:"catalog"`
+	}
+	getStore := func(t *testing.T, app *fiber.App, accessToken string) (storeResponse, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodGet, "/store", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var raw map[string]json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		var store storeResponse
+		for key, value := range raw {
+			var target any
+			switch key {
+			case "data_currency":
+				target = &store.DataCurrency
+			case "featured":
+				target = &store.Featured
+			case "bundles":
+				target = &store.Bundles
+			case "catalog":
+				target = &store.Catalog
+			default:
+				continue
+			}
+			if err := json.Unmarshal(value, target); err != nil {
+				t.Fatalf("Failed to decode %s: %v", key, err)
+			}
+		}
+		return store, raw
+	}
+
+	t.Run("all sections", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		defer db.Close()
+
+		playerID := testutils.CreateTestPlayer(t, db, "shopper", "shopper@example.com", "password")
+		accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+		if _, err := db.Exec(`UPDATE player_progression SET data_currency = 300 WHERE player_id = ?`, playerID); err != nil {
+			t.Fatalf("Failed to set data currency: %v", err)
+		}
+		insert := func(query string, args ...interface{}) int64 {
+			t.Helper()
+			res, err := db.Exec(query, args...)
+			if err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+			id, _ := res.LastInsertId()
+			return id
+		}
+		ownedID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Owned Skin', 'character_skin', 'rare', 1, 100)`)
+		missingID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Missing Emote', 'emote', 'rare', 1, 100)`)
+		featuredID := insert(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES ('Featured Badge', 'badge', 'epic', 1, 300)`)
+		insert(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, playerID, ownedID)
+		partialID := insert(`INSERT INTO cosmetic_bundles (name, price) VALUES ('Duo Pack', 160)`)
+		insert(`INSERT INTO cosmetic_bundle_items (bundle_id, cosmetic_id) VALUES (?, ?), (?, ?)`, partialID, ownedID, partialID, missingID)
+		completeID := insert(`INSERT INTO cosmetic_bundles (name, price) VALUES ('Solo Pack', 80)`)
+		insert(`INSERT INTO cosmetic_bundle_items (bundle_id, cosmetic_id) VALUES (?, ?)`, completeID, ownedID)
+
+		cfg := testutils.GetTestConfig()
+		// 9999 does not exist and must be skipped
+		cfg.Cosmetics.FeaturedCosmetics = []int64{featuredID, 9999}
+		app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+		store, _ := getStore(t, app, accessToken)
+		if store.DataCurrency != 300 {
+			t.Errorf("Expected data currency 300, got %d", store.DataCurrency)
+		}
+		if len(store.Catalog) != 3 {
+			t.Fatalf("Expected 3 catalog items, got %d", len(store.Catalog))
+		}
+		for _, item := range store.Catalog {
+			if item.Owned != (item.CosmeticID == ownedID) {
+				t.Errorf("Expected cosmetic %d owned=%v, got %v", item.CosmeticID, item.CosmeticID == ownedID, item.Owned)
+			}
+		}
+		if len(store.Featured) != 1 || store.Featured[0].CosmeticID != featuredID || store.Featured[0].Owned {
+			t.Errorf("Expected only the unowned featured badge, got %+v", store.Featured)
+		}
+		if len(store.Bundles) != 2 {
+			t.Fatalf("Expected 2 bundles, got %d", len(store.Bundles))
+		}
+		partial, complete := store.Bundles[0], store.Bundles[1]
+		if partial.BundleID != partialID || partial.FullyOwned || partial.PlayerPrice != 80 || len(partial.Cosmetics) != 2 {
+			t.Errorf("Expected partially owned duo pack at 80, got %+v", partial)
+		}
+		for _, item := range partial.Cosmetics {
+			if item.Owned != (item.CosmeticID == ownedID) {
+				t.Errorf("Expected bundle cosmetic %d owned=%v, got %v", item.CosmeticID, item.CosmeticID == ownedID, item.Owned)
+			}
+		}
+		if complete.BundleID != completeID || !complete.FullyOwned || complete.PlayerPrice != 0 {
+			t.Errorf("Expected fully owned solo pack, got %+v", complete)
+		}
+	})
+
+	t.Run("nothing configured", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		defer db.Close()
+		app := createFullTestServer(t, db)
+
+		playerID := testutils.CreateTestPlayer(t, db, "window", "window@example.com", "password")
+		accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+		store, raw := getStore(t, app, accessToken)
+		for _, section := range []string{"data_currency", "featured", "bundles", "catalog"} {
+			if _, ok := raw[section]; !ok {
+				t.Errorf("Expected section %q in response", section)
+			}
+		}
+		for _, section := range []string{"featured", "bundles", "catalog"} {
+			if string(raw[section]) != "[]" {
+				t.Errorf("Expected %s to be an empty list, got %s", section, raw[section])
+			}
+		}
+		if store.DataCurrency != 0 {
+			t.Errorf("Expected data currency 0, got %d", store.DataCurrency)
+		}
+	})
+}
+
+func TestAdminHandlers_BackfillPrestigeCosmetic(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	// Use a batch size of 1 so the grant spans multiple transactions
+	cfg := testutils.GetTestConfig()
+	cfg.Progression.PrestigeBackfillBatchSize = 1
+	gw := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db)
+	app := gw.Router()
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+
+	_, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, is_prestige_only) VALUES (?, ?, ?, ?, ?)`,
+		"Prestige Badge", "badge", "legendary", 2, 1)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	var cosmeticID int64
+	if err := db.QueryRow(`SELECT cosmetic_id FROM cosmetic_items WHERE name = ?`, "Prestige Badge").Scan(&cosmeticID); err != nil {
+		t.Fatalf("Failed to get cosmetic ID: %v", err)
+	}
+
+	prestigeLevels := map[string]int64{"rookie": 1, "veteranA": 2, "veteranB": 3, "owner": 4}
+	players := make(map[string]int64)
+	for name, level := range prestigeLevels {
+		id := testutils.CreateTestPlayer(t, db, name, name+"@example.com", "password")
+		if _, err := db.Exec(`UPDATE player_progression SET prestige_level = ? WHERE player_id = ?`, level, id); err != nil {
+			t.Fatalf("Failed to set prestige level: %v", err)
+		}
+		players[name] = id
+	}
+	if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'prestige')`, players["owner"], cosmeticID); err != nil {
+		t.Fatalf("Failed to grant existing cosmetic: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cosmetics/"+strconv.FormatInt(cosmeticID, 10)+"/backfill-prestige", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result["granted"].(float64) != 2 {
+		t.Errorf("Expected 2 grants, got %v", result["granted"])
+	}
+
+	expectOwned := map[string]bool{"rookie": false, "veteranA": true, "veteranB": true, "owner": true}
+	for name, want := range expectOwned {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ? AND cosmetic_id = ?`, players[name], cosmeticID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count cosmetics: %v", err)
+		}
+		if (count == 1) != want {
+			t.Errorf("Player %s: expected owned=%v, got count %d", name, want, count)
+		}
+	}
+	var adminCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM player_cosmetics WHERE player_id = ?`, adminID).Scan(&adminCount); err != nil {
+		t.Fatalf("Failed to count admin cosmetics: %v", err)
+	}
+	if adminCount != 0 {
+		t.Errorf("Expected admin (prestige 0) to receive nothing, got %d", adminCount)
+	}
+}
+
+func TestAdminHandlers_AdjustCurrency(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+	playerID := testutils.CreateTestPlayer(t, db, "player", "player@example.com", "password")
+
+	adjust := func(token string, id int64, payload map[string]interface{}) *http.Response {
+		t.Helper()
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/admin/players/"+strconv.FormatInt(id, 10)+"/currency", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+	balance := func() int64 {
+		t.Helper()
+		var currency int64
+		if err := db.QueryRow(`SELECT data_currency FROM player_progression WHERE player_id = ?`, playerID).Scan(&currency); err != nil {
+			t.Fatalf("Failed to read balance: %v", err)
+		}
+		return currency
+	}
+
+	resp := adjust(adminToken, playerID, map[string]interface{}{"amount": 500, "reason": "server outage compensation"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for grant, got %d", resp.StatusCode)
+	}
+	var result struct {
+		DataCurrency int64 `json:"data_currency"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.DataCurrency != 500 || balance() != 500 {
+		t.Errorf("Expected balance 500 after grant, got response %d and stored %d", result.DataCurrency, balance())
+	}
+	var txType string
+	var amount, balanceAfter int64
+	if err := db.QueryRow(`SELECT transaction_type, amount, balance_after FROM currency_transactions WHERE player_id = ?`, playerID).Scan(&txType, &amount, &balanceAfter); err != nil {
+		t.Fatalf("Failed to read ledger: %v", err)
+	}
+	if txType != "admin_grant" || amount != 500 || balanceAfter != 500 {
+		t.Errorf("Expected admin_grant ledger entry of 500, got %s %d -> %d", txType, amount, balanceAfter)
+	}
+
+	// Deductions can't take the balance below zero
+	if resp := adjust(adminToken, playerID, map[string]interface{}{"amount": -600, "reason": "exploit clawback"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for over-deduction, got %d", resp.StatusCode)
+	}
+	if balance() != 500 {
+		t.Errorf("Expected balance unchanged after rejected deduction, got %d", balance())
+	}
+	if resp := adjust(adminToken, playerID, map[string]interface{}{"amount": -500, "reason": "exploit clawback"}); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 deducting the whole balance, got %d", resp.StatusCode)
+	}
+	if balance() != 0 {
+		t.Errorf("Expected balance 0 after deduction, got %d", balance())
+	}
+
+	// Validation, unknown players and non-admins
+	if resp := adjust(adminToken, playerID, map[string]interface{}{"amount": 0, "reason": "noop"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for zero amount, got %d", resp.StatusCode)
+	}
+	if resp := adjust(adminToken, playerID, map[string]interface{}{"amount": 10}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a reason, got %d", resp.StatusCode)
+	}
+	if resp := adjust(adminToken, 9999, map[string]interface{}{"amount": 10, "reason": "gift"}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+	if resp := adjust(testutils.CreateTestAccessToken(t, db, playerID), playerID, map[string]interface{}{"amount": 10, "reason": "gift"}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for non-admin, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandlers_CosmeticItemCRUD(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+
+	doRequest := func(method, path string, payload interface{}) *http.Response {
+		t.Helper()
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+	type cosmetic struct {
+		CosmeticID  int64  `json:"cosmetic_id"`
+		Name        string `json:"name"`
+		Slot        string `json:"slot"`
+		Rarity      string `json:"rarity"`
+		UnlockLevel int64  `json:"unlock_level"`
+		DataCost    int64  `json:"data_cost"`
+	}
+	create := func(name string) cosmetic {
+		t.Helper()
+		resp := doRequest(http.MethodPost, "/admin/cosmetics", map[string]interface{}{
+			"name": name, "slot": "weapon_skin", "rarity": "rare", "data_cost": 250,
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 for create, got %d", resp.StatusCode)
+		}
+		var item cosmetic
+		if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+			t.Fatalf("Failed to decode cosmetic: %v", err)
+		}
+		return item
+	}
+
+	// Enum values are checked before they reach the CHECK constraints
+	resp := doRequest(http.MethodPost, "/admin/cosmetics", map[string]interface{}{"name": "Shiny", "slot": "weapon_skin", "rarity": "mythic"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid rarity, got %d", resp.StatusCode)
+	}
+	resp = doRequest(http.MethodPost, "/admin/cosmetics", map[string]interface{}{"name": "Shiny", "slot": "hat", "rarity": "rare"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid slot, got %d", resp.StatusCode)
+	}
+	resp = doRequest(http.MethodPost, "/admin/cosmetics", map[string]interface{}{"name": " ", "slot": "emote", "rarity": "rare"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a name, got %d", resp.StatusCode)
+	}
+
+	item := create("Plasma Rifle")
+	if item.Name != "Plasma Rifle" || item.UnlockLevel != 1 || item.DataCost != 250 {
+		t.Errorf("Unexpected created cosmetic: %+v", item)
+	}
+	path := "/admin/cosmetics/" + strconv.FormatInt(item.CosmeticID, 10)
+
+	resp = doRequest(http.MethodPut, path, map[string]interface{}{"name": "Plasma Rifle Mk II", "slot": "weapon_skin", "rarity": "epic", "unlock_level": 5, "data_cost": 400})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for update, got %d", resp.StatusCode)
+	}
+	resp = doRequest(http.MethodGet, path, nil)
+	var fetched cosmetic
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		t.Fatalf("Failed to decode cosmetic: %v", err)
+	}
+	if fetched.Name != "Plasma Rifle Mk II" || fetched.Rarity != "epic" || fetched.UnlockLevel != 5 || fetched.DataCost != 400 {
+		t.Errorf("Expected updated cosmetic, got %+v", fetched)
+	}
+	if resp := doRequest(http.MethodPut, "/admin/cosmetics/9999", map[string]interface{}{"name": "Ghost", "slot": "emote", "rarity": "common"}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 updating unknown cosmetic, got %d", resp.StatusCode)
+	}
+
+	// A cosmetic in a loot table or owned by a player can't be deleted
+	if _, err := db.Exec(`INSERT INTO loot_tables (name, drop_chance) VALUES ('Crate', 0.5)`); err != nil {
+		t.Fatalf("Failed to insert loot table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO loot_table_entries (loot_table_id, cosmetic_id, weight) VALUES (last_insert_rowid(), ?, 10)`, item.CosmeticID); err != nil {
+		t.Fatalf("Failed to insert loot entry: %v", err)
+	}
+	if resp := doRequest(http.MethodDelete, path, nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 deleting a cosmetic in a loot table, got %d", resp.StatusCode)
+	}
+	owned := create("Victory Dance")
+	if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, adminID, owned.CosmeticID); err != nil {
+		t.Fatalf("Failed to grant cosmetic: %v", err)
+	}
+	if resp := doRequest(http.MethodDelete, "/admin/cosmetics/"+strconv.FormatInt(owned.CosmeticID, 10), nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 deleting an owned cosmetic, got %d", resp.StatusCode)
+	}
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cosmetic_items`).Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count cosmetics: %v", err)
+	}
+	if remaining != 2 {
+		t.Errorf("Expected both in-use cosmetics kept, got %d", remaining)
+	}
+
+	unused := create("Unused Badge")
+	unusedPath := "/admin/cosmetics/" + strconv.FormatInt(unused.CosmeticID, 10)
+	if resp := doRequest(http.MethodDelete, unusedPath, nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204 deleting an unused cosmetic, got %d", resp.StatusCode)
+	}
+	if resp := doRequest(http.MethodGet, unusedPath, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 after delete, got %d", resp.StatusCode)
+	}
+
+	resp = doRequest(http.MethodGet, "/admin/cosmetics", nil)
+	var list struct {
+		Cosmetics []cosmetic `json:"cosmetics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	if len(list.Cosmetics) != 2 {
+		t.Errorf("Expected 2 cosmetics listed, got %d", len(list.Cosmetics))
+	}
+}
+
+func TestAdminHandlers_GetEconomySnapshot(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	adminID := testutils.CreateTestPlayer(t, db, "admin", "admin@example.com", "password")
+	if _, err := db.Exec(`UPDATE players SET is_admin = 1 WHERE player_id = ?`, adminID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	adminToken := testutils.CreateTestAccessToken(t, db, adminID)
+	alice := testutils.CreateTestPlayer(t, db, "alice", "alice@example.com", "password")
+	bob := testutils.CreateTestPlayer(t, db, "bob", "bob@example.com", "password")
+
+	balances := map[int64]int64{adminID: 0, alice: 300, bob: 600}
+	for id, balance := range balances {
+		if _, err := db.Exec(`UPDATE player_progression SET data_currency = ? WHERE player_id = ?`, balance, id); err != nil {
+			t.Fatalf("Failed to set balance: %v", err)
+		}
+	}
+
+	var cosmeticIDs []int64
+	for _, name := range []string{"Hat", "Cape"} {
+		res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level, data_cost) VALUES (?, 'badge', 'common', 1, 100)`, name)
+		if err != nil {
+			t.Fatalf("Failed to insert cosmetic item: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		cosmeticIDs = append(cosmeticIDs, id)
+	}
+
+	txns := []struct {
+		player    int64
+		amount    int64
+		txType    string
+		reference interface{}
+	}{
+		{alice, 500, "match_reward", nil},
+		{bob, 700, "match_reward", nil},
+		{bob, 100, "admin_grant", nil},
+		{alice, -100, "purchase", cosmeticIDs[0]},
+		{alice, -100, "purchase", cosmeticIDs[1]},
+		{bob, -100, "purchase", cosmeticIDs[0]},
+		{bob, -100, "purchase", cosmeticIDs[0]},
+	}
+	for _, tx := range txns {
+		if _, err := db.Exec(`INSERT INTO currency_transactions (player_id, amount, balance_after, transaction_type, reference_id) VALUES (?, ?, 0, ?, ?)`,
+			tx.player, tx.amount, tx.txType, tx.reference); err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/economy/snapshot", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		TotalCirculation int64   `json:"total_circulation"`
+		AverageBalance   float64 `json:"average_balance"`
+		PlayerCount      int64   `json:"player_count"`
+		TotalEarned      int64   `json:"total_earned"`
+		TotalSpent       int64   `json:"total_spent"`
+		TopSellers       []struct {
+			CosmeticID int64 `json:"cosmetic_id"`
+			UnitsSold  int64 `json:"units_sold"`
+			Revenue    int64 `json:"revenue"`
+		} `json:"top_sellers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.TotalCirculation != 900 {
+		t.Errorf("Expected circulation 900, got %d", result.TotalCirculation)
+	}
+	if result.PlayerCount != 3 || result.AverageBalance != 300 {
+		t.Errorf("Expected 3 players averaging 300, got %d averaging %v", result.PlayerCount, result.AverageBalance)
+	}
+	if result.TotalEarned != 1300 || result.TotalSpent != 400 {
+		t.Errorf("Expected earned 1300 / spent 400, got %d / %d", result.TotalEarned, result.TotalSpent)
+	}
+	if len(result.TopSellers) != 2 {
+		t.Fatalf("Expected 2 top sellers, got %d", len(result.TopSellers))
+	}
+	if result.TopSellers[0].CosmeticID != cosmeticIDs[0] || result.TopSellers[0].UnitsSold != 3 || result.TopSellers[0].Revenue != 300 {
+		t.Errorf("Unexpected top seller: %+v", result.TopSellers[0])
+	}
+}
+
+func TestCosmeticHandlers_GetFriendsOwningCosmetic(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, rarity, unlock_level) VALUES ('Zombie Skin', 'character_skin', 'rare', 1)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+
+	friends := []struct {
+		username      string
+		status        string
+		requestedByMe bool
+		owns          bool
+		hideInventory bool
+	}{
+		{"alice", "accepted", true, true, false},
+		{"bob", "accepted", false, true, false},   // friendship stored in the other direction
+		{"carol", "accepted", true, false, false}, // friend without the item
+		{"dave", "accepted", true, true, true},    // hides inventory from friends
+		{"eve", "pending", true, true, false},     // not an accepted friend
+	}
+	for _, f := range friends {
+		friendID := testutils.CreateTestPlayer(t, db, f.username, f.username+"@example.com", "password")
+		requester, target := playerID, friendID
+		if !f.requestedByMe {
+			requester, target = friendID, playerID
+		}
+		if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, ?)`, requester, target, f.status); err != nil {
+			t.Fatalf("Failed to insert friendship: %v", err)
+		}
+		if f.owns {
+			if _, err := db.Exec(`INSERT INTO player_cosmetics (player_id, cosmetic_id, unlocked_via) VALUES (?, ?, 'purchase')`, friendID, cosmeticID); err != nil {
+				t.Fatalf("Failed to grant cosmetic: %v", err)
+			}
+		}
+		if f.hideInventory {
+			if _, err := db.Exec(`INSERT INTO player_settings (player_id, inventory_visible_to_friends) VALUES (?, 0)`, friendID); err != nil {
+				t.Fatalf("Failed to insert settings: %v", err)
+			}
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/cosmetics/"+strconv.FormatInt(cosmeticID, 10)+"/friends-owning", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Count   int `json:"count"`
+		Friends []struct {
+			PlayerID int64  `json:"player_id"`
+			Username string `json:"username"`
+		} `json:"friends"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Count != 2 || len(body.Friends) != 2 {
+		t.Fatalf("Expected 2 friends owning the cosmetic, got %+v", body)
+	}
+	if body.Friends[0].Username != "alice" || body.Friends[1].Username != "bob" {
+		t.Errorf("Expected alice and bob, got %+v", body.Friends)
+	}
+
+	// Unknown cosmetic
+	req = httptest.NewRequest(http.MethodGet, "/cosmetics/9999/friends-owning", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown cosmetic, got %d", resp.StatusCode)
+	}
+}
@@ -3,14 +3,20 @@ package progression
 import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/db/types"
+	"ai-zombie-defense/backend-api/internal/metrics"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 type progressionService struct {
@@ -36,7 +42,7 @@ func (s *progressionService) GetPlayerProgression(ctx context.Context, playerID
 			// Create default progression row
 			err = s.queries.CreatePlayerProgression(ctx, s.dbConn, playerID)
 			if err != nil {
-				s.logger.Warn("Failed to create player progression row",
+				logging.FromContext(ctx, s.logger).Warn("Failed to create player progression row",
 					zap.Int64("player_id", playerID),
 					zap.Error(err))
 			}
@@ -61,18 +67,23 @@ func (s *progressionService) GetPlayerProgression(ctx context.Context, playerID
 }
 
 func (s *progressionService) calculateLevelFromXP(xp int64) int64 {
-	if xp <= 0 {
-		return 1
-	}
-	base := int64(s.config.Progression.BaseXPPerLevel)
-	if base <= 0 {
-		base = 1000
-	}
-	level := xp/base + 1
-	if level < 1 {
-		return 1
+	return LevelForXP(s.config.Progression, xp)
+}
+
+func (s *progressionService) xpForNextLevel(level int64) int64 {
+	return XPForNextLevel(s.config.Progression, level)
+}
+
+// XPProgress splits total experience into the XP earned within the current
+// level and the XP that level costs, using the configured curve. Both are
+// derived from calculateLevelFromXP so the client never re-implements the curve.
+func (s *progressionService) XPProgress(experience int64) (int64, int64) {
+	level := s.calculateLevelFromXP(experience)
+	into := experience - XPThreshold(s.config.Progression, level)
+	if into < 0 {
+		into = 0
 	}
-	return level
+	return into, s.xpForNextLevel(level)
 }
 
 func (s *progressionService) AddExperience(ctx context.Context, playerID int64, xpGain int64) error {
@@ -101,7 +112,7 @@ func (s *progressionService) AddExperience(ctx context.Context, playerID int64,
 			PlayerID: playerID,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to update level after XP gain",
+			logging.FromContext(ctx, s.logger).Warn("Failed to update level after XP gain",
 				zap.Int64("player_id", playerID),
 				zap.Int64("new_level", newLevel),
 				zap.Error(err))
@@ -154,7 +165,7 @@ func (s *progressionService) addExperienceWithTx(ctx context.Context, dbTx db.DB
 			PlayerID: playerID,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to update level after XP gain",
+			logging.FromContext(ctx, s.logger).Warn("Failed to update level after XP gain",
 				zap.Int64("player_id", playerID),
 				zap.Int64("new_level", newLevel),
 				zap.Error(err))
@@ -163,20 +174,15 @@ func (s *progressionService) addExperienceWithTx(ctx context.Context, dbTx db.DB
 	return nil
 }
 
-func (s *progressionService) AddMatchRewards(ctx context.Context, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned int64) error {
-	return s.addMatchRewardsWithTx(ctx, s.dbConn, playerID, kills, deaths, wavesSurvived, scrapEarned, dataEarned)
+func (s *progressionService) AddMatchRewards(ctx context.Context, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned, revives, healingGiven int64) error {
+	return s.addMatchRewardsWithTx(ctx, s.dbConn, playerID, kills, deaths, wavesSurvived, scrapEarned, dataEarned, revives, healingGiven)
 }
 
-func (s *progressionService) addMatchRewardsWithTx(ctx context.Context, dbTx db.DBTX, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned int64) error {
-	if kills < 0 || deaths < 0 || wavesSurvived < 0 || scrapEarned < 0 || dataEarned < 0 {
+func (s *progressionService) addMatchRewardsWithTx(ctx context.Context, dbTx db.DBTX, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned, revives, healingGiven int64) error {
+	if kills < 0 || deaths < 0 || wavesSurvived < 0 || scrapEarned < 0 || dataEarned < 0 || revives < 0 || healingGiven < 0 {
 		return fmt.Errorf("match stats cannot be negative")
 	}
-	baseXP := int64(100)
-	xpPerKill := int64(10)
-	xpPerWave := int64(50)
-	xpPerScrap := int64(1)
-
-	totalXP := baseXP + (kills * xpPerKill) + (wavesSurvived * xpPerWave) + (scrapEarned * xpPerScrap)
+	totalXP := MatchRewardXP(s.config.Progression.XPRewards, kills, wavesSurvived, scrapEarned, revives, healingGiven)
 
 	err := s.queries.IncrementMatchStats(ctx, dbTx, &db.IncrementMatchStatsParams{
 		TotalMatchesPlayed: 1,
@@ -200,34 +206,53 @@ func (s *progressionService) addMatchRewardsWithTx(ctx context.Context, dbTx db.
 			PlayerID:     playerID,
 		})
 		if err != nil {
-			s.logger.Warn("Failed to add data currency",
+			logging.FromContext(ctx, s.logger).Warn("Failed to add data currency",
 				zap.Int64("player_id", playerID),
 				zap.Int64("data_earned", dataEarned),
 				zap.Error(err))
+			return nil
+		}
+		balance, err := s.queries.GetDataCurrency(ctx, dbTx, playerID)
+		if err != nil {
+			return fmt.Errorf("failed to get data currency: %w", err)
+		}
+		if err := s.createCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          dataEarned,
+			BalanceAfter:    balance,
+			TransactionType: "match_reward",
+			ReferenceID:     nil,
+		}); err != nil {
+			return fmt.Errorf("failed to create currency transaction: %w", err)
 		}
 	}
 	return nil
 }
 
+// createCurrencyTransaction records a currency transaction and counts it in
+// the minted/spent metrics. The metrics are updated before the caller commits,
+// so a transaction that is later rolled back is still counted.
+func (s *progressionService) createCurrencyTransaction(ctx context.Context, dbTx db.DBTX, arg *db.CreateCurrencyTransactionParams) error {
+	if err := s.queries.CreateCurrencyTransaction(ctx, dbTx, arg); err != nil {
+		return err
+	}
+	metrics.RecordCurrency(arg.TransactionType, arg.Amount)
+	return nil
+}
+
 func (s *progressionService) AddDataCurrency(ctx context.Context, playerID int64, amount int64, transactionType string, referenceID *int64) error {
 	if amount == 0 {
 		return nil
 	}
-	var dbTx db.DBTX
-	var tx *sql.Tx
-	var err error
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		return s.AddDataCurrencyWithTransaction(ctx, tx, playerID, amount, transactionType, referenceID)
+	})
+}
 
-	if db, ok := s.dbConn.(*sql.DB); ok {
-		tx, err = db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
-		}
-		defer tx.Rollback()
-		dbTx = tx
-	} else {
-		dbTx = s.dbConn
+func (s *progressionService) AddDataCurrencyWithTransaction(ctx context.Context, dbTx db.DBTX, playerID int64, amount int64, transactionType string, referenceID *int64) error {
+	if amount == 0 {
+		return nil
 	}
-
 	balance, err := s.queries.GetDataCurrency(ctx, dbTx, playerID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -246,7 +271,7 @@ func (s *progressionService) AddDataCurrency(ctx context.Context, playerID int64
 	}); err != nil {
 		return fmt.Errorf("failed to set data currency: %w", err)
 	}
-	if err := s.queries.CreateCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
+	if err := s.createCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
 		PlayerID:        playerID,
 		Amount:          amount,
 		BalanceAfter:    newBalance,
@@ -255,68 +280,69 @@ func (s *progressionService) AddDataCurrency(ctx context.Context, playerID int64
 	}); err != nil {
 		return fmt.Errorf("failed to create currency transaction: %w", err)
 	}
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
-		}
-	}
 	return nil
 }
 
-func (s *progressionService) PrestigePlayer(ctx context.Context, playerID int64) error {
-	var dbTx db.DBTX
-	var tx *sql.Tx
-	var err error
-
-	if db, ok := s.dbConn.(*sql.DB); ok {
-		tx, err = db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+func (s *progressionService) AdjustDataCurrency(ctx context.Context, playerID int64, amount int64) (int64, error) {
+	if _, err := s.queries.GetPlayer(ctx, s.dbConn, playerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrPlayerNotFound
 		}
-		defer tx.Rollback()
-		dbTx = tx
-	} else {
-		dbTx = s.dbConn
+		return 0, fmt.Errorf("failed to get player: %w", err)
 	}
 
-	err = s.queries.PrestigePlayer(ctx, dbTx, playerID)
+	var balance int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		var err error
+		balance, err = s.queries.GetDataCurrency(ctx, tx, playerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get data currency: %w", err)
+		}
+		if balance+amount < 0 {
+			return ErrInsufficientCurrency
+		}
+		return s.AddDataCurrencyWithTransaction(ctx, tx, playerID, amount, "admin_grant", nil)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to prestige player: %w", err)
+		return 0, err
 	}
+	return balance + amount, nil
+}
 
-	progression, err := s.queries.GetPlayerProgression(ctx, dbTx, playerID)
-	if err != nil {
-		return fmt.Errorf("failed to get player progression: %w", err)
-	}
+func (s *progressionService) PrestigePlayer(ctx context.Context, playerID int64) error {
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if err := s.queries.PrestigePlayer(ctx, tx, playerID); err != nil {
+			return fmt.Errorf("failed to prestige player: %w", err)
+		}
 
-	cosmetics, err := s.queries.GetPrestigeCosmetics(ctx, dbTx, &db.GetPrestigeCosmeticsParams{
-		PlayerID:    playerID,
-		UnlockLevel: progression.PrestigeLevel,
-	})
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to get prestige cosmetics: %w", err)
-	}
+		progression, err := s.queries.GetPlayerProgression(ctx, tx, playerID)
+		if err != nil {
+			return fmt.Errorf("failed to get player progression: %w", err)
+		}
 
-	for _, cosmetic := range cosmetics {
-		err = s.queries.GrantCosmeticToPlayer(ctx, dbTx, &db.GrantCosmeticToPlayerParams{
+		cosmetics, err := s.queries.GetPrestigeCosmetics(ctx, tx, &db.GetPrestigeCosmeticsParams{
 			PlayerID:    playerID,
-			CosmeticID:  cosmetic.CosmeticID,
-			UnlockedVia: "prestige",
+			UnlockLevel: progression.PrestigeLevel,
 		})
-		if err != nil {
-			s.logger.Warn("Failed to grant cosmetic to player",
-				zap.Int64("player_id", playerID),
-				zap.Int64("cosmetic_id", cosmetic.CosmeticID),
-				zap.Error(err))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get prestige cosmetics: %w", err)
 		}
-	}
 
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
+		for _, cosmetic := range cosmetics {
+			err = s.queries.GrantCosmeticToPlayer(ctx, tx, &db.GrantCosmeticToPlayerParams{
+				PlayerID:    playerID,
+				CosmeticID:  cosmetic.CosmeticID,
+				UnlockedVia: "prestige",
+			})
+			if err != nil {
+				logging.FromContext(ctx, s.logger).Warn("Failed to grant cosmetic to player",
+					zap.Int64("player_id", playerID),
+					zap.Int64("cosmetic_id", cosmetic.CosmeticID),
+					zap.Error(err))
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (s *progressionService) GetCosmeticCatalog(ctx context.Context) ([]*db.CosmeticItem, error) {
@@ -327,7 +353,26 @@ func (s *progressionService) GetPlayerCosmetics(ctx context.Context, playerID in
 	return s.queries.GetPlayerCosmetics(ctx, s.dbConn, playerID)
 }
 
-func (s *progressionService) EquipCosmetic(ctx context.Context, playerID int64, cosmeticID int64) error {
+// GetFriendsOwningCosmetic lists the player's accepted friends who own the
+// cosmetic. Friends who turned off inventory_visible_to_friends are left out.
+func (s *progressionService) GetFriendsOwningCosmetic(ctx context.Context, playerID int64, cosmeticID int64) ([]*db.ListFriendsOwningCosmeticRow, error) {
+	if _, err := s.queries.GetCosmeticItem(ctx, s.dbConn, cosmeticID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCosmeticNotFound
+		}
+		return nil, fmt.Errorf("failed to get cosmetic item: %w", err)
+	}
+	friends, err := s.queries.ListFriendsOwningCosmetic(ctx, s.dbConn, &db.ListFriendsOwningCosmeticParams{
+		PlayerID:   playerID,
+		CosmeticID: cosmeticID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends owning cosmetic: %w", err)
+	}
+	return friends, nil
+}
+
+func (s *progressionService) EquipCosmetic(ctx context.Context, playerID int64, cosmeticID int64, loadoutID *int64) error {
 	cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, cosmeticID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -336,7 +381,7 @@ func (s *progressionService) EquipCosmetic(ctx context.Context, playerID int64,
 		return fmt.Errorf("failed to get cosmetic item: %w", err)
 	}
 
-	_, err = s.queries.GetPlayerCosmetic(ctx, s.dbConn, &db.GetPlayerCosmeticParams{
+	owned, err := s.queries.GetPlayerCosmetic(ctx, s.dbConn, &db.GetPlayerCosmeticParams{
 		PlayerID:   playerID,
 		CosmeticID: cosmeticID,
 	})
@@ -347,63 +392,277 @@ func (s *progressionService) EquipCosmetic(ctx context.Context, playerID int64,
 		return fmt.Errorf("failed to check cosmetic ownership: %w", err)
 	}
 
-	loadout, err := s.queries.GetActiveLoadout(ctx, s.dbConn, playerID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			params := &db.CreateLoadoutParams{
-				PlayerID: playerID,
-				Name:     "Default",
-				IsActive: 1,
-			}
-			err = s.queries.CreateLoadout(ctx, s.dbConn, params)
+	// Restricted items (e.g. prestige rewards) only count when they were
+	// unlocked through the named method
+	if cosmetic.EquipRestriction != nil && owned.UnlockedVia != *cosmetic.EquipRestriction {
+		return ErrEquipRestricted
+	}
+
+	// Time-limited event items stay in the inventory after their window closes
+	// but can no longer be equipped
+	if cosmetic.AvailableUntil.Valid && time.Now().UTC().After(cosmetic.AvailableUntil.Time) {
+		return ErrCosmeticExpired
+	}
+
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		var loadout *db.Loadout
+		if loadoutID != nil {
+			loadout, err = s.queries.GetLoadout(ctx, tx, &db.GetLoadoutParams{
+				LoadoutID: *loadoutID,
+				PlayerID:  playerID,
+			})
 			if err != nil {
-				return fmt.Errorf("failed to create default loadout: %w", err)
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrLoadoutNotFound
+				}
+				return fmt.Errorf("failed to get loadout: %w", err)
 			}
-			loadout, err = s.queries.GetActiveLoadout(ctx, s.dbConn, playerID)
+		} else {
+			loadout, err = s.activeLoadout(ctx, tx, playerID)
 			if err != nil {
-				return fmt.Errorf("failed to retrieve created loadout: %w", err)
+				return err
 			}
-		} else {
-			return fmt.Errorf("failed to get active loadout: %w", err)
 		}
-	}
 
-	var dbTx db.DBTX
-	var tx *sql.Tx
-	if db, ok := s.dbConn.(*sql.DB); ok {
-		tx, err = db.BeginTx(ctx, nil)
+		if err := s.queries.DeleteLoadoutCosmeticBySlot(ctx, tx, &db.DeleteLoadoutCosmeticBySlotParams{
+			LoadoutID: loadout.LoadoutID,
+			Slot:      cosmetic.Slot,
+		}); err != nil {
+			return fmt.Errorf("failed to clear slot: %w", err)
+		}
+
+		if err := s.queries.InsertLoadoutCosmetic(ctx, tx, &db.InsertLoadoutCosmeticParams{
+			LoadoutID:  loadout.LoadoutID,
+			CosmeticID: cosmeticID,
+			Slot:       cosmetic.Slot,
+		}); err != nil {
+			return fmt.Errorf("failed to equip cosmetic: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *progressionService) ListLoadouts(ctx context.Context, playerID int64) ([]*Loadout, error) {
+	loadouts, err := s.queries.GetPlayerLoadouts(ctx, s.dbConn, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loadouts: %w", err)
+	}
+	result := make([]*Loadout, 0, len(loadouts))
+	for _, loadout := range loadouts {
+		cosmetics, err := s.queries.GetLoadoutCosmetics(ctx, s.dbConn, loadout.LoadoutID)
 		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+			return nil, fmt.Errorf("failed to get loadout cosmetics: %w", err)
 		}
-		defer tx.Rollback()
-		dbTx = tx
-	} else {
-		dbTx = s.dbConn
+		slots := make([]*LoadoutSlot, 0, len(cosmetics))
+		for _, c := range cosmetics {
+			slots = append(slots, &LoadoutSlot{Slot: c.Slot, CosmeticID: c.CosmeticID})
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+		result = append(result, &Loadout{Loadout: loadout, Slots: slots})
 	}
+	return result, nil
+}
 
-	err = s.queries.DeleteLoadoutCosmeticBySlot(ctx, dbTx, &db.DeleteLoadoutCosmeticBySlotParams{
-		LoadoutID: loadout.LoadoutID,
-		Slot:      cosmetic.Slot,
+func (s *progressionService) CreateLoadout(ctx context.Context, playerID int64, name string) (*db.Loadout, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxLoadoutNameLength {
+		return nil, ErrInvalidLoadoutName
+	}
+	loadout, err := s.queries.CreateLoadout(ctx, s.dbConn, &db.CreateLoadoutParams{
+		PlayerID: playerID,
+		Name:     name,
+		IsActive: 0,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to clear slot: %w", err)
+		return nil, fmt.Errorf("failed to create loadout: %w", err)
 	}
+	return loadout, nil
+}
 
-	err = s.queries.InsertLoadoutCosmetic(ctx, dbTx, &db.InsertLoadoutCosmeticParams{
-		LoadoutID:  loadout.LoadoutID,
-		CosmeticID: cosmeticID,
-		Slot:       cosmetic.Slot,
+// SetActiveLoadout deactivates every loadout before activating the target, in
+// one transaction, so the one-active-loadout index is never violated and an
+// unknown loadout leaves the current one active.
+func (s *progressionService) SetActiveLoadout(ctx context.Context, playerID int64, loadoutID int64) error {
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if err := s.queries.DeactivateLoadouts(ctx, tx, playerID); err != nil {
+			return fmt.Errorf("failed to deactivate loadouts: %w", err)
+		}
+		activated, err := s.queries.UpdateLoadoutActive(ctx, tx, &db.UpdateLoadoutActiveParams{
+			IsActive:  1,
+			LoadoutID: loadoutID,
+			PlayerID:  playerID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to activate loadout: %w", err)
+		}
+		if activated == 0 {
+			return ErrLoadoutNotFound
+		}
+		return nil
 	})
+}
+
+func (s *progressionService) DeleteLoadout(ctx context.Context, playerID int64, loadoutID int64) error {
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		deleted, err := s.queries.DeleteInactiveLoadout(ctx, tx, &db.DeleteInactiveLoadoutParams{
+			LoadoutID: loadoutID,
+			PlayerID:  playerID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete loadout: %w", err)
+		}
+		if deleted > 0 {
+			return nil
+		}
+		// Nothing deleted: either the loadout isn't the player's or it is active
+		if _, err := s.queries.GetLoadout(ctx, tx, &db.GetLoadoutParams{
+			LoadoutID: loadoutID,
+			PlayerID:  playerID,
+		}); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrLoadoutNotFound
+			}
+			return fmt.Errorf("failed to get loadout: %w", err)
+		}
+		return ErrLoadoutActive
+	})
+}
+
+// activeLoadout returns the player's active loadout, creating an active
+// "Default" one first if there is none. The insert comes before the read so a
+// transaction takes SQLite's write lock up front instead of failing to upgrade
+// a stale read, and the one-active-loadout index makes a concurrent duplicate
+// a no-op.
+func (s *progressionService) activeLoadout(ctx context.Context, dbTx db.DBTX, playerID int64) (*db.Loadout, error) {
+	if err := s.queries.EnsureActiveLoadout(ctx, dbTx, playerID); err != nil {
+		return nil, fmt.Errorf("failed to create default loadout: %w", err)
+	}
+	loadout, err := s.queries.GetActiveLoadout(ctx, dbTx, playerID)
 	if err != nil {
-		return fmt.Errorf("failed to equip cosmetic: %w", err)
+		return nil, fmt.Errorf("failed to get active loadout: %w", err)
 	}
+	return loadout, nil
+}
+
+// ResetLoadout clears the active loadout and re-equips the highest-rarity
+// owned cosmetic in each slot (lowest cosmetic ID on ties), skipping items
+// past their available_until. Slots the player owns nothing for stay empty,
+// so the public loadout shows the configured default there.
+func (s *progressionService) ResetLoadout(ctx context.Context, playerID int64) ([]*LoadoutSlot, error) {
+	var slots []*LoadoutSlot
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		loadout, err := s.activeLoadout(ctx, tx, playerID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.queries.ClearLoadoutCosmetics(ctx, tx, loadout.LoadoutID); err != nil {
+			return fmt.Errorf("failed to clear loadout: %w", err)
+		}
+
+		owned, err := s.queries.GetPlayerCosmetics(ctx, tx, playerID)
+		if err != nil {
+			return fmt.Errorf("failed to get player cosmetics: %w", err)
+		}
+		now := time.Now().UTC()
+		best := make(map[string]*db.GetPlayerCosmeticsRow)
+		for _, item := range owned {
+			if item.AvailableUntil.Valid && now.After(item.AvailableUntil.Time) {
+				continue
+			}
+			if item.EquipRestriction != nil && item.UnlockedVia != *item.EquipRestriction {
+				continue
+			}
+			cur, ok := best[item.Slot]
+			if !ok || RarityRank[item.Rarity] > RarityRank[cur.Rarity] ||
+				(RarityRank[item.Rarity] == RarityRank[cur.Rarity] && item.CosmeticID < cur.CosmeticID) {
+				best[item.Slot] = item
+			}
+		}
 
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
+		slots = make([]*LoadoutSlot, 0, len(best))
+		for slot, item := range best {
+			if err := s.queries.InsertLoadoutCosmetic(ctx, tx, &db.InsertLoadoutCosmeticParams{
+				LoadoutID:  loadout.LoadoutID,
+				CosmeticID: item.CosmeticID,
+				Slot:       slot,
+			}); err != nil {
+				return fmt.Errorf("failed to equip cosmetic: %w", err)
+			}
+			slots = append(slots, &LoadoutSlot{Slot: slot, CosmeticID: item.CosmeticID})
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+	return slots, nil
+}
+
+// GetPublicLoadout returns the cosmetics equipped in a player's active loadout,
+// filling empty slots with Cosmetics.DefaultSlotCosmetics. Slots are sorted by name.
+func (s *progressionService) GetPublicLoadout(ctx context.Context, playerID int64) ([]*LoadoutSlot, error) {
+	if _, err := s.queries.GetPlayer(ctx, s.dbConn, playerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	equipped := make(map[string]int64)
+	loadout, err := s.queries.GetActiveLoadout(ctx, s.dbConn, playerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get active loadout: %w", err)
+	}
+	if err == nil {
+		cosmetics, err := s.queries.GetLoadoutCosmetics(ctx, s.dbConn, loadout.LoadoutID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loadout cosmetics: %w", err)
+		}
+		for _, c := range cosmetics {
+			equipped[c.Slot] = c.CosmeticID
+		}
+	}
+
+	slots := make([]*LoadoutSlot, 0, len(equipped)+len(s.config.Cosmetics.DefaultSlotCosmetics))
+	for slot, cosmeticID := range equipped {
+		slots = append(slots, &LoadoutSlot{Slot: slot, CosmeticID: cosmeticID})
+	}
+	for slot, cosmeticID := range s.config.Cosmetics.DefaultSlotCosmetics {
+		if _, ok := equipped[slot]; !ok {
+			slots = append(slots, &LoadoutSlot{Slot: slot, CosmeticID: cosmeticID, IsDefault: true})
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+	return slots, nil
+}
+
+func (s *progressionService) GetEquippedCosmetics(ctx context.Context, playerID int64) ([]*EquippedCosmetic, error) {
+	slots, err := s.GetPublicLoadout(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	equipped := make([]*EquippedCosmetic, 0, len(slots))
+	for _, slot := range slots {
+		cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, slot.CosmeticID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// A configured default may point at a removed cosmetic; skip it
+				logging.FromContext(ctx, s.logger).Warn("equipped cosmetic not found",
+					zap.Int64("player_id", playerID),
+					zap.Int64("cosmetic_id", slot.CosmeticID))
+				continue
+			}
+			return nil, fmt.Errorf("failed to get cosmetic item: %w", err)
+		}
+		equipped = append(equipped, &EquippedCosmetic{
+			Slot:      slot.Slot,
+			IsDefault: slot.IsDefault,
+			Cosmetic:  cosmetic,
+		})
+	}
+	return equipped, nil
 }
 
 func (s *progressionService) PurchaseCosmetic(ctx context.Context, playerID int64, cosmeticID int64) error {
@@ -414,6 +673,10 @@ func (s *progressionService) PurchaseCosmetic(ctx context.Context, playerID int6
 		}
 		return fmt.Errorf("failed to get cosmetic item: %w", err)
 	}
+	// A bought copy of a restricted item could never be equipped
+	if cosmetic.EquipRestriction != nil && *cosmetic.EquipRestriction != "purchase" {
+		return ErrCosmeticNotForSale
+	}
 
 	_, err = s.queries.GetPlayerCosmetic(ctx, s.dbConn, &db.GetPlayerCosmeticParams{
 		PlayerID:   playerID,
@@ -423,68 +686,577 @@ func (s *progressionService) PurchaseCosmetic(ctx context.Context, playerID int6
 		return ErrCosmeticAlreadyOwned
 	}
 
-	balance, err := s.queries.GetDataCurrency(ctx, s.dbConn, playerID)
+	// The cap and the balance are checked on the purchase transaction, so
+	// concurrent purchases can't both pass them before either is recorded
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		// A NULL max_per_day means the item has no daily cap
+		if cosmetic.MaxPerDay != nil {
+			purchasedToday, err := s.queries.CountPurchasesToday(ctx, tx, &db.CountPurchasesTodayParams{
+				PlayerID:    playerID,
+				ReferenceID: &cosmeticID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to count today's purchases: %w", err)
+			}
+			if purchasedToday >= *cosmetic.MaxPerDay {
+				return ErrPurchaseLimitReached
+			}
+		}
+
+		balance, err := s.queries.GetDataCurrency(ctx, tx, playerID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				if err := s.queries.CreatePlayerProgression(ctx, tx, playerID); err != nil {
+					return fmt.Errorf("failed to create player progression: %w", err)
+				}
+				balance = 0
+			} else {
+				return fmt.Errorf("failed to get data currency: %w", err)
+			}
+		}
+
+		if balance < cosmetic.DataCost {
+			return ErrInsufficientCurrency
+		}
+
+		newBalance := balance - cosmetic.DataCost
+		if err := s.queries.SetDataCurrency(ctx, tx, &db.SetDataCurrencyParams{
+			DataCurrency: newBalance,
+			PlayerID:     playerID,
+		}); err != nil {
+			return fmt.Errorf("failed to set data currency: %w", err)
+		}
+
+		if err := s.createCurrencyTransaction(ctx, tx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          -cosmetic.DataCost,
+			BalanceAfter:    newBalance,
+			TransactionType: "purchase",
+			ReferenceID:     &cosmeticID,
+		}); err != nil {
+			return fmt.Errorf("failed to create currency transaction: %w", err)
+		}
+
+		if err := s.queries.GrantCosmeticToPlayer(ctx, tx, &db.GrantCosmeticToPlayerParams{
+			PlayerID:    playerID,
+			CosmeticID:  cosmeticID,
+			UnlockedVia: "purchase",
+		}); err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return ErrCosmeticAlreadyOwned
+			}
+			return fmt.Errorf("failed to grant cosmetic: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *progressionService) ListCosmeticBundles(ctx context.Context) ([]*CosmeticBundle, error) {
+	bundles, err := s.queries.ListActiveCosmeticBundles(ctx, s.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cosmetic bundles: %w", err)
+	}
+	result := make([]*CosmeticBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		items, err := s.queries.ListCosmeticBundleItems(ctx, s.dbConn, bundle.BundleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list bundle items: %w", err)
+		}
+		result = append(result, &CosmeticBundle{Bundle: bundle, Cosmetics: items})
+	}
+	return result, nil
+}
+
+// PurchaseBundle grants every cosmetic in the bundle the player does not own yet
+// and charges the bundle price, pro-rated by the number of missing items when
+// Cosmetics.BundleProRate is set. Ownership, balance and grants are all checked
+// and written in one transaction.
+func (s *progressionService) PurchaseBundle(ctx context.Context, playerID int64, bundleID int64) (*BundlePurchase, error) {
+	var missing []int64
+	var price int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		bundle, err := s.queries.GetCosmeticBundle(ctx, tx, bundleID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBundleNotFound
+			}
+			return fmt.Errorf("failed to get bundle: %w", err)
+		}
+		if bundle.IsActive == 0 {
+			return ErrBundleNotFound
+		}
+
+		items, err := s.queries.ListCosmeticBundleItems(ctx, tx, bundleID)
+		if err != nil {
+			return fmt.Errorf("failed to list bundle items: %w", err)
+		}
+		for _, item := range items {
+			if item.EquipRestriction != nil && *item.EquipRestriction != "purchase" {
+				return ErrCosmeticNotForSale
+			}
+			_, err := s.queries.GetPlayerCosmetic(ctx, tx, &db.GetPlayerCosmeticParams{
+				PlayerID:   playerID,
+				CosmeticID: item.CosmeticID,
+			})
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to check cosmetic ownership: %w", err)
+			}
+			missing = append(missing, item.CosmeticID)
+		}
+		if len(missing) == 0 {
+			return ErrBundleAlreadyOwned
+		}
+
+		price = s.bundlePrice(bundle.Price, len(missing), len(items))
+
+		balance, err := s.queries.GetDataCurrency(ctx, tx, playerID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				if err := s.queries.CreatePlayerProgression(ctx, tx, playerID); err != nil {
+					return fmt.Errorf("failed to create player progression: %w", err)
+				}
+				balance = 0
+			} else {
+				return fmt.Errorf("failed to get data currency: %w", err)
+			}
+		}
+		if balance < price {
+			return ErrInsufficientCurrency
+		}
+
+		newBalance := balance - price
+		if err := s.queries.SetDataCurrency(ctx, tx, &db.SetDataCurrencyParams{
+			DataCurrency: newBalance,
+			PlayerID:     playerID,
+		}); err != nil {
+			return fmt.Errorf("failed to set data currency: %w", err)
+		}
+		if err := s.createCurrencyTransaction(ctx, tx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          -price,
+			BalanceAfter:    newBalance,
+			TransactionType: "bundle_purchase",
+			ReferenceID:     &bundleID,
+		}); err != nil {
+			return fmt.Errorf("failed to create currency transaction: %w", err)
+		}
+
+		for _, cosmeticID := range missing {
+			if err := s.queries.GrantCosmeticToPlayer(ctx, tx, &db.GrantCosmeticToPlayerParams{
+				PlayerID:    playerID,
+				CosmeticID:  cosmeticID,
+				UnlockedVia: "purchase",
+			}); err != nil {
+				return fmt.Errorf("failed to grant cosmetic: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			if err := s.queries.CreatePlayerProgression(ctx, s.dbConn, playerID); err != nil {
-				return fmt.Errorf("failed to create player progression: %w", err)
+		return nil, err
+	}
+	return &BundlePurchase{
+		BundleID:           bundleID,
+		Charged:            price,
+		GrantedCosmeticIDs: missing,
+	}, nil
+}
+
+// bundlePrice is what a player missing the given number of a bundle's items pays for it.
+func (s *progressionService) bundlePrice(price int64, missing, total int) int64 {
+	if s.config.Cosmetics.BundleProRate && missing < total {
+		// Rounds down so a partial owner never pays more than the exact share
+		return price * int64(missing) / int64(total)
+	}
+	return price
+}
+
+// GetStore loads the player's balance, the catalog, their owned cosmetics and
+// the active bundles concurrently and combines them into one store view.
+func (s *progressionService) GetStore(ctx context.Context, playerID int64) (*Store, error) {
+	var (
+		progression *db.PlayerProgression
+		catalog     []*db.CosmeticItem
+		owned       []*db.GetPlayerCosmeticsRow
+		bundles     []*CosmeticBundle
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		progression, err = s.GetPlayerProgression(gctx, playerID)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		catalog, err = s.GetCosmeticCatalog(gctx)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		owned, err = s.GetPlayerCosmetics(gctx, playerID)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		bundles, err = s.ListCosmeticBundles(gctx)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to load store: %w", err)
+	}
+
+	ownedIDs := make(map[int64]bool, len(owned))
+	for _, o := range owned {
+		ownedIDs[o.CosmeticID] = true
+	}
+	toItem := func(cosmetic *db.CosmeticItem) *StoreItem {
+		return &StoreItem{Cosmetic: cosmetic, Owned: ownedIDs[cosmetic.CosmeticID]}
+	}
+
+	store := &Store{
+		DataCurrency: progression.DataCurrency,
+		Catalog:      make([]*StoreItem, 0, len(catalog)),
+		Featured:     []*StoreItem{},
+		Bundles:      make([]*StoreBundle, 0, len(bundles)),
+	}
+	byID := make(map[int64]*db.CosmeticItem, len(catalog))
+	for _, cosmetic := range catalog {
+		byID[cosmetic.CosmeticID] = cosmetic
+		store.Catalog = append(store.Catalog, toItem(cosmetic))
+	}
+	for _, id := range s.config.Cosmetics.FeaturedCosmetics {
+		// Featured IDs that were removed from the catalog are skipped rather than failing the store
+		if cosmetic, ok := byID[id]; ok {
+			store.Featured = append(store.Featured, toItem(cosmetic))
+		}
+	}
+	for _, bundle := range bundles {
+		sb := &StoreBundle{Bundle: bundle.Bundle, Items: make([]*StoreItem, 0, len(bundle.Cosmetics))}
+		missing := 0
+		for _, cosmetic := range bundle.Cosmetics {
+			item := toItem(cosmetic)
+			if !item.Owned {
+				missing++
 			}
-			balance = 0
-		} else {
+			sb.Items = append(sb.Items, item)
+		}
+		sb.FullyOwned = missing == 0
+		if !sb.FullyOwned {
+			sb.Price = s.bundlePrice(bundle.Bundle.Price, missing, len(bundle.Cosmetics))
+		}
+		store.Bundles = append(store.Bundles, sb)
+	}
+	return store, nil
+}
+
+// UndoPurchase refunds the most recent purchase of a cosmetic in full if it was
+// made within Cosmetics.UndoWindow. Ownership is revoked and the cosmetic is
+// removed from every loadout in the same transaction as the refund.
+func (s *progressionService) UndoPurchase(ctx context.Context, playerID int64, cosmeticID int64) error {
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		purchase, err := s.queries.GetLatestPurchase(ctx, tx, &db.GetLatestPurchaseParams{
+			PlayerID:    playerID,
+			ReferenceID: &cosmeticID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPurchaseNotFound
+			}
+			return fmt.Errorf("failed to get latest purchase: %w", err)
+		}
+		if time.Since(purchase.CreatedAt.Time) > s.config.Cosmetics.UndoWindow {
+			return ErrUndoWindowExpired
+		}
+
+		// The cosmetic may have been re-acquired through a loot drop or grant after
+		// an undo; the refund ledger, not ownership, says whether this purchase was
+		// already undone
+		refunds, err := s.queries.CountRefundsAfter(ctx, tx, &db.CountRefundsAfterParams{
+			PlayerID:      playerID,
+			ReferenceID:   &cosmeticID,
+			TransactionID: purchase.TransactionID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count refunds: %w", err)
+		}
+		if refunds > 0 {
+			return ErrPurchaseAlreadyUndone
+		}
+
+		removed, err := s.queries.DeletePurchasedCosmetic(ctx, tx, &db.DeletePurchasedCosmeticParams{
+			PlayerID:   playerID,
+			CosmeticID: cosmeticID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove cosmetic: %w", err)
+		}
+		if removed == 0 {
+			return ErrCosmeticNotOwned
+		}
+
+		if err := s.queries.DeletePlayerLoadoutCosmetic(ctx, tx, &db.DeletePlayerLoadoutCosmeticParams{
+			CosmeticID: cosmeticID,
+			PlayerID:   playerID,
+		}); err != nil {
+			return fmt.Errorf("failed to unequip cosmetic: %w", err)
+		}
+
+		balance, err := s.queries.GetDataCurrency(ctx, tx, playerID)
+		if err != nil {
 			return fmt.Errorf("failed to get data currency: %w", err)
 		}
+		refund := -purchase.Amount
+		newBalance := balance + refund
+		if err := s.queries.SetDataCurrency(ctx, tx, &db.SetDataCurrencyParams{
+			DataCurrency: newBalance,
+			PlayerID:     playerID,
+		}); err != nil {
+			return fmt.Errorf("failed to set data currency: %w", err)
+		}
+		if err := s.createCurrencyTransaction(ctx, tx, &db.CreateCurrencyTransactionParams{
+			PlayerID:        playerID,
+			Amount:          refund,
+			BalanceAfter:    newBalance,
+			TransactionType: "refund",
+			ReferenceID:     &cosmeticID,
+		}); err != nil {
+			return fmt.Errorf("failed to create currency transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+// BackfillPrestigeCosmetic grants a prestige-only cosmetic to every player whose
+// prestige level meets its unlock level and who doesn't already own it. Grants are
+// committed in batches of Progression.PrestigeBackfillBatchSize so a large player
+// base doesn't hold a single long-running write transaction.
+func (s *progressionService) BackfillPrestigeCosmetic(ctx context.Context, cosmeticID int64) (int64, error) {
+	cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, cosmeticID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrCosmeticNotFound
+		}
+		return 0, fmt.Errorf("failed to get cosmetic item: %w", err)
+	}
+	if cosmetic.IsPrestigeOnly == 0 {
+		return 0, ErrCosmeticNotPrestige
 	}
 
-	if balance < cosmetic.DataCost {
-		return ErrInsufficientCurrency
+	batchSize := int64(s.config.Progression.PrestigeBackfillBatchSize)
+	if batchSize <= 0 {
+		batchSize = 500
 	}
 
-	var dbTx db.DBTX
-	var tx *sql.Tx
-	if db, ok := s.dbConn.(*sql.DB); ok {
-		tx, err = db.BeginTx(ctx, nil)
+	var granted int64
+	var lastPlayerID int64
+	for {
+		playerIDs, err := s.queries.ListPrestigeBackfillCandidates(ctx, s.dbConn, &db.ListPrestigeBackfillCandidatesParams{
+			CosmeticID:    cosmeticID,
+			PrestigeLevel: cosmetic.UnlockLevel,
+			PlayerID:      lastPlayerID,
+			Limit:         batchSize,
+		})
+		if err != nil {
+			return granted, fmt.Errorf("failed to list backfill candidates: %w", err)
+		}
+		if len(playerIDs) == 0 {
+			break
+		}
+
+		n, err := s.grantCosmeticBatch(ctx, cosmeticID, playerIDs)
 		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+			return granted, err
+		}
+		granted += n
+		lastPlayerID = playerIDs[len(playerIDs)-1]
+
+		if int64(len(playerIDs)) < batchSize {
+			break
 		}
-		defer tx.Rollback()
-		dbTx = tx
-	} else {
-		dbTx = s.dbConn
 	}
 
-	newBalance := balance - cosmetic.DataCost
-	if err := s.queries.SetDataCurrency(ctx, dbTx, &db.SetDataCurrencyParams{
-		DataCurrency: newBalance,
-		PlayerID:     playerID,
-	}); err != nil {
-		return fmt.Errorf("failed to set data currency: %w", err)
+	logging.FromContext(ctx, s.logger).Info("Prestige cosmetic backfill completed",
+		zap.Int64("cosmetic_id", cosmeticID),
+		zap.Int64("granted", granted))
+	return granted, nil
+}
+
+func (s *progressionService) grantCosmeticBatch(ctx context.Context, cosmeticID int64, playerIDs []int64) (int64, error) {
+	var granted int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		for _, playerID := range playerIDs {
+			err := s.queries.GrantCosmeticToPlayer(ctx, tx, &db.GrantCosmeticToPlayerParams{
+				PlayerID:    playerID,
+				CosmeticID:  cosmeticID,
+				UnlockedVia: "prestige",
+			})
+			if err != nil {
+				// Ownership may have been granted concurrently (e.g. the player just prestiged)
+				if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+					continue
+				}
+				return fmt.Errorf("failed to grant cosmetic: %w", err)
+			}
+			granted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return granted, nil
+}
 
-	if err := s.queries.CreateCurrencyTransaction(ctx, dbTx, &db.CreateCurrencyTransactionParams{
-		PlayerID:        playerID,
-		Amount:          -cosmetic.DataCost,
-		BalanceAfter:    newBalance,
-		TransactionType: "purchase",
-		ReferenceID:     &cosmeticID,
-	}); err != nil {
-		return fmt.Errorf("failed to create currency transaction: %w", err)
+func (s *progressionService) GetEconomySnapshot(ctx context.Context, topSellers int64) (*EconomySnapshot, error) {
+	circulation, err := s.queries.GetCurrencyCirculation(ctx, s.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency circulation: %w", err)
 	}
+	totals, err := s.queries.GetCurrencyTotalsByType(ctx, s.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency totals: %w", err)
+	}
+	sellers, err := s.queries.GetTopCosmeticSellers(ctx, s.dbConn, topSellers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top cosmetic sellers: %w", err)
+	}
+	return &EconomySnapshot{
+		Circulation:  circulation,
+		TotalsByType: totals,
+		TopSellers:   sellers,
+	}, nil
+}
 
-	if err := s.queries.GrantCosmeticToPlayer(ctx, dbTx, &db.GrantCosmeticToPlayerParams{
-		PlayerID:    playerID,
-		CosmeticID:  cosmeticID,
-		UnlockedVia: "purchase",
-	}); err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return ErrCosmeticAlreadyOwned
+func (s *progressionService) GetCurrencyTransactions(ctx context.Context, playerID int64, limit, offset int64) ([]*db.GetCurrencyTransactionsRow, error) {
+	transactions, err := s.queries.GetCurrencyTransactions(ctx, s.dbConn, &db.GetCurrencyTransactionsParams{
+		PlayerID: playerID,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// validateCosmeticItem checks input against the cosmetic_items CHECK constraints
+// so bad values fail with a typed error instead of a SQLite constraint error.
+func validateCosmeticItem(input CosmeticItemInput) error {
+	if !CosmeticSlots[input.Slot] {
+		return ErrInvalidCosmeticSlot
+	}
+	if _, ok := RarityRank[input.Rarity]; !ok {
+		return ErrInvalidRarity
+	}
+	if strings.TrimSpace(input.Name) == "" || input.UnlockLevel < 1 || input.DataCost < 0 {
+		return ErrInvalidCosmeticItem
+	}
+	if input.MaxPerDay != nil && *input.MaxPerDay < 1 {
+		return ErrInvalidCosmeticItem
+	}
+	if input.EquipRestriction != nil && !UnlockMethods[*input.EquipRestriction] {
+		return ErrInvalidCosmeticItem
+	}
+	return nil
+}
+
+func cosmeticAvailableUntil(input CosmeticItemInput) types.NullTimestamp {
+	if input.AvailableUntil == nil {
+		return types.NullTimestamp{}
+	}
+	return types.NullTimestamp{Timestamp: types.Timestamp{Time: input.AvailableUntil.UTC()}, Valid: true}
+}
+
+func (s *progressionService) CreateCosmeticItem(ctx context.Context, input CosmeticItemInput) (*db.CosmeticItem, error) {
+	if err := validateCosmeticItem(input); err != nil {
+		return nil, err
+	}
+	isPrestigeOnly := int64(0)
+	if input.IsPrestigeOnly {
+		isPrestigeOnly = 1
+	}
+	cosmetic, err := s.queries.CreateCosmeticItem(ctx, s.dbConn, &db.CreateCosmeticItemParams{
+		Name:             strings.TrimSpace(input.Name),
+		Description:      input.Description,
+		Slot:             input.Slot,
+		Category:         input.Category,
+		Rarity:           input.Rarity,
+		UnlockLevel:      input.UnlockLevel,
+		DataCost:         input.DataCost,
+		IsPrestigeOnly:   isPrestigeOnly,
+		MaxPerDay:        input.MaxPerDay,
+		AvailableUntil:   cosmeticAvailableUntil(input),
+		EquipRestriction: input.EquipRestriction,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cosmetic item: %w", err)
+	}
+	return cosmetic, nil
+}
+
+func (s *progressionService) GetCosmeticItem(ctx context.Context, cosmeticID int64) (*db.CosmeticItem, error) {
+	cosmetic, err := s.queries.GetCosmeticItem(ctx, s.dbConn, cosmeticID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCosmeticNotFound
 		}
-		return fmt.Errorf("failed to grant cosmetic: %w", err)
+		return nil, fmt.Errorf("failed to get cosmetic item: %w", err)
 	}
+	return cosmetic, nil
+}
 
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
+func (s *progressionService) UpdateCosmeticItem(ctx context.Context, cosmeticID int64, input CosmeticItemInput) (*db.CosmeticItem, error) {
+	if err := validateCosmeticItem(input); err != nil {
+		return nil, err
+	}
+	isPrestigeOnly := int64(0)
+	if input.IsPrestigeOnly {
+		isPrestigeOnly = 1
+	}
+	cosmetic, err := s.queries.UpdateCosmeticItem(ctx, s.dbConn, &db.UpdateCosmeticItemParams{
+		Name:             strings.TrimSpace(input.Name),
+		Description:      input.Description,
+		Slot:             input.Slot,
+		Category:         input.Category,
+		Rarity:           input.Rarity,
+		UnlockLevel:      input.UnlockLevel,
+		DataCost:         input.DataCost,
+		IsPrestigeOnly:   isPrestigeOnly,
+		MaxPerDay:        input.MaxPerDay,
+		AvailableUntil:   cosmeticAvailableUntil(input),
+		EquipRestriction: input.EquipRestriction,
+		CosmeticID:       cosmeticID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCosmeticNotFound
 		}
+		return nil, fmt.Errorf("failed to update cosmetic item: %w", err)
 	}
-	return nil
+	return cosmetic, nil
+}
+
+func (s *progressionService) DeleteCosmeticItem(ctx context.Context, cosmeticID int64) error {
+	return db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		// Every reference cascades, so deleting a cosmetic in use would silently
+		// strip it from players' inventories, loot tables and bundles
+		refs, err := s.queries.CountCosmeticItemReferences(ctx, tx, cosmeticID)
+		if err != nil {
+			return fmt.Errorf("failed to count cosmetic references: %w", err)
+		}
+		if refs.LootEntries > 0 || refs.Owners > 0 || refs.BundleItems > 0 {
+			return ErrCosmeticInUse
+		}
+		deleted, err := s.queries.DeleteCosmeticItem(ctx, tx, cosmeticID)
+		if err != nil {
+			return fmt.Errorf("failed to delete cosmetic item: %w", err)
+		}
+		if deleted == 0 {
+			return ErrCosmeticNotFound
+		}
+		return nil
+	})
 }
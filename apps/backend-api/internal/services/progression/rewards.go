@@ -0,0 +1,55 @@
+package progression
+
+import (
+	"math"
+	"time"
+
+	"ai-zombie-defense/backend-api/pkg/config"
+)
+
+// DefaultXPRewards is the match reward formula used when config.ProgressionConfig.XPRewards is unset.
+var DefaultXPRewards = config.XPRewardsConfig{
+	BaseXP:   100,
+	PerKill:  10,
+	PerWave:  50,
+	PerScrap: 1,
+}
+
+// MatchRewardXP returns the XP a player earns for a match with the given stats.
+// A zero-valued rewards config falls back to DefaultXPRewards.
+func MatchRewardXP(rewards config.XPRewardsConfig, kills, wavesSurvived, scrapEarned, revives, healingGiven int64) int64 {
+	if rewards == (config.XPRewardsConfig{}) {
+		rewards = DefaultXPRewards
+	}
+	return rewards.BaseXP +
+		kills*rewards.PerKill +
+		wavesSurvived*rewards.PerWave +
+		scrapEarned*rewards.PerScrap +
+		revives*rewards.PerRevive +
+		healingGiven*rewards.PerHealing
+}
+
+// IsShortMatch reports whether a match of the given duration counts toward reward decay.
+func IsShortMatch(decay config.RewardDecayConfig, duration time.Duration) bool {
+	return decay.ShortMatchDuration > 0 && duration < decay.ShortMatchDuration
+}
+
+// RewardDecayMultiplier returns the reward multiplier for a short match when the
+// player already finished recentShortMatches short matches within the decay window.
+// The first FreeMatches short matches earn full rewards; each one after that
+// multiplies rewards by Factor, down to MinMultiplier.
+func RewardDecayMultiplier(decay config.RewardDecayConfig, recentShortMatches int) float64 {
+	excess := recentShortMatches - decay.FreeMatches + 1
+	if decay.ShortMatchDuration <= 0 || excess <= 0 || decay.Factor <= 0 || decay.Factor >= 1 {
+		return 1
+	}
+	return math.Max(math.Pow(decay.Factor, float64(excess)), decay.MinMultiplier)
+}
+
+// ApplyRewardMultiplier scales a reward amount, rounding down.
+func ApplyRewardMultiplier(amount int64, multiplier float64) int64 {
+	if multiplier >= 1 {
+		return amount
+	}
+	return int64(math.Floor(float64(amount) * multiplier))
+}
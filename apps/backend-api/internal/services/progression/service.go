@@ -4,24 +4,200 @@ import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"context"
 	"errors"
+	"time"
 )
 
 var (
-	ErrCosmeticNotFound     = errors.New("cosmetic not found")
-	ErrCosmeticNotOwned     = errors.New("cosmetic not owned")
-	ErrLoadoutNotFound      = errors.New("loadout not found")
-	ErrInsufficientCurrency = errors.New("insufficient data currency")
-	ErrCosmeticAlreadyOwned = errors.New("cosmetic already owned")
+	ErrCosmeticNotFound      = errors.New("cosmetic not found")
+	ErrCosmeticNotOwned      = errors.New("cosmetic not owned")
+	ErrLoadoutNotFound       = errors.New("loadout not found")
+	ErrLoadoutActive         = errors.New("cannot delete the active loadout")
+	ErrInvalidLoadoutName    = errors.New("invalid loadout name")
+	ErrInsufficientCurrency  = errors.New("insufficient data currency")
+	ErrCosmeticAlreadyOwned  = errors.New("cosmetic already owned")
+	ErrCosmeticNotPrestige   = errors.New("cosmetic is not prestige-only")
+	ErrPurchaseLimitReached  = errors.New("daily purchase limit reached")
+	ErrCosmeticExpired       = errors.New("cosmetic is no longer equippable")
+	ErrEquipRestricted       = errors.New("cosmetic can only be equipped when unlocked another way")
+	ErrCosmeticNotForSale    = errors.New("cosmetic cannot be purchased")
+	ErrPurchaseNotFound      = errors.New("purchase not found")
+	ErrPurchaseAlreadyUndone = errors.New("purchase already undone")
+	ErrUndoWindowExpired     = errors.New("purchase undo window has expired")
+	ErrPlayerNotFound        = errors.New("player not found")
+	ErrBundleNotFound        = errors.New("bundle not found")
+	ErrBundleAlreadyOwned    = errors.New("all bundle cosmetics already owned")
+	ErrInvalidCosmeticSlot   = errors.New("invalid cosmetic slot")
+	ErrInvalidRarity         = errors.New("invalid cosmetic rarity")
+	ErrInvalidCosmeticItem   = errors.New("invalid cosmetic item")
+	ErrCosmeticInUse         = errors.New("cosmetic is referenced by loot tables, bundles or players")
 )
 
+// RarityRank orders cosmetic rarities from lowest to highest
+var RarityRank = map[string]int{
+	"common":    1,
+	"uncommon":  2,
+	"rare":      3,
+	"epic":      4,
+	"legendary": 5,
+}
+
+// CosmeticSlots are the values accepted by the cosmetic_items.slot CHECK constraint
+var CosmeticSlots = map[string]bool{
+	"character_skin":  true,
+	"weapon_skin":     true,
+	"emote":           true,
+	"taunt":           true,
+	"badge":           true,
+	"title":           true,
+	"particle_effect": true,
+	"other":           true,
+}
+
+// UnlockMethods are the values accepted by the player_cosmetics.unlocked_via
+// and cosmetic_items.equip_restriction CHECK constraints
+var UnlockMethods = map[string]bool{
+	"level_up":  true,
+	"purchase":  true,
+	"loot_drop": true,
+	"prestige":  true,
+	"referral":  true,
+}
+
+// CosmeticItemInput holds the admin-editable fields of a cosmetic item.
+// Slot must be one of CosmeticSlots and Rarity a key of RarityRank.
+// EquipRestriction, when set, must be one of UnlockMethods: only players who
+// unlocked the item that way can equip it, and it cannot be bought unless the
+// restriction is "purchase".
+type CosmeticItemInput struct {
+	Name             string
+	Description      *string
+	Slot             string
+	Category         *string
+	Rarity           string
+	UnlockLevel      int64
+	DataCost         int64
+	IsPrestigeOnly   bool
+	MaxPerDay        *int64
+	AvailableUntil   *time.Time
+	EquipRestriction *string
+}
+
+// LoadoutSlot is one slot of a player's publicly visible loadout.
+type LoadoutSlot struct {
+	Slot       string
+	CosmeticID int64
+	// IsDefault is true when the slot is empty and shows the configured default cosmetic.
+	IsDefault bool
+}
+
+// MaxLoadoutNameLength is the longest loadout name CreateLoadout accepts, in characters.
+const MaxLoadoutNameLength = 32
+
+// Loadout is one of a player's named loadouts with the cosmetics equipped in
+// it, sorted by slot.
+type Loadout struct {
+	Loadout *db.Loadout
+	Slots   []*LoadoutSlot
+}
+
+// EquippedCosmetic is a loadout slot with the catalog entry of the cosmetic
+// shown in it, as needed by game servers to render a player.
+type EquippedCosmetic struct {
+	Slot      string
+	IsDefault bool
+	Cosmetic  *db.CosmeticItem
+}
+
+// CosmeticBundle is an active store bundle with the cosmetics it contains.
+type CosmeticBundle struct {
+	Bundle    *db.CosmeticBundle
+	Cosmetics []*db.CosmeticItem
+}
+
+// BundlePurchase is the outcome of buying a bundle: what was charged and
+// which of its cosmetics were newly granted.
+type BundlePurchase struct {
+	BundleID           int64
+	Charged            int64
+	GrantedCosmeticIDs []int64
+}
+
+// StoreItem is a cosmetic as shown in the store, with the player's ownership.
+type StoreItem struct {
+	Cosmetic *db.CosmeticItem
+	Owned    bool
+}
+
+// StoreBundle is a bundle as shown in the store. Price is what PurchaseBundle
+// would charge the player right now.
+type StoreBundle struct {
+	Bundle     *db.CosmeticBundle
+	Items      []*StoreItem
+	Price      int64
+	FullyOwned bool
+}
+
+// Store is everything the client store screen needs in one payload. Featured
+// and Bundles are empty, not nil, when none are configured.
+type Store struct {
+	DataCurrency int64
+	Catalog      []*StoreItem
+	Featured     []*StoreItem
+	Bundles      []*StoreBundle
+}
+
+// EconomySnapshot aggregates currency figures used for economy balancing.
+type EconomySnapshot struct {
+	Circulation  *db.GetCurrencyCirculationRow
+	TotalsByType []*db.GetCurrencyTotalsByTypeRow
+	TopSellers   []*db.GetTopCosmeticSellersRow
+}
+
 type Service interface {
 	GetPlayerProgression(ctx context.Context, playerID int64) (*db.PlayerProgression, error)
 	AddExperience(ctx context.Context, playerID int64, xpGain int64) error
+	XPProgress(experience int64) (xpIntoLevel int64, xpForNextLevel int64)
 	PrestigePlayer(ctx context.Context, playerID int64) error
 	AddDataCurrency(ctx context.Context, playerID int64, amount int64, transactionType string, referenceID *int64) error
+	// AddDataCurrencyWithTransaction is AddDataCurrency on the caller's transaction, so the
+	// credit commits or rolls back together with the caller's other writes.
+	AddDataCurrencyWithTransaction(ctx context.Context, dbTx db.DBTX, playerID int64, amount int64, transactionType string, referenceID *int64) error
+	// AdjustDataCurrency applies an admin grant (positive amount) or deduction
+	// (negative amount) and returns the new balance. A deduction larger than the
+	// balance fails with ErrInsufficientCurrency.
+	AdjustDataCurrency(ctx context.Context, playerID int64, amount int64) (int64, error)
 	GetCosmeticCatalog(ctx context.Context) ([]*db.CosmeticItem, error)
 	GetPlayerCosmetics(ctx context.Context, playerID int64) ([]*db.GetPlayerCosmeticsRow, error)
-	EquipCosmetic(ctx context.Context, playerID int64, cosmeticID int64) error
-	AddMatchRewards(ctx context.Context, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned int64) error
+	GetFriendsOwningCosmetic(ctx context.Context, playerID int64, cosmeticID int64) ([]*db.ListFriendsOwningCosmeticRow, error)
+	// EquipCosmetic equips the cosmetic into loadoutID, or into the active
+	// loadout (created as "Default" if needed) when loadoutID is nil.
+	EquipCosmetic(ctx context.Context, playerID int64, cosmeticID int64, loadoutID *int64) error
+	// ListLoadouts returns the player's loadouts, oldest first.
+	ListLoadouts(ctx context.Context, playerID int64) ([]*Loadout, error)
+	// CreateLoadout adds an empty, inactive loadout. The name is trimmed and must
+	// be 1 to MaxLoadoutNameLength characters.
+	CreateLoadout(ctx context.Context, playerID int64, name string) (*db.Loadout, error)
+	// SetActiveLoadout makes loadoutID the player's only active loadout.
+	SetActiveLoadout(ctx context.Context, playerID int64, loadoutID int64) error
+	// DeleteLoadout removes an inactive loadout; the active one fails with ErrLoadoutActive.
+	DeleteLoadout(ctx context.Context, playerID int64, loadoutID int64) error
+	GetPublicLoadout(ctx context.Context, playerID int64) ([]*LoadoutSlot, error)
+	// GetEquippedCosmetics resolves GetPublicLoadout slots to their cosmetic items.
+	GetEquippedCosmetics(ctx context.Context, playerID int64) ([]*EquippedCosmetic, error)
+	ResetLoadout(ctx context.Context, playerID int64) ([]*LoadoutSlot, error)
+	AddMatchRewards(ctx context.Context, playerID int64, kills, deaths, wavesSurvived, scrapEarned, dataEarned, revives, healingGiven int64) error
 	PurchaseCosmetic(ctx context.Context, playerID int64, cosmeticID int64) error
+	UndoPurchase(ctx context.Context, playerID int64, cosmeticID int64) error
+	ListCosmeticBundles(ctx context.Context) ([]*CosmeticBundle, error)
+	PurchaseBundle(ctx context.Context, playerID int64, bundleID int64) (*BundlePurchase, error)
+	GetStore(ctx context.Context, playerID int64) (*Store, error)
+	BackfillPrestigeCosmetic(ctx context.Context, cosmeticID int64) (int64, error)
+	GetEconomySnapshot(ctx context.Context, topSellers int64) (*EconomySnapshot, error)
+	GetCurrencyTransactions(ctx context.Context, playerID int64, limit, offset int64) ([]*db.GetCurrencyTransactionsRow, error)
+	CreateCosmeticItem(ctx context.Context, input CosmeticItemInput) (*db.CosmeticItem, error)
+	GetCosmeticItem(ctx context.Context, cosmeticID int64) (*db.CosmeticItem, error)
+	UpdateCosmeticItem(ctx context.Context, cosmeticID int64, input CosmeticItemInput) (*db.CosmeticItem, error)
+	// DeleteCosmeticItem removes a cosmetic nobody owns and no loot table or
+	// bundle contains; otherwise it fails with ErrCosmeticInUse.
+	DeleteCosmeticItem(ctx context.Context, cosmeticID int64) error
 }
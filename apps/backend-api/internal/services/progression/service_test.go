@@ -53,6 +53,19 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if _, err := db.Exec(createProgressionSQL); err != nil {
 		t.Fatalf("Failed to create player_progression table: %v", err)
 	}
+	createTransactionsSQL := `CREATE TABLE currency_transactions (
+    transaction_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'bundle_purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+    reference_id INTEGER,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);`
+	if _, err := db.Exec(createTransactionsSQL); err != nil {
+		t.Fatalf("Failed to create currency_transactions table: %v", err)
+	}
 	return db
 }
 
@@ -159,7 +172,7 @@ func TestProgressionService_AddMatchRewards(t *testing.T) {
 	wavesSurvived := int64(5)
 	scrapEarned := int64(500)
 	dataEarned := int64(100)
-	err = service.AddMatchRewards(ctx, playerID, kills, deaths, wavesSurvived, scrapEarned, dataEarned)
+	err = service.AddMatchRewards(ctx, playerID, kills, deaths, wavesSurvived, scrapEarned, dataEarned, 0, 0)
 	if err != nil {
 		t.Fatalf("AddMatchRewards failed: %v", err)
 	}
@@ -176,3 +189,84 @@ func TestProgressionService_AddMatchRewards(t *testing.T) {
 		t.Errorf("Expected data currency %d, got %d", dataEarned, progressionData.DataCurrency)
 	}
 }
+
+func TestProgressionService_AddMatchRewardsCustomXPRewards(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	rewards := progression.DefaultXPRewards
+	rewards.PerKill = 25
+	cfg := config.Config{
+		Progression: config.ProgressionConfig{
+			BaseXPPerLevel: 1000,
+			XPRewards:      rewards,
+		},
+	}
+	service := progression.NewProgressionService(cfg, logger, dbConn)
+	ctx := context.Background()
+
+	result, err := dbConn.Exec(`INSERT INTO players (username, email, password_hash) VALUES (?, ?, ?)`,
+		"testuser", "test@example.com", "hash")
+	if err != nil {
+		t.Fatalf("Failed to insert player: %v", err)
+	}
+	playerID, _ := result.LastInsertId()
+
+	// 100 base + 40 kills*25 + 10 waves*50 + 500 scrap = 2100 XP
+	if err := service.AddMatchRewards(ctx, playerID, 40, 0, 10, 500, 0, 0, 0); err != nil {
+		t.Fatalf("AddMatchRewards failed: %v", err)
+	}
+
+	progressionData, err := service.GetPlayerProgression(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get player progression: %v", err)
+	}
+	if progressionData.Experience != 2100 {
+		t.Errorf("Expected XP 2100, got %d", progressionData.Experience)
+	}
+	if progressionData.Level != 3 {
+		t.Errorf("Expected level 3, got %d", progressionData.Level)
+	}
+}
+
+func TestProgressionService_AddMatchRewardsExponentialCurve(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dbConn := setupTestDB(t)
+	defer dbConn.Close()
+
+	// Level costs: 100, 150, 225, 338, 506, 759, 1139, 1709, 2563, 3844, 5767...
+	// so level 11 needs 11333 cumulative XP and level 12 needs 17100
+	cfg := config.Config{
+		Progression: config.ProgressionConfig{
+			BaseXPPerLevel:    100,
+			CurveType:         progression.CurveExponential,
+			CurveGrowthFactor: 1.5,
+		},
+	}
+	service := progression.NewProgressionService(cfg, logger, dbConn)
+	ctx := context.Background()
+
+	result, err := dbConn.Exec(`INSERT INTO players (username, email, password_hash) VALUES (?, ?, ?)`,
+		"testuser", "test@example.com", "hash")
+	if err != nil {
+		t.Fatalf("Failed to insert player: %v", err)
+	}
+	playerID, _ := result.LastInsertId()
+
+	// 100 base + 50 kills*10 + 20 waves*50 + 10000 scrap = 11600 XP
+	if err := service.AddMatchRewards(ctx, playerID, 50, 0, 20, 10000, 0, 0, 0); err != nil {
+		t.Fatalf("AddMatchRewards failed: %v", err)
+	}
+
+	progressionData, err := service.GetPlayerProgression(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get player progression: %v", err)
+	}
+	if progressionData.Experience != 11600 {
+		t.Errorf("Expected XP 11600, got %d", progressionData.Experience)
+	}
+	if progressionData.Level != 11 {
+		t.Errorf("Expected a 10-level jump to level 11, got %d", progressionData.Level)
+	}
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+	"ai-zombie-defense/backend-api/internal/api/validation"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/server"
+	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,22 +18,24 @@ import (
 
 type ServerHandlers struct {
 	service server.Service
+	config  config.Config
 	logger  *zap.Logger
 }
 
-func NewServerHandlers(service server.Service, logger *zap.Logger) *ServerHandlers {
+func NewServerHandlers(service server.Service, cfg config.Config, logger *zap.Logger) *ServerHandlers {
 	return &ServerHandlers{
 		service: service,
+		config:  cfg,
 		logger:  logger,
 	}
 }
 
 type RegisterServerRequest struct {
-	IPAddress   string  `json:"ip_address"`
-	Port        int64   `json:"port"`
-	Name        string  `json:"name"`
+	IPAddress   string  `json:"ip_address" validate:"required"`
+	Port        int64   `json:"port" validate:"required,min=1,max=65535"`
+	Name        string  `json:"name" validate:"required"`
 	MapRotation *string `json:"map_rotation,omitempty"`
-	MaxPlayers  int64   `json:"max_players"`
+	MaxPlayers  int64   `json:"max_players" validate:"required,min=1"`
 	Region      *string `json:"region,omitempty"`
 	Version     *string `json:"version,omitempty"`
 }
@@ -49,41 +56,32 @@ type RegisterServerResponse struct {
 // RegisterServer handles POST /servers/register
 func (h *ServerHandlers) RegisterServer(c *fiber.Ctx) error {
 	var req RegisterServerRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	// Validate required fields
-	if req.IPAddress == "" || req.Port <= 0 || req.Name == "" || req.MaxPlayers <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Missing or invalid required fields (ip_address, port, name, max_players)",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	// Register server via auth service
-	server, authToken, err := h.service.RegisterServer(c.Context(), req.IPAddress, req.Port, req.Name, req.MapRotation, req.MaxPlayers, req.Region, req.Version)
+	srv, authToken, err := h.service.RegisterServer(c.Context(), req.IPAddress, req.Port, req.Name, req.MapRotation, req.MaxPlayers, req.Region, req.Version)
 	if err != nil {
-		h.logger.Error("Failed to register server", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to register server",
-		})
+		if err == server.ErrInvalidIPAddress {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid ip_address")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to register server", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to register server")
 	}
 
 	// Build response
-	createdAt := server.CreatedAt.Time.Format("2006-01-02T15:04:05Z")
+	createdAt := srv.CreatedAt.Time.Format("2006-01-02T15:04:05Z")
 	resp := RegisterServerResponse{
-		ServerID:    server.ServerID,
+		ServerID:    srv.ServerID,
 		AuthToken:   authToken,
-		IPAddress:   server.IpAddress,
-		Port:        server.Port,
-		Name:        server.Name,
-		MapRotation: server.MapRotation,
-		MaxPlayers:  server.MaxPlayers,
-		Region:      server.Region,
-		Version:     server.Version,
+		IPAddress:   srv.IpAddress,
+		Port:        srv.Port,
+		Name:        srv.Name,
+		MapRotation: srv.MapRotation,
+		MaxPlayers:  srv.MaxPlayers,
+		Region:      srv.Region,
+		Version:     srv.Version,
 		CreatedAt:   createdAt,
 	}
 
@@ -92,7 +90,7 @@ func (h *ServerHandlers) RegisterServer(c *fiber.Ctx) error {
 
 // UpdateHeartbeatRequest defines the request body for updating server heartbeat.
 type UpdateHeartbeatRequest struct {
-	CurrentPlayers int64   `json:"current_players"`
+	CurrentPlayers int64   `json:"current_players" validate:"min=0"`
 	Map            *string `json:"map,omitempty"`
 }
 
@@ -100,33 +98,19 @@ type UpdateHeartbeatRequest struct {
 func (h *ServerHandlers) UpdateHeartbeat(c *fiber.Ctx) error {
 	serverID, ok := middleware.GetServerID(c)
 	if !ok {
-		h.logger.Error("server ID not found in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "internal server error",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
 	}
 
 	var req UpdateHeartbeatRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	// Validate current players not negative
-	if req.CurrentPlayers < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "current_players cannot be negative",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
 	}
 
 	err := h.service.UpdateServerHeartbeat(c.Context(), serverID, req.CurrentPlayers, req.Map)
 	if err != nil {
-		h.logger.Error("Failed to update server heartbeat", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update heartbeat",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to update server heartbeat", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update heartbeat")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -134,17 +118,119 @@ func (h *ServerHandlers) UpdateHeartbeat(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateServerRequest defines the request body for updating server metadata.
+// Omitted fields are left unchanged.
+type UpdateServerRequest struct {
+	Name        *string `json:"name,omitempty" validate:"min=1"`
+	MapRotation *string `json:"map_rotation,omitempty"`
+	Region      *string `json:"region,omitempty"`
+	MaxPlayers  *int64  `json:"max_players,omitempty" validate:"min=1"`
+}
+
+type UpdateServerResponse struct {
+	ServerID       int64   `json:"server_id"`
+	IPAddress      string  `json:"ip_address"`
+	Port           int64   `json:"port"`
+	Name           string  `json:"name"`
+	MapRotation    *string `json:"map_rotation,omitempty"`
+	MaxPlayers     int64   `json:"max_players"`
+	CurrentPlayers int64   `json:"current_players"`
+	Region         *string `json:"region,omitempty"`
+	Version        *string `json:"version,omitempty"`
+}
+
+// UpdateServer handles PUT /servers/:id
+func (h *ServerHandlers) UpdateServer(c *fiber.Ctx) error {
+	serverID, ok := middleware.GetServerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	var req UpdateServerRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
+	}
+
+	srv, err := h.service.UpdateServerMetadata(c.Context(), serverID, server.ServerMetadataUpdate{
+		Name:        req.Name,
+		MapRotation: req.MapRotation,
+		Region:      req.Region,
+		MaxPlayers:  req.MaxPlayers,
+	})
+	if err != nil {
+		if errors.Is(err, server.ErrMaxPlayersBelowCurrent) {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "max_players cannot be below current_players")
+		}
+		if errors.Is(err, server.ErrServerNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeServerNotFound, "server not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to update server metadata", zap.Error(err), zap.Int64("server_id", serverID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update server")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(UpdateServerResponse{
+		ServerID:       srv.ServerID,
+		IPAddress:      srv.IpAddress,
+		Port:           srv.Port,
+		Name:           srv.Name,
+		MapRotation:    srv.MapRotation,
+		MaxPlayers:     srv.MaxPlayers,
+		CurrentPlayers: srv.CurrentPlayers,
+		Region:         srv.Region,
+		Version:        srv.Version,
+	})
+}
+
+// RequireServer responds 404 for an unknown :id. It runs before server
+// authentication on DELETE /servers/:id, which would otherwise report any
+// valid token as a mismatch.
+func (h *ServerHandlers) RequireServer(c *fiber.Ctx) error {
+	serverID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "invalid server ID format")
+	}
+	if _, err := h.service.GetServer(c.Context(), serverID); err != nil {
+		if errors.Is(err, server.ErrServerNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeServerNotFound, "server not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to get server", zap.Error(err), zap.Int64("server_id", serverID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to get server")
+	}
+	return c.Next()
+}
+
+// DeregisterServer handles DELETE /servers/:id
+func (h *ServerHandlers) DeregisterServer(c *fiber.Ctx) error {
+	serverID, ok := middleware.GetServerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	if err := h.service.DeregisterServer(c.Context(), serverID); err != nil {
+		if errors.Is(err, server.ErrServerNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeServerNotFound, "server not found")
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to deregister server", zap.Error(err), zap.Int64("server_id", serverID))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to deregister server")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // ListServers handles GET /servers
 func (h *ServerHandlers) ListServers(c *fiber.Ctx) error {
 	// Parse query parameters
 	region := c.Query("region")
 	mapRotation := c.Query("map")
 	version := c.Query("version")
+	name := strings.TrimSpace(c.Query("name"))
 	minPlayersStr := c.Query("min_players")
 	maxPlayersStr := c.Query("max_players")
 
 	// Convert strings to pointers
-	var regionPtr, mapPtr, versionPtr *string
+	var regionPtr, mapPtr, versionPtr, namePtr *string
 	if region != "" {
 		regionPtr = &region
 	}
@@ -154,33 +240,30 @@ func (h *ServerHandlers) ListServers(c *fiber.Ctx) error {
 	if version != "" {
 		versionPtr = &version
 	}
+	if name != "" {
+		namePtr = &name
+	}
 
 	var minPlayersPtr, maxPlayersPtr *int64
 	if minPlayersStr != "" {
 		val, err := strconv.ParseInt(minPlayersStr, 10, 64)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid min_players parameter",
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid min_players parameter")
 		}
 		minPlayersPtr = &val
 	}
 	if maxPlayersStr != "" {
 		val, err := strconv.ParseInt(maxPlayersStr, 10, 64)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid max_players parameter",
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid max_players parameter")
 		}
 		maxPlayersPtr = &val
 	}
 
-	servers, err := h.service.ListActiveServers(c.Context(), regionPtr, mapPtr, versionPtr, minPlayersPtr, maxPlayersPtr)
+	servers, err := h.service.ListActiveServers(c.Context(), regionPtr, mapPtr, versionPtr, namePtr, minPlayersPtr, maxPlayersPtr)
 	if err != nil {
-		h.logger.Error("Failed to list servers", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve servers",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list servers", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve servers")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(servers)
@@ -189,41 +272,38 @@ func (h *ServerHandlers) ListServers(c *fiber.Ctx) error {
 type GenerateJoinTokenResponse struct {
 	Token     string `json:"token"`
 	ExpiresAt string `json:"expires_at"`
-	ServerID  int64  `json:"server_id"`
-	PlayerID  int64  `json:"player_id"`
+	// ExpiresIn is the token lifetime in seconds, for scheduling a refresh.
+	ExpiresIn int64 `json:"expires_in"`
+	ServerID  int64 `json:"server_id"`
+	PlayerID  int64 `json:"player_id"`
 }
 
 // GenerateJoinToken handles POST /servers/:id/join
 func (h *ServerHandlers) GenerateJoinToken(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	serverID, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid server ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid server ID")
 	}
 
-	// Generate token with 30-second expiry
-	token, err := h.service.GenerateJoinToken(c.Context(), playerID, int64(serverID), 30*time.Second)
+	expiresIn := h.config.GameServer.JoinTokenExpiration
+	token, err := h.service.GenerateJoinToken(c.Context(), playerID, int64(serverID), expiresIn)
 	if err != nil {
-		h.logger.Error("Failed to generate join token", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate join token",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to generate join token", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to generate join token")
 	}
 
 	// Get token details (we could fetch from DB, but we know expiry)
-	expiresAt := time.Now().UTC().Add(30 * time.Second).Format("2006-01-02T15:04:05Z")
+	expiresAt := time.Now().UTC().Add(expiresIn).Format("2006-01-02T15:04:05Z")
 	resp := GenerateJoinTokenResponse{
 		Token:     token,
 		ExpiresAt: expiresAt,
+		ExpiresIn: int64(expiresIn / time.Second),
 		ServerID:  int64(serverID),
 		PlayerID:  playerID,
 	}
@@ -236,41 +316,112 @@ type ValidateJoinTokenResponse struct {
 	ExpiresAt string `json:"expires_at"`
 }
 
-// ValidateJoinToken handles POST /servers/join-token/:token/validate
+// ValidateJoinToken handles POST /servers/:id/join-token/:token/validate
 func (h *ServerHandlers) ValidateJoinToken(c *fiber.Ctx) error {
+	authServerID, ok := middleware.GetServerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
 	token := c.Params("token")
 	if token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Missing token",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Missing token")
 	}
 
-	playerID, serverID, err := h.service.ValidateJoinToken(c.Context(), token)
+	// Validation consumes the token, so only one concurrent request can succeed
+	joinToken, err := h.service.ValidateJoinToken(c.Context(), authServerID, token)
 	if err != nil {
+		if errors.Is(err, server.ErrJoinTokenWrongServer) {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeJoinTokenWrongServer, err.Error())
+		}
 		if errors.Is(err, server.ErrJoinTokenInvalid) || errors.Is(err, server.ErrJoinTokenExpired) || errors.Is(err, server.ErrJoinTokenAlreadyUsed) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidJoinToken, err.Error())
 		}
-		h.logger.Error("Failed to validate join token", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to validate join token",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to validate join token", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to validate join token")
 	}
 
-	// Mark token as used (consume)
-	err = h.service.MarkTokenUsed(c.Context(), token)
-	if err != nil {
-		h.logger.Error("Failed to mark token as used", zap.Error(err))
-		// We still return success because token is valid, but log error
+	// Record the join for the server's history; failures don't block the player
+	if err := h.service.RecordServerJoin(c.Context(), joinToken.ServerID, joinToken.PlayerID); err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to record server join", zap.Error(err))
 	}
 
-	// Get token details for expiration (optional)
-	// We could fetch token row, but for simplicity just return IDs
 	resp := ValidateJoinTokenResponse{
-		PlayerID:  playerID,
-		ServerID:  serverID,
-		ExpiresAt: time.Now().UTC().Add(30 * time.Second).Format("2006-01-02T15:04:05Z"), // approximate
+		PlayerID:  joinToken.PlayerID,
+		ServerID:  joinToken.ServerID,
+		ExpiresAt: joinToken.ExpiresAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// MarkTokensUsedBatchRequest defines the request body for consuming several join tokens at once,
+// at most 100 per request.
+type MarkTokensUsedBatchRequest struct {
+	Tokens []string `json:"tokens" validate:"required,max=100"`
+}
+
+// MarkTokensUsedBatch handles POST /servers/:id/join-token/mark-used-batch
+func (h *ServerHandlers) MarkTokensUsedBatch(c *fiber.Ctx) error {
+	serverID, ok := middleware.GetServerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	var req MarkTokensUsedBatchRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return validation.Respond(c, err)
+	}
+
+	marked, err := h.service.MarkTokensUsed(c.Context(), serverID, req.Tokens)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to mark tokens as used", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to mark tokens as used")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"requested": len(req.Tokens),
+		"marked":    marked,
+	})
+}
+
+type ServerJoinResponse struct {
+	PlayerID int64  `json:"player_id"`
+	Username string `json:"username"`
+	JoinedAt string `json:"joined_at"`
+}
+
+// ListServerJoins handles GET /servers/:id/joins
+func (h *ServerHandlers) ListServerJoins(c *fiber.Ctx) error {
+	serverID, ok := middleware.GetServerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("server ID not found in context")
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	}
+
+	// Parse limit query parameter (default 50, max 500)
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	joins, err := h.service.ListServerJoins(c.Context(), serverID, int64(limit))
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list server joins", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve server joins")
+	}
+
+	resp := make([]ServerJoinResponse, len(joins))
+	for i, j := range joins {
+		resp[i] = ServerJoinResponse{
+			PlayerID: j.PlayerID,
+			Username: j.Username,
+			JoinedAt: j.JoinedAt.Time.Format("2006-01-02T15:04:05Z"),
+		}
 	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
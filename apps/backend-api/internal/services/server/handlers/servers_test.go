@@ -2,14 +2,20 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/api/validation"
+	"ai-zombie-defense/backend-api/internal/services/server"
 	"ai-zombie-defense/backend-api/internal/testutils"
 
 	"github.com/gofiber/fiber/v2"
@@ -81,6 +87,111 @@ func TestUpdateHeartbeat(t *testing.T) {
 	}
 }
 
+func TestRegisterServerNormalizesIPAddress(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	register := func(app *fiber.App, ipAddress string) (int, string) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"ip_address":  ipAddress,
+			"port":        27015,
+			"name":        "Test Server",
+			"max_players": 12,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/servers/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make register request: %v", err)
+		}
+		var result struct {
+			IPAddress string `json:"ip_address"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result.IPAddress
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		status   int
+		expected string
+	}{
+		{"ipv4", "192.168.1.10", http.StatusCreated, "192.168.1.10"},
+		{"ipv4 with whitespace", " 10.0.0.1 ", http.StatusCreated, "10.0.0.1"},
+		{"ipv6 canonicalized", "2001:0DB8:0000:0000:0000:0000:0000:0001", http.StatusCreated, "2001:db8::1"},
+		{"bracketed ipv6", "[::1]", http.StatusCreated, "::1"},
+		{"hostname rejected", "localhost", http.StatusBadRequest, ""},
+		{"garbage", "not an ip", http.StatusBadRequest, ""},
+		{"out of range octet", "256.1.1.1", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, ip := register(app, tt.input)
+			if status != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, status)
+			}
+			if ip != tt.expected {
+				t.Errorf("Expected ip_address %q, got %q", tt.expected, ip)
+			}
+		})
+	}
+
+	t.Run("hostname allowed by config", func(t *testing.T) {
+		cfg := testutils.GetTestConfig()
+		cfg.GameServer.AllowHostnames = true
+		hostApp := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+
+		status, ip := register(hostApp, "Game-1.Example.com")
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", status)
+		}
+		if ip != "game-1.example.com" {
+			t.Errorf("Expected lowercased hostname, got %q", ip)
+		}
+		if status, _ := register(hostApp, "bad_host!"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for invalid hostname, got %d", status)
+		}
+	})
+}
+
+func TestRegisterServerValidation(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"ip_address":  "10.0.0.1",
+		"port":        70000,
+		"max_players": -1,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/servers/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to make register request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", resp.StatusCode)
+	}
+	var result struct {
+		Code   string                  `json:"code"`
+		Fields []validation.FieldError `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := []validation.FieldError{
+		{Field: "port", Message: "must be at most 65535"},
+		{Field: "name", Message: "is required"},
+		{Field: "max_players", Message: "must be at least 1"},
+	}
+	if result.Code != "VALIDATION_FAILED" || !reflect.DeepEqual(result.Fields, want) {
+		t.Errorf("Expected VALIDATION_FAILED with %+v, got %s %+v", want, result.Code, result.Fields)
+	}
+}
+
 func TestListServers(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -140,3 +251,620 @@ func TestListServers(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestValidateJoinTokenRecordsServerJoin(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	serverID, authToken := registerTestServer(t, app)
+
+	// Player requests a join token
+	playerID := testutils.CreateTestPlayer(t, db, "joiner", "joiner@example.com", "pass")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+	joinToken := requestJoinToken(t, app, serverID, accessToken)
+
+	// Server validates the token
+	req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join-token/"+joinToken+"/validate", nil)
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to validate join token: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Join shows up in the server's history
+	req = httptest.NewRequest(http.MethodGet, "/servers/"+serverID+"/joins?limit=10", nil)
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to list server joins: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var joins []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&joins); err != nil {
+		t.Fatalf("Failed to decode joins response: %v", err)
+	}
+	if len(joins) != 1 {
+		t.Fatalf("Expected 1 join, got %d", len(joins))
+	}
+	if int64(joins[0]["player_id"].(float64)) != playerID {
+		t.Errorf("Expected player_id %d, got %v", playerID, joins[0]["player_id"])
+	}
+	if joins[0]["username"] != "joiner" {
+		t.Errorf("Expected username joiner, got %v", joins[0]["username"])
+	}
+
+	// Another server's token cannot read this history
+	otherServerID := testutils.CreateTestServerRow(t, db)
+	req = httptest.NewRequest(http.MethodGet, "/servers/"+strconv.FormatInt(otherServerID, 10)+"/joins", nil)
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to list server joins: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", resp.StatusCode)
+	}
+}
+
+func registerTestServer(t *testing.T, app *fiber.App) (string, string) {
+	t.Helper()
+	registerReq := map[string]interface{}{
+		"ip_address":  "127.0.0.1",
+		"port":        27015,
+		"name":        "Test Server",
+		"max_players": 12,
+	}
+	body, _ := json.Marshal(registerReq)
+	req := httptest.NewRequest(http.MethodPost, "/servers/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to register server: %v", err)
+	}
+	var registerResp map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&registerResp); err != nil {
+		t.Fatalf("Failed to decode registration response: %v", err)
+	}
+	return strconv.FormatInt(int64(registerResp["server_id"].(float64)), 10), registerResp["auth_token"].(string)
+}
+
+func requestJoinToken(t *testing.T, app *fiber.App, serverID, accessToken string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to generate join token: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	var joinResp map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&joinResp); err != nil {
+		t.Fatalf("Failed to decode join token response: %v", err)
+	}
+	return joinResp["token"].(string)
+}
+
+func TestMarkTokensUsedBatch(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	serverID, authToken := registerTestServer(t, app)
+	playerID := testutils.CreateTestPlayer(t, db, "batcher", "batcher@example.com", "pass")
+	accessToken := testutils.CreateTestAccessToken(t, db, playerID)
+
+	token1 := requestJoinToken(t, app, serverID, accessToken)
+	token2 := requestJoinToken(t, app, serverID, accessToken)
+	expired := requestJoinToken(t, app, serverID, accessToken)
+	if _, err := db.Exec(`UPDATE join_tokens SET expires_at = '2000-01-01T00:00:00Z' WHERE token = ?`, expired); err != nil {
+		t.Fatalf("Failed to expire token: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"tokens": []string{token1, token2, expired, "unknown-token"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join-token/mark-used-batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to mark tokens used: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result["marked"].(float64) != 2 {
+		t.Errorf("Expected 2 tokens marked, got %v", result["marked"])
+	}
+
+	for token, wantUsed := range map[string]bool{token1: true, token2: true, expired: false} {
+		var usedAt sql.NullString
+		if err := db.QueryRow(`SELECT used_at FROM join_tokens WHERE token = ?`, token).Scan(&usedAt); err != nil {
+			t.Fatalf("Failed to read token: %v", err)
+		}
+		if usedAt.Valid != wantUsed {
+			t.Errorf("Token %s: expected used=%v, got used_at=%v", token[:8], wantUsed, usedAt)
+		}
+	}
+}
+
+func TestGetServerPlayerLoadout(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	_, authToken := registerTestServer(t, app)
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password")
+
+	res, err := db.Exec(`INSERT INTO cosmetic_items (name, slot, category, rarity, unlock_level) VALUES ('Hazmat Suit', 'character_skin', 'armor', 'epic', 1)`)
+	if err != nil {
+		t.Fatalf("Failed to insert cosmetic item: %v", err)
+	}
+	cosmeticID, _ := res.LastInsertId()
+	res, err = db.Exec(`INSERT INTO loadouts (player_id, name, is_active) VALUES (?, 'Main', 1)`, playerID)
+	if err != nil {
+		t.Fatalf("Failed to insert loadout: %v", err)
+	}
+	loadoutID, _ := res.LastInsertId()
+	if _, err := db.Exec(`INSERT INTO loadout_cosmetics (loadout_id, cosmetic_id, slot) VALUES (?, ?, 'character_skin')`, loadoutID, cosmeticID); err != nil {
+		t.Fatalf("Failed to equip cosmetic: %v", err)
+	}
+
+	getLoadout := func(token string, playerID int64) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/servers/players/"+strconv.FormatInt(playerID, 10)+"/loadout", nil)
+		if token != "" {
+			req.Header.Set("X-Server-Token", token)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if resp := getLoadout("", playerID); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without server token, got %d", resp.StatusCode)
+	}
+	if resp := getLoadout("bogus", playerID); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with invalid server token, got %d", resp.StatusCode)
+	}
+	if resp := getLoadout(authToken, playerID+100); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+
+	resp := getLoadout(authToken, playerID)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var result struct {
+		PlayerID int64 `json:"player_id"`
+		Slots    map[string]struct {
+			CosmeticID int64  `json:"cosmetic_id"`
+			Name       string `json:"name"`
+			Rarity     string `json:"rarity"`
+			IsDefault  bool   `json:"is_default"`
+		} `json:"slots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.PlayerID != playerID {
+		t.Errorf("Expected player_id %d, got %d", playerID, result.PlayerID)
+	}
+	skin, ok := result.Slots["character_skin"]
+	if !ok {
+		t.Fatalf("Expected character_skin slot, got %+v", result.Slots)
+	}
+	if skin.CosmeticID != cosmeticID || skin.Name != "Hazmat Suit" || skin.Rarity != "epic" || skin.IsDefault {
+		t.Errorf("Unexpected character_skin slot %+v", skin)
+	}
+}
+
+func TestMarkStaleServersOffline(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+	cfg := testutils.GetTestConfig()
+	serverSvc := server.NewServerService(cfg, zaptest.NewLogger(t), db)
+
+	staleID := testutils.CreateTestServerRow(t, db)
+	freshID := testutils.CreateTestServerRow(t, db)
+	for id, age := range map[int64]time.Duration{staleID: cfg.GameServer.HeartbeatTTL + time.Minute, freshID: 0} {
+		if err := serverSvc.UpdateServerHeartbeat(context.Background(), id, 3, nil); err != nil {
+			t.Fatalf("UpdateServerHeartbeat failed: %v", err)
+		}
+		heartbeat := time.Now().UTC().Add(-age).Format("2006-01-02T15:04:05Z")
+		if _, err := db.Exec(`UPDATE servers SET last_heartbeat = ? WHERE server_id = ?`, heartbeat, id); err != nil {
+			t.Fatalf("Failed to set heartbeat: %v", err)
+		}
+	}
+
+	marked, err := serverSvc.MarkStaleServersOffline(context.Background())
+	if err != nil {
+		t.Fatalf("MarkStaleServersOffline failed: %v", err)
+	}
+	if marked != 1 {
+		t.Errorf("Expected 1 server marked offline, got %d", marked)
+	}
+
+	listServerIDs := func() []int64 {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/servers", nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to list servers: %v", err)
+		}
+		var servers []struct {
+			ServerID int64 `json:"server_id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&servers); err != nil {
+			t.Fatalf("Failed to decode servers: %v", err)
+		}
+		ids := make([]int64, 0, len(servers))
+		for _, s := range servers {
+			ids = append(ids, s.ServerID)
+		}
+		return ids
+	}
+	if ids := listServerIDs(); len(ids) != 1 || ids[0] != freshID {
+		t.Errorf("Expected only the fresh server %d to be listed, got %v", freshID, ids)
+	}
+
+	// A heartbeat brings the stale server back online
+	if err := serverSvc.UpdateServerHeartbeat(context.Background(), staleID, 1, nil); err != nil {
+		t.Fatalf("UpdateServerHeartbeat failed: %v", err)
+	}
+	if ids := listServerIDs(); len(ids) != 2 {
+		t.Errorf("Expected both servers after the heartbeat, got %v", ids)
+	}
+}
+
+func deregisterTestServer(t *testing.T, app *fiber.App, serverID, authToken string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodDelete, "/servers/"+serverID, nil)
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to deregister server: %v", err)
+	}
+	return resp
+}
+
+func TestDeregisterServer(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+	countRows := func(query, serverID string) int {
+		t.Helper()
+		var count int
+		if err := db.QueryRow(query, serverID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count rows: %v", err)
+		}
+		return count
+	}
+
+	t.Run("deletes the server", func(t *testing.T) {
+		serverID, authToken := registerTestServer(t, app)
+		playerID := testutils.CreateTestPlayer(t, db, "fan", "fan@example.com", "password123")
+		requestJoinToken(t, app, serverID, testutils.CreateTestAccessToken(t, db, playerID))
+		if _, err := db.Exec(`INSERT INTO server_favorites (player_id, server_id) VALUES (?, ?)`, playerID, serverID); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+
+		resp := deregisterTestServer(t, app, serverID, authToken)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", resp.StatusCode)
+		}
+		for _, query := range []string{
+			`SELECT COUNT(*) FROM servers WHERE server_id = ?`,
+			`SELECT COUNT(*) FROM server_favorites WHERE server_id = ?`,
+			`SELECT COUNT(*) FROM join_tokens WHERE server_id = ?`,
+		} {
+			if count := countRows(query, serverID); count != 0 {
+				t.Errorf("Expected no rows for %q, got %d", query, count)
+			}
+		}
+
+		resp = deregisterTestServer(t, app, serverID, authToken)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404 once deregistered, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("keeps a server with matches", func(t *testing.T) {
+		serverID, authToken := registerTestServer(t, app)
+		if _, err := db.Exec(`INSERT INTO matches (server_id, map_name, game_mode, start_time, outcome) VALUES (?, 'Map1', 'survival', '2026-01-01T00:00:00Z', 'completed')`, serverID); err != nil {
+			t.Fatalf("Failed to insert match: %v", err)
+		}
+
+		resp := deregisterTestServer(t, app, serverID, authToken)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", resp.StatusCode)
+		}
+		if count := countRows(`SELECT COUNT(*) FROM matches WHERE server_id = ?`, serverID); count != 1 {
+			t.Errorf("Expected the match to be kept, got %d", count)
+		}
+		if count := countRows(`SELECT COUNT(*) FROM servers WHERE server_id = ? AND auth_token IS NULL AND is_online = 0`, serverID); count != 1 {
+			t.Errorf("Expected the server to be kept offline without a token")
+		}
+		resp = deregisterTestServer(t, app, serverID, authToken)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected the revoked token to be rejected, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("token for another server", func(t *testing.T) {
+		serverID, _ := registerTestServer(t, app)
+		_, otherToken := registerTestServer(t, app)
+		resp := deregisterTestServer(t, app, serverID, otherToken)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", resp.StatusCode)
+		}
+		if count := countRows(`SELECT COUNT(*) FROM servers WHERE server_id = ?`, serverID); count != 1 {
+			t.Errorf("Expected the server to be kept, got %d", count)
+		}
+	})
+
+	t.Run("unknown server", func(t *testing.T) {
+		_, authToken := registerTestServer(t, app)
+		resp := deregisterTestServer(t, app, "9999", authToken)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestUpdateServerMetadata(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+	serverID, authToken := registerTestServer(t, app)
+
+	update := func(token string, payload map[string]interface{}) *http.Response {
+		t.Helper()
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPut, "/servers/"+serverID, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Server-Token", token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to update server: %v", err)
+		}
+		return resp
+	}
+
+	resp := update(authToken, map[string]interface{}{"name": "Zombie Land", "map_rotation": "map2"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var updated struct {
+		Name        string `json:"name"`
+		MapRotation string `json:"map_rotation"`
+		MaxPlayers  int64  `json:"max_players"`
+	}
+	json.NewDecoder(resp.Body).Decode(&updated)
+	if updated.Name != "Zombie Land" || updated.MapRotation != "map2" || updated.MaxPlayers != 12 {
+		t.Errorf("Expected name and map to change and max_players to stay 12, got %+v", updated)
+	}
+
+	var storedToken string
+	if err := db.QueryRow(`SELECT auth_token FROM servers WHERE server_id = ?`, serverID).Scan(&storedToken); err != nil {
+		t.Fatalf("Failed to read auth token: %v", err)
+	}
+	if storedToken != authToken {
+		t.Errorf("Expected the auth token to be unchanged")
+	}
+
+	t.Run("max_players below current_players", func(t *testing.T) {
+		if _, err := db.Exec(`UPDATE servers SET current_players = 8 WHERE server_id = ?`, serverID); err != nil {
+			t.Fatalf("Failed to set current players: %v", err)
+		}
+		resp := update(authToken, map[string]interface{}{"max_players": 6})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+		resp = update(authToken, map[string]interface{}{"max_players": 8})
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200 for max_players equal to current_players, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("token for another server", func(t *testing.T) {
+		_, otherToken := registerTestServer(t, app)
+		resp := update(otherToken, map[string]interface{}{"name": "Hijacked"})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestGenerateJoinTokenConfiguredExpiry(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	cfg := testutils.GetTestConfig()
+	cfg.GameServer.JoinTokenExpiration = 5 * time.Minute
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+	serverID, _ := registerTestServer(t, app)
+	playerID := testutils.CreateTestPlayer(t, db, "joiner", "joiner@example.com", "password123")
+
+	before := time.Now().UTC().Truncate(time.Second)
+	req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join", nil)
+	req.Header.Set("Authorization", "Bearer "+testutils.CreateTestAccessToken(t, db, playerID))
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to generate join token: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Token     string `json:"token"`
+		ExpiresIn int64  `json:"expires_in"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.ExpiresIn != 300 {
+		t.Errorf("Expected expires_in 300, got %d", body.ExpiresIn)
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT expires_at FROM join_tokens WHERE token = ?`, body.Token).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read join token: %v", err)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, stored)
+	if err != nil {
+		t.Fatalf("Failed to parse expires_at %q: %v", stored, err)
+	}
+	if expiresAt.Before(before.Add(5*time.Minute)) || expiresAt.After(time.Now().Add(5*time.Minute)) {
+		t.Errorf("Expected expires_at about 5 minutes from now, got %s", expiresAt)
+	}
+}
+
+func TestValidateJoinTokenConcurrentSingleUse(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+	serverID, authToken := registerTestServer(t, app)
+	playerID := testutils.CreateTestPlayer(t, db, "joiner", "joiner@example.com", "password123")
+	joinToken := requestJoinToken(t, app, serverID, testutils.CreateTestAccessToken(t, db, playerID))
+
+	// Fire both validations at once; only one may consume the token
+	start := make(chan struct{})
+	statuses := make([]int, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join-token/"+joinToken+"/validate", nil)
+			req.Header.Set("X-Server-Token", authToken)
+			<-start
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	ok, rejected := 0, 0
+	for i, status := range statuses {
+		if errs[i] != nil {
+			t.Fatalf("Failed to validate join token: %v", errs[i])
+		}
+		switch status {
+		case http.StatusOK:
+			ok++
+		case http.StatusBadRequest:
+			rejected++
+		}
+	}
+	if ok != 1 || rejected != 1 {
+		t.Fatalf("Expected exactly one success and one rejection, got statuses %v", statuses)
+	}
+
+	// A later attempt reports the token as already used
+	req := httptest.NewRequest(http.MethodPost, "/servers/"+serverID+"/join-token/"+joinToken+"/validate", nil)
+	req.Header.Set("X-Server-Token", authToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Failed to validate join token: %v", err)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusBadRequest || body["error"] != server.ErrJoinTokenAlreadyUsed.Error() || body["code"] != "INVALID_JOIN_TOKEN" {
+		t.Errorf("Expected 400 %q, got %d %v", server.ErrJoinTokenAlreadyUsed, resp.StatusCode, body)
+	}
+
+	var joins int
+	db.QueryRow(`SELECT COUNT(*) FROM server_joins`).Scan(&joins)
+	if joins != 1 {
+		t.Errorf("Expected 1 recorded join, got %d", joins)
+	}
+}
+
+func TestListServersNameSearch(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createTestServerWithAllRoutes(t, db)
+
+	servers := []struct {
+		name     string
+		region   string
+		isOnline int
+	}{
+		{"Zombie Outbreak EU", "eu-west", 1},
+		{"The ZOMBIE Pit", "us-east", 1},
+		{"zombieland", "eu-west", 1},
+		{"Survivors Only", "eu-west", 1},
+		{"Offline Zombie Den", "eu-west", 0},
+		{"100% Undead_Fun", "us-east", 1},
+	}
+	for i, srv := range servers {
+		if _, err := db.Exec(`INSERT INTO servers (ip_address, port, name, max_players, region, is_online) VALUES ('127.0.0.1', ?, ?, 12, ?, ?)`,
+			27015+i, srv.name, srv.region, srv.isOnline); err != nil {
+			t.Fatalf("Failed to insert server: %v", err)
+		}
+	}
+
+	listNames := func(query string) []string {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/servers"+query, nil), -1)
+		if err != nil {
+			t.Fatalf("Failed to list servers: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var list []struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(resp.Body).Decode(&list)
+		names := make([]string, len(list))
+		for i, srv := range list {
+			names[i] = srv.Name
+		}
+		return names
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"case-insensitive substring", "?name=zombie", []string{"Zombie Outbreak EU", "The ZOMBIE Pit", "zombieland"}},
+		{"composes with region", "?name=zombie&region=eu-west", []string{"Zombie Outbreak EU", "zombieland"}},
+		{"empty name is ignored", "?name=", []string{"Zombie Outbreak EU", "The ZOMBIE Pit", "zombieland", "Survivors Only", "100% Undead_Fun"}},
+		{"wildcards match literally", "?name=%25", []string{"100% Undead_Fun"}},
+		{"underscore matches literally", "?name=d_f", []string{"100% Undead_Fun"}},
+		{"no match", "?name=vampire", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listNames(tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
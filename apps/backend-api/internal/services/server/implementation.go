@@ -4,12 +4,15 @@ import (
 	"ai-zombie-defense/backend-api/internal/db"
 	"ai-zombie-defense/backend-api/internal/db/types"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	cryptorand "crypto/rand"
 	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -38,6 +41,11 @@ func (s *serverService) RegisterServer(ctx context.Context, ipAddress string, po
 	}
 	authToken := hex.EncodeToString(tokenBytes)
 
+	ipAddress, err := s.normalizeAddress(ipAddress)
+	if err != nil {
+		return nil, "", err
+	}
+
 	params := &db.CreateServerParams{
 		IpAddress:   ipAddress,
 		Port:        port,
@@ -57,6 +65,50 @@ func (s *serverService) RegisterServer(ctx context.Context, ipAddress string, po
 	return server, authToken, nil
 }
 
+// normalizeAddress parses a server address and returns its canonical form.
+// IPv6 addresses may be bracketed and are compressed (e.g. "[2001:DB8:0::1]" -> "2001:db8::1").
+// Hostnames are only accepted when GameServer.AllowHostnames is set.
+func (s *serverService) normalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	unbracketed := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	if ip := net.ParseIP(unbracketed); ip != nil {
+		return ip.String(), nil
+	}
+	if s.config.GameServer.AllowHostnames && isValidHostname(address) {
+		return strings.ToLower(address), nil
+	}
+	return "", ErrInvalidIPAddress
+}
+
+// isValidHostname reports whether name is a syntactically valid RFC 1123 hostname.
+func isValidHostname(name string) bool {
+	if len(name) == 0 || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *serverService) GetServer(ctx context.Context, serverID int64) (*db.Server, error) {
+	server, err := s.queries.GetServer(ctx, s.dbConn, serverID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrServerNotFound
+		}
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+	return server, nil
+}
+
 func (s *serverService) GetServerByAuthToken(ctx context.Context, authToken string) (*db.Server, error) {
 	server, err := s.queries.GetServerByAuthToken(ctx, s.dbConn, &authToken)
 	if err != nil {
@@ -68,6 +120,92 @@ func (s *serverService) GetServerByAuthToken(ctx context.Context, authToken stri
 	return server, nil
 }
 
+func (s *serverService) UpdateServerMetadata(ctx context.Context, serverID int64, update ServerMetadataUpdate) (*db.Server, error) {
+	var server *db.Server
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		current, err := s.queries.GetServer(ctx, tx, serverID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrServerNotFound
+			}
+			return fmt.Errorf("failed to get server: %w", err)
+		}
+
+		params := &db.UpdateServerMetadataParams{
+			Name:        current.Name,
+			MapRotation: current.MapRotation,
+			Region:      current.Region,
+			MaxPlayers:  current.MaxPlayers,
+			ServerID:    serverID,
+		}
+		if update.Name != nil {
+			params.Name = *update.Name
+		}
+		if update.MapRotation != nil {
+			params.MapRotation = update.MapRotation
+		}
+		if update.Region != nil {
+			params.Region = update.Region
+		}
+		if update.MaxPlayers != nil {
+			if *update.MaxPlayers < current.CurrentPlayers {
+				return ErrMaxPlayersBelowCurrent
+			}
+			params.MaxPlayers = *update.MaxPlayers
+		}
+
+		server, err = s.queries.UpdateServerMetadata(ctx, tx, params)
+		if err != nil {
+			return fmt.Errorf("failed to update server metadata: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+func (s *serverService) DeregisterServer(ctx context.Context, serverID int64) error {
+	var matches int64
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if _, err := s.queries.GetServer(ctx, tx, serverID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrServerNotFound
+			}
+			return fmt.Errorf("failed to get server: %w", err)
+		}
+		var err error
+		matches, err = s.queries.CountServerMatches(ctx, tx, serverID)
+		if err != nil {
+			return fmt.Errorf("failed to count server matches: %w", err)
+		}
+
+		if matches == 0 {
+			// Favorites, join tokens and join history cascade with the row
+			if err := s.queries.DeleteServer(ctx, tx, serverID); err != nil {
+				return fmt.Errorf("failed to delete server: %w", err)
+			}
+		} else {
+			if err := s.queries.DeleteServerFavorites(ctx, tx, serverID); err != nil {
+				return fmt.Errorf("failed to delete server favorites: %w", err)
+			}
+			if err := s.queries.DeleteServerJoinTokens(ctx, tx, serverID); err != nil {
+				return fmt.Errorf("failed to delete server join tokens: %w", err)
+			}
+			if err := s.queries.RetireServer(ctx, tx, serverID); err != nil {
+				return fmt.Errorf("failed to retire server: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx, s.logger).Info("Server deregistered", zap.Int64("server_id", serverID), zap.Bool("retained", matches > 0))
+	return nil
+}
+
 func (s *serverService) UpdateServerHeartbeat(ctx context.Context, serverID int64, currentPlayers int64, mapRotation *string) error {
 	now := time.Now().UTC().Format("2006-01-02T15:04:05Z")
 	params := &db.UpdateServerHeartbeatParams{
@@ -83,12 +221,31 @@ func (s *serverService) UpdateServerHeartbeat(ctx context.Context, serverID int6
 	return nil
 }
 
-func (s *serverService) ListActiveServers(ctx context.Context, region, mapRotation, version *string, minPlayers, maxPlayers *int64) ([]*db.Server, error) {
+func (s *serverService) MarkStaleServersOffline(ctx context.Context) (int64, error) {
+	cutoff := time.Now().UTC().Add(-s.config.GameServer.HeartbeatTTL).Format("2006-01-02T15:04:05Z")
+	marked, err := s.queries.MarkStaleServersOffline(ctx, s.dbConn, &cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark stale servers offline: %w", err)
+	}
+	if marked > 0 {
+		logging.FromContext(ctx, s.logger).Info("Marked stale servers offline", zap.Int64("count", marked))
+	}
+	return marked, nil
+}
+
+// likeEscaper escapes LIKE wildcards so a name search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *serverService) ListActiveServers(ctx context.Context, region, mapRotation, version, name *string, minPlayers, maxPlayers *int64) ([]*db.Server, error) {
 	params := &db.ListActiveServersParams{
 		Region:      region,
 		MapRotation: mapRotation,
 		Version:     version,
 	}
+	if name != nil {
+		escaped := likeEscaper.Replace(*name)
+		params.Name = &escaped
+	}
 	if minPlayers != nil {
 		params.CurrentPlayers = *minPlayers
 	} else {
@@ -126,69 +283,80 @@ func (s *serverService) GenerateJoinToken(ctx context.Context, playerID int64, s
 		return "", fmt.Errorf("failed to create join token: %w", err)
 	}
 
-	s.logger.Debug("Join token generated",
+	logging.FromContext(ctx, s.logger).Debug("Join token generated",
 		zap.Int64("player_id", playerID),
 		zap.Int64("server_id", serverID),
 		zap.Time("expires_at", expiresAt))
 	return token, nil
 }
 
-func (s *serverService) ValidateJoinToken(ctx context.Context, token string) (playerID int64, serverID int64, err error) {
-	joinToken, err := s.queries.GetValidJoinToken(ctx, s.dbConn, token)
+func (s *serverService) ValidateJoinToken(ctx context.Context, serverID int64, token string) (*db.JoinToken, error) {
+	joinToken, err := s.queries.ConsumeJoinToken(ctx, s.dbConn, &db.ConsumeJoinTokenParams{
+		Token:    token,
+		ServerID: serverID,
+	})
+	if err == nil {
+		return joinToken, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to consume join token: %w", err)
+	}
+
+	// Nothing was consumed; look the token up only to report why
+	tokenRow, err := s.queries.GetJoinToken(ctx, s.dbConn, token)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			tokenRow, err2 := s.queries.GetJoinToken(ctx, s.dbConn, token)
-			if err2 != nil {
-				if errors.Is(err2, sql.ErrNoRows) {
-					return 0, 0, ErrJoinTokenInvalid
-				}
-				return 0, 0, fmt.Errorf("failed to get join token: %w", err2)
-			}
-			expiresAt := tokenRow.ExpiresAt.Time
-			if expiresAt.Before(time.Now().UTC()) {
-				return 0, 0, ErrJoinTokenExpired
-			}
-			if tokenRow.UsedAt.Valid {
-				return 0, 0, ErrJoinTokenAlreadyUsed
-			}
-			return 0, 0, ErrJoinTokenInvalid
+			return nil, ErrJoinTokenInvalid
 		}
-		return 0, 0, fmt.Errorf("failed to validate join token: %w", err)
+		return nil, fmt.Errorf("failed to get join token: %w", err)
 	}
-
-	return joinToken.PlayerID, joinToken.ServerID, nil
+	switch {
+	case tokenRow.ServerID != serverID:
+		return nil, ErrJoinTokenWrongServer
+	case tokenRow.UsedAt.Valid:
+		return nil, ErrJoinTokenAlreadyUsed
+	case !tokenRow.ExpiresAt.Time.After(time.Now().UTC()):
+		return nil, ErrJoinTokenExpired
+	}
+	return nil, ErrJoinTokenInvalid
 }
 
-func (s *serverService) MarkTokenUsed(ctx context.Context, token string) error {
-	err := s.queries.MarkTokenUsed(ctx, s.dbConn, token)
+// MarkTokensUsed consumes a batch of join tokens belonging to the server in a single
+// UPDATE. Tokens that are unknown, expired, already used, or issued for another
+// server are skipped; the number of tokens actually marked is returned.
+func (s *serverService) MarkTokensUsed(ctx context.Context, serverID int64, tokens []string) (int64, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+	marked, err := s.queries.MarkTokensUsed(ctx, s.dbConn, &db.MarkTokensUsedParams{
+		ServerID: serverID,
+		Tokens:   tokens,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to mark token as used: %w", err)
+		return 0, fmt.Errorf("failed to mark tokens as used: %w", err)
 	}
-	s.logger.Debug("Join token marked as used", zap.String("token", token))
-	return nil
+	logging.FromContext(ctx, s.logger).Debug("Join tokens marked as used",
+		zap.Int64("server_id", serverID),
+		zap.Int("requested", len(tokens)),
+		zap.Int64("marked", marked))
+	return marked, nil
 }
 
 func (s *serverService) AddFavorite(ctx context.Context, playerID int64, serverID int64, note *string) error {
-	existing, err := s.queries.GetFavorite(ctx, s.dbConn, &db.GetFavoriteParams{
-		PlayerID: playerID,
-		ServerID: serverID,
-	})
-	if err == nil && existing != nil {
-		return ErrFavoriteAlreadyExists
-	}
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to check existing favorite: %w", err)
-	}
-	params := &db.AddFavoriteParams{
+	// The insert is a no-op on an existing favorite, so concurrent adds can't
+	// both pass a separate existence check and collide on the primary key
+	added, err := s.queries.AddFavorite(ctx, s.dbConn, &db.AddFavoriteParams{
 		PlayerID: playerID,
 		ServerID: serverID,
 		Note:     note,
-	}
-	err = s.queries.AddFavorite(ctx, s.dbConn, params)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to add favorite: %w", err)
 	}
-	s.logger.Debug("Favorite added", zap.Int64("player_id", playerID), zap.Int64("server_id", serverID))
+	if added == 0 {
+		return ErrFavoriteAlreadyExists
+	}
+	logging.FromContext(ctx, s.logger).Debug("Favorite added", zap.Int64("player_id", playerID), zap.Int64("server_id", serverID))
 	return nil
 }
 
@@ -201,7 +369,22 @@ func (s *serverService) RemoveFavorite(ctx context.Context, playerID int64, serv
 	if err != nil {
 		return fmt.Errorf("failed to remove favorite: %w", err)
 	}
-	s.logger.Debug("Favorite removed", zap.Int64("player_id", playerID), zap.Int64("server_id", serverID))
+	logging.FromContext(ctx, s.logger).Debug("Favorite removed", zap.Int64("player_id", playerID), zap.Int64("server_id", serverID))
+	return nil
+}
+
+func (s *serverService) UpdateFavoriteNote(ctx context.Context, playerID int64, serverID int64, note *string) error {
+	updated, err := s.queries.UpdateFavoriteNote(ctx, s.dbConn, &db.UpdateFavoriteNoteParams{
+		Note:     note,
+		PlayerID: playerID,
+		ServerID: serverID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update favorite note: %w", err)
+	}
+	if updated == 0 {
+		return ErrFavoriteNotFound
+	}
 	return nil
 }
 
@@ -212,3 +395,47 @@ func (s *serverService) ListPlayerFavorites(ctx context.Context, playerID int64)
 	}
 	return favorites, nil
 }
+
+// RecordServerJoin stores a join record for the server and prunes history
+// older than the configured retention or beyond the per-server cap.
+func (s *serverService) RecordServerJoin(ctx context.Context, serverID int64, playerID int64) error {
+	err := s.queries.CreateServerJoin(ctx, s.dbConn, &db.CreateServerJoinParams{
+		ServerID: serverID,
+		PlayerID: playerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record server join: %w", err)
+	}
+
+	if retention := s.config.GameServer.JoinHistoryRetention; retention > 0 {
+		cutoff := types.Timestamp{Time: time.Now().UTC().Add(-retention)}
+		if err := s.queries.DeleteServerJoinsBefore(ctx, s.dbConn, cutoff); err != nil {
+			return fmt.Errorf("failed to prune expired server joins: %w", err)
+		}
+	}
+
+	if maxJoins := s.config.GameServer.JoinHistoryMaxPerServer; maxJoins > 0 {
+		err := s.queries.TrimServerJoins(ctx, s.dbConn, &db.TrimServerJoinsParams{
+			ServerID:   serverID,
+			ServerID_2: serverID,
+			Limit:      int64(maxJoins),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to trim server joins: %w", err)
+		}
+	}
+
+	logging.FromContext(ctx, s.logger).Debug("Server join recorded", zap.Int64("server_id", serverID), zap.Int64("player_id", playerID))
+	return nil
+}
+
+func (s *serverService) ListServerJoins(ctx context.Context, serverID int64, limit int64) ([]*db.ListServerJoinsRow, error) {
+	joins, err := s.queries.ListServerJoins(ctx, s.dbConn, &db.ListServerJoinsParams{
+		ServerID: serverID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server joins: %w", err)
+	}
+	return joins, nil
+}
@@ -8,23 +8,55 @@ import (
 )
 
 var (
-	ErrServerNotFound        = errors.New("server not found")
-	ErrJoinTokenInvalid      = errors.New("join token invalid")
-	ErrJoinTokenExpired      = errors.New("join token expired")
-	ErrJoinTokenAlreadyUsed  = errors.New("join token already used")
-	ErrFavoriteAlreadyExists = errors.New("server already favorited")
-	ErrFavoriteNotFound      = errors.New("favorite not found")
+	ErrServerNotFound         = errors.New("server not found")
+	ErrJoinTokenInvalid       = errors.New("join token invalid")
+	ErrJoinTokenExpired       = errors.New("join token expired")
+	ErrJoinTokenAlreadyUsed   = errors.New("join token already used")
+	ErrJoinTokenWrongServer   = errors.New("join token does not belong to this server")
+	ErrFavoriteAlreadyExists  = errors.New("server already favorited")
+	ErrFavoriteNotFound       = errors.New("favorite not found")
+	ErrInvalidIPAddress       = errors.New("invalid ip address")
+	ErrMaxPlayersBelowCurrent = errors.New("max players below current players")
 )
 
+// ServerMetadataUpdate holds the operator-editable server fields; nil fields
+// are left unchanged.
+type ServerMetadataUpdate struct {
+	Name        *string
+	MapRotation *string
+	Region      *string
+	MaxPlayers  *int64
+}
+
 type Service interface {
 	RegisterServer(ctx context.Context, ipAddress string, port int64, name string, mapRotation *string, maxPlayers int64, region *string, version *string) (*db.Server, string, error)
+	GetServer(ctx context.Context, serverID int64) (*db.Server, error)
 	GetServerByAuthToken(ctx context.Context, authToken string) (*db.Server, error)
+	// UpdateServerMetadata applies a partial update and returns the updated server. It returns
+	// ErrMaxPlayersBelowCurrent when MaxPlayers is below the server's current player count.
+	UpdateServerMetadata(ctx context.Context, serverID int64, update ServerMetadataUpdate) (*db.Server, error)
+	// DeregisterServer removes a server along with its favorites and join tokens.
+	// A server with recorded matches keeps its row, offline and with its auth token
+	// revoked, since deleting it would cascade to the players' match stats.
+	DeregisterServer(ctx context.Context, serverID int64) error
 	UpdateServerHeartbeat(ctx context.Context, serverID int64, currentPlayers int64, mapRotation *string) error
-	ListActiveServers(ctx context.Context, region, mapRotation, version *string, minPlayers, maxPlayers *int64) ([]*db.Server, error)
+	// MarkStaleServersOffline marks online servers whose last heartbeat is older than
+	// GameServer.HeartbeatTTL as offline and returns how many were marked.
+	MarkStaleServersOffline(ctx context.Context) (int64, error)
+	// ListActiveServers returns online servers matching every non-nil filter. name matches
+	// case-insensitively anywhere in the server name.
+	ListActiveServers(ctx context.Context, region, mapRotation, version, name *string, minPlayers, maxPlayers *int64) ([]*db.Server, error)
 	GenerateJoinToken(ctx context.Context, playerID int64, serverID int64, expiresIn time.Duration) (string, error)
-	ValidateJoinToken(ctx context.Context, token string) (playerID int64, serverID int64, err error)
-	MarkTokenUsed(ctx context.Context, token string) error
+	// ValidateJoinToken validates token for serverID and consumes it in the same statement,
+	// so a token admits exactly one join even under concurrent validations.
+	ValidateJoinToken(ctx context.Context, serverID int64, token string) (*db.JoinToken, error)
+	MarkTokensUsed(ctx context.Context, serverID int64, tokens []string) (int64, error)
 	AddFavorite(ctx context.Context, playerID int64, serverID int64, note *string) error
 	RemoveFavorite(ctx context.Context, playerID int64, serverID int64) error
+	// UpdateFavoriteNote replaces the note on a favorite; a nil note clears it.
+	// Returns ErrFavoriteNotFound if the player hasn't favorited the server.
+	UpdateFavoriteNote(ctx context.Context, playerID int64, serverID int64, note *string) error
 	ListPlayerFavorites(ctx context.Context, playerID int64) ([]*db.ListPlayerFavoritesRow, error)
+	RecordServerJoin(ctx context.Context, serverID int64, playerID int64) error
+	ListServerJoins(ctx context.Context, serverID int64, limit int64) ([]*db.ListServerJoinsRow, error)
 }
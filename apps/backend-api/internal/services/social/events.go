@@ -0,0 +1,80 @@
+package social
+
+import (
+	"sync"
+	"time"
+)
+
+// Friend event types pushed to GET /friends/events subscribers
+const (
+	EventFriendRequest  = "friend_request"
+	EventFriendAccepted = "friend_accepted"
+)
+
+// Event is friend activity delivered to one player's subscribers. PlayerID and
+// Username identify the other player: the requester or the one who accepted.
+type Event struct {
+	Type      string    `json:"type"`
+	PlayerID  int64     `json:"player_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// eventBufferSize bounds each subscriber's queue. Events for a subscriber that
+// isn't keeping up are dropped rather than blocking the publisher.
+const eventBufferSize = 16
+
+// EventHub is an in-process pub/sub of friend events keyed by player ID. A player
+// may hold several subscriptions at once, e.g. one per open client.
+type EventHub struct {
+	mu   sync.RWMutex
+	subs map[int64]map[chan Event]struct{}
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[int64]map[chan Event]struct{})}
+}
+
+// Subscribe registers a subscription for playerID. The returned cancel func
+// removes it and closes the channel; it is safe to call more than once.
+func (h *EventHub) Subscribe(playerID int64) (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+	h.mu.Lock()
+	if h.subs[playerID] == nil {
+		h.subs[playerID] = make(map[chan Event]struct{})
+	}
+	h.subs[playerID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[playerID], ch)
+			if len(h.subs[playerID]) == 0 {
+				delete(h.subs, playerID)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers event to every subscription of playerID without blocking.
+func (h *EventHub) Publish(playerID int64, event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[playerID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions playerID currently holds.
+func (h *EventHub) Subscribers(playerID int64) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs[playerID])
+}
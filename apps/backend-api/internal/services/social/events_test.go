@@ -0,0 +1,63 @@
+package social
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventHub(t *testing.T) {
+	hub := NewEventHub()
+	phone, cancelPhone := hub.Subscribe(1)
+	desktop, cancelDesktop := hub.Subscribe(1)
+	other, cancelOther := hub.Subscribe(2)
+	defer cancelOther()
+
+	// Every subscription of the recipient gets the event, nobody else does
+	hub.Publish(1, Event{Type: EventFriendRequest, PlayerID: 3})
+	for name, ch := range map[string]<-chan Event{"phone": phone, "desktop": desktop} {
+		select {
+		case event := <-ch:
+			if event.Type != EventFriendRequest || event.PlayerID != 3 {
+				t.Errorf("%s: unexpected event %+v", name, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: expected an event", name)
+		}
+	}
+	select {
+	case event := <-other:
+		t.Errorf("Expected no event for player 2, got %+v", event)
+	default:
+	}
+
+	// Cancelling closes the channel and forgets the subscription; repeats are harmless
+	cancelPhone()
+	cancelPhone()
+	if _, ok := <-phone; ok {
+		t.Error("Expected cancelled subscription to be closed")
+	}
+	if n := hub.Subscribers(1); n != 1 {
+		t.Errorf("Expected 1 subscriber left, got %d", n)
+	}
+	cancelDesktop()
+	if n := hub.Subscribers(1); n != 0 {
+		t.Errorf("Expected no subscribers left, got %d", n)
+	}
+
+	// A subscriber that never reads doesn't block publishers
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < eventBufferSize*2; i++ {
+			hub.Publish(2, Event{Type: EventFriendAccepted})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+	if got := len(other); got != eventBufferSize {
+		t.Errorf("Expected %d buffered events, got %d", eventBufferSize, got)
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/server"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
@@ -26,6 +28,10 @@ type AddFavoriteRequest struct {
 	Note     *string `json:"note,omitempty"`
 }
 
+type UpdateFavoriteRequest struct {
+	Note *string `json:"note"`
+}
+
 type FavoriteResponse struct {
 	ServerID int64       `json:"server_id"`
 	AddedAt  string      `json:"added_at"`
@@ -42,6 +48,7 @@ type ServerInfo struct {
 	MaxPlayers     int64   `json:"max_players"`
 	CurrentPlayers int64   `json:"current_players"`
 	IsOnline       bool    `json:"is_online"`
+	IsFull         bool    `json:"is_full"`
 	LastHeartbeat  *string `json:"last_heartbeat,omitempty"`
 	Region         *string `json:"region,omitempty"`
 	Version        *string `json:"version,omitempty"`
@@ -52,37 +59,27 @@ type ServerInfo struct {
 func (h *FavoriteHandlers) AddFavorite(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	var req AddFavoriteRequest
 	if err := c.BodyParser(&req); err != nil {
-		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
 	}
 
 	if req.ServerID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid server ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid server ID")
 	}
 
 	err := h.service.AddFavorite(c.Context(), playerID, req.ServerID, req.Note)
 	if err != nil {
 		if errors.Is(err, server.ErrFavoriteAlreadyExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeFavoriteAlreadyExists, err.Error())
 		}
-		h.logger.Error("Failed to add favorite", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add favorite",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to add favorite", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to add favorite")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -94,25 +91,19 @@ func (h *FavoriteHandlers) AddFavorite(c *fiber.Ctx) error {
 func (h *FavoriteHandlers) RemoveFavorite(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	serverID, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid server ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid server ID")
 	}
 
 	err = h.service.RemoveFavorite(c.Context(), playerID, int64(serverID))
 	if err != nil {
-		h.logger.Error("Failed to remove favorite", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to remove favorite",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to remove favorite", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to remove favorite")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -120,22 +111,51 @@ func (h *FavoriteHandlers) RemoveFavorite(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateFavorite handles PUT /favorites/:id
+func (h *FavoriteHandlers) UpdateFavorite(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	serverID, err := c.ParamsInt("id")
+	if err != nil {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid server ID")
+	}
+
+	var req UpdateFavoriteRequest
+	if err := c.BodyParser(&req); err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
+	}
+
+	err = h.service.UpdateFavoriteNote(c.Context(), playerID, int64(serverID), req.Note)
+	if err != nil {
+		if errors.Is(err, server.ErrFavoriteNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeFavoriteNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to update favorite", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update favorite")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "updated",
+	})
+}
+
 // ListFavorites handles GET /favorites
 func (h *FavoriteHandlers) ListFavorites(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	favorites, err := h.service.ListPlayerFavorites(c.Context(), playerID)
 	if err != nil {
-		h.logger.Error("Failed to list favorites", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve favorites",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list favorites", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve favorites")
 	}
 
 	// Transform to response format
@@ -150,6 +170,7 @@ func (h *FavoriteHandlers) ListFavorites(c *fiber.Ctx) error {
 			MaxPlayers:     fav.MaxPlayers,
 			CurrentPlayers: fav.CurrentPlayers,
 			IsOnline:       fav.IsOnline == 1,
+			IsFull:         fav.CurrentPlayers >= fav.MaxPlayers,
 			LastHeartbeat:  fav.LastHeartbeat,
 			Region:         fav.Region,
 			Version:        fav.Version,
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strconv"
+	"sync"
 	"testing"
 
 	"ai-zombie-defense/backend-api/internal/testutils"
 
+	"github.com/gofiber/fiber/v2"
 	_ "modernc.org/sqlite"
 )
 
@@ -60,6 +63,56 @@ func TestFavoriteHandlers_AddFavorite(t *testing.T) {
 	}
 }
 
+// postConcurrently fires n identical POSTs at once and returns their status
+// codes in ascending order.
+func postConcurrently(t *testing.T, app *fiber.App, path, token string, payload interface{}, n int) []int {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	start := make(chan struct{})
+	statuses := make([]int, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			<-start
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+func TestFavoriteHandlers_AddFavoriteConcurrent(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password123")
+	serverID := testutils.CreateTestServerRow(t, db)
+	app := createFullTestServer(t, db)
+	token := testutils.CreateTestAccessToken(t, db, playerID)
+
+	statuses := postConcurrently(t, app, "/favorites", token, map[string]interface{}{"server_id": serverID}, 2)
+	if statuses[0] != http.StatusCreated || statuses[1] != http.StatusConflict {
+		t.Errorf("Expected one 201 and one 409, got %v", statuses)
+	}
+}
+
 func TestFavoriteHandlers_RemoveFavorite(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -85,3 +138,189 @@ func TestFavoriteHandlers_RemoveFavorite(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestFavoriteHandlers_UpdateFavorite(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password123")
+	serverID := testutils.CreateTestServerRow(t, db)
+	app := createFullTestServer(t, db)
+	token := testutils.CreateTestAccessToken(t, db, playerID)
+
+	if _, err := db.Exec("INSERT INTO server_favorites (player_id, server_id, note) VALUES (?, ?, 'old note')", playerID, serverID); err != nil {
+		t.Fatalf("Failed to add favorite: %v", err)
+	}
+
+	updateNote := func(serverID int64, payload map[string]interface{}) *http.Response {
+		t.Helper()
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPut, "/favorites/"+strconv.FormatInt(serverID, 10), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+	storedNote := func() *string {
+		t.Helper()
+		var note *string
+		if err := db.QueryRow("SELECT note FROM server_favorites WHERE player_id = ? AND server_id = ?", playerID, serverID).Scan(&note); err != nil {
+			t.Fatalf("Failed to read favorite: %v", err)
+		}
+		return note
+	}
+
+	t.Run("existing favorite", func(t *testing.T) {
+		resp := updateNote(serverID, map[string]interface{}{"note": "new note"})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if note := storedNote(); note == nil || *note != "new note" {
+			t.Errorf("Expected note %q, got %v", "new note", note)
+		}
+	})
+
+	t.Run("null note clears it", func(t *testing.T) {
+		resp := updateNote(serverID, map[string]interface{}{"note": nil})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if note := storedNote(); note != nil {
+			t.Errorf("Expected note to be cleared, got %q", *note)
+		}
+	})
+
+	t.Run("nonexistent favorite", func(t *testing.T) {
+		otherServerID := testutils.CreateTestServerRow(t, db)
+		resp := updateNote(otherServerID, map[string]interface{}{"note": "nope"})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM server_favorites WHERE server_id = ?", otherServerID).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected no favorite to be created, got %d", count)
+		}
+	})
+}
+
+func TestFavoriteHandlers_ListFavoritesNewestFirst(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token := testutils.CreateTestAccessToken(t, db, playerID)
+
+	var serverIDs []int64
+	for _, addedAt := range []string{"2026-01-01T00:00:00Z", "2026-03-01T00:00:00Z", "2026-02-01T00:00:00Z"} {
+		serverID := testutils.CreateTestServerRow(t, db)
+		if _, err := db.Exec("INSERT INTO server_favorites (player_id, server_id, added_at) VALUES (?, ?, ?)", playerID, serverID, addedAt); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+		serverIDs = append(serverIDs, serverID)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/favorites", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var favorites []struct {
+		ServerID int64 `json:"server_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&favorites); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := []int64{serverIDs[1], serverIDs[2], serverIDs[0]}
+	if len(favorites) != len(want) {
+		t.Fatalf("Expected %d favorites, got %d", len(want), len(favorites))
+	}
+	for i, id := range want {
+		if favorites[i].ServerID != id {
+			t.Errorf("Expected favorite %d to be server %d, got %d", i, id, favorites[i].ServerID)
+		}
+	}
+}
+
+func TestFavoriteHandlers_ListFavoritesServerStatus(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	playerID := testutils.CreateTestPlayer(t, db, "testuser", "test@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token := testutils.CreateTestAccessToken(t, db, playerID)
+
+	onlineID := testutils.CreateTestServerRow(t, db)
+	offlineID := testutils.CreateTestServerRow(t, db)
+	fullID := testutils.CreateTestServerRow(t, db)
+	deletedID := testutils.CreateTestServerRow(t, db)
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{"UPDATE servers SET is_online = 1, current_players = 4, max_players = 10 WHERE server_id = ?", []interface{}{onlineID}},
+		{"UPDATE servers SET is_online = 0, current_players = 0, max_players = 10 WHERE server_id = ?", []interface{}{offlineID}},
+		{"UPDATE servers SET is_online = 1, current_players = 10, max_players = 10 WHERE server_id = ?", []interface{}{fullID}},
+	} {
+		if _, err := db.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("Failed to update server: %v", err)
+		}
+	}
+	for _, serverID := range []int64{onlineID, offlineID, fullID, deletedID} {
+		if _, err := db.Exec("INSERT INTO server_favorites (player_id, server_id) VALUES (?, ?)", playerID, serverID); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+	}
+	// Deleting a server cascades to its favorites
+	if _, err := db.Exec("DELETE FROM servers WHERE server_id = ?", deletedID); err != nil {
+		t.Fatalf("Failed to delete server: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/favorites", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var favorites []struct {
+		ServerID int64 `json:"server_id"`
+		Server   struct {
+			IsOnline       bool  `json:"is_online"`
+			IsFull         bool  `json:"is_full"`
+			CurrentPlayers int64 `json:"current_players"`
+			MaxPlayers     int64 `json:"max_players"`
+		} `json:"server"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&favorites); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(favorites) != 3 {
+		t.Fatalf("Expected 3 favorites after the server deletion, got %d", len(favorites))
+	}
+
+	type status struct {
+		online, full     bool
+		current, maximum int64
+	}
+	want := map[int64]status{
+		onlineID:  {true, false, 4, 10},
+		offlineID: {false, false, 0, 10},
+		fullID:    {true, true, 10, 10},
+	}
+	for _, fav := range favorites {
+		expected, ok := want[fav.ServerID]
+		if !ok {
+			t.Errorf("Unexpected favorite for server %d", fav.ServerID)
+			continue
+		}
+		got := status{fav.Server.IsOnline, fav.Server.IsFull, fav.Server.CurrentPlayers, fav.Server.MaxPlayers}
+		if got != expected {
+			t.Errorf("Server %d: expected %+v, got %+v", fav.ServerID, expected, got)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package handlers
 
 import (
+	"ai-zombie-defense/backend-api/internal/api/apierror"
 	"ai-zombie-defense/backend-api/internal/middleware"
 	"ai-zombie-defense/backend-api/internal/services/social"
+	"ai-zombie-defense/backend-api/internal/websocket"
+	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -11,12 +20,14 @@ import (
 
 type FriendHandlers struct {
 	service social.Service
+	config  config.Config
 	logger  *zap.Logger
 }
 
-func NewFriendHandlers(service social.Service, logger *zap.Logger) *FriendHandlers {
+func NewFriendHandlers(service social.Service, cfg config.Config, logger *zap.Logger) *FriendHandlers {
 	return &FriendHandlers{
 		service: service,
+		config:  cfg,
 		logger:  logger,
 	}
 }
@@ -25,6 +36,18 @@ type SendFriendRequestRequest struct {
 	FriendID int64 `json:"friend_id"`
 }
 
+type BulkFriendRequestRequest struct {
+	FriendIDs []int64 `json:"friend_ids"`
+}
+
+type BulkFriendRequestResult struct {
+	FriendID int64  `json:"friend_id"`
+	Status   string `json:"status"`
+}
+
+// maxBulkFriendRequests caps the number of targets in a single bulk request
+const maxBulkFriendRequests = 50
+
 type UpdateFriendRequestRequest struct {
 	Action string `json:"action"` // "accept" or "decline"
 }
@@ -37,46 +60,48 @@ type FriendResponse struct {
 	UpdatedAt      string `json:"updated_at"`
 }
 
+// PendingFriendRequestResponse describes a pending request from the caller's
+// side: PlayerID and Username are always the other player.
+type PendingFriendRequestResponse struct {
+	PlayerID  int64  `json:"player_id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+}
+
 // SendFriendRequest handles POST /friends/request
 func (h *FriendHandlers) SendFriendRequest(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	var req SendFriendRequestRequest
 	if err := c.BodyParser(&req); err != nil {
-		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
 	}
 
 	if req.FriendID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid friend ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid friend ID")
 	}
 
 	err := h.service.SendFriendRequest(c.Context(), playerID, req.FriendID)
 	if err != nil {
 		if errors.Is(err, social.ErrCannotFriendSelf) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeCannotFriendSelf, err.Error())
 		}
 		if errors.Is(err, social.ErrFriendRequestAlreadyExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeFriendRequestAlreadyExists, err.Error())
 		}
-		h.logger.Error("Failed to send friend request", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to send friend request",
-		})
+		if errors.Is(err, social.ErrFriendRequestsDisabled) || errors.Is(err, social.ErrBlocked) {
+			return apierror.Respond(c, fiber.StatusForbidden, apierror.CodeFriendRequestNotAllowed, err.Error())
+		}
+		if errors.Is(err, social.ErrPlayerNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to send friend request", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to send friend request")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -84,29 +109,58 @@ func (h *FriendHandlers) SendFriendRequest(c *fiber.Ctx) error {
 	})
 }
 
+// SendBulkFriendRequests handles POST /friends/request/bulk
+func (h *FriendHandlers) SendBulkFriendRequests(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	var req BulkFriendRequestRequest
+	if err := c.BodyParser(&req); err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
+	}
+
+	if len(req.FriendIDs) == 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "friend_ids must not be empty")
+	}
+	if len(req.FriendIDs) > maxBulkFriendRequests {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, fmt.Sprintf("friend_ids must contain at most %d entries", maxBulkFriendRequests))
+	}
+
+	results := h.service.SendFriendRequests(c.Context(), playerID, req.FriendIDs)
+	response := make([]BulkFriendRequestResult, 0, len(results))
+	for _, r := range results {
+		response = append(response, BulkFriendRequestResult{
+			FriendID: r.FriendID,
+			Status:   r.Status,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"results": response,
+	})
+}
+
 // UpdateFriendRequest handles PUT /friends/:id
 func (h *FriendHandlers) UpdateFriendRequest(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	requesterID, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid player ID",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid player ID")
 	}
 
 	var req UpdateFriendRequestRequest
 	if err := c.BodyParser(&req); err != nil {
-		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to parse request body", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeInvalidRequestBody, "Invalid request body")
 	}
 
 	switch req.Action {
@@ -115,26 +169,18 @@ func (h *FriendHandlers) UpdateFriendRequest(c *fiber.Ctx) error {
 	case "decline":
 		err = h.service.DeclineFriendRequest(c.Context(), int64(requesterID), playerID)
 	default:
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid action, must be 'accept' or 'decline'",
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid action, must be 'accept' or 'decline'")
 	}
 
 	if err != nil {
 		if errors.Is(err, social.ErrFriendRequestNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeFriendRequestNotFound, err.Error())
 		}
 		if errors.Is(err, social.ErrFriendRequestNotPending) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return apierror.Respond(c, fiber.StatusConflict, apierror.CodeFriendRequestNotPending, err.Error())
 		}
-		h.logger.Error("Failed to update friend request", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update friend request",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to update friend request", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update friend request")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -142,22 +188,102 @@ func (h *FriendHandlers) UpdateFriendRequest(c *fiber.Ctx) error {
 	})
 }
 
+// RemoveFriend handles DELETE /friends/:id
+func (h *FriendHandlers) RemoveFriend(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	friendID, err := c.ParamsInt("id")
+	if err != nil || friendID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid player ID")
+	}
+
+	err = h.service.RemoveFriend(c.Context(), playerID, int64(friendID))
+	if err != nil {
+		if errors.Is(err, social.ErrFriendRequestNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeFriendRequestNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to remove friend", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to remove friend")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "removed",
+	})
+}
+
+// BlockPlayer handles POST /friends/:id/block
+func (h *FriendHandlers) BlockPlayer(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	blockedID, err := c.ParamsInt("id")
+	if err != nil || blockedID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid player ID")
+	}
+
+	err = h.service.BlockPlayer(c.Context(), playerID, int64(blockedID))
+	if err != nil {
+		if errors.Is(err, social.ErrCannotBlockSelf) {
+			return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeCannotBlockSelf, err.Error())
+		}
+		if errors.Is(err, social.ErrPlayerNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodePlayerNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to block player", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to block player")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "blocked",
+	})
+}
+
+// UnblockPlayer handles DELETE /friends/:id/block
+func (h *FriendHandlers) UnblockPlayer(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	blockedID, err := c.ParamsInt("id")
+	if err != nil || blockedID <= 0 {
+		return apierror.Respond(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid player ID")
+	}
+
+	err = h.service.UnblockPlayer(c.Context(), playerID, int64(blockedID))
+	if err != nil {
+		if errors.Is(err, social.ErrBlockNotFound) {
+			return apierror.Respond(c, fiber.StatusNotFound, apierror.CodeBlockNotFound, err.Error())
+		}
+		logging.FromContext(c.Context(), h.logger).Error("Failed to unblock player", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to unblock player")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "unblocked",
+	})
+}
+
 // ListFriends handles GET /friends
 func (h *FriendHandlers) ListFriends(c *fiber.Ctx) error {
 	playerID, ok := middleware.GetPlayerID(c)
 	if !ok {
-		h.logger.Error("player ID not found in context")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 	}
 
 	friends, err := h.service.ListFriends(c.Context(), playerID)
 	if err != nil {
-		h.logger.Error("Failed to list friends", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve friends",
-		})
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list friends", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve friends")
 	}
 
 	response := make([]FriendResponse, 0, len(friends))
@@ -173,3 +299,159 @@ func (h *FriendHandlers) ListFriends(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(response)
 }
+
+// ListIncomingRequests handles GET /friends/requests/incoming
+func (h *FriendHandlers) ListIncomingRequests(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	requests, err := h.service.ListPendingIncoming(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list incoming friend requests", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve friend requests")
+	}
+
+	response := make([]PendingFriendRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		response = append(response, PendingFriendRequestResponse{
+			PlayerID:  r.RequesterPlayerID,
+			Username:  r.RequesterUsername,
+			CreatedAt: r.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// ListOutgoingRequests handles GET /friends/requests/outgoing
+func (h *FriendHandlers) ListOutgoingRequests(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	requests, err := h.service.ListPendingOutgoing(c.Context(), playerID)
+	if err != nil {
+		logging.FromContext(c.Context(), h.logger).Error("Failed to list outgoing friend requests", zap.Error(err))
+		return apierror.Respond(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve friend requests")
+	}
+
+	response := make([]PendingFriendRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		response = append(response, PendingFriendRequestResponse{
+			PlayerID:  r.TargetPlayerID,
+			Username:  r.TargetUsername,
+			CreatedAt: r.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// FriendEvents handles GET /friends/events, a Server-Sent Events stream of
+// incoming friend requests and accepted requests for the caller.
+func (h *FriendHandlers) FriendEvents(c *fiber.Ctx) error {
+	playerID, ok := middleware.GetPlayerID(c)
+	if !ok {
+		logging.FromContext(c.Context(), h.logger).Error("player ID not found in context")
+		return apierror.Respond(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+	}
+
+	events, unsubscribe := h.service.SubscribeEvents(playerID)
+	heartbeat := h.config.Social.EventsHeartbeatInterval
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	// The stream outlives the handler, so resolve the request logger up front
+	logger := logging.FromContext(c.Context(), h.logger)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		var tick <-chan time.Time
+		if heartbeat > 0 {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		// Flush the headers right away so the client knows the stream is open
+		if _, err := w.WriteString(": connected\n\n"); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					logger.Error("Failed to encode friend event", zap.Error(err))
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-tick:
+				w.WriteString(": heartbeat\n\n")
+			}
+			// A failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// PresenceSocket handles GET /ws/presence. The connection keeps the caller online
+// for their friends and receives friend_online/friend_offline events as JSON
+// text messages, starting with the friends already online.
+func (h *FriendHandlers) PresenceSocket(conn *websocket.Conn) {
+	playerID, ok := conn.Locals(middleware.PlayerIDKey).(int64)
+	if !ok {
+		h.logger.Error("player ID not found in context")
+		return
+	}
+
+	session, err := h.service.ConnectPresence(context.Background(), playerID)
+	if err != nil {
+		h.logger.Error("Failed to connect presence", zap.Error(err), zap.Int64("player_id", playerID))
+		return
+	}
+	defer session.Close()
+
+	// Clients only need to hold the socket open; reading answers pings and notices the disconnect
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range session.OnlineFriends {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case event, ok := <-session.Events:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -1,15 +1,24 @@
 package handlers_test
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/services/social"
 	"ai-zombie-defense/backend-api/internal/testutils"
 
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap/zaptest"
 	_ "modernc.org/sqlite"
 )
 
@@ -39,6 +48,20 @@ func TestFriendHandlers_SendFriendRequest(t *testing.T) {
 	}
 }
 
+func TestFriendHandlers_SendFriendRequestConcurrent(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+
+	statuses := postConcurrently(t, app, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID}, 2)
+	if statuses[0] != http.StatusCreated || statuses[1] != http.StatusConflict {
+		t.Errorf("Expected one 201 and one 409, got %v", statuses)
+	}
+}
+
 func TestFriendHandlers_AcceptFriendRequest(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	defer db.Close()
@@ -80,14 +103,524 @@ func TestFriendHandlers_AcceptFriendRequest(t *testing.T) {
 		t.Errorf("Expected status 200 for accept, got %d", resp.StatusCode)
 	}
 
-	// Verify they are friends via GET /friends (player2 perspective)
-	req = httptest.NewRequest(http.MethodGet, "/friends", nil)
-	req.Header.Set("Authorization", "Bearer "+token2)
-	resp, err = app.Test(req)
+	// Both sides see each other via GET /friends, whoever sent the request
+	for _, view := range []struct {
+		token    string
+		friendID int64
+		username string
+	}{
+		{token2, player1ID, "player1"},
+		{token1, player2ID, "player2"},
+	} {
+		req = httptest.NewRequest(http.MethodGet, "/friends", nil)
+		req.Header.Set("Authorization", "Bearer "+view.token)
+		resp, err = app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200 for list friends, got %d", resp.StatusCode)
+		}
+		var friends []struct {
+			FriendPlayerID int64  `json:"friend_player_id"`
+			FriendUsername string `json:"friend_username"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&friends); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(friends) != 1 || friends[0].FriendPlayerID != view.friendID || friends[0].FriendUsername != view.username {
+			t.Errorf("Expected only friend %s (%d), got %+v", view.username, view.friendID, friends)
+		}
+	}
+}
+
+func TestFriendHandlers_ListFriendsMutualRequests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	player3ID := testutils.CreateTestPlayer(t, db, "player3", "player3@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+
+	// player1 and player2 requested each other and both accepted; player3 is still pending
+	for _, row := range [][2]int64{{player1ID, player2ID}, {player2ID, player1ID}} {
+		if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, 'accepted')`, row[0], row[1]); err != nil {
+			t.Fatalf("Failed to insert friendship: %v", err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, 'pending')`, player3ID, player1ID); err != nil {
+		t.Fatalf("Failed to insert friend request: %v", err)
+	}
+
+	resp := sendJSON(t, app, http.MethodGet, "/friends", token1, nil)
+	var friends []struct {
+		FriendPlayerID int64 `json:"friend_player_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&friends); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(friends) != 1 || friends[0].FriendPlayerID != player2ID {
+		t.Errorf("Expected player2 listed once, got %+v", friends)
+	}
+}
+
+func TestFriendHandlers_SendFriendRequestDisabled(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+
+	if _, err := db.Exec(`INSERT INTO player_settings (player_id, allow_friend_requests) VALUES (?, 0)`, player2ID); err != nil {
+		t.Fatalf("Failed to disable friend requests: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"friend_id": player2ID})
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token1)
+	resp, err := app.Test(req)
 	if err != nil {
 		t.Fatalf("Failed to make request: %v", err)
 	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", resp.StatusCode)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM friends WHERE player_id = ? AND friend_id = ?`, player1ID, player2ID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count friend requests: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no friend request to be stored, got %d", count)
+	}
+}
+
+func TestFriendHandlers_SendBulkFriendRequests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	senderID := testutils.CreateTestPlayer(t, db, "sender", "sender@example.com", "password123")
+	openID := testutils.CreateTestPlayer(t, db, "open", "open@example.com", "password123")
+	pendingID := testutils.CreateTestPlayer(t, db, "pending", "pending@example.com", "password123")
+	closedID := testutils.CreateTestPlayer(t, db, "closed", "closed@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token := testutils.CreateTestAccessToken(t, db, senderID)
+
+	if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, 'pending')`, senderID, pendingID); err != nil {
+		t.Fatalf("Failed to seed existing request: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO player_settings (player_id, allow_friend_requests) VALUES (?, 0)`, closedID); err != nil {
+		t.Fatalf("Failed to disable friend requests: %v", err)
+	}
+
+	missingID := closedID + 100
+	body, _ := json.Marshal(map[string]interface{}{
+		"friend_ids": []int64{openID, pendingID, closedID, senderID, missingID},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/friends/request/bulk", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Results []struct {
+			FriendID int64  `json:"friend_id"`
+			Status   string `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	expected := map[int64]string{
+		openID:    "sent",
+		pendingID: "already_friends",
+		closedID:  "blocked",
+		senderID:  "self",
+		missingID: "not_found",
+	}
+	if len(result.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(result.Results))
+	}
+	for _, r := range result.Results {
+		if r.Status != expected[r.FriendID] {
+			t.Errorf("Friend %d: expected status %q, got %q", r.FriendID, expected[r.FriendID], r.Status)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM friends WHERE player_id = ?`, senderID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count friend requests: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 stored requests (seeded + new), got %d", count)
+	}
+}
+
+// sendJSON makes an authenticated request with an optional JSON body.
+func sendJSON(t *testing.T, app *fiber.App, method, path, token string, payload interface{}) *http.Response {
+	t.Helper()
+	var body io.Reader
+	if payload != nil {
+		b, _ := json.Marshal(payload)
+		body = bytes.NewReader(b)
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request %s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+func TestFriendHandlers_BlockPlayer(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+	blockPath := "/friends/" + strconv.FormatInt(player2ID, 10) + "/block"
+
+	countRows := func() int {
+		t.Helper()
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM friends WHERE status != 'blocked'`).Scan(&count); err != nil {
+			t.Fatalf("Failed to count friend rows: %v", err)
+		}
+		return count
+	}
+
+	// player2 has a pending request to player1 when player1 blocks them
+	resp := sendJSON(t, app, http.MethodPost, "/friends/request", token2, map[string]interface{}{"friend_id": player1ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodPost, blockPath, token1, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for block, got %d", resp.StatusCode)
+	}
+	if count := countRows(); count != 0 {
+		t.Errorf("Expected the pending request to be removed, got %d rows", count)
+	}
+
+	// Requests are rejected in both directions
+	resp = sendJSON(t, app, http.MethodPost, "/friends/request", token2, map[string]interface{}{"friend_id": player1ID})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for blocked player's request, got %d", resp.StatusCode)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body["error"] != social.ErrBlocked.Error() || body["code"] != "FRIEND_REQUEST_NOT_ALLOWED" {
+		t.Errorf("Expected error %q with code FRIEND_REQUEST_NOT_ALLOWED, got %v", social.ErrBlocked, body)
+	}
+	resp = sendJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for blocker's request, got %d", resp.StatusCode)
+	}
+	if count := countRows(); count != 0 {
+		t.Errorf("Expected no friend request to be stored, got %d", count)
+	}
+
+	// Blocking again is a no-op; self and unknown players are rejected
+	resp = sendJSON(t, app, http.MethodPost, blockPath, token1, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for repeated block, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodPost, "/friends/"+strconv.FormatInt(player1ID, 10)+"/block", token1, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for blocking self, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodPost, "/friends/9999/block", token1, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown player, got %d", resp.StatusCode)
+	}
+
+	// Only the blocker can lift the block
+	resp = sendJSON(t, app, http.MethodDelete, "/friends/"+strconv.FormatInt(player1ID, 10)+"/block", token2, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unblocking without a block, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodDelete, blockPath, token1, nil)
 	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200 for list friends, got %d", resp.StatusCode)
+		t.Fatalf("Expected status 200 for unblock, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodPost, "/friends/request", token2, map[string]interface{}{"friend_id": player1ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201 after unblock, got %d", resp.StatusCode)
+	}
+}
+
+func TestFriendHandlers_BlockRemovesFriendship(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+
+	if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, 'accepted')`, player1ID, player2ID); err != nil {
+		t.Fatalf("Failed to insert friendship: %v", err)
+	}
+
+	// The friend who didn't send the original request blocks
+	resp := sendJSON(t, app, http.MethodPost, "/friends/"+strconv.FormatInt(player1ID, 10)+"/block", token2, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for block, got %d", resp.StatusCode)
+	}
+
+	resp = sendJSON(t, app, http.MethodGet, "/friends", token2, nil)
+	var friends []map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&friends)
+	if len(friends) != 0 {
+		t.Errorf("Expected no friends after block, got %v", friends)
+	}
+	var status string
+	if err := db.QueryRow(`SELECT status FROM friends WHERE player_id = ? AND friend_id = ?`, player2ID, player1ID).Scan(&status); err != nil {
+		t.Fatalf("Failed to read block: %v", err)
+	}
+	if status != "blocked" {
+		t.Errorf("Expected a blocked row, got %q", status)
+	}
+}
+
+func TestFriendHandlers_ListPendingRequests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+
+	resp := sendJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	type pending struct {
+		PlayerID  int64  `json:"player_id"`
+		Username  string `json:"username"`
+		CreatedAt string `json:"created_at"`
+	}
+	list := func(path, token string) []pending {
+		t.Helper()
+		resp := sendJSON(t, app, http.MethodGet, path, token, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d", path, resp.StatusCode)
+		}
+		var requests []pending
+		if err := json.NewDecoder(resp.Body).Decode(&requests); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return requests
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		playerID int64
+		username string
+	}{
+		{"outgoing for requester", "/friends/requests/outgoing", token1, player2ID, "player2"},
+		{"incoming for target", "/friends/requests/incoming", token2, player1ID, "player1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := list(tt.path, tt.token)
+			if len(requests) != 1 || requests[0].PlayerID != tt.playerID || requests[0].Username != tt.username || requests[0].CreatedAt == "" {
+				t.Errorf("Expected one request with %s (%d), got %+v", tt.username, tt.playerID, requests)
+			}
+		})
+	}
+
+	// The other direction of each list stays empty
+	if requests := list("/friends/requests/incoming", token1); len(requests) != 0 {
+		t.Errorf("Expected no incoming requests for player1, got %+v", requests)
+	}
+	if requests := list("/friends/requests/outgoing", token2); len(requests) != 0 {
+		t.Errorf("Expected no outgoing requests for player2, got %+v", requests)
+	}
+
+	// Endpoints require authentication
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/friends/requests/incoming", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestFriendHandlers_RemoveFriend(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+
+	// Become friends through the API
+	resp := sendJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodPut, "/friends/"+strconv.FormatInt(player1ID, 10), token2, map[string]interface{}{"action": "accept"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for accept, got %d", resp.StatusCode)
+	}
+
+	// The player who accepted unfriends the requester
+	resp = sendJSON(t, app, http.MethodDelete, "/friends/"+strconv.FormatInt(player1ID, 10), token2, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for unfriend, got %d", resp.StatusCode)
+	}
+
+	for _, token := range []string{token1, token2} {
+		resp = sendJSON(t, app, http.MethodGet, "/friends", token, nil)
+		var friends []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&friends); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(friends) != 0 {
+			t.Errorf("Expected no friends after unfriend, got %v", friends)
+		}
+	}
+
+	// Nothing left to remove
+	resp = sendJSON(t, app, http.MethodDelete, "/friends/"+strconv.FormatInt(player2ID, 10), token1, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a friendship, got %d", resp.StatusCode)
+	}
+}
+
+func TestFriendHandlers_RemoveFriendKeepsPendingRequests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	app := createFullTestServer(t, db)
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+
+	resp := sendJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	resp = sendJSON(t, app, http.MethodDelete, "/friends/"+strconv.FormatInt(player2ID, 10), token1, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a pending request, got %d", resp.StatusCode)
+	}
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM friends WHERE status = 'pending'`).Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected the pending request to be kept, got %d", count)
+	}
+}
+
+func TestFriendHandlers_FriendEvents(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	cfg := testutils.GetTestConfig()
+	cfg.Social.EventsHeartbeatInterval = 50 * time.Millisecond
+	app := gateway.NewAPIGateway(cfg, zaptest.NewLogger(t), db).Router()
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	token2 := testutils.CreateTestAccessToken(t, db, player2ID)
+
+	// Streams need a real connection; app.Test waits for the whole response
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(ln)
+	defer ln.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+"/friends/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token2)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	// waitFor reads lines until one starts with prefix
+	waitFor := func(prefix string) string {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("Stream ended while waiting for %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return line
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for %q", prefix)
+			}
+		}
+	}
+
+	// The subscription is registered once the stream is open
+	waitFor(": connected")
+	waitFor(": heartbeat")
+
+	resp2 := sendJSON(t, app, http.MethodPost, "/friends/request", token1, map[string]interface{}{"friend_id": player2ID})
+	if resp2.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp2.StatusCode)
+	}
+
+	if line := waitFor("event: "); line != "event: "+social.EventFriendRequest {
+		t.Errorf("Expected %s event, got %q", social.EventFriendRequest, line)
+	}
+	var event social.Event
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(waitFor("data: "), "data: ")), &event); err != nil {
+		t.Fatalf("Failed to decode event data: %v", err)
+	}
+	if event.Type != social.EventFriendRequest || event.PlayerID != player1ID || event.Username != "player1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestFriendHandlers_FriendEventsRequiresAuth(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	app := createFullTestServer(t, db)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/friends/events", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
 	}
 }
@@ -0,0 +1,144 @@
+package handlers_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/gateway"
+	"ai-zombie-defense/backend-api/internal/services/social"
+	"ai-zombie-defense/backend-api/internal/testutils"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type presenceClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dialPresence opens GET /ws/presence for the token's player.
+func dialPresence(t *testing.T, addr, token string) *presenceClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_, err = io.WriteString(conn, "GET /ws/presence HTTP/1.1\r\nHost: "+addr+
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ=="+
+		"\r\nSec-WebSocket-Version: 13\r\nAuthorization: Bearer "+token+"\r\n\r\n")
+	if err != nil {
+		t.Fatalf("Failed to send handshake: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	return &presenceClient{conn: conn, br: br}
+}
+
+// next reads the next presence event, failing after a few seconds.
+func (c *presenceClient) next(t *testing.T) social.Event {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		t.Fatalf("Failed to read presence event: %v", err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatalf("Failed to read presence event: %v", err)
+	}
+	var event social.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("Failed to decode presence event %q: %v", payload, err)
+	}
+	return event
+}
+
+// expectNone asserts no event arrives within a short window.
+func (c *presenceClient) expectNone(t *testing.T) {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, err := c.br.Peek(1); err == nil {
+		t.Errorf("Expected no presence event, got %+v", c.next(t))
+	}
+}
+
+func expectPresence(t *testing.T, event social.Event, eventType string, playerID int64, username string) {
+	t.Helper()
+	if event.Type != eventType || event.PlayerID != playerID || event.Username != username {
+		t.Errorf("Expected %s for %s (%d), got %+v", eventType, username, playerID, event)
+	}
+}
+
+func TestFriendHandlers_PresenceSocket(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	player1ID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	player2ID := testutils.CreateTestPlayer(t, db, "player2", "player2@example.com", "password123")
+	player3ID := testutils.CreateTestPlayer(t, db, "player3", "player3@example.com", "password123")
+	if _, err := db.Exec(`INSERT INTO friends (player_id, friend_id, status) VALUES (?, ?, 'accepted')`, player1ID, player2ID); err != nil {
+		t.Fatalf("Failed to insert friendship: %v", err)
+	}
+	app := gateway.NewAPIGateway(testutils.GetTestConfig(), zaptest.NewLogger(t), db).Router()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(ln)
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	// player2 comes online first; player3 isn't a friend and never hears anything
+	player2 := dialPresence(t, addr, testutils.CreateTestAccessToken(t, db, player2ID))
+	stranger := dialPresence(t, addr, testutils.CreateTestAccessToken(t, db, player3ID))
+	player2.expectNone(t)
+
+	// player1 learns player2 is already online, and player2 hears player1 arrive
+	token1 := testutils.CreateTestAccessToken(t, db, player1ID)
+	phone := dialPresence(t, addr, token1)
+	expectPresence(t, phone.next(t), social.EventFriendOnline, player2ID, "player2")
+	expectPresence(t, player2.next(t), social.EventFriendOnline, player1ID, "player1")
+
+	// A second device neither re-announces player1 nor takes them offline when one closes
+	desktop := dialPresence(t, addr, token1)
+	expectPresence(t, desktop.next(t), social.EventFriendOnline, player2ID, "player2")
+	phone.conn.Close()
+	player2.expectNone(t)
+
+	// Closing the last device takes player1 offline
+	desktop.conn.Close()
+	expectPresence(t, player2.next(t), social.EventFriendOffline, player1ID, "player1")
+	stranger.expectNone(t)
+}
+
+func TestFriendHandlers_PresenceSocketRequiresUpgrade(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+	playerID := testutils.CreateTestPlayer(t, db, "player1", "player1@example.com", "password123")
+	app := createFullTestServer(t, db)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ws/presence", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", resp.StatusCode)
+	}
+
+	resp = sendJSON(t, app, http.MethodGet, "/ws/presence", testutils.CreateTestAccessToken(t, db, playerID), nil)
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("Expected status 426 for a plain request, got %d", resp.StatusCode)
+	}
+}
@@ -2,29 +2,40 @@ package social
 
 import (
 	"ai-zombie-defense/backend-api/internal/db"
+	"ai-zombie-defense/backend-api/internal/services/notification"
 	"ai-zombie-defense/backend-api/pkg/config"
+	"ai-zombie-defense/backend-api/pkg/logging"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type socialService struct {
-	config  config.Config
-	logger  *zap.Logger
-	dbConn  db.DBTX
-	queries *db.Queries
+	config          config.Config
+	logger          *zap.Logger
+	dbConn          db.DBTX
+	queries         *db.Queries
+	notificationSvc notification.Service
+	events          *EventHub
+	presence        *PresenceRegistry
+	presenceEvents  *EventHub
 }
 
-func NewSocialService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX) Service {
+func NewSocialService(cfg config.Config, logger *zap.Logger, dbConn db.DBTX, notificationSvc notification.Service) Service {
 	return &socialService{
-		config:  cfg,
-		logger:  logger,
-		dbConn:  dbConn,
-		queries: db.New(),
+		config:          cfg,
+		logger:          logger,
+		dbConn:          dbConn,
+		queries:         db.New(),
+		notificationSvc: notificationSvc,
+		events:          NewEventHub(),
+		presence:        NewPresenceRegistry(),
+		presenceEvents:  NewEventHub(),
 	}
 }
 
@@ -32,31 +43,72 @@ func (s *socialService) SendFriendRequest(ctx context.Context, playerID int64, f
 	if playerID == friendID {
 		return ErrCannotFriendSelf
 	}
-	existing, err := s.queries.GetFriendRequest(ctx, s.dbConn, &db.GetFriendRequestParams{
+	if _, err := s.queries.GetPlayer(ctx, s.dbConn, friendID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPlayerNotFound
+		}
+		return fmt.Errorf("failed to get target player: %w", err)
+	}
+	blocked, err := s.queries.IsBlockedBetween(ctx, s.dbConn, &db.IsBlockedBetweenParams{
 		PlayerID: playerID,
 		FriendID: friendID,
 	})
-	if err == nil && existing != nil {
-		return ErrFriendRequestAlreadyExists
+	if err != nil {
+		return fmt.Errorf("failed to check blocks: %w", err)
 	}
+	if blocked == 1 {
+		return ErrBlocked
+	}
+	settings, err := s.queries.GetPlayerSettings(ctx, s.dbConn, friendID)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to check existing friend request: %w", err)
+		return fmt.Errorf("failed to get target player settings: %w", err)
+	}
+	if err == nil && settings.AllowFriendRequests == 0 {
+		return ErrFriendRequestsDisabled
 	}
-	params := &db.CreateFriendRequestParams{
+	// The insert is a no-op when the pair already has a row, so concurrent
+	// requests can't both pass a separate existence check and collide
+	created, err := s.queries.CreateFriendRequest(ctx, s.dbConn, &db.CreateFriendRequestParams{
 		PlayerID: playerID,
 		FriendID: friendID,
-	}
-	err = s.queries.CreateFriendRequest(ctx, s.dbConn, params)
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: friends.player_id, friends.friend_id") {
-			return ErrFriendRequestAlreadyExists
-		}
 		return fmt.Errorf("failed to create friend request: %w", err)
 	}
-	s.logger.Debug("Friend request sent", zap.Int64("player_id", playerID), zap.Int64("friend_id", friendID))
+	if created == 0 {
+		return ErrFriendRequestAlreadyExists
+	}
+	logging.FromContext(ctx, s.logger).Debug("Friend request sent", zap.Int64("player_id", playerID), zap.Int64("friend_id", friendID))
+	s.publishEvent(ctx, friendID, EventFriendRequest, playerID)
 	return nil
 }
 
+// SendFriendRequests sends a friend request to each target independently so one
+// bad ID doesn't fail the rest of the batch.
+func (s *socialService) SendFriendRequests(ctx context.Context, playerID int64, friendIDs []int64) []*BulkFriendRequestResult {
+	results := make([]*BulkFriendRequestResult, 0, len(friendIDs))
+	for _, friendID := range friendIDs {
+		status := BulkStatusSent
+		if err := s.SendFriendRequest(ctx, playerID, friendID); err != nil {
+			switch {
+			case errors.Is(err, ErrCannotFriendSelf):
+				status = BulkStatusSelf
+			case errors.Is(err, ErrPlayerNotFound):
+				status = BulkStatusNotFound
+			case errors.Is(err, ErrFriendRequestAlreadyExists):
+				status = BulkStatusAlreadyFriends
+			case errors.Is(err, ErrFriendRequestsDisabled), errors.Is(err, ErrBlocked):
+				status = BulkStatusBlocked
+			default:
+				logging.FromContext(ctx, s.logger).Error("Failed to send bulk friend request", zap.Error(err), zap.Int64("player_id", playerID), zap.Int64("friend_id", friendID))
+				status = BulkStatusError
+			}
+		}
+		results = append(results, &BulkFriendRequestResult{FriendID: friendID, Status: status})
+	}
+	return results
+}
+
 func (s *socialService) AcceptFriendRequest(ctx context.Context, requesterPlayerID int64, friendID int64) error {
 	request, err := s.queries.GetFriendRequest(ctx, s.dbConn, &db.GetFriendRequestParams{
 		PlayerID: requesterPlayerID,
@@ -79,10 +131,102 @@ func (s *socialService) AcceptFriendRequest(ctx context.Context, requesterPlayer
 	if err != nil {
 		return fmt.Errorf("failed to accept friend request: %w", err)
 	}
-	s.logger.Debug("Friend request accepted", zap.Int64("player_id", requesterPlayerID), zap.Int64("friend_id", friendID))
+	logging.FromContext(ctx, s.logger).Debug("Friend request accepted", zap.Int64("player_id", requesterPlayerID), zap.Int64("friend_id", friendID))
+	s.notifyFriendAccepted(ctx, requesterPlayerID, friendID)
+	s.publishEvent(ctx, requesterPlayerID, EventFriendAccepted, friendID)
 	return nil
 }
 
+// publishEvent pushes an event about otherID to recipientID's open event streams.
+// The username lookup is skipped when nobody is listening.
+func (s *socialService) publishEvent(ctx context.Context, recipientID int64, eventType string, otherID int64) {
+	if s.events.Subscribers(recipientID) == 0 {
+		return
+	}
+	event := Event{Type: eventType, PlayerID: otherID, CreatedAt: time.Now().UTC()}
+	if other, err := s.queries.GetPlayer(ctx, s.dbConn, otherID); err == nil {
+		event.Username = other.Username
+	}
+	s.events.Publish(recipientID, event)
+}
+
+func (s *socialService) SubscribeEvents(playerID int64) (<-chan Event, func()) {
+	return s.events.Subscribe(playerID)
+}
+
+func (s *socialService) ConnectPresence(ctx context.Context, playerID int64) (*PresenceSession, error) {
+	friends, err := s.queries.ListFriends(ctx, s.dbConn, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+
+	// Subscribe before going online so no transition in between is missed
+	events, unsubscribe := s.presenceEvents.Subscribe(playerID)
+	session := &PresenceSession{Events: events}
+	now := time.Now().UTC()
+	for _, f := range friends {
+		if s.presence.IsOnline(f.FriendPlayerID) {
+			session.OnlineFriends = append(session.OnlineFriends, Event{
+				Type:      EventFriendOnline,
+				PlayerID:  f.FriendPlayerID,
+				Username:  f.FriendUsername,
+				CreatedAt: now,
+			})
+		}
+	}
+	if s.presence.Connect(playerID) {
+		s.broadcastPresence(ctx, playerID, EventFriendOnline, friends)
+	}
+
+	var once sync.Once
+	session.close = func() {
+		once.Do(func() {
+			unsubscribe()
+			if !s.presence.Disconnect(playerID) {
+				return
+			}
+			// The connection's request context is long gone by now
+			ctx := context.Background()
+			friends, err := s.queries.ListFriends(ctx, s.dbConn, playerID)
+			if err != nil {
+				logging.FromContext(ctx, s.logger).Warn("Failed to list friends for offline presence", zap.Int64("player_id", playerID), zap.Error(err))
+				return
+			}
+			s.broadcastPresence(ctx, playerID, EventFriendOffline, friends)
+		})
+	}
+	return session, nil
+}
+
+// broadcastPresence tells playerID's accepted friends that they came online or went offline.
+func (s *socialService) broadcastPresence(ctx context.Context, playerID int64, eventType string, friends []*db.ListFriendsRow) {
+	event := Event{Type: eventType, PlayerID: playerID, CreatedAt: time.Now().UTC()}
+	if player, err := s.queries.GetPlayer(ctx, s.dbConn, playerID); err == nil {
+		event.Username = player.Username
+	}
+	for _, f := range friends {
+		s.presenceEvents.Publish(f.FriendPlayerID, event)
+	}
+}
+
+// notifyFriendAccepted tells the requester their request was accepted.
+// Notification failures are logged and never fail the acceptance itself.
+func (s *socialService) notifyFriendAccepted(ctx context.Context, requesterPlayerID int64, friendID int64) {
+	if s.notificationSvc == nil {
+		return
+	}
+	message := "Your friend request was accepted"
+	if friend, err := s.queries.GetPlayer(ctx, s.dbConn, friendID); err == nil {
+		message = fmt.Sprintf("%s accepted your friend request", friend.Username)
+	}
+	if err := s.notificationSvc.Notify(ctx, requesterPlayerID, notification.TypeFriendAccepted, message, &friendID); err != nil {
+		logging.FromContext(ctx, s.logger).Warn("Failed to create friend accepted notification",
+			zap.Int64("player_id", requesterPlayerID),
+			zap.Int64("friend_id", friendID),
+			zap.Error(err))
+	}
+}
+
 func (s *socialService) DeclineFriendRequest(ctx context.Context, requesterPlayerID int64, friendID int64) error {
 	request, err := s.queries.GetFriendRequest(ctx, s.dbConn, &db.GetFriendRequestParams{
 		PlayerID: requesterPlayerID,
@@ -105,7 +249,71 @@ func (s *socialService) DeclineFriendRequest(ctx context.Context, requesterPlaye
 	if err != nil {
 		return fmt.Errorf("failed to decline friend request: %w", err)
 	}
-	s.logger.Debug("Friend request declined", zap.Int64("player_id", requesterPlayerID), zap.Int64("friend_id", friendID))
+	logging.FromContext(ctx, s.logger).Debug("Friend request declined", zap.Int64("player_id", requesterPlayerID), zap.Int64("friend_id", friendID))
+	return nil
+}
+
+func (s *socialService) RemoveFriend(ctx context.Context, playerID int64, friendID int64) error {
+	removed, err := s.queries.RemoveFriend(ctx, s.dbConn, &db.RemoveFriendParams{
+		PlayerID: playerID,
+		FriendID: friendID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove friend: %w", err)
+	}
+	if removed == 0 {
+		return ErrFriendRequestNotFound
+	}
+	logging.FromContext(ctx, s.logger).Debug("Friend removed", zap.Int64("player_id", playerID), zap.Int64("friend_id", friendID))
+	return nil
+}
+
+func (s *socialService) BlockPlayer(ctx context.Context, playerID int64, blockedID int64) error {
+	if playerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+
+	err := db.WithTx(ctx, s.dbConn, func(tx db.DBTX) error {
+		if _, err := s.queries.GetPlayer(ctx, tx, blockedID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("failed to get target player: %w", err)
+		}
+		// Blocking ends any friendship or pending request, whoever started it
+		if err := s.queries.DeleteFriendRelationship(ctx, tx, &db.DeleteFriendRelationshipParams{
+			PlayerID: playerID,
+			FriendID: blockedID,
+		}); err != nil {
+			return fmt.Errorf("failed to delete friend relationship: %w", err)
+		}
+		if err := s.queries.BlockPlayer(ctx, tx, &db.BlockPlayerParams{
+			PlayerID: playerID,
+			FriendID: blockedID,
+		}); err != nil {
+			return fmt.Errorf("failed to block player: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx, s.logger).Debug("Player blocked", zap.Int64("player_id", playerID), zap.Int64("blocked_id", blockedID))
+	return nil
+}
+
+func (s *socialService) UnblockPlayer(ctx context.Context, playerID int64, blockedID int64) error {
+	removed, err := s.queries.UnblockPlayer(ctx, s.dbConn, &db.UnblockPlayerParams{
+		PlayerID: playerID,
+		FriendID: blockedID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unblock player: %w", err)
+	}
+	if removed == 0 {
+		return ErrBlockNotFound
+	}
+	logging.FromContext(ctx, s.logger).Debug("Player unblocked", zap.Int64("player_id", playerID), zap.Int64("blocked_id", blockedID))
 	return nil
 }
 
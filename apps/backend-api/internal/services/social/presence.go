@@ -0,0 +1,66 @@
+package social
+
+import "sync"
+
+// Presence event types pushed to GET /ws/presence connections
+const (
+	EventFriendOnline  = "friend_online"
+	EventFriendOffline = "friend_offline"
+)
+
+// PresenceRegistry counts open presence connections per player. A player is
+// online while at least one of their connections (one per device) is open.
+type PresenceRegistry struct {
+	mu    sync.Mutex
+	conns map[int64]int
+}
+
+func NewPresenceRegistry() *PresenceRegistry {
+	return &PresenceRegistry{conns: make(map[int64]int)}
+}
+
+// Connect records a connection and reports whether it brought the player online.
+func (r *PresenceRegistry) Connect(playerID int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conns[playerID]++
+	return r.conns[playerID] == 1
+}
+
+// Disconnect drops a connection and reports whether the player went offline.
+func (r *PresenceRegistry) Disconnect(playerID int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conns[playerID] == 0 {
+		return false
+	}
+	r.conns[playerID]--
+	if r.conns[playerID] > 0 {
+		return false
+	}
+	delete(r.conns, playerID)
+	return true
+}
+
+// IsOnline reports whether the player has any open connection.
+func (r *PresenceRegistry) IsOnline(playerID int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conns[playerID] > 0
+}
+
+// PresenceSession is one open presence connection.
+type PresenceSession struct {
+	// OnlineFriends holds a friend_online event for each accepted friend already online.
+	OnlineFriends []Event
+	// Events carries friends' online/offline transitions while the session is open.
+	Events <-chan Event
+
+	close func()
+}
+
+// Close ends the session; the player goes offline with their last open session.
+// It is safe to call more than once.
+func (s *PresenceSession) Close() {
+	s.close()
+}
@@ -11,13 +11,49 @@ var (
 	ErrFriendRequestNotFound      = errors.New("friend request not found")
 	ErrFriendRequestNotPending    = errors.New("friend request not pending")
 	ErrCannotFriendSelf           = errors.New("cannot send friend request to yourself")
+	ErrFriendRequestsDisabled     = errors.New("player is not accepting friend requests")
+	ErrPlayerNotFound             = errors.New("player not found")
+	ErrBlocked                    = errors.New("player is blocked")
+	ErrCannotBlockSelf            = errors.New("cannot block yourself")
+	ErrBlockNotFound              = errors.New("block not found")
 )
 
+// Per-target outcomes reported by SendFriendRequests
+const (
+	BulkStatusSent           = "sent"
+	BulkStatusAlreadyFriends = "already_friends"
+	BulkStatusBlocked        = "blocked"
+	BulkStatusSelf           = "self"
+	BulkStatusNotFound       = "not_found"
+	BulkStatusError          = "error"
+)
+
+// BulkFriendRequestResult is the outcome of one target in a bulk friend request.
+type BulkFriendRequestResult struct {
+	FriendID int64
+	Status   string
+}
+
 type Service interface {
 	SendFriendRequest(ctx context.Context, playerID int64, friendID int64) error
+	SendFriendRequests(ctx context.Context, playerID int64, friendIDs []int64) []*BulkFriendRequestResult
 	AcceptFriendRequest(ctx context.Context, requesterPlayerID int64, friendID int64) error
 	DeclineFriendRequest(ctx context.Context, requesterPlayerID int64, friendID int64) error
+	// RemoveFriend ends an accepted friendship from either side; ErrFriendRequestNotFound
+	// if the players aren't friends.
+	RemoveFriend(ctx context.Context, playerID int64, friendID int64) error
+	// BlockPlayer blocks blockedID for playerID, dropping any pending or accepted
+	// relationship between them. Blocking an already blocked player is a no-op.
+	BlockPlayer(ctx context.Context, playerID int64, blockedID int64) error
+	// UnblockPlayer lifts playerID's block on blockedID; ErrBlockNotFound if there is none.
+	UnblockPlayer(ctx context.Context, playerID int64, blockedID int64) error
 	ListFriends(ctx context.Context, playerID int64) ([]*db.ListFriendsRow, error)
 	ListPendingIncoming(ctx context.Context, playerID int64) ([]*db.ListPendingIncomingRow, error)
 	ListPendingOutgoing(ctx context.Context, playerID int64) ([]*db.ListPendingOutgoingRow, error)
+	// SubscribeEvents streams friend requests and acceptances addressed to playerID
+	// until the returned cancel func is called.
+	SubscribeEvents(playerID int64) (<-chan Event, func())
+	// ConnectPresence opens a presence session for playerID. The first open session
+	// brings them online for their accepted friends and closing the last takes them offline.
+	ConnectPresence(ctx context.Context, playerID int64) (*PresenceSession, error)
 }
@@ -5,6 +5,7 @@ import (
 	"ai-zombie-defense/backend-api/pkg/config"
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -19,16 +20,99 @@ func GetTestConfig() config.Config {
 			MigrationsPath: "./migrations",
 		},
 		Server: config.ServerConfig{
-			Host: "localhost",
-			Port: 8080,
+			Host:                  "localhost",
+			Port:                  8080,
+			RateLimitMax:          1000,
+			RateLimitDuration:     time.Minute,
+			AuthRateLimitMax:      1000,
+			AuthRateLimitDuration: time.Minute,
+			ShutdownTimeout:       10 * time.Second,
 		},
 		JWT: config.JWTConfig{
-			Secret:            "test-secret",
-			AccessExpiration:  15 * time.Minute,
-			RefreshExpiration: 7 * 24 * time.Hour,
+			Secret:             "test-secret",
+			AccessExpiration:   15 * time.Minute,
+			RefreshExpiration:  7 * 24 * time.Hour,
+			RefreshSliding:     false,
+			RefreshMaxLifetime: 30 * 24 * time.Hour,
 		},
 		Progression: config.ProgressionConfig{
-			BaseXPPerLevel: 1000,
+			BaseXPPerLevel:            1000,
+			CurveType:                 "linear",
+			CurveGrowthFactor:         1.15,
+			PrestigeBackfillBatchSize: 500,
+			XPRewards: config.XPRewardsConfig{
+				BaseXP:   100,
+				PerKill:  10,
+				PerWave:  50,
+				PerScrap: 1,
+			},
+			RewardDecay: config.RewardDecayConfig{
+				ShortMatchDuration: 5 * time.Minute,
+				Window:             time.Hour,
+				FreeMatches:        3,
+				Factor:             0.5,
+				MinMultiplier:      0.1,
+			},
+			StatCaps: config.StatCapsConfig{
+				MaxKillsPerWave: 500,
+				MaxScrapPerWave: 5000,
+				MaxDataPerWave:  500,
+			},
+		},
+		GameServer: config.GameServerConfig{
+			JoinHistoryRetention:    30 * 24 * time.Hour,
+			JoinHistoryMaxPerServer: 1000,
+			AllowHostnames:          false,
+			JoinTokenExpiration:     30 * time.Second,
+			HeartbeatTTL:            90 * time.Second,
+			HeartbeatSweepInterval:  30 * time.Second,
+		},
+		Notification: config.NotificationConfig{
+			MaxUnreadPerPlayer: 100,
+		},
+		Cosmetics: config.CosmeticsConfig{
+			UndoWindow:           5 * time.Minute,
+			DefaultSlotCosmetics: map[string]int64{},
+			BundleProRate:        true,
+		},
+		Webhook: config.WebhookConfig{
+			QueueSize:  1000,
+			DropPolicy: "oldest",
+			Timeout:    5 * time.Second,
+		},
+		Account: config.AccountConfig{
+			EmailChangeTokenExpiry:  24 * time.Hour,
+			PasswordPolicy:          config.PasswordPolicy{MinLength: 8},
+			LoginLockoutThreshold:   5,
+			LoginLockoutDuration:    5 * time.Minute,
+			LoginLockoutMaxDuration: time.Hour,
+			ReferralClaimWindow:     7 * 24 * time.Hour,
+			ReferralCurrencyReward:  250,
+		},
+		Leaderboard: config.LeaderboardConfig{
+			WeeklyMode:      "calendar",
+			RefreshInterval: time.Minute,
+			SnapshotMaxAge:  5 * time.Minute,
+		},
+		Admin: config.AdminConfig{
+			OperationConcurrency:        map[string]int{},
+			DefaultOperationConcurrency: 1,
+			StatusCacheTTL:              30 * time.Second,
+		},
+		Moderation: config.ModerationConfig{
+			ReportsPerDay: 5,
+		},
+		Loot: config.LootConfig{
+			PityThreshold:       10,
+			PityMinRarity:       "rare",
+			GuaranteedDrop:      false,
+			DuplicateLootRefund: map[string]int64{},
+		},
+		Social: config.SocialConfig{
+			EventsHeartbeatInterval: 15 * time.Second,
+		},
+		Metrics: config.MetricsConfig{
+			Enabled: true,
 		},
 	}
 }
@@ -38,6 +122,9 @@ func SetupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Failed to open database: %v", err)
 	}
+	// Every connection to :memory: is a separate database, so concurrent
+	// service calls must share the one that holds the schema
+	db.SetMaxOpenConns(1)
 	// Enable foreign keys
 	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
 		t.Fatalf("Failed to enable foreign keys: %v", err)
@@ -69,6 +156,15 @@ func createTables(t *testing.T, db *sql.DB) {
             created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
             ip_address TEXT,
             user_agent TEXT,
+            started_at TEXT,
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE login_failures (
+            player_id INTEGER PRIMARY KEY,
+            failed_attempts INTEGER NOT NULL DEFAULT 0,
+            lockouts INTEGER NOT NULL DEFAULT 0,
+            locked_until TEXT,
+            updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
         );`,
 		`CREATE TABLE player_settings (
@@ -80,6 +176,10 @@ func createTables(t *testing.T, db *sql.DB) {
             subtitles_enabled INTEGER NOT NULL DEFAULT 0,
             created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
             updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            allow_friend_requests INTEGER NOT NULL DEFAULT 1,
+            show_on_leaderboard INTEGER NOT NULL DEFAULT 1,
+            match_history_public INTEGER NOT NULL DEFAULT 1,
+            inventory_visible_to_friends INTEGER NOT NULL DEFAULT 1,
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
         );`,
 		`CREATE TABLE player_progression (
@@ -102,7 +202,7 @@ func createTables(t *testing.T, db *sql.DB) {
             player_id INTEGER NOT NULL,
             amount INTEGER NOT NULL,
             balance_after INTEGER NOT NULL,
-            transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+            transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'bundle_purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
             reference_id INTEGER,
             created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
@@ -117,7 +217,10 @@ func createTables(t *testing.T, db *sql.DB) {
             unlock_level INTEGER NOT NULL DEFAULT 1,
             data_cost INTEGER NOT NULL DEFAULT 0,
             is_prestige_only INTEGER NOT NULL DEFAULT 0,
-            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            max_per_day INTEGER,
+            available_until TEXT,
+            equip_restriction TEXT CHECK (equip_restriction IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral'))
         );`,
 		`CREATE TABLE loot_tables (
             loot_table_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -136,15 +239,36 @@ func createTables(t *testing.T, db *sql.DB) {
             max_quantity INTEGER NOT NULL DEFAULT 1,
             FOREIGN KEY (loot_table_id) REFERENCES loot_tables (loot_table_id) ON DELETE CASCADE,
             FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE loot_pity (
+            player_id INTEGER PRIMARY KEY,
+            unlucky_rolls INTEGER NOT NULL DEFAULT 0,
+            updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
         );`,
 		`CREATE TABLE player_cosmetics (
             player_id INTEGER NOT NULL,
             cosmetic_id INTEGER NOT NULL,
             unlocked_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
-            unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige')),
+            unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral')),
             PRIMARY KEY (player_id, cosmetic_id),
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
             FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE cosmetic_bundles (
+            bundle_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE,
+            description TEXT,
+            price INTEGER NOT NULL CHECK (price >= 0),
+            is_active INTEGER NOT NULL DEFAULT 1,
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+        );`,
+		`CREATE TABLE cosmetic_bundle_items (
+            bundle_id INTEGER NOT NULL,
+            cosmetic_id INTEGER NOT NULL,
+            PRIMARY KEY (bundle_id, cosmetic_id),
+            FOREIGN KEY (bundle_id) REFERENCES cosmetic_bundles (bundle_id) ON DELETE CASCADE,
+            FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
         );`,
 		`CREATE TABLE friends (
             player_id INTEGER NOT NULL,
@@ -165,6 +289,7 @@ func createTables(t *testing.T, db *sql.DB) {
             updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
         );`,
+		`CREATE UNIQUE INDEX idx_loadouts_one_active ON loadouts(player_id) WHERE is_active = 1;`,
 		`CREATE TABLE loadout_cosmetics (
             loadout_id INTEGER NOT NULL,
             cosmetic_id INTEGER NOT NULL,
@@ -208,8 +333,12 @@ func createTables(t *testing.T, db *sql.DB) {
             waves_survived INTEGER NOT NULL DEFAULT 0,
             total_zombies_killed INTEGER NOT NULL DEFAULT 0,
             total_players INTEGER NOT NULL DEFAULT 0,
+            idempotency_key TEXT,
             FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE
         );`,
+		`CREATE UNIQUE INDEX idx_matches_server_idempotency_key ON matches(server_id, idempotency_key);`,
+		`CREATE INDEX idx_matches_start_time ON matches(start_time);`,
+		`CREATE INDEX idx_servers_is_online ON servers(is_online);`,
 		`CREATE TABLE player_match_stats (
             player_id INTEGER NOT NULL,
             match_id INTEGER NOT NULL,
@@ -229,6 +358,90 @@ func createTables(t *testing.T, db *sql.DB) {
             FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
             FOREIGN KEY (match_id) REFERENCES matches (match_id) ON DELETE CASCADE
         );`,
+		`CREATE INDEX idx_player_match_stats_match_id ON player_match_stats(match_id);`,
+		`CREATE TABLE join_tokens (
+            join_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            token TEXT NOT NULL UNIQUE,
+            player_id INTEGER NOT NULL,
+            server_id INTEGER NOT NULL,
+            expires_at TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            used_at TEXT,
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
+            FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE email_change_tokens (
+            email_change_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            token TEXT NOT NULL UNIQUE,
+            player_id INTEGER NOT NULL,
+            new_email TEXT NOT NULL,
+            expires_at TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            used_at TEXT,
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE referrals (
+            referral_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            referrer_id INTEGER NOT NULL,
+            referred_id INTEGER NOT NULL UNIQUE,
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            CHECK (referrer_id != referred_id),
+            FOREIGN KEY (referrer_id) REFERENCES players (player_id) ON DELETE CASCADE,
+            FOREIGN KEY (referred_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE player_reports (
+            report_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            reporter_id INTEGER NOT NULL,
+            target_id INTEGER NOT NULL,
+            category TEXT NOT NULL CHECK (category IN ('cheating', 'abuse', 'griefing', 'other')),
+            reason TEXT,
+            status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            resolved_at TEXT,
+            resolved_by INTEGER,
+            CHECK (reporter_id != target_id),
+            FOREIGN KEY (reporter_id) REFERENCES players (player_id) ON DELETE CASCADE,
+            FOREIGN KEY (target_id) REFERENCES players (player_id) ON DELETE CASCADE,
+            FOREIGN KEY (resolved_by) REFERENCES players (player_id) ON DELETE SET NULL
+        );`,
+		`CREATE INDEX idx_player_reports_target_id_status ON player_reports(target_id, status);`,
+		`CREATE INDEX idx_player_reports_reporter_id_created_at ON player_reports(reporter_id, created_at);`,
+		`CREATE UNIQUE INDEX idx_player_reports_open_pair ON player_reports(reporter_id, target_id) WHERE status = 'open';`,
+		`CREATE TABLE notifications (
+            notification_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            player_id INTEGER NOT NULL,
+            notification_type TEXT NOT NULL,
+            message TEXT NOT NULL,
+            reference_id INTEGER,
+            is_read INTEGER NOT NULL DEFAULT 0,
+            created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            read_at TEXT,
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE server_joins (
+            server_join_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            server_id INTEGER NOT NULL,
+            player_id INTEGER NOT NULL,
+            joined_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE,
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE TABLE leaderboard_snapshots (
+            period TEXT NOT NULL CHECK (period IN ('daily', 'weekly', 'alltime')),
+            metric TEXT NOT NULL,
+            ranking INTEGER NOT NULL,
+            player_id INTEGER NOT NULL,
+            username TEXT NOT NULL,
+            total_score INTEGER NOT NULL,
+            matches_played INTEGER NOT NULL,
+            avg_kills_per_match REAL,
+            avg_waves_survived REAL,
+            metric_value INTEGER NOT NULL,
+            generated_at TEXT NOT NULL,
+            PRIMARY KEY (period, metric, ranking),
+            FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+        );`,
+		`CREATE INDEX idx_leaderboard_snapshots_player_id ON leaderboard_snapshots(period, metric, player_id);`,
 	}
 
 	for _, sql := range tables {
@@ -241,6 +454,8 @@ func createTables(t *testing.T, db *sql.DB) {
 func CreateTestPlayer(t *testing.T, dbConn *sql.DB, username, email, password string) int64 {
 	logger := zaptest.NewLogger(t)
 	cfg := GetTestConfig()
+	// Fixtures may use any password; the policy is tested on its own
+	cfg.Account.PasswordPolicy = config.PasswordPolicy{}
 	service := auth.NewAuthService(cfg, logger, dbConn)
 
 	// Use bcrypt directly for hashing if needed, or use service
@@ -257,7 +472,7 @@ func CreateTestAccessToken(t *testing.T, dbConn *sql.DB, playerID int64) string
 	logger := zaptest.NewLogger(t)
 	cfg := GetTestConfig()
 	service := auth.NewAuthService(cfg, logger, dbConn)
-	token, err := service.GenerateAccessToken(playerID)
+	token, err := service.GenerateAccessToken(context.Background(), playerID)
 	if err != nil {
 		t.Fatalf("Failed to generate access token: %v", err)
 	}
@@ -288,3 +503,13 @@ func CreateTestServerRow(t *testing.T, dbConn *sql.DB) int64 {
 	}
 	return serverID
 }
+
+// CreateTestServerAuthToken assigns a fresh auth token to an existing server
+// row and returns it for use in the X-Server-Token header.
+func CreateTestServerAuthToken(t *testing.T, dbConn *sql.DB, serverID int64) string {
+	token := fmt.Sprintf("test-server-token-%d", serverID)
+	if _, err := dbConn.Exec(`UPDATE servers SET auth_token = ? WHERE server_id = ?`, token, serverID); err != nil {
+		t.Fatalf("Failed to set server auth token: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ai-zombie-defense/backend-api/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// Queue overflow policies accepted by config.WebhookConfig.DropPolicy
+const (
+	DropOldest = "oldest"
+	DropNewest = "newest"
+)
+
+const (
+	defaultQueueSize = 1000
+	// maxRecentFailures bounds the failure history kept for the status endpoint
+	maxRecentFailures = 20
+)
+
+// Event is a single webhook payload.
+type Event struct {
+	Type      string      `json:"type"`
+	Payload   interface{} `json:"payload"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Publisher accepts events for delivery. Services depend on it rather than on
+// Dispatcher so they can run without a webhook endpoint configured.
+type Publisher interface {
+	Enqueue(evt Event) bool
+}
+
+// Failure records a delivery attempt that did not succeed.
+type Failure struct {
+	EventType string    `json:"event_type"`
+	Error     string    `json:"error"`
+	At        time.Time `json:"at"`
+}
+
+// Status is a point-in-time view of the dispatcher queue and counters.
+type Status struct {
+	QueueDepth     int       `json:"queue_depth"`
+	QueueCapacity  int       `json:"queue_capacity"`
+	DropPolicy     string    `json:"drop_policy"`
+	Delivered      int64     `json:"delivered"`
+	Dropped        int64     `json:"dropped"`
+	Failed         int64     `json:"failed"`
+	RecentFailures []Failure `json:"recent_failures"`
+}
+
+// Dispatcher delivers events to a webhook endpoint from a bounded in-memory
+// queue. Enqueue never blocks: when the queue is full an event is dropped
+// according to the drop policy, so a slow or unavailable endpoint can't
+// exhaust memory or stall request handlers.
+type Dispatcher struct {
+	url    string
+	policy string
+	client *http.Client
+	logger *zap.Logger
+	queue  chan Event
+
+	// enqueueMu serialises evict-then-send under DropOldest
+	enqueueMu sync.Mutex
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+	failed    atomic.Int64
+
+	failuresMu     sync.Mutex
+	recentFailures []Failure
+}
+
+func NewDispatcher(cfg config.WebhookConfig, logger *zap.Logger) *Dispatcher {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	policy := cfg.DropPolicy
+	if policy != DropNewest {
+		policy = DropOldest
+	}
+	return &Dispatcher{
+		url:    cfg.URL,
+		policy: policy,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		queue:  make(chan Event, size),
+	}
+}
+
+// Enqueue queues evt for delivery and reports whether it was accepted. Under
+// DropOldest the incoming event is always accepted and the oldest queued one
+// is evicted instead.
+func (d *Dispatcher) Enqueue(evt Event) bool {
+	if evt.CreatedAt.IsZero() {
+		evt.CreatedAt = time.Now().UTC()
+	}
+
+	d.enqueueMu.Lock()
+	defer d.enqueueMu.Unlock()
+	for {
+		select {
+		case d.queue <- evt:
+			return true
+		default:
+		}
+
+		if d.policy == DropNewest {
+			d.dropped.Add(1)
+			return false
+		}
+		select {
+		case <-d.queue:
+			d.dropped.Add(1)
+		default:
+			// The worker drained the queue in the meantime; retry the send
+		}
+	}
+}
+
+// Run delivers queued events until ctx is cancelled. Events still queued at
+// shutdown are discarded. Intended to be started with worker.Manager.Go.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-d.queue:
+			if err := d.deliver(ctx, evt); err != nil {
+				d.recordFailure(evt, err)
+				continue
+			}
+			d.delivered.Add(1)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, evt Event) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) recordFailure(evt Event, err error) {
+	d.failed.Add(1)
+	d.logger.Warn("webhook delivery failed", zap.String("event_type", evt.Type), zap.Error(err))
+
+	d.failuresMu.Lock()
+	defer d.failuresMu.Unlock()
+	d.recentFailures = append(d.recentFailures, Failure{
+		EventType: evt.Type,
+		Error:     err.Error(),
+		At:        time.Now().UTC(),
+	})
+	if len(d.recentFailures) > maxRecentFailures {
+		d.recentFailures = d.recentFailures[len(d.recentFailures)-maxRecentFailures:]
+	}
+}
+
+// Status returns the current queue depth, counters and most recent failures.
+func (d *Dispatcher) Status() Status {
+	d.failuresMu.Lock()
+	failures := make([]Failure, len(d.recentFailures))
+	copy(failures, d.recentFailures)
+	d.failuresMu.Unlock()
+
+	return Status{
+		QueueDepth:     len(d.queue),
+		QueueCapacity:  cap(d.queue),
+		DropPolicy:     d.policy,
+		Delivered:      d.delivered.Load(),
+		Dropped:        d.dropped.Load(),
+		Failed:         d.failed.Load(),
+		RecentFailures: failures,
+	}
+}
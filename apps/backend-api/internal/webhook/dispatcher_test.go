@@ -0,0 +1,157 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/webhook"
+	"ai-zombie-defense/backend-api/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_FloodDropsInsteadOfBlocking(t *testing.T) {
+	// The endpoint never answers, so at most one event is in flight
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	d := webhook.NewDispatcher(config.WebhookConfig{
+		URL:        slow.URL,
+		QueueSize:  10,
+		DropPolicy: webhook.DropOldest,
+		Timeout:    time.Minute,
+	}, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	const flood = 10000
+	start := time.Now()
+	for i := 0; i < flood; i++ {
+		d.Enqueue(webhook.Event{Type: "test", Payload: i})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Enqueue blocked on a slow endpoint: flood took %s", elapsed)
+	}
+
+	status := d.Status()
+	if status.QueueDepth > 10 {
+		t.Errorf("Expected queue depth <= 10, got %d", status.QueueDepth)
+	}
+	if status.Dropped < flood-10-1 {
+		t.Errorf("Expected at least %d dropped events, got %d", flood-10-1, status.Dropped)
+	}
+}
+
+func TestDispatcher_DropNewestRejectsWhenFull(t *testing.T) {
+	d := webhook.NewDispatcher(config.WebhookConfig{
+		URL:        "http://127.0.0.1:0",
+		QueueSize:  5,
+		DropPolicy: webhook.DropNewest,
+	}, zap.NewNop())
+
+	accepted := 0
+	for i := 0; i < 8; i++ {
+		if d.Enqueue(webhook.Event{Type: "test", Payload: i}) {
+			accepted++
+		}
+	}
+	if accepted != 5 {
+		t.Errorf("Expected 5 accepted events, got %d", accepted)
+	}
+	if status := d.Status(); status.Dropped != 3 || status.QueueDepth != 5 {
+		t.Errorf("Expected 3 dropped and depth 5, got %+v", status)
+	}
+}
+
+func TestDispatcher_DropOldestKeepsNewestEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evt struct {
+			Payload int `json:"payload"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&evt)
+		mu.Lock()
+		received = append(received, evt.Payload)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	d := webhook.NewDispatcher(config.WebhookConfig{
+		URL:        srv.URL,
+		QueueSize:  5,
+		DropPolicy: webhook.DropOldest,
+		Timeout:    time.Second,
+	}, zap.NewNop())
+	for i := 0; i < 20; i++ {
+		if !d.Enqueue(webhook.Event{Type: "test", Payload: i}) {
+			t.Fatalf("Expected event %d to be accepted under drop-oldest", i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+	waitFor(t, func() bool { return d.Status().Delivered == 5 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, payload := range received {
+		if payload != 15+i {
+			t.Errorf("Expected delivered events 15-19 in order, got %v", received)
+			break
+		}
+	}
+	if status := d.Status(); status.Dropped != 15 {
+		t.Errorf("Expected 15 dropped events, got %d", status.Dropped)
+	}
+}
+
+func TestDispatcher_RecordsDeliveryFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d := webhook.NewDispatcher(config.WebhookConfig{URL: srv.URL, QueueSize: 5, Timeout: time.Second}, zap.NewNop())
+	d.Enqueue(webhook.Event{Type: "match.completed"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+	waitFor(t, func() bool { return d.Status().Failed == 1 })
+
+	status := d.Status()
+	if len(status.RecentFailures) != 1 || status.RecentFailures[0].EventType != "match.completed" {
+		t.Errorf("Expected one recorded failure for match.completed, got %+v", status.RecentFailures)
+	}
+	if status.Delivered != 0 {
+		t.Errorf("Expected no delivered events, got %d", status.Delivered)
+	}
+}
@@ -0,0 +1,132 @@
+// Package websocket mounts github.com/gofiber/contrib/websocket handlers with the
+// gateway's conventions: plain requests get the JSON 426 error, incoming messages
+// are size-capped, and every connection is kept alive with pings so a client that
+// vanishes without a close frame is torn down instead of holding its goroutine.
+package websocket
+
+import (
+	"sync"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/api/apierror"
+
+	contribws "github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Conn is an upgraded WebSocket connection. ReadMessage must only be called from
+// one goroutine, and so must the write methods; control frames and Close may come
+// from any goroutine.
+type Conn = contribws.Conn
+
+// Message types, matching the frame opcodes
+const (
+	TextMessage   = contribws.TextMessage
+	BinaryMessage = contribws.BinaryMessage
+	CloseMessage  = contribws.CloseMessage
+	PingMessage   = contribws.PingMessage
+	PongMessage   = contribws.PongMessage
+)
+
+// Close status codes
+const (
+	CloseNormalClosure = contribws.CloseNormalClosure
+	CloseProtocolError = contribws.CloseProtocolError
+	CloseMessageTooBig = contribws.CloseMessageTooBig
+)
+
+// MaxMessageSize bounds one incoming message; larger ones close the connection.
+const MaxMessageSize = 64 << 10
+
+const (
+	// DefaultPongWait is how long a connection may go without any frame from the
+	// client before it is considered gone.
+	DefaultPongWait = 60 * time.Second
+	// DefaultPingInterval leaves the client time to answer a ping before PongWait runs out.
+	DefaultPingInterval = DefaultPongWait * 9 / 10
+	// writeWait bounds a single ping write.
+	writeWait = 10 * time.Second
+)
+
+// Config tunes the keepalive. Zero fields fall back to the defaults.
+type Config struct {
+	// PingInterval is how often the server pings the client.
+	PingInterval time.Duration
+	// PongWait is the read deadline, pushed back by every pong.
+	PongWait time.Duration
+}
+
+// IsWebSocketUpgrade reports whether the request asks to be upgraded to a WebSocket.
+func IsWebSocketUpgrade(c *fiber.Ctx) bool {
+	return contribws.IsWebSocketUpgrade(c)
+}
+
+// New returns a handler that upgrades the request and runs handler on the
+// connection, closing it when handler returns. Requests that aren't an upgrade
+// get 426 Upgrade Required. Locals set by earlier middleware under string keys
+// stay readable through Conn.Locals.
+//
+// The handler must keep reading (ReadMessage) for the keepalive to work: reads
+// process the client's pongs, and a read fails once PongWait passes without any.
+func New(handler func(*Conn), config ...Config) fiber.Handler {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = DefaultPongWait
+	}
+	if cfg.PingInterval <= 0 || cfg.PingInterval >= cfg.PongWait {
+		cfg.PingInterval = cfg.PongWait * 9 / 10
+	}
+
+	upgrade := contribws.New(func(conn *Conn) {
+		conn.SetReadLimit(MaxMessageSize)
+		extend := func() error {
+			return conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+		}
+		extend()
+		conn.SetPongHandler(func(string) error { return extend() })
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keepAlive(conn, cfg.PingInterval, stop)
+		}()
+		defer func() {
+			close(stop)
+			wg.Wait()
+			conn.Close()
+		}()
+
+		handler(conn)
+	})
+
+	return func(c *fiber.Ctx) error {
+		if !IsWebSocketUpgrade(c) {
+			c.Set("Sec-WebSocket-Version", "13")
+			return apierror.Respond(c, fiber.StatusUpgradeRequired, apierror.CodeUpgradeRequired, "WebSocket upgrade required")
+		}
+		return upgrade(c)
+	}
+}
+
+// keepAlive pings the client every interval until stop is closed. A failed ping
+// closes the connection, which also unblocks the handler's pending read.
+func keepAlive(conn *Conn, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteControl(PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				conn.Close()
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
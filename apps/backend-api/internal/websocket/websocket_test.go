@@ -0,0 +1,166 @@
+package websocket_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/websocket"
+
+	client "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+func startServer(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(ln)
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().String()
+}
+
+func dial(t *testing.T, addr, path string) *client.Conn {
+	t.Helper()
+	conn, _, err := client.DefaultDialer.Dial("ws://"+addr+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// echo answers every message until the connection fails, then reports on returned.
+func echo(returned chan<- struct{}) func(*websocket.Conn) {
+	return func(conn *websocket.Conn) {
+		defer close(returned)
+		for {
+			messageType, p, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, p); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestNewRejectsPlainRequests(t *testing.T) {
+	app := fiber.New()
+	app.Get("/ws", websocket.New(func(*websocket.Conn) {}))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("Expected status 426, got %d", resp.StatusCode)
+	}
+}
+
+func TestConnEcho(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", func(c *fiber.Ctx) error {
+		c.Locals("greeting", "hi")
+		return c.Next()
+	}, websocket.New(func(conn *websocket.Conn) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(conn.Locals("greeting").(string))); err != nil {
+			return
+		}
+		echo(make(chan struct{}))(conn)
+	}))
+	conn := dial(t, startServer(t, app), "/ws")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Locals from earlier middleware survive the upgrade
+	if messageType, p, err := conn.ReadMessage(); err != nil || messageType != websocket.TextMessage || string(p) != "hi" {
+		t.Errorf("Expected greeting, got type %d %q (err %v)", messageType, p, err)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("Failed to write message: %v", err)
+	}
+	if messageType, p, err := conn.ReadMessage(); err != nil || messageType != websocket.TextMessage || string(p) != "hello" {
+		t.Errorf("Expected echo, got type %d %q (err %v)", messageType, p, err)
+	}
+}
+
+func TestConnRejectsOversizedMessages(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	returned := make(chan struct{})
+	app.Get("/ws", websocket.New(echo(returned)))
+	conn := dial(t, startServer(t, app), "/ws")
+
+	if err := conn.WriteMessage(websocket.BinaryMessage, make([]byte, websocket.MaxMessageSize+1)); err != nil {
+		t.Fatalf("Failed to write message: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !client.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Errorf("Expected close 1009, got %v", err)
+	}
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the handler to return after an oversized message")
+	}
+}
+
+func TestKeepAliveDropsSilentClient(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	returned := make(chan struct{})
+	app.Get("/ws", websocket.New(echo(returned), websocket.Config{
+		PingInterval: 50 * time.Millisecond,
+		PongWait:     200 * time.Millisecond,
+	}))
+	conn := dial(t, startServer(t, app), "/ws")
+
+	// A client that vanished never answers pings
+	pings := 0
+	conn.SetPingHandler(func(string) error {
+		pings++
+		return nil
+	})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("Expected the server to close a client that stopped answering pings")
+	}
+	if pings == 0 {
+		t.Error("Expected the server to ping the client")
+	}
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the handler to return once the pong deadline passed")
+	}
+}
+
+func TestKeepAliveKeepsRespondingClient(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	returned := make(chan struct{})
+	app.Get("/ws", websocket.New(echo(returned), websocket.Config{
+		PingInterval: 50 * time.Millisecond,
+		PongWait:     200 * time.Millisecond,
+	}))
+	conn := dial(t, startServer(t, app), "/ws")
+
+	// Reading lets the default ping handler answer with pongs, well past PongWait
+	conn.SetReadDeadline(time.Now().Add(600 * time.Millisecond))
+	if _, _, err := conn.ReadMessage(); !isTimeout(err) {
+		t.Fatalf("Expected the connection to stay open, got %v", err)
+	}
+	select {
+	case <-returned:
+		t.Fatal("Expected the handler to keep running while the client answers pings")
+	default:
+	}
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
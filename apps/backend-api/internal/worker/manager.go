@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Manager owns the server's background goroutines. Every worker receives a
+// context that is cancelled on Shutdown, which then waits for all of them to
+// return so work isn't abandoned half-finished when the process exits.
+type Manager struct {
+	logger *zap.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+func NewManager(logger *zap.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs fn in a managed goroutine. fn must return once ctx is cancelled.
+// Calls made after Shutdown are ignored.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		m.logger.Warn("worker started after shutdown, ignoring", zap.String("worker", name))
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.logger.Debug("worker started", zap.String("worker", name))
+		fn(m.ctx)
+		m.logger.Debug("worker stopped", zap.String("worker", name))
+	}()
+}
+
+// Every runs fn on a fixed interval until shutdown. Errors are logged and the
+// worker keeps ticking.
+func (m *Manager) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	m.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil && ctx.Err() == nil {
+					m.logger.Error("worker run failed", zap.String("worker", name), zap.Error(err))
+				}
+			}
+		}
+	})
+}
+
+// Shutdown cancels all workers and waits for them to return, giving up when
+// ctx expires.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopped = true
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
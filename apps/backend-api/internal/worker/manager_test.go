@@ -0,0 +1,72 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ai-zombie-defense/backend-api/internal/worker"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestManager_ShutdownStopsWorkers(t *testing.T) {
+	m := worker.NewManager(zaptest.NewLogger(t))
+
+	var ticks atomic.Int64
+	stopped := make(chan struct{})
+	m.Go("blocking", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	m.Every("ticker", 5*time.Millisecond, func(ctx context.Context) error {
+		ticks.Add(1)
+		return nil
+	})
+
+	time.Sleep(30 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected workers to stop promptly, took %v", elapsed)
+	}
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("Expected blocking worker to observe cancellation")
+	}
+	if ticks.Load() == 0 {
+		t.Error("Expected periodic worker to have run before shutdown")
+	}
+	after := ticks.Load()
+	time.Sleep(20 * time.Millisecond)
+	if ticks.Load() != after {
+		t.Error("Expected periodic worker to stop ticking after shutdown")
+	}
+}
+
+func TestManager_ShutdownTimesOut(t *testing.T) {
+	// The stuck worker outlives the test, so it can't log through t
+	m := worker.NewManager(zap.NewNop())
+
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE server_joins (
+    server_join_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    joined_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (server_id) REFERENCES servers (server_id) ON DELETE CASCADE,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_server_joins_server_id_joined_at ON server_joins(server_id, joined_at);
+CREATE INDEX idx_server_joins_player_id ON server_joins(player_id);
+
+-- +goose Down
+DROP INDEX idx_server_joins_player_id;
+DROP INDEX idx_server_joins_server_id_joined_at;
+DROP TABLE server_joins;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE notifications (
+    notification_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    notification_type TEXT NOT NULL,
+    message TEXT NOT NULL,
+    reference_id INTEGER,
+    is_read INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    read_at TEXT,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_player_id_is_read ON notifications(player_id, is_read, created_at);
+
+-- +goose Down
+DROP INDEX idx_notifications_player_id_is_read;
+DROP TABLE notifications;
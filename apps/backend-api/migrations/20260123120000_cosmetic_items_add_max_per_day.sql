@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE cosmetic_items ADD COLUMN max_per_day INTEGER;
+
+-- +goose Down
+ALTER TABLE cosmetic_items DROP COLUMN max_per_day;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE player_settings ADD COLUMN allow_friend_requests INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE player_settings ADD COLUMN show_on_leaderboard INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE player_settings ADD COLUMN match_history_public INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE player_settings DROP COLUMN match_history_public;
+ALTER TABLE player_settings DROP COLUMN show_on_leaderboard;
+ALTER TABLE player_settings DROP COLUMN allow_friend_requests;
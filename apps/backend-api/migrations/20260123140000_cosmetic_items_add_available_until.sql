@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE cosmetic_items ADD COLUMN available_until TEXT;
+
+-- +goose Down
+ALTER TABLE cosmetic_items DROP COLUMN available_until;
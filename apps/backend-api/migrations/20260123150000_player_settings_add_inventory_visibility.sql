@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE player_settings ADD COLUMN inventory_visible_to_friends INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE player_settings DROP COLUMN inventory_visible_to_friends;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE email_change_tokens (
+    email_change_token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token TEXT NOT NULL UNIQUE,
+    player_id INTEGER NOT NULL,
+    new_email TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    used_at TEXT,
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_change_tokens_player_id ON email_change_tokens(player_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_email_change_tokens_player_id;
+DROP TABLE IF EXISTS email_change_tokens;
@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE referrals (
+    referral_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    referrer_id INTEGER NOT NULL,
+    referred_id INTEGER NOT NULL UNIQUE,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    CHECK (referrer_id != referred_id),
+    FOREIGN KEY (referrer_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (referred_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_referrals_referrer_id ON referrals(referrer_id);
+
+-- SQLite cannot alter a CHECK constraint, so player_cosmetics is rebuilt to
+-- allow cosmetics unlocked through referral rewards.
+CREATE TABLE player_cosmetics_new (
+    player_id INTEGER NOT NULL,
+    cosmetic_id INTEGER NOT NULL,
+    unlocked_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral')),
+    PRIMARY KEY (player_id, cosmetic_id),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+);
+
+INSERT INTO player_cosmetics_new (player_id, cosmetic_id, unlocked_at, unlocked_via)
+SELECT player_id, cosmetic_id, unlocked_at, unlocked_via FROM player_cosmetics;
+
+DROP INDEX IF EXISTS idx_player_cosmetics_cosmetic_id;
+DROP TABLE player_cosmetics;
+ALTER TABLE player_cosmetics_new RENAME TO player_cosmetics;
+CREATE INDEX idx_player_cosmetics_cosmetic_id ON player_cosmetics (cosmetic_id);
+
+-- +goose Down
+CREATE TABLE player_cosmetics_old (
+    player_id INTEGER NOT NULL,
+    cosmetic_id INTEGER NOT NULL,
+    unlocked_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    unlocked_via TEXT NOT NULL CHECK (unlocked_via IN ('level_up', 'purchase', 'loot_drop', 'prestige')),
+    PRIMARY KEY (player_id, cosmetic_id),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+);
+
+INSERT INTO player_cosmetics_old (player_id, cosmetic_id, unlocked_at, unlocked_via)
+SELECT player_id, cosmetic_id, unlocked_at, unlocked_via FROM player_cosmetics
+WHERE unlocked_via != 'referral';
+
+DROP INDEX IF EXISTS idx_player_cosmetics_cosmetic_id;
+DROP TABLE player_cosmetics;
+ALTER TABLE player_cosmetics_old RENAME TO player_cosmetics;
+CREATE INDEX idx_player_cosmetics_cosmetic_id ON player_cosmetics (cosmetic_id);
+
+DROP INDEX IF EXISTS idx_referrals_referrer_id;
+DROP TABLE IF EXISTS referrals;
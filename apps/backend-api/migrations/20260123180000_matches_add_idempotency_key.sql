@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE matches ADD COLUMN idempotency_key TEXT;
+CREATE UNIQUE INDEX idx_matches_server_idempotency_key ON matches(server_id, idempotency_key);
+
+-- +goose Down
+DROP INDEX idx_matches_server_idempotency_key;
+ALTER TABLE matches DROP COLUMN idempotency_key;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN started_at TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN started_at;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE player_reports (
+    report_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    reporter_id INTEGER NOT NULL,
+    target_id INTEGER NOT NULL,
+    category TEXT NOT NULL CHECK (category IN ('cheating', 'abuse', 'griefing', 'other')),
+    reason TEXT,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    resolved_at TEXT,
+    resolved_by INTEGER,
+    CHECK (reporter_id != target_id),
+    FOREIGN KEY (reporter_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (target_id) REFERENCES players (player_id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES players (player_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_player_reports_target_id_status ON player_reports(target_id, status);
+CREATE INDEX idx_player_reports_reporter_id_created_at ON player_reports(reporter_id, created_at);
+-- A reporter has at most one open report against the same player.
+CREATE UNIQUE INDEX idx_player_reports_open_pair ON player_reports(reporter_id, target_id) WHERE status = 'open';
+
+-- +goose Down
+DROP TABLE player_reports;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE loot_pity (
+    player_id INTEGER PRIMARY KEY,
+    unlucky_rolls INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE loot_pity;
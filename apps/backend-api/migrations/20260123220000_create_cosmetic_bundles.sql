@@ -0,0 +1,71 @@
+-- +goose Up
+CREATE TABLE cosmetic_bundles (
+    bundle_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE cosmetic_bundle_items (
+    bundle_id INTEGER NOT NULL,
+    cosmetic_id INTEGER NOT NULL,
+    PRIMARY KEY (bundle_id, cosmetic_id),
+    FOREIGN KEY (bundle_id) REFERENCES cosmetic_bundles (bundle_id) ON DELETE CASCADE,
+    FOREIGN KEY (cosmetic_id) REFERENCES cosmetic_items (cosmetic_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_cosmetic_bundle_items_cosmetic_id ON cosmetic_bundle_items (cosmetic_id);
+
+-- SQLite cannot alter a CHECK constraint, so currency_transactions is rebuilt to
+-- allow bundle purchases, which reference a bundle rather than a cosmetic.
+CREATE TABLE currency_transactions_new (
+    transaction_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'bundle_purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+    reference_id INTEGER,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+INSERT INTO currency_transactions_new (transaction_id, player_id, amount, balance_after, transaction_type, reference_id, created_at)
+SELECT transaction_id, player_id, amount, balance_after, transaction_type, reference_id, created_at FROM currency_transactions;
+
+DROP INDEX IF EXISTS idx_currency_transactions_player_id;
+DROP INDEX IF EXISTS idx_currency_transactions_created_at;
+DROP TABLE currency_transactions;
+ALTER TABLE currency_transactions_new RENAME TO currency_transactions;
+CREATE INDEX idx_currency_transactions_player_id ON currency_transactions (player_id);
+CREATE INDEX idx_currency_transactions_created_at ON currency_transactions (created_at);
+
+-- +goose Down
+CREATE TABLE currency_transactions_old (
+    transaction_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('match_reward', 'purchase', 'prestige_reward', 'admin_grant', 'refund', 'other')),
+    reference_id INTEGER,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+INSERT INTO currency_transactions_old (transaction_id, player_id, amount, balance_after, transaction_type, reference_id, created_at)
+SELECT transaction_id, player_id, amount, balance_after,
+    CASE WHEN transaction_type = 'bundle_purchase' THEN 'other' ELSE transaction_type END,
+    reference_id, created_at
+FROM currency_transactions;
+
+DROP INDEX IF EXISTS idx_currency_transactions_player_id;
+DROP INDEX IF EXISTS idx_currency_transactions_created_at;
+DROP TABLE currency_transactions;
+ALTER TABLE currency_transactions_old RENAME TO currency_transactions;
+CREATE INDEX idx_currency_transactions_player_id ON currency_transactions (player_id);
+CREATE INDEX idx_currency_transactions_created_at ON currency_transactions (created_at);
+
+DROP INDEX IF EXISTS idx_cosmetic_bundle_items_cosmetic_id;
+DROP TABLE IF EXISTS cosmetic_bundle_items;
+DROP TABLE IF EXISTS cosmetic_bundles;
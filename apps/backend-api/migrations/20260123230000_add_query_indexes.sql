@@ -0,0 +1,10 @@
+-- +goose Up
+-- sessions(token) and players(email) are already covered by their UNIQUE
+-- constraints and player_match_stats(match_id) by its own migration, so only
+-- the time-windowed leaderboards and the server browser need new indexes.
+CREATE INDEX idx_matches_start_time ON matches(start_time);
+CREATE INDEX idx_servers_is_online ON servers(is_online);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_servers_is_online;
+DROP INDEX IF EXISTS idx_matches_start_time;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Materialized rankings written by the leaderboard refresh job, one set of
+-- rows per period and metric, all sharing the generated_at of their refresh.
+CREATE TABLE leaderboard_snapshots (
+    period TEXT NOT NULL CHECK (period IN ('daily', 'weekly', 'alltime')),
+    metric TEXT NOT NULL,
+    ranking INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    total_score INTEGER NOT NULL,
+    matches_played INTEGER NOT NULL,
+    avg_kills_per_match REAL,
+    avg_waves_survived REAL,
+    metric_value INTEGER NOT NULL,
+    generated_at TEXT NOT NULL,
+    PRIMARY KEY (period, metric, ranking),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_leaderboard_snapshots_player_id ON leaderboard_snapshots(period, metric, player_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_leaderboard_snapshots_player_id;
+DROP TABLE leaderboard_snapshots;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Emails are stored lowercased from now on. Rows whose email matches another
+-- account's case-insensitively are left untouched, since lowercasing them would
+-- violate UNIQUE(email); such accounts must be merged or renamed by hand.
+UPDATE players SET email = lower(email)
+WHERE email <> lower(email)
+  AND NOT EXISTS (
+    SELECT 1 FROM players other
+    WHERE lower(other.email) = lower(players.email)
+      AND other.player_id <> players.player_id
+  );
+
+-- +goose Down
+-- The original casing is not kept, so there is nothing to restore.
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE login_failures (
+    player_id INTEGER PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until TEXT,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (player_id) REFERENCES players (player_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE login_failures;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Concurrent equips could create several active loadouts for a player; keep
+-- the oldest active one before enforcing at most one.
+UPDATE loadouts SET is_active = 0
+WHERE is_active = 1
+    AND loadout_id > (
+        SELECT MIN(l.loadout_id) FROM loadouts l
+        WHERE l.player_id = loadouts.player_id AND l.is_active = 1
+    );
+
+CREATE UNIQUE INDEX idx_loadouts_one_active ON loadouts(player_id) WHERE is_active = 1;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_loadouts_one_active;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- NULL means any owner can equip the item; otherwise only players who
+-- unlocked it through the named method can.
+ALTER TABLE cosmetic_items ADD COLUMN equip_restriction TEXT
+    CHECK (equip_restriction IN ('level_up', 'purchase', 'loot_drop', 'prestige', 'referral'));
+
+-- +goose Down
+ALTER TABLE cosmetic_items DROP COLUMN equip_restriction;
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,10 +11,21 @@ import (
 
 // Config holds all configuration for the application.
 type Config struct {
-	Database    DatabaseConfig
-	Server      ServerConfig
-	JWT         JWTConfig
-	Progression ProgressionConfig
+	Database     DatabaseConfig
+	Server       ServerConfig
+	JWT          JWTConfig
+	Progression  ProgressionConfig
+	GameServer   GameServerConfig
+	Notification NotificationConfig
+	Cosmetics    CosmeticsConfig
+	Webhook      WebhookConfig
+	Account      AccountConfig
+	Leaderboard  LeaderboardConfig
+	Admin        AdminConfig
+	Moderation   ModerationConfig
+	Loot         LootConfig
+	Social       SocialConfig
+	Metrics      MetricsConfig
 }
 
 // DatabaseConfig holds database connection settings.
@@ -32,6 +45,15 @@ type ServerConfig struct {
 	CORSAllowOrigins  string
 	RateLimitMax      int
 	RateLimitDuration time.Duration
+	// AuthRateLimitMax caps login and registration attempts per client IP and per account
+	// name in each AuthRateLimitDuration, on top of the global limit (0 disables it).
+	AuthRateLimitMax      int
+	AuthRateLimitDuration time.Duration
+	// BlockedCountries lists ISO country codes denied access to /auth routes (empty disables geoblocking).
+	BlockedCountries []string
+	// ShutdownTimeout bounds graceful shutdown: draining in-flight requests and
+	// stopping background workers.
+	ShutdownTimeout time.Duration
 }
 
 // JWTConfig holds JWT token generation and validation settings.
@@ -39,12 +61,197 @@ type JWTConfig struct {
 	Secret            string
 	AccessExpiration  time.Duration
 	RefreshExpiration time.Duration
+	// RefreshSliding restarts the refresh expiry from each successful refresh instead of
+	// keeping the expiry set at login.
+	RefreshSliding bool
+	// RefreshMaxLifetime caps how long after login a sliding session can be kept alive; 0 disables the cap.
+	RefreshMaxLifetime time.Duration
 }
 
 // ProgressionConfig holds player progression settings.
 type ProgressionConfig struct {
-	// BaseXPPerLevel is the base XP required to reach the next level (linear scaling).
+	// BaseXPPerLevel is the XP cost of each level on a linear curve, and of level 2 on an exponential one.
 	BaseXPPerLevel int
+	// CurveType selects the XP curve: "linear" (default when empty), "exponential" or "table".
+	CurveType string
+	// CurveGrowthFactor multiplies each successive level's XP cost on an exponential curve.
+	CurveGrowthFactor float64
+	// XPTable lists cumulative XP thresholds for a table curve; XPTable[0] is the XP needed for level 2.
+	XPTable []int64
+	// PrestigeBackfillBatchSize is how many players are granted per transaction when backfilling prestige cosmetics.
+	PrestigeBackfillBatchSize int
+	// XPRewards is the match reward XP formula.
+	XPRewards XPRewardsConfig
+	// RewardDecay reduces rewards for players farming many short matches in a row.
+	RewardDecay RewardDecayConfig
+	// StatCaps bounds the per-wave stats a match submission may claim.
+	StatCaps StatCapsConfig
+}
+
+// XPRewardsConfig holds the XP awarded per match and per unit of each player stat.
+type XPRewardsConfig struct {
+	BaseXP     int64
+	PerKill    int64
+	PerWave    int64
+	PerScrap   int64
+	PerRevive  int64
+	PerHealing int64
+}
+
+// RewardDecayConfig holds the diminishing returns applied to rapid short matches.
+type RewardDecayConfig struct {
+	// ShortMatchDuration is the match length below which a match counts as short (zero disables decay).
+	ShortMatchDuration time.Duration
+	// Window is how far back from a match's end time earlier short matches are counted.
+	Window time.Duration
+	// FreeMatches is how many short matches within the window earn full rewards before decay starts.
+	FreeMatches int
+	// Factor multiplies rewards once per short match beyond FreeMatches.
+	Factor float64
+	// MinMultiplier is the floor the decayed reward multiplier never drops below.
+	MinMultiplier float64
+}
+
+// StatCapsConfig holds the most of each stat a player can plausibly earn per
+// wave survived (a match counts as at least one wave). Zero disables a cap.
+type StatCapsConfig struct {
+	MaxKillsPerWave int64
+	MaxScrapPerWave int64
+	MaxDataPerWave  int64
+}
+
+// GameServerConfig holds dedicated game server registry settings.
+type GameServerConfig struct {
+	// JoinHistoryRetention is how long server join records are kept before being pruned.
+	JoinHistoryRetention time.Duration
+	// JoinHistoryMaxPerServer caps the number of join records kept per server.
+	JoinHistoryMaxPerServer int
+	// AllowHostnames lets servers register with a DNS hostname instead of an IP address.
+	AllowHostnames bool
+	// JoinTokenExpiration is how long a player's join token stays valid.
+	JoinTokenExpiration time.Duration
+	// HeartbeatTTL is how long a server stays online after its last heartbeat.
+	HeartbeatTTL time.Duration
+	// HeartbeatSweepInterval is how often servers past HeartbeatTTL are marked offline (0 disables the sweeper).
+	HeartbeatSweepInterval time.Duration
+}
+
+// NotificationConfig holds in-client notification settings.
+type NotificationConfig struct {
+	// MaxUnreadPerPlayer caps unread notifications per player; the oldest unread are dropped first.
+	MaxUnreadPerPlayer int
+}
+
+// CosmeticsConfig holds cosmetic store settings.
+type CosmeticsConfig struct {
+	// UndoWindow is how long after a purchase the player may undo it for a full refund.
+	UndoWindow time.Duration
+	// DefaultSlotCosmetics maps a slot to the cosmetic ID shown in public loadouts when that slot is empty.
+	DefaultSlotCosmetics map[string]int64
+	// BundleProRate charges a partially owned bundle only for the share of items still missing;
+	// when false the full bundle price is charged regardless of ownership.
+	BundleProRate bool
+	// FeaturedCosmetics lists cosmetic IDs highlighted at the top of the store, in display order.
+	FeaturedCosmetics []int64
+}
+
+// WebhookConfig holds outbound webhook dispatcher settings.
+type WebhookConfig struct {
+	// URL receives event POSTs; the dispatcher is disabled when empty.
+	URL string
+	// QueueSize bounds the number of undelivered events held in memory.
+	QueueSize int
+	// DropPolicy is "oldest" (evict the oldest queued event) or "newest" (reject the incoming one) when the queue is full.
+	DropPolicy string
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+}
+
+// AccountConfig holds player account management settings.
+type AccountConfig struct {
+	// EmailChangeTokenExpiry is how long an email change verification token stays valid.
+	EmailChangeTokenExpiry time.Duration
+	// PasswordPolicy is enforced on the password at registration and on password changes.
+	PasswordPolicy PasswordPolicy
+	// LoginLockoutThreshold is how many consecutive wrong passwords lock an account (0 disables lockout).
+	LoginLockoutThreshold int
+	// LoginLockoutDuration is the length of the first lockout; each further lockout before a
+	// successful login doubles it.
+	LoginLockoutDuration time.Duration
+	// LoginLockoutMaxDuration caps a lockout, so failures caused by someone else can't keep the
+	// owner out for longer.
+	LoginLockoutMaxDuration time.Duration
+	// ReferralClaimWindow is how long after registration a player may claim a referral.
+	ReferralClaimWindow time.Duration
+	// ReferralCurrencyReward is the data currency granted to both players on a referral claim.
+	ReferralCurrencyReward int64
+	// ReferralCosmeticID is the cosmetic granted to both players on a referral claim (0 disables it).
+	ReferralCosmeticID int64
+}
+
+// PasswordPolicy holds the strength rules a new password must meet.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// RequireLetter, RequireDigit and RequireSymbol each demand at least one character of that class.
+	RequireLetter bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// LeaderboardConfig holds leaderboard period and snapshot settings.
+type LeaderboardConfig struct {
+	// WeeklyMode is "calendar" (matches since Monday 00:00 UTC) or "rolling" (matches in the last 7 days).
+	WeeklyMode string
+	// RefreshInterval is how often the background job rewrites the leaderboard snapshots (0 disables it).
+	RefreshInterval time.Duration
+	// SnapshotMaxAge is how old a snapshot may be before reads fall back to computing the board live.
+	SnapshotMaxAge time.Duration
+}
+
+// AdminConfig holds settings for admin-only operations.
+type AdminConfig struct {
+	// OperationConcurrency caps how many runs of each named admin operation may be in flight at once.
+	OperationConcurrency map[string]int
+	// DefaultOperationConcurrency applies to operations missing from OperationConcurrency.
+	DefaultOperationConcurrency int
+	// StatusCacheTTL is how long AdminMiddleware trusts a cached is_admin lookup (0 disables the cache).
+	StatusCacheTTL time.Duration
+}
+
+// ModerationConfig holds community moderation settings.
+type ModerationConfig struct {
+	// ReportsPerDay caps how many player reports one player may submit in a rolling 24 hours.
+	ReportsPerDay int
+}
+
+// LootConfig holds loot drop settings.
+type LootConfig struct {
+	// PityThreshold is how many drops in a row without a PityMinRarity or better
+	// cosmetic force the next drop to be one; 0 disables pity.
+	PityThreshold int
+	// PityMinRarity is the lowest rarity that a pity drop grants and that resets the counter.
+	PityMinRarity string
+	// GuaranteedDrop normalizes table drop chances so every loot roll drops something.
+	GuaranteedDrop bool
+	// DuplicateLootRefund maps a rarity to the data currency credited per unit when a drop
+	// is a cosmetic the player already owns; rarities without an entry are not refunded.
+	DuplicateLootRefund map[string]int64
+}
+
+// SocialConfig holds friends system settings.
+type SocialConfig struct {
+	// EventsHeartbeatInterval is how often GET /friends/events writes a comment line to keep idle streams open (0 disables it).
+	EventsHeartbeatInterval time.Duration
+}
+
+// MetricsConfig holds Prometheus metrics settings.
+type MetricsConfig struct {
+	// Enabled turns on request instrumentation and the /metrics endpoint.
+	Enabled bool
+	// Addr, when set, serves /metrics on its own listener (e.g. "127.0.0.1:9090")
+	// instead of the public API port.
+	Addr string
 }
 
 // LoadConfig loads configuration from environment variables and defaults.
@@ -67,6 +274,83 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	xpTable, err := parseXPTable(v.GetString("progression_xp_table"))
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSlotCosmetics, err := parseSlotCosmetics(v.GetString("cosmetics_default_slot_cosmetics"))
+	if err != nil {
+		return nil, err
+	}
+
+	featuredCosmetics, err := parseCosmeticIDs(v.GetString("cosmetics_featured"))
+	if err != nil {
+		return nil, err
+	}
+
+	operationConcurrency, err := parseOperationConcurrency(v.GetString("admin_operation_concurrency"))
+	if err != nil {
+		return nil, err
+	}
+
+	if v.GetDuration("server_shutdown_timeout") <= 0 {
+		return nil, fmt.Errorf("SERVER_SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	if v.GetInt("db_max_open_conns") <= 0 {
+		return nil, fmt.Errorf("DB_MAX_OPEN_CONNS must be positive")
+	}
+
+	if v.GetInt("admin_default_operation_concurrency") <= 0 {
+		return nil, fmt.Errorf("ADMIN_DEFAULT_OPERATION_CONCURRENCY must be positive")
+	}
+
+	if policy := v.GetString("webhook_drop_policy"); policy != "oldest" && policy != "newest" {
+		return nil, fmt.Errorf("WEBHOOK_DROP_POLICY must be \"oldest\" or \"newest\", got %q", policy)
+	}
+
+	if mode := v.GetString("leaderboard_weekly_mode"); mode != "calendar" && mode != "rolling" {
+		return nil, fmt.Errorf("LEADERBOARD_WEEKLY_MODE must be \"calendar\" or \"rolling\", got %q", mode)
+	}
+
+	if v.GetDuration("jwt_refresh_max_lifetime") < 0 {
+		return nil, fmt.Errorf("JWT_REFRESH_MAX_LIFETIME cannot be negative")
+	}
+
+	if v.GetInt("auth_rate_limit_max") < 0 {
+		return nil, fmt.Errorf("AUTH_RATE_LIMIT_MAX cannot be negative")
+	}
+
+	if v.GetInt("account_password_min_length") < 0 {
+		return nil, fmt.Errorf("ACCOUNT_PASSWORD_MIN_LENGTH cannot be negative")
+	}
+
+	if v.GetInt("account_login_lockout_threshold") < 0 {
+		return nil, fmt.Errorf("ACCOUNT_LOGIN_LOCKOUT_THRESHOLD cannot be negative")
+	}
+
+	if v.GetDuration("account_login_lockout_max_duration") < v.GetDuration("account_login_lockout_duration") {
+		return nil, fmt.Errorf("ACCOUNT_LOGIN_LOCKOUT_MAX_DURATION cannot be shorter than ACCOUNT_LOGIN_LOCKOUT_DURATION")
+	}
+
+	if v.GetInt("moderation_reports_per_day") <= 0 {
+		return nil, fmt.Errorf("MODERATION_REPORTS_PER_DAY must be positive")
+	}
+
+	if v.GetInt("loot_pity_threshold") < 0 {
+		return nil, fmt.Errorf("LOOT_PITY_THRESHOLD cannot be negative")
+	}
+
+	if rarity := v.GetString("loot_pity_min_rarity"); !isRarity(rarity) {
+		return nil, fmt.Errorf("LOOT_PITY_MIN_RARITY must be a cosmetic rarity, got %q", rarity)
+	}
+
+	duplicateLootRefund, err := parseRarityAmounts(v.GetString("loot_duplicate_refund"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Build config struct
 	cfg := &Config{
 		Database: DatabaseConfig{
@@ -78,19 +362,113 @@ func LoadConfig() (*Config, error) {
 			MigrationsPath:  v.GetString("db_migrations_path"),
 		},
 		Server: ServerConfig{
-			Host:              v.GetString("server_host"),
-			Port:              v.GetInt("server_port"),
-			CORSAllowOrigins:  v.GetString("cors_allow_origins"),
-			RateLimitMax:      v.GetInt("rate_limit_max"),
-			RateLimitDuration: v.GetDuration("rate_limit_duration"),
+			Host:                  v.GetString("server_host"),
+			Port:                  v.GetInt("server_port"),
+			CORSAllowOrigins:      v.GetString("cors_allow_origins"),
+			RateLimitMax:          v.GetInt("rate_limit_max"),
+			RateLimitDuration:     v.GetDuration("rate_limit_duration"),
+			AuthRateLimitMax:      v.GetInt("auth_rate_limit_max"),
+			AuthRateLimitDuration: v.GetDuration("auth_rate_limit_duration"),
+			BlockedCountries:      parseList(v.GetString("blocked_countries")),
+			ShutdownTimeout:       v.GetDuration("server_shutdown_timeout"),
 		},
 		JWT: JWTConfig{
-			Secret:            v.GetString("jwt_secret"),
-			AccessExpiration:  v.GetDuration("jwt_access_expiration"),
-			RefreshExpiration: v.GetDuration("jwt_refresh_expiration"),
+			Secret:             v.GetString("jwt_secret"),
+			AccessExpiration:   v.GetDuration("jwt_access_expiration"),
+			RefreshExpiration:  v.GetDuration("jwt_refresh_expiration"),
+			RefreshSliding:     v.GetBool("jwt_refresh_sliding"),
+			RefreshMaxLifetime: v.GetDuration("jwt_refresh_max_lifetime"),
 		},
 		Progression: ProgressionConfig{
-			BaseXPPerLevel: v.GetInt("progression_base_xp_per_level"),
+			BaseXPPerLevel:            v.GetInt("progression_base_xp_per_level"),
+			CurveType:                 v.GetString("progression_curve_type"),
+			CurveGrowthFactor:         v.GetFloat64("progression_curve_growth_factor"),
+			XPTable:                   xpTable,
+			PrestigeBackfillBatchSize: v.GetInt("progression_prestige_backfill_batch_size"),
+			XPRewards: XPRewardsConfig{
+				BaseXP:     v.GetInt64("progression_xp_reward_base"),
+				PerKill:    v.GetInt64("progression_xp_reward_per_kill"),
+				PerWave:    v.GetInt64("progression_xp_reward_per_wave"),
+				PerScrap:   v.GetInt64("progression_xp_reward_per_scrap"),
+				PerRevive:  v.GetInt64("progression_xp_reward_per_revive"),
+				PerHealing: v.GetInt64("progression_xp_reward_per_healing"),
+			},
+			RewardDecay: RewardDecayConfig{
+				ShortMatchDuration: v.GetDuration("progression_reward_decay_short_match_duration"),
+				Window:             v.GetDuration("progression_reward_decay_window"),
+				FreeMatches:        v.GetInt("progression_reward_decay_free_matches"),
+				Factor:             v.GetFloat64("progression_reward_decay_factor"),
+				MinMultiplier:      v.GetFloat64("progression_reward_decay_min_multiplier"),
+			},
+			StatCaps: StatCapsConfig{
+				MaxKillsPerWave: v.GetInt64("progression_stat_caps_max_kills_per_wave"),
+				MaxScrapPerWave: v.GetInt64("progression_stat_caps_max_scrap_per_wave"),
+				MaxDataPerWave:  v.GetInt64("progression_stat_caps_max_data_per_wave"),
+			},
+		},
+		GameServer: GameServerConfig{
+			JoinHistoryRetention:    v.GetDuration("game_server_join_history_retention"),
+			JoinHistoryMaxPerServer: v.GetInt("game_server_join_history_max_per_server"),
+			AllowHostnames:          v.GetBool("game_server_allow_hostnames"),
+			JoinTokenExpiration:     v.GetDuration("game_server_join_token_expiration"),
+			HeartbeatTTL:            v.GetDuration("game_server_heartbeat_ttl"),
+			HeartbeatSweepInterval:  v.GetDuration("game_server_heartbeat_sweep_interval"),
+		},
+		Notification: NotificationConfig{
+			MaxUnreadPerPlayer: v.GetInt("notification_max_unread_per_player"),
+		},
+		Cosmetics: CosmeticsConfig{
+			UndoWindow:           v.GetDuration("cosmetics_undo_window"),
+			DefaultSlotCosmetics: defaultSlotCosmetics,
+			BundleProRate:        v.GetBool("cosmetics_bundle_pro_rate"),
+			FeaturedCosmetics:    featuredCosmetics,
+		},
+		Webhook: WebhookConfig{
+			URL:        v.GetString("webhook_url"),
+			QueueSize:  v.GetInt("webhook_queue_size"),
+			DropPolicy: v.GetString("webhook_drop_policy"),
+			Timeout:    v.GetDuration("webhook_timeout"),
+		},
+		Account: AccountConfig{
+			EmailChangeTokenExpiry: v.GetDuration("account_email_change_token_expiry"),
+			PasswordPolicy: PasswordPolicy{
+				MinLength:     v.GetInt("account_password_min_length"),
+				RequireLetter: v.GetBool("account_password_require_letter"),
+				RequireDigit:  v.GetBool("account_password_require_digit"),
+				RequireSymbol: v.GetBool("account_password_require_symbol"),
+			},
+			LoginLockoutThreshold:   v.GetInt("account_login_lockout_threshold"),
+			LoginLockoutDuration:    v.GetDuration("account_login_lockout_duration"),
+			LoginLockoutMaxDuration: v.GetDuration("account_login_lockout_max_duration"),
+			ReferralClaimWindow:     v.GetDuration("account_referral_claim_window"),
+			ReferralCurrencyReward:  v.GetInt64("account_referral_currency_reward"),
+			ReferralCosmeticID:      v.GetInt64("account_referral_cosmetic_id"),
+		},
+		Leaderboard: LeaderboardConfig{
+			WeeklyMode:      v.GetString("leaderboard_weekly_mode"),
+			RefreshInterval: v.GetDuration("leaderboard_refresh_interval"),
+			SnapshotMaxAge:  v.GetDuration("leaderboard_snapshot_max_age"),
+		},
+		Admin: AdminConfig{
+			OperationConcurrency:        operationConcurrency,
+			DefaultOperationConcurrency: v.GetInt("admin_default_operation_concurrency"),
+			StatusCacheTTL:              v.GetDuration("admin_status_cache_ttl"),
+		},
+		Moderation: ModerationConfig{
+			ReportsPerDay: v.GetInt("moderation_reports_per_day"),
+		},
+		Loot: LootConfig{
+			PityThreshold:       v.GetInt("loot_pity_threshold"),
+			PityMinRarity:       v.GetString("loot_pity_min_rarity"),
+			GuaranteedDrop:      v.GetBool("loot_guaranteed_drop"),
+			DuplicateLootRefund: duplicateLootRefund,
+		},
+		Social: SocialConfig{
+			EventsHeartbeatInterval: v.GetDuration("social_events_heartbeat_interval"),
+		},
+		Metrics: MetricsConfig{
+			Enabled: v.GetBool("metrics_enabled"),
+			Addr:    v.GetString("metrics_addr"),
 		},
 	}
 
@@ -112,13 +490,95 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("cors_allow_origins", "*")
 	v.SetDefault("rate_limit_max", 10)
 	v.SetDefault("rate_limit_duration", 1*time.Minute)
+	v.SetDefault("auth_rate_limit_max", 5)
+	v.SetDefault("auth_rate_limit_duration", 1*time.Minute)
+	v.SetDefault("server_shutdown_timeout", 10*time.Second)
 
 	// JWT defaults
 	v.SetDefault("jwt_access_expiration", 15*time.Minute)
 	v.SetDefault("jwt_refresh_expiration", 7*24*time.Hour) // 7 days
+	v.SetDefault("jwt_refresh_sliding", false)
+	v.SetDefault("jwt_refresh_max_lifetime", 30*24*time.Hour)
 
 	// Progression defaults
 	v.SetDefault("progression_base_xp_per_level", 1000)
+	v.SetDefault("progression_curve_type", "linear")
+	v.SetDefault("progression_curve_growth_factor", 1.15)
+	v.SetDefault("progression_prestige_backfill_batch_size", 500)
+	v.SetDefault("progression_xp_reward_base", 100)
+	v.SetDefault("progression_xp_reward_per_kill", 10)
+	v.SetDefault("progression_xp_reward_per_wave", 50)
+	v.SetDefault("progression_xp_reward_per_scrap", 1)
+	v.SetDefault("progression_xp_reward_per_revive", 0)
+	v.SetDefault("progression_xp_reward_per_healing", 0)
+	v.SetDefault("progression_reward_decay_short_match_duration", 5*time.Minute)
+	v.SetDefault("progression_reward_decay_window", time.Hour)
+	v.SetDefault("progression_reward_decay_free_matches", 3)
+	v.SetDefault("progression_reward_decay_factor", 0.5)
+	v.SetDefault("progression_reward_decay_min_multiplier", 0.1)
+	v.SetDefault("progression_stat_caps_max_kills_per_wave", 500)
+	v.SetDefault("progression_stat_caps_max_scrap_per_wave", 5000)
+	v.SetDefault("progression_stat_caps_max_data_per_wave", 500)
+
+	// Game server defaults
+	v.SetDefault("game_server_join_history_retention", 30*24*time.Hour) // 30 days
+	v.SetDefault("game_server_join_history_max_per_server", 1000)
+	v.SetDefault("game_server_allow_hostnames", false)
+	v.SetDefault("game_server_join_token_expiration", 30*time.Second)
+	v.SetDefault("game_server_heartbeat_ttl", 90*time.Second)
+	v.SetDefault("game_server_heartbeat_sweep_interval", 30*time.Second)
+
+	// Notification defaults
+	v.SetDefault("notification_max_unread_per_player", 100)
+
+	// Cosmetics defaults
+	v.SetDefault("cosmetics_undo_window", 5*time.Minute)
+	v.SetDefault("cosmetics_bundle_pro_rate", true)
+	v.SetDefault("cosmetics_featured", "")
+
+	// Webhook defaults
+	v.SetDefault("webhook_queue_size", 1000)
+	v.SetDefault("webhook_drop_policy", "oldest")
+	v.SetDefault("webhook_timeout", 5*time.Second)
+
+	// Account defaults
+	v.SetDefault("account_email_change_token_expiry", 24*time.Hour)
+	v.SetDefault("account_password_min_length", 8)
+	v.SetDefault("account_password_require_letter", false)
+	v.SetDefault("account_password_require_digit", false)
+	v.SetDefault("account_password_require_symbol", false)
+	v.SetDefault("account_login_lockout_threshold", 5)
+	v.SetDefault("account_login_lockout_duration", 5*time.Minute)
+	v.SetDefault("account_login_lockout_max_duration", time.Hour)
+	v.SetDefault("account_referral_claim_window", 7*24*time.Hour)
+	v.SetDefault("account_referral_currency_reward", 250)
+	v.SetDefault("account_referral_cosmetic_id", 0)
+
+	// Leaderboard defaults
+	v.SetDefault("leaderboard_weekly_mode", "calendar")
+	v.SetDefault("leaderboard_refresh_interval", time.Minute)
+	v.SetDefault("leaderboard_snapshot_max_age", 5*time.Minute)
+
+	// Admin defaults
+	v.SetDefault("admin_operation_concurrency", "")
+	v.SetDefault("admin_default_operation_concurrency", 1)
+	v.SetDefault("admin_status_cache_ttl", 30*time.Second)
+
+	// Moderation defaults
+	v.SetDefault("moderation_reports_per_day", 5)
+
+	// Loot defaults
+	v.SetDefault("loot_pity_threshold", 10)
+	v.SetDefault("loot_pity_min_rarity", "rare")
+	v.SetDefault("loot_guaranteed_drop", false)
+	v.SetDefault("loot_duplicate_refund", "")
+
+	// Social defaults
+	v.SetDefault("social_events_heartbeat_interval", 15*time.Second)
+
+	// Metrics defaults
+	v.SetDefault("metrics_enabled", true)
+	v.SetDefault("metrics_addr", "")
 }
 
 func bindEnv(v *viper.Viper) {
@@ -136,14 +596,194 @@ func bindEnv(v *viper.Viper) {
 	_ = v.BindEnv("cors_allow_origins", "CORS_ALLOW_ORIGINS")
 	_ = v.BindEnv("rate_limit_max", "RATE_LIMIT_MAX")
 	_ = v.BindEnv("rate_limit_duration", "RATE_LIMIT_DURATION")
+	_ = v.BindEnv("auth_rate_limit_max", "AUTH_RATE_LIMIT_MAX")
+	_ = v.BindEnv("auth_rate_limit_duration", "AUTH_RATE_LIMIT_DURATION")
+	_ = v.BindEnv("blocked_countries", "BLOCKED_COUNTRIES")
+	_ = v.BindEnv("server_shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT")
 
 	// JWT
 	_ = v.BindEnv("jwt_secret", "JWT_SECRET")
 	_ = v.BindEnv("jwt_access_expiration", "JWT_ACCESS_EXPIRATION")
 	_ = v.BindEnv("jwt_refresh_expiration", "JWT_REFRESH_EXPIRATION")
+	_ = v.BindEnv("jwt_refresh_sliding", "JWT_REFRESH_SLIDING")
+	_ = v.BindEnv("jwt_refresh_max_lifetime", "JWT_REFRESH_MAX_LIFETIME")
 
 	// Progression
 	_ = v.BindEnv("progression_base_xp_per_level", "PROGRESSION_BASE_XP_PER_LEVEL")
+	_ = v.BindEnv("progression_curve_type", "PROGRESSION_CURVE_TYPE")
+	_ = v.BindEnv("progression_curve_growth_factor", "PROGRESSION_CURVE_GROWTH_FACTOR")
+	_ = v.BindEnv("progression_xp_table", "PROGRESSION_XP_TABLE")
+	_ = v.BindEnv("progression_prestige_backfill_batch_size", "PROGRESSION_PRESTIGE_BACKFILL_BATCH_SIZE")
+	_ = v.BindEnv("progression_xp_reward_base", "PROGRESSION_XP_REWARD_BASE")
+	_ = v.BindEnv("progression_xp_reward_per_kill", "PROGRESSION_XP_REWARD_PER_KILL")
+	_ = v.BindEnv("progression_xp_reward_per_wave", "PROGRESSION_XP_REWARD_PER_WAVE")
+	_ = v.BindEnv("progression_xp_reward_per_scrap", "PROGRESSION_XP_REWARD_PER_SCRAP")
+	_ = v.BindEnv("progression_xp_reward_per_revive", "PROGRESSION_XP_REWARD_PER_REVIVE")
+	_ = v.BindEnv("progression_xp_reward_per_healing", "PROGRESSION_XP_REWARD_PER_HEALING")
+	_ = v.BindEnv("progression_reward_decay_short_match_duration", "PROGRESSION_REWARD_DECAY_SHORT_MATCH_DURATION")
+	_ = v.BindEnv("progression_reward_decay_window", "PROGRESSION_REWARD_DECAY_WINDOW")
+	_ = v.BindEnv("progression_reward_decay_free_matches", "PROGRESSION_REWARD_DECAY_FREE_MATCHES")
+	_ = v.BindEnv("progression_reward_decay_factor", "PROGRESSION_REWARD_DECAY_FACTOR")
+	_ = v.BindEnv("progression_reward_decay_min_multiplier", "PROGRESSION_REWARD_DECAY_MIN_MULTIPLIER")
+	_ = v.BindEnv("progression_stat_caps_max_kills_per_wave", "PROGRESSION_STAT_CAPS_MAX_KILLS_PER_WAVE")
+	_ = v.BindEnv("progression_stat_caps_max_scrap_per_wave", "PROGRESSION_STAT_CAPS_MAX_SCRAP_PER_WAVE")
+	_ = v.BindEnv("progression_stat_caps_max_data_per_wave", "PROGRESSION_STAT_CAPS_MAX_DATA_PER_WAVE")
+
+	// Game server
+	_ = v.BindEnv("game_server_join_history_retention", "GAME_SERVER_JOIN_HISTORY_RETENTION")
+	_ = v.BindEnv("game_server_join_history_max_per_server", "GAME_SERVER_JOIN_HISTORY_MAX_PER_SERVER")
+	_ = v.BindEnv("game_server_allow_hostnames", "GAME_SERVER_ALLOW_HOSTNAMES")
+	_ = v.BindEnv("game_server_join_token_expiration", "GAME_SERVER_JOIN_TOKEN_EXPIRATION")
+	_ = v.BindEnv("game_server_heartbeat_ttl", "GAME_SERVER_HEARTBEAT_TTL")
+	_ = v.BindEnv("game_server_heartbeat_sweep_interval", "GAME_SERVER_HEARTBEAT_SWEEP_INTERVAL")
+
+	// Notification
+	_ = v.BindEnv("notification_max_unread_per_player", "NOTIFICATION_MAX_UNREAD_PER_PLAYER")
+
+	// Cosmetics
+	_ = v.BindEnv("cosmetics_undo_window", "COSMETICS_UNDO_WINDOW")
+	_ = v.BindEnv("cosmetics_default_slot_cosmetics", "COSMETICS_DEFAULT_SLOT_COSMETICS")
+	_ = v.BindEnv("cosmetics_bundle_pro_rate", "COSMETICS_BUNDLE_PRO_RATE")
+	_ = v.BindEnv("cosmetics_featured", "COSMETICS_FEATURED")
+
+	// Webhook
+	_ = v.BindEnv("webhook_url", "WEBHOOK_URL")
+	_ = v.BindEnv("webhook_queue_size", "WEBHOOK_QUEUE_SIZE")
+	_ = v.BindEnv("webhook_drop_policy", "WEBHOOK_DROP_POLICY")
+	_ = v.BindEnv("webhook_timeout", "WEBHOOK_TIMEOUT")
+
+	// Account
+	_ = v.BindEnv("account_email_change_token_expiry", "ACCOUNT_EMAIL_CHANGE_TOKEN_EXPIRY")
+	_ = v.BindEnv("account_password_min_length", "ACCOUNT_PASSWORD_MIN_LENGTH")
+	_ = v.BindEnv("account_password_require_letter", "ACCOUNT_PASSWORD_REQUIRE_LETTER")
+	_ = v.BindEnv("account_password_require_digit", "ACCOUNT_PASSWORD_REQUIRE_DIGIT")
+	_ = v.BindEnv("account_password_require_symbol", "ACCOUNT_PASSWORD_REQUIRE_SYMBOL")
+	_ = v.BindEnv("account_login_lockout_threshold", "ACCOUNT_LOGIN_LOCKOUT_THRESHOLD")
+	_ = v.BindEnv("account_login_lockout_duration", "ACCOUNT_LOGIN_LOCKOUT_DURATION")
+	_ = v.BindEnv("account_login_lockout_max_duration", "ACCOUNT_LOGIN_LOCKOUT_MAX_DURATION")
+	_ = v.BindEnv("account_referral_claim_window", "ACCOUNT_REFERRAL_CLAIM_WINDOW")
+	_ = v.BindEnv("account_referral_currency_reward", "ACCOUNT_REFERRAL_CURRENCY_REWARD")
+	_ = v.BindEnv("account_referral_cosmetic_id", "ACCOUNT_REFERRAL_COSMETIC_ID")
+
+	// Leaderboard
+	_ = v.BindEnv("leaderboard_weekly_mode", "LEADERBOARD_WEEKLY_MODE")
+	_ = v.BindEnv("leaderboard_refresh_interval", "LEADERBOARD_REFRESH_INTERVAL")
+	_ = v.BindEnv("leaderboard_snapshot_max_age", "LEADERBOARD_SNAPSHOT_MAX_AGE")
+
+	// Admin
+	_ = v.BindEnv("admin_operation_concurrency", "ADMIN_OPERATION_CONCURRENCY")
+	_ = v.BindEnv("admin_default_operation_concurrency", "ADMIN_DEFAULT_OPERATION_CONCURRENCY")
+	_ = v.BindEnv("admin_status_cache_ttl", "ADMIN_STATUS_CACHE_TTL")
+
+	// Moderation
+	_ = v.BindEnv("moderation_reports_per_day", "MODERATION_REPORTS_PER_DAY")
+
+	// Loot
+	_ = v.BindEnv("loot_pity_threshold", "LOOT_PITY_THRESHOLD")
+	_ = v.BindEnv("loot_pity_min_rarity", "LOOT_PITY_MIN_RARITY")
+	_ = v.BindEnv("loot_guaranteed_drop", "LOOT_GUARANTEED_DROP")
+	_ = v.BindEnv("loot_duplicate_refund", "LOOT_DUPLICATE_REFUND")
+
+	// Social
+	_ = v.BindEnv("social_events_heartbeat_interval", "SOCIAL_EVENTS_HEARTBEAT_INTERVAL")
+
+	// Metrics
+	_ = v.BindEnv("metrics_enabled", "METRICS_ENABLED")
+	_ = v.BindEnv("metrics_addr", "METRICS_ADDR")
+}
+
+// parseList splits a comma-separated value into trimmed, non-empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseXPTable parses a comma-separated list of strictly increasing cumulative XP thresholds.
+func parseXPTable(value string) ([]int64, error) {
+	var table []int64
+	for _, item := range parseList(value) {
+		threshold, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("PROGRESSION_XP_TABLE contains invalid threshold %q", item)
+		}
+		if threshold <= 0 || (len(table) > 0 && threshold <= table[len(table)-1]) {
+			return nil, fmt.Errorf("PROGRESSION_XP_TABLE thresholds must be positive and strictly increasing")
+		}
+		table = append(table, threshold)
+	}
+	return table, nil
+}
+
+// parseCosmeticIDs parses a comma-separated list of positive cosmetic IDs, e.g. "4,9,2".
+func parseCosmeticIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, item := range parseList(value) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("COSMETICS_FEATURED contains invalid cosmetic ID %q", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// isRarity reports whether value is one of the cosmetic_items.rarity values.
+func isRarity(value string) bool {
+	switch value {
+	case "common", "uncommon", "rare", "epic", "legendary":
+		return true
+	}
+	return false
+}
+
+// parseRarityAmounts parses comma-separated rarity:amount pairs, e.g. "common:5,rare:25".
+func parseRarityAmounts(value string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+	for _, item := range parseList(value) {
+		rarity, amount, ok := strings.Cut(item, ":")
+		rarity = strings.TrimSpace(rarity)
+		n, err := strconv.ParseInt(strings.TrimSpace(amount), 10, 64)
+		if !ok || !isRarity(rarity) || err != nil || n < 0 {
+			return nil, fmt.Errorf("LOOT_DUPLICATE_REFUND contains invalid entry %q", item)
+		}
+		amounts[rarity] = n
+	}
+	return amounts, nil
+}
+
+// parseSlotCosmetics parses comma-separated slot:cosmetic_id pairs, e.g. "badge:3,title:7".
+func parseSlotCosmetics(value string) (map[string]int64, error) {
+	slots := make(map[string]int64)
+	for _, item := range parseList(value) {
+		slot, id, ok := strings.Cut(item, ":")
+		slot = strings.TrimSpace(slot)
+		cosmeticID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if !ok || slot == "" || err != nil || cosmeticID <= 0 {
+			return nil, fmt.Errorf("COSMETICS_DEFAULT_SLOT_COSMETICS contains invalid entry %q", item)
+		}
+		slots[slot] = cosmeticID
+	}
+	return slots, nil
+}
+
+// parseOperationConcurrency parses comma-separated operation:limit pairs, e.g. "prestige_backfill:1,notification_broadcast:2".
+func parseOperationConcurrency(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, item := range parseList(value) {
+		operation, limit, ok := strings.Cut(item, ":")
+		operation = strings.TrimSpace(operation)
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || operation == "" || err != nil || n <= 0 {
+			return nil, fmt.Errorf("ADMIN_OPERATION_CONCURRENCY contains invalid entry %q", item)
+		}
+		limits[operation] = n
+	}
+	return limits, nil
 }
 
 func validateRequired(v *viper.Viper) error {
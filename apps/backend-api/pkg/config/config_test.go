@@ -85,6 +85,9 @@ func TestLoadConfigEnvironmentOverride(t *testing.T) {
 	t.Setenv("SERVER_PORT", "3000")
 	t.Setenv("JWT_ACCESS_EXPIRATION", "1h")
 	t.Setenv("JWT_REFRESH_EXPIRATION", "48h")
+	t.Setenv("BLOCKED_COUNTRIES", "kp, IR")
+	t.Setenv("PROGRESSION_CURVE_TYPE", "table")
+	t.Setenv("PROGRESSION_XP_TABLE", "100, 300,700")
 
 	cfg, err := LoadConfig()
 	if err != nil {
@@ -125,6 +128,176 @@ func TestLoadConfigEnvironmentOverride(t *testing.T) {
 	if cfg.JWT.RefreshExpiration != 48*time.Hour {
 		t.Errorf("JWT_REFRESH_EXPIRATION override mismatch: got %v", cfg.JWT.RefreshExpiration)
 	}
+	if len(cfg.Server.BlockedCountries) != 2 || cfg.Server.BlockedCountries[0] != "kp" || cfg.Server.BlockedCountries[1] != "IR" {
+		t.Errorf("BLOCKED_COUNTRIES override mismatch: got %v", cfg.Server.BlockedCountries)
+	}
+	if cfg.Progression.CurveType != "table" {
+		t.Errorf("PROGRESSION_CURVE_TYPE override mismatch: got %s", cfg.Progression.CurveType)
+	}
+	if len(cfg.Progression.XPTable) != 3 || cfg.Progression.XPTable[2] != 700 {
+		t.Errorf("PROGRESSION_XP_TABLE override mismatch: got %v", cfg.Progression.XPTable)
+	}
+}
+
+func TestLoadConfigInvalidXPTable(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("PROGRESSION_XP_TABLE", "100,50")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for non-increasing PROGRESSION_XP_TABLE")
+	}
+}
+
+func TestLoadConfigDefaultSlotCosmetics(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("COSMETICS_DEFAULT_SLOT_COSMETICS", "badge:3, title:7")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Cosmetics.DefaultSlotCosmetics["badge"] != 3 || cfg.Cosmetics.DefaultSlotCosmetics["title"] != 7 {
+		t.Errorf("Unexpected default slot cosmetics: %v", cfg.Cosmetics.DefaultSlotCosmetics)
+	}
+
+	t.Setenv("COSMETICS_DEFAULT_SLOT_COSMETICS", "badge")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for malformed COSMETICS_DEFAULT_SLOT_COSMETICS")
+	}
+}
+
+func TestLoadConfigFeaturedCosmetics(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("COSMETICS_FEATURED", "4, 9,2")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := cfg.Cosmetics.FeaturedCosmetics; len(got) != 3 || got[0] != 4 || got[1] != 9 || got[2] != 2 {
+		t.Errorf("Unexpected featured cosmetics: %v", got)
+	}
+
+	t.Setenv("COSMETICS_FEATURED", "4,skin")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for malformed COSMETICS_FEATURED")
+	}
+}
+
+func TestLoadConfigDuplicateLootRefund(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("LOOT_DUPLICATE_REFUND", "common:5, rare:25")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := cfg.Loot.DuplicateLootRefund; len(got) != 2 || got["common"] != 5 || got["rare"] != 25 {
+		t.Errorf("Unexpected duplicate loot refund: %v", got)
+	}
+
+	for _, invalid := range []string{"mythic:5", "rare:-1", "rare"} {
+		t.Setenv("LOOT_DUPLICATE_REFUND", invalid)
+		if _, err := LoadConfig(); err == nil {
+			t.Errorf("Expected error for LOOT_DUPLICATE_REFUND=%q", invalid)
+		}
+	}
+}
+
+func TestLoadConfigOperationConcurrency(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("ADMIN_OPERATION_CONCURRENCY", "prestige_backfill:1, notification_broadcast:3")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Admin.OperationConcurrency["prestige_backfill"] != 1 || cfg.Admin.OperationConcurrency["notification_broadcast"] != 3 {
+		t.Errorf("Unexpected operation concurrency: %v", cfg.Admin.OperationConcurrency)
+	}
+	if cfg.Admin.DefaultOperationConcurrency != 1 {
+		t.Errorf("Expected default operation concurrency 1, got %d", cfg.Admin.DefaultOperationConcurrency)
+	}
+
+	t.Setenv("ADMIN_OPERATION_CONCURRENCY", "prestige_backfill:0")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for non-positive ADMIN_OPERATION_CONCURRENCY limit")
+	}
+}
+
+func TestLoadConfigInvalidWebhookDropPolicy(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("WEBHOOK_DROP_POLICY", "block")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for unknown WEBHOOK_DROP_POLICY")
+	}
+}
+
+func TestLoadConfigInvalidLeaderboardWeeklyMode(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("LEADERBOARD_WEEKLY_MODE", "monthly")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for unknown LEADERBOARD_WEEKLY_MODE")
+	}
+}
+
+func TestLoadConfigPasswordPolicy(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if want := (PasswordPolicy{MinLength: 8}); cfg.Account.PasswordPolicy != want {
+		t.Errorf("Default password policy mismatch: got %+v", cfg.Account.PasswordPolicy)
+	}
+
+	t.Setenv("ACCOUNT_PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("ACCOUNT_PASSWORD_REQUIRE_DIGIT", "true")
+	t.Setenv("ACCOUNT_PASSWORD_REQUIRE_SYMBOL", "true")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if want := (PasswordPolicy{MinLength: 12, RequireDigit: true, RequireSymbol: true}); cfg.Account.PasswordPolicy != want {
+		t.Errorf("Password policy override mismatch: got %+v", cfg.Account.PasswordPolicy)
+	}
+
+	t.Setenv("ACCOUNT_PASSWORD_MIN_LENGTH", "-1")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for negative ACCOUNT_PASSWORD_MIN_LENGTH")
+	}
+}
+
+func TestLoadConfigLoginLockout(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Account.LoginLockoutThreshold != 5 || cfg.Account.LoginLockoutDuration != 5*time.Minute || cfg.Account.LoginLockoutMaxDuration != time.Hour {
+		t.Errorf("Default login lockout mismatch: got %d %v %v", cfg.Account.LoginLockoutThreshold, cfg.Account.LoginLockoutDuration, cfg.Account.LoginLockoutMaxDuration)
+	}
+
+	t.Setenv("ACCOUNT_LOGIN_LOCKOUT_MAX_DURATION", "1m")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for ACCOUNT_LOGIN_LOCKOUT_MAX_DURATION shorter than ACCOUNT_LOGIN_LOCKOUT_DURATION")
+	}
+}
+
+func TestLoadConfigShutdownTimeout(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.ShutdownTimeout != 10*time.Second {
+		t.Errorf("Default SERVER_SHUTDOWN_TIMEOUT mismatch: got %v", cfg.Server.ShutdownTimeout)
+	}
+
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "30s")
+	if cfg, err = LoadConfig(); err != nil || cfg.Server.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Expected SERVER_SHUTDOWN_TIMEOUT 30s, got %v (err %v)", cfg.Server.ShutdownTimeout, err)
+	}
+
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "0s")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("Expected error for zero SERVER_SHUTDOWN_TIMEOUT")
+	}
 }
 
 func TestLoadConfigInvalidDuration(t *testing.T) {
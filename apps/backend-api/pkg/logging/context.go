@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey string
+
+// RequestIDKey is the context key holding the request's correlation ID. Fiber
+// stores locals as fasthttp user values, so setting it with c.Locals makes
+// c.Context(), which handlers pass to services, carry the ID too.
+const RequestIDKey contextKey = "request_id"
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// FromContext returns logger with a request_id field when ctx carries a
+// request ID, and logger unchanged otherwise (e.g. in background workers).
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if id := RequestID(ctx); id != "" {
+		return logger.With(zap.String("request_id", id))
+	}
+	return logger
+}
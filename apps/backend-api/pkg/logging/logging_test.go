@@ -1,10 +1,13 @@
 package logging
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -72,3 +75,25 @@ func TestMustNewLogger(t *testing.T) {
 	// Test that it actually logs
 	logger.Info("test message from MustNewLogger")
 }
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	FromContext(context.Background(), logger).Info("no id")
+	FromContext(WithRequestID(context.Background(), "abc"), logger).Info("with id")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if len(entries[0].Context) != 0 {
+		t.Errorf("Expected no fields without a request ID, got %v", entries[0].Context)
+	}
+	if fields := entries[1].ContextMap(); fields["request_id"] != "abc" {
+		t.Errorf("Expected request_id abc, got %v", fields)
+	}
+	if got := RequestID(WithRequestID(context.Background(), "xyz")); got != "xyz" {
+		t.Errorf("Expected RequestID xyz, got %q", got)
+	}
+}